@@ -0,0 +1,193 @@
+package antispam
+
+import (
+	"context"
+	"strings"
+
+	"github.com/autowp/goautowp/config"
+	"github.com/autowp/goautowp/schema"
+	"github.com/doug-martin/goqu/v9"
+)
+
+const reasonsSeparator = "; "
+
+type Action string
+
+const (
+	ActionNone       Action = "none"
+	ActionFlag       Action = "flag"
+	ActionQuarantine Action = "quarantine"
+)
+
+// Message is comment being classified.
+type Message struct {
+	ID       int64
+	AuthorID int64
+	TypeID   schema.CommentMessageType
+	ItemID   int64
+	Text     string
+}
+
+// Classifier scores message from 0 (clean) to 1 (certainly spam).
+// Reason is shown to moderators when score is positive.
+type Classifier interface {
+	Classify(ctx context.Context, message Message) (score float64, reason string, err error)
+}
+
+// Trainer is classifier learning from decisions of moderators.
+type Trainer interface {
+	Train(ctx context.Context) error
+}
+
+type Verdict struct {
+	Score   float64
+	Reasons []string
+	Action  Action
+}
+
+// Service combines scores of classifiers and decides what to do with message.
+type Service struct {
+	db          *goqu.Database
+	config      config.AntispamConfig
+	classifiers []Classifier
+}
+
+func NewService(db *goqu.Database, config config.AntispamConfig, classifiers ...Classifier) *Service {
+	return &Service{
+		db:          db,
+		config:      config,
+		classifiers: classifiers,
+	}
+}
+
+// DefaultClassifiers returns all built-in classifiers configured by config.
+func DefaultClassifiers(db *goqu.Database, config config.AntispamConfig) []Classifier {
+	return []Classifier{
+		&LinkDensity{MaxLinks: config.MaxLinks},
+		&BannedPhrases{Phrases: config.BannedPhrases},
+		&RepeatedText{db: db, WindowHours: config.RepeatWindowHours},
+		&NewAccount{db: db, Days: config.NewAccountDays},
+		NewBayes(db),
+	}
+}
+
+// Classify combines scores of classifiers as independent evidences: 1 - Π(1 - score).
+func (s *Service) Classify(ctx context.Context, message Message) (Verdict, error) {
+	clean := 1.0
+	reasons := make([]string, 0)
+
+	for _, classifier := range s.classifiers {
+		score, reason, err := classifier.Classify(ctx, message)
+		if err != nil {
+			return Verdict{}, err
+		}
+
+		if score <= 0 {
+			continue
+		}
+
+		clean *= 1 - min(score, 1)
+
+		reasons = append(reasons, reason)
+	}
+
+	verdict := Verdict{Score: 1 - clean, Reasons: reasons, Action: ActionNone}
+
+	switch {
+	case verdict.Score >= s.config.QuarantineScore:
+		verdict.Action = ActionQuarantine
+	case verdict.Score >= s.config.FlagScore:
+		verdict.Action = ActionFlag
+	}
+
+	return verdict, nil
+}
+
+// Train retrains all classifiers able to learn.
+func (s *Service) Train(ctx context.Context) error {
+	for _, classifier := range s.classifiers {
+		if trainer, ok := classifier.(Trainer); ok {
+			err := trainer.Train(ctx)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Store keeps verdict of message for moderators.
+func (s *Service) Store(ctx context.Context, messageID int64, verdict Verdict) error {
+	return s.db.WithTx(func(tx *goqu.TxDatabase) error {
+		return s.StoreTx(ctx, tx, messageID, verdict)
+	})
+}
+
+// StoreTx keeps verdict of message within transaction storing the message.
+func (s *Service) StoreTx(ctx context.Context, tx *goqu.TxDatabase, messageID int64, verdict Verdict) error {
+	record := goqu.Record{
+		schema.CommentClassificationTableCommentIDColName: messageID,
+		schema.CommentClassificationTableScoreColName:     verdict.Score,
+		schema.CommentClassificationTableActionColName:    verdict.Action,
+		schema.CommentClassificationTableReasonsColName:   strings.Join(verdict.Reasons, reasonsSeparator),
+		schema.CommentClassificationTableCreatedAtColName: goqu.Func("NOW"),
+	}
+
+	_, err := tx.Insert(schema.CommentClassificationTable).Rows(record).
+		OnConflict(goqu.DoUpdate(schema.CommentClassificationTableCommentIDColName, record)).
+		Executor().ExecContext(ctx)
+
+	return err
+}
+
+// Clear removes stored verdict of message. Returns true when message had one.
+func (s *Service) Clear(ctx context.Context, messageID int64) (bool, error) {
+	res, err := s.db.Delete(schema.CommentClassificationTable).
+		Where(schema.CommentClassificationTableCommentIDCol.Eq(messageID)).
+		Executor().ExecContext(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+// Verdicts returns stored verdicts of messages indexed by their ids.
+func (s *Service) Verdicts(ctx context.Context, messageIDs []int64) (map[int64]Verdict, error) {
+	result := make(map[int64]Verdict, len(messageIDs))
+	if len(messageIDs) == 0 {
+		return result, nil
+	}
+
+	rows := make([]schema.CommentClassificationRow, 0, len(messageIDs))
+
+	err := s.db.Select(
+		schema.CommentClassificationTableCommentIDCol,
+		schema.CommentClassificationTableScoreCol,
+		schema.CommentClassificationTableActionCol,
+		schema.CommentClassificationTableReasonsCol,
+	).
+		From(schema.CommentClassificationTable).
+		Where(schema.CommentClassificationTableCommentIDCol.In(messageIDs)).
+		ScanStructsContext(ctx, &rows)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		verdict := Verdict{Score: row.Score, Action: Action(row.Action), Reasons: []string{}}
+		if len(row.Reasons) > 0 {
+			verdict.Reasons = strings.Split(row.Reasons, reasonsSeparator)
+		}
+
+		result[row.CommentID] = verdict
+	}
+
+	return result, nil
+}
@@ -0,0 +1,114 @@
+package antispam
+
+import (
+	"context"
+	"testing"
+
+	"github.com/autowp/goautowp/config"
+	"github.com/autowp/goautowp/schema"
+	"github.com/stretchr/testify/require"
+)
+
+type constClassifier float64
+
+func (s constClassifier) Classify(_ context.Context, _ Message) (float64, string, error) {
+	return float64(s), "const", nil
+}
+
+func TestClassifyCombinesScores(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.AntispamConfig{FlagScore: 0.5, QuarantineScore: 0.9}
+
+	verdict, err := NewService(nil, cfg, constClassifier(0), constClassifier(0.3)).Classify(t.Context(), Message{})
+	require.NoError(t, err)
+	require.InDelta(t, 0.3, verdict.Score, 0.0001)
+	require.Equal(t, ActionNone, verdict.Action)
+	require.Equal(t, []string{"const"}, verdict.Reasons)
+
+	verdict, err = NewService(nil, cfg, constClassifier(0.5), constClassifier(0.5)).Classify(t.Context(), Message{})
+	require.NoError(t, err)
+	require.InDelta(t, 0.75, verdict.Score, 0.0001)
+	require.Equal(t, ActionFlag, verdict.Action)
+
+	verdict, err = NewService(nil, cfg, constClassifier(0.5), constClassifier(2)).Classify(t.Context(), Message{})
+	require.NoError(t, err)
+	require.InDelta(t, 1, verdict.Score, 0.0001)
+	require.Equal(t, ActionQuarantine, verdict.Action)
+}
+
+func TestLinkDensity(t *testing.T) {
+	t.Parallel()
+
+	classifier := LinkDensity{MaxLinks: 3}
+
+	score, _, err := classifier.Classify(t.Context(), Message{Text: "Nice car, I had the same one"})
+	require.NoError(t, err)
+	require.Zero(t, score)
+
+	score, _, err = classifier.Classify(t.Context(), Message{
+		Text: "Photos of the same model are at https://example.com/gallery, worth looking at",
+	})
+	require.NoError(t, err)
+	require.InDelta(t, 0.25, score, 0.0001)
+
+	// message of links only is flagged, but not quarantined until it has too many of them
+	score, _, err = classifier.Classify(t.Context(), Message{Text: "https://example.com/gallery"})
+	require.NoError(t, err)
+	require.InDelta(t, 0.5, score, 0.0001)
+
+	score, _, err = classifier.Classify(t.Context(), Message{
+		Text: "https://a.example.com https://b.example.com www.c.example.com",
+	})
+	require.NoError(t, err)
+	require.InDelta(t, 0.75, score, 0.0001)
+
+	score, reason, err := classifier.Classify(t.Context(), Message{
+		Text: "https://a.example.com https://b.example.com www.c.example.com https://d.example.com",
+	})
+	require.NoError(t, err)
+	require.InDelta(t, 1, score, 0.0001)
+	require.Equal(t, "links: 4", reason)
+}
+
+func TestBannedPhrases(t *testing.T) {
+	t.Parallel()
+
+	classifier := BannedPhrases{Phrases: []string{"Buy Cheap"}}
+
+	score, _, err := classifier.Classify(t.Context(), Message{Text: "buy cheap watches here"})
+	require.NoError(t, err)
+	require.InDelta(t, 1, score, 0.0001)
+
+	score, _, err = classifier.Classify(t.Context(), Message{Text: "cheap to buy and to run"})
+	require.NoError(t, err)
+	require.Zero(t, score)
+}
+
+func TestTokenize(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, []string{"купить", "bmw", "m3", "дешево"}, tokenize("Купить BMW M3 дешево? купить, a!"))
+}
+
+func TestBayesProbability(t *testing.T) {
+	t.Parallel()
+
+	model := bayesModel{
+		spamDocuments: 100,
+		hamDocuments:  100,
+		tokens: map[string]schema.CommentSpamTokenRow{
+			"casino": {Token: "casino", Spam: 60, Ham: 1},
+			"bonus":  {Token: "bonus", Spam: 40, Ham: 2},
+			"engine": {Token: "engine", Spam: 2, Ham: 50},
+			"sedan":  {Token: "sedan", Spam: 1, Ham: 30},
+		},
+	}
+
+	require.Greater(t, model.probability([]string{"casino", "bonus", "unknown"}), 0.99)
+	require.Less(t, model.probability([]string{"engine", "sedan"}), 0.01)
+	require.Zero(t, model.probability([]string{"unknown"}))
+
+	model.hamDocuments = 1
+	require.Zero(t, model.probability([]string{"casino"}))
+}
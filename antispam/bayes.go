@@ -0,0 +1,245 @@
+package antispam
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/autowp/goautowp/schema"
+	"github.com/doug-martin/goqu/v9"
+)
+
+const (
+	bayesMinDocuments      = 20
+	bayesTrainLimit        = 5000
+	bayesHamMinAge         = 7 * 24 * time.Hour
+	bayesMinTokenDocuments = 2
+	bayesInterestingTokens = 15
+	// bayesStrength is weight of neutral 0.5 assumption for rarely seen tokens.
+	bayesStrength     = 1.0
+	bayesMinTokenProb = 0.01
+	bayesMaxTokenProb = 0.99
+	bayesInsertBatch  = 500
+	minTokenLength    = 2
+	maxTokenLength    = 32
+	totalsToken       = ""
+)
+
+// Bayes is naive Bayes classifier trained on comments deleted by moderators as spam
+// and long-living comments as ham.
+type Bayes struct {
+	db *goqu.Database
+}
+
+func NewBayes(db *goqu.Database) *Bayes {
+	return &Bayes{db: db}
+}
+
+type bayesModel struct {
+	spamDocuments int64
+	hamDocuments  int64
+	tokens        map[string]schema.CommentSpamTokenRow
+}
+
+func tokenize(text string) []string {
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	seen := make(map[string]bool, len(words))
+	result := make([]string, 0, len(words))
+
+	for _, word := range words {
+		length := utf8.RuneCountInString(word)
+		if length < minTokenLength || length > maxTokenLength || seen[word] {
+			continue
+		}
+
+		seen[word] = true
+
+		result = append(result, word)
+	}
+
+	return result
+}
+
+// probability combines probabilities of the most interesting known tokens, 0 when model is not trained.
+func (s *bayesModel) probability(tokens []string) float64 {
+	if s.spamDocuments < bayesMinDocuments || s.hamDocuments < bayesMinDocuments {
+		return 0
+	}
+
+	probs := make([]float64, 0, len(tokens))
+
+	for _, token := range tokens {
+		row, ok := s.tokens[token]
+		if !ok || row.Spam+row.Ham == 0 {
+			continue
+		}
+
+		spamFreq := float64(row.Spam) / float64(s.spamDocuments)
+		hamFreq := float64(row.Ham) / float64(s.hamDocuments)
+		prob := spamFreq / (spamFreq + hamFreq)
+		seen := float64(row.Spam + row.Ham)
+
+		probs = append(probs, (bayesStrength*0.5+seen*prob)/(bayesStrength+seen))
+	}
+
+	if len(probs) == 0 {
+		return 0
+	}
+
+	sort.Slice(probs, func(i, j int) bool {
+		return math.Abs(probs[i]-0.5) > math.Abs(probs[j]-0.5)
+	})
+
+	if len(probs) > bayesInterestingTokens {
+		probs = probs[:bayesInterestingTokens]
+	}
+
+	var logSpam, logHam float64
+
+	for _, prob := range probs {
+		prob = min(max(prob, bayesMinTokenProb), bayesMaxTokenProb)
+		logSpam += math.Log(prob)
+		logHam += math.Log(1 - prob)
+	}
+
+	return 1 / (1 + math.Exp(logHam-logSpam))
+}
+
+func (s *Bayes) Classify(ctx context.Context, message Message) (float64, string, error) {
+	tokens := tokenize(message.Text)
+	if len(tokens) == 0 {
+		return 0, "", nil
+	}
+
+	rows := make([]schema.CommentSpamTokenRow, 0)
+
+	err := s.db.Select(
+		schema.CommentSpamTokenTableTokenCol, schema.CommentSpamTokenTableSpamCol, schema.CommentSpamTokenTableHamCol,
+	).
+		From(schema.CommentSpamTokenTable).
+		Where(schema.CommentSpamTokenTableTokenCol.In(append(tokens, totalsToken))).
+		ScanStructsContext(ctx, &rows)
+	if err != nil {
+		return 0, "", err
+	}
+
+	model := bayesModel{tokens: make(map[string]schema.CommentSpamTokenRow, len(rows))}
+
+	for _, row := range rows {
+		if row.Token == totalsToken {
+			model.spamDocuments, model.hamDocuments = row.Spam, row.Ham
+
+			continue
+		}
+
+		model.tokens[row.Token] = row
+	}
+
+	prob := model.probability(tokens)
+	if prob <= 0.5 {
+		return 0, "", nil
+	}
+
+	return (prob - 0.5) * 2, fmt.Sprintf("bayes: %.2f", prob), nil
+}
+
+// Train rebuilds model from comments deleted by moderators as spam and comments that survived for a week.
+func (s *Bayes) Train(ctx context.Context) error {
+	var spam, ham []string
+
+	err := s.db.Select(schema.CommentMessageTableMessageCol).
+		From(schema.CommentMessageTable).
+		Where(
+			schema.CommentMessageTableDeletedCol.IsTrue(),
+			schema.CommentMessageTableDeletedByCol.IsNotNull(),
+			schema.CommentMessageTableDeletedAsSpamCol.IsTrue(),
+		).
+		Order(schema.CommentMessageTableIDCol.Desc()).
+		Limit(bayesTrainLimit).
+		ScanValsContext(ctx, &spam)
+	if err != nil {
+		return err
+	}
+
+	err = s.db.Select(schema.CommentMessageTableMessageCol).
+		From(schema.CommentMessageTable).
+		Where(
+			schema.CommentMessageTableDeletedCol.IsFalse(),
+			schema.CommentMessageTableDatetimeCol.Lt(time.Now().Add(-bayesHamMinAge)),
+		).
+		Order(schema.CommentMessageTableIDCol.Desc()).
+		Limit(bayesTrainLimit).
+		ScanValsContext(ctx, &ham)
+	if err != nil {
+		return err
+	}
+
+	counts := make(map[string]*schema.CommentSpamTokenRow)
+
+	count := func(texts []string, isSpam bool) {
+		for _, text := range texts {
+			for _, token := range tokenize(text) {
+				row, ok := counts[token]
+				if !ok {
+					row = &schema.CommentSpamTokenRow{Token: token}
+					counts[token] = row
+				}
+
+				if isSpam {
+					row.Spam++
+				} else {
+					row.Ham++
+				}
+			}
+		}
+	}
+
+	count(spam, true)
+	count(ham, false)
+
+	records := []interface{}{goqu.Record{
+		schema.CommentSpamTokenTableTokenColName: totalsToken,
+		schema.CommentSpamTokenTableSpamColName:  len(spam),
+		schema.CommentSpamTokenTableHamColName:   len(ham),
+	}}
+
+	for _, row := range counts {
+		if row.Spam+row.Ham < bayesMinTokenDocuments {
+			continue
+		}
+
+		records = append(records, goqu.Record{
+			schema.CommentSpamTokenTableTokenColName: row.Token,
+			schema.CommentSpamTokenTableSpamColName:  row.Spam,
+			schema.CommentSpamTokenTableHamColName:   row.Ham,
+		})
+	}
+
+	ctx = context.WithoutCancel(ctx)
+
+	return s.db.WithTx(func(tx *goqu.TxDatabase) error {
+		_, err := tx.Delete(schema.CommentSpamTokenTable).Executor().ExecContext(ctx)
+		if err != nil {
+			return err
+		}
+
+		for start := 0; start < len(records); start += bayesInsertBatch {
+			end := min(start+bayesInsertBatch, len(records))
+
+			_, err = tx.Insert(schema.CommentSpamTokenTable).Rows(records[start:end]...).Executor().ExecContext(ctx)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
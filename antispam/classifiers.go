@@ -0,0 +1,143 @@
+package antispam
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/autowp/goautowp/schema"
+	"github.com/doug-martin/goqu/v9"
+)
+
+const (
+	linksRatioThreshold   = 0.5
+	linksRatioMaxScore    = 0.8
+	minRepeatedTextLength = 20
+	repeatedTextScore     = 0.6
+	newAccountScore       = 0.2
+	newAccountLinksScore  = 0.6
+)
+
+var linkRegexp = regexp.MustCompile(`(?i)(https?://|www\.)\S+`)
+
+// LinkDensity scores messages with many links or consisting mostly of links.
+type LinkDensity struct {
+	MaxLinks int
+}
+
+func (s *LinkDensity) Classify(_ context.Context, message Message) (float64, string, error) {
+	links := linkRegexp.FindAllString(message.Text, -1)
+	if len(links) == 0 {
+		return 0, "", nil
+	}
+
+	linksLength := 0
+	for _, link := range links {
+		linksLength += utf8.RuneCountInString(link)
+	}
+
+	score := float64(len(links)) / float64(s.MaxLinks+1)
+
+	// single link posted alone is common in honest replies, so the ratio grows with number of links
+	// and never reaches quarantine by itself, only the number of links does
+	ratio := float64(linksLength) / float64(utf8.RuneCountInString(strings.TrimSpace(message.Text)))
+	if ratio > linksRatioThreshold {
+		score = max(score, ratio*linksRatioMaxScore*(1+min(score, 1))/2)
+	}
+
+	return min(score, 1), fmt.Sprintf("links: %d", len(links)), nil
+}
+
+// BannedPhrases scores messages containing any of phrases, case-insensitive.
+type BannedPhrases struct {
+	Phrases []string
+}
+
+func (s *BannedPhrases) Classify(_ context.Context, message Message) (float64, string, error) {
+	text := strings.ToLower(message.Text)
+
+	for _, phrase := range s.Phrases {
+		if len(phrase) > 0 && strings.Contains(text, strings.ToLower(phrase)) {
+			return 1, "banned phrase: " + phrase, nil
+		}
+	}
+
+	return 0, "", nil
+}
+
+// RepeatedText scores messages author already posted to other topics recently.
+type RepeatedText struct {
+	db          *goqu.Database
+	WindowHours int
+}
+
+func (s *RepeatedText) Classify(ctx context.Context, message Message) (float64, string, error) {
+	if message.AuthorID == 0 || utf8.RuneCountInString(strings.TrimSpace(message.Text)) < minRepeatedTextLength {
+		return 0, "", nil
+	}
+
+	count, err := s.db.From(schema.CommentMessageTable).
+		Where(
+			schema.CommentMessageTableAuthorIDCol.Eq(message.AuthorID),
+			schema.CommentMessageTableIDCol.Neq(message.ID),
+			schema.CommentMessageTableMessageCol.Eq(message.Text),
+			schema.CommentMessageTableDatetimeCol.Gt(
+				time.Now().Add(-time.Duration(s.WindowHours)*time.Hour),
+			),
+			goqu.Or(
+				schema.CommentMessageTableTypeIDCol.Neq(message.TypeID),
+				schema.CommentMessageTableItemIDCol.Neq(message.ItemID),
+			),
+		).
+		CountContext(ctx)
+	if err != nil {
+		return 0, "", err
+	}
+
+	switch {
+	case count == 0:
+		return 0, "", nil
+	case count == 1:
+		return repeatedTextScore, "repeated in other topic", nil
+	}
+
+	return 1, fmt.Sprintf("repeated in %d other topics", count), nil
+}
+
+// NewAccount scores messages of recently registered users, especially ones with links.
+type NewAccount struct {
+	db   *goqu.Database
+	Days int
+}
+
+func (s *NewAccount) Classify(ctx context.Context, message Message) (float64, string, error) {
+	if message.AuthorID == 0 {
+		return 0, "", nil
+	}
+
+	var regDate time.Time
+
+	success, err := s.db.Select(schema.UserTableRegDateCol).
+		From(schema.UserTable).
+		Where(
+			schema.UserTableIDCol.Eq(message.AuthorID),
+			schema.UserTableRegDateCol.IsNotNull(),
+		).
+		ScanValContext(ctx, &regDate)
+	if err != nil {
+		return 0, "", err
+	}
+
+	if !success || time.Since(regDate) > time.Duration(s.Days)*24*time.Hour {
+		return 0, "", nil
+	}
+
+	if linkRegexp.MatchString(message.Text) {
+		return newAccountLinksScore, "new account with links", nil
+	}
+
+	return newAccountScore, "new account", nil
+}
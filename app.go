@@ -2,8 +2,10 @@ package goautowp
 
 import (
 	"context"
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"net"
 	"net/http"
 	"os"
@@ -11,9 +13,13 @@ import (
 	"sync"
 	"time"
 
+	"github.com/autowp/goautowp/attrs"
 	"github.com/autowp/goautowp/attrsamqp"
 	"github.com/autowp/goautowp/config"
+	"github.com/autowp/goautowp/export"
 	"github.com/autowp/goautowp/image/storage"
+	"github.com/autowp/goautowp/importer"
+	"github.com/autowp/goautowp/items"
 	"github.com/autowp/goautowp/schema"
 	_ "github.com/doug-martin/goqu/v9/dialect/mysql"    // enable mysql dialect
 	_ "github.com/doug-martin/goqu/v9/dialect/postgres" // enable postgres dialect
@@ -25,6 +31,7 @@ import (
 	_ "github.com/lib/pq"                                      // enable postgres driver
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"github.com/sirupsen/logrus"
+	"google.golang.org/protobuf/encoding/protojson"
 )
 
 type ServeOptions struct {
@@ -72,6 +79,11 @@ func (s *Application) ServeGRPC(quit chan bool) error {
 		return err
 	}
 
+	err = s.reindexEmptySearchIndex()
+	if err != nil {
+		return err
+	}
+
 	lis, err := net.Listen("tcp", s.container.Config().GRPC.Listen)
 	if err != nil {
 		return err
@@ -138,6 +150,41 @@ func (s *Application) Serve(ctx context.Context, options ServeOptions, quit chan
 		}()
 	}
 
+	if options.GRPC {
+		wg.Add(1)
+
+		go func() {
+			err := s.ListenSearchRefreshAMQP(ctx, quit)
+			if err != nil {
+				logrus.Errorln(err.Error())
+			}
+
+			wg.Done()
+		}()
+
+		wg.Add(1)
+
+		go func() {
+			err := s.RefreshSearchAutocomplete(ctx, quit)
+			if err != nil {
+				logrus.Errorln(err.Error())
+			}
+
+			wg.Done()
+		}()
+
+		wg.Add(1)
+
+		go func() {
+			err := s.ProcessCommentNotifications(ctx, quit)
+			if err != nil {
+				logrus.Errorln(err.Error())
+			}
+
+			wg.Done()
+		}()
+	}
+
 	if options.Public {
 		wg.Add(1)
 
@@ -421,6 +468,46 @@ func (s *Application) SchedulerDaily(ctx context.Context) error {
 
 	logrus.Infof("%d messages was deleted", deleted)
 
+	digestService, err := s.container.DigestService()
+	if err != nil {
+		return err
+	}
+
+	sent, err := digestService.SendDigests(ctx, time.Now())
+	if err != nil {
+		logrus.Error(err.Error())
+
+		return err
+	}
+
+	logrus.Infof("%d digests was sent", sent)
+
+	antispamService, err := s.container.Antispam()
+	if err != nil {
+		return err
+	}
+
+	err = antispamService.Train(ctx)
+	if err != nil {
+		logrus.Error(err.Error())
+
+		return err
+	}
+
+	db, err := s.container.GoquDB()
+	if err != nil {
+		return err
+	}
+
+	deleted, err = export.NewExporter(db, export.License{}).CleanupTombstones(ctx)
+	if err != nil {
+		logrus.Error(err.Error())
+
+		return err
+	}
+
+	logrus.Infof("%d export tombstones was deleted", deleted)
+
 	// affected, err = commentsRep.RefreshRepliesCount(ctx)
 	// if err != nil {
 	//	logrus.Error(err.Error())
@@ -580,7 +667,200 @@ func (s *Application) SpecsRefreshActualValues(ctx context.Context) error {
 		return err
 	}
 
-	return repository.UpdateAllActualValues(ctx)
+	return repository.MarkAllDirtyValues(ctx)
+}
+
+// reindexEmptySearchIndex fills search index in background when it is just created, e.g. kept in memory.
+func (s *Application) reindexEmptySearchIndex() error {
+	indexer, err := s.container.SearchIndexer()
+	if err != nil {
+		return err
+	}
+
+	count, err := indexer.Index().DocCount()
+	if err != nil {
+		return err
+	}
+
+	autocompleteCount, err := indexer.Autocomplete().DocCount()
+	if err != nil {
+		return err
+	}
+
+	if count > 0 && autocompleteCount > 0 {
+		return nil
+	}
+
+	go func() {
+		err := indexer.Reindex(context.Background())
+		if err != nil {
+			logrus.Errorf("search reindex: %s", err.Error())
+		}
+	}()
+
+	return nil
+}
+
+// SearchReindex rebuilds full-text search index from the database.
+func (s *Application) SearchReindex(ctx context.Context) error {
+	indexer, err := s.container.SearchIndexer()
+	if err != nil {
+		return err
+	}
+
+	return indexer.Reindex(ctx)
+}
+
+// CatalogueExport writes catalogue data set to dir, only items changed after since when it is not zero.
+func (s *Application) CatalogueExport(ctx context.Context, dir string, since time.Time) error {
+	db, err := s.container.GoquDB()
+	if err != nil {
+		return err
+	}
+
+	cfg := s.container.Config().CatalogueExport
+
+	metadata, err := export.NewExporter(db, export.License{
+		Name:        cfg.License,
+		URL:         cfg.LicenseURL,
+		Attribution: cfg.Attribution,
+	}).Export(ctx, dir, since)
+	if err != nil {
+		return err
+	}
+
+	for _, file := range metadata.Files {
+		logrus.Infof("%s: %d records", file.Name, file.Records)
+	}
+
+	return nil
+}
+
+func (s *Application) SpecsReportSuspiciousValues(ctx context.Context, output io.Writer) error {
+	repository, err := s.container.AttrsRepository()
+	if err != nil {
+		return err
+	}
+
+	return repository.SuspiciousValues(ctx, func(warning attrs.ValueWarning) error {
+		line, err := protojson.Marshal(extractAttrValueWarning(warning))
+		if err != nil {
+			return err
+		}
+
+		_, err = fmt.Fprintln(output, string(line))
+
+		return err
+	})
+}
+
+func (s *Application) CatalogueCheck(ctx context.Context, repair []string, output io.Writer) error {
+	kinds := make([]items.CheckProblemKind, 0, len(repair))
+
+	for _, value := range repair {
+		if value == "all" {
+			kinds = items.CheckProblemKinds
+
+			break
+		}
+
+		kind, err := items.ParseCheckProblemKind(value)
+		if err != nil {
+			return err
+		}
+
+		kinds = append(kinds, kind)
+	}
+
+	repository, err := s.container.ItemsRepository()
+	if err != nil {
+		return err
+	}
+
+	encoder := json.NewEncoder(output)
+
+	return repository.CheckCatalogue(ctx, kinds, func(problem items.CheckProblem) error {
+		return encoder.Encode(problem)
+	})
+}
+
+// importEvents records changes of catalogue import in log of events.
+type importEvents struct {
+	events *Events
+}
+
+func (s importEvents) AddItemEvent(ctx context.Context, userID int64, message string, itemIDs []int64) error {
+	return s.events.Add(ctx, Event{UserID: userID, Message: message, Items: itemIDs})
+}
+
+// CatalogueImport prints diff of file against catalogue and applies it unless dryRun is set.
+func (s *Application) CatalogueImport(
+	ctx context.Context, path string, userID int64, dryRun bool, output io.Writer,
+) error {
+	file, err := importer.ReadFile(path)
+	if err != nil {
+		return err
+	}
+
+	repository, err := s.container.ItemsRepository()
+	if err != nil {
+		return err
+	}
+
+	attrsRepository, err := s.container.AttrsRepository()
+	if err != nil {
+		return err
+	}
+
+	textStorageRepository, err := s.container.TextStorageRepository()
+	if err != nil {
+		return err
+	}
+
+	searchIndexer, err := s.container.SearchIndexer()
+	if err != nil {
+		return err
+	}
+
+	db, err := s.container.GoquDB()
+	if err != nil {
+		return err
+	}
+
+	events, err := s.container.Events()
+	if err != nil {
+		return err
+	}
+
+	catalogueImporter := importer.NewImporter(
+		db, repository, attrsRepository, textStorageRepository, searchIndexer, importValidator{repository: repository},
+		importEvents{events: events},
+	)
+
+	plan, err := catalogueImporter.Plan(ctx, file)
+	if err != nil {
+		return err
+	}
+
+	for _, change := range plan.Changes {
+		_, err = fmt.Fprintln(output, change.String())
+		if err != nil {
+			return err
+		}
+	}
+
+	if dryRun || len(plan.Changes) == 0 {
+		return nil
+	}
+
+	ids, err := catalogueImporter.Apply(ctx, plan, userID)
+	if err != nil {
+		return err
+	}
+
+	logrus.Infof("%d changes applied to %d items", len(plan.Changes), len(ids))
+
+	return nil
 }
 
 func (s *Application) RefreshItemParentLanguage(
@@ -731,6 +1011,51 @@ func (s *Application) ListenMonitoringAMQP(ctx context.Context, quit chan bool)
 	return nil
 }
 
+// ListenSearchRefreshAMQP applies search index refreshes made by other grpc replicas.
+func (s *Application) ListenSearchRefreshAMQP(ctx context.Context, quit chan bool) error {
+	indexer, err := s.container.SearchIndexer()
+	if err != nil {
+		return err
+	}
+
+	logrus.Info("Search refresh listener started")
+
+	err = indexer.ListenRefresh(ctx, quit)
+	if err != nil {
+		logrus.Error(err.Error())
+
+		return err
+	}
+
+	logrus.Info("Search refresh listener stopped")
+
+	return nil
+}
+
+// RefreshSearchAutocomplete periodically updates popularity of items in autocomplete index of this replica.
+func (s *Application) RefreshSearchAutocomplete(ctx context.Context, quit chan bool) error {
+	indexer, err := s.container.SearchIndexer()
+	if err != nil {
+		return err
+	}
+
+	indexer.RefreshAutocompletePeriodically(ctx, quit)
+
+	return nil
+}
+
+// ProcessCommentNotifications delivers queued notifications of topic subscribers about new comments.
+func (s *Application) ProcessCommentNotifications(ctx context.Context, quit chan bool) error {
+	repository, err := s.container.CommentsRepository()
+	if err != nil {
+		return err
+	}
+
+	repository.ProcessNotificationsPeriodically(ctx, quit)
+
+	return nil
+}
+
 func (s *Application) AttrsUpdateValuesAMQP(ctx context.Context, quit chan bool) error {
 	repository, err := s.container.AttrsRepository()
 	if err != nil {
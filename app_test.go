@@ -1,15 +1,24 @@
 package goautowp
 
 import (
+	"encoding/json"
 	"errors"
 	"fmt"
+	"io"
 	"math/rand"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
 	"testing"
 	"time"
 
 	"github.com/autowp/goautowp/config"
+	"github.com/autowp/goautowp/export"
 	"github.com/autowp/goautowp/image/storage"
+	"github.com/autowp/goautowp/query"
 	"github.com/autowp/goautowp/schema"
+	"github.com/autowp/goautowp/util"
 	"github.com/doug-martin/goqu/v9"
 	"github.com/golang-migrate/migrate/v4"
 	"github.com/stretchr/testify/require"
@@ -270,3 +279,197 @@ func TestDaily(t *testing.T) {
 	err := app.SchedulerDaily(t.Context())
 	require.NoError(t, err)
 }
+
+func readExportFile[T any](t *testing.T, dir, name string) []T {
+	t.Helper()
+
+	file, err := os.Open(filepath.Join(dir, name))
+	require.NoError(t, err)
+
+	defer util.Close(file)
+
+	result := make([]T, 0)
+	decoder := json.NewDecoder(file)
+
+	for decoder.More() {
+		var row T
+
+		err = decoder.Decode(&row)
+		require.NoError(t, err)
+
+		result = append(result, row)
+	}
+
+	return result
+}
+
+func TestCatalogueExport(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	cfg := config.LoadConfig(".")
+	app := NewApplication(cfg)
+	random := rand.New(rand.NewSource(time.Now().UnixNano())) //nolint:gosec
+	since := time.Now().Add(-time.Minute)
+
+	brandID := createItem(t, conn, cnt, &APIItem{
+		Name:       fmt.Sprintf("brand-%d", random.Int()),
+		IsGroup:    true,
+		ItemTypeId: ItemType_ITEM_TYPE_BRAND,
+		Catname:    fmt.Sprintf("brand-%d", random.Int()),
+	})
+
+	vehicleID := createItem(t, conn, cnt, &APIItem{
+		Name:       fmt.Sprintf("vehicle-%d", random.Int()),
+		ItemTypeId: ItemType_ITEM_TYPE_VEHICLE,
+	})
+
+	repository, err := cnt.ItemsRepository()
+	require.NoError(t, err)
+
+	_, err = repository.CreateItemParent(ctx, vehicleID, brandID, schema.ItemParentTypeDefault, "", 0)
+	require.NoError(t, err)
+
+	dir := t.TempDir()
+
+	err = app.CatalogueExport(ctx, dir, time.Time{})
+	require.NoError(t, err)
+
+	metadata, err := os.ReadFile(filepath.Join(dir, "metadata.json"))
+	require.NoError(t, err)
+	require.Contains(t, string(metadata), export.FormatName)
+
+	brands := readExportFile[export.Brand](t, dir, "brands.ndjson")
+	require.True(t, slices.ContainsFunc(brands, func(row export.Brand) bool {
+		return row.ID == brandID
+	}))
+
+	exportedItems := readExportFile[export.Item](t, dir, "items.ndjson")
+	require.True(t, slices.ContainsFunc(exportedItems, func(row export.Item) bool {
+		return row.ID == vehicleID && row.Type == "vehicle"
+	}))
+
+	isOwnLink := func(row export.ItemParent) bool {
+		return row.ItemID == vehicleID && row.ParentID == brandID
+	}
+	require.True(t, slices.ContainsFunc(readExportFile[export.ItemParent](t, dir, "item_parents.ndjson"), isOwnLink))
+	require.Empty(t, readExportFile[export.Deletion](t, dir, "deletions.ndjson"))
+
+	err = repository.RemoveItemParent(ctx, vehicleID, brandID, 0)
+	require.NoError(t, err)
+
+	dir = t.TempDir()
+
+	err = app.CatalogueExport(ctx, dir, since)
+	require.NoError(t, err)
+
+	exportedItems = readExportFile[export.Item](t, dir, "items.ndjson")
+	require.True(t, slices.ContainsFunc(exportedItems, func(row export.Item) bool {
+		return row.ID == vehicleID
+	}))
+	require.False(t, slices.ContainsFunc(readExportFile[export.ItemParent](t, dir, "item_parents.ndjson"), isOwnLink))
+	require.True(t, slices.ContainsFunc(
+		readExportFile[export.Deletion](t, dir, "deletions.ndjson"),
+		func(row export.Deletion) bool {
+			return row.Entity == "item_parent" && row.ItemID != nil && *row.ItemID == vehicleID &&
+				row.ParentID != nil && *row.ParentID == brandID
+		},
+	))
+}
+
+func TestCatalogueImport(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	cfg := config.LoadConfig(".")
+	app := NewApplication(cfg)
+	random := rand.New(rand.NewSource(time.Now().UnixNano())) //nolint:gosec
+
+	goquDB, err := cnt.GoquDB()
+	require.NoError(t, err)
+
+	userID, _ := getUserWithCleanHistory(t, conn, cfg, goquDB, adminUsername, adminPassword)
+
+	brandCatname := fmt.Sprintf("brand-%d", random.Int())
+	createItem(t, conn, cnt, &APIItem{
+		Name:       brandCatname,
+		IsGroup:    true,
+		ItemTypeId: ItemType_ITEM_TYPE_BRAND,
+		Catname:    brandCatname,
+	})
+
+	otherBrandID := createItem(t, conn, cnt, &APIItem{
+		Name:       fmt.Sprintf("brand-%d", random.Int()),
+		IsGroup:    true,
+		ItemTypeId: ItemType_ITEM_TYPE_BRAND,
+		Catname:    fmt.Sprintf("brand-%d", random.Int()),
+	})
+
+	lineName := fmt.Sprintf("line-%d", random.Int())
+	otherLineID := createItem(t, conn, cnt, &APIItem{
+		Name:       lineName,
+		IsGroup:    true,
+		ItemTypeId: ItemType_ITEM_TYPE_VEHICLE,
+	})
+
+	repository, err := cnt.ItemsRepository()
+	require.NoError(t, err)
+
+	_, err = repository.CreateItemParent(ctx, otherLineID, otherBrandID, schema.ItemParentTypeDefault, "", 0)
+	require.NoError(t, err)
+
+	modelCatname := fmt.Sprintf("model-%d", random.Int())
+	path := filepath.Join(t.TempDir(), "line.yaml")
+	err = os.WriteFile(path, []byte(fmt.Sprintf(`
+items:
+  - key: line
+    type: vehicle
+    name: %s
+    is_group: true
+    parents:
+      - item: %s
+  - type: vehicle
+    catname: %s
+    name: %s
+    begin_year: 1974
+    languages:
+      ru:
+        name: Модель
+    parents:
+      - item: line
+`, lineName, brandCatname, modelCatname, modelCatname)), 0o600)
+	require.NoError(t, err)
+
+	// line of other brand with the same name is not matched
+	var output strings.Builder
+
+	err = app.CatalogueImport(ctx, path, userID, true, &output)
+	require.NoError(t, err)
+	require.Contains(t, output.String(), "+ item line: vehicle")
+	require.Contains(t, output.String(), "+ item "+modelCatname+": vehicle")
+
+	err = app.CatalogueImport(ctx, path, userID, false, io.Discard)
+	require.NoError(t, err)
+
+	model, err := repository.Item(ctx, &query.ItemListOptions{
+		Catname: modelCatname,
+		ItemParentCacheAncestor: &query.ItemParentCacheListOptions{
+			ItemsByParentID: &query.ItemListOptions{Catname: brandCatname},
+		},
+	}, nil)
+	require.NoError(t, err)
+	require.Equal(t, int32(1974), model.BeginYear.Int32)
+
+	_, err = repository.Item(ctx, &query.ItemListOptions{
+		ItemID:                  otherLineID,
+		ItemParentCacheAncestor: &query.ItemParentCacheListOptions{ParentID: otherBrandID},
+	}, nil)
+	require.NoError(t, err)
+
+	// imported items are matched on the second run
+	output.Reset()
+
+	err = app.CatalogueImport(ctx, path, userID, true, &output)
+	require.NoError(t, err)
+	require.Empty(t, output.String())
+}
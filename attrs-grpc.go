@@ -2,12 +2,16 @@ package goautowp
 
 import (
 	"context"
+	"errors"
+	"fmt"
 
 	"github.com/autowp/goautowp/attrs"
 	"github.com/autowp/goautowp/query"
 	"github.com/autowp/goautowp/schema"
 	"github.com/autowp/goautowp/users"
 	"github.com/autowp/goautowp/util"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
@@ -396,6 +400,48 @@ func (s *AttrsGRPCServer) GetUserValues(
 	}, nil
 }
 
+func (s *AttrsGRPCServer) GetPendingRecalculations(
+	ctx context.Context,
+	in *AttrPendingRecalculationsRequest,
+) (*AttrPendingRecalculationsResponse, error) {
+	userCtx, err := s.auth.ValidateGRPC(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if userCtx.UserID == 0 {
+		return nil, status.Errorf(codes.PermissionDenied, "PermissionDenied")
+	}
+
+	if in.GetItemId() == 0 {
+		return nil, status.Errorf(codes.InvalidArgument, "item_id cannot be nil")
+	}
+
+	rows, err := s.repository.DirtyValues(ctx, in.GetItemId())
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	backlog, err := s.repository.DirtyValuesCount(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	items := make([]*AttrPendingRecalculation, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, &AttrPendingRecalculation{
+			AttributeId: row.AttributeID,
+			ItemId:      row.ItemID,
+			CreatedAt:   timestamppb.New(row.CreatedAt),
+		})
+	}
+
+	return &AttrPendingRecalculationsResponse{
+		Items:   items,
+		Backlog: backlog,
+	}, nil
+}
+
 func (s *AttrsGRPCServer) GetConflicts(
 	ctx context.Context,
 	in *AttrConflictsRequest,
@@ -501,7 +547,7 @@ func (s *AttrsGRPCServer) DeleteUserValues(
 func (s *AttrsGRPCServer) SetUserValues(
 	ctx context.Context,
 	in *AttrSetUserValuesRequest,
-) (*emptypb.Empty, error) {
+) (*AttrSetUserValuesResponse, error) {
 	userCtx, err := s.auth.ValidateGRPC(ctx)
 	if err != nil {
 		return nil, status.Error(codes.Internal, err.Error())
@@ -514,30 +560,70 @@ func (s *AttrsGRPCServer) SetUserValues(
 		)
 	}
 
-	for _, item := range in.GetItems() {
+	values := make([]attrs.Value, len(in.GetItems()))
+	fvs := make([]*errdetails.BadRequest_FieldViolation, 0)
+
+	for idx, item := range in.GetItems() {
 		protoValue := item.GetValue()
 
+		values[idx] = attrs.Value{
+			Valid:       protoValue.GetValid(),
+			FloatValue:  protoValue.GetFloatValue(),
+			IntValue:    protoValue.GetIntValue(),
+			BoolValue:   protoValue.GetBoolValue(),
+			ListValue:   protoValue.GetListValue(),
+			StringValue: protoValue.GetStringValue(),
+			IsEmpty:     protoValue.GetIsEmpty(),
+		}
+
+		violations, err := s.repository.ValidateUserValue(ctx, item.GetAttributeId(), values[idx])
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+
+		for _, violation := range violations {
+			fvs = append(fvs, &errdetails.BadRequest_FieldViolation{
+				Field:       fmt.Sprintf("items[%d].value", idx),
+				Description: violation,
+			})
+		}
+	}
+
+	if len(fvs) > 0 {
+		return nil, wrapFieldViolations(fvs)
+	}
+
+	for idx, item := range in.GetItems() {
 		_, err = s.repository.SetUserValue(
 			ctx,
 			userCtx.UserID,
 			item.GetAttributeId(),
 			item.GetItemId(),
-			attrs.Value{
-				Valid:       protoValue.GetValid(),
-				FloatValue:  protoValue.GetFloatValue(),
-				IntValue:    protoValue.GetIntValue(),
-				BoolValue:   protoValue.GetBoolValue(),
-				ListValue:   protoValue.GetListValue(),
-				StringValue: protoValue.GetStringValue(),
-				IsEmpty:     protoValue.GetIsEmpty(),
-			},
+			values[idx],
 		)
 		if err != nil {
 			return nil, status.Error(codes.Internal, err.Error())
 		}
 	}
 
-	return &emptypb.Empty{}, nil
+	warnings := make([]*AttrValueWarning, 0)
+
+	for idx, item := range in.GetItems() {
+		valueWarnings, err := s.repository.ValueWarnings(ctx, item.GetAttributeId(), item.GetItemId(), values[idx])
+		if err != nil {
+			logrus.Errorf("attrs: failed to collect value warnings: %s", err.Error())
+
+			continue
+		}
+
+		for _, warning := range valueWarnings {
+			warnings = append(warnings, extractAttrValueWarning(warning))
+		}
+	}
+
+	return &AttrSetUserValuesResponse{
+		Warnings: warnings,
+	}, nil
 }
 
 func (s *AttrsGRPCServer) MoveUserValues(
@@ -605,6 +691,28 @@ func (s *AttrsGRPCServer) GetChildSpecifications(
 	}, nil
 }
 
+func (s *AttrsGRPCServer) GetSpecificationsTable(
+	ctx context.Context, in *GetSpecificationsRequest,
+) (*SpecificationsTable, error) {
+	table, err := s.repository.Specifications(ctx, []int64{in.GetItemId()}, 0, in.GetLanguage())
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return extractSpecificationsTable(table.Components()), nil
+}
+
+func (s *AttrsGRPCServer) GetChildSpecificationsTable(
+	ctx context.Context, in *GetSpecificationsRequest,
+) (*SpecificationsTable, error) {
+	table, err := s.repository.ChildSpecifications(ctx, in.GetItemId(), in.GetLanguage())
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return extractSpecificationsTable(table.Components()), nil
+}
+
 func (s *AttrsGRPCServer) GetChartParameters(
 	ctx context.Context,
 	_ *emptypb.Empty,
@@ -658,3 +766,41 @@ func (s *AttrsGRPCServer) GetChartData(
 		Datasets: result,
 	}, nil
 }
+
+func (s *AttrsGRPCServer) GetChartSeries(ctx context.Context, in *ChartSeriesRequest) (*ChartSeries, error) {
+	series, err := s.repository.ChartSeries(
+		ctx,
+		in.GetAttributeId(),
+		reduceChartFilter(in.GetFilter()),
+		reduceChartGroupBy(in.GetGroupBy()),
+		in.GetPercentiles(),
+	)
+	if err != nil {
+		if errors.Is(err, attrs.ErrChartInvalidRequest) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return extractChartSeries(series), nil
+}
+
+func (s *AttrsGRPCServer) GetChartScatter(ctx context.Context, in *ChartScatterRequest) (*ChartScatter, error) {
+	scatter, err := s.repository.ChartScatter(
+		ctx,
+		in.GetXAttributeId(),
+		in.GetYAttributeId(),
+		reduceChartFilter(in.GetFilter()),
+		uint(in.GetLimit()),
+	)
+	if err != nil {
+		if errors.Is(err, attrs.ErrChartInvalidRequest) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return extractChartScatter(scatter), nil
+}
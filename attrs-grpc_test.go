@@ -13,7 +13,9 @@ import (
 	"github.com/autowp/goautowp/schema"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
 	"google.golang.org/protobuf/types/known/fieldmaskpb"
 )
@@ -186,6 +188,23 @@ func requireUserValues(t *testing.T, userValues *AttrUserValuesResponse) {
 	require.True(t, treeMultipleFound)
 }
 
+// processDirtyValues does the job of attrs AMQP worker, which is not running in tests.
+func processDirtyValues(t *testing.T) {
+	t.Helper()
+
+	repository, err := cnt.AttrsRepository()
+	require.NoError(t, err)
+
+	for {
+		processed, err := repository.ProcessDirtyValues(t.Context(), 100)
+		require.NoError(t, err)
+
+		if processed == 0 {
+			return
+		}
+	}
+}
+
 func requireValues(t *testing.T, values *AttrValuesResponse) {
 	t.Helper()
 
@@ -690,6 +709,8 @@ func TestValuesInherits(t *testing.T) {
 	)
 	require.NoError(t, err)
 
+	processDirtyValues(t)
+
 	// check values
 	for _, currentItemID := range []int64{itemID, childItemID} {
 		values, err := client.GetValues(
@@ -769,6 +790,8 @@ func TestEngineValuesApplied(t *testing.T) {
 	)
 	require.NoError(t, err)
 
+	processDirtyValues(t)
+
 	// check values
 	for _, currentItemID := range []int64{itemID, engineItemID} {
 		values, err := client.GetValues(
@@ -1235,6 +1258,8 @@ func TestValuesInheritsThroughItem(t *testing.T) {
 	)
 	require.NoError(t, err)
 
+	processDirtyValues(t)
+
 	// check values
 	for _, currentItemID := range []int64{itemID, childItemID, inheritorItemID} {
 		values, err := client.GetValues(
@@ -1357,6 +1382,8 @@ func TestInheritedValueOverridden(t *testing.T) {
 	)
 	require.NoError(t, err)
 
+	processDirtyValues(t)
+
 	_, err = client.SetUserValues(
 		metadata.AppendToOutgoingContext(
 			t.Context(),
@@ -1379,6 +1406,8 @@ func TestInheritedValueOverridden(t *testing.T) {
 	)
 	require.NoError(t, err)
 
+	processDirtyValues(t)
+
 	// check values
 	values, err := client.GetValues(
 		metadata.AppendToOutgoingContext(ctx, authorizationHeader, bearerPrefix+token.AccessToken),
@@ -2090,6 +2119,39 @@ func TestSpecifications(t *testing.T) {
 	require.Contains(t, res.GetHtml(), "hydrogen")
 	require.Contains(t, res.GetHtml(), "V6/4")
 	require.Contains(t, res.GetHtml(), engineName)
+
+	table, err := client.GetSpecificationsTable(
+		metadata.AppendToOutgoingContext(
+			t.Context(),
+			authorizationHeader,
+			bearerPrefix+token.AccessToken,
+		),
+		&GetSpecificationsRequest{
+			ItemId:   itemID,
+			Language: "en",
+		},
+	)
+	require.NoError(t, err)
+	require.Len(t, table.GetItems(), 1)
+	require.Equal(t, itemID, table.GetItems()[0].GetId())
+
+	values := make(map[int64]*SpecificationsTableValue)
+
+	for _, attribute := range table.GetAttributes() {
+		for _, cell := range attribute.GetCells() {
+			if cell.GetValue() != nil {
+				values[attribute.GetId()] = cell.GetValue()
+			}
+		}
+	}
+
+	require.Contains(t, values, schema.TurningDiameterAttr)
+	require.Equal(t, "7.1", values[schema.TurningDiameterAttr].GetValue())
+	require.Equal(t, "m", values[schema.TurningDiameterAttr].GetUnitAbbr())
+	require.Equal(t, "7.1 m", values[schema.TurningDiameterAttr].GetText())
+	require.Contains(t, values, schema.EngineConfigurationAttr)
+	require.Equal(t, "V6/4", values[schema.EngineConfigurationAttr].GetValue())
+	require.Len(t, values[schema.EngineConfigurationAttr].GetParts(), 3)
 }
 
 func TestChildSpecifications(t *testing.T) { //nolint: maintidx
@@ -2328,6 +2390,8 @@ func TestChildSpecifications(t *testing.T) { //nolint: maintidx
 	)
 	require.NoError(t, err)
 
+	processDirtyValues(t)
+
 	res, err := client.GetChildSpecifications(
 		metadata.AppendToOutgoingContext(
 			t.Context(),
@@ -2489,6 +2553,229 @@ func TestSetUserValuesIsEmpty(t *testing.T) {
 	require.True(t, values.GetItems()[index].GetValue().GetIsEmpty())
 }
 
+func TestSetUserValuesValidation(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+
+	cfg := config.LoadConfig(".")
+	kc := cnt.Keycloak()
+	token, err := kc.Login(ctx, "frontend", "", cfg.Keycloak.Realm, adminUsername, adminPassword)
+	require.NoError(t, err)
+	require.NotNil(t, token)
+
+	client := NewAttrsClient(conn)
+	ctx = metadata.AppendToOutgoingContext(ctx, authorizationHeader, bearerPrefix+token.AccessToken)
+
+	itemID := createItem(t, conn, cnt, &APIItem{
+		Name:       "TestSetUserValuesValidation",
+		ItemTypeId: ItemType_ITEM_TYPE_VEHICLE,
+	})
+
+	_, err = client.SetUserValues(ctx, &AttrSetUserValuesRequest{
+		Items: []*AttrUserValue{
+			{
+				AttributeId: schema.LengthAttr,
+				ItemId:      itemID,
+				Value: &AttrValueValue{
+					Valid:    true,
+					IntValue: -5,
+				},
+			},
+		},
+	})
+	require.ErrorContains(t, err, "invalid request")
+
+	res, err := client.SetUserValues(ctx, &AttrSetUserValuesRequest{
+		Items: []*AttrUserValue{
+			{
+				AttributeId: schema.AccelerationTo100KmhAttr,
+				ItemId:      itemID,
+				Value: &AttrValueValue{
+					Valid:      true,
+					FloatValue: 200,
+				},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	codesByAttribute := make(map[AttrValueWarning_Code]int64)
+
+	for _, warning := range res.GetWarnings() {
+		require.Equal(t, itemID, warning.GetItemId())
+		codesByAttribute[warning.GetCode()] = warning.GetRelatedAttributeId()
+	}
+
+	require.Contains(t, codesByAttribute, AttrValueWarning_OUT_OF_PLAUSIBLE_RANGE)
+	require.Contains(t, codesByAttribute, AttrValueWarning_MISSING_DEPENDENCY)
+	require.Equal(t, schema.EnginePowerAttr, codesByAttribute[AttrValueWarning_MISSING_DEPENDENCY])
+}
+
+func TestDerivedValues(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+
+	cfg := config.LoadConfig(".")
+	kc := cnt.Keycloak()
+	token, err := kc.Login(ctx, "frontend", "", cfg.Keycloak.Realm, adminUsername, adminPassword)
+	require.NoError(t, err)
+	require.NotNil(t, token)
+
+	client := NewAttrsClient(conn)
+	ctx = metadata.AppendToOutgoingContext(ctx, authorizationHeader, bearerPrefix+token.AccessToken)
+
+	itemID := createItem(t, conn, cnt, &APIItem{
+		Name:       "TestDerivedValues",
+		ItemTypeId: ItemType_ITEM_TYPE_VEHICLE,
+	})
+
+	_, err = client.SetUserValues(ctx, &AttrSetUserValuesRequest{
+		Items: []*AttrUserValue{
+			{
+				AttributeId: schema.EnginePowerAttr,
+				ItemId:      itemID,
+				Value:       &AttrValueValue{Valid: true, IntValue: 150},
+			},
+			{
+				AttributeId: schema.CurbWeightAttr,
+				ItemId:      itemID,
+				Value:       &AttrValueValue{Valid: true, IntValue: 1200},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	derivedValues := func() map[int64]*AttrValueValue {
+		t.Helper()
+
+		values, err := client.GetValues(ctx, &AttrValuesRequest{ItemId: itemID, Language: "en"})
+		require.NoError(t, err)
+
+		result := make(map[int64]*AttrValueValue, len(values.GetItems()))
+		for _, value := range values.GetItems() {
+			result[value.GetAttributeId()] = value.GetValue()
+		}
+
+		return result
+	}
+
+	values := derivedValues()
+	require.Contains(t, values, schema.PowerToWeightAttr)
+	require.InDelta(t, 125, values[schema.PowerToWeightAttr].GetFloatValue(), 0.01)
+	require.Contains(t, values, schema.EnginePowerKWAttr)
+	require.Equal(t, int32(112), values[schema.EnginePowerKWAttr].GetIntValue())
+	require.NotContains(t, values, schema.EngineTorqueLbFtAttr)
+
+	// changing input recomputes formula
+	_, err = client.SetUserValues(ctx, &AttrSetUserValuesRequest{
+		Items: []*AttrUserValue{
+			{
+				AttributeId: schema.CurbWeightAttr,
+				ItemId:      itemID,
+				Value:       &AttrValueValue{Valid: true, IntValue: 1500},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	values = derivedValues()
+	require.InDelta(t, 100, values[schema.PowerToWeightAttr].GetFloatValue(), 0.01)
+
+	// user value takes precedence over formula
+	_, err = client.SetUserValues(ctx, &AttrSetUserValuesRequest{
+		Items: []*AttrUserValue{
+			{
+				AttributeId: schema.EnginePowerKWAttr,
+				ItemId:      itemID,
+				Value:       &AttrValueValue{Valid: true, IntValue: 110},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	values = derivedValues()
+	require.Equal(t, int32(110), values[schema.EnginePowerKWAttr].GetIntValue())
+}
+
+func TestGetPendingRecalculations(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+
+	cfg := config.LoadConfig(".")
+	kc := cnt.Keycloak()
+	token, err := kc.Login(ctx, "frontend", "", cfg.Keycloak.Realm, adminUsername, adminPassword)
+	require.NoError(t, err)
+	require.NotNil(t, token)
+
+	client := NewAttrsClient(conn)
+	ctx = metadata.AppendToOutgoingContext(ctx, authorizationHeader, bearerPrefix+token.AccessToken)
+
+	parentID := createItem(t, conn, cnt, &APIItem{
+		Name:       "TestGetPendingRecalculations Parent",
+		ItemTypeId: ItemType_ITEM_TYPE_VEHICLE,
+		IsGroup:    true,
+	})
+
+	childID := createItem(t, conn, cnt, &APIItem{
+		Name:       "TestGetPendingRecalculations Child",
+		ItemTypeId: ItemType_ITEM_TYPE_VEHICLE,
+	})
+
+	_, err = NewItemsClient(conn).CreateItemParent(ctx, &ItemParent{
+		ItemId: childID, ParentId: parentID, Catname: "vehicle1",
+	})
+	require.NoError(t, err)
+
+	_, err = client.GetPendingRecalculations(t.Context(), &AttrPendingRecalculationsRequest{ItemId: childID})
+	require.Error(t, err)
+
+	_, err = client.GetPendingRecalculations(ctx, &AttrPendingRecalculationsRequest{})
+	require.Error(t, err)
+
+	_, err = client.SetUserValues(ctx, &AttrSetUserValuesRequest{
+		Items: []*AttrUserValue{
+			{
+				AttributeId: intAttributeID,
+				ItemId:      parentID,
+				Value:       &AttrValueValue{Valid: true, IntValue: 4100},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	res, err := client.GetPendingRecalculations(ctx, &AttrPendingRecalculationsRequest{ItemId: childID})
+	require.NoError(t, err)
+	require.NotEmpty(t, res.GetItems())
+
+	for _, item := range res.GetItems() {
+		require.Equal(t, childID, item.GetItemId())
+	}
+
+	processDirtyValues(t)
+
+	res, err = client.GetPendingRecalculations(ctx, &AttrPendingRecalculationsRequest{ItemId: childID})
+	require.NoError(t, err)
+	require.Empty(t, res.GetItems())
+
+	values, err := client.GetValues(ctx, &AttrValuesRequest{ItemId: childID, Language: "en"})
+	require.NoError(t, err)
+
+	var inherited bool
+
+	for _, value := range values.GetItems() {
+		if value.GetAttributeId() == intAttributeID {
+			inherited = true
+
+			require.Equal(t, int32(4100), value.GetValue().GetIntValue())
+		}
+	}
+
+	require.True(t, inherited)
+}
+
 func TestChart(t *testing.T) {
 	t.Parallel()
 
@@ -2507,3 +2794,173 @@ func TestChart(t *testing.T) {
 	)
 	require.NoError(t, err)
 }
+
+func TestChartSeries(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+
+	cfg := config.LoadConfig(".")
+	kc := cnt.Keycloak()
+	token, err := kc.Login(ctx, "frontend", "", cfg.Keycloak.Realm, adminUsername, adminPassword)
+	require.NoError(t, err)
+	require.NotNil(t, token)
+
+	client := NewAttrsClient(conn)
+	authCtx := metadata.AppendToOutgoingContext(ctx, authorizationHeader, bearerPrefix+token.AccessToken)
+
+	randomInt := rand.Int() //nolint: gosec
+
+	brandID := createItem(t, conn, cnt, &APIItem{
+		Name:       fmt.Sprintf("TestChartSeries Brand %d", randomInt),
+		IsGroup:    true,
+		ItemTypeId: ItemType_ITEM_TYPE_BRAND,
+		Catname:    fmt.Sprintf("chart-series-brand-%d", randomInt),
+	})
+
+	for idx, power := range []int32{100, 150, 200} {
+		itemID := createItem(t, conn, cnt, &APIItem{
+			Name:       fmt.Sprintf("TestChartSeries %d", idx),
+			ItemTypeId: ItemType_ITEM_TYPE_VEHICLE,
+			BeginYear:  1971 + int32(idx)*10, //nolint: gosec
+		})
+
+		_, err = NewItemsClient(conn).CreateItemParent(authCtx, &ItemParent{
+			ItemId: itemID, ParentId: brandID, Catname: fmt.Sprintf("vehicle%d", idx),
+		})
+		require.NoError(t, err)
+
+		_, err = client.SetUserValues(authCtx, &AttrSetUserValuesRequest{
+			Items: []*AttrUserValue{
+				{
+					AttributeId: schema.EnginePowerAttr,
+					ItemId:      itemID,
+					Value:       &AttrValueValue{Valid: true, IntValue: power},
+				},
+				{
+					AttributeId: schema.CurbWeightAttr,
+					ItemId:      itemID,
+					Value:       &AttrValueValue{Valid: true, IntValue: 1000 + power},
+				},
+			},
+		})
+		require.NoError(t, err)
+	}
+
+	series, err := client.GetChartSeries(ctx, &ChartSeriesRequest{
+		AttributeId: schema.EnginePowerAttr,
+		Filter:      &ChartFilter{BrandId: brandID},
+		GroupBy:     ChartSeriesRequest_DECADE,
+		Percentiles: []float64{0.5},
+	})
+	require.NoError(t, err)
+	require.Len(t, series.GetPoints(), 3)
+	require.Equal(t, int64(1970), series.GetPoints()[0].GetGroupId())
+	require.InDelta(t, 100, series.GetPoints()[0].GetMean(), 0.01)
+	require.InDelta(t, 5, series.GetTrend().GetSlope(), 0.01)
+
+	series, err = client.GetChartSeries(ctx, &ChartSeriesRequest{
+		AttributeId: schema.EnginePowerAttr,
+		Filter:      &ChartFilter{BrandId: brandID, YearFrom: 1980},
+		GroupBy:     ChartSeriesRequest_BRAND,
+	})
+	require.NoError(t, err)
+	require.Len(t, series.GetPoints(), 1)
+	require.Equal(t, brandID, series.GetPoints()[0].GetGroupId())
+	require.Equal(t, int32(2), series.GetPoints()[0].GetCount())
+	require.Nil(t, series.GetTrend())
+
+	scatter, err := client.GetChartScatter(ctx, &ChartScatterRequest{
+		XAttributeId: schema.CurbWeightAttr,
+		YAttributeId: schema.EnginePowerAttr,
+		Filter:       &ChartFilter{BrandId: brandID},
+	})
+	require.NoError(t, err)
+	require.Len(t, scatter.GetPoints(), 3)
+	require.InDelta(t, 1, scatter.GetTrend().GetSlope(), 0.01)
+	require.InDelta(t, -1000, scatter.GetTrend().GetIntercept(), 0.01)
+
+	_, err = client.GetChartSeries(ctx, &ChartSeriesRequest{AttributeId: schema.EngineNameAttr})
+	require.Error(t, err)
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = client.GetChartSeries(ctx, &ChartSeriesRequest{
+		AttributeId: schema.EnginePowerAttr,
+		Percentiles: []float64{0.1, 0.2, 0.3, 0.4, 0.5, 0.6, 0.7, 0.8, 0.9, 0.95, 0.99},
+	})
+	require.Error(t, err)
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestDerivedValueDoesNotOverrideEngineValue(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+
+	cfg := config.LoadConfig(".")
+	kc := cnt.Keycloak()
+	token, err := kc.Login(ctx, "frontend", "", cfg.Keycloak.Realm, adminUsername, adminPassword)
+	require.NoError(t, err)
+	require.NotNil(t, token)
+
+	client := NewAttrsClient(conn)
+	itemsClient := NewItemsClient(conn)
+	ctx = metadata.AppendToOutgoingContext(ctx, authorizationHeader, bearerPrefix+token.AccessToken)
+
+	engineID := createItem(t, conn, cnt, &APIItem{
+		Name:       "TestDerivedValueDoesNotOverrideEngineValue Engine",
+		ItemTypeId: ItemType_ITEM_TYPE_ENGINE,
+	})
+
+	// formula gives 1998 cc, but explicit value is different
+	_, err = client.SetUserValues(ctx, &AttrSetUserValuesRequest{
+		Items: []*AttrUserValue{
+			{
+				AttributeId: schema.EngineCylinderDiameter,
+				ItemId:      engineID,
+				Value:       &AttrValueValue{Valid: true, FloatValue: 86},
+			},
+			{
+				AttributeId: schema.EngineStrokeAttr,
+				ItemId:      engineID,
+				Value:       &AttrValueValue{Valid: true, FloatValue: 86},
+			},
+			{
+				AttributeId: schema.EngineConfigurationCylindersCountAttr,
+				ItemId:      engineID,
+				Value:       &AttrValueValue{Valid: true, IntValue: 4},
+			},
+			{
+				AttributeId: schema.EngineVolumeAttr,
+				ItemId:      engineID,
+				Value:       &AttrValueValue{Valid: true, IntValue: 1984},
+			},
+		},
+	})
+	require.NoError(t, err)
+
+	itemID := createItem(t, conn, cnt, &APIItem{
+		Name:       "TestDerivedValueDoesNotOverrideEngineValue",
+		ItemTypeId: ItemType_ITEM_TYPE_VEHICLE,
+	})
+
+	_, err = itemsClient.UpdateItem(ctx, &UpdateItemRequest{
+		Item:       &APIItem{Id: itemID, EngineItemId: engineID},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"engine_item_id"}},
+	})
+	require.NoError(t, err)
+
+	values, err := client.GetValues(ctx, &AttrValuesRequest{ItemId: itemID, Language: "en"})
+	require.NoError(t, err)
+
+	var volume *AttrValueValue
+
+	for _, value := range values.GetItems() {
+		if value.GetAttributeId() == schema.EngineVolumeAttr {
+			volume = value.GetValue()
+		}
+	}
+
+	require.NotNil(t, volume)
+	require.Equal(t, int32(1984), volume.GetIntValue())
+}
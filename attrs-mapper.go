@@ -3,6 +3,7 @@ package goautowp
 import (
 	"github.com/autowp/goautowp/attrs"
 	"github.com/autowp/goautowp/schema"
+	"google.golang.org/protobuf/types/known/wrapperspb"
 )
 
 func extractAttrValue(value attrs.Value) AttrValueValue {
@@ -40,3 +41,189 @@ func extractAttrTypeID(in schema.AttrsAttributeTypeID) AttrAttributeType_ID {
 
 	return AttrAttributeType_UNKNOWN
 }
+
+func extractSpecificationsTableValue(value *attrs.Component) *SpecificationsTableValue {
+	if value == nil {
+		return nil
+	}
+
+	parts := make([]*SpecificationsTableValue, 0, len(value.Parts))
+	for _, part := range value.Parts {
+		parts = append(parts, extractSpecificationsTableValue(part))
+	}
+
+	var unitName, unitAbbr string
+	if value.Unit != nil {
+		unitName = value.Unit.Name
+		unitAbbr = value.Unit.Abbr
+	}
+
+	return &SpecificationsTableValue{
+		AttributeId: value.AttributeID,
+		Value:       value.Value,
+		UnitName:    unitName,
+		UnitAbbr:    unitAbbr,
+		Text:        value.Text(),
+		Parts:       parts,
+	}
+}
+
+func extractSpecificationsTableImage(image *attrs.CarSpecTableItemImage) *SpecificationsTableImage {
+	if image == nil {
+		return nil
+	}
+
+	return &SpecificationsTableImage{
+		Src:    image.Src,
+		Width:  int32(image.Width),  //nolint: gosec
+		Height: int32(image.Height), //nolint: gosec
+	}
+}
+
+func extractSpecificationsTable(table *attrs.TableComponents) *SpecificationsTable {
+	resultItems := make([]*SpecificationsTableItem, 0, len(table.Items))
+
+	for _, item := range table.Items {
+		var today *wrapperspb.BoolValue
+		if item.Today != nil {
+			today = &wrapperspb.BoolValue{Value: *item.Today}
+		}
+
+		resultItems = append(resultItems, &SpecificationsTableItem{
+			Id:                 item.ID,
+			Name:               item.Name,
+			Today:              today,
+			BeginYear:          item.BeginYear,
+			BeginMonth:         int32(item.BeginMonth),
+			EndYear:            item.EndYear,
+			EndMonth:           int32(item.EndMonth),
+			TopPictureUrl:      item.TopPictureURL,
+			TopPictureImage:    extractSpecificationsTableImage(item.TopPictureImage),
+			BottomPictureUrl:   item.BottomPictureURL,
+			BottomPictureImage: extractSpecificationsTableImage(item.BottomPictureImage),
+		})
+	}
+
+	resultAttributes := make([]*SpecificationsTableAttribute, 0, len(table.Attributes))
+
+	for _, attribute := range table.Attributes {
+		cells := make([]*SpecificationsTableCell, 0, len(attribute.Cells))
+		for _, cell := range attribute.Cells {
+			cells = append(cells, &SpecificationsTableCell{
+				ItemId:  cell.ItemID,
+				Colspan: uint32(cell.Colspan), //nolint: gosec
+				Value:   extractSpecificationsTableValue(cell.Value),
+			})
+		}
+
+		resultAttributes = append(resultAttributes, &SpecificationsTableAttribute{
+			Id:        attribute.ID,
+			Name:      attribute.Name,
+			Deep:      int32(attribute.Deep), //nolint: gosec
+			HasChilds: attribute.HasChilds,
+			HasValues: attribute.HasValues,
+			Cells:     cells,
+		})
+	}
+
+	return &SpecificationsTable{
+		Items:      resultItems,
+		Attributes: resultAttributes,
+	}
+}
+
+func extractAttrValueWarningCode(code attrs.ValueWarningCode) AttrValueWarning_Code {
+	switch code {
+	case attrs.ValueWarningCodeOutOfPlausibleRange:
+		return AttrValueWarning_OUT_OF_PLAUSIBLE_RANGE
+	case attrs.ValueWarningCodePrecision:
+		return AttrValueWarning_PRECISION
+	case attrs.ValueWarningCodeMissingDependency:
+		return AttrValueWarning_MISSING_DEPENDENCY
+	case attrs.ValueWarningCodeSiblingsOutlier:
+		return AttrValueWarning_SIBLINGS_OUTLIER
+	}
+
+	return AttrValueWarning_UNKNOWN
+}
+
+func extractAttrValueWarning(warning attrs.ValueWarning) *AttrValueWarning {
+	return &AttrValueWarning{
+		ItemId:             warning.ItemID,
+		AttributeId:        warning.AttributeID,
+		RelatedAttributeId: warning.RelatedAttributeID,
+		Code:               extractAttrValueWarningCode(warning.Code),
+		Message:            warning.Message,
+	}
+}
+
+func reduceChartFilter(in *ChartFilter) attrs.ChartFilter {
+	return attrs.ChartFilter{
+		VehicleTypeIDs: in.GetVehicleTypeIds(),
+		BrandID:        in.GetBrandId(),
+		YearFrom:       in.GetYearFrom(),
+		YearTo:         in.GetYearTo(),
+		ZoneID:         in.GetZoneId(),
+	}
+}
+
+func reduceChartGroupBy(in ChartSeriesRequest_GroupBy) attrs.ChartGroupBy {
+	switch in {
+	case ChartSeriesRequest_DECADE:
+		return attrs.ChartGroupByDecade
+	case ChartSeriesRequest_BRAND:
+		return attrs.ChartGroupByBrand
+	case ChartSeriesRequest_YEAR:
+	}
+
+	return attrs.ChartGroupByYear
+}
+
+func extractChartTrendLine(trend *attrs.ChartTrend) *ChartTrendLine {
+	if trend == nil {
+		return nil
+	}
+
+	return &ChartTrendLine{
+		Slope:     trend.Slope,
+		Intercept: trend.Intercept,
+		R2:        trend.R2,
+	}
+}
+
+func extractChartSeries(series *attrs.ChartSeries) *ChartSeries {
+	points := make([]*ChartSeriesPoint, 0, len(series.Points))
+	for _, point := range series.Points {
+		points = append(points, &ChartSeriesPoint{
+			GroupId:     point.GroupID,
+			Name:        point.Name,
+			Count:       int32(point.Count), //nolint: gosec
+			Mean:        point.Mean,
+			Min:         point.Min,
+			Max:         point.Max,
+			Percentiles: point.Percentiles,
+		})
+	}
+
+	return &ChartSeries{
+		Points: points,
+		Trend:  extractChartTrendLine(series.Trend),
+	}
+}
+
+func extractChartScatter(scatter *attrs.ChartScatter) *ChartScatter {
+	points := make([]*ChartScatterPoint, 0, len(scatter.Points))
+	for _, point := range scatter.Points {
+		points = append(points, &ChartScatterPoint{
+			ItemId: point.ItemID,
+			X:      point.X,
+			Y:      point.Y,
+			Year:   point.Year,
+		})
+	}
+
+	return &ChartScatter{
+		Points: points,
+		Trend:  extractChartTrendLine(scatter.Trend),
+	}
+}
@@ -0,0 +1,445 @@
+package attrs
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strconv"
+
+	"github.com/autowp/goautowp/schema"
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+)
+
+var ErrChartInvalidRequest = errors.New("invalid chart request")
+
+const (
+	chartDecade          = 10
+	chartScatterMaxLimit = 5000
+	chartSeriesMaxRows   = 100000
+	chartMaxPercentiles  = 10
+
+	chartItemIDAlias = "item_id"
+	chartXAlias      = "x"
+	chartYAlias      = "y"
+	chartYearAlias   = "year"
+	chartGroupAlias  = "group_id"
+	chartNameAlias   = "group_name"
+)
+
+type ChartGroupBy int
+
+const (
+	ChartGroupByYear ChartGroupBy = iota
+	ChartGroupByDecade
+	ChartGroupByBrand
+)
+
+type ChartFilter struct {
+	VehicleTypeIDs []int64
+	BrandID        int64
+	YearFrom       int32
+	YearTo         int32
+	ZoneID         int64
+}
+
+type ChartSeriesPoint struct {
+	GroupID     int64
+	Name        string
+	Count       int
+	Mean        float64
+	Min         float64
+	Max         float64
+	Percentiles []float64
+}
+
+// ChartTrend is a least squares fitted line `y = Slope * x + Intercept`.
+type ChartTrend struct {
+	Slope     float64
+	Intercept float64
+	R2        float64
+}
+
+type ChartSeries struct {
+	Points []ChartSeriesPoint
+	Trend  *ChartTrend
+}
+
+type ChartScatterPoint struct {
+	ItemID int64   `db:"item_id"`
+	X      float64 `db:"x"`
+	Y      float64 `db:"y"`
+	Year   int32   `db:"year"`
+}
+
+type ChartScatter struct {
+	Points []ChartScatterPoint
+	Trend  *ChartTrend
+}
+
+// percentile of sorted values with linear interpolation between closest ranks.
+func percentile(sorted []float64, rank float64) float64 {
+	if len(sorted) == 0 {
+		return 0
+	}
+
+	pos := rank * float64(len(sorted)-1)
+	lower := int(math.Floor(pos))
+	upper := int(math.Ceil(pos))
+
+	return sorted[lower] + (sorted[upper]-sorted[lower])*(pos-float64(lower))
+}
+
+func linearTrend(xs, ys []float64) *ChartTrend {
+	if len(xs) < 2 || len(xs) != len(ys) { //nolint: mnd
+		return nil
+	}
+
+	count := float64(len(xs))
+
+	var sumX, sumY float64
+	for i := range xs {
+		sumX += xs[i]
+		sumY += ys[i]
+	}
+
+	meanX, meanY := sumX/count, sumY/count
+
+	var covXY, varX, varY float64
+	for i := range xs {
+		covXY += (xs[i] - meanX) * (ys[i] - meanY)
+		varX += (xs[i] - meanX) * (xs[i] - meanX)
+		varY += (ys[i] - meanY) * (ys[i] - meanY)
+	}
+
+	if varX == 0 {
+		return nil
+	}
+
+	slope := covXY / varX
+	trend := ChartTrend{
+		Slope:     slope,
+		Intercept: meanY - slope*meanX,
+		R2:        1,
+	}
+
+	if varY > 0 {
+		trend.R2 = covXY * covXY / (varX * varY)
+	}
+
+	return &trend
+}
+
+func seriesPoint(groupID int64, name string, values []float64, percentiles []float64) ChartSeriesPoint {
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+
+	var sum float64
+	for _, value := range sorted {
+		sum += value
+	}
+
+	point := ChartSeriesPoint{
+		GroupID:     groupID,
+		Name:        name,
+		Count:       len(sorted),
+		Mean:        sum / float64(len(sorted)),
+		Min:         sorted[0],
+		Max:         sorted[len(sorted)-1],
+		Percentiles: make([]float64, 0, len(percentiles)),
+	}
+
+	for _, rank := range percentiles {
+		point.Percentiles = append(point.Percentiles, percentile(sorted, rank))
+	}
+
+	return point
+}
+
+type chartValueTable struct {
+	table          exp.AliasedExpression
+	itemIDCol      exp.IdentifierExpression
+	attributeIDCol exp.IdentifierExpression
+	valueCol       exp.IdentifierExpression
+}
+
+func (s *Repository) chartValueTable(ctx context.Context, attributeID int64, alias string) (*chartValueTable, error) {
+	attribute, err := s.Attribute(ctx, attributeID)
+	if err != nil {
+		return nil, err
+	}
+
+	if attribute == nil || !attribute.TypeID.Valid {
+		return nil, fmt.Errorf("%w: attribute `%d` not found", ErrChartInvalidRequest, attributeID)
+	}
+
+	if attribute.TypeID.AttributeTypeID != schema.AttrsAttributeTypeIDInteger &&
+		attribute.TypeID.AttributeTypeID != schema.AttrsAttributeTypeIDFloat {
+		return nil, fmt.Errorf("%w: attribute `%d` is not numeric", ErrChartInvalidRequest, attributeID)
+	}
+
+	valueTable, err := ValueTableByType(attribute.TypeID.AttributeTypeID)
+	if err != nil {
+		return nil, err
+	}
+
+	aliasTable := goqu.T(alias)
+
+	return &chartValueTable{
+		table:          valueTable.Table.As(alias),
+		itemIDCol:      aliasTable.Col(valueTable.ItemIDColName),
+		attributeIDCol: aliasTable.Col(valueTable.AttributeIDColName),
+		valueCol:       aliasTable.Col(valueTable.ValueColName),
+	}, nil
+}
+
+// chartFilter applies filter to select over item table.
+func (s *Repository) chartFilter(sqSelect *goqu.SelectDataset, filter ChartFilter) (*goqu.SelectDataset, error) {
+	if len(filter.VehicleTypeIDs) > 0 {
+		sqSelect = sqSelect.Where(goqu.L("EXISTS ?",
+			s.db.Select(goqu.V(true)).
+				From(schema.ItemVehicleTypeTable).
+				Join(schema.VehicleTypeParentTable, goqu.On(
+					schema.ItemVehicleTypeTableVehicleTypeIDCol.Eq(schema.VehicleTypeParentTableIDCol),
+				)).
+				Where(
+					schema.ItemVehicleTypeTableItemIDCol.Eq(schema.ItemTableIDCol),
+					schema.VehicleTypeParentTableParentIDCol.In(filter.VehicleTypeIDs),
+				),
+		))
+	}
+
+	if filter.BrandID > 0 {
+		sqSelect = sqSelect.Where(goqu.L("EXISTS ?",
+			s.db.Select(goqu.V(true)).
+				From(schema.ItemParentCacheTable).
+				Where(
+					schema.ItemParentCacheTableItemIDCol.Eq(schema.ItemTableIDCol),
+					schema.ItemParentCacheTableParentIDCol.Eq(filter.BrandID),
+				),
+		))
+	}
+
+	if filter.YearFrom > 0 {
+		sqSelect = sqSelect.Where(schema.ItemTableBeginYearCol.Gte(filter.YearFrom))
+	}
+
+	if filter.YearTo > 0 {
+		sqSelect = sqSelect.Where(schema.ItemTableBeginYearCol.Lte(filter.YearTo))
+	}
+
+	if filter.ZoneID > 0 {
+		busVehicleType := goqu.L("EXISTS ?",
+			s.db.Select(goqu.V(true)).
+				From(schema.ItemVehicleTypeTable).
+				Where(
+					schema.ItemVehicleTypeTableItemIDCol.Eq(schema.ItemTableIDCol),
+					schema.ItemVehicleTypeTableVehicleTypeIDCol.In(busVehicleTypes),
+				),
+		)
+
+		switch filter.ZoneID {
+		case engineZoneID:
+			sqSelect = sqSelect.Where(schema.ItemTableItemTypeIDCol.Eq(schema.ItemTableItemTypeIDEngine))
+		case busZoneID:
+			sqSelect = sqSelect.Where(
+				schema.ItemTableItemTypeIDCol.Neq(schema.ItemTableItemTypeIDEngine),
+				busVehicleType,
+			)
+		case defaultZoneID:
+			sqSelect = sqSelect.Where(
+				schema.ItemTableItemTypeIDCol.Neq(schema.ItemTableItemTypeIDEngine),
+				goqu.L("NOT ?", busVehicleType),
+			)
+		default:
+			return nil, fmt.Errorf("%w: zone `%d` is not supported", ErrChartInvalidRequest, filter.ZoneID)
+		}
+	}
+
+	return sqSelect, nil
+}
+
+// chartSampleOrder shuffles items in stable order, so limited selection is an even sample
+// over the whole catalogue rather than the oldest items.
+func chartSampleOrder() exp.OrderedExpression {
+	return goqu.L("CRC32(?)", schema.ItemTableIDCol).Asc()
+}
+
+// ChartSeries aggregates actual values of attribute by year, decade or brand.
+// Trend line is fitted over means of year and decade groups.
+func (s *Repository) ChartSeries(
+	ctx context.Context, attributeID int64, filter ChartFilter, groupBy ChartGroupBy, percentiles []float64,
+) (*ChartSeries, error) {
+	if len(percentiles) > chartMaxPercentiles {
+		return nil, fmt.Errorf("%w: at most %d percentiles allowed", ErrChartInvalidRequest, chartMaxPercentiles)
+	}
+
+	for _, rank := range percentiles {
+		if rank < 0 || rank > 1 {
+			return nil, fmt.Errorf("%w: percentile %v is out of [0, 1] range", ErrChartInvalidRequest, rank)
+		}
+	}
+
+	values, err := s.chartValueTable(ctx, attributeID, chartXAlias)
+	if err != nil {
+		return nil, err
+	}
+
+	brandAlias := "brand"
+	brandTable := goqu.T(brandAlias)
+
+	var groupCol, nameCol exp.Expression
+
+	sqSelect := s.db.From(values.table).
+		Join(schema.ItemTable, goqu.On(values.itemIDCol.Eq(schema.ItemTableIDCol))).
+		Where(
+			values.attributeIDCol.Eq(attributeID),
+			values.valueCol.IsNotNull(),
+		)
+
+	switch groupBy {
+	case ChartGroupByBrand:
+		sqSelect = sqSelect.
+			Join(schema.ItemParentCacheTable, goqu.On(schema.ItemParentCacheTableItemIDCol.Eq(schema.ItemTableIDCol))).
+			Join(schema.ItemTable.As(brandAlias), goqu.On(
+				schema.ItemParentCacheTableParentIDCol.Eq(brandTable.Col(schema.ItemTableIDColName)),
+				brandTable.Col(schema.ItemTableItemTypeIDColName).Eq(schema.ItemTableItemTypeIDBrand),
+			))
+		groupCol = brandTable.Col(schema.ItemTableIDColName)
+		nameCol = brandTable.Col(schema.ItemTableNameColName)
+	case ChartGroupByDecade:
+		sqSelect = sqSelect.Where(schema.ItemTableBeginYearCol.Gt(0))
+		groupCol = goqu.L("? - MOD(?, ?)", schema.ItemTableBeginYearCol, schema.ItemTableBeginYearCol, chartDecade)
+		nameCol = goqu.V("")
+	case ChartGroupByYear:
+		sqSelect = sqSelect.Where(schema.ItemTableBeginYearCol.Gt(0))
+		groupCol = schema.ItemTableBeginYearCol
+		nameCol = goqu.V("")
+	}
+
+	sqSelect, err = s.chartFilter(sqSelect, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []struct {
+		GroupID int64   `db:"group_id"`
+		Name    string  `db:"group_name"`
+		Value   float64 `db:"x"`
+	}
+
+	err = sqSelect.
+		Select(
+			goqu.L("?", groupCol).As(chartGroupAlias),
+			goqu.L("?", nameCol).As(chartNameAlias),
+			values.valueCol.As(chartXAlias),
+		).
+		Order(chartSampleOrder()).
+		Limit(chartSeriesMaxRows).
+		ScanStructsContext(ctx, &rows)
+	if err != nil {
+		return nil, err
+	}
+
+	groups := make(map[int64][]float64)
+	names := make(map[int64]string)
+
+	for _, row := range rows {
+		groups[row.GroupID] = append(groups[row.GroupID], row.Value)
+		names[row.GroupID] = row.Name
+	}
+
+	groupIDs := slices.Sorted(maps.Keys(groups))
+
+	result := ChartSeries{
+		Points: make([]ChartSeriesPoint, 0, len(groupIDs)),
+		Trend:  nil,
+	}
+
+	xs := make([]float64, 0, len(groupIDs))
+	ys := make([]float64, 0, len(groupIDs))
+
+	for _, groupID := range groupIDs {
+		name := names[groupID]
+		if groupBy != ChartGroupByBrand {
+			name = strconv.FormatInt(groupID, 10)
+		}
+
+		point := seriesPoint(groupID, name, groups[groupID], percentiles)
+		result.Points = append(result.Points, point)
+
+		xs = append(xs, float64(groupID))
+		ys = append(ys, point.Mean)
+	}
+
+	if groupBy != ChartGroupByBrand {
+		result.Trend = linearTrend(xs, ys)
+	}
+
+	return &result, nil
+}
+
+// ChartScatter returns pairs of actual values of two attributes of same items, e.g. power vs. mass.
+func (s *Repository) ChartScatter(
+	ctx context.Context, xAttributeID, yAttributeID int64, filter ChartFilter, limit uint,
+) (*ChartScatter, error) {
+	xValues, err := s.chartValueTable(ctx, xAttributeID, chartXAlias)
+	if err != nil {
+		return nil, err
+	}
+
+	yValues, err := s.chartValueTable(ctx, yAttributeID, chartYAlias)
+	if err != nil {
+		return nil, err
+	}
+
+	if limit == 0 || limit > chartScatterMaxLimit {
+		limit = chartScatterMaxLimit
+	}
+
+	sqSelect := s.db.Select(
+		schema.ItemTableIDCol.As(chartItemIDAlias),
+		xValues.valueCol.As(chartXAlias),
+		yValues.valueCol.As(chartYAlias),
+		goqu.COALESCE(schema.ItemTableBeginYearCol, 0).As(chartYearAlias),
+	).
+		From(xValues.table).
+		Join(yValues.table, goqu.On(xValues.itemIDCol.Eq(yValues.itemIDCol))).
+		Join(schema.ItemTable, goqu.On(xValues.itemIDCol.Eq(schema.ItemTableIDCol))).
+		Where(
+			xValues.attributeIDCol.Eq(xAttributeID),
+			yValues.attributeIDCol.Eq(yAttributeID),
+			xValues.valueCol.IsNotNull(),
+			yValues.valueCol.IsNotNull(),
+		).
+		Order(chartSampleOrder()).
+		Limit(limit)
+
+	sqSelect, err = s.chartFilter(sqSelect, filter)
+	if err != nil {
+		return nil, err
+	}
+
+	points := make([]ChartScatterPoint, 0)
+
+	err = sqSelect.ScanStructsContext(ctx, &points)
+	if err != nil {
+		return nil, err
+	}
+
+	xs := make([]float64, 0, len(points))
+	ys := make([]float64, 0, len(points))
+
+	for _, point := range points {
+		xs = append(xs, point.X)
+		ys = append(ys, point.Y)
+	}
+
+	return &ChartScatter{
+		Points: points,
+		Trend:  linearTrend(xs, ys),
+	}, nil
+}
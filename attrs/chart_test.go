@@ -0,0 +1,51 @@
+package attrs
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestPercentile(t *testing.T) {
+	t.Parallel()
+
+	sorted := []float64{1, 2, 3, 4, 5}
+
+	require.InDelta(t, 1, percentile(sorted, 0), 1e-9)
+	require.InDelta(t, 3, percentile(sorted, 0.5), 1e-9)
+	require.InDelta(t, 5, percentile(sorted, 1), 1e-9)
+	require.InDelta(t, 1.4, percentile(sorted, 0.1), 1e-9)
+	require.InDelta(t, 7, percentile([]float64{7}, 0.9), 1e-9)
+	require.Zero(t, percentile(nil, 0.5))
+}
+
+func TestLinearTrend(t *testing.T) {
+	t.Parallel()
+
+	trend := linearTrend([]float64{1, 2, 3, 4}, []float64{3, 5, 7, 9})
+	require.NotNil(t, trend)
+	require.InDelta(t, 2, trend.Slope, 1e-9)
+	require.InDelta(t, 1, trend.Intercept, 1e-9)
+	require.InDelta(t, 1, trend.R2, 1e-9)
+
+	trend = linearTrend([]float64{1, 2, 3, 4}, []float64{2, 1, 4, 3})
+	require.NotNil(t, trend)
+	require.InDelta(t, 0.6, trend.Slope, 1e-9)
+	require.InDelta(t, 0.36, trend.R2, 1e-9)
+
+	require.Nil(t, linearTrend([]float64{1}, []float64{1}))
+	require.Nil(t, linearTrend([]float64{2, 2}, []float64{1, 3}))
+}
+
+func TestSeriesPoint(t *testing.T) {
+	t.Parallel()
+
+	point := seriesPoint(1990, "1990", []float64{40, 10, 30, 20}, []float64{0.25, 0.5})
+	require.Equal(t, 4, point.Count)
+	require.InDelta(t, 25, point.Mean, 1e-9)
+	require.InDelta(t, 10, point.Min, 1e-9)
+	require.InDelta(t, 40, point.Max, 1e-9)
+	require.Len(t, point.Percentiles, 2)
+	require.InDelta(t, 17.5, point.Percentiles[0], 1e-9)
+	require.InDelta(t, 25, point.Percentiles[1], 1e-9)
+}
@@ -0,0 +1,149 @@
+package attrs
+
+import (
+	"strings"
+
+	"github.com/autowp/goautowp/util"
+)
+
+type ComponentUnit struct {
+	Name string
+	Abbr string
+}
+
+// Component is a structured counterpart of the rendered html value.
+// Value holds the composed text, Parts holds raw values of the sub-attributes it was built from.
+type Component struct {
+	AttributeID int64
+	Value       string
+	Unit        *ComponentUnit
+	Parts       []*Component
+}
+
+func (s *Component) IsEmpty() bool {
+	return s == nil || len(s.Value) == 0
+}
+
+// Text returns plain text representation with unit abbreviation suffix.
+func (s *Component) Text() string {
+	if s.IsEmpty() {
+		return ""
+	}
+
+	if s.Unit != nil && len(s.Unit.Abbr) > 0 {
+		return s.Value + " " + s.Unit.Abbr
+	}
+
+	return s.Value
+}
+
+func componentUnit(attribute *AttributeRow, units map[int64]I18nUnit) *ComponentUnit {
+	if !attribute.UnitID.Valid {
+		return nil
+	}
+
+	unit, ok := units[attribute.UnitID.Int64]
+	if !ok {
+		return nil
+	}
+
+	return &ComponentUnit{Name: unit.Name, Abbr: unit.Abbr}
+}
+
+func componentParts(values map[int64]string, attributeIDs ...int64) []*Component {
+	parts := make([]*Component, 0, len(attributeIDs))
+
+	for _, attributeID := range attributeIDs {
+		value, ok := values[attributeID]
+		if ok && len(value) > 0 {
+			parts = append(parts, &Component{AttributeID: attributeID, Value: value})
+		}
+	}
+
+	return parts
+}
+
+type TableComponents struct {
+	Items      []CarSpecTableItem
+	Attributes []TableComponentsAttribute
+}
+
+type TableComponentsAttribute struct {
+	ID        int64
+	Name      string
+	Deep      int
+	Cells     []TableComponentsCell
+	HasChilds bool
+	HasValues bool
+}
+
+type TableComponentsCell struct {
+	ItemID  int64
+	Value   *Component
+	Colspan uint
+}
+
+func (s *CarSpecTable) ComponentCells(attribute *AttributeRow) ([]TableComponentsCell, bool) {
+	cells := make([]TableComponentsCell, 0)
+	hasValues := false
+
+	for _, item := range s.Items {
+		value := s.componentValue(attribute, item.Values)
+
+		if !value.IsEmpty() {
+			hasValues = true
+		}
+
+		lastColIdx := len(cells) - 1
+		if lastColIdx >= 0 && cells[lastColIdx].Value.Text() == value.Text() {
+			cells[lastColIdx].Colspan++
+		} else {
+			cells = append(cells, TableComponentsCell{
+				ItemID:  item.ID,
+				Value:   value,
+				Colspan: 1,
+			})
+		}
+	}
+
+	return cells, hasValues
+}
+
+// Components returns the same table as Render does, but with typed values instead of html.
+func (s *CarSpecTable) Components() *TableComponents {
+	attributes := make([]TableComponentsAttribute, 0, len(s.Attributes))
+
+	for _, attribute := range s.Attributes {
+		if !util.Contains(hideAttrs, attribute.ID) {
+			cells, hasValues := s.ComponentCells(attribute)
+			attributes = append(attributes, TableComponentsAttribute{
+				ID:        attribute.ID,
+				Name:      attribute.NameTranslated,
+				Deep:      attribute.Deep,
+				Cells:     cells,
+				HasChilds: len(attribute.Childs) > 0,
+				HasValues: hasValues,
+			})
+		}
+	}
+
+	return &TableComponents{
+		Items:      s.Items,
+		Attributes: attributes,
+	}
+}
+
+func (s *CarSpecTable) componentValue(attribute *AttributeRow, values map[int64]string) *Component {
+	renderer, ok := renderMap[attribute.ID]
+	if !ok {
+		renderer = DefaultValue{}
+	}
+
+	component := renderer.Components(attribute, values, s.Units)
+	if component != nil {
+		component.AttributeID = attribute.ID
+		component.Value = strings.TrimSpace(component.Value)
+	}
+
+	return component
+}
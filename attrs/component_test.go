@@ -0,0 +1,88 @@
+package attrs
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/autowp/goautowp/schema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestComponentsMatchHTML(t *testing.T) {
+	t.Parallel()
+
+	units := map[int64]I18nUnit{
+		1:                          {Name: "millimeter", Abbr: "mm"},
+		schema.FuelTankPrimaryAttr: {Name: "liter", Abbr: "l"},
+	}
+	attribute := &AttributeRow{
+		AttrsAttributeRow: schema.AttrsAttributeRow{
+			ID:     schema.LengthAttr,
+			UnitID: sql.NullInt64{Int64: 1, Valid: true},
+		},
+	}
+
+	table := CarSpecTable{
+		Items: []CarSpecTableItem{
+			{ID: 1, Values: map[int64]string{schema.LengthAttr: "4500"}},
+			{ID: 2, Values: map[int64]string{schema.LengthAttr: "4500"}},
+			{ID: 3, Values: map[int64]string{schema.LengthAttr: "4600"}},
+		},
+		Attributes: []*AttributeRow{attribute},
+		Units:      units,
+	}
+
+	components := table.Components()
+	require.Len(t, components.Attributes, 1)
+	require.True(t, components.Attributes[0].HasValues)
+
+	cells := components.Attributes[0].Cells
+	require.Len(t, cells, 2)
+	require.Equal(t, uint(2), cells[0].Colspan)
+	require.Equal(t, "4500", cells[0].Value.Value)
+	require.Equal(t, "mm", cells[0].Value.Unit.Abbr)
+	require.Equal(t, "4600 mm", cells[1].Value.Text())
+
+	htmlCells, _ := table.Cells(attribute)
+	require.Len(t, htmlCells, len(cells))
+}
+
+func TestCompositeComponents(t *testing.T) {
+	t.Parallel()
+
+	units := map[int64]I18nUnit{
+		schema.FuelTankPrimaryAttr: {Name: "liter", Abbr: "l"},
+	}
+	values := map[int64]string{
+		schema.FuelTankPrimaryAttr:                    "60",
+		schema.FuelTankSecondaryAttr:                  "20",
+		schema.GearboxTypeAttr:                        "automatic",
+		schema.GearboxGearsAttr:                       "5",
+		schema.GearboxNameAttr:                        "<5HP19>",
+		schema.FrontWheelRimWidthAttr:                 "7",
+		schema.FrontWheelRadiusAttr:                   "16",
+		schema.FrontWheelTyreWidthAttr:                "205",
+		schema.EngineConfigurationCylindersCountAttr:  "6",
+		schema.EngineConfigurationCylindersLayoutAttr: "V",
+	}
+
+	fuelTank := renderMap[schema.FuelTankAttr].Components(&AttributeRow{}, values, units)
+	require.Equal(t, "60+20", fuelTank.Value)
+	require.Equal(t, "60+20 l", fuelTank.Text())
+	require.Len(t, fuelTank.Parts, 2)
+	require.Equal(t, schema.FuelTankSecondaryAttr, fuelTank.Parts[1].AttributeID)
+
+	gearbox := renderMap[schema.GearboxAttr].Components(&AttributeRow{}, values, units)
+	require.Equal(t, "automatic 5 (<5HP19>)", gearbox.Value)
+	require.Len(t, gearbox.Parts, 3)
+
+	wheel := renderMap[schema.FrontWheelAttr].Components(&AttributeRow{}, values, units)
+	require.Equal(t, "7J × 16\n205/?? R16", wheel.Value)
+	require.Len(t, wheel.Parts, 3)
+
+	engine := renderMap[schema.EngineConfigurationAttr].Components(&AttributeRow{}, values, units)
+	require.Equal(t, "V6", engine.Value)
+
+	require.Nil(t, renderMap[schema.BootVolumeAttr].Components(&AttributeRow{}, values, units))
+	require.Empty(t, (*Component)(nil).Text())
+}
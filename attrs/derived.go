@@ -0,0 +1,118 @@
+package attrs
+
+import (
+	"context"
+	"fmt"
+	"math"
+
+	"github.com/autowp/goautowp/schema"
+)
+
+// derivedAttributes are computed from actual values of other attributes
+// when neither user, engine nor parent provides a value.
+var derivedAttributes = MustNewFormulaGraph(map[int64]string{
+	// cylinder diameter & stroke in mm, volume in cc
+	schema.EngineVolumeAttr: fmt.Sprintf(
+		"pi / 4 * {%d} ^ 2 * {%d} * {%d} / 1000",
+		schema.EngineCylinderDiameter, schema.EngineStrokeAttr, schema.EngineConfigurationCylindersCountAttr,
+	),
+	// hp per tonne of curb weight
+	schema.PowerToWeightAttr: fmt.Sprintf("{%d} / {%d} * 1000", schema.EnginePowerAttr, schema.CurbWeightAttr),
+	// mechanical horsepower to kW
+	schema.EnginePowerKWAttr: fmt.Sprintf("{%d} * 0.745699872", schema.EnginePowerAttr),
+	// Nm to lb·ft
+	schema.EngineTorqueLbFtAttr: fmt.Sprintf("{%d} * 0.737562149", schema.EngineTorqueAttr),
+})
+
+func isDerivedAttributeID(attributeID int64) bool {
+	_, ok := derivedAttributes.Formula(attributeID)
+
+	return ok
+}
+
+func (s *Repository) calcDerivedValue(
+	ctx context.Context, attribute *schema.AttrsAttributeRow, itemID int64,
+) (Value, error) {
+	formula, ok := derivedAttributes.Formula(attribute.ID)
+	if !ok || !attribute.TypeID.Valid {
+		return Value{}, nil
+	}
+
+	inputs := make(map[int64]float64, len(formula.Inputs()))
+
+	for _, inputID := range formula.Inputs() {
+		input, err := s.Attribute(ctx, inputID)
+		if err != nil {
+			return Value{}, err
+		}
+
+		if input == nil || !input.TypeID.Valid {
+			return Value{}, fmt.Errorf("%w: `%d`", errAttributeNotFound, inputID)
+		}
+
+		value, err := s.ActualValue(ctx, inputID, itemID)
+		if err != nil {
+			return Value{}, err
+		}
+
+		number, ok := numericValue(input.TypeID.AttributeTypeID, value)
+		if !ok {
+			return Value{}, nil
+		}
+
+		inputs[inputID] = number
+	}
+
+	result, ok := formula.Evaluate(inputs)
+	if !ok {
+		return Value{}, nil
+	}
+
+	switch attribute.TypeID.AttributeTypeID { //nolint: exhaustive
+	case schema.AttrsAttributeTypeIDInteger:
+		if result > math.MaxInt32 || result < math.MinInt32 {
+			return Value{}, nil
+		}
+
+		return Value{
+			Valid:    true,
+			IntValue: int32(math.Round(result)),
+			Type:     attribute.TypeID.AttributeTypeID,
+		}, nil
+
+	case schema.AttrsAttributeTypeIDFloat:
+		if attribute.Precision.Valid {
+			multiplier := math.Pow10(int(attribute.Precision.Int32))
+			result = math.Round(result*multiplier) / multiplier
+		}
+
+		return Value{
+			Valid:      true,
+			FloatValue: result,
+			Type:       attribute.TypeID.AttributeTypeID,
+		}, nil
+	}
+
+	return Value{}, fmt.Errorf("%w: %d", errAttributeTypeNotSupported, attribute.TypeID.AttributeTypeID)
+}
+
+// propagateDerived recomputes formulas which use attribute as an input.
+func (s *Repository) propagateDerived(ctx context.Context, attributeID int64, itemID int64) error {
+	for _, dependentID := range derivedAttributes.Dependents(attributeID) {
+		dependent, err := s.Attribute(ctx, dependentID)
+		if err != nil {
+			return err
+		}
+
+		if dependent == nil || !dependent.TypeID.Valid {
+			continue
+		}
+
+		_, err = s.updateAttributeActualValue(ctx, dependent, itemID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
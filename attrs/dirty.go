@@ -0,0 +1,277 @@
+package attrs
+
+import (
+	"context"
+	"math"
+
+	"github.com/autowp/goautowp/schema"
+	"github.com/autowp/goautowp/util"
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	dirtyValueLeaseMinutes      = 10
+	dirtyValueMaxBackoffMinutes = 24 * 60
+)
+
+// dirtyValuesOnConflict bumps version of already queued value and makes it available for processing immediately.
+func dirtyValuesOnConflict() exp.ConflictExpression {
+	return goqu.DoUpdate(
+		schema.AttrsDirtyValuesTableAttributeIDColName+","+schema.AttrsDirtyValuesTableItemIDColName,
+		goqu.Record{
+			schema.AttrsDirtyValuesTableVersionColName: goqu.L(
+				"? + 1", goqu.C(schema.AttrsDirtyValuesTableVersionColName),
+			),
+			schema.AttrsDirtyValuesTableAttemptsColName:      0,
+			schema.AttrsDirtyValuesTableNextAttemptAtColName: goqu.L("NOW()"),
+		},
+	)
+}
+
+// itemDepth is a distance from the item to the farthest root of item_parent graph.
+func itemDepth(itemIDCol exp.IdentifierExpression) exp.SQLFunctionExpression {
+	return goqu.COALESCE(
+		goqu.Select(goqu.MAX(schema.ItemParentCacheTableDiffCol)).
+			From(schema.ItemParentCacheTable).
+			Where(schema.ItemParentCacheTableItemIDCol.Eq(itemIDCol)),
+		0,
+	)
+}
+
+// MarkDirtyValues queues recalculation of actual value of attribute for items.
+// Repeated marks of the same pair are deduplicated, only version is incremented.
+func (s *Repository) MarkDirtyValues(ctx context.Context, attributeID int64, itemIDs []int64) error {
+	if len(itemIDs) == 0 {
+		return nil
+	}
+
+	var depthRows []struct {
+		ItemID int64 `db:"item_id"`
+		Depth  int64 `db:"depth"`
+	}
+
+	err := s.db.Select(
+		schema.ItemParentCacheTableItemIDCol.As("item_id"),
+		goqu.MAX(schema.ItemParentCacheTableDiffCol).As("depth"),
+	).
+		From(schema.ItemParentCacheTable).
+		Where(schema.ItemParentCacheTableItemIDCol.In(itemIDs)).
+		GroupBy(schema.ItemParentCacheTableItemIDCol).
+		ScanStructsContext(ctx, &depthRows)
+	if err != nil {
+		return err
+	}
+
+	depths := make(map[int64]int64, len(depthRows))
+	for _, row := range depthRows {
+		depths[row.ItemID] = row.Depth
+	}
+
+	records := make([]goqu.Record, 0, len(itemIDs))
+	for _, itemID := range itemIDs {
+		records = append(records, goqu.Record{
+			schema.AttrsDirtyValuesTableAttributeIDColName: attributeID,
+			schema.AttrsDirtyValuesTableItemIDColName:      itemID,
+			schema.AttrsDirtyValuesTableDepthColName:       depths[itemID],
+		})
+	}
+
+	_, err = util.ExecAndRetryOnDeadlock(ctx,
+		s.db.Insert(schema.AttrsDirtyValuesTable).Rows(records).OnConflict(dirtyValuesOnConflict()).Executor(),
+	)
+
+	return err
+}
+
+// MarkAllDirtyValues queues recalculation of all actual values backed by user values
+// and formulas, which have inputs provided by users.
+func (s *Repository) MarkAllDirtyValues(ctx context.Context) error {
+	cols := []any{
+		schema.AttrsDirtyValuesTableAttributeIDColName,
+		schema.AttrsDirtyValuesTableItemIDColName,
+		schema.AttrsDirtyValuesTableDepthColName,
+	}
+
+	_, err := s.db.Insert(schema.AttrsDirtyValuesTable).
+		Cols(cols...).
+		FromQuery(
+			s.db.Select(
+				schema.AttrsUserValuesTableAttributeIDCol,
+				schema.AttrsUserValuesTableItemIDCol,
+				itemDepth(schema.AttrsUserValuesTableItemIDCol),
+			).
+				Distinct().
+				From(schema.AttrsUserValuesTable),
+		).
+		OnConflict(dirtyValuesOnConflict()).
+		Executor().ExecContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	for _, attributeID := range derivedAttributes.Order() {
+		formula, _ := derivedAttributes.Formula(attributeID)
+
+		_, err = s.db.Insert(schema.AttrsDirtyValuesTable).
+			Cols(cols...).
+			FromQuery(
+				s.db.Select(
+					goqu.V(attributeID),
+					schema.AttrsUserValuesTableItemIDCol,
+					itemDepth(schema.AttrsUserValuesTableItemIDCol),
+				).
+					Distinct().
+					From(schema.AttrsUserValuesTable).
+					Where(schema.AttrsUserValuesTableAttributeIDCol.In(formula.Inputs())),
+			).
+			OnConflict(dirtyValuesOnConflict()).
+			Executor().ExecContext(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// DirtyValuesCount returns size of recalculation backlog.
+func (s *Repository) DirtyValuesCount(ctx context.Context) (int64, error) {
+	return s.db.From(schema.AttrsDirtyValuesTable).CountContext(ctx)
+}
+
+// DirtyValues returns pending recalculations of item.
+func (s *Repository) DirtyValues(ctx context.Context, itemID int64) ([]schema.AttrsDirtyValueRow, error) {
+	rows := make([]schema.AttrsDirtyValueRow, 0)
+
+	err := s.db.Select(
+		schema.AttrsDirtyValuesTableAttributeIDCol,
+		schema.AttrsDirtyValuesTableItemIDCol,
+		schema.AttrsDirtyValuesTableVersionCol,
+		schema.AttrsDirtyValuesTableCreatedAtCol,
+	).
+		From(schema.AttrsDirtyValuesTable).
+		Where(schema.AttrsDirtyValuesTableItemIDCol.Eq(itemID)).
+		Order(schema.AttrsDirtyValuesTableAttributeIDCol.Asc()).
+		ScanStructsContext(ctx, &rows)
+
+	return rows, err
+}
+
+// claimDirtyValues takes up to limit values available for processing and leases them to the caller,
+// so concurrent workers skip them. Lease expires if the worker dies before it is done.
+// Items closer to the root of item_parent graph goes first, so parents are settled before their childs.
+func (s *Repository) claimDirtyValues(ctx context.Context, limit uint) ([]schema.AttrsDirtyValueRow, error) {
+	rows := make([]schema.AttrsDirtyValueRow, 0)
+
+	err := s.db.WithTx(func(tx *goqu.TxDatabase) error {
+		err := tx.Select(
+			schema.AttrsDirtyValuesTableAttributeIDCol,
+			schema.AttrsDirtyValuesTableItemIDCol,
+			schema.AttrsDirtyValuesTableVersionCol,
+			schema.AttrsDirtyValuesTableCreatedAtCol,
+			schema.AttrsDirtyValuesTableAttemptsCol,
+		).
+			From(schema.AttrsDirtyValuesTable).
+			Where(schema.AttrsDirtyValuesTableNextAttemptAtCol.Lte(goqu.L("NOW()"))).
+			Order(schema.AttrsDirtyValuesTableDepthCol.Asc(), schema.AttrsDirtyValuesTableCreatedAtCol.Asc()).
+			Limit(limit).
+			ForUpdate(exp.SkipLocked).
+			ScanStructsContext(ctx, &rows)
+		if err != nil {
+			return err
+		}
+
+		for _, row := range rows {
+			_, err = tx.Update(schema.AttrsDirtyValuesTable).
+				Set(goqu.Record{
+					schema.AttrsDirtyValuesTableNextAttemptAtColName: goqu.L(
+						"NOW() + INTERVAL ? MINUTE", dirtyValueLeaseMinutes,
+					),
+				}).
+				Where(
+					schema.AttrsDirtyValuesTableAttributeIDCol.Eq(row.AttributeID),
+					schema.AttrsDirtyValuesTableItemIDCol.Eq(row.ItemID),
+				).
+				Executor().ExecContext(ctx)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	return rows, err
+}
+
+// ProcessDirtyValues recalculates up to limit queued values and returns number of processed.
+// Failed values are postponed with exponential backoff and don't block the rest of the queue.
+func (s *Repository) ProcessDirtyValues(ctx context.Context, limit uint) (int, error) {
+	err := s.loadAttributesTree(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	rows, err := s.claimDirtyValues(ctx, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, row := range rows {
+		attribute, ok := s.attributes[row.AttributeID]
+		if ok && attribute.TypeID.Valid {
+			_, err = s.updateAttributeActualValue(ctx, attribute, row.ItemID)
+			if err != nil {
+				logrus.Errorf(
+					"attrs: failed to recalculate value of attribute %d of item %d: %s",
+					row.AttributeID, row.ItemID, err.Error(),
+				)
+
+				err = s.postponeDirtyValue(ctx, row)
+				if err != nil {
+					return 0, err
+				}
+
+				continue
+			}
+		}
+
+		// row marked again while processing stays in queue
+		_, err = util.ExecAndRetryOnDeadlock(ctx,
+			s.db.Delete(schema.AttrsDirtyValuesTable).Where(
+				schema.AttrsDirtyValuesTableAttributeIDCol.Eq(row.AttributeID),
+				schema.AttrsDirtyValuesTableItemIDCol.Eq(row.ItemID),
+				schema.AttrsDirtyValuesTableVersionCol.Eq(row.Version),
+			).Executor(),
+		)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return len(rows), nil
+}
+
+func (s *Repository) postponeDirtyValue(ctx context.Context, row schema.AttrsDirtyValueRow) error {
+	backoff := int(min(math.Pow(2, float64(row.Attempts)), dirtyValueMaxBackoffMinutes)) //nolint: mnd
+
+	// row marked again while processing is retried without delay
+	_, err := util.ExecAndRetryOnDeadlock(ctx,
+		s.db.Update(schema.AttrsDirtyValuesTable).
+			Set(goqu.Record{
+				schema.AttrsDirtyValuesTableAttemptsColName: goqu.L(
+					"? + 1", goqu.C(schema.AttrsDirtyValuesTableAttemptsColName),
+				),
+				schema.AttrsDirtyValuesTableNextAttemptAtColName: goqu.L("NOW() + INTERVAL ? MINUTE", backoff),
+			}).
+			Where(
+				schema.AttrsDirtyValuesTableAttributeIDCol.Eq(row.AttributeID),
+				schema.AttrsDirtyValuesTableItemIDCol.Eq(row.ItemID),
+				schema.AttrsDirtyValuesTableVersionCol.Eq(row.Version),
+			).Executor(),
+	)
+
+	return err
+}
@@ -0,0 +1,396 @@
+package attrs
+
+import (
+	"errors"
+	"fmt"
+	"maps"
+	"math"
+	"slices"
+	"strconv"
+)
+
+var (
+	errFormulaSyntax = errors.New("formula syntax error")
+	errFormulaCycle  = errors.New("formula dependency cycle")
+)
+
+// Formula is an arithmetic expression over actual values of other attributes.
+// Attributes are referenced by id in braces, e.g. `{33} / {72} * 1000`.
+// Supported are numbers, `pi`, parentheses, unary minus and `+ - * / ^` operators.
+type Formula struct {
+	source string
+	root   formulaNode
+	inputs []int64
+}
+
+type formulaNode interface {
+	eval(values map[int64]float64) (float64, bool)
+}
+
+type formulaNumber float64
+
+func (s formulaNumber) eval(_ map[int64]float64) (float64, bool) {
+	return float64(s), true
+}
+
+type formulaAttribute int64
+
+func (s formulaAttribute) eval(values map[int64]float64) (float64, bool) {
+	value, ok := values[int64(s)]
+
+	return value, ok
+}
+
+type formulaNegate struct {
+	operand formulaNode
+}
+
+func (s formulaNegate) eval(values map[int64]float64) (float64, bool) {
+	value, ok := s.operand.eval(values)
+
+	return -value, ok
+}
+
+type formulaBinary struct {
+	operator byte
+	left     formulaNode
+	right    formulaNode
+}
+
+func (s formulaBinary) eval(values map[int64]float64) (float64, bool) {
+	left, ok := s.left.eval(values)
+	if !ok {
+		return 0, false
+	}
+
+	right, ok := s.right.eval(values)
+	if !ok {
+		return 0, false
+	}
+
+	var result float64
+
+	switch s.operator {
+	case '+':
+		result = left + right
+	case '-':
+		result = left - right
+	case '*':
+		result = left * right
+	case '/':
+		if right == 0 {
+			return 0, false
+		}
+
+		result = left / right
+	case '^':
+		result = math.Pow(left, right)
+	default:
+		return 0, false
+	}
+
+	if math.IsNaN(result) || math.IsInf(result, 0) {
+		return 0, false
+	}
+
+	return result, true
+}
+
+// ParseFormula parses formula source.
+func ParseFormula(source string) (*Formula, error) {
+	parser := formulaParser{source: source, pos: 0, inputs: make([]int64, 0)}
+
+	root, err := parser.parseExpression()
+	if err != nil {
+		return nil, err
+	}
+
+	parser.skipSpaces()
+
+	if parser.pos < len(parser.source) {
+		return nil, parser.errorf("unexpected `%c`", parser.source[parser.pos])
+	}
+
+	return &Formula{source: source, root: root, inputs: parser.inputs}, nil
+}
+
+func (s *Formula) String() string {
+	return s.source
+}
+
+// Inputs returns ids of attributes referenced by formula.
+func (s *Formula) Inputs() []int64 {
+	return s.inputs
+}
+
+// Evaluate computes formula. Returns false when any of inputs is missing or result is not a finite number.
+func (s *Formula) Evaluate(values map[int64]float64) (float64, bool) {
+	return s.root.eval(values)
+}
+
+type formulaParser struct {
+	source string
+	pos    int
+	inputs []int64
+}
+
+func (s *formulaParser) errorf(format string, args ...any) error {
+	return fmt.Errorf("%w at %d in `%s`: %s", errFormulaSyntax, s.pos, s.source, fmt.Sprintf(format, args...))
+}
+
+func (s *formulaParser) skipSpaces() {
+	for s.pos < len(s.source) && s.source[s.pos] == ' ' {
+		s.pos++
+	}
+}
+
+func (s *formulaParser) accept(operators string) (byte, bool) {
+	s.skipSpaces()
+
+	if s.pos < len(s.source) {
+		for i := range len(operators) {
+			if s.source[s.pos] == operators[i] {
+				s.pos++
+
+				return operators[i], true
+			}
+		}
+	}
+
+	return 0, false
+}
+
+// expression = term { ("+" | "-") term } .
+func (s *formulaParser) parseExpression() (formulaNode, error) {
+	return s.parseBinary("+-", s.parseTerm)
+}
+
+// term = unary { ("*" | "/") unary } .
+func (s *formulaParser) parseTerm() (formulaNode, error) {
+	return s.parseBinary("*/", s.parseUnary)
+}
+
+func (s *formulaParser) parseBinary(operators string, operand func() (formulaNode, error)) (formulaNode, error) {
+	left, err := operand()
+	if err != nil {
+		return nil, err
+	}
+
+	for {
+		operator, ok := s.accept(operators)
+		if !ok {
+			return left, nil
+		}
+
+		right, err := operand()
+		if err != nil {
+			return nil, err
+		}
+
+		left = formulaBinary{operator: operator, left: left, right: right}
+	}
+}
+
+// unary = "-" unary | power .
+func (s *formulaParser) parseUnary() (formulaNode, error) {
+	if _, ok := s.accept("-"); ok {
+		operand, err := s.parseUnary()
+		if err != nil {
+			return nil, err
+		}
+
+		return formulaNegate{operand: operand}, nil
+	}
+
+	return s.parsePower()
+}
+
+// power = primary [ "^" unary ] .
+func (s *formulaParser) parsePower() (formulaNode, error) {
+	base, err := s.parsePrimary()
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := s.accept("^"); !ok {
+		return base, nil
+	}
+
+	exponent, err := s.parseUnary()
+	if err != nil {
+		return nil, err
+	}
+
+	return formulaBinary{operator: '^', left: base, right: exponent}, nil
+}
+
+// primary = number | "{" id "}" | "pi" | "(" expression ")" .
+func (s *formulaParser) parsePrimary() (formulaNode, error) {
+	s.skipSpaces()
+
+	if s.pos >= len(s.source) {
+		return nil, s.errorf("unexpected end")
+	}
+
+	switch char := s.source[s.pos]; {
+	case char == '(':
+		s.pos++
+
+		node, err := s.parseExpression()
+		if err != nil {
+			return nil, err
+		}
+
+		if _, ok := s.accept(")"); !ok {
+			return nil, s.errorf("`)` expected")
+		}
+
+		return node, nil
+
+	case char == '{':
+		s.pos++
+		start := s.pos
+
+		for s.pos < len(s.source) && s.source[s.pos] != '}' {
+			s.pos++
+		}
+
+		if s.pos >= len(s.source) {
+			return nil, s.errorf("`}` expected")
+		}
+
+		attributeID, err := strconv.ParseInt(s.source[start:s.pos], 10, 64)
+		if err != nil || attributeID <= 0 {
+			return nil, s.errorf("invalid attribute id `%s`", s.source[start:s.pos])
+		}
+
+		s.pos++
+
+		if !slices.Contains(s.inputs, attributeID) {
+			s.inputs = append(s.inputs, attributeID)
+		}
+
+		return formulaAttribute(attributeID), nil
+
+	case len(s.source) >= s.pos+2 && s.source[s.pos:s.pos+2] == "pi":
+		s.pos += 2
+
+		return formulaNumber(math.Pi), nil
+
+	case char == '.' || (char >= '0' && char <= '9'):
+		start := s.pos
+
+		for s.pos < len(s.source) && (s.source[s.pos] == '.' || (s.source[s.pos] >= '0' && s.source[s.pos] <= '9')) {
+			s.pos++
+		}
+
+		value, err := strconv.ParseFloat(s.source[start:s.pos], 64)
+		if err != nil {
+			return nil, s.errorf("invalid number `%s`", s.source[start:s.pos])
+		}
+
+		return formulaNumber(value), nil
+	}
+
+	return nil, s.errorf("unexpected `%c`", s.source[s.pos])
+}
+
+// FormulaGraph holds formulas of derived attributes and reverse dependencies between them.
+type FormulaGraph struct {
+	formulas   map[int64]*Formula
+	dependents map[int64][]int64
+	order      []int64
+}
+
+// NewFormulaGraph parses formulas and orders them so each formula goes after formulas of its inputs.
+func NewFormulaGraph(sources map[int64]string) (*FormulaGraph, error) {
+	graph := FormulaGraph{
+		formulas:   make(map[int64]*Formula, len(sources)),
+		dependents: make(map[int64][]int64),
+		order:      make([]int64, 0, len(sources)),
+	}
+
+	attributeIDs := slices.Sorted(maps.Keys(sources))
+
+	for _, attributeID := range attributeIDs {
+		formula, err := ParseFormula(sources[attributeID])
+		if err != nil {
+			return nil, fmt.Errorf("attribute %d: %w", attributeID, err)
+		}
+
+		graph.formulas[attributeID] = formula
+
+		for _, inputID := range formula.Inputs() {
+			graph.dependents[inputID] = append(graph.dependents[inputID], attributeID)
+		}
+	}
+
+	const (
+		unvisited = iota
+		visiting
+		visited
+	)
+
+	state := make(map[int64]int, len(sources))
+
+	var visit func(attributeID int64) error
+	visit = func(attributeID int64) error {
+		switch state[attributeID] {
+		case visited:
+			return nil
+		case visiting:
+			return fmt.Errorf("%w: attribute %d", errFormulaCycle, attributeID)
+		}
+
+		state[attributeID] = visiting
+
+		if formula, ok := graph.formulas[attributeID]; ok {
+			for _, inputID := range formula.Inputs() {
+				if err := visit(inputID); err != nil {
+					return err
+				}
+			}
+
+			graph.order = append(graph.order, attributeID)
+		}
+
+		state[attributeID] = visited
+
+		return nil
+	}
+
+	for _, attributeID := range attributeIDs {
+		if err := visit(attributeID); err != nil {
+			return nil, err
+		}
+	}
+
+	return &graph, nil
+}
+
+// MustNewFormulaGraph is like NewFormulaGraph but panics on invalid formulas.
+func MustNewFormulaGraph(sources map[int64]string) *FormulaGraph {
+	graph, err := NewFormulaGraph(sources)
+	if err != nil {
+		panic(err)
+	}
+
+	return graph
+}
+
+// Formula returns formula of derived attribute.
+func (s *FormulaGraph) Formula(attributeID int64) (*Formula, bool) {
+	formula, ok := s.formulas[attributeID]
+
+	return formula, ok
+}
+
+// Dependents returns derived attributes which use attribute as a direct input.
+func (s *FormulaGraph) Dependents(attributeID int64) []int64 {
+	return s.dependents[attributeID]
+}
+
+// Order returns derived attributes sorted so that inputs go before formulas using them.
+func (s *FormulaGraph) Order() []int64 {
+	return s.order
+}
@@ -0,0 +1,101 @@
+package attrs
+
+import (
+	"math"
+	"testing"
+
+	"github.com/autowp/goautowp/schema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFormulaEvaluate(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		source   string
+		expected float64
+	}{
+		{"1 + 2 * 3", 7},
+		{"(1 + 2) * 3", 9},
+		{"2 ^ 3 ^ 2", 512},
+		{"-2 ^ 2", -4},
+		{"10 / 4 - 0.5", 2},
+		{"{1} * {2} - -{1}", 15},
+		{"pi", math.Pi},
+	}
+
+	values := map[int64]float64{1: 3, 2: 4}
+
+	for _, test := range tests {
+		formula, err := ParseFormula(test.source)
+		require.NoError(t, err, test.source)
+
+		result, ok := formula.Evaluate(values)
+		require.True(t, ok, test.source)
+		require.InDelta(t, test.expected, result, 1e-9, test.source)
+	}
+}
+
+func TestFormulaInputs(t *testing.T) {
+	t.Parallel()
+
+	formula, err := ParseFormula("{28} ^ 2 * {29} * {28}")
+	require.NoError(t, err)
+	require.Equal(t, []int64{28, 29}, formula.Inputs())
+
+	_, ok := formula.Evaluate(map[int64]float64{28: 1})
+	require.False(t, ok)
+
+	formula, err = ParseFormula("{1} / {2}")
+	require.NoError(t, err)
+
+	_, ok = formula.Evaluate(map[int64]float64{1: 1, 2: 0})
+	require.False(t, ok)
+}
+
+func TestFormulaSyntaxErrors(t *testing.T) {
+	t.Parallel()
+
+	for _, source := range []string{"", "1 +", "(1", "{a}", "{1", "1 2", "1..2", "x"} {
+		_, err := ParseFormula(source)
+		require.ErrorIs(t, err, errFormulaSyntax, source)
+	}
+}
+
+func TestFormulaGraph(t *testing.T) {
+	t.Parallel()
+
+	graph, err := NewFormulaGraph(map[int64]string{
+		3: "{2} * 2",
+		2: "{1} + 1",
+		4: "{1} + {3}",
+	})
+	require.NoError(t, err)
+	require.Equal(t, []int64{2, 3, 4}, graph.Order())
+	require.Equal(t, []int64{2, 4}, graph.Dependents(1))
+	require.Equal(t, []int64{3}, graph.Dependents(2))
+
+	_, err = NewFormulaGraph(map[int64]string{
+		1: "{2}",
+		2: "{1} * 2",
+	})
+	require.ErrorIs(t, err, errFormulaCycle)
+}
+
+func TestDerivedAttributes(t *testing.T) {
+	t.Parallel()
+
+	formula, ok := derivedAttributes.Formula(schema.EngineVolumeAttr)
+	require.True(t, ok)
+
+	// 2.0 litre engine with 86 × 86 mm cylinders
+	volume, ok := formula.Evaluate(map[int64]float64{
+		schema.EngineCylinderDiameter:                86,
+		schema.EngineStrokeAttr:                      86,
+		schema.EngineConfigurationCylindersCountAttr: 4,
+	})
+	require.True(t, ok)
+	require.InDelta(t, 1998, volume, 1)
+
+	require.Contains(t, derivedAttributes.Dependents(schema.CurbWeightAttr), schema.PowerToWeightAttr)
+}
@@ -11,6 +11,7 @@ import (
 
 type Renderer interface {
 	Render(attribute *AttributeRow, values map[int64]string, units map[int64]I18nUnit) template.HTML
+	Components(attribute *AttributeRow, values map[int64]string, units map[int64]I18nUnit) *Component
 }
 
 func unitSuffixHTML(name, abbr string) template.HTML {
@@ -63,6 +64,30 @@ func (s BootVolume) Render(
 	return res
 }
 
+func (s BootVolume) Components(
+	attribute *AttributeRow,
+	values map[int64]string,
+	units map[int64]I18nUnit,
+) *Component {
+	minValue, minValueOk := values[s.Min]
+	maxValue, maxValueOk := values[s.Max]
+
+	if !minValueOk || len(minValue) == 0 {
+		return nil
+	}
+
+	result := minValue
+	if maxValueOk && len(maxValue) > 0 && minValue != maxValue {
+		result += "–" + maxValue
+	}
+
+	return &Component{
+		Value: result,
+		Unit:  componentUnit(attribute, units),
+		Parts: componentParts(values, s.Min, s.Max),
+	}
+}
+
 type DefaultValue struct{}
 
 func (s DefaultValue) Render(
@@ -82,17 +107,29 @@ func (s DefaultValue) Render(
 	return util.HTMLEscapeString(value) + renderUnitSuffix(attribute, units)
 }
 
+func (s DefaultValue) Components(
+	attribute *AttributeRow,
+	values map[int64]string,
+	units map[int64]I18nUnit,
+) *Component {
+	value, ok := values[attribute.ID]
+	if !ok || value == "—" {
+		return nil
+	}
+
+	return &Component{
+		Value: value,
+		Unit:  componentUnit(attribute, units),
+	}
+}
+
 type EngineConfiguration struct {
 	CylindersCount  int64
 	CylindersLayout int64
 	ValvesCount     int64
 }
 
-func (s EngineConfiguration) Render(
-	_ *AttributeRow,
-	values map[int64]string,
-	_ map[int64]I18nUnit,
-) template.HTML {
+func (s EngineConfiguration) text(values map[int64]string) string {
 	cylinders, cylindersOk := values[s.CylindersCount]
 	layout, layoutOk := values[s.CylindersLayout]
 	valves, valvesOk := values[s.ValvesCount]
@@ -117,7 +154,31 @@ func (s EngineConfiguration) Render(
 		result += "/" + valves
 	}
 
-	return util.HTMLEscapeString(result)
+	return result
+}
+
+func (s EngineConfiguration) Render(
+	_ *AttributeRow,
+	values map[int64]string,
+	_ map[int64]I18nUnit,
+) template.HTML {
+	return util.HTMLEscapeString(s.text(values))
+}
+
+func (s EngineConfiguration) Components(
+	_ *AttributeRow,
+	values map[int64]string,
+	_ map[int64]I18nUnit,
+) *Component {
+	result := s.text(values)
+	if len(result) == 0 {
+		return nil
+	}
+
+	return &Component{
+		Value: result,
+		Parts: componentParts(values, s.CylindersLayout, s.CylindersCount, s.ValvesCount),
+	}
 }
 
 type EnginePlacement struct {
@@ -146,6 +207,22 @@ func (s EnginePlacement) Render(
 	return template.HTML(strings.Join(array, ", ")) //nolint: gosec
 }
 
+func (s EnginePlacement) Components(
+	attribute *AttributeRow,
+	values map[int64]string,
+	units map[int64]I18nUnit,
+) *Component {
+	result := string(s.Render(attribute, values, units))
+	if len(result) == 0 {
+		return nil
+	}
+
+	return &Component{
+		Value: result,
+		Parts: componentParts(values, s.Placement, s.Orientation),
+	}
+}
+
 type FuelTank struct {
 	Primary   int64
 	Secondary int64
@@ -178,17 +255,47 @@ func (s FuelTank) Render(
 	return res
 }
 
+func (s FuelTank) Components(
+	_ *AttributeRow,
+	values map[int64]string,
+	units map[int64]I18nUnit,
+) *Component {
+	primary, primaryOk := values[s.Primary]
+	secondary, secondaryOk := values[s.Secondary]
+
+	result := ""
+
+	if primaryOk && len(primary) > 0 {
+		result = primary
+	}
+
+	if secondaryOk && len(secondary) > 0 {
+		result += "+" + secondary
+	}
+
+	if len(result) == 0 {
+		return nil
+	}
+
+	var unit *ComponentUnit
+	if i18nUnit, ok := units[schema.FuelTankPrimaryAttr]; ok {
+		unit = &ComponentUnit{Name: i18nUnit.Name, Abbr: i18nUnit.Abbr}
+	}
+
+	return &Component{
+		Value: result,
+		Unit:  unit,
+		Parts: componentParts(values, s.Primary, s.Secondary),
+	}
+}
+
 type Gearbox struct {
 	Type  int64
 	Gears int64
 	Name  int64
 }
 
-func (s Gearbox) Render(
-	_ *AttributeRow,
-	values map[int64]string,
-	_ map[int64]I18nUnit,
-) template.HTML {
+func (s Gearbox) text(values map[int64]string) string {
 	typeVal, typeOk := values[s.Type]
 	gears, gearsOk := values[s.Gears]
 	name, nameOk := values[s.Name]
@@ -214,7 +321,31 @@ func (s Gearbox) Render(
 		}
 	}
 
-	return util.HTMLEscapeString(result)
+	return result
+}
+
+func (s Gearbox) Render(
+	_ *AttributeRow,
+	values map[int64]string,
+	_ map[int64]I18nUnit,
+) template.HTML {
+	return util.HTMLEscapeString(s.text(values))
+}
+
+func (s Gearbox) Components(
+	_ *AttributeRow,
+	values map[int64]string,
+	_ map[int64]I18nUnit,
+) *Component {
+	result := s.text(values)
+	if len(result) == 0 {
+		return nil
+	}
+
+	return &Component{
+		Value: result,
+		Parts: componentParts(values, s.Type, s.Gears, s.Name),
+	}
 }
 
 type Wheel struct {
@@ -224,11 +355,7 @@ type Wheel struct {
 	Rimwidth   int64
 }
 
-func (s Wheel) Render(
-	_ *AttributeRow,
-	values map[int64]string,
-	_ map[int64]I18nUnit,
-) template.HTML {
+func (s Wheel) names(values map[int64]string) (string, string) {
 	tyreWidth, tyreWidthOk := values[s.TyreWidth]
 	tyreSeries, tyreSeriesOk := values[s.TyreSeries]
 	radius, radiusOk := values[s.Radius]
@@ -254,5 +381,32 @@ func (s Wheel) Render(
 		)
 	}
 
+	return diskName, tyreName
+}
+
+func (s Wheel) Render(
+	_ *AttributeRow,
+	values map[int64]string,
+	_ map[int64]I18nUnit,
+) template.HTML {
+	diskName, tyreName := s.names(values)
+
 	return util.HTMLEscapeString(diskName) + "<br />" + util.HTMLEscapeString(tyreName)
 }
+
+func (s Wheel) Components(
+	_ *AttributeRow,
+	values map[int64]string,
+	_ map[int64]I18nUnit,
+) *Component {
+	diskName, tyreName := s.names(values)
+
+	if len(diskName) == 0 && len(tyreName) == 0 {
+		return nil
+	}
+
+	return &Component{
+		Value: strings.TrimSpace(diskName + "\n" + tyreName),
+		Parts: componentParts(values, s.Rimwidth, s.Radius, s.TyreWidth, s.TyreSeries),
+	}
+}
@@ -1251,6 +1251,9 @@ func (s *Repository) updateActualValue(ctx context.Context, attributeID, itemID
 	return nil
 }
 
+// UpdateActualValues recomputes actual values of item.
+// Formulas of derived attributes are re-evaluated through propagateDerived only when their inputs change,
+// except ones which can take a value from user or engine of the item.
 func (s *Repository) UpdateActualValues(ctx context.Context, itemID int64) error {
 	err := s.loadAttributesTree(ctx)
 	if err != nil {
@@ -1258,7 +1261,7 @@ func (s *Repository) UpdateActualValues(ctx context.Context, itemID int64) error
 	}
 
 	for _, attribute := range s.attributes {
-		if attribute.TypeID.Valid {
+		if attribute.TypeID.Valid && !isDerivedAttributeID(attribute.ID) {
 			_, err = s.updateAttributeActualValue(ctx, attribute, itemID)
 			if err != nil {
 				return err
@@ -1266,9 +1269,67 @@ func (s *Repository) UpdateActualValues(ctx context.Context, itemID int64) error
 		}
 	}
 
+	derivedIDs, err := s.derivedAttributesWithOwnSources(ctx, itemID)
+	if err != nil {
+		return err
+	}
+
+	for _, attributeID := range derivedIDs {
+		attribute, ok := s.attributes[attributeID]
+		if !ok || !attribute.TypeID.Valid {
+			continue
+		}
+
+		_, err = s.updateAttributeActualValue(ctx, attribute, itemID)
+		if err != nil {
+			return err
+		}
+	}
+
 	return nil
 }
 
+// derivedAttributesWithOwnSources returns derived attributes of the item, which values may come
+// from user values or engine instead of formula, in order of their dependencies.
+func (s *Repository) derivedAttributesWithOwnSources(ctx context.Context, itemID int64) ([]int64, error) {
+	var engineItemID sql.NullInt64
+
+	_, err := s.db.Select(schema.ItemTableEngineItemIDCol).
+		From(schema.ItemTable).
+		Where(schema.ItemTableIDCol.Eq(itemID)).
+		ScanValContext(ctx, &engineItemID)
+	if err != nil {
+		return nil, err
+	}
+
+	if engineItemID.Valid {
+		return derivedAttributes.Order(), nil
+	}
+
+	var userAttributeIDs []int64
+
+	err = s.db.Select(schema.AttrsUserValuesTableAttributeIDCol).Distinct().
+		From(schema.AttrsUserValuesTable).
+		Where(
+			schema.AttrsUserValuesTableItemIDCol.Eq(itemID),
+			schema.AttrsUserValuesTableAttributeIDCol.In(derivedAttributes.Order()),
+		).
+		ScanValsContext(ctx, &userAttributeIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]int64, 0, len(userAttributeIDs))
+
+	for _, attributeID := range derivedAttributes.Order() {
+		if slices.Contains(userAttributeIDs, attributeID) {
+			result = append(result, attributeID)
+		}
+	}
+
+	return result, nil
+}
+
 func (s *Repository) updateAttributeActualValue(
 	ctx context.Context, attribute *schema.AttrsAttributeRow, itemID int64,
 ) (bool, error) {
@@ -1284,13 +1345,23 @@ func (s *Repository) updateAttributeActualValue(
 		}
 	}
 
-	if !actualValue.Valid {
+	derived := isDerivedAttributeID(attribute.ID)
+
+	// derived attributes are computed from own inputs of the item rather than inherited from parents
+	if !actualValue.Valid && !derived {
 		actualValue, err = s.calcInheritedValue(ctx, attribute.ID, itemID)
 		if err != nil {
 			return false, fmt.Errorf("calcInheritedValue(%d, %d): %w", attribute.ID, itemID, err)
 		}
 	}
 
+	if !actualValue.Valid {
+		actualValue, err = s.calcDerivedValue(ctx, attribute, itemID)
+		if err != nil {
+			return false, fmt.Errorf("calcDerivedValue(%d, %d): %w", attribute.ID, itemID, err)
+		}
+	}
+
 	ctx = context.WithoutCancel(ctx)
 
 	somethingChanged, err := s.setActualValue(ctx, attribute, itemID, actualValue)
@@ -1299,9 +1370,11 @@ func (s *Repository) updateAttributeActualValue(
 	}
 
 	if somethingChanged {
-		err = s.propagateInheritance(ctx, attribute, itemID)
-		if err != nil {
-			return false, fmt.Errorf("propagateInheritance(%d, %d): %w", attribute.ID, itemID, err)
+		if !derived {
+			err = s.propagateInheritance(ctx, attribute, itemID)
+			if err != nil {
+				return false, fmt.Errorf("propagateInheritance(%d, %d): %w", attribute.ID, itemID, err)
+			}
 		}
 
 		err = s.propagateEngine(ctx, attribute, itemID)
@@ -1309,6 +1382,11 @@ func (s *Repository) updateAttributeActualValue(
 			return false, fmt.Errorf("propagateEngine(%d, %d): %w", attribute.ID, itemID, err)
 		}
 
+		err = s.propagateDerived(ctx, attribute.ID, itemID)
+		if err != nil {
+			return false, fmt.Errorf("propagateDerived(%d, %d): %w", attribute.ID, itemID, err)
+		}
+
 		err = s.refreshConflictFlag(ctx, attribute.ID, itemID)
 		if err != nil {
 			return false, fmt.Errorf("refreshConflictFlag(%d, %d): %w", attribute.ID, itemID, err)
@@ -2396,6 +2474,7 @@ func (s *Repository) SetUserValue( //nolint: maintidx
 	return somethingChanged || valueChanged, nil
 }
 
+// propagateInheritance queues recalculation of childs, they will be processed by ProcessDirtyValues.
 func (s *Repository) propagateInheritance(
 	ctx context.Context, attribute *schema.AttrsAttributeRow, itemID int64,
 ) error {
@@ -2409,41 +2488,7 @@ func (s *Repository) propagateInheritance(
 		return err
 	}
 
-	ctx = context.WithoutCancel(ctx)
-
-	for _, childID := range childIDs {
-		// update only if row use inheritance
-		haveValue, err := s.haveOwnAttributeValue(ctx, attribute.ID, childID)
-		if err != nil {
-			return err
-		}
-
-		if !haveValue {
-			value, err := s.calcInheritedValue(ctx, attribute.ID, childID)
-			if err != nil {
-				return err
-			}
-
-			changed, err := s.setActualValue(ctx, attribute, childID, value)
-			if err != nil {
-				return err
-			}
-
-			if changed {
-				err = s.propagateInheritance(ctx, attribute, childID)
-				if err != nil {
-					return err
-				}
-
-				err = s.propagateEngine(ctx, attribute, childID)
-				if err != nil {
-					return err
-				}
-			}
-		}
-	}
-
-	return nil
+	return s.MarkDirtyValues(context.WithoutCancel(ctx), attribute.ID, childIDs)
 }
 
 func (s *Repository) haveOwnAttributeValue(
@@ -2463,6 +2508,7 @@ func (s *Repository) haveOwnAttributeValue(
 	return success && exists, err
 }
 
+// propagateEngine queues recalculation of vehicles with this engine.
 func (s *Repository) propagateEngine(
 	ctx context.Context,
 	attribute *schema.AttrsAttributeRow,
@@ -2490,21 +2536,7 @@ func (s *Repository) propagateEngine(
 		return err
 	}
 
-	ctx = context.WithoutCancel(ctx)
-
-	for _, vehicleID := range vehicleIDs {
-		_, err = s.updateAttributeActualValue(ctx, attribute, vehicleID)
-		if err != nil {
-			return fmt.Errorf(
-				"%w: updateAttributeActualValue(%d, %d)",
-				errAttributeNotFound,
-				attribute.ID,
-				itemID,
-			)
-		}
-	}
-
-	return nil
+	return s.MarkDirtyValues(context.WithoutCancel(ctx), attribute.ID, vehicleIDs)
 }
 
 func (s *Repository) refreshConflictFlag(ctx context.Context, attributeID, itemID int64) error {
@@ -2837,35 +2869,6 @@ func (s *Repository) MoveUserValues(ctx context.Context, srcItemID, destItemID i
 	return nil
 }
 
-func (s *Repository) UpdateAllActualValues(ctx context.Context) error {
-	err := s.loadAttributesTree(ctx)
-	if err != nil {
-		return err
-	}
-
-	var itemIDs []int64
-
-	err = s.db.Select(schema.AttrsUserValuesTableItemIDCol).Distinct().
-		From(schema.AttrsUserValuesTable).
-		ScanValsContext(ctx, &itemIDs)
-	if err != nil {
-		return err
-	}
-
-	for _, itemID := range itemIDs {
-		for _, attribute := range s.attributes {
-			if attribute.TypeID.Valid {
-				_, err = s.updateAttributeActualValue(ctx, attribute, itemID)
-				if err != nil {
-					return err
-				}
-			}
-		}
-	}
-
-	return nil
-}
-
 func (s *Repository) UpdateInheritedValues(ctx context.Context, itemID int64) error {
 	err := s.loadAttributesTree(ctx)
 	if err != nil {
@@ -3130,8 +3133,14 @@ func (s *Repository) Specifications( //nolint: maintidx
 
 		result = append(result, CarSpecTableItem{
 			ID:                 itemID,
+			Name:               name,
 			NameHTML:           template.HTML(name),      //nolint: gosec
 			YearsHTML:          template.HTML(yearsHTML), //nolint: gosec
+			Today:              util.NullBoolToBoolPtr(car.Today),
+			BeginYear:          util.NullInt32ToScalar(car.BeginYear),
+			BeginMonth:         util.NullInt16ToScalar(car.BeginMonth),
+			EndYear:            util.NullInt32ToScalar(car.EndYear),
+			EndMonth:           util.NullInt16ToScalar(car.EndMonth),
 			TopPictureURL:      topPictureURL,
 			TopPictureImage:    topPicture,
 			BottomPictureURL:   bottomPictureURL,
@@ -3559,6 +3568,18 @@ func (s *Repository) ItemsActualValues(
 	return values, nil
 }
 
+// ItemsActualValuesText returns actual values of items formatted for language.
+func (s *Repository) ItemsActualValuesText(
+	ctx context.Context, itemIDs []int64, lang string,
+) (map[int64]map[int64]string, error) {
+	values, err := s.ItemsActualValues(ctx, itemIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	return s.actualValuesToText(ctx, values, lang)
+}
+
 func (s *Repository) specPicture(
 	ctx context.Context, itemID int64, orderBy pictures.OrderBy,
 ) (*CarSpecTableItemImage, string, error) {
@@ -1,7 +1,6 @@
 package attrs
 
 import (
-	"context"
 	"database/sql"
 	"testing"
 
@@ -12,7 +11,7 @@ import (
 	"github.com/autowp/goautowp/i18nbundle"
 	"github.com/autowp/goautowp/image/storage"
 	"github.com/autowp/goautowp/items"
-	"github.com/autowp/goautowp/messaging"
+	"github.com/autowp/goautowp/notifications"
 	"github.com/autowp/goautowp/pictures"
 	"github.com/autowp/goautowp/query"
 	"github.com/autowp/goautowp/textstorage"
@@ -66,21 +65,12 @@ func createRepository(t *testing.T) *Repository {
 		cfg.MessageInterval,
 		imageStorage,
 	)
-	i, err := i18nbundle.New()
-	require.NoError(t, err)
-
-	messagingRepo := messaging.NewRepository(
-		goquDB,
-		func(_ context.Context, _ int64, _ int64, _ string) error {
-			return nil
-		},
-		i,
-	)
+	notifier := notifications.NewTestNotifier(t, goquDB, usersRepository, cfg.Notifications)
 	hostsManager := hosts.NewManager(cfg.Languages)
 	commentsRepository := comments.NewRepository(
 		goquDB,
 		usersRepository,
-		messagingRepo,
+		notifier,
 		hostsManager,
 	)
 	picturesRepository := pictures.NewRepository(
@@ -73,8 +73,14 @@ type CarSpecTableItemImage struct {
 
 type CarSpecTableItem struct {
 	ID                 int64
+	Name               string
 	NameHTML           template.HTML
 	YearsHTML          template.HTML
+	Today              *bool
+	BeginYear          int32
+	BeginMonth         int16
+	EndYear            int32
+	EndMonth           int16
 	TopPictureURL      string
 	TopPictureImage    *CarSpecTableItemImage
 	BottomPictureURL   string
@@ -0,0 +1,581 @@
+package attrs
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+
+	"github.com/autowp/goautowp/schema"
+	"github.com/doug-martin/goqu/v9"
+)
+
+type ValueWarningCode int
+
+const (
+	ValueWarningCodeOutOfPlausibleRange ValueWarningCode = iota + 1
+	ValueWarningCodePrecision
+	ValueWarningCodeMissingDependency
+	ValueWarningCodeSiblingsOutlier
+)
+
+const (
+	minPlausibilitySiblings      = 3
+	plausibilityParentsBatchSize = 100
+	precisionEpsilon             = 1e-9
+)
+
+type ValueRange struct {
+	Min float64
+	Max float64
+}
+
+func (s ValueRange) Contains(value float64) bool {
+	return value >= s.Min && value <= s.Max
+}
+
+// ValidationRule describes constraints of numeric attribute.
+// Values outside of Range are rejected, values outside of Plausible range are accepted with warning.
+type ValidationRule struct {
+	Range     *ValueRange
+	Plausible *ValueRange
+	Requires  []int64
+}
+
+// PlausibilityCheck compares Numerator/Denominator ratio (or Numerator value, when Denominator is 0)
+// of the item with the same metric of its siblings in the same parent.
+type PlausibilityCheck struct {
+	Numerator   int64
+	Denominator int64
+	MaxFactor   float64
+}
+
+type ValueWarning struct {
+	ItemID             int64
+	AttributeID        int64
+	RelatedAttributeID int64
+	Code               ValueWarningCode
+	Message            string
+}
+
+var (
+	validationRules = map[int64]ValidationRule{
+		schema.LengthAttr: {
+			Range:     &ValueRange{Min: 1, Max: 100000},
+			Plausible: &ValueRange{Min: 1000, Max: 30000},
+		},
+		schema.WidthAttr: {
+			Range:     &ValueRange{Min: 1, Max: 20000},
+			Plausible: &ValueRange{Min: 500, Max: 5000},
+		},
+		schema.HeightAttr: {
+			Range:     &ValueRange{Min: 1, Max: 20000},
+			Plausible: &ValueRange{Min: 500, Max: 5000},
+		},
+		schema.ClearanceAttr: {
+			Range:     &ValueRange{Min: 0, Max: 5000},
+			Plausible: &ValueRange{Min: 50, Max: 1000},
+		},
+		schema.TurningDiameterAttr: {
+			Range:     &ValueRange{Min: 0.1, Max: 200},
+			Plausible: &ValueRange{Min: 4, Max: 40},
+		},
+		schema.EngineConfigurationCylindersCountAttr: {
+			Range:     &ValueRange{Min: 1, Max: 64},
+			Plausible: &ValueRange{Min: 1, Max: 24},
+		},
+		schema.EngineConfigurationValvesCountAttr: {
+			Range:     &ValueRange{Min: 1, Max: 16},
+			Plausible: &ValueRange{Min: 1, Max: 6},
+		},
+		schema.EngineCylinderDiameter: {
+			Range:     &ValueRange{Min: 1, Max: 3000},
+			Plausible: &ValueRange{Min: 30, Max: 300},
+		},
+		schema.EngineStrokeAttr: {
+			Range:     &ValueRange{Min: 1, Max: 3000},
+			Plausible: &ValueRange{Min: 30, Max: 300},
+		},
+		schema.EngineVolumeAttr: {
+			Range:     &ValueRange{Min: 1, Max: 1000000},
+			Plausible: &ValueRange{Min: 50, Max: 30000},
+		},
+		schema.EnginePowerAttr: {
+			Range:     &ValueRange{Min: 0.1, Max: 100000},
+			Plausible: &ValueRange{Min: 1, Max: 2000},
+		},
+		schema.MaxSpeedAttr: {
+			Range:     &ValueRange{Min: 1, Max: 2000},
+			Plausible: &ValueRange{Min: 10, Max: 500},
+		},
+		schema.AccelerationTo100KmhAttr: {
+			Range:     &ValueRange{Min: 0.1, Max: 1000},
+			Plausible: &ValueRange{Min: 1.5, Max: 100},
+			Requires:  []int64{schema.EnginePowerAttr},
+		},
+		schema.AccelerationTo60MphAttr: {
+			Range:     &ValueRange{Min: 0.1, Max: 1000},
+			Plausible: &ValueRange{Min: 1.5, Max: 100},
+			Requires:  []int64{schema.EnginePowerAttr},
+		},
+		schema.CurbWeightAttr: {
+			Range:     &ValueRange{Min: 1, Max: 1000000},
+			Plausible: &ValueRange{Min: 100, Max: 50000},
+		},
+		schema.FuelTankPrimaryAttr: {
+			Range:     &ValueRange{Min: 0.1, Max: 10000},
+			Plausible: &ValueRange{Min: 5, Max: 1500},
+		},
+		schema.FuelTankSecondaryAttr: {
+			Range:     &ValueRange{Min: 0.1, Max: 10000},
+			Plausible: &ValueRange{Min: 5, Max: 1500},
+		},
+		schema.FuelConsumptionMixedAttr: {
+			Range:     &ValueRange{Min: 0.1, Max: 1000},
+			Plausible: &ValueRange{Min: 1, Max: 60},
+		},
+		schema.EmissionsAttr: {
+			Range:     &ValueRange{Min: 0, Max: 10000},
+			Plausible: &ValueRange{Min: 0, Max: 1000},
+		},
+	}
+
+	plausibilityChecks = []PlausibilityCheck{
+		{Numerator: schema.EnginePowerAttr, Denominator: schema.CurbWeightAttr, MaxFactor: 2.5},
+		{Numerator: schema.MaxSpeedAttr, MaxFactor: 1.5},
+		{Numerator: schema.CurbWeightAttr, MaxFactor: 2},
+	}
+)
+
+func numericValue(typeID schema.AttrsAttributeTypeID, value Value) (float64, bool) {
+	if !value.Valid || value.IsEmpty {
+		return 0, false
+	}
+
+	switch typeID { //nolint: exhaustive
+	case schema.AttrsAttributeTypeIDInteger:
+		return float64(value.IntValue), true
+	case schema.AttrsAttributeTypeIDFloat:
+		return value.FloatValue, true
+	}
+
+	return 0, false
+}
+
+func exceedsPrecision(value float64, precision int32) bool {
+	multiplier := math.Pow10(int(precision))
+
+	return math.Abs(math.Round(value*multiplier)-value*multiplier) > precisionEpsilon
+}
+
+// ValidateUserValue returns list of violations, which prevents value from being saved.
+func (s *Repository) ValidateUserValue(ctx context.Context, attributeID int64, value Value) ([]string, error) {
+	attribute, err := s.Attribute(ctx, attributeID)
+	if err != nil {
+		return nil, err
+	}
+
+	if attribute == nil {
+		return nil, fmt.Errorf("%w: `%d`", errAttributeNotFound, attributeID)
+	}
+
+	rule, ok := validationRules[attributeID]
+	if !ok || rule.Range == nil {
+		return nil, nil
+	}
+
+	number, ok := numericValue(attribute.TypeID.AttributeTypeID, value)
+	if !ok {
+		return nil, nil
+	}
+
+	if !rule.Range.Contains(number) {
+		return []string{
+			fmt.Sprintf("value must be in range %g – %g", rule.Range.Min, rule.Range.Max),
+		}, nil
+	}
+
+	return nil, nil
+}
+
+// ValueWarnings returns problems of the value, which doesn't prevent it from being saved.
+func (s *Repository) ValueWarnings(
+	ctx context.Context, attributeID, itemID int64, value Value,
+) ([]ValueWarning, error) {
+	attribute, err := s.Attribute(ctx, attributeID)
+	if err != nil {
+		return nil, err
+	}
+
+	if attribute == nil {
+		return nil, fmt.Errorf("%w: `%d`", errAttributeNotFound, attributeID)
+	}
+
+	number, ok := numericValue(attribute.TypeID.AttributeTypeID, value)
+	if !ok {
+		return nil, nil
+	}
+
+	result := make([]ValueWarning, 0)
+
+	rule, hasRule := validationRules[attributeID]
+	if hasRule && rule.Plausible != nil && !rule.Plausible.Contains(number) {
+		result = append(result, ValueWarning{
+			ItemID:      itemID,
+			AttributeID: attributeID,
+			Code:        ValueWarningCodeOutOfPlausibleRange,
+			Message: fmt.Sprintf(
+				"value %g is outside of usual range %g – %g", number, rule.Plausible.Min, rule.Plausible.Max,
+			),
+		})
+	}
+
+	if attribute.TypeID.AttributeTypeID == schema.AttrsAttributeTypeIDFloat && attribute.Precision.Valid &&
+		exceedsPrecision(number, attribute.Precision.Int32) {
+		result = append(result, ValueWarning{
+			ItemID:      itemID,
+			AttributeID: attributeID,
+			Code:        ValueWarningCodePrecision,
+			Message: fmt.Sprintf(
+				"value %g has more than %d decimal places and will be displayed rounded",
+				number, attribute.Precision.Int32,
+			),
+		})
+	}
+
+	if hasRule {
+		for _, requiredID := range rule.Requires {
+			requiredValue, err := s.ActualValue(ctx, requiredID, itemID)
+			if err != nil {
+				return nil, err
+			}
+
+			if !requiredValue.Valid {
+				result = append(result, ValueWarning{
+					ItemID:             itemID,
+					AttributeID:        attributeID,
+					RelatedAttributeID: requiredID,
+					Code:               ValueWarningCodeMissingDependency,
+					Message:            fmt.Sprintf("value requires attribute %d to be specified", requiredID),
+				})
+			}
+		}
+	}
+
+	siblingsWarnings, err := s.siblingsWarnings(ctx, attributeID, itemID)
+	if err != nil {
+		return nil, err
+	}
+
+	return append(result, siblingsWarnings...), nil
+}
+
+func (s *Repository) siblingsWarnings(ctx context.Context, attributeID, itemID int64) ([]ValueWarning, error) {
+	result := make([]ValueWarning, 0)
+
+	var parentIDs []int64
+
+	err := s.db.Select(schema.ItemParentTableParentIDCol).
+		From(schema.ItemParentTable).
+		Where(schema.ItemParentTableItemIDCol.Eq(itemID)).
+		ScanValsContext(ctx, &parentIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(parentIDs) == 0 {
+		return result, nil
+	}
+
+	for _, check := range plausibilityChecks {
+		if check.Numerator != attributeID && check.Denominator != attributeID {
+			continue
+		}
+
+		warnings, err := s.plausibilityOutliers(ctx, check, parentIDs)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, warning := range warnings {
+			if warning.ItemID == itemID {
+				result = append(result, warning)
+			}
+		}
+	}
+
+	return result, nil
+}
+
+// numericActualValues returns actual values of integer or float attribute for the given items.
+func (s *Repository) numericActualValues(
+	ctx context.Context, attributeID int64, itemIDs []int64,
+) (map[int64]float64, error) {
+	result := make(map[int64]float64)
+
+	if len(itemIDs) == 0 {
+		return result, nil
+	}
+
+	attribute, err := s.Attribute(ctx, attributeID)
+	if err != nil {
+		return nil, err
+	}
+
+	if attribute == nil {
+		return nil, fmt.Errorf("%w: `%d`", errAttributeNotFound, attributeID)
+	}
+
+	valueTable, err := ValueTableByType(attribute.TypeID.AttributeTypeID)
+	if err != nil {
+		return nil, err
+	}
+
+	var rows []struct {
+		ItemID int64   `db:"item_id"`
+		Value  float64 `db:"value"`
+	}
+
+	err = s.db.Select(valueTable.ItemIDCol.As("item_id"), valueTable.ValueCol.As("value")).
+		From(valueTable.Table).
+		Where(
+			valueTable.AttributeIDCol.Eq(attributeID),
+			valueTable.ItemIDCol.In(itemIDs),
+			valueTable.ValueCol.IsNotNull(),
+		).
+		ScanStructsContext(ctx, &rows)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		result[row.ItemID] = row.Value
+	}
+
+	return result, nil
+}
+
+func (s *Repository) plausibilityMetrics(
+	ctx context.Context, check PlausibilityCheck, itemIDs []int64,
+) (map[int64]float64, error) {
+	numerators, err := s.numericActualValues(ctx, check.Numerator, itemIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	if check.Denominator == 0 {
+		return numerators, nil
+	}
+
+	denominators, err := s.numericActualValues(ctx, check.Denominator, itemIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[int64]float64, len(numerators))
+
+	for itemID, numerator := range numerators {
+		denominator, ok := denominators[itemID]
+		if ok && denominator > 0 {
+			result[itemID] = numerator / denominator
+		}
+	}
+
+	return result, nil
+}
+
+func median(values []float64) float64 {
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+
+	middle := len(sorted) / 2 //nolint: mnd
+	if len(sorted)%2 == 0 {
+		return (sorted[middle-1] + sorted[middle]) / 2 //nolint: mnd
+	}
+
+	return sorted[middle]
+}
+
+// plausibilityOutliers compares metric of each child of the parents with median of its siblings.
+func (s *Repository) plausibilityOutliers(
+	ctx context.Context, check PlausibilityCheck, parentIDs []int64,
+) ([]ValueWarning, error) {
+	var links []struct {
+		ParentID int64 `db:"parent_id"`
+		ItemID   int64 `db:"item_id"`
+	}
+
+	err := s.db.Select(schema.ItemParentTableParentIDCol, schema.ItemParentTableItemIDCol).
+		From(schema.ItemParentTable).
+		Where(schema.ItemParentTableParentIDCol.In(parentIDs)).
+		ScanStructsContext(ctx, &links)
+	if err != nil {
+		return nil, err
+	}
+
+	childIDsByParent := make(map[int64][]int64, len(parentIDs))
+	for _, link := range links {
+		childIDsByParent[link.ParentID] = append(childIDsByParent[link.ParentID], link.ItemID)
+	}
+
+	childIDs := make([]int64, 0, len(links))
+
+	for parentID, ids := range childIDsByParent {
+		if len(ids) <= minPlausibilitySiblings {
+			delete(childIDsByParent, parentID)
+
+			continue
+		}
+
+		childIDs = append(childIDs, ids...)
+	}
+
+	if len(childIDs) == 0 {
+		return nil, nil
+	}
+
+	allMetrics, err := s.plausibilityMetrics(ctx, check, childIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]ValueWarning, 0)
+
+	for _, parentID := range parentIDs {
+		metrics := make(map[int64]float64, len(childIDsByParent[parentID]))
+
+		for _, childID := range childIDsByParent[parentID] {
+			if metric, ok := allMetrics[childID]; ok {
+				metrics[childID] = metric
+			}
+		}
+
+		result = append(result, parentOutliers(check, parentID, metrics)...)
+	}
+
+	return result, nil
+}
+
+func parentOutliers(check PlausibilityCheck, parentID int64, metrics map[int64]float64) []ValueWarning {
+	result := make([]ValueWarning, 0)
+
+	for itemID, metric := range metrics {
+		siblings := make([]float64, 0, len(metrics))
+
+		for siblingID, siblingMetric := range metrics {
+			if siblingID != itemID {
+				siblings = append(siblings, siblingMetric)
+			}
+		}
+
+		if len(siblings) < minPlausibilitySiblings {
+			continue
+		}
+
+		siblingsMedian := median(siblings)
+		if siblingsMedian <= 0 {
+			continue
+		}
+
+		if metric > siblingsMedian*check.MaxFactor || metric < siblingsMedian/check.MaxFactor {
+			result = append(result, ValueWarning{
+				ItemID:             itemID,
+				AttributeID:        check.Numerator,
+				RelatedAttributeID: check.Denominator,
+				Code:               ValueWarningCodeSiblingsOutlier,
+				Message: fmt.Sprintf(
+					"value %g differs more than %g times from median %g of siblings in parent %d",
+					metric, check.MaxFactor, siblingsMedian, parentID,
+				),
+			})
+		}
+	}
+
+	return result
+}
+
+// SuspiciousValues walks through actual values of the catalogue and reports implausible ones.
+func (s *Repository) SuspiciousValues(ctx context.Context, callback func(ValueWarning) error) error {
+	for attributeID, rule := range validationRules {
+		if rule.Plausible == nil {
+			continue
+		}
+
+		attribute, err := s.Attribute(ctx, attributeID)
+		if err != nil {
+			return err
+		}
+
+		if attribute == nil {
+			continue
+		}
+
+		valueTable, err := ValueTableByType(attribute.TypeID.AttributeTypeID)
+		if err != nil {
+			return err
+		}
+
+		var rows []struct {
+			ItemID int64   `db:"item_id"`
+			Value  float64 `db:"value"`
+		}
+
+		err = s.db.Select(valueTable.ItemIDCol.As("item_id"), valueTable.ValueCol.As("value")).
+			From(valueTable.Table).
+			Where(
+				valueTable.AttributeIDCol.Eq(attributeID),
+				valueTable.ValueCol.IsNotNull(),
+				goqu.Or(
+					valueTable.ValueCol.Lt(rule.Plausible.Min),
+					valueTable.ValueCol.Gt(rule.Plausible.Max),
+				),
+			).
+			ScanStructsContext(ctx, &rows)
+		if err != nil {
+			return err
+		}
+
+		for _, row := range rows {
+			err = callback(ValueWarning{
+				ItemID:      row.ItemID,
+				AttributeID: attributeID,
+				Code:        ValueWarningCodeOutOfPlausibleRange,
+				Message: fmt.Sprintf(
+					"value %g is outside of usual range %g – %g", row.Value, rule.Plausible.Min, rule.Plausible.Max,
+				),
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	var parentIDs []int64
+
+	err := s.db.Select(schema.ItemParentTableParentIDCol).
+		From(schema.ItemParentTable).
+		GroupBy(schema.ItemParentTableParentIDCol).
+		Having(goqu.COUNT(goqu.Star()).Gt(minPlausibilitySiblings)).
+		ScanValsContext(ctx, &parentIDs)
+	if err != nil {
+		return err
+	}
+
+	for _, check := range plausibilityChecks {
+		for batch := range slices.Chunk(parentIDs, plausibilityParentsBatchSize) {
+			warnings, err := s.plausibilityOutliers(ctx, check, batch)
+			if err != nil {
+				return err
+			}
+
+			for _, warning := range warnings {
+				err = callback(warning)
+				if err != nil {
+					return err
+				}
+			}
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,49 @@
+package attrs
+
+import (
+	"testing"
+
+	"github.com/autowp/goautowp/schema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValueRange(t *testing.T) {
+	t.Parallel()
+
+	valueRange := ValueRange{Min: 1.5, Max: 100}
+
+	require.True(t, valueRange.Contains(1.5))
+	require.True(t, valueRange.Contains(100))
+	require.False(t, valueRange.Contains(1.4))
+	require.False(t, valueRange.Contains(100.1))
+}
+
+func TestExceedsPrecision(t *testing.T) {
+	t.Parallel()
+
+	require.False(t, exceedsPrecision(7.1, 1))
+	require.True(t, exceedsPrecision(7.091, 1))
+	require.False(t, exceedsPrecision(7.091, 3))
+	require.False(t, exceedsPrecision(250, 0))
+}
+
+func TestMedian(t *testing.T) {
+	t.Parallel()
+
+	require.InDelta(t, 2.0, median([]float64{3, 1, 2}), 0.001)
+	require.InDelta(t, 2.5, median([]float64{4, 1, 2, 3}), 0.001)
+}
+
+func TestNumericValue(t *testing.T) {
+	t.Parallel()
+
+	number, ok := numericValue(schema.AttrsAttributeTypeIDInteger, Value{Valid: true, IntValue: 6})
+	require.True(t, ok)
+	require.InDelta(t, 6.0, number, 0.001)
+
+	_, ok = numericValue(schema.AttrsAttributeTypeIDFloat, Value{Valid: true, IsEmpty: true})
+	require.False(t, ok)
+
+	_, ok = numericValue(schema.AttrsAttributeTypeIDString, Value{Valid: true, StringValue: "6"})
+	require.False(t, ok)
+}
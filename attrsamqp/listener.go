@@ -3,12 +3,31 @@ package attrsamqp
 import (
 	"context"
 	"encoding/json"
+	"time"
 
 	"github.com/autowp/goautowp/attrs"
 	"github.com/autowp/goautowp/util"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
 	"github.com/sirupsen/logrus"
 )
 
+const (
+	dirtyValuesInterval  = time.Second
+	dirtyValuesBatchSize = 100
+)
+
+var (
+	dirtyValuesBacklog = promauto.NewGauge(prometheus.GaugeOpts{
+		Name: "attrs_dirty_values_backlog",
+		Help: "Number of actual values waiting for recalculation.",
+	})
+	dirtyValuesProcessed = promauto.NewCounter(prometheus.CounterOpts{
+		Name: "attrs_dirty_values_processed_total",
+		Help: "Number of recalculated actual values.",
+	})
+)
+
 type UpdateValuesMessage struct {
 	ItemID int64  `json:"item_id"`
 	Type   string `json:"type"`
@@ -69,9 +88,15 @@ func (s *AttrsAMQP) ListenUpdateValues(
 		return err
 	}
 
+	ticker := time.NewTicker(dirtyValuesInterval)
+	defer ticker.Stop()
+
 	quit := false
 	for !quit {
 		select {
+		case <-ticker.C:
+			quit = s.processDirtyValues(ctx, quitChan)
+
 		case msg := <-msgs:
 			if msg.ContentType != "application/json" {
 				logrus.Errorf("unexpected mime `%s`", msg.ContentType)
@@ -116,3 +141,36 @@ func (s *AttrsAMQP) ListenUpdateValues(
 
 	return conn.Close()
 }
+
+// processDirtyValues drains recalculation queue. Returns true when quit is requested meanwhile.
+func (s *AttrsAMQP) processDirtyValues(ctx context.Context, quitChan chan bool) bool {
+	for {
+		processed, err := s.repository.ProcessDirtyValues(ctx, dirtyValuesBatchSize)
+		if err != nil {
+			logrus.Error(err.Error())
+
+			return false
+		}
+
+		dirtyValuesProcessed.Add(float64(processed))
+
+		backlog, err := s.repository.DirtyValuesCount(ctx)
+		if err != nil {
+			logrus.Error(err.Error())
+
+			return false
+		}
+
+		dirtyValuesBacklog.Set(float64(backlog))
+
+		if processed < dirtyValuesBatchSize {
+			return false
+		}
+
+		select {
+		case <-quitChan:
+			return true
+		default:
+		}
+	}
+}
@@ -110,6 +110,38 @@ func (s *Repository) Exists(ctx context.Context, ip net.IP) (bool, error) {
 	return success && exists, nil
 }
 
+// BannedIPs returns IPs of list which are banned now, indexed by their string form.
+func (s *Repository) BannedIPs(ctx context.Context, ips []net.IP) (map[string]bool, error) {
+	result := make(map[string]bool, len(ips))
+	if len(ips) == 0 {
+		return result, nil
+	}
+
+	values := make([]string, 0, len(ips))
+	for _, ip := range ips {
+		values = append(values, ip.String())
+	}
+
+	var banned []string
+
+	err := s.db.Select(goqu.Func("host", schema.IPBanTableIPCol)).
+		From(schema.IPBanTable).
+		Where(
+			schema.IPBanTableIPCol.In(values),
+			schema.IPBanTableUntilCol.Gte(goqu.Func("NOW")),
+		).
+		ScanValsContext(ctx, &banned)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, ip := range banned {
+		result[net.ParseIP(ip).String()] = true
+	}
+
+	return result, nil
+}
+
 // Get ban info.
 func (s *Repository) Get(ctx context.Context, ip net.IP) (*Item, error) {
 	var item Item
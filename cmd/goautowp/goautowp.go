@@ -5,6 +5,7 @@ import (
 	"os"
 	"os/signal"
 	"syscall"
+	"time"
 	_ "time/tzdata"
 
 	"github.com/autowp/goautowp"
@@ -398,6 +399,12 @@ func mainReturnWithCode() int { //nolint: maintidx
 					return autowpApp.SpecsRefreshActualValues(ctx)
 				},
 			},
+			{
+				Name: "specs-report-suspicious-values",
+				Action: func(ctx context.Context, _ *cli.Command) error {
+					return autowpApp.SpecsReportSuspiciousValues(ctx, os.Stdout)
+				},
+			},
 			{
 				Name: "refresh-item-parent-language",
 				Flags: []cli.Flag{
@@ -429,12 +436,92 @@ func mainReturnWithCode() int { //nolint: maintidx
 					return autowpApp.RefreshItemParentAllAuto(ctx)
 				},
 			},
+			{
+				Name: "search-reindex",
+				Action: func(ctx context.Context, _ *cli.Command) error {
+					return autowpApp.SearchReindex(ctx)
+				},
+			},
 			{
 				Name: "catalogue-rebuild-item-order-cache",
 				Action: func(ctx context.Context, _ *cli.Command) error {
 					return autowpApp.RebuildItemOrderCache(ctx)
 				},
 			},
+			{
+				Name: "catalogue-export",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "dir",
+						Value:    "",
+						Usage:    "directory to write data set to",
+						Required: true,
+					},
+					&cli.StringFlag{
+						Name:     "since",
+						Value:    "",
+						Usage:    "export only items changed after this RFC 3339 time",
+						Required: false,
+					},
+				},
+				Action: func(ctx context.Context, command *cli.Command) error {
+					var since time.Time
+
+					if value := command.String("since"); value != "" {
+						var err error
+
+						since, err = time.Parse(time.RFC3339, value)
+						if err != nil {
+							return err
+						}
+					}
+
+					return autowpApp.CatalogueExport(ctx, command.String("dir"), since)
+				},
+			},
+			{
+				Name: "catalogue-import",
+				Flags: []cli.Flag{
+					&cli.StringFlag{
+						Name:     "file",
+						Value:    "",
+						Usage:    "YAML or JSON file with items to import",
+						Required: true,
+					},
+					&cli.Int64Flag{
+						Name:     "user-id",
+						Value:    0,
+						Usage:    "id of user changes are made by",
+						Required: true,
+					},
+					&cli.BoolFlag{
+						Name:     "dry-run",
+						Value:    false,
+						Usage:    "only print changes",
+						Required: false,
+					},
+				},
+				Action: func(ctx context.Context, command *cli.Command) error {
+					return autowpApp.CatalogueImport(
+						ctx, command.String("file"), command.Int64("user-id"), command.Bool("dry-run"), os.Stdout,
+					)
+				},
+			},
+			{
+				Name: "catalogue-check",
+				Flags: []cli.Flag{
+					&cli.StringSliceFlag{
+						Name:  "repair",
+						Value: nil,
+						Usage: "kinds of problems to repair: cycle, catname, duplicate-catname, orphaned-language, " +
+							"parent-cache, vehicle-type, order-cache or all",
+						Required: false,
+					},
+				},
+				Action: func(ctx context.Context, command *cli.Command) error {
+					return autowpApp.CatalogueCheck(ctx, command.StringSlice("repair"), os.Stdout)
+				},
+			},
 			{
 				Name: "pictures",
 				Commands: []*cli.Command{
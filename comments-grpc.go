@@ -4,14 +4,23 @@ import (
 	"context"
 	"errors"
 	"fmt"
+	"net"
+	"slices"
+	"time"
 
+	"github.com/autowp/goautowp/antispam"
+	"github.com/autowp/goautowp/ban"
 	"github.com/autowp/goautowp/comments"
+	"github.com/autowp/goautowp/markup"
 	"github.com/autowp/goautowp/pictures"
 	"github.com/autowp/goautowp/schema"
+	"github.com/autowp/goautowp/search"
 	"github.com/autowp/goautowp/users"
 	"github.com/autowp/goautowp/util"
 	"github.com/autowp/goautowp/validation"
+	"github.com/doug-martin/goqu/v9"
 	"github.com/doug-martin/goqu/v9/exp"
+	"github.com/sirupsen/logrus"
 	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
@@ -26,6 +35,12 @@ var (
 
 const MaxReplies = 500
 
+const (
+	moderationQueueDefaultLimit = 50
+	moderationQueueMaxLimit     = 100
+	moderateMessagesMaxCount    = 100
+)
+
 type CommentsGRPCServer struct {
 	UnimplementedCommentsServer
 	auth               *Auth
@@ -33,6 +48,11 @@ type CommentsGRPCServer struct {
 	usersRepository    *users.Repository
 	picturesRepository *pictures.Repository
 	userExtractor      *UserExtractor
+	searchIndexer      *search.Indexer
+	markupExtractor    *MarkupExtractor
+	antispam           *antispam.Service
+	banRepository      *ban.Repository
+	events             *Events
 }
 
 func extractPicturesStatus(status schema.PictureStatus) PictureStatus {
@@ -118,10 +138,73 @@ func convertModeratorAttention(
 	return 0, fmt.Errorf("%w: `%v`", errUnknownModeratorAttention, value)
 }
 
+// messagesPrefetch holds data of page of messages loaded in batch, so their references are resolved
+// with single set of queries. Messages missing in it are loaded one by one.
+type messagesPrefetch struct {
+	rendered map[int64]string
+	history  map[int64][]*APICommentsMessageRevision
+}
+
+// prefetchMessages renders messages of the page and loads history of those visible to user.
+func prefetchMessages(
+	ctx context.Context, markupExtractor *MarkupExtractor, repository *comments.Repository,
+	rows []*schema.CommentMessageRow, userID int64, roles []string, fields *CommentMessageFields, lang string,
+) (messagesPrefetch, error) {
+	var result messagesPrefetch
+
+	if len(rows) == 0 {
+		return result, nil
+	}
+
+	if fields.GetTextHtml() {
+		sources := make([]string, 0, len(rows))
+		for _, row := range rows {
+			sources = append(sources, row.Message)
+		}
+
+		results, err := markupExtractor.RenderAll(ctx, sources, lang)
+		if err != nil {
+			return result, err
+		}
+
+		result.rendered = make(map[int64]string, len(rows))
+		for idx, row := range rows {
+			result.rendered[row.ID] = results[idx].HTML
+		}
+	}
+
+	if fields.GetHistory() {
+		ids := make([]int64, 0, len(rows))
+
+		for _, row := range rows {
+			if canViewMessageHistory(row, userID, roles) {
+				ids = append(ids, row.ID)
+			}
+		}
+
+		var err error
+
+		result.history, err = extractMessagesHistory(ctx, repository, ids)
+		if err != nil {
+			return result, err
+		}
+	}
+
+	return result, nil
+}
+
+// canViewMessageHistory reports whether user can see previous revisions of message,
+// they may hold text the author removed on purpose.
+func canViewMessageHistory(row *schema.CommentMessageRow, userID int64, roles []string) bool {
+	return util.Contains(roles, users.RoleCommentsModer) ||
+		(userID > 0 && row.AuthorID.Valid && row.AuthorID.Int64 == userID)
+}
+
+// extractMessage converts row to API message.
 func extractMessage(
 	ctx context.Context, row *schema.CommentMessageRow, repository *comments.Repository,
-	picturesRepository *pictures.Repository, userID int64, roles []string, canViewIP bool,
-	fields *CommentMessageFields,
+	picturesRepository *pictures.Repository, markupExtractor *MarkupExtractor, prefetched messagesPrefetch,
+	userID int64, roles []string, canViewIP bool, fields *CommentMessageFields, lang string,
 ) (*APICommentsMessage, error) {
 	canRemove := util.Contains(roles, users.RoleCommentsModer)
 	isModer := util.Contains(roles, users.RoleModer)
@@ -157,6 +240,8 @@ func extractMessage(
 	var (
 		preview       string
 		text          string
+		textHTML      string
+		history       []*APICommentsMessageRevision
 		vote          int32
 		userVote      int32
 		route         []string
@@ -166,7 +251,7 @@ func extractMessage(
 
 	if canRemove || !row.Deleted {
 		if fields.GetPreview() {
-			preview = util.GetTextPreview(row.Message, util.TextPreviewOptions{
+			preview = util.GetTextPreview(markup.PlainText(row.Message), util.TextPreviewOptions{
 				Maxlines:  1,
 				Maxlength: comments.CommentMessagePreviewLength,
 			})
@@ -183,6 +268,34 @@ func extractMessage(
 			text = row.Message
 		}
 
+		if fields.GetTextHtml() {
+			var ok bool
+
+			textHTML, ok = prefetched.rendered[row.ID]
+			if !ok {
+				result, err := markupExtractor.Render(ctx, row.Message, lang)
+				if err != nil {
+					return nil, err
+				}
+
+				textHTML = result.HTML
+			}
+		}
+
+		if fields.GetHistory() && canViewMessageHistory(row, userID, roles) {
+			var ok bool
+
+			history, ok = prefetched.history[row.ID]
+			if !ok {
+				histories, err := extractMessagesHistory(ctx, repository, []int64{row.ID})
+				if err != nil {
+					return nil, err
+				}
+
+				history = histories[row.ID]
+			}
+		}
+
 		if fields.GetVote() {
 			vote = row.Vote
 		}
@@ -198,14 +311,15 @@ func extractMessage(
 
 		if fields.GetReplies() {
 			paginator := repository.Paginator(comments.Request{
-				ItemID:       row.ItemID,
-				TypeID:       row.TypeID,
-				ParentID:     row.ID,
-				PerPage:      MaxReplies,
-				Order:        []exp.OrderedExpression{schema.CommentMessageTableDatetimeCol.Desc()},
-				FetchMessage: fields.GetPreview() || fields.GetText(),
-				FetchVote:    fields.GetVote(),
-				FetchIP:      canViewIP,
+				ItemID:        row.ItemID,
+				TypeID:        row.TypeID,
+				ParentID:      row.ID,
+				PerPage:       MaxReplies,
+				Order:         []exp.OrderedExpression{schema.CommentMessageTableDatetimeCol.Desc()},
+				FetchMessage:  fields.GetPreview() || fields.GetText() || fields.GetTextHtml(),
+				FetchVote:     fields.GetVote(),
+				FetchIP:       canViewIP,
+				HideBlockedBy: userID,
 			})
 
 			sqSelect, err := paginator.GetCurrentItems(ctx)
@@ -220,6 +334,13 @@ func extractMessage(
 				return nil, err
 			}
 
+			repliesPrefetched, err := prefetchMessages(
+				ctx, markupExtractor, repository, rows, userID, roles, fields, lang,
+			)
+			if err != nil {
+				return nil, err
+			}
+
 			replies = make([]*APICommentsMessage, 0)
 
 			for _, row := range rows {
@@ -228,10 +349,13 @@ func extractMessage(
 					row,
 					repository,
 					picturesRepository,
+					markupExtractor,
+					repliesPrefetched,
 					userID,
 					roles,
 					canViewIP,
 					fields,
+					lang,
 				)
 				if err != nil {
 					return nil, err
@@ -258,7 +382,7 @@ func extractMessage(
 		ip = row.IP.String()
 	}
 
-	return &APICommentsMessage{
+	result := &APICommentsMessage{
 		Id:                 row.ID,
 		TypeId:             typeID,
 		ItemId:             row.ItemID,
@@ -271,12 +395,45 @@ func extractMessage(
 		Ip:                 ip,
 		Preview:            preview,
 		Text:               text,
+		TextHtml:           textHTML,
+		History:            history,
 		Vote:               vote,
 		Route:              route,
 		UserVote:           userVote,
 		Replies:            replies,
 		PictureStatus:      pictureStatus,
-	}, nil
+	}
+
+	if row.EditedAt.Valid {
+		result.EditedAt = timestamppb.New(row.EditedAt.Time)
+	}
+
+	return result, nil
+}
+
+func extractMessagesHistory(
+	ctx context.Context, repository *comments.Repository, messageIDs []int64,
+) (map[int64][]*APICommentsMessageRevision, error) {
+	rows, err := repository.MessagesHistory(ctx, messageIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[int64][]*APICommentsMessageRevision, len(messageIDs))
+
+	for _, id := range messageIDs {
+		result[id] = make([]*APICommentsMessageRevision, 0, len(rows[id]))
+
+		for _, row := range rows[id] {
+			result[id] = append(result[id], &APICommentsMessageRevision{
+				Text:      row.Message,
+				CreatedAt: timestamppb.New(row.CreatedAt),
+				UserId:    util.NullInt64ToScalar(row.UserID),
+			})
+		}
+	}
+
+	return result, nil
 }
 
 func NewCommentsGRPCServer(
@@ -285,6 +442,11 @@ func NewCommentsGRPCServer(
 	usersRepository *users.Repository,
 	picturesRepository *pictures.Repository,
 	userExtractor *UserExtractor,
+	searchIndexer *search.Indexer,
+	markupExtractor *MarkupExtractor,
+	antispamService *antispam.Service,
+	banRepository *ban.Repository,
+	events *Events,
 ) *CommentsGRPCServer {
 	return &CommentsGRPCServer{
 		auth:               auth,
@@ -292,6 +454,11 @@ func NewCommentsGRPCServer(
 		usersRepository:    usersRepository,
 		picturesRepository: picturesRepository,
 		userExtractor:      userExtractor,
+		searchIndexer:      searchIndexer,
+		markupExtractor:    markupExtractor,
+		antispam:           antispamService,
+		banRepository:      banRepository,
+		events:             events,
 	}
 }
 
@@ -439,16 +606,24 @@ func (s *CommentsGRPCServer) SetDeleted(
 		return nil, status.Errorf(codes.PermissionDenied, "PermissionDenied")
 	}
 
+	quarantined := false
+
 	if in.GetDeleted() {
-		err = s.repository.QueueDeleteMessage(ctx, in.GetCommentId(), userCtx.UserID)
+		err = s.repository.QueueDeleteMessage(ctx, in.GetCommentId(), userCtx.UserID, in.GetSpam())
 	} else {
-		err = s.repository.RestoreMessage(ctx, in.GetCommentId())
+		quarantined, err = s.repository.RestoreMessage(ctx, in.GetCommentId())
 	}
 
 	if err != nil {
 		return &emptypb.Empty{}, status.Error(codes.Internal, err.Error())
 	}
 
+	if quarantined {
+		s.notifyRestored(ctx, in.GetCommentId())
+	}
+
+	s.searchIndexer.Refresh(ctx, search.KindComment, in.GetCommentId())
+
 	return &emptypb.Empty{}, nil
 }
 
@@ -530,6 +705,16 @@ func (s *CommentsGRPCServer) VoteComment(
 	}, nil
 }
 
+func commentMessageInputFilter() *validation.InputFilter {
+	return &validation.InputFilter{
+		Filters: []validation.FilterInterface{&validation.StringTrimFilter{}},
+		Validators: []validation.ValidatorInterface{
+			&validation.NotEmpty{},
+			&validation.StringLength{Min: 0, Max: comments.MaxMessageLength},
+		},
+	}
+}
+
 func (s *AddCommentRequest) Validate(
 	ctx context.Context,
 	repository *comments.Repository,
@@ -541,15 +726,7 @@ func (s *AddCommentRequest) Validate(
 		err      error
 	)
 
-	msgInputFilter := validation.InputFilter{
-		Filters: []validation.FilterInterface{&validation.StringTrimFilter{}},
-		Validators: []validation.ValidatorInterface{
-			&validation.NotEmpty{},
-			&validation.StringLength{Min: 0, Max: comments.MaxMessageLength},
-		},
-	}
-
-	s.Message, problems, err = msgInputFilter.IsValidString(s.GetMessage())
+	s.Message, problems, err = commentMessageInputFilter().IsValidString(s.GetMessage())
 	if err != nil {
 		return nil, err
 	}
@@ -608,10 +785,29 @@ func (s *CommentsGRPCServer) Add(
 		return nil, status.Error(codes.InvalidArgument, err.Error())
 	}
 
-	moderatorAttention := in.GetModeratorAttention()
+	verdict, err := s.classify(ctx, userCtx, antispam.Message{ //nolint:exhaustruct
+		AuthorID: userCtx.UserID,
+		TypeID:   commentsType,
+		ItemID:   in.GetItemId(),
+		Text:     in.GetMessage(),
+	})
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
 
 	ctx = context.WithoutCancel(ctx)
 
+	moderation := comments.Moderation{ //nolint:exhaustruct
+		Attention:  in.GetModeratorAttention() || verdict.Action == antispam.ActionFlag,
+		Quarantine: verdict.Action == antispam.ActionQuarantine,
+	}
+
+	if verdict.Action != antispam.ActionNone {
+		moderation.Store = func(ctx context.Context, tx *goqu.TxDatabase, messageID int64) error {
+			return s.antispam.StoreTx(ctx, tx, messageID, verdict)
+		}
+	}
+
 	messageID, err := s.repository.Add(
 		ctx,
 		commentsType,
@@ -620,7 +816,7 @@ func (s *CommentsGRPCServer) Add(
 		userCtx.UserID,
 		in.GetMessage(),
 		userCtx.IP.String(),
-		moderatorAttention,
+		moderation,
 	)
 	if err != nil {
 		return nil, status.Error(codes.Internal, err.Error())
@@ -630,10 +826,11 @@ func (s *CommentsGRPCServer) Add(
 		return nil, status.Errorf(codes.Internal, "Message add failed")
 	}
 
+	// message is stored already, so failures of following steps are logged only
 	if util.Contains(userCtx.Roles, users.RoleModer) && in.GetParentId() > 0 && in.GetResolve() {
 		err = s.repository.CompleteMessage(ctx, in.GetParentId())
 		if err != nil {
-			return nil, status.Error(codes.Internal, err.Error())
+			logrus.Errorf("comments: failed to resolve message %d: %s", in.GetParentId(), err.Error())
 		}
 	}
 
@@ -643,25 +840,207 @@ func (s *CommentsGRPCServer) Add(
 		err = s.usersRepository.TouchLastMessage(ctx, userCtx.UserID)
 	}
 
+	if err != nil {
+		logrus.Errorf("comments: failed to count message of user %d: %s", userCtx.UserID, err.Error())
+	}
+
+	if moderation.Quarantine {
+		return &AddCommentResponse{
+			Id:          messageID,
+			Quarantined: true,
+		}, nil
+	}
+
+	s.notifyPosted(ctx, messageID, in.GetParentId() > 0, in.GetMessage())
+
+	s.searchIndexer.Refresh(ctx, search.KindComment, messageID)
+
+	return &AddCommentResponse{ //nolint:exhaustruct
+		Id: messageID,
+	}, nil
+}
+
+func (s *CommentsEditMessageRequest) Validate() ([]*errdetails.BadRequest_FieldViolation, error) {
+	var (
+		result   = make([]*errdetails.BadRequest_FieldViolation, 0)
+		problems []string
+		err      error
+	)
+
+	s.Message, problems, err = commentMessageInputFilter().IsValidString(s.GetMessage())
+	if err != nil {
+		return nil, err
+	}
+
+	for _, fv := range problems {
+		result = append(result, &errdetails.BadRequest_FieldViolation{
+			Field:       "message",
+			Description: fv,
+		})
+	}
+
+	return result, nil
+}
+
+func (s *CommentsGRPCServer) EditMessage(
+	ctx context.Context,
+	in *CommentsEditMessageRequest,
+) (*emptypb.Empty, error) {
+	userCtx, err := s.auth.ValidateGRPC(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if userCtx.UserID == 0 {
+		return nil, status.Error(codes.PermissionDenied, "PermissionDenied")
+	}
+
+	InvalidParams, err := in.Validate()
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if len(InvalidParams) > 0 {
+		return nil, wrapFieldViolations(InvalidParams)
+	}
+
+	row, err := s.repository.Message(ctx, in.GetId(), false, false, false)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if row == nil {
+		return nil, status.Errorf(codes.NotFound, "NotFound")
+	}
+
+	if row.Deleted {
+		return nil, status.Error(codes.FailedPrecondition, "message is deleted")
+	}
+
+	if !util.Contains(userCtx.Roles, users.RoleCommentsModer) {
+		if !row.AuthorID.Valid || row.AuthorID.Int64 != userCtx.UserID {
+			return nil, status.Error(codes.PermissionDenied, "PermissionDenied")
+		}
+
+		if time.Since(row.CreatedAt) > comments.MessageEditPeriod {
+			return nil, status.Error(codes.PermissionDenied, "edit period is expired")
+		}
+	}
+
+	err = s.repository.EditMessage(ctx, row.ID, userCtx.UserID, in.GetMessage())
 	if err != nil {
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
-	if in.GetParentId() > 0 {
-		err = s.repository.NotifyAboutReply(ctx, messageID)
+	// edit is committed already, so failures of following steps are logged only
+	quarantined, err := s.moderateEdited(ctx, userCtx, antispam.Message{
+		ID:       row.ID,
+		AuthorID: row.AuthorID.Int64,
+		TypeID:   row.TypeID,
+		ItemID:   row.ItemID,
+		Text:     in.GetMessage(),
+	})
+	if err != nil {
+		logrus.Errorf("comments: failed to moderate edited message %d: %s", row.ID, err.Error())
+	}
+
+	if !quarantined {
+		err = s.notifyMentions(ctx, row.ID, in.GetMessage())
 		if err != nil {
-			return nil, status.Error(codes.Internal, err.Error())
+			logrus.Errorf("comments: failed to notify mentions of edited message %d: %s", row.ID, err.Error())
+		}
+	}
+
+	s.searchIndexer.Refresh(ctx, search.KindComment, row.ID)
+
+	return &emptypb.Empty{}, nil
+}
+
+// classify classifies message of user. Messages of moderators are trusted.
+func (s *CommentsGRPCServer) classify(
+	ctx context.Context, userCtx UserContext, message antispam.Message,
+) (antispam.Verdict, error) {
+	if util.Contains(userCtx.Roles, users.RoleModer) {
+		return antispam.Verdict{Action: antispam.ActionNone}, nil //nolint:exhaustruct
+	}
+
+	return s.antispam.Classify(ctx, message)
+}
+
+// moderateEdited classifies edited message again and flags or quarantines it.
+// Verdict and attention flag left from the previous version are removed when message became clean.
+func (s *CommentsGRPCServer) moderateEdited(
+	ctx context.Context, userCtx UserContext, message antispam.Message,
+) (bool, error) {
+	verdict, err := s.classify(ctx, userCtx, message)
+	if err != nil {
+		return false, err
+	}
+
+	if verdict.Action == antispam.ActionNone {
+		cleared, err := s.antispam.Clear(ctx, message.ID)
+		if err != nil || !cleared {
+			return false, err
+		}
+
+		return false, s.repository.ClearAttention(ctx, message.ID)
+	}
+
+	err = s.antispam.Store(ctx, message.ID, verdict)
+	if err != nil {
+		return false, err
+	}
+
+	if verdict.Action == antispam.ActionQuarantine {
+		return true, s.repository.Quarantine(ctx, message.ID)
+	}
+
+	return false, s.repository.RequireAttention(ctx, message.ID)
+}
+
+// notifyPosted notifies author of parent message, mentioned users and subscribers of topic about new message.
+// Message is stored already, so failures are logged and don't prevent other notifications.
+func (s *CommentsGRPCServer) notifyPosted(ctx context.Context, messageID int64, isReply bool, message string) {
+	if isReply {
+		err := s.repository.NotifyAboutReply(ctx, messageID)
+		if err != nil {
+			logrus.Errorf("comments: failed to notify about reply %d: %s", messageID, err.Error())
 		}
 	}
 
+	err := s.notifyMentions(ctx, messageID, message)
+	if err != nil {
+		logrus.Errorf("comments: failed to notify mentions of message %d: %s", messageID, err.Error())
+	}
+
 	err = s.repository.NotifySubscribers(ctx, messageID)
 	if err != nil {
-		return nil, status.Error(codes.Internal, err.Error())
+		logrus.Errorf("comments: failed to queue notification of subscribers about %d: %s", messageID, err.Error())
 	}
+}
 
-	return &AddCommentResponse{ //nolint:exhaustruct
-		Id: messageID,
-	}, nil
+// notifyRestored sends notifications skipped while message was quarantined.
+// Message is already restored at this point, so failures are logged only.
+func (s *CommentsGRPCServer) notifyRestored(ctx context.Context, messageID int64) {
+	row, err := s.repository.Message(ctx, messageID, true, false, false)
+	if err != nil {
+		logrus.Errorf("comments: failed to notify about restored message %d: %s", messageID, err.Error())
+
+		return
+	}
+
+	if row != nil {
+		s.notifyPosted(ctx, messageID, row.ParentID.Valid, row.Message)
+	}
+}
+
+func (s *CommentsGRPCServer) notifyMentions(ctx context.Context, messageID int64, message string) error {
+	mentioned, err := s.repository.SetMentions(ctx, messageID, message)
+	if err != nil {
+		return err
+	}
+
+	return s.repository.NotifyMentions(ctx, messageID, mentioned)
 }
 
 func (s *CommentsGRPCServer) GetMessagePage(
@@ -703,7 +1082,7 @@ func (s *CommentsGRPCServer) GetMessage(
 	row, err := s.repository.Message(
 		ctx,
 		in.GetId(),
-		fields.GetPreview() || fields.GetText(),
+		fields.GetPreview() || fields.GetText() || fields.GetTextHtml(),
 		fields.GetVote(),
 		canViewIP,
 	)
@@ -720,10 +1099,13 @@ func (s *CommentsGRPCServer) GetMessage(
 		row,
 		s.repository,
 		s.picturesRepository,
+		s.markupExtractor,
+		messagesPrefetch{},
 		userCtx.UserID,
 		userCtx.Roles,
 		canViewIP,
 		fields,
+		in.GetLanguage(),
 	)
 }
 
@@ -750,35 +1132,33 @@ func (s *CommentsGRPCServer) GetMessages(
 	}
 
 	options := comments.Request{
-		ItemID:       in.GetItemId(),
-		TypeID:       typeID,
-		ParentID:     in.GetParentId(),
-		NoParents:    in.GetNoParents(),
-		UserID:       in.GetUserId(),
-		Order:        []exp.OrderedExpression{schema.CommentMessageTableDatetimeCol.Asc()},
-		FetchMessage: fields.GetPreview() || fields.GetText(),
-		FetchVote:    fields.GetVote(),
-		FetchIP:      canViewIP,
-		Page:         in.GetPage(),
+		ItemID:          in.GetItemId(),
+		TypeID:          typeID,
+		ParentID:        in.GetParentId(),
+		NoParents:       in.GetNoParents(),
+		UserID:          in.GetUserId(),
+		MentionedUserID: in.GetMentionedUserId(),
+		FetchMessage:    fields.GetPreview() || fields.GetText() || fields.GetTextHtml(),
+		FetchVote:       fields.GetVote(),
+		FetchIP:         canViewIP,
+		Page:            in.GetPage(),
+		HideBlockedBy:   userCtx.UserID,
 	}
 
+	keyset := comments.MessagesKeysetDateAsc
+
 	switch in.GetOrder() {
 	case GetMessagesRequest_VOTE_DESC:
-		options.Order = []exp.OrderedExpression{
-			schema.CommentMessageTableVoteCol.Desc(),
-			schema.CommentMessageTableDatetimeCol.Desc(),
-		}
+		keyset = comments.MessagesKeysetVoteDesc
 	case GetMessagesRequest_VOTE_ASC:
-		options.Order = []exp.OrderedExpression{
-			schema.CommentMessageTableVoteCol.Asc(),
-			schema.CommentMessageTableDatetimeCol.Desc(),
-		}
+		keyset = comments.MessagesKeysetVoteAsc
 	case GetMessagesRequest_DATE_DESC:
-		options.Order = []exp.OrderedExpression{schema.CommentMessageTableDatetimeCol.Desc()}
+		keyset = comments.MessagesKeysetDateDesc
 	case GetMessagesRequest_DATE_ASC, GetMessagesRequest_DEFAULT:
-		options.Order = []exp.OrderedExpression{schema.CommentMessageTableDatetimeCol.Asc()}
 	}
 
+	options.Order = keyset.Order(schema.CommentMessageTableName)
+
 	if isModer {
 		if len(in.GetUserIdentity()) > 0 {
 			options.UserID, err = s.usersRepository.UserIDByIdentity(ctx, in.GetUserIdentity())
@@ -799,7 +1179,7 @@ func (s *CommentsGRPCServer) GetMessages(
 		if in.GetPicturesOfItemId() > 0 {
 			options.PicturesOfItemID = in.GetPicturesOfItemId()
 		}
-	} else if in.GetItemId() == 0 && in.GetUserId() == 0 {
+	} else if in.GetItemId() == 0 && in.GetUserId() == 0 && in.GetMentionedUserId() == 0 {
 		return nil, status.Error(codes.PermissionDenied, "PermissionDenied")
 	}
 
@@ -809,73 +1189,594 @@ func (s *CommentsGRPCServer) GetMessages(
 
 	options.PerPage = in.GetLimit()
 
-	paginator := s.repository.Paginator(options)
+	var (
+		rows       []*schema.CommentMessageRow
+		pages      *util.Pages
+		nextCursor string
+	)
+
+	if in.GetCursor() != nil {
+		rows, nextCursor, err = s.repository.MessagesByCursor(ctx, options, keyset, in.GetCursor().GetValue())
+		if err != nil {
+			return nil, wrapCursorError(err)
+		}
+
+		if in.GetCount() {
+			count, err := s.repository.Paginator(options).GetTotalItemCount(ctx)
+			if err != nil {
+				return nil, status.Error(codes.Internal, err.Error())
+			}
 
-	msgs := make([]*APICommentsMessage, 0)
+			pages = &util.Pages{TotalItemCount: count}
+		}
+	} else {
+		paginator := s.repository.Paginator(options)
 
-	if in.GetLimit() > 0 {
 		sqSelect, err := paginator.GetCurrentItems(ctx)
 		if err != nil {
 			return nil, err
 		}
 
-		rows := make([]*schema.CommentMessageRow, 0)
-
 		err = sqSelect.ScanStructsContext(ctx, &rows)
 		if err != nil {
 			return nil, err
 		}
 
-		for _, row := range rows {
-			msg, err := extractMessage(
-				ctx,
-				row,
-				s.repository,
-				s.picturesRepository,
-				userCtx.UserID,
-				userCtx.Roles,
-				canViewIP,
-				fields,
-			)
-			if err != nil {
-				return nil, err
-			}
-
-			msgs = append(msgs, msg)
-		}
-
-		if userCtx.UserID > 0 && in.GetItemId() > 0 && in.GetTypeId() > 0 {
-			err = s.repository.SetSubscriptionSent(
-				ctx,
-				typeID,
-				in.GetItemId(),
-				userCtx.UserID,
-				false,
-			)
-			if err != nil {
-				return nil, err
-			}
+		pages, err = paginator.GetPages(ctx)
+		if err != nil {
+			return nil, err
 		}
 	}
 
-	pages, err := paginator.GetPages(ctx)
+	prefetched, err := prefetchMessages(
+		ctx, s.markupExtractor, s.repository, rows, userCtx.UserID, userCtx.Roles, fields, in.GetLanguage(),
+	)
 	if err != nil {
-		return nil, err
+		return nil, status.Error(codes.Internal, err.Error())
 	}
 
-	return &APICommentsMessages{
-		Items: msgs,
-		Paginator: &Pages{
-			PageCount:        pages.PageCount,
-			First:            pages.First,
-			Last:             pages.Last,
-			Previous:         pages.Previous,
-			Next:             pages.Next,
-			Current:          pages.Current,
-			FirstPageInRange: pages.FirstPageInRange,
-			LastPageInRange:  pages.LastPageInRange,
+	msgs := make([]*APICommentsMessage, 0, len(rows))
+
+	for _, row := range rows {
+		msg, err := extractMessage(
+			ctx,
+			row,
+			s.repository,
+			s.picturesRepository,
+			s.markupExtractor,
+			prefetched,
+			userCtx.UserID,
+			userCtx.Roles,
+			canViewIP,
+			fields,
+			in.GetLanguage(),
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		msgs = append(msgs, msg)
+	}
+
+	if userCtx.UserID > 0 && in.GetItemId() > 0 && in.GetTypeId() > 0 {
+		err = s.repository.SetSubscriptionSent(
+			ctx,
+			typeID,
+			in.GetItemId(),
+			userCtx.UserID,
+			false,
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	var paginator *Pages
+	if pages != nil {
+		paginator = &Pages{
+			PageCount:        pages.PageCount,
+			First:            pages.First,
+			Last:             pages.Last,
+			Previous:         pages.Previous,
+			Next:             pages.Next,
+			Current:          pages.Current,
+			FirstPageInRange: pages.FirstPageInRange,
+			LastPageInRange:  pages.LastPageInRange,
 			PagesInRange:     pages.PagesInRange,
 			TotalItemCount:   pages.TotalItemCount,
+		}
+	}
+
+	return &APICommentsMessages{
+		Items:      msgs,
+		Paginator:  paginator,
+		NextCursor: nextCursor,
+	}, nil
+}
+
+func (s *CommentsGRPCServer) GetMessageTree(
+	ctx context.Context,
+	in *GetMessageTreeRequest,
+) (*APICommentsMessageTree, error) {
+	userCtx, err := s.auth.ValidateGRPC(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	typeID, err := convertCommentsType(in.GetTypeId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	if typeID == 0 || in.GetItemId() == 0 {
+		return nil, status.Error(codes.InvalidArgument, "type_id and item_id are required")
+	}
+
+	fields := in.GetFields()
+	if fields == nil {
+		fields = &CommentMessageFields{}
+	}
+
+	// replies are loaded as tree
+	fields.Replies = false
+
+	keyset := comments.MessagesKeysetDateAsc
+
+	switch in.GetOrder() {
+	case GetMessageTreeRequest_NEWEST:
+		keyset = comments.MessagesKeysetDateDesc
+	case GetMessageTreeRequest_TOP_VOTED:
+		keyset = comments.MessagesKeysetVoteDesc
+	case GetMessageTreeRequest_OLDEST:
+	}
+
+	limit := in.GetLimit()
+	if limit <= 0 {
+		limit = messageTreeDefaultLimit
+	}
+
+	repliesLimit := in.GetRepliesLimit()
+	if repliesLimit <= 0 {
+		repliesLimit = messageTreeDefaultRepliesLimit
+	}
+
+	cursor := in.GetCursor()
+
+	var fullPath, path []int64
+
+	if in.GetMessageId() > 0 {
+		row, err := s.repository.Message(ctx, in.GetMessageId(), false, false, false)
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+
+		if row == nil || row.TypeID != typeID || row.ItemID != in.GetItemId() {
+			return nil, status.Error(codes.NotFound, "NotFound")
+		}
+
+		fullPath, err = s.repository.MessagePath(ctx, row.ID)
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+
+		path = fullPath
+
+		if in.GetParentId() > 0 {
+			idx := slices.Index(fullPath, in.GetParentId())
+			if idx < 0 {
+				return nil, status.Error(codes.InvalidArgument, "message is not a reply to parent_id")
+			}
+
+			path = fullPath[idx+1:]
+		}
+
+		cursor = ""
+	}
+
+	isModer := util.Contains(userCtx.Roles, users.RoleModer)
+
+	builder := messageTreeBuilder{
+		repository:         s.repository,
+		picturesRepository: s.picturesRepository,
+		markupExtractor:    s.markupExtractor,
+		userCtx:            userCtx,
+		canViewIP:          isModer,
+		fields:             fields,
+		lang:               in.GetLanguage(),
+		request: comments.Request{ //nolint:exhaustruct
+			ItemID:        in.GetItemId(),
+			TypeID:        typeID,
+			FetchMessage:  fields.GetPreview() || fields.GetText() || fields.GetTextHtml(),
+			FetchVote:     fields.GetVote(),
+			FetchIP:       isModer,
+			HideBlockedBy: userCtx.UserID,
 		},
+		keyset:       keyset,
+		depth:        min(max(in.GetDepth(), 0), messageTreeMaxDepth),
+		repliesLimit: min(repliesLimit, messageTreeMaxLimit),
+		nodesLeft:    messageTreeMaxNodes,
+	}
+
+	items, nextCursor, prevCursor, err := builder.build(
+		ctx, in.GetParentId(), cursor, min(limit, messageTreeMaxLimit), path,
+	)
+	if err != nil {
+		return nil, wrapCursorError(err)
+	}
+
+	return &APICommentsMessageTree{
+		Items:      items,
+		NextCursor: nextCursor,
+		PrevCursor: prevCursor,
+		Path:       fullPath,
+	}, nil
+}
+
+func (s *CommentsGRPCServer) GetModerationQueue(
+	ctx context.Context,
+	in *CommentsModerationQueueRequest,
+) (*CommentsModerationQueue, error) {
+	userCtx, err := s.auth.ValidateGRPC(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if !util.Contains(userCtx.Roles, users.RoleCommentsModer) {
+		return nil, status.Error(codes.PermissionDenied, "PermissionDenied")
+	}
+
+	typeID, err := convertCommentsType(in.GetTypeId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	fields := in.GetFields()
+	if fields == nil {
+		fields = &CommentMessageFields{}
+	}
+
+	limit := in.GetLimit()
+	if limit <= 0 {
+		limit = moderationQueueDefaultLimit
+	}
+
+	limit = min(limit, moderationQueueMaxLimit)
+
+	fetchMessage := fields.GetPreview() || fields.GetText() || fields.GetTextHtml()
+
+	rows, nextCursor, err := s.repository.MessagesByCursor(ctx, comments.Request{ //nolint:exhaustruct
+		TypeID:             typeID,
+		ModeratorAttention: schema.CommentMessageModeratorAttentionRequired,
+		Quarantined:        in.GetQuarantined(),
+		FetchMessage:       fetchMessage,
+		FetchVote:          fields.GetVote(),
+		FetchIP:            true,
+		PerPage:            limit,
+	}, comments.MessagesKeysetDateAsc, in.GetCursor())
+	if err != nil {
+		return nil, wrapCursorError(err)
+	}
+
+	ids := make([]int64, 0, len(rows))
+	authorIDs := make([]int64, 0, len(rows))
+	parentIDs := make([]int64, 0, len(rows))
+	ips := make([]net.IP, 0, len(rows))
+
+	for _, row := range rows {
+		ids = append(ids, row.ID)
+
+		if row.AuthorID.Valid {
+			authorIDs = append(authorIDs, row.AuthorID.Int64)
+		}
+
+		if row.ParentID.Valid {
+			parentIDs = append(parentIDs, row.ParentID.Int64)
+		}
+
+		if len(row.IP) > 0 {
+			ips = append(ips, row.IP)
+		}
+	}
+
+	parents, err := s.repository.MessagesByIDs(
+		ctx, util.RemoveDuplicate(parentIDs), fetchMessage, fields.GetVote(), true,
+	)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	bannedIPs, err := s.banRepository.BannedIPs(ctx, ips)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	verdicts, err := s.antispam.Verdicts(ctx, ids)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	reputations, err := s.repository.AuthorsReputation(ctx, util.RemoveDuplicate(authorIDs))
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	// parents are prefetched together with messages of the page
+	prefetchRows := slices.Clone(rows)
+	for _, parent := range parents {
+		prefetchRows = append(prefetchRows, parent)
+	}
+
+	prefetched, err := prefetchMessages(
+		ctx, s.markupExtractor, s.repository, prefetchRows, userCtx.UserID, userCtx.Roles, fields, in.GetLanguage(),
+	)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	items := make([]*CommentsModerationQueueItem, 0, len(rows))
+
+	for _, row := range rows {
+		item, err := s.extractModerationQueueItem(ctx, row, parents, prefetched, userCtx, fields, in.GetLanguage())
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+
+		item.IpBanned = len(row.IP) > 0 && bannedIPs[row.IP.String()]
+
+		if verdict, ok := verdicts[row.ID]; ok {
+			item.SpamScore = verdict.Score
+			item.SpamReasons = verdict.Reasons
+		}
+
+		if reputation, ok := reputations[row.AuthorID.Int64]; ok && row.AuthorID.Valid {
+			item.Author = &CommentsAuthorReputation{
+				MessagesCount:   reputation.MessagesCount,
+				DeletedCount:    reputation.DeletedCount,
+				AttentionsCount: reputation.AttentionsCount,
+				Votes:           reputation.Votes,
+			}
+
+			if reputation.RegDate.Valid {
+				item.Author.RegDate = timestamppb.New(reputation.RegDate.Time)
+			}
+		}
+
+		items = append(items, item)
+	}
+
+	return &CommentsModerationQueue{
+		Items:      items,
+		NextCursor: nextCursor,
 	}, nil
 }
+
+func (s *CommentsGRPCServer) extractModerationQueueItem(
+	ctx context.Context, row *schema.CommentMessageRow, parents map[int64]*schema.CommentMessageRow,
+	prefetched messagesPrefetch, userCtx UserContext, fields *CommentMessageFields, lang string,
+) (*CommentsModerationQueueItem, error) {
+	msg, err := extractMessage(
+		ctx, row, s.repository, s.picturesRepository, s.markupExtractor, prefetched, userCtx.UserID, userCtx.Roles,
+		true, fields, lang,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	item := &CommentsModerationQueueItem{Message: msg} //nolint:exhaustruct
+
+	if parentRow, ok := parents[row.ParentID.Int64]; ok && row.ParentID.Valid {
+		item.Parent, err = extractMessage(
+			ctx, parentRow, s.repository, s.picturesRepository, s.markupExtractor, prefetched, userCtx.UserID,
+			userCtx.Roles, true, fields, lang,
+		)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return item, nil
+}
+
+func (s *CommentsModerateMessagesRequest) Validate() []*errdetails.BadRequest_FieldViolation {
+	result := make([]*errdetails.BadRequest_FieldViolation, 0)
+
+	if len(s.GetIds()) == 0 {
+		result = append(result, &errdetails.BadRequest_FieldViolation{
+			Field:       "ids",
+			Description: "Value is required and can't be empty",
+		})
+	}
+
+	if len(s.GetIds()) > moderateMessagesMaxCount {
+		result = append(result, &errdetails.BadRequest_FieldViolation{
+			Field:       "ids",
+			Description: fmt.Sprintf("No more than %d messages at once", moderateMessagesMaxCount),
+		})
+	}
+
+	if s.GetBanPeriod() < 0 {
+		result = append(result, &errdetails.BadRequest_FieldViolation{
+			Field:       "ban_period",
+			Description: "Value must be positive",
+		})
+	}
+
+	return result
+}
+
+// ModerateMessages applies the same moderator decision to batch of messages.
+// Actions are applied in order: resolve, delete or restore, move, ban IPs.
+func (s *CommentsGRPCServer) ModerateMessages(
+	ctx context.Context,
+	in *CommentsModerateMessagesRequest,
+) (*emptypb.Empty, error) {
+	userCtx, err := s.auth.ValidateGRPC(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if !util.Contains(userCtx.Roles, users.RoleCommentsModer) {
+		return nil, status.Error(codes.PermissionDenied, "PermissionDenied")
+	}
+
+	InvalidParams := in.Validate()
+	if len(InvalidParams) > 0 {
+		return nil, wrapFieldViolations(InvalidParams)
+	}
+
+	move := in.GetMoveToItemId() > 0
+	if move && !util.Contains(userCtx.Roles, users.RoleForumsModer) {
+		return nil, status.Error(codes.PermissionDenied, "PermissionDenied")
+	}
+
+	banIPs := in.GetBanPeriod() > 0
+	if banIPs && !util.Contains(userCtx.Roles, users.RoleUsersModer) {
+		return nil, status.Error(codes.PermissionDenied, "PermissionDenied")
+	}
+
+	moveToType, err := convertCommentsType(in.GetMoveToTypeId())
+	if err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	ids := util.RemoveDuplicate(in.GetIds())
+	rows := make([]*schema.CommentMessageRow, 0, len(ids))
+
+	messages, err := s.repository.MessagesByIDs(ctx, ids, false, false, true)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	// check all messages before changing any of them
+	for _, id := range ids {
+		row, ok := messages[id]
+		if !ok {
+			return nil, status.Errorf(codes.NotFound, "message %d not found", id)
+		}
+
+		if move && row.TypeID != schema.CommentMessageTypeIDForums {
+			return nil, status.Error(codes.PermissionDenied, "PermissionDenied")
+		}
+
+		if in.GetDeleted().GetValue() && !in.GetResolve() &&
+			row.ModeratorAttention == schema.CommentMessageModeratorAttentionRequired {
+			return nil, status.Errorf(
+				codes.FailedPrecondition, "message %d requires attention and can't be deleted unresolved", id,
+			)
+		}
+
+		rows = append(rows, row)
+	}
+
+	ctx = context.WithoutCancel(ctx)
+
+	for _, row := range rows {
+		err = s.moderateMessage(ctx, userCtx.UserID, row, in, moveToType)
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+	}
+
+	if banIPs {
+		err = s.banAuthorsIPs(ctx, userCtx.UserID, rows, in.GetBanPeriod(), in.GetBanReason())
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+// moderateMessage applies decision to message in one transaction. Events, notifications and search index
+// are updated after commit, their failures are logged only.
+func (s *CommentsGRPCServer) moderateMessage(
+	ctx context.Context, userID int64, row *schema.CommentMessageRow, in *CommentsModerateMessagesRequest,
+	moveToType schema.CommentMessageType,
+) error {
+	decision := comments.ModerationDecision{
+		Resolve:      in.GetResolve(),
+		Delete:       in.GetDeleted() != nil && in.GetDeleted().GetValue(),
+		Restore:      in.GetDeleted() != nil && !in.GetDeleted().GetValue(),
+		Spam:         in.GetSpam(),
+		MoveToType:   moveToType,
+		MoveToItemID: in.GetMoveToItemId(),
+	}
+
+	result, err := s.repository.ModerateMessage(ctx, row.ID, userID, decision)
+	if err != nil {
+		return err
+	}
+
+	events := make([]string, 0)
+
+	if result.Resolved {
+		events = append(events, fmt.Sprintf("Комментарий №%d проверен", row.ID))
+	}
+
+	switch {
+	case decision.Delete:
+		events = append(events, fmt.Sprintf("Комментарий №%d удалён", row.ID))
+	case decision.Restore:
+		events = append(events, fmt.Sprintf("Комментарий №%d восстановлен", row.ID))
+	}
+
+	if result.Moved {
+		events = append(events, fmt.Sprintf(
+			"Комментарий №%d перенесён в тему %d/%d", row.ID, moveToType, in.GetMoveToItemId(),
+		))
+	}
+
+	for _, message := range events {
+		s.logMessageEvent(ctx, userID, row, message)
+	}
+
+	if result.Quarantined {
+		s.notifyRestored(ctx, row.ID)
+	}
+
+	if decision.Delete || decision.Restore {
+		s.searchIndexer.Refresh(ctx, search.KindComment, row.ID)
+	}
+
+	return nil
+}
+
+func (s *CommentsGRPCServer) banAuthorsIPs(
+	ctx context.Context, userID int64, rows []*schema.CommentMessageRow, period int32, reason string,
+) error {
+	banned := make(map[string]bool, len(rows))
+
+	for _, row := range rows {
+		if len(row.IP) == 0 || banned[row.IP.String()] {
+			continue
+		}
+
+		banned[row.IP.String()] = true
+
+		err := s.banRepository.Add(ctx, row.IP, time.Hour*time.Duration(period), userID, reason)
+		if err != nil {
+			return err
+		}
+
+		s.logMessageEvent(ctx, userID, row, fmt.Sprintf(
+			"IP %s автора комментария №%d забанен на %d ч.: %s", row.IP.String(), row.ID, period, reason,
+		))
+	}
+
+	return nil
+}
+
+// logMessageEvent adds event about message to log. Change is committed already, so failure is logged only.
+func (s *CommentsGRPCServer) logMessageEvent(
+	ctx context.Context, userID int64, row *schema.CommentMessageRow, message string,
+) {
+	event := Event{UserID: userID, Message: message}
+	if row.AuthorID.Valid {
+		event.Users = []int64{row.AuthorID.Int64}
+	}
+
+	err := s.events.Add(ctx, event)
+	if err != nil {
+		logrus.Errorf("comments: failed to add event about message %d: %s", row.ID, err.Error())
+	}
+}
@@ -1,11 +1,18 @@
 package goautowp
 
 import (
+	"strconv"
+	"strings"
 	"testing"
 
 	"github.com/autowp/goautowp/config"
+	"github.com/autowp/goautowp/schema"
+	"github.com/doug-martin/goqu/v9"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
 )
 
 func TestAddEmptyCommentShouldReturnError(t *testing.T) {
@@ -364,6 +371,495 @@ func TestCompleteComment(t *testing.T) {
 	require.NoError(t, err)
 }
 
+func TestCommentMarkup(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+
+	client := NewCommentsClient(conn)
+	cfg := config.LoadConfig(".")
+
+	goquDB, err := cnt.GoquDB()
+	require.NoError(t, err)
+
+	userID, token := getUserWithCleanHistory(t, conn, cfg, goquDB, testUsername, testPassword)
+	mention := "@user" + strconv.FormatInt(userID, 10)
+
+	commentItem, err := client.Add(
+		metadata.AppendToOutgoingContext(ctx, authorizationHeader, bearerPrefix+token),
+		&AddCommentRequest{
+			ItemId:  1,
+			TypeId:  CommentsType_ARTICLES_TYPE_ID,
+			Message: "**Hello** " + mention + " <script>alert(1)</script> [x](javascript:alert(1))",
+		},
+	)
+	require.NoError(t, err)
+
+	msg, err := client.GetMessage(
+		metadata.AppendToOutgoingContext(ctx, authorizationHeader, bearerPrefix+token),
+		&GetMessageRequest{
+			Id:     commentItem.GetId(),
+			Fields: &CommentMessageFields{Preview: true, Text: true, TextHtml: true},
+		},
+	)
+	require.NoError(t, err)
+	require.Equal(t, "**Hello** "+mention+" <script>alert(1)</script> [x](javascript:alert(1))", msg.GetText())
+	require.Contains(t, msg.GetTextHtml(), "<strong>Hello</strong>")
+	require.Contains(t, msg.GetTextHtml(), `rel="nofollow">`+mention+"</a>")
+	require.Contains(t, msg.GetTextHtml(), "&lt;script&gt;")
+	require.NotContains(t, msg.GetTextHtml(), "<script>")
+	require.NotContains(t, msg.GetTextHtml(), `href="javascript`)
+	require.True(t, strings.HasPrefix(msg.GetPreview(), "Hello "+mention+" <script>"))
+}
+
+func TestEditComment(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+
+	client := NewCommentsClient(conn)
+	cfg := config.LoadConfig(".")
+
+	goquDB, err := cnt.GoquDB()
+	require.NoError(t, err)
+
+	adminID, adminToken := getUserWithCleanHistory(t, conn, cfg, goquDB, adminUsername, adminPassword)
+	_, token := getUserWithCleanHistory(t, conn, cfg, goquDB, testUsername, testPassword)
+
+	commentItem, err := client.Add(
+		metadata.AppendToOutgoingContext(ctx, authorizationHeader, bearerPrefix+adminToken),
+		&AddCommentRequest{
+			ItemId:  1,
+			TypeId:  CommentsType_ARTICLES_TYPE_ID,
+			Message: "Tets",
+		},
+	)
+	require.NoError(t, err)
+
+	fields := &CommentMessageFields{Text: true, History: true}
+
+	original, err := client.GetMessage(
+		metadata.AppendToOutgoingContext(ctx, authorizationHeader, bearerPrefix+adminToken),
+		&GetMessageRequest{Id: commentItem.GetId(), Fields: fields},
+	)
+	require.NoError(t, err)
+	require.Nil(t, original.GetEditedAt())
+	require.Empty(t, original.GetHistory())
+
+	_, err = client.EditMessage(
+		metadata.AppendToOutgoingContext(ctx, authorizationHeader, bearerPrefix+token),
+		&CommentsEditMessageRequest{Id: commentItem.GetId(), Message: "Hacked"},
+	)
+	require.Error(t, err)
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = client.EditMessage(
+		metadata.AppendToOutgoingContext(ctx, authorizationHeader, bearerPrefix+adminToken),
+		&CommentsEditMessageRequest{Id: commentItem.GetId(), Message: "Test"},
+	)
+	require.NoError(t, err)
+
+	edited, err := client.GetMessage(
+		metadata.AppendToOutgoingContext(ctx, authorizationHeader, bearerPrefix+adminToken),
+		&GetMessageRequest{Id: commentItem.GetId(), Fields: fields},
+	)
+	require.NoError(t, err)
+	require.Equal(t, "Test", edited.GetText())
+	require.NotNil(t, edited.GetEditedAt())
+	require.Equal(t, original.GetCreatedAt().AsTime(), edited.GetCreatedAt().AsTime())
+	require.Len(t, edited.GetHistory(), 1)
+	require.Equal(t, "Tets", edited.GetHistory()[0].GetText())
+	require.Equal(t, adminID, edited.GetHistory()[0].GetUserId())
+
+	// history is available to author and moderators only
+	foreign, err := client.GetMessage(
+		metadata.AppendToOutgoingContext(ctx, authorizationHeader, bearerPrefix+token),
+		&GetMessageRequest{Id: commentItem.GetId(), Fields: fields},
+	)
+	require.NoError(t, err)
+	require.Equal(t, "Test", foreign.GetText())
+	require.Empty(t, foreign.GetHistory())
+
+	anonymous, err := client.GetMessage(ctx, &GetMessageRequest{Id: commentItem.GetId(), Fields: fields})
+	require.NoError(t, err)
+	require.Empty(t, anonymous.GetHistory())
+}
+
+func TestCommentMentions(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+
+	client := NewCommentsClient(conn)
+	cfg := config.LoadConfig(".")
+
+	goquDB, err := cnt.GoquDB()
+	require.NoError(t, err)
+
+	_, adminToken := getUserWithCleanHistory(t, conn, cfg, goquDB, adminUsername, adminPassword)
+	testerID, _ := getUserWithCleanHistory(t, conn, cfg, goquDB, testUsername, testPassword)
+
+	_, err = goquDB.Update(schema.UserTable).
+		Set(goqu.Record{schema.UserTableIdentityColName: testUsername}).
+		Where(schema.UserTableIDCol.Eq(testerID)).
+		Executor().ExecContext(ctx)
+	require.NoError(t, err)
+
+	commentItem, err := client.Add(
+		metadata.AppendToOutgoingContext(ctx, authorizationHeader, bearerPrefix+adminToken),
+		&AddCommentRequest{
+			ItemId:  1,
+			TypeId:  CommentsType_ARTICLES_TYPE_ID,
+			Message: "Hello @" + testUsername + ", @unknown-user",
+		},
+	)
+	require.NoError(t, err)
+
+	mentions, err := client.GetMessages(ctx, &GetMessagesRequest{
+		MentionedUserId: testerID,
+		Order:           GetMessagesRequest_DATE_DESC,
+		Limit:           10,
+		Fields:          &CommentMessageFields{Text: true},
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, mentions.GetItems())
+	require.Equal(t, commentItem.GetId(), mentions.GetItems()[0].GetId())
+
+	_, err = client.EditMessage(
+		metadata.AppendToOutgoingContext(ctx, authorizationHeader, bearerPrefix+adminToken),
+		&CommentsEditMessageRequest{Id: commentItem.GetId(), Message: "Hello everyone"},
+	)
+	require.NoError(t, err)
+
+	mentions, err = client.GetMessages(ctx, &GetMessagesRequest{
+		MentionedUserId: testerID,
+		Order:           GetMessagesRequest_DATE_DESC,
+		Limit:           10,
+	})
+	require.NoError(t, err)
+
+	for _, item := range mentions.GetItems() {
+		require.NotEqual(t, commentItem.GetId(), item.GetId())
+	}
+}
+
+func TestCommentQuarantine(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+
+	client := NewCommentsClient(conn)
+	cfg := config.LoadConfig(".")
+
+	goquDB, err := cnt.GoquDB()
+	require.NoError(t, err)
+
+	_, userToken := getUserWithCleanHistory(t, conn, cfg, goquDB, testUsername, testPassword)
+	_, adminToken := getUserWithCleanHistory(t, conn, cfg, goquDB, adminUsername, adminPassword)
+
+	commentItem, err := client.Add(
+		metadata.AppendToOutgoingContext(ctx, authorizationHeader, bearerPrefix+userToken),
+		&AddCommentRequest{
+			ItemId:  1,
+			TypeId:  CommentsType_ARTICLES_TYPE_ID,
+			Message: "https://a.example.com https://b.example.com https://c.example.com https://d.example.com",
+		},
+	)
+	require.NoError(t, err)
+	require.True(t, commentItem.GetQuarantined())
+
+	msg, err := client.GetMessage(
+		metadata.AppendToOutgoingContext(ctx, authorizationHeader, bearerPrefix+adminToken),
+		&GetMessageRequest{Id: commentItem.GetId()},
+	)
+	require.NoError(t, err)
+	require.True(t, msg.GetDeleted())
+	require.Equal(t, ModeratorAttention_REQUIRED, msg.GetModeratorAttention())
+}
+
+func TestModerationQueue(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+
+	client := NewCommentsClient(conn)
+	cfg := config.LoadConfig(".")
+
+	goquDB, err := cnt.GoquDB()
+	require.NoError(t, err)
+
+	_, userToken := getUserWithCleanHistory(t, conn, cfg, goquDB, testUsername, testPassword)
+	_, adminToken := getUserWithCleanHistory(t, conn, cfg, goquDB, adminUsername, adminPassword)
+
+	commentItem, err := client.Add(
+		metadata.AppendToOutgoingContext(ctx, authorizationHeader, bearerPrefix+userToken),
+		&AddCommentRequest{
+			ItemId:  1,
+			TypeId:  CommentsType_ARTICLES_TYPE_ID,
+			Message: "www.a.example.com www.b.example.com www.c.example.com www.d.example.com",
+		},
+	)
+	require.NoError(t, err)
+	require.True(t, commentItem.GetQuarantined())
+
+	_, err = client.GetModerationQueue(
+		metadata.AppendToOutgoingContext(ctx, authorizationHeader, bearerPrefix+userToken),
+		&CommentsModerationQueueRequest{},
+	)
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	var found *CommentsModerationQueueItem
+
+	cursor := ""
+
+	for found == nil {
+		queue, err := client.GetModerationQueue(
+			metadata.AppendToOutgoingContext(ctx, authorizationHeader, bearerPrefix+adminToken),
+			&CommentsModerationQueueRequest{
+				TypeId:      CommentsType_ARTICLES_TYPE_ID,
+				Quarantined: true,
+				Fields:      &CommentMessageFields{Text: true},
+				Limit:       500,
+				Cursor:      cursor,
+			},
+		)
+		require.NoError(t, err)
+		// limit is clamped
+		require.LessOrEqual(t, len(queue.GetItems()), moderationQueueMaxLimit)
+
+		for _, item := range queue.GetItems() {
+			if item.GetMessage().GetId() == commentItem.GetId() {
+				found = item
+			}
+		}
+
+		cursor = queue.GetNextCursor()
+		if cursor == "" {
+			break
+		}
+	}
+
+	require.NotNil(t, found)
+	require.Positive(t, found.GetSpamScore())
+	require.NotEmpty(t, found.GetSpamReasons())
+	require.Positive(t, found.GetAuthor().GetMessagesCount())
+
+	_, err = client.ModerateMessages(
+		metadata.AppendToOutgoingContext(ctx, authorizationHeader, bearerPrefix+adminToken),
+		&CommentsModerateMessagesRequest{
+			Ids:     []int64{commentItem.GetId()},
+			Deleted: wrapperspb.Bool(true),
+		},
+	)
+	require.Equal(t, codes.FailedPrecondition, status.Code(err))
+
+	_, err = client.ModerateMessages(
+		metadata.AppendToOutgoingContext(ctx, authorizationHeader, bearerPrefix+adminToken),
+		&CommentsModerateMessagesRequest{
+			Ids:     []int64{commentItem.GetId()},
+			Resolve: true,
+			Deleted: wrapperspb.Bool(true),
+		},
+	)
+	require.NoError(t, err)
+
+	msg, err := client.GetMessage(
+		metadata.AppendToOutgoingContext(ctx, authorizationHeader, bearerPrefix+adminToken),
+		&GetMessageRequest{Id: commentItem.GetId()},
+	)
+	require.NoError(t, err)
+	require.True(t, msg.GetDeleted())
+	require.Equal(t, ModeratorAttention_COMPLETE, msg.GetModeratorAttention())
+}
+
+func TestRestoreQuarantinedNotifies(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+
+	client := NewCommentsClient(conn)
+	cfg := config.LoadConfig(".")
+
+	goquDB, err := cnt.GoquDB()
+	require.NoError(t, err)
+
+	_, userToken := getUserWithCleanHistory(t, conn, cfg, goquDB, testUsername, testPassword)
+	adminID, adminToken := getUserWithCleanHistory(t, conn, cfg, goquDB, adminUsername, adminPassword)
+
+	commentItem, err := client.Add(
+		metadata.AppendToOutgoingContext(ctx, authorizationHeader, bearerPrefix+userToken),
+		&AddCommentRequest{
+			ItemId: 1,
+			TypeId: CommentsType_ARTICLES_TYPE_ID,
+			Message: "@user" + strconv.FormatInt(adminID, 10) +
+				" https://a.example.com https://b.example.com https://c.example.com https://d.example.com",
+		},
+	)
+	require.NoError(t, err)
+	require.True(t, commentItem.GetQuarantined())
+
+	mentioned := func() bool {
+		mentions, err := client.GetMessages(ctx, &GetMessagesRequest{
+			MentionedUserId: adminID,
+			Order:           GetMessagesRequest_DATE_DESC,
+			Limit:           10,
+		})
+		require.NoError(t, err)
+
+		for _, item := range mentions.GetItems() {
+			if item.GetId() == commentItem.GetId() {
+				return true
+			}
+		}
+
+		return false
+	}
+
+	require.False(t, mentioned())
+
+	_, err = client.ModerateMessages(
+		metadata.AppendToOutgoingContext(ctx, authorizationHeader, bearerPrefix+adminToken),
+		&CommentsModerateMessagesRequest{
+			Ids:     []int64{commentItem.GetId()},
+			Resolve: true,
+			Deleted: wrapperspb.Bool(false),
+		},
+	)
+	require.NoError(t, err)
+
+	require.True(t, mentioned())
+}
+
+func TestMessageTree(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+
+	client := NewCommentsClient(conn)
+	cfg := config.LoadConfig(".")
+
+	goquDB, err := cnt.GoquDB()
+	require.NoError(t, err)
+
+	_, token := getUserWithCleanHistory(t, conn, cfg, goquDB, adminUsername, adminPassword)
+	authCtx := metadata.AppendToOutgoingContext(ctx, authorizationHeader, bearerPrefix+token)
+
+	previous, err := client.Add(authCtx, &AddCommentRequest{
+		ItemId:  1,
+		TypeId:  CommentsType_ARTICLES_TYPE_ID,
+		Message: "Tree previous root",
+	})
+	require.NoError(t, err)
+
+	var ids []int64
+
+	parentID := int64(0)
+
+	for _, message := range []string{"Tree root", "Tree reply", "Tree reply to reply"} {
+		response, err := client.Add(authCtx, &AddCommentRequest{
+			ItemId:   1,
+			TypeId:   CommentsType_ARTICLES_TYPE_ID,
+			Message:  message,
+			ParentId: parentID,
+		})
+		require.NoError(t, err)
+
+		parentID = response.GetId()
+		ids = append(ids, parentID)
+	}
+
+	tree, err := client.GetMessageTree(authCtx, &GetMessageTreeRequest{
+		TypeId:    CommentsType_ARTICLES_TYPE_ID,
+		ItemId:    1,
+		Order:     GetMessageTreeRequest_NEWEST,
+		MessageId: ids[2],
+		Fields:    &CommentMessageFields{Text: true},
+	})
+	require.NoError(t, err)
+	require.Equal(t, ids, tree.GetPath())
+	require.NotEmpty(t, tree.GetItems())
+
+	root := tree.GetItems()[0]
+	require.Equal(t, ids[0], root.GetMessage().GetId())
+	require.Equal(t, "Tree root", root.GetMessage().GetText())
+	require.Len(t, root.GetReplies(), 1)
+	require.False(t, root.GetHasMoreReplies())
+	require.Equal(t, ids[1], root.GetReplies()[0].GetMessage().GetId())
+	require.Len(t, root.GetReplies()[0].GetReplies(), 1)
+	require.Equal(t, ids[2], root.GetReplies()[0].GetReplies()[0].GetMessage().GetId())
+
+	// deep link in the middle of topic can be scrolled back
+	older, err := client.GetMessageTree(authCtx, &GetMessageTreeRequest{
+		TypeId:    CommentsType_ARTICLES_TYPE_ID,
+		ItemId:    1,
+		Order:     GetMessageTreeRequest_OLDEST,
+		MessageId: ids[2],
+		Limit:     1,
+	})
+	require.NoError(t, err)
+	require.Len(t, older.GetItems(), 1)
+	require.Equal(t, ids[0], older.GetItems()[0].GetMessage().GetId())
+	require.NotEmpty(t, older.GetPrevCursor())
+
+	prevPage, err := client.GetMessageTree(authCtx, &GetMessageTreeRequest{
+		TypeId: CommentsType_ARTICLES_TYPE_ID,
+		ItemId: 1,
+		Order:  GetMessageTreeRequest_OLDEST,
+		Limit:  1,
+		Cursor: older.GetPrevCursor(),
+	})
+	require.NoError(t, err)
+	require.Len(t, prevPage.GetItems(), 1)
+	require.Less(t, prevPage.GetItems()[0].GetMessage().GetId(), ids[0])
+	require.NotEmpty(t, prevPage.GetNextCursor())
+
+	if prevPage.GetItems()[0].GetMessage().GetId() == previous.GetId() {
+		nextPage, err := client.GetMessageTree(authCtx, &GetMessageTreeRequest{
+			TypeId: CommentsType_ARTICLES_TYPE_ID,
+			ItemId: 1,
+			Order:  GetMessageTreeRequest_OLDEST,
+			Limit:  1,
+			Cursor: prevPage.GetNextCursor(),
+		})
+		require.NoError(t, err)
+		require.Len(t, nextPage.GetItems(), 1)
+		require.Equal(t, ids[0], nextPage.GetItems()[0].GetMessage().GetId())
+	}
+
+	// replies of all branches of a level are loaded together
+	deep, err := client.GetMessageTree(authCtx, &GetMessageTreeRequest{
+		TypeId:   CommentsType_ARTICLES_TYPE_ID,
+		ItemId:   1,
+		ParentId: ids[0],
+		Depth:    1,
+	})
+	require.NoError(t, err)
+	require.Len(t, deep.GetItems(), 1)
+	require.Len(t, deep.GetItems()[0].GetReplies(), 1)
+	require.Equal(t, ids[2], deep.GetItems()[0].GetReplies()[0].GetMessage().GetId())
+	require.False(t, deep.GetItems()[0].GetHasMoreReplies())
+
+	// without deep link the second level is not loaded when depth is 0
+	subtree, err := client.GetMessageTree(authCtx, &GetMessageTreeRequest{
+		TypeId:   CommentsType_ARTICLES_TYPE_ID,
+		ItemId:   1,
+		ParentId: ids[0],
+	})
+	require.NoError(t, err)
+	require.Len(t, subtree.GetItems(), 1)
+	require.Equal(t, ids[1], subtree.GetItems()[0].GetMessage().GetId())
+	require.Equal(t, int32(1), subtree.GetItems()[0].GetRepliesCount())
+	require.True(t, subtree.GetItems()[0].GetHasMoreReplies())
+	require.Empty(t, subtree.GetItems()[0].GetReplies())
+
+	_, err = client.GetMessageTree(authCtx, &GetMessageTreeRequest{
+		TypeId: CommentsType_ARTICLES_TYPE_ID,
+		ItemId: 1,
+		Cursor: "invalid",
+	})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
 func TestMessagesByUserIdentity(t *testing.T) {
 	t.Parallel()
 
@@ -0,0 +1,280 @@
+package goautowp
+
+import (
+	"context"
+	"slices"
+
+	"github.com/autowp/goautowp/comments"
+	"github.com/autowp/goautowp/pictures"
+	"github.com/autowp/goautowp/schema"
+	"github.com/autowp/goautowp/util"
+)
+
+const (
+	messageTreeDefaultLimit        = 20
+	messageTreeDefaultRepliesLimit = 5
+	messageTreeMaxLimit            = 100
+	messageTreeMaxDepth            = 5
+	// messageTreeMaxNodes limits size of response, branches beyond it are left for lazy loading.
+	messageTreeMaxNodes = 500
+)
+
+// messageTreeBuilder loads slice of thread breadth-first, so replies budget is shared by branches evenly.
+type messageTreeBuilder struct {
+	repository         *comments.Repository
+	picturesRepository *pictures.Repository
+	markupExtractor    *MarkupExtractor
+	userCtx            UserContext
+	canViewIP          bool
+	fields             *CommentMessageFields
+	lang               string
+	request            comments.Request
+	keyset             util.Keyset[*schema.CommentMessageRow]
+	depth              int32
+	repliesLimit       int32
+	nodesLeft          int32
+}
+
+type messageTreeBranch struct {
+	node  *APICommentsMessageTreeNode
+	level int32
+	path  []int64
+}
+
+// build returns messages of parentID, top level of topic when 0, with replies and cursors of the next
+// and previous pages. When path is not empty, each branch on the path starts with node of the path.
+func (s *messageTreeBuilder) build(
+	ctx context.Context, parentID int64, cursor string, limit int32, path []int64,
+) ([]*APICommentsMessageTreeNode, string, string, error) {
+	nodes, next, prev, err := s.children(ctx, parentID, cursor, limit, path)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	level := make([]messageTreeBranch, 0, len(nodes))
+	for _, node := range nodes {
+		level = append(level, messageTreeBranch{node: node, level: 0, path: childPath(node, path)})
+	}
+
+	for len(level) > 0 {
+		level, err = s.expand(ctx, level)
+		if err != nil {
+			return nil, "", "", err
+		}
+	}
+
+	return nodes, next, prev, nil
+}
+
+// expand loads replies of branches of one level and returns branches of the next level.
+// Replies of all branches are loaded with one query, except branches on the path of deep link,
+// which start with node of the path.
+func (s *messageTreeBuilder) expand(ctx context.Context, level []messageTreeBranch) ([]messageTreeBranch, error) {
+	var err error
+
+	parentIDs := make([]int64, 0, len(level))
+
+	for _, branch := range level {
+		if s.batched(branch) {
+			parentIDs = append(parentIDs, branch.node.GetMessage().GetId())
+		}
+	}
+
+	replies := make(map[int64][]*schema.CommentMessageRow)
+	cursors := make(map[int64]string)
+
+	if len(parentIDs) > 0 && s.nodesLeft > 0 {
+		replies, cursors, err = s.repository.RepliesByParents(ctx, s.request, s.keyset, parentIDs, s.repliesLimit)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// replies budget is shared by branches in order
+	loaded := make([]*schema.CommentMessageRow, 0)
+
+	for _, branch := range level {
+		if !s.batched(branch) {
+			continue
+		}
+
+		id := branch.node.GetMessage().GetId()
+
+		if s.nodesLeft <= 0 {
+			delete(replies, id)
+
+			continue
+		}
+
+		rows := replies[id]
+		if take := int(max(min(s.repliesLimit, s.nodesLeft), 1)); len(rows) > take {
+			rows = rows[:take]
+			cursors[id] = s.keyset.Encode(rows[len(rows)-1])
+		}
+
+		// branch without visible replies is loaded too
+		replies[id] = rows
+
+		s.nodesLeft -= int32(len(rows)) //nolint: gosec
+
+		loaded = append(loaded, rows...)
+	}
+
+	prefetched, err := prefetchMessages(
+		ctx, s.markupExtractor, s.repository, loaded, s.userCtx.UserID, s.userCtx.Roles, s.fields, s.lang,
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	next := make([]messageTreeBranch, 0, len(loaded))
+
+	for _, branch := range level {
+		if branch.node.GetRepliesCount() == 0 {
+			continue
+		}
+
+		branch.node.HasMoreReplies = true
+
+		id := branch.node.GetMessage().GetId()
+
+		var (
+			nodes        []*APICommentsMessageTreeNode
+			cursor, prev string
+		)
+
+		if len(branch.path) > 0 {
+			nodes, cursor, prev, err = s.children(ctx, id, "", s.repliesLimit, branch.path)
+		} else {
+			rows, ok := replies[id]
+			if !ok {
+				continue
+			}
+
+			cursor = cursors[id]
+			nodes, err = s.nodes(ctx, rows, prefetched)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		branch.node.Replies = nodes
+		branch.node.RepliesCursor = cursor
+		branch.node.RepliesPrevCursor = prev
+		branch.node.HasMoreReplies = len(cursor) > 0
+
+		for _, reply := range nodes {
+			next = append(next, messageTreeBranch{
+				node:  reply,
+				level: branch.level + 1,
+				path:  childPath(reply, branch.path),
+			})
+		}
+	}
+
+	return next, nil
+}
+
+// batched reports whether replies of branch are loaded with replies of other branches of the level.
+func (s *messageTreeBuilder) batched(branch messageTreeBranch) bool {
+	return branch.node.GetRepliesCount() > 0 && len(branch.path) == 0 && branch.level < s.depth
+}
+
+// children loads page of messages of parentID. Cursor may be the previous page cursor, such page is loaded
+// in reverse order. When path is not empty, page starts with node of the path.
+func (s *messageTreeBuilder) children(
+	ctx context.Context, parentID int64, cursor string, limit int32, path []int64,
+) ([]*APICommentsMessageTreeNode, string, string, error) {
+	var (
+		err        error
+		rows       []*schema.CommentMessageRow
+		next, prev string
+	)
+
+	request := s.request
+	request.ParentID = parentID
+	request.NoParents = parentID == 0
+	// node of the path is loaded even when budget is exhausted
+	request.PerPage = max(min(limit, s.nodesLeft), 1)
+
+	if len(path) > 0 {
+		cursor, err = s.repository.CursorAt(ctx, request, s.keyset, path[0])
+		if err != nil {
+			return nil, "", "", err
+		}
+	}
+
+	reversed := s.keyset.Reverse()
+
+	if _, err = reversed.Decode(cursor); len(cursor) > 0 && err == nil {
+		rows, prev, err = s.repository.MessagesByCursor(ctx, request, reversed, cursor)
+		if err != nil {
+			return nil, "", "", err
+		}
+
+		slices.Reverse(rows)
+
+		if len(rows) > 0 {
+			next = s.keyset.Encode(rows[len(rows)-1])
+		}
+	} else {
+		rows, next, err = s.repository.MessagesByCursor(ctx, request, s.keyset, cursor)
+		if err != nil {
+			return nil, "", "", err
+		}
+
+		// cursor points after preceding message, so there is something before the page
+		if len(cursor) > 0 && len(rows) > 0 {
+			prev = reversed.Encode(rows[0])
+		}
+	}
+
+	s.nodesLeft -= int32(len(rows)) //nolint: gosec
+
+	prefetched, err := prefetchMessages(
+		ctx, s.markupExtractor, s.repository, rows, s.userCtx.UserID, s.userCtx.Roles, s.fields, s.lang,
+	)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	nodes, err := s.nodes(ctx, rows, prefetched)
+	if err != nil {
+		return nil, "", "", err
+	}
+
+	return nodes, next, prev, nil
+}
+
+func (s *messageTreeBuilder) nodes(
+	ctx context.Context, rows []*schema.CommentMessageRow, prefetched messagesPrefetch,
+) ([]*APICommentsMessageTreeNode, error) {
+	nodes := make([]*APICommentsMessageTreeNode, 0, len(rows))
+
+	for _, row := range rows {
+		msg, err := extractMessage(
+			ctx, row, s.repository, s.picturesRepository, s.markupExtractor, prefetched, s.userCtx.UserID,
+			s.userCtx.Roles, s.canViewIP, s.fields, s.lang,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		nodes = append(nodes, &APICommentsMessageTreeNode{ //nolint:exhaustruct
+			Message:      msg,
+			RepliesCount: row.RepliesCount,
+		})
+	}
+
+	return nodes, nil
+}
+
+// childPath returns rest of the path when node is on it.
+func childPath(node *APICommentsMessageTreeNode, path []int64) []int64 {
+	if len(path) > 1 && path[0] == node.GetMessage().GetId() {
+		return path[1:]
+	}
+
+	return nil
+}
@@ -0,0 +1,158 @@
+package comments
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/autowp/goautowp/schema"
+	"github.com/autowp/goautowp/util"
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	notificationsInterval         = time.Second
+	notificationsBatchSize        = 100
+	notificationLeaseMinutes      = 10
+	notificationMaxBackoffMinutes = 24 * 60
+)
+
+// NotifySubscribers queues notification of topic subscribers about new message,
+// it is delivered by ProcessNotifications out of request.
+func (s *Repository) NotifySubscribers(ctx context.Context, messageID int64) error {
+	_, err := s.db.Insert(schema.CommentNotificationQueueTable).
+		Rows(goqu.Record{schema.CommentNotificationQueueTableMessageIDColName: messageID}).
+		OnConflict(goqu.DoNothing()).
+		Executor().ExecContext(ctx)
+
+	return err
+}
+
+// claimNotifications takes up to limit queued notifications and leases them to the caller,
+// so concurrent workers skip them. Lease expires if the worker dies before it is done.
+func (s *Repository) claimNotifications(
+	ctx context.Context, limit uint,
+) ([]schema.CommentNotificationQueueRow, error) {
+	rows := make([]schema.CommentNotificationQueueRow, 0)
+
+	err := s.db.WithTx(func(tx *goqu.TxDatabase) error {
+		err := tx.Select(
+			schema.CommentNotificationQueueTableMessageIDCol,
+			schema.CommentNotificationQueueTableAttemptsCol,
+		).
+			From(schema.CommentNotificationQueueTable).
+			Where(schema.CommentNotificationQueueTableNextAttemptAtCol.Lte(goqu.L("NOW()"))).
+			Order(schema.CommentNotificationQueueTableCreatedAtCol.Asc()).
+			Limit(limit).
+			ForUpdate(exp.SkipLocked).
+			ScanStructsContext(ctx, &rows)
+		if err != nil || len(rows) == 0 {
+			return err
+		}
+
+		ids := make([]int64, 0, len(rows))
+		for _, row := range rows {
+			ids = append(ids, row.MessageID)
+		}
+
+		_, err = tx.Update(schema.CommentNotificationQueueTable).
+			Set(goqu.Record{
+				schema.CommentNotificationQueueTableNextAttemptAtColName: goqu.L(
+					"NOW() + INTERVAL ? MINUTE", notificationLeaseMinutes,
+				),
+			}).
+			Where(schema.CommentNotificationQueueTableMessageIDCol.In(ids)).
+			Executor().ExecContext(ctx)
+
+		return err
+	})
+
+	return rows, err
+}
+
+// ProcessNotifications delivers up to limit queued notifications and returns number of processed.
+// Failed notifications are postponed with exponential backoff and don't block the rest of the queue.
+func (s *Repository) ProcessNotifications(ctx context.Context, limit uint) (int, error) {
+	rows, err := s.claimNotifications(ctx, limit)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, row := range rows {
+		err = s.notifySubscribers(ctx, row.MessageID)
+		if err != nil {
+			logrus.Errorf("comments: failed to notify subscribers about message %d: %s", row.MessageID, err.Error())
+
+			err = s.postponeNotification(ctx, row)
+			if err != nil {
+				return 0, err
+			}
+
+			continue
+		}
+
+		_, err = util.ExecAndRetryOnDeadlock(ctx,
+			s.db.Delete(schema.CommentNotificationQueueTable).
+				Where(schema.CommentNotificationQueueTableMessageIDCol.Eq(row.MessageID)).
+				Executor(),
+		)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	return len(rows), nil
+}
+
+func (s *Repository) postponeNotification(ctx context.Context, row schema.CommentNotificationQueueRow) error {
+	backoff := int(min(math.Pow(2, float64(row.Attempts)), notificationMaxBackoffMinutes)) //nolint: mnd
+
+	_, err := util.ExecAndRetryOnDeadlock(ctx,
+		s.db.Update(schema.CommentNotificationQueueTable).
+			Set(goqu.Record{
+				schema.CommentNotificationQueueTableAttemptsColName: goqu.L(
+					"? + 1", goqu.C(schema.CommentNotificationQueueTableAttemptsColName),
+				),
+				schema.CommentNotificationQueueTableNextAttemptAtColName: goqu.L(
+					"NOW() + INTERVAL ? MINUTE", backoff,
+				),
+			}).
+			Where(schema.CommentNotificationQueueTableMessageIDCol.Eq(row.MessageID)).
+			Executor(),
+	)
+
+	return err
+}
+
+// ProcessNotificationsPeriodically drains notification queue until quit.
+func (s *Repository) ProcessNotificationsPeriodically(ctx context.Context, quitChan chan bool) {
+	ticker := time.NewTicker(notificationsInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			for {
+				processed, err := s.ProcessNotifications(ctx, notificationsBatchSize)
+				if err != nil {
+					logrus.Errorf("comments: failed to process notifications: %s", err.Error())
+				}
+
+				if err != nil || processed < notificationsBatchSize {
+					break
+				}
+
+				select {
+				case <-quitChan:
+					return
+				default:
+				}
+			}
+
+		case <-quitChan:
+			return
+		}
+	}
+}
@@ -7,19 +7,22 @@ import (
 	"fmt"
 	"math"
 	"net/url"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
 
 	"github.com/autowp/goautowp/frontend"
 	"github.com/autowp/goautowp/hosts"
-	"github.com/autowp/goautowp/messaging"
+	"github.com/autowp/goautowp/markup"
+	"github.com/autowp/goautowp/notifications"
 	"github.com/autowp/goautowp/query"
 	"github.com/autowp/goautowp/schema"
 	"github.com/autowp/goautowp/users"
 	"github.com/autowp/goautowp/util"
 	"github.com/doug-martin/goqu/v9"
 	"github.com/doug-martin/goqu/v9/exp"
+	"github.com/sirupsen/logrus"
 )
 
 var (
@@ -36,6 +39,7 @@ var (
 		"comment with moderation attention requirement can't be deleted",
 	)
 	errFailedToBuildURL = errors.New("failed to build URL")
+	errBrokenThread     = errors.New("broken thread")
 )
 
 const CommentMessagePreviewLength = 60
@@ -44,6 +48,15 @@ const deleteTTLDays = 300
 
 const MaxMessageLength = 16 * 1024
 
+// maxThreadDepth protects walking up the thread from cycles in broken data.
+const maxThreadDepth = 1000
+
+// MessageEditPeriod is time after posting while author can edit message.
+const MessageEditPeriod = time.Hour
+
+// MessageHistoryLimit is number of latest revisions shown in history of message.
+const MessageHistoryLimit = 20
+
 type RatingFan struct {
 	UserID int64 `db:"user_id"`
 	Volume int64 `db:"volume"`
@@ -60,6 +73,7 @@ type Request struct {
 	ParentID           int64
 	NoParents          bool
 	UserID             int64
+	MentionedUserID    int64
 	Order              []exp.OrderedExpression
 	ModeratorAttention schema.CommentMessageModeratorAttention
 	PicturesOfItemID   int64
@@ -68,6 +82,20 @@ type Request struct {
 	FetchIP            bool
 	PerPage            int32
 	Page               int32
+	// Quarantined selects messages hidden by classifier and not deleted by moderators
+	Quarantined bool
+	// HideBlockedBy excludes messages of authors blocked by user
+	HideBlockedBy int64
+}
+
+// AuthorReputation is summary of author's comments history.
+type AuthorReputation struct {
+	AuthorID        int64        `db:"author_id"`
+	RegDate         sql.NullTime `db:"reg_date"`
+	MessagesCount   int32        `db:"messages_count"`
+	DeletedCount    int32        `db:"deleted_count"`
+	AttentionsCount int32        `db:"attentions_count"`
+	Votes           int32        `db:"votes"`
 }
 
 type RatingUser struct {
@@ -75,26 +103,58 @@ type RatingUser struct {
 	Volume   int64 `db:"volume"`
 }
 
+// ModerationDecision is decision of moderator applied to message by ModerateMessage.
+type ModerationDecision struct {
+	// Resolve marks required attention of moderator as completed
+	Resolve bool
+	// Delete deletes message, Restore restores deleted or quarantined one
+	Delete  bool
+	Restore bool
+	// Spam marks deleted message as spam, such messages are used to train classifier
+	Spam bool
+	// MoveToItemID moves message with its replies to another topic when positive
+	MoveToType   schema.CommentMessageType
+	MoveToItemID int64
+}
+
+// ModerationResult describes changes made by ModerateMessage.
+type ModerationResult struct {
+	Resolved bool
+	// Quarantined is true when restored message was quarantined, so notifications skipped on posting are due
+	Quarantined bool
+	Moved       bool
+}
+
+// queryBuilder is implemented by both goqu.Database and goqu.TxDatabase, so helpers taking it
+// are shared by standalone writes and writes within transaction of caller.
+type queryBuilder interface {
+	From(from ...interface{}) *goqu.SelectDataset
+	Select(cols ...interface{}) *goqu.SelectDataset
+	Insert(table interface{}) *goqu.InsertDataset
+	Update(table interface{}) *goqu.UpdateDataset
+	Delete(table interface{}) *goqu.DeleteDataset
+}
+
 // Repository Main Object.
 type Repository struct {
-	db                *goqu.Database
-	userRepository    *users.Repository
-	messageRepository *messaging.Repository
-	hostManager       *hosts.Manager
+	db             *goqu.Database
+	userRepository *users.Repository
+	notifier       *notifications.Notifier
+	hostManager    *hosts.Manager
 }
 
 // NewRepository constructor.
 func NewRepository(
 	db *goqu.Database,
 	userRepository *users.Repository,
-	messageRepository *messaging.Repository,
+	notifier *notifications.Notifier,
 	hostManager *hosts.Manager,
 ) *Repository {
 	return &Repository{
-		db:                db,
-		userRepository:    userRepository,
-		messageRepository: messageRepository,
-		hostManager:       hostManager,
+		db:             db,
+		userRepository: userRepository,
+		notifier:       notifier,
+		hostManager:    hostManager,
 	}
 }
 
@@ -221,14 +281,22 @@ func (s *Repository) View(
 	return err
 }
 
+// QueueDeleteMessage deletes message by moderator. Messages deleted as spam are used to train classifier.
 func (s *Repository) QueueDeleteMessage(
 	ctx context.Context,
 	commentID int64,
 	byUserID int64,
+	spam bool,
+) error {
+	return s.deleteMessageByModer(ctx, s.db, commentID, byUserID, spam)
+}
+
+func (s *Repository) deleteMessageByModer(
+	ctx context.Context, db queryBuilder, commentID int64, byUserID int64, spam bool,
 ) error {
 	var moderatorAttention schema.CommentMessageModeratorAttention
 
-	success, err := s.db.Select(schema.CommentMessageTableModeratorAttentionCol).
+	success, err := db.Select(schema.CommentMessageTableModeratorAttentionCol).
 		From(schema.CommentMessageTable).
 		Where(schema.CommentMessageTableIDCol.Eq(commentID)).
 		ScanValContext(ctx, &moderatorAttention)
@@ -244,11 +312,12 @@ func (s *Repository) QueueDeleteMessage(
 		return errCommentWithModerAttentionCantBeDeleted
 	}
 
-	_, err = s.db.Update(schema.CommentMessageTable).
+	_, err = db.Update(schema.CommentMessageTable).
 		Set(goqu.Record{
-			schema.CommentMessageTableDeletedColName:    1,
-			schema.CommentMessageTableDeletedByColName:  byUserID,
-			schema.CommentMessageTableDeleteDateColName: goqu.Func("NOW"),
+			schema.CommentMessageTableDeletedColName:       1,
+			schema.CommentMessageTableDeletedByColName:     byUserID,
+			schema.CommentMessageTableDeleteDateColName:    goqu.Func("NOW"),
+			schema.CommentMessageTableDeletedAsSpamColName: spam,
 		}).
 		Where(schema.CommentMessageTableIDCol.Eq(commentID)).
 		Executor().ExecContext(ctx)
@@ -256,16 +325,90 @@ func (s *Repository) QueueDeleteMessage(
 	return err
 }
 
-func (s *Repository) RestoreMessage(ctx context.Context, commentID int64) error {
-	_, err := s.db.Update(schema.CommentMessageTable).
-		Set(goqu.Record{
-			schema.CommentMessageTableDeletedColName:    0,
-			schema.CommentMessageTableDeleteDateColName: nil,
-		}).
+// RestoreMessage restores deleted message. Returns true when message was quarantined,
+// so notifications skipped on posting are due now.
+func (s *Repository) RestoreMessage(ctx context.Context, commentID int64) (bool, error) {
+	return s.restoreMessage(ctx, s.db, commentID)
+}
+
+func (s *Repository) restoreMessage(ctx context.Context, db queryBuilder, commentID int64) (bool, error) {
+	record := goqu.Record{
+		schema.CommentMessageTableDeletedColName:       0,
+		schema.CommentMessageTableDeleteDateColName:    nil,
+		schema.CommentMessageTableDeletedAsSpamColName: 0,
+	}
+
+	// quarantined message is restored with separate statement, so concurrent restores notify once
+	res, err := db.Update(schema.CommentMessageTable).
+		Set(record).
+		Where(
+			schema.CommentMessageTableIDCol.Eq(commentID),
+			schema.CommentMessageTableDeletedCol.IsTrue(),
+			schema.CommentMessageTableDeletedByCol.IsNull(),
+		).
+		Executor().ExecContext(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	if affected > 0 {
+		return true, nil
+	}
+
+	_, err = db.Update(schema.CommentMessageTable).
+		Set(record).
 		Where(schema.CommentMessageTableIDCol.Eq(commentID)).
 		Executor().ExecContext(ctx)
 
-	return err
+	return false, err
+}
+
+// ModerateMessage applies decision of moderator to message in one transaction.
+// Actions are applied in order: resolve, delete or restore, move.
+func (s *Repository) ModerateMessage(
+	ctx context.Context, messageID int64, userID int64, decision ModerationDecision,
+) (ModerationResult, error) {
+	var result ModerationResult
+
+	ctx = context.WithoutCancel(ctx)
+
+	err := s.db.WithTx(func(tx *goqu.TxDatabase) error {
+		var err error
+
+		if decision.Resolve {
+			result.Resolved, err = s.completeMessage(ctx, tx, messageID)
+			if err != nil {
+				return err
+			}
+		}
+
+		switch {
+		case decision.Delete:
+			err = s.deleteMessageByModer(ctx, tx, messageID, userID, decision.Spam)
+		case decision.Restore:
+			result.Quarantined, err = s.restoreMessage(ctx, tx, messageID)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		if decision.MoveToItemID > 0 {
+			result.Moved, err = s.moveMessage(ctx, tx, messageID, decision.MoveToType, decision.MoveToItemID)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+
+	return result, err
 }
 
 func (s *Repository) GetCommentType(
@@ -292,30 +435,37 @@ func (s *Repository) GetCommentType(
 func (s *Repository) MoveMessage(
 	ctx context.Context, commentID int64, dstType schema.CommentMessageType, dstItemID int64,
 ) error {
+	_, err := s.moveMessage(context.WithoutCancel(ctx), s.db, commentID, dstType, dstItemID)
+
+	return err
+}
+
+// moveMessage moves message with its replies to another topic. Returns false when message is there already.
+func (s *Repository) moveMessage(
+	ctx context.Context, db queryBuilder, commentID int64, dstType schema.CommentMessageType, dstItemID int64,
+) (bool, error) {
 	st := struct {
 		SrcType   schema.CommentMessageType `db:"type_id"`
 		SrcItemID int64                     `db:"item_id"`
 	}{}
 
-	success, err := s.db.Select(schema.CommentMessageTableTypeIDCol, schema.CommentMessageTableItemIDCol).
+	success, err := db.Select(schema.CommentMessageTableTypeIDCol, schema.CommentMessageTableItemIDCol).
 		From(schema.CommentMessageTable).
 		Where(schema.CommentMessageTableIDCol.Eq(commentID)).
 		ScanStructContext(ctx, &st)
 	if err != nil {
-		return err
+		return false, err
 	}
 
 	if !success {
-		return sql.ErrNoRows
+		return false, sql.ErrNoRows
 	}
 
 	if st.SrcItemID == dstItemID && st.SrcType == dstType {
-		return nil
+		return false, nil
 	}
 
-	ctx = context.WithoutCancel(ctx)
-
-	_, err = s.db.Update(schema.CommentMessageTable).
+	_, err = db.Update(schema.CommentMessageTable).
 		Set(goqu.Record{
 			schema.CommentMessageTableTypeIDColName:   dstType,
 			schema.CommentMessageTableItemIDColName:   dstItemID,
@@ -324,29 +474,30 @@ func (s *Repository) MoveMessage(
 		Where(schema.CommentMessageTableIDCol.Eq(commentID)).
 		Executor().ExecContext(ctx)
 	if err != nil {
-		return err
+		return false, err
 	}
 
-	err = s.moveMessageRecursive(ctx, commentID, dstType, dstItemID)
+	err = s.moveMessageRecursive(ctx, db, commentID, dstType, dstItemID)
 	if err != nil {
-		return err
+		return false, err
 	}
 
-	err = s.updateTopicStat(ctx, st.SrcType, st.SrcItemID)
+	err = s.updateTopicStat(ctx, db, st.SrcType, st.SrcItemID)
 	if err != nil {
-		return err
+		return false, err
 	}
 
-	return s.updateTopicStat(ctx, dstType, dstItemID)
+	return true, s.updateTopicStat(ctx, db, dstType, dstItemID)
 }
 
 func (s *Repository) moveMessageRecursive(
 	ctx context.Context,
+	db queryBuilder,
 	parentID int64,
 	dstType schema.CommentMessageType,
 	dstItemID int64,
 ) error {
-	_, err := s.db.Update(schema.CommentMessageTable).
+	_, err := db.Update(schema.CommentMessageTable).
 		Set(goqu.Record{
 			schema.CommentMessageTableTypeIDColName: dstType,
 			schema.CommentMessageTableItemIDColName: dstItemID,
@@ -359,7 +510,7 @@ func (s *Repository) moveMessageRecursive(
 
 	var ids []int64
 
-	err = s.db.Select(schema.CommentMessageTableIDCol).
+	err = db.Select(schema.CommentMessageTableIDCol).
 		From(schema.CommentMessageTable).
 		Where(schema.CommentMessageTableParentIDCol.Eq(parentID)).
 		ScanValsContext(ctx, &ids)
@@ -368,7 +519,7 @@ func (s *Repository) moveMessageRecursive(
 	}
 
 	for _, id := range ids {
-		err = s.moveMessageRecursive(ctx, id, dstType, dstItemID)
+		err = s.moveMessageRecursive(ctx, db, id, dstType, dstItemID)
 		if err != nil {
 			return err
 		}
@@ -379,6 +530,7 @@ func (s *Repository) moveMessageRecursive(
 
 func (s *Repository) updateTopicStat(
 	ctx context.Context,
+	db queryBuilder,
 	commentType schema.CommentMessageType,
 	itemID int64,
 ) error {
@@ -387,7 +539,7 @@ func (s *Repository) updateTopicStat(
 		LastUpdate    *sql.NullTime `db:"last_update"`
 	}{}
 
-	success, err := s.db.Select(
+	success, err := db.Select(
 		goqu.COUNT(goqu.Star()).As("count"),
 		goqu.MAX(schema.CommentMessageTableDatetimeCol).As("last_update"),
 	).
@@ -408,7 +560,7 @@ func (s *Repository) updateTopicStat(
 	ctx = context.WithoutCancel(ctx)
 
 	if st.MessagesCount <= 0 {
-		_, err = s.db.Delete(schema.CommentTopicTable).Where(
+		_, err = db.Delete(schema.CommentTopicTable).Where(
 			schema.CommentTopicTableTypeIDCol.Eq(commentType),
 			schema.CommentTopicTableItemIDCol.Eq(itemID),
 		).Executor().ExecContext(ctx)
@@ -417,7 +569,7 @@ func (s *Repository) updateTopicStat(
 	}
 
 	if st.LastUpdate.Valid {
-		_, err = s.db.Insert(schema.CommentTopicTable).Rows(goqu.Record{
+		_, err = db.Insert(schema.CommentTopicTable).Rows(goqu.Record{
 			schema.CommentTopicTableItemIDColName:     itemID,
 			schema.CommentTopicTableTypeIDColName:     commentType,
 			schema.CommentTopicTableLastUpdateColName: st.LastUpdate.Time.Format(time.DateTime),
@@ -548,7 +700,14 @@ func (s *Repository) updateVote(ctx context.Context, commentID int64) (int32, er
 }
 
 func (s *Repository) CompleteMessage(ctx context.Context, id int64) error {
-	_, err := s.db.Update(schema.CommentMessageTable).
+	_, err := s.completeMessage(ctx, s.db, id)
+
+	return err
+}
+
+// completeMessage marks required attention of moderator as completed. Returns false when nothing was required.
+func (s *Repository) completeMessage(ctx context.Context, db queryBuilder, id int64) (bool, error) {
+	res, err := db.Update(schema.CommentMessageTable).
 		Set(goqu.Record{
 			schema.CommentMessageTableModeratorAttentionColName: schema.CommentMessageModeratorAttentionCompleted,
 		}).
@@ -557,10 +716,72 @@ func (s *Repository) CompleteMessage(ctx context.Context, id int64) error {
 			schema.CommentMessageTableModeratorAttentionCol.Eq(schema.CommentMessageModeratorAttentionRequired),
 		).
 		Executor().ExecContext(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+
+	return affected > 0, nil
+}
+
+// RequireAttention marks message as requiring attention of moderators.
+func (s *Repository) RequireAttention(ctx context.Context, id int64) error {
+	_, err := s.db.Update(schema.CommentMessageTable).
+		Set(goqu.Record{
+			schema.CommentMessageTableModeratorAttentionColName: schema.CommentMessageModeratorAttentionRequired,
+		}).
+		Where(schema.CommentMessageTableIDCol.Eq(id)).
+		Executor().ExecContext(ctx)
 
 	return err
 }
 
+// ClearAttention removes attention flag of message, e.g. when edited message is no longer suspicious.
+func (s *Repository) ClearAttention(ctx context.Context, id int64) error {
+	_, err := s.db.Update(schema.CommentMessageTable).
+		Set(goqu.Record{
+			schema.CommentMessageTableModeratorAttentionColName: schema.CommentMessageModeratorAttentionNone,
+		}).
+		Where(
+			schema.CommentMessageTableIDCol.Eq(id),
+			schema.CommentMessageTableModeratorAttentionCol.Eq(schema.CommentMessageModeratorAttentionRequired),
+		).
+		Executor().ExecContext(ctx)
+
+	return err
+}
+
+// Quarantine hides message until moderator restores or deletes it.
+// Deleter is left empty to distinguish quarantine from deletion by moderator.
+func (s *Repository) Quarantine(ctx context.Context, id int64) error {
+	_, err := s.db.Update(schema.CommentMessageTable).
+		Set(goqu.Record{
+			schema.CommentMessageTableDeletedColName:            1,
+			schema.CommentMessageTableDeletedByColName:          nil,
+			schema.CommentMessageTableDeleteDateColName:         nil,
+			schema.CommentMessageTableModeratorAttentionColName: schema.CommentMessageModeratorAttentionRequired,
+		}).
+		Where(schema.CommentMessageTableIDCol.Eq(id)).
+		Executor().ExecContext(ctx)
+
+	return err
+}
+
+// Moderation is state new message is stored with.
+type Moderation struct {
+	// Attention marks message as requiring attention of moderators
+	Attention bool
+	// Quarantine hides message until moderator restores or deletes it
+	Quarantine bool
+	// Store is called in transaction of insert to keep reasons of the state, e.g. verdict of classifier
+	Store func(ctx context.Context, tx *goqu.TxDatabase, messageID int64) error
+}
+
+// Add stores message together with its moderation state, so message is never visible before it is classified.
 func (s *Repository) Add(
 	ctx context.Context,
 	typeID schema.CommentMessageType,
@@ -569,81 +790,97 @@ func (s *Repository) Add(
 	userID int64,
 	message string,
 	addr string,
-	attention bool,
+	moderation Moderation,
 ) (int64, error) {
-	if parentID > 0 {
-		deleted := false
+	ma := schema.CommentMessageModeratorAttentionNone
+	if moderation.Attention || moderation.Quarantine {
+		ma = schema.CommentMessageModeratorAttentionRequired
+	}
 
-		success, err := s.db.Select(schema.CommentMessageTableDeletedCol).
-			From(schema.CommentMessageTable).
-			Where(
-				schema.CommentMessageTableTypeIDCol.Eq(typeID),
-				schema.CommentMessageTableItemIDCol.Eq(itemID),
-				schema.CommentMessageTableIDCol.Eq(parentID),
-			).
-			ScanValContext(ctx, &deleted)
-		if err != nil {
-			return 0, err
-		}
+	ctx = context.WithoutCancel(ctx)
 
-		if !success {
-			return 0, errMessageNotFound
-		}
+	var messageID int64
+
+	err := s.db.WithTx(func(tx *goqu.TxDatabase) error {
+		if parentID > 0 {
+			deleted := false
+
+			success, err := tx.Select(schema.CommentMessageTableDeletedCol).
+				From(schema.CommentMessageTable).
+				Where(
+					schema.CommentMessageTableTypeIDCol.Eq(typeID),
+					schema.CommentMessageTableItemIDCol.Eq(itemID),
+					schema.CommentMessageTableIDCol.Eq(parentID),
+				).
+				ScanValContext(ctx, &deleted)
+			if err != nil {
+				return err
+			}
+
+			if !success {
+				return errMessageNotFound
+			}
 
-		if deleted {
-			return 0, errMessageIsDeleted
+			if deleted {
+				return errMessageIsDeleted
+			}
 		}
-	}
 
-	ma := schema.CommentMessageModeratorAttentionNone
-	if attention {
-		ma = schema.CommentMessageModeratorAttentionRequired
-	}
+		res, err := tx.Insert(schema.CommentMessageTable).
+			Cols(schema.CommentMessageTableDatetimeCol, schema.CommentMessageTableTypeIDCol,
+				schema.CommentMessageTableItemIDCol, schema.CommentMessageTableParentIDCol,
+				schema.CommentMessageTableAuthorIDCol, schema.CommentMessageTableMessageCol,
+				schema.CommentMessageTableIPCol, schema.CommentMessageTableModeratorAttentionCol,
+				schema.CommentMessageTableDeletedCol).
+			Vals(goqu.Vals{
+				goqu.Func("NOW"),
+				typeID,
+				itemID,
+				sql.NullInt64{
+					Int64: parentID,
+					Valid: parentID > 0,
+				},
+				userID,
+				message,
+				goqu.Func("INET6_ATON", addr),
+				ma,
+				moderation.Quarantine,
+			}).Executor().ExecContext(ctx)
+		if err != nil {
+			return err
+		}
 
-	ctx = context.WithoutCancel(ctx)
+		messageID, err = res.LastInsertId()
+		if err != nil {
+			return err
+		}
 
-	res, err := s.db.Insert(schema.CommentMessageTable).
-		Cols(schema.CommentMessageTableDatetimeCol, schema.CommentMessageTableTypeIDCol,
-			schema.CommentMessageTableItemIDCol, schema.CommentMessageTableParentIDCol,
-			schema.CommentMessageTableAuthorIDCol, schema.CommentMessageTableMessageCol,
-			schema.CommentMessageTableIPCol, schema.CommentMessageTableModeratorAttentionCol).
-		Vals(goqu.Vals{
-			goqu.Func("NOW"),
-			typeID,
-			itemID,
-			sql.NullInt64{
-				Int64: parentID,
-				Valid: parentID > 0,
-			},
-			userID,
-			message,
-			goqu.Func("INET6_ATON", addr),
-			ma,
-		}).Executor().ExecContext(ctx)
-	if err != nil {
-		return 0, err
-	}
+		if moderation.Store != nil {
+			return moderation.Store(ctx, tx, messageID)
+		}
 
-	messageID, err := res.LastInsertId()
+		return nil
+	})
 	if err != nil {
 		return 0, err
 	}
 
+	// message is stored already, counters are recalculated from scratch on the next message
 	if parentID > 0 {
 		err = s.UpdateMessageRepliesCount(ctx, parentID)
 		if err != nil {
-			return 0, err
+			logrus.Errorf("comments: failed to update replies count of %d: %s", parentID, err.Error())
 		}
 	}
 
-	err = s.updateTopicStat(ctx, typeID, itemID)
+	err = s.updateTopicStat(ctx, s.db, typeID, itemID)
 	if err != nil {
-		return 0, err
+		logrus.Errorf("comments: failed to update stat of topic %d/%d: %s", typeID, itemID, err.Error())
 	}
 
 	err = s.UpdateTopicView(ctx, typeID, itemID, userID)
 	if err != nil {
-		return 0, err
+		logrus.Errorf("comments: failed to update topic view of user %d: %s", userID, err.Error())
 	}
 
 	return messageID, nil
@@ -733,7 +970,6 @@ func (s *Repository) NotifyAboutReply(ctx context.Context, messageID int64) erro
 		AuthorID       int64          `db:"author_id"`
 		ParentAuthorID int64          `db:"parent_author_id"`
 		AuthorIdentity sql.NullString `db:"identity"`
-		ParentLanguage string         `db:"language"`
 	}{}
 
 	cm1 := "cm1"
@@ -749,7 +985,6 @@ func (s *Repository) NotifyAboutReply(ctx context.Context, messageID int64) erro
 		cm1Table.Col(schema.CommentMessageTableAuthorIDColName),
 		pmTable.Col(schema.CommentMessageTableAuthorIDColName).As("parent_author_id"),
 		u1Table.Col(schema.UserTableIdentityColName),
-		puTable.Col(schema.UserTableLanguageColName),
 	).
 		From(schema.CommentMessageTable.As(cm1)).
 		Join(schema.CommentMessageTable.As(pm), goqu.On(
@@ -782,36 +1017,244 @@ func (s *Repository) NotifyAboutReply(ctx context.Context, messageID int64) erro
 		return nil
 	}
 
+	blocked, err := s.userRepository.IsBlocked(ctx, st.ParentAuthorID, st.AuthorID)
+	if err != nil {
+		return err
+	}
+
+	if blocked {
+		return nil
+	}
+
 	var ai *string
 	if st.AuthorIdentity.Valid {
 		ai = &st.AuthorIdentity.String
 	}
 
-	userURL, err := s.userURL(st.AuthorID, ai, st.ParentLanguage)
+	return s.notifier.Notify(
+		ctx, notifications.EventReply, st.ParentAuthorID, "pm/user-%s-replies-to-you-%s",
+		func(lang string) (map[string]interface{}, error) {
+			return s.notificationTemplateData(ctx, messageID, st.AuthorID, ai, lang)
+		},
+	)
+}
+
+// SetMentions stores users mentioned in message, forgets ones no longer mentioned after edit
+// and returns ids of ones that were not mentioned in it before.
+func (s *Repository) SetMentions(ctx context.Context, messageID int64, message string) ([]int64, error) {
+	rows, err := s.userRepository.UsersByIdentities(ctx, markup.ParseReferences(message).Users)
 	if err != nil {
-		return err
+		return nil, err
+	}
+
+	userIDs := make([]int64, 0, len(rows))
+	for _, row := range rows {
+		userIDs = append(userIDs, row.ID)
+	}
+
+	// same user can be mentioned both by identity and as user<id>
+	userIDs = util.RemoveDuplicate(userIDs)
+	result := make([]int64, 0, len(userIDs))
+
+	ctx = context.WithoutCancel(ctx)
+
+	err = s.db.WithTx(func(tx *goqu.TxDatabase) error {
+		sqDelete := tx.Delete(schema.CommentMentionTable).
+			Where(schema.CommentMentionTableCommentIDCol.Eq(messageID))
+		if len(userIDs) > 0 {
+			sqDelete = sqDelete.Where(schema.CommentMentionTableUserIDCol.NotIn(userIDs))
+		}
+
+		_, err := sqDelete.Executor().ExecContext(ctx)
+		if err != nil {
+			return err
+		}
+
+		for _, userID := range userIDs {
+			res, err := tx.Insert(schema.CommentMentionTable).Rows(goqu.Record{
+				schema.CommentMentionTableCommentIDColName: messageID,
+				schema.CommentMentionTableUserIDColName:    userID,
+			}).OnConflict(goqu.DoNothing()).Executor().ExecContext(ctx)
+			if err != nil {
+				return err
+			}
+
+			affected, err := res.RowsAffected()
+			if err != nil {
+				return err
+			}
+
+			if affected > 0 {
+				result = append(result, userID)
+			}
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// NotifyMentions notifies mentioned users, except author and author of parent message
+// who is already notified about reply.
+func (s *Repository) NotifyMentions(ctx context.Context, messageID int64, userIDs []int64) error {
+	if len(userIDs) == 0 {
+		return nil
 	}
 
-	uri, err := s.hostManager.URIByLanguage(st.ParentLanguage)
+	st := struct {
+		AuthorID       int64          `db:"author_id"`
+		ParentAuthorID sql.NullInt64  `db:"parent_author_id"`
+		AuthorIdentity sql.NullString `db:"identity"`
+	}{}
+
+	cm := "cm"
+	cmTable := goqu.T(cm)
+	pm := "pm"
+	pmTable := goqu.T(pm)
+
+	success, err := s.db.Select(
+		cmTable.Col(schema.CommentMessageTableAuthorIDColName),
+		pmTable.Col(schema.CommentMessageTableAuthorIDColName).As("parent_author_id"),
+		schema.UserTableIdentityCol,
+	).
+		From(schema.CommentMessageTable.As(cm)).
+		Join(schema.UserTable, goqu.On(
+			cmTable.Col(schema.CommentMessageTableAuthorIDColName).Eq(schema.UserTableIDCol),
+		)).
+		LeftJoin(schema.CommentMessageTable.As(pm), goqu.On(
+			cmTable.Col(schema.CommentMessageTableParentIDColName).Eq(pmTable.Col(schema.CommentMessageTableIDColName)),
+		)).
+		Where(cmTable.Col(schema.CommentMessageTableIDColName).Eq(messageID)).
+		ScanStructContext(ctx, &st)
 	if err != nil {
 		return err
 	}
 
-	messageURL, err := s.messageURL(ctx, messageID, uri)
+	if !success {
+		return nil
+	}
+
+	var ai *string
+	if st.AuthorIdentity.Valid {
+		ai = &st.AuthorIdentity.String
+	}
+
+	// author of parent is notified about reply already, users blocked author are not notified
+	sqSelect := s.db.Select(schema.UserTableIDCol).
+		From(schema.UserTable).
+		Where(
+			schema.UserTableIDCol.In(userIDs),
+			schema.UserTableIDCol.Neq(st.AuthorID),
+			goqu.L("NOT EXISTS ?", s.db.Select(goqu.V(true)).
+				From(schema.UserBlockTable).
+				Where(
+					schema.UserBlockTableUserIDCol.Eq(schema.UserTableIDCol),
+					schema.UserBlockTableBlockedUserIDCol.Eq(st.AuthorID),
+				),
+			),
+		)
+
+	if st.ParentAuthorID.Valid {
+		sqSelect = sqSelect.Where(schema.UserTableIDCol.Neq(st.ParentAuthorID.Int64))
+	}
+
+	recipientIDs := make([]int64, 0, len(userIDs))
+
+	err = sqSelect.ScanValsContext(ctx, &recipientIDs)
 	if err != nil {
 		return err
 	}
 
-	return s.messageRepository.CreateMessageFromTemplate(
-		ctx, 0, st.ParentAuthorID, "pm/user-%s-replies-to-you-%s", map[string]interface{}{
-			"Name":    userURL,
-			"Message": messageURL,
-		},
-		st.ParentLanguage,
-	)
+	for _, userID := range recipientIDs {
+		err = s.notifier.Notify(
+			ctx, notifications.EventMention, userID, "pm/user-%s-mentioned-you-%s",
+			func(lang string) (map[string]interface{}, error) {
+				return s.notificationTemplateData(ctx, messageID, st.AuthorID, ai, lang)
+			},
+		)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *Repository) notificationTemplateData(
+	ctx context.Context, messageID int64, authorID int64, authorIdentity *string, lang string,
+) (map[string]interface{}, error) {
+	userURL, err := s.userURL(authorID, authorIdentity, lang)
+	if err != nil {
+		return nil, err
+	}
+
+	uri, err := s.hostManager.URIByLanguage(lang)
+	if err != nil {
+		return nil, err
+	}
+
+	messageURL, err := s.MessageURL(ctx, messageID, uri)
+	if err != nil {
+		return nil, err
+	}
+
+	return map[string]interface{}{
+		"Name":    userURL,
+		"Message": messageURL,
+	}, nil
 }
 
-func (s *Repository) NotifySubscribers(ctx context.Context, messageID int64) error {
+// UnreadReplies returns ids of replies to messages of user posted since time, in topics user didn't view after.
+// Replies of users blocked by user are skipped.
+func (s *Repository) UnreadReplies(ctx context.Context, userID int64, since time.Time) ([]int64, error) {
+	cm := "cm"
+	cmTable := goqu.T(cm)
+	pm := "pm"
+	pmTable := goqu.T(pm)
+
+	var ids []int64
+
+	err := s.db.Select(cmTable.Col(schema.CommentMessageTableIDColName)).
+		From(schema.CommentMessageTable.As(cm)).
+		Join(schema.CommentMessageTable.As(pm), goqu.On(
+			cmTable.Col(schema.CommentMessageTableParentIDColName).Eq(pmTable.Col(schema.CommentMessageTableIDColName)),
+		)).
+		LeftJoin(schema.CommentTopicViewTable, goqu.On(
+			schema.CommentTopicViewTableUserIDCol.Eq(userID),
+			schema.CommentTopicViewTableTypeIDCol.Eq(cmTable.Col(schema.CommentMessageTableTypeIDColName)),
+			schema.CommentTopicViewTableItemIDCol.Eq(cmTable.Col(schema.CommentMessageTableItemIDColName)),
+		)).
+		Where(
+			pmTable.Col(schema.CommentMessageTableAuthorIDColName).Eq(userID),
+			cmTable.Col(schema.CommentMessageTableAuthorIDColName).Neq(userID),
+			cmTable.Col(schema.CommentMessageTableDeletedColName).IsFalse(),
+			cmTable.Col(schema.CommentMessageTableDatetimeColName).Gt(since),
+			goqu.Or(
+				schema.CommentTopicViewTableTimestampCol.IsNull(),
+				schema.CommentTopicViewTableTimestampCol.Lt(cmTable.Col(schema.CommentMessageTableDatetimeColName)),
+			),
+			goqu.L("NOT EXISTS ?",
+				s.db.Select(goqu.V(true)).
+					From(schema.UserBlockTable).
+					Where(
+						schema.UserBlockTableUserIDCol.Eq(userID),
+						schema.UserBlockTableBlockedUserIDCol.Eq(cmTable.Col(schema.CommentMessageTableAuthorIDColName)),
+					),
+			),
+		).
+		Order(cmTable.Col(schema.CommentMessageTableDatetimeColName).Desc()).
+		ScanValsContext(ctx, &ids)
+
+	return ids, err
+}
+
+// notifySubscribers notifies subscribers of topic about new message unless they are notified already
+// since their last visit, opted out of comments of author or blocked them.
+func (s *Repository) notifySubscribers(ctx context.Context, messageID int64) error {
 	var authorIdentity sql.NullString
 
 	st := struct {
@@ -859,15 +1302,25 @@ func (s *Repository) NotifySubscribers(ctx context.Context, messageID int64) err
 		return err
 	}
 
-	filteredIDs := make([]int64, 0)
+	disabledIDs, err := s.userRepository.CommentsNotificationsDisabled(ctx, ids, st.AuthorID.Int64)
+	if err != nil {
+		return err
+	}
 
-	for _, id := range ids {
-		prefs, err := s.userRepository.UserPreferences(ctx, id, st.AuthorID.Int64)
-		if err != nil {
-			return err
-		}
+	blockedByIDs, err := s.userRepository.BlockedBy(ctx, ids, st.AuthorID.Int64)
+	if err != nil {
+		return err
+	}
+
+	skip := make(map[int64]bool, len(disabledIDs)+len(blockedByIDs))
+	for _, id := range append(disabledIDs, blockedByIDs...) {
+		skip[id] = true
+	}
 
-		if !prefs.DisableCommentsNotifications {
+	filteredIDs := make([]int64, 0, len(ids))
+
+	for _, id := range ids {
+		if !skip[id] {
 			filteredIDs = append(filteredIDs, id)
 		}
 	}
@@ -878,51 +1331,25 @@ func (s *Repository) NotifySubscribers(ctx context.Context, messageID int64) err
 
 	ctx = context.WithoutCancel(ctx)
 
-	subscribers, err := s.db.Select(schema.UserTableIDCol, schema.UserTableLanguageCol).
+	subscriberIDs := make([]int64, 0, len(filteredIDs))
+
+	err = s.db.Select(schema.UserTableIDCol).
 		From(schema.UserTable).
 		Where(
 			schema.UserTableIDCol.In(filteredIDs),
 			schema.UserTableIDCol.Neq(st.AuthorID.Int64),
 		).
-		Executor().QueryContext(ctx) //nolint:sqlclosecheck
+		ScanValsContext(ctx, &subscriberIDs)
 	if err != nil {
 		return err
 	}
 
-	defer util.Close(subscribers)
-
-	var (
-		subscriberID       int64
-		subscriberLanguage string
-	)
-
-	for subscribers.Next() {
-		err = subscribers.Scan(&subscriberID, &subscriberLanguage)
-		if err != nil {
-			return err
-		}
-
-		userURL, err := s.userURL(st.AuthorID.Int64, au, subscriberLanguage)
-		if err != nil {
-			return err
-		}
-
-		uri, err := s.hostManager.URIByLanguage(subscriberLanguage)
-		if err != nil {
-			return err
-		}
-
-		messageURL, err := s.messageURL(ctx, messageID, uri)
-		if err != nil {
-			return err
-		}
-
-		err = s.messageRepository.CreateMessageFromTemplate(
-			ctx, 0, subscriberID, "pm/user-%s-post-new-message-%s", map[string]interface{}{
-				"Name":    userURL,
-				"Message": messageURL,
+	for _, subscriberID := range subscriberIDs {
+		err = s.notifier.Notify(
+			ctx, notifications.EventSubscription, subscriberID, "pm/user-%s-post-new-message-%s",
+			func(lang string) (map[string]interface{}, error) {
+				return s.notificationTemplateData(ctx, messageID, st.AuthorID.Int64, au, lang)
 			},
-			subscriberLanguage,
 		)
 		if err != nil {
 			return err
@@ -934,7 +1361,7 @@ func (s *Repository) NotifySubscribers(ctx context.Context, messageID int64) err
 		}
 	}
 
-	return subscribers.Err()
+	return nil
 }
 
 func (s *Repository) getSubscribersIDs(
@@ -980,7 +1407,7 @@ func (s *Repository) SetSubscriptionSent(
 	return err
 }
 
-func (s *Repository) messageURL(
+func (s *Repository) MessageURL(
 	ctx context.Context,
 	messageID int64,
 	uri *url.URL,
@@ -1163,7 +1590,7 @@ func (s *Repository) CleanupDeleted(ctx context.Context) (int64, error) {
 
 		affected += a
 
-		err = s.updateTopicStat(ctx, typeID, itemID)
+		err = s.updateTopicStat(ctx, s.db, typeID, itemID)
 		if err != nil {
 			return 0, err
 		}
@@ -1383,7 +1810,7 @@ func (s *Repository) deleteMessage(ctx context.Context, id int64) (int64, error)
 		return 0, err
 	}
 
-	err = s.updateTopicStat(ctx, typeID, id)
+	err = s.updateTopicStat(ctx, s.db, typeID, id)
 	if err != nil {
 		return 0, err
 	}
@@ -1653,6 +2080,7 @@ func (s *Repository) columns(fetchMessage bool, fetchVote bool, fetchIP bool) []
 		schema.CommentMessageTableItemIDCol, schema.CommentMessageTableParentIDCol,
 		schema.CommentMessageTableDatetimeCol, schema.CommentMessageTableDeletedCol,
 		schema.CommentMessageTableModeratorAttentionCol, schema.CommentMessageTableAuthorIDCol,
+		schema.CommentMessageTableEditedAtCol, schema.CommentMessageTableRepliesCountCol,
 	}
 
 	if fetchIP {
@@ -1692,6 +2120,128 @@ func (s *Repository) Message(
 	return &row, nil
 }
 
+// MessagesByIDs returns messages indexed by their ids, missing messages are skipped.
+func (s *Repository) MessagesByIDs(
+	ctx context.Context, messageIDs []int64, fetchMessage bool, fetchVote bool, canViewIP bool,
+) (map[int64]*schema.CommentMessageRow, error) {
+	result := make(map[int64]*schema.CommentMessageRow, len(messageIDs))
+	if len(messageIDs) == 0 {
+		return result, nil
+	}
+
+	rows := make([]*schema.CommentMessageRow, 0, len(messageIDs))
+
+	err := s.db.Select(s.columns(fetchMessage, fetchVote, canViewIP)...).
+		From(schema.CommentMessageTable).
+		Where(schema.CommentMessageTableIDCol.In(messageIDs)).
+		ScanStructsContext(ctx, &rows)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		result[row.ID] = row
+	}
+
+	return result, nil
+}
+
+// EditMessage replaces text of message keeping previous version in history.
+// Datetime of message is left untouched, so order of messages and topic stat are not affected.
+func (s *Repository) EditMessage(ctx context.Context, messageID int64, userID int64, message string) error {
+	ctx = context.WithoutCancel(ctx)
+
+	return s.db.WithTx(func(tx *goqu.TxDatabase) error {
+		var current string
+
+		success, err := tx.Select(schema.CommentMessageTableMessageCol).
+			From(schema.CommentMessageTable).
+			Where(schema.CommentMessageTableIDCol.Eq(messageID)).
+			ForUpdate(exp.Wait).
+			ScanValContext(ctx, &current)
+		if err != nil {
+			return err
+		}
+
+		if !success {
+			return errMessageNotFound
+		}
+
+		if current == message {
+			return nil
+		}
+
+		_, err = tx.Insert(schema.CommentMessageHistoryTable).Rows(goqu.Record{
+			schema.CommentMessageHistoryTableCommentIDColName: messageID,
+			schema.CommentMessageHistoryTableUserIDColName:    sql.NullInt64{Int64: userID, Valid: userID > 0},
+			schema.CommentMessageHistoryTableCreatedAtColName: goqu.Func("NOW"),
+			schema.CommentMessageHistoryTableMessageColName:   current,
+		}).Executor().ExecContext(ctx)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Update(schema.CommentMessageTable).
+			Set(goqu.Record{
+				schema.CommentMessageTableMessageColName:  message,
+				schema.CommentMessageTableEditedAtColName: goqu.Func("NOW"),
+				// guards against implicit ON UPDATE of the first timestamp column
+				schema.CommentMessageTableDatetimeColName: schema.CommentMessageTableDatetimeCol,
+			}).
+			Where(schema.CommentMessageTableIDCol.Eq(messageID)).
+			Executor().ExecContext(ctx)
+
+		return err
+	})
+}
+
+// MessageHistory returns previous versions of message, latest first.
+// MessagesHistory returns previous revisions of messages, latest first, at most MessageHistoryLimit per message.
+func (s *Repository) MessagesHistory(
+	ctx context.Context, messageIDs []int64,
+) (map[int64][]*schema.CommentMessageHistoryRow, error) {
+	result := make(map[int64][]*schema.CommentMessageHistoryRow, len(messageIDs))
+
+	if len(messageIDs) == 0 {
+		return result, nil
+	}
+
+	const rowNumberAlias = "num"
+
+	subSelect := s.db.Select(
+		schema.CommentMessageHistoryTableIDCol, schema.CommentMessageHistoryTableCommentIDCol,
+		schema.CommentMessageHistoryTableUserIDCol, schema.CommentMessageHistoryTableCreatedAtCol,
+		schema.CommentMessageHistoryTableMessageCol,
+		goqu.L(
+			"ROW_NUMBER() OVER(PARTITION BY ? ORDER BY ? DESC)",
+			schema.CommentMessageHistoryTableCommentIDCol, schema.CommentMessageHistoryTableIDCol,
+		).As(rowNumberAlias),
+	).
+		From(schema.CommentMessageHistoryTable).
+		Where(schema.CommentMessageHistoryTableCommentIDCol.In(messageIDs))
+
+	rows := make([]*schema.CommentMessageHistoryRow, 0)
+
+	err := s.db.Select(
+		schema.CommentMessageHistoryTableIDColName, schema.CommentMessageHistoryTableCommentIDColName,
+		schema.CommentMessageHistoryTableUserIDColName, schema.CommentMessageHistoryTableCreatedAtColName,
+		schema.CommentMessageHistoryTableMessageColName,
+	).
+		From(subSelect.As("t")).
+		Where(goqu.C(rowNumberAlias).Lte(MessageHistoryLimit)).
+		Order(goqu.C(schema.CommentMessageHistoryTableCommentIDColName).Asc(), goqu.C(rowNumberAlias).Asc()).
+		ScanStructsContext(ctx, &rows)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		result[row.CommentID] = append(result[row.CommentID], row)
+	}
+
+	return result, nil
+}
+
 func (s *Repository) IsNewMessage(
 	ctx context.Context,
 	typeID schema.CommentMessageType,
@@ -1811,7 +2361,204 @@ func (s *Repository) MessageRowRoute(
 	return result, nil
 }
 
+func messageID(row *schema.CommentMessageRow) any { return row.ID }
+
+func messageCreatedAt(row *schema.CommentMessageRow) any { return row.CreatedAt }
+
+func messageVote(row *schema.CommentMessageRow) any { return row.Vote }
+
+// Orders of messages supporting cursor pagination.
+var (
+	MessagesKeysetDateAsc = util.Keyset[*schema.CommentMessageRow]{
+		{Name: schema.CommentMessageTableDatetimeColName, Value: messageCreatedAt},
+		{Name: schema.CommentMessageTableIDColName, Value: messageID},
+	}
+	MessagesKeysetDateDesc = util.Keyset[*schema.CommentMessageRow]{
+		{Name: schema.CommentMessageTableDatetimeColName, Desc: true, Value: messageCreatedAt},
+		{Name: schema.CommentMessageTableIDColName, Desc: true, Value: messageID},
+	}
+	MessagesKeysetVoteDesc = util.Keyset[*schema.CommentMessageRow]{
+		{Name: schema.CommentMessageTableVoteColName, Desc: true, Value: messageVote},
+		{Name: schema.CommentMessageTableDatetimeColName, Desc: true, Value: messageCreatedAt},
+		{Name: schema.CommentMessageTableIDColName, Desc: true, Value: messageID},
+	}
+	MessagesKeysetVoteAsc = util.Keyset[*schema.CommentMessageRow]{
+		{Name: schema.CommentMessageTableVoteColName, Value: messageVote},
+		{Name: schema.CommentMessageTableDatetimeColName, Desc: true, Value: messageCreatedAt},
+		{Name: schema.CommentMessageTableIDColName, Desc: true, Value: messageID},
+	}
+)
+
 func (s *Repository) Paginator(request Request) *util.Paginator {
+	return &util.Paginator{
+		SQLSelect:         s.messagesSelect(request).Order(request.Order...),
+		ItemCountPerPage:  request.PerPage,
+		CurrentPageNumber: request.Page,
+	}
+}
+
+// MessagesByCursor returns page of messages following cursor in keyset order and cursor of next page,
+// empty for the last page. Unlike Paginator it doesn't count rows and doesn't use OFFSET.
+func (s *Repository) MessagesByCursor(
+	ctx context.Context, request Request, keyset util.Keyset[*schema.CommentMessageRow], cursor string,
+) ([]*schema.CommentMessageRow, string, error) {
+	after, err := keyset.Decode(cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	// vote is a part of cursor
+	request.FetchVote = true
+
+	sqSelect := after.Where(s.messagesSelect(request), schema.CommentMessageTableName).
+		Order(keyset.Order(schema.CommentMessageTableName)...)
+
+	if request.PerPage > 0 {
+		sqSelect = sqSelect.Limit(uint(request.PerPage) + 1)
+	}
+
+	rows := make([]*schema.CommentMessageRow, 0)
+
+	err = sqSelect.ScanStructsContext(ctx, &rows)
+	if err != nil {
+		return nil, "", err
+	}
+
+	rows, next := keyset.Page(rows, uint32(max(request.PerPage, 0))) //nolint: gosec
+
+	return rows, next, nil
+}
+
+// RepliesByParents returns the first page of replies of every parent in keyset order and cursors of
+// their next pages, empty for the last ones. Replies of all parents are loaded with one windowed query.
+func (s *Repository) RepliesByParents(
+	ctx context.Context, request Request, keyset util.Keyset[*schema.CommentMessageRow], parentIDs []int64,
+	limit int32,
+) (map[int64][]*schema.CommentMessageRow, map[int64]string, error) {
+	replies := make(map[int64][]*schema.CommentMessageRow, len(parentIDs))
+	cursors := make(map[int64]string, len(parentIDs))
+
+	if len(parentIDs) == 0 || limit <= 0 {
+		return replies, cursors, nil
+	}
+
+	const rowNumberAlias = "num"
+
+	request.ParentID = 0
+	request.NoParents = false
+	// vote is a part of cursor
+	request.FetchVote = true
+
+	order := keyset.Order(schema.CommentMessageTableName)
+	orderArgs := make([]interface{}, 0, len(order)+1)
+	orderArgs = append(orderArgs, schema.CommentMessageTableParentIDCol)
+
+	for _, expr := range order {
+		orderArgs = append(orderArgs, expr)
+	}
+
+	subSelect := s.messagesSelect(request).
+		SelectAppend(goqu.L(
+			"ROW_NUMBER() OVER(PARTITION BY ? ORDER BY "+strings.Repeat("?, ", len(order)-1)+"?)", orderArgs...,
+		).As(rowNumberAlias)).
+		Where(schema.CommentMessageTableParentIDCol.In(parentIDs))
+
+	var rows []struct {
+		schema.CommentMessageRow
+		Num int64 `db:"num"`
+	}
+
+	// one extra row per parent detects next page
+	err := s.db.Select(goqu.T("t").All()).
+		From(subSelect.As("t")).
+		Where(goqu.C(rowNumberAlias).Lte(limit+1)).
+		Order(goqu.C(schema.CommentMessageTableParentIDColName).Asc(), goqu.C(rowNumberAlias).Asc()).
+		ScanStructsContext(ctx, &rows)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	for _, row := range rows {
+		replies[row.ParentID.Int64] = append(replies[row.ParentID.Int64], &row.CommentMessageRow)
+	}
+
+	for parentID, parentReplies := range replies {
+		replies[parentID], cursors[parentID] = keyset.Page(parentReplies, uint32(limit)) //nolint: gosec
+	}
+
+	return replies, cursors, nil
+}
+
+// CursorAt returns cursor of page starting with message in keyset order, empty when nothing precedes message.
+func (s *Repository) CursorAt(
+	ctx context.Context, request Request, keyset util.Keyset[*schema.CommentMessageRow], messageID int64,
+) (string, error) {
+	row, err := s.Message(ctx, messageID, false, true, false)
+	if err != nil {
+		return "", err
+	}
+
+	if row == nil {
+		return "", errMessageNotFound
+	}
+
+	reversed := keyset.Reverse()
+
+	before, err := reversed.Decode(reversed.Encode(row))
+	if err != nil {
+		return "", err
+	}
+
+	request.FetchVote = true
+
+	rows := make([]*schema.CommentMessageRow, 0)
+
+	err = before.Where(s.messagesSelect(request), schema.CommentMessageTableName).
+		Order(reversed.Order(schema.CommentMessageTableName)...).
+		Limit(1).
+		ScanStructsContext(ctx, &rows)
+	if err != nil {
+		return "", err
+	}
+
+	if len(rows) == 0 {
+		return "", nil
+	}
+
+	return keyset.Encode(rows[0]), nil
+}
+
+// MessagePath returns ids of message ancestors starting from top level one, message itself is the last.
+func (s *Repository) MessagePath(ctx context.Context, messageID int64) ([]int64, error) {
+	path := make([]int64, 0)
+	parentID := sql.NullInt64{Int64: messageID, Valid: true}
+
+	for parentID.Valid {
+		if len(path) >= maxThreadDepth || slices.Contains(path, parentID.Int64) {
+			return nil, errBrokenThread
+		}
+
+		path = append(path, parentID.Int64)
+
+		success, err := s.db.Select(schema.CommentMessageTableParentIDCol).
+			From(schema.CommentMessageTable).
+			Where(schema.CommentMessageTableIDCol.Eq(parentID.Int64)).
+			ScanValContext(ctx, &parentID)
+		if err != nil {
+			return nil, err
+		}
+
+		if !success {
+			return nil, errMessageNotFound
+		}
+	}
+
+	slices.Reverse(path)
+
+	return path, nil
+}
+
+func (s *Repository) messagesSelect(request Request) *goqu.SelectDataset {
 	columns := s.columns(request.FetchMessage, request.FetchVote, request.FetchIP)
 
 	sqSelect := s.db.Select(columns...).
@@ -1855,19 +2602,78 @@ func (s *Repository) Paginator(request Request) *util.Paginator {
 		sqSelect = sqSelect.Where(schema.CommentMessageTableAuthorIDCol.Eq(request.UserID))
 	}
 
+	if request.MentionedUserID > 0 {
+		sqSelect = sqSelect.
+			Join(
+				schema.CommentMentionTable,
+				goqu.On(schema.CommentMessageTableIDCol.Eq(schema.CommentMentionTableCommentIDCol)),
+			).
+			Where(schema.CommentMentionTableUserIDCol.Eq(request.MentionedUserID))
+	}
+
 	if request.ModeratorAttention > 0 {
 		sqSelect = sqSelect.Where(
 			schema.CommentMessageTableModeratorAttentionCol.Eq(request.ModeratorAttention),
 		)
 	}
 
-	sqSelect = sqSelect.Order(request.Order...)
+	if request.Quarantined {
+		sqSelect = sqSelect.Where(
+			schema.CommentMessageTableDeletedCol.IsTrue(),
+			schema.CommentMessageTableDeletedByCol.IsNull(),
+		)
+	}
 
-	return &util.Paginator{
-		SQLSelect:         sqSelect,
-		ItemCountPerPage:  request.PerPage,
-		CurrentPageNumber: request.Page,
+	if request.HideBlockedBy > 0 {
+		sqSelect = sqSelect.Where(goqu.L("NOT EXISTS ?",
+			s.db.Select(goqu.V(true)).
+				From(schema.UserBlockTable).
+				Where(
+					schema.UserBlockTableUserIDCol.Eq(request.HideBlockedBy),
+					schema.UserBlockTableBlockedUserIDCol.Eq(schema.CommentMessageTableAuthorIDCol),
+				),
+		))
 	}
+
+	return sqSelect
+}
+
+// AuthorsReputation returns reputation of authors indexed by their ids.
+// Only deletions made by moderators are counted as deleted.
+func (s *Repository) AuthorsReputation(ctx context.Context, authorIDs []int64) (map[int64]AuthorReputation, error) {
+	result := make(map[int64]AuthorReputation, len(authorIDs))
+	if len(authorIDs) == 0 {
+		return result, nil
+	}
+
+	rows := make([]AuthorReputation, 0, len(authorIDs))
+
+	err := s.db.Select(
+		schema.CommentMessageTableAuthorIDCol,
+		goqu.MAX(schema.UserTableRegDateCol).As("reg_date"),
+		goqu.COUNT(goqu.Star()).As("messages_count"),
+		goqu.COALESCE(goqu.SUM(goqu.L(
+			"? AND ? IS NOT NULL", schema.CommentMessageTableDeletedCol, schema.CommentMessageTableDeletedByCol,
+		)), 0).As("deleted_count"),
+		goqu.COALESCE(goqu.SUM(
+			schema.CommentMessageTableModeratorAttentionCol.Neq(schema.CommentMessageModeratorAttentionNone),
+		), 0).As("attentions_count"),
+		goqu.COALESCE(goqu.SUM(schema.CommentMessageTableVoteCol), 0).As("votes"),
+	).
+		From(schema.CommentMessageTable).
+		LeftJoin(schema.UserTable, goqu.On(schema.CommentMessageTableAuthorIDCol.Eq(schema.UserTableIDCol))).
+		Where(schema.CommentMessageTableAuthorIDCol.In(authorIDs)).
+		GroupBy(schema.CommentMessageTableAuthorIDCol).
+		ScanStructsContext(ctx, &rows)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		result[row.AuthorID] = row
+	}
+
+	return result, nil
 }
 
 func (s *Repository) TopAuthors(ctx context.Context, limit uint) ([]RatingUser, error) {
@@ -1924,12 +2730,12 @@ func (s *Repository) MoveMessages(
 		return err
 	}
 
-	err = s.updateTopicStat(ctx, srcTypeID, srcItemID)
+	err = s.updateTopicStat(ctx, s.db, srcTypeID, srcItemID)
 	if err != nil {
 		return err
 	}
 
-	return s.updateTopicStat(ctx, dstTypeID, dstItemID)
+	return s.updateTopicStat(ctx, s.db, dstTypeID, dstItemID)
 }
 
 func (s *Repository) DeleteTopic(
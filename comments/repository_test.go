@@ -11,9 +11,8 @@ import (
 	"github.com/Nerzal/gocloak/v13"
 	"github.com/autowp/goautowp/config"
 	"github.com/autowp/goautowp/hosts"
-	"github.com/autowp/goautowp/i18nbundle"
 	"github.com/autowp/goautowp/image/storage"
-	"github.com/autowp/goautowp/messaging"
+	"github.com/autowp/goautowp/notifications"
 	"github.com/autowp/goautowp/schema"
 	"github.com/autowp/goautowp/users"
 	"github.com/doug-martin/goqu/v9"
@@ -90,18 +89,9 @@ func createRepository(t *testing.T) (*Repository, *goqu.Database) {
 
 	hostsManager := hosts.NewManager(cfg.Languages)
 
-	i, err := i18nbundle.New()
-	require.NoError(t, err)
-
-	messagingRepository := messaging.NewRepository(
-		goquDB,
-		func(_ context.Context, _ int64, _ int64, _ string) error {
-			return nil
-		},
-		i,
-	)
+	notifier := notifications.NewTestNotifier(t, goquDB, usersRepository, cfg.Notifications)
 
-	repo := NewRepository(goquDB, usersRepository, messagingRepository, hostsManager)
+	repo := NewRepository(goquDB, usersRepository, notifier, hostsManager)
 
 	return repo, goquDB
 }
@@ -140,7 +130,7 @@ func TestAdd(t *testing.T) {
 		itemID      int64 = 1
 	)
 
-	_, err := repo.Add(ctx, commentType, itemID, 0, userID, "Test message", "127.0.0.1", false)
+	_, err := repo.Add(ctx, commentType, itemID, 0, userID, "Test message", "127.0.0.1", Moderation{})
 	require.NoError(t, err)
 }
 
@@ -161,3 +151,42 @@ func TestCleanTopics(t *testing.T) {
 	_, err := repo.CleanTopics(t.Context())
 	require.NoError(t, err)
 }
+
+func TestNotifySubscribers(t *testing.T) {
+	t.Parallel()
+
+	repo, db := createRepository(t)
+	ctx := t.Context()
+	authorID := createRandomUser(ctx, t, db)
+	subscriberID := createRandomUser(ctx, t, db)
+	blockingID := createRandomUser(ctx, t, db)
+
+	var (
+		commentType = schema.CommentMessageTypeIDPictures
+		itemID      = rand.New(rand.NewSource(time.Now().UnixNano())).Int63n(1000000) + 1000000 //nolint:gosec
+	)
+
+	require.NoError(t, repo.Subscribe(ctx, subscriberID, commentType, itemID))
+	require.NoError(t, repo.Subscribe(ctx, blockingID, commentType, itemID))
+	require.NoError(t, repo.userRepository.BlockUser(ctx, blockingID, authorID))
+
+	messageID, err := repo.Add(ctx, commentType, itemID, 0, authorID, "Test message", "127.0.0.1", Moderation{})
+	require.NoError(t, err)
+
+	err = repo.NotifySubscribers(ctx, messageID)
+	require.NoError(t, err)
+
+	// queue is drained by batches, other tests may add to it meanwhile
+	for {
+		processed, err := repo.ProcessNotifications(ctx, notificationsBatchSize)
+		require.NoError(t, err)
+
+		if processed < notificationsBatchSize {
+			break
+		}
+	}
+
+	awaiting, err := repo.getSubscribersIDs(ctx, commentType, itemID, true)
+	require.NoError(t, err)
+	require.Equal(t, []int64{blockingID}, awaiting)
+}
@@ -89,6 +89,10 @@ type FeedbackConfig struct {
 	Subject string   `mapstructure:"subject" yaml:"subject"`
 }
 
+type NotificationsConfig struct {
+	EmailFrom string `mapstructure:"email-from" yaml:"email-from"`
+}
+
 type TelegramConfig struct {
 	AccessToken  string `mapstructure:"access-token"  yaml:"access-token"`
 	WebHook      string `mapstructure:"webhook"       yaml:"webhook"`
@@ -120,6 +124,32 @@ type AttrsAttrs struct {
 	AttrsUpdateValuesQueue string `mapstructure:"update_values_queue" yaml:"update_values_queue"`
 }
 
+type SearchConfig struct {
+	IndexPath             string `mapstructure:"index-path"              yaml:"index-path"`
+	AutocompleteIndexPath string `mapstructure:"autocomplete-index-path" yaml:"autocomplete-index-path"`
+	RefreshExchange       string `mapstructure:"refresh-exchange"        yaml:"refresh-exchange"`
+}
+
+type CatalogueExportConfig struct {
+	License     string `mapstructure:"license"     yaml:"license"`
+	LicenseURL  string `mapstructure:"license-url" yaml:"license-url"`
+	Attribution string `mapstructure:"attribution" yaml:"attribution"`
+}
+
+type GraphQLConfig struct {
+	MaxDepth      int `mapstructure:"max-depth"      yaml:"max-depth"`
+	MaxComplexity int `mapstructure:"max-complexity" yaml:"max-complexity"`
+}
+
+type AntispamConfig struct {
+	FlagScore         float64  `mapstructure:"flag-score"          yaml:"flag-score"`
+	QuarantineScore   float64  `mapstructure:"quarantine-score"    yaml:"quarantine-score"`
+	MaxLinks          int      `mapstructure:"max-links"           yaml:"max-links"`
+	BannedPhrases     []string `mapstructure:"banned-phrases"      yaml:"banned-phrases"`
+	NewAccountDays    int      `mapstructure:"new-account-days"    yaml:"new-account-days"`
+	RepeatWindowHours int      `mapstructure:"repeat-window-hours" yaml:"repeat-window-hours"`
+}
+
 // Config Application config definition.
 type Config struct {
 	GRPC               GRPCConfig                `mapstructure:"grpc"                 yaml:"grpc"`
@@ -153,6 +183,11 @@ type Config struct {
 	MostsMinCarsCount  int                       `mapstructure:"mosts-min-cars-count" yaml:"mosts-min-cars-count"`
 	YoomoneyConfig     YoomoneyConfig            `mapstructure:"yoomoney"             yaml:"yoomoney"`
 	TrustedNetwork     string                    `mapstructure:"trusted-network"      yaml:"trusted-network"`
+	Search             SearchConfig              `mapstructure:"search"               yaml:"search"`
+	CatalogueExport    CatalogueExportConfig     `mapstructure:"catalogue-export"     yaml:"catalogue-export"`
+	GraphQL            GraphQLConfig             `mapstructure:"graphql"              yaml:"graphql"`
+	Notifications      NotificationsConfig       `mapstructure:"notifications"        yaml:"notifications"`
+	Antispam           AntispamConfig            `mapstructure:"antispam"             yaml:"antispam"`
 }
 
 var configMutex = sync.RWMutex{}
@@ -10,10 +10,12 @@ import (
 	"time"
 
 	"github.com/Nerzal/gocloak/v13"
+	"github.com/autowp/goautowp/antispam"
 	"github.com/autowp/goautowp/attrs"
 	"github.com/autowp/goautowp/ban"
 	"github.com/autowp/goautowp/comments"
 	"github.com/autowp/goautowp/config"
+	"github.com/autowp/goautowp/digest"
 	"github.com/autowp/goautowp/email"
 	"github.com/autowp/goautowp/hosts"
 	"github.com/autowp/goautowp/i18nbundle"
@@ -24,7 +26,9 @@ import (
 	"github.com/autowp/goautowp/log"
 	"github.com/autowp/goautowp/messaging"
 	"github.com/autowp/goautowp/mosts"
+	"github.com/autowp/goautowp/notifications"
 	"github.com/autowp/goautowp/pictures"
+	"github.com/autowp/goautowp/search"
 	"github.com/autowp/goautowp/telegram"
 	"github.com/autowp/goautowp/textstorage"
 	"github.com/autowp/goautowp/traffic"
@@ -80,6 +84,9 @@ type Container struct {
 	keyCloak               *gocloak.GoCloak
 	messagingGrpcServer    *MessagingGRPCServer
 	messagingRepository    *messaging.Repository
+	notifier               *notifications.Notifier
+	digestService          *digest.Service
+	antispamService        *antispam.Service
 	publicHTTPServer       *http.Server
 	publicRouter           http.HandlerFunc
 	grpcServerWithServices *grpc.Server
@@ -102,6 +109,10 @@ type Container struct {
 	yoomoneyHandler        *YoomoneyHandler
 	logRepository          *log.Repository
 	LogGrpcServer          *LogGRPCServer
+	searchIndex            *search.Index
+	searchAutocomplete     *search.Autocomplete
+	searchIndexer          *search.Indexer
+	searchGrpcServer       *SearchGRPCServer
 }
 
 // NewContainer constructor.
@@ -121,6 +132,31 @@ func (s *Container) Close() error {
 	s.usersRepository = nil
 	s.feedback = nil
 
+	if s.searchIndexer != nil {
+		s.searchIndexer.Close()
+		s.searchIndexer = nil
+	}
+
+	if s.searchIndex != nil {
+		err := s.searchIndex.Close()
+		if err != nil {
+			logrus.Error(err.Error())
+		}
+
+		s.searchIndex = nil
+		s.searchIndexer = nil
+	}
+
+	if s.searchAutocomplete != nil {
+		err := s.searchAutocomplete.Close()
+		if err != nil {
+			logrus.Error(err.Error())
+		}
+
+		s.searchAutocomplete = nil
+		s.searchIndexer = nil
+	}
+
 	if s.autowpDB != nil {
 		err := s.autowpDB.Close()
 		if err != nil {
@@ -288,7 +324,7 @@ func (s *Container) CommentsRepository() (*comments.Repository, error) {
 			return nil, err
 		}
 
-		messagingRepository, err := s.MessagingRepository()
+		notifier, err := s.Notifier()
 		if err != nil {
 			return nil, err
 		}
@@ -296,7 +332,7 @@ func (s *Container) CommentsRepository() (*comments.Repository, error) {
 		s.commentsRepository = comments.NewRepository(
 			db,
 			usersRepository,
-			messagingRepository,
+			notifier,
 			s.HostsManager(),
 		)
 	}
@@ -612,6 +648,116 @@ func (s *Container) PicturesREST() (*PicturesREST, error) {
 	), nil
 }
 
+func (s *Container) GraphQLREST() (*GraphQLREST, error) {
+	itemsRepo, err := s.ItemsRepository()
+	if err != nil {
+		return nil, err
+	}
+
+	picturesRepo, err := s.PicturesRepository()
+	if err != nil {
+		return nil, err
+	}
+
+	attrsRepo, err := s.AttrsRepository()
+	if err != nil {
+		return nil, err
+	}
+
+	usersRepo, err := s.UsersRepository()
+	if err != nil {
+		return nil, err
+	}
+
+	imageStorage, err := s.ImageStorage()
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := s.Config()
+
+	return NewGraphQLREST(
+		NewCatalogueGraphQL(itemsRepo, picturesRepo, attrsRepo, usersRepo, imageStorage),
+		cfg.GraphQL.MaxDepth,
+		cfg.GraphQL.MaxComplexity,
+	), nil
+}
+
+func (s *Container) DigestService() (*digest.Service, error) {
+	if s.digestService == nil {
+		db, err := s.GoquDB()
+		if err != nil {
+			return nil, err
+		}
+
+		usersRepository, err := s.UsersRepository()
+		if err != nil {
+			return nil, err
+		}
+
+		commentsRepository, err := s.CommentsRepository()
+		if err != nil {
+			return nil, err
+		}
+
+		messagingRepository, err := s.MessagingRepository()
+		if err != nil {
+			return nil, err
+		}
+
+		itemsRepository, err := s.ItemsRepository()
+		if err != nil {
+			return nil, err
+		}
+
+		i18n, err := s.I18n()
+		if err != nil {
+			return nil, err
+		}
+
+		cfg := s.Config()
+
+		s.digestService = digest.NewService(
+			db,
+			usersRepository,
+			commentsRepository,
+			messagingRepository,
+			itemsRepository,
+			s.EmailSender(),
+			s.HostsManager(),
+			i18n,
+			cfg.Notifications.EmailFrom,
+			cfg.EmailSalt,
+		)
+	}
+
+	return s.digestService, nil
+}
+
+func (s *Container) Antispam() (*antispam.Service, error) {
+	if s.antispamService == nil {
+		db, err := s.GoquDB()
+		if err != nil {
+			return nil, err
+		}
+
+		cfg := s.Config().Antispam
+
+		s.antispamService = antispam.NewService(db, cfg, antispam.DefaultClassifiers(db, cfg)...)
+	}
+
+	return s.antispamService, nil
+}
+
+func (s *Container) DigestREST() (*DigestREST, error) {
+	service, err := s.DigestService()
+	if err != nil {
+		return nil, err
+	}
+
+	return NewDigestREST(service), nil
+}
+
 func (s *Container) PublicRouter(ctx context.Context) (http.HandlerFunc, error) {
 	if s.publicRouter != nil {
 		return s.publicRouter, nil
@@ -677,6 +823,20 @@ func (s *Container) PublicRouter(ctx context.Context) (http.HandlerFunc, error)
 
 	usersREST.SetupRouter(ginEngine) //nolint: contextcheck
 
+	graphQLREST, err := s.GraphQLREST()
+	if err != nil {
+		return nil, fmt.Errorf("GraphQLREST(): %w", err)
+	}
+
+	graphQLREST.SetupRouter(ginEngine) //nolint: contextcheck
+
+	digestREST, err := s.DigestREST()
+	if err != nil {
+		return nil, fmt.Errorf("DigestREST(): %w", err)
+	}
+
+	digestREST.SetupRouter(ginEngine) //nolint: contextcheck
+
 	s.publicRouter = func(resp http.ResponseWriter, req *http.Request) {
 		if wrappedGrpc.IsAcceptableGrpcCorsRequest(req) || wrappedGrpc.IsGrpcWebRequest(req) {
 			wrappedGrpc.ServeHTTP(resp, req)
@@ -787,6 +947,11 @@ func (s *Container) GRPCServerWithServices() (*grpc.Server, error) {
 		return nil, err
 	}
 
+	searchSrv, err := s.SearchGRPCServer()
+	if err != nil {
+		return nil, err
+	}
+
 	trustedPeers := []netip.Prefix{
 		netip.MustParsePrefix(s.Config().TrustedNetwork),
 	}
@@ -829,6 +994,7 @@ func (s *Container) GRPCServerWithServices() (*grpc.Server, error) {
 	RegisterTrafficServer(grpcServer, trafficSrv)
 	RegisterUsersServer(grpcServer, usersSrv)
 	RegisterRatingServer(grpcServer, ratingSrv)
+	RegisterSearchServer(grpcServer, searchSrv)
 
 	reflection.Register(grpcServer)
 
@@ -1017,6 +1183,10 @@ func (s *Container) ItemExtractor() *ItemExtractor {
 	return NewItemExtractor(s)
 }
 
+func (s *Container) MarkupExtractor() *MarkupExtractor {
+	return NewMarkupExtractor(s)
+}
+
 func (s *Container) PictureItemExtractor() *PictureItemExtractor {
 	return NewPictureItemExtractor(s)
 }
@@ -1187,6 +1357,11 @@ func (s *Container) UsersGRPCServer() (*UsersGRPCServer, error) {
 			return nil, err
 		}
 
+		notifier, err := s.Notifier()
+		if err != nil {
+			return nil, err
+		}
+
 		s.usersGrpcServer = NewUsersGRPCServer(
 			auth,
 			contactsRepository,
@@ -1195,6 +1370,7 @@ func (s *Container) UsersGRPCServer() (*UsersGRPCServer, error) {
 			cfg.Languages,
 			cfg.Captcha,
 			userExtractor,
+			notifier,
 		)
 	}
 
@@ -1327,6 +1503,11 @@ func (s *Container) ItemsGRPCServer() (*ItemsGRPCServer, error) {
 			return nil, err
 		}
 
+		searchIndexer, err := s.SearchIndexer()
+		if err != nil {
+			return nil, err
+		}
+
 		s.itemsGrpcServer = NewItemsGRPCServer(
 			repo,
 			db,
@@ -1346,6 +1527,7 @@ func (s *Container) ItemsGRPCServer() (*ItemsGRPCServer, error) {
 			s.NewLinkExtractor(),
 			itemOfDayRepository,
 			redisClient,
+			searchIndexer,
 		)
 	}
 
@@ -1401,12 +1583,37 @@ func (s *Container) CommentsGRPCServer() (*CommentsGRPCServer, error) {
 			return nil, err
 		}
 
+		searchIndexer, err := s.SearchIndexer()
+		if err != nil {
+			return nil, err
+		}
+
+		antispamService, err := s.Antispam()
+		if err != nil {
+			return nil, err
+		}
+
+		banRepository, err := s.BanRepository()
+		if err != nil {
+			return nil, err
+		}
+
+		events, err := s.Events()
+		if err != nil {
+			return nil, err
+		}
+
 		s.commentsGrpcServer = NewCommentsGRPCServer(
 			auth,
 			commentsRepository,
 			usersRepository,
 			picturesRepository,
 			userExtractor,
+			searchIndexer,
+			s.MarkupExtractor(),
+			antispamService,
+			banRepository,
+			events,
 		)
 	}
 
@@ -1547,6 +1754,16 @@ func (s *Container) PicturesGRPCServer() (*PicturesGRPCServer, error) {
 			return nil, err
 		}
 
+		searchIndexer, err := s.SearchIndexer()
+		if err != nil {
+			return nil, err
+		}
+
+		notifier, err := s.Notifier()
+		if err != nil {
+			return nil, err
+		}
+
 		s.picturesGrpcServer = NewPicturesGRPCServer(
 			repository,
 			auth,
@@ -1562,6 +1779,8 @@ func (s *Container) PicturesGRPCServer() (*PicturesGRPCServer, error) {
 			s.PictureExtractor(),
 			s.PictureItemExtractor(),
 			s.ItemExtractor(),
+			searchIndexer,
+			notifier,
 		)
 	}
 
@@ -1631,7 +1850,12 @@ func (s *Container) ForumsGRPCServer() (*ForumsGRPCServer, error) {
 			return nil, err
 		}
 
-		s.forumsGrpcServer = NewForumsGRPCServer(auth, forums, commentsRepo, usersRepo)
+		searchIndexer, err := s.SearchIndexer()
+		if err != nil {
+			return nil, err
+		}
+
+		s.forumsGrpcServer = NewForumsGRPCServer(auth, forums, commentsRepo, usersRepo, searchIndexer)
 	}
 
 	return s.forumsGrpcServer, nil
@@ -1649,7 +1873,7 @@ func (s *Container) MessagingGRPCServer() (*MessagingGRPCServer, error) {
 			return nil, err
 		}
 
-		s.messagingGrpcServer = NewMessagingGRPCServer(repository, auth)
+		s.messagingGrpcServer = NewMessagingGRPCServer(repository, auth, s.MarkupExtractor())
 	}
 
 	return s.messagingGrpcServer, nil
@@ -1698,15 +1922,21 @@ func (s *Container) MessagingRepository() (*messaging.Repository, error) {
 			return nil, err
 		}
 
+		usersRepository, err := s.UsersRepository()
+		if err != nil {
+			return nil, err
+		}
+
 		s.messagingRepository = messaging.NewRepository(
 			db,
+			usersRepository,
 			func(ctx context.Context, fromUserID int64, toUserID int64, text string) error {
-				tg, err := s.TelegramService()
+				notifier, err := s.Notifier()
 				if err != nil {
 					return err
 				}
 
-				return tg.NotifyMessage(ctx, fromUserID, toUserID, text)
+				return notifier.MessageCreated(ctx, fromUserID, toUserID, text)
 			},
 			i18n,
 		)
@@ -1715,6 +1945,43 @@ func (s *Container) MessagingRepository() (*messaging.Repository, error) {
 	return s.messagingRepository, nil
 }
 
+func (s *Container) Notifier() (*notifications.Notifier, error) {
+	if s.notifier == nil {
+		usersRepository, err := s.UsersRepository()
+		if err != nil {
+			return nil, err
+		}
+
+		messagingRepository, err := s.MessagingRepository()
+		if err != nil {
+			return nil, err
+		}
+
+		i18n, err := s.I18n()
+		if err != nil {
+			return nil, err
+		}
+
+		s.notifier = notifications.NewNotifier(
+			usersRepository,
+			messagingRepository,
+			s.EmailSender(),
+			s.Config().Notifications,
+			i18n,
+			func(ctx context.Context, fromUserID int64, toUserID int64, text string) error {
+				tg, err := s.TelegramService()
+				if err != nil {
+					return err
+				}
+
+				return tg.NotifyMessage(ctx, fromUserID, toUserID, text)
+			},
+		)
+	}
+
+	return s.notifier, nil
+}
+
 func (s *Container) Keycloak() *gocloak.GoCloak {
 	if s.keyCloak == nil {
 		client := gocloak.NewClient(s.Config().Keycloak.URL)
@@ -1831,3 +2098,67 @@ func (s *Container) YoomoneyHandler() (*YoomoneyHandler, error) {
 
 	return s.yoomoneyHandler, nil
 }
+
+func (s *Container) SearchIndex() (*search.Index, error) {
+	if s.searchIndex == nil {
+		idx, err := search.Open(s.Config().Search.IndexPath)
+		if err != nil {
+			return nil, err
+		}
+
+		s.searchIndex = idx
+	}
+
+	return s.searchIndex, nil
+}
+
+func (s *Container) SearchAutocomplete() (*search.Autocomplete, error) {
+	if s.searchAutocomplete == nil {
+		autocomplete, err := search.OpenAutocomplete(s.Config().Search.AutocompleteIndexPath)
+		if err != nil {
+			return nil, err
+		}
+
+		s.searchAutocomplete = autocomplete
+	}
+
+	return s.searchAutocomplete, nil
+}
+
+func (s *Container) SearchIndexer() (*search.Indexer, error) {
+	if s.searchIndexer == nil {
+		db, err := s.GoquDB()
+		if err != nil {
+			return nil, err
+		}
+
+		idx, err := s.SearchIndex()
+		if err != nil {
+			return nil, err
+		}
+
+		autocomplete, err := s.SearchAutocomplete()
+		if err != nil {
+			return nil, err
+		}
+
+		cfg := s.Config()
+
+		s.searchIndexer = search.NewIndexer(db, idx, autocomplete, cfg.RabbitMQ, cfg.Search.RefreshExchange)
+	}
+
+	return s.searchIndexer, nil
+}
+
+func (s *Container) SearchGRPCServer() (*SearchGRPCServer, error) {
+	if s.searchGrpcServer == nil {
+		idx, err := s.SearchIndex()
+		if err != nil {
+			return nil, err
+		}
+
+		s.searchGrpcServer = NewSearchGRPCServer(idx)
+	}
+
+	return s.searchGrpcServer, nil
+}
@@ -0,0 +1,79 @@
+package goautowp
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+
+	"github.com/autowp/goautowp/digest"
+	"github.com/gin-gonic/gin"
+)
+
+type DigestREST struct {
+	service *digest.Service
+}
+
+func NewDigestREST(service *digest.Service) *DigestREST {
+	return &DigestREST{
+		service: service,
+	}
+}
+
+func (s *DigestREST) unsubscribePageAction(ctx *gin.Context) {
+	userID, err := strconv.ParseInt(ctx.Query("user_id"), 10, 64)
+	if err != nil {
+		ctx.String(http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	page, err := s.service.UnsubscribePage(ctx, userID, ctx.Query("sign"), ctx.Request.URL.RequestURI())
+	if err != nil {
+		if errors.Is(err, digest.ErrInvalidSignature) {
+			ctx.Status(http.StatusForbidden)
+
+			return
+		}
+
+		ctx.String(http.StatusInternalServerError, err.Error())
+
+		return
+	}
+
+	ctx.Data(http.StatusOK, "text/html; charset=utf-8", []byte(page))
+}
+
+func (s *DigestREST) unsubscribeAction(ctx *gin.Context) {
+	userID, err := strconv.ParseInt(ctx.Query("user_id"), 10, 64)
+	if err != nil {
+		ctx.String(http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	message, err := s.service.Unsubscribe(ctx, userID, ctx.Query("sign"))
+	if err != nil {
+		if errors.Is(err, digest.ErrInvalidSignature) {
+			ctx.Status(http.StatusForbidden)
+
+			return
+		}
+
+		ctx.String(http.StatusInternalServerError, err.Error())
+
+		return
+	}
+
+	ctx.String(http.StatusOK, message)
+}
+
+func (s *DigestREST) SetupRouter(router *gin.Engine) {
+	// GET for link in email only asks for confirmation, POST is sent by that page
+	// and by one-click List-Unsubscribe of mail clients
+	router.GET("/api/digest/unsubscribe", func(ctx *gin.Context) {
+		s.unsubscribePageAction(ctx)
+	})
+	router.POST("/api/digest/unsubscribe", func(ctx *gin.Context) {
+		s.unsubscribeAction(ctx)
+	})
+}
@@ -0,0 +1,211 @@
+package goautowp
+
+import (
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"slices"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/Nerzal/gocloak/v13"
+	"github.com/autowp/goautowp/config"
+	"github.com/autowp/goautowp/digest"
+	"github.com/autowp/goautowp/users"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/protobuf/types/known/emptypb"
+)
+
+func TestDigestUnsubscribe(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	cfg := config.LoadConfig(".")
+	client := NewUsersClient(conn)
+	kc := cnt.Keycloak()
+
+	token, err := kc.Login(ctx, "frontend", "", cfg.Keycloak.Realm, testUsername, testPassword)
+	require.NoError(t, err)
+	require.NotNil(t, token)
+
+	ctx = metadata.AppendToOutgoingContext(ctx, authorizationHeader, bearerPrefix+token.AccessToken)
+
+	me, err := client.Me(ctx, &APIMeRequest{})
+	require.NoError(t, err)
+
+	_, err = client.SetDigestPeriod(ctx, &SetDigestPeriodRequest{Period: DigestPeriod_DIGEST_PERIOD_DAILY})
+	require.NoError(t, err)
+
+	prefs, err := client.GetNotificationPreferences(ctx, &emptypb.Empty{})
+	require.NoError(t, err)
+	require.Equal(t, DigestPeriod_DIGEST_PERIOD_DAILY, prefs.GetDigestPeriod())
+
+	digestService, err := cnt.DigestService()
+	require.NoError(t, err)
+
+	digestREST, err := cnt.DigestREST()
+	require.NoError(t, err)
+
+	router := gin.New()
+	digestREST.SetupRouter(router)
+
+	unsubscribeURL, err := url.Parse(digestService.UnsubscribeURL(&url.URL{}, me.GetId()))
+	require.NoError(t, err)
+
+	forgedURL := *unsubscribeURL
+	query := forgedURL.Query()
+	query.Set("sign", "forged")
+	forgedURL.RawQuery = query.Encode()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, forgedURL.String(), nil)
+	require.NoError(t, err)
+
+	resRecorder := httptest.NewRecorder()
+	router.ServeHTTP(resRecorder, req)
+	require.Equal(t, http.StatusForbidden, resRecorder.Code)
+
+	req, err = http.NewRequestWithContext(ctx, http.MethodPost, forgedURL.String(), nil)
+	require.NoError(t, err)
+
+	resRecorder = httptest.NewRecorder()
+	router.ServeHTTP(resRecorder, req)
+	require.Equal(t, http.StatusForbidden, resRecorder.Code)
+
+	// link of email only asks for confirmation
+	req, err = http.NewRequestWithContext(ctx, http.MethodGet, unsubscribeURL.String(), nil)
+	require.NoError(t, err)
+
+	resRecorder = httptest.NewRecorder()
+	router.ServeHTTP(resRecorder, req)
+	require.Equal(t, http.StatusOK, resRecorder.Code)
+	require.Contains(t, resRecorder.Body.String(), `<form method="post"`)
+
+	prefs, err = client.GetNotificationPreferences(ctx, &emptypb.Empty{})
+	require.NoError(t, err)
+	require.Equal(t, DigestPeriod_DIGEST_PERIOD_DAILY, prefs.GetDigestPeriod())
+
+	req, err = http.NewRequestWithContext(ctx, http.MethodPost, unsubscribeURL.String(), nil)
+	require.NoError(t, err)
+
+	resRecorder = httptest.NewRecorder()
+	router.ServeHTTP(resRecorder, req)
+	require.Equal(t, http.StatusOK, resRecorder.Code)
+
+	prefs, err = client.GetNotificationPreferences(ctx, &emptypb.Empty{})
+	require.NoError(t, err)
+	require.Equal(t, DigestPeriod_DIGEST_PERIOD_NONE, prefs.GetDigestPeriod())
+}
+
+func TestSendDigests(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	cfg := config.LoadConfig(".")
+	kc := cnt.Keycloak()
+
+	goquDB, err := cnt.GoquDB()
+	require.NoError(t, err)
+
+	random := rand.New(rand.NewSource(time.Now().UnixNano())) //nolint:gosec
+	userEmail := "digest" + strconv.Itoa(random.Int()) + "@example.com"
+	password := "password"
+
+	clientToken, err := kc.LoginClient(ctx, cfg.Keycloak.ClientID, cfg.Keycloak.ClientSecret, cfg.Keycloak.Realm)
+	require.NoError(t, err)
+
+	_, err = kc.CreateUser(ctx, clientToken.AccessToken, cfg.Keycloak.Realm, gocloak.User{
+		Enabled:       gocloak.BoolP(true),
+		EmailVerified: gocloak.BoolP(true),
+		Username:      &userEmail,
+		FirstName:     gocloak.StringP("digest"),
+		Email:         &userEmail,
+		Credentials: &[]gocloak.CredentialRepresentation{{
+			Type:  gocloak.StringP("password"),
+			Value: &password,
+		}},
+	})
+	require.NoError(t, err)
+
+	token, err := kc.Login(ctx, "frontend", "", cfg.Keycloak.Realm, userEmail, password)
+	require.NoError(t, err)
+
+	userCtx := metadata.AppendToOutgoingContext(ctx, authorizationHeader, bearerPrefix+token.AccessToken)
+
+	me, err := NewUsersClient(conn).Me(userCtx, &APIMeRequest{})
+	require.NoError(t, err)
+
+	userID := me.GetId()
+
+	_, err = NewUsersClient(conn).SetDigestPeriod(
+		userCtx, &SetDigestPeriodRequest{Period: DigestPeriod_DIGEST_PERIOD_DAILY},
+	)
+	require.NoError(t, err)
+
+	_, adminToken := getUserWithCleanHistory(t, conn, cfg, goquDB, adminUsername, adminPassword)
+	adminCtx := metadata.AppendToOutgoingContext(ctx, authorizationHeader, bearerPrefix+adminToken)
+
+	commentsClient := NewCommentsClient(conn)
+
+	comment, err := commentsClient.Add(userCtx, &AddCommentRequest{
+		ItemId:  1,
+		TypeId:  CommentsType_ARTICLES_TYPE_ID,
+		Message: "Digest root comment",
+	})
+	require.NoError(t, err)
+
+	reply, err := commentsClient.Add(adminCtx, &AddCommentRequest{
+		ItemId:   1,
+		TypeId:   CommentsType_ARTICLES_TYPE_ID,
+		Message:  "Digest reply",
+		ParentId: comment.GetId(),
+	})
+	require.NoError(t, err)
+
+	now := time.Now()
+	since := now.Add(-time.Hour)
+
+	commentsRepository, err := cnt.CommentsRepository()
+	require.NoError(t, err)
+
+	replyIDs, err := commentsRepository.UnreadReplies(ctx, userID, since)
+	require.NoError(t, err)
+	require.Contains(t, replyIDs, reply.GetId())
+
+	usersRepository, err := cnt.UsersRepository()
+	require.NoError(t, err)
+
+	subscribed := func(period digest.Period, sentBefore time.Time) bool {
+		subscriptions, err := usersRepository.DigestSubscriptions(ctx, string(period), sentBefore)
+		require.NoError(t, err)
+
+		return slices.ContainsFunc(subscriptions, func(subscription users.DigestSubscription) bool {
+			return subscription.UserID == userID
+		})
+	}
+
+	require.True(t, subscribed(digest.PeriodDaily, now))
+	require.False(t, subscribed(digest.PeriodWeekly, now))
+
+	digestService, err := cnt.DigestService()
+	require.NoError(t, err)
+
+	sent, err := digestService.SendDigests(ctx, now)
+	require.NoError(t, err)
+	require.Positive(t, sent)
+
+	// digest is sent once per period
+	require.False(t, subscribed(digest.PeriodDaily, now.Add(-time.Minute)))
+	require.True(t, subscribed(digest.PeriodDaily, now.Add(time.Minute)))
+
+	// viewed replies are not sent again
+	_, err = commentsClient.View(userCtx, &CommentsViewRequest{ItemId: 1, TypeId: CommentsType_ARTICLES_TYPE_ID})
+	require.NoError(t, err)
+
+	replyIDs, err = commentsRepository.UnreadReplies(ctx, userID, since)
+	require.NoError(t, err)
+	require.NotContains(t, replyIDs, reply.GetId())
+}
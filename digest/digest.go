@@ -0,0 +1,508 @@
+package digest
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"embed"
+	"encoding/hex"
+	"errors"
+	htmltemplate "html/template"
+	"net/url"
+	"strconv"
+	texttemplate "text/template"
+	"time"
+
+	"github.com/autowp/goautowp/comments"
+	"github.com/autowp/goautowp/email"
+	"github.com/autowp/goautowp/frontend"
+	"github.com/autowp/goautowp/hosts"
+	"github.com/autowp/goautowp/i18nbundle"
+	"github.com/autowp/goautowp/items"
+	"github.com/autowp/goautowp/messaging"
+	"github.com/autowp/goautowp/query"
+	"github.com/autowp/goautowp/schema"
+	"github.com/autowp/goautowp/users"
+	"github.com/doug-martin/goqu/v9"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/sirupsen/logrus"
+)
+
+//go:embed *.tmpl
+var TemplatesFS embed.FS
+
+var ErrInvalidSignature = errors.New("invalid signature")
+
+type Period string
+
+const (
+	PeriodDaily  Period = "daily"
+	PeriodWeekly Period = "weekly"
+)
+
+var periodDurations = []struct {
+	period   Period
+	duration time.Duration
+}{
+	{period: PeriodDaily, duration: 24 * time.Hour},
+	{period: PeriodWeekly, duration: 7 * 24 * time.Hour},
+}
+
+const (
+	// sendTolerance allows scheduler to start a bit earlier than a period after previous digest.
+	sendTolerance   = time.Hour
+	maxReplies      = 10
+	unsubscribePath = "/api/digest/unsubscribe"
+)
+
+type ItemPictures struct {
+	Name  string
+	Count int32
+	URL   string
+}
+
+type TemplateData struct {
+	Language         string
+	Subject          string
+	RepliesTitle     string
+	Replies          []string
+	MoreReplies      string
+	PicturesTitle    string
+	Pictures         []ItemPictures
+	MessagesTitle    string
+	MessagesURL      string
+	UnsubscribeTitle string
+	UnsubscribeURL   string
+}
+
+type unsubscribeTemplateData struct {
+	Language       string
+	Title          string
+	Question       string
+	UnsubscribeURL string
+}
+
+// Service collects unread replies, new pictures of subscribed items and unread personal messages
+// into periodic email digest.
+type Service struct {
+	db                  *goqu.Database
+	userRepository      *users.Repository
+	commentsRepository  *comments.Repository
+	messagingRepository *messaging.Repository
+	itemRepository      *items.Repository
+	emailSender         email.Sender
+	hostManager         *hosts.Manager
+	i18n                *i18nbundle.I18n
+	emailFrom           string
+	salt                string
+}
+
+func NewService(
+	db *goqu.Database,
+	userRepository *users.Repository,
+	commentsRepository *comments.Repository,
+	messagingRepository *messaging.Repository,
+	itemRepository *items.Repository,
+	emailSender email.Sender,
+	hostManager *hosts.Manager,
+	i18n *i18nbundle.I18n,
+	emailFrom string,
+	salt string,
+) *Service {
+	return &Service{
+		db:                  db,
+		userRepository:      userRepository,
+		commentsRepository:  commentsRepository,
+		messagingRepository: messagingRepository,
+		itemRepository:      itemRepository,
+		emailSender:         emailSender,
+		hostManager:         hostManager,
+		i18n:                i18n,
+		emailFrom:           emailFrom,
+		salt:                salt,
+	}
+}
+
+// SendDigests sends digests to subscribers, which period passed since previous digest.
+// Returns number of sent emails, digests without news are skipped.
+func (s *Service) SendDigests(ctx context.Context, now time.Time) (int, error) {
+	sent := 0
+
+	for _, period := range periodDurations {
+		subscriptions, err := s.userRepository.DigestSubscriptions(
+			ctx, string(period.period), now.Add(-period.duration+sendTolerance),
+		)
+		if err != nil {
+			return sent, err
+		}
+
+		for _, subscription := range subscriptions {
+			since := now.Add(-period.duration)
+			if subscription.SentAt.Valid {
+				since = subscription.SentAt.Time
+			}
+
+			success, err := s.Send(ctx, subscription.UserID, since)
+			if err != nil {
+				logrus.Errorf("digest of user %d: %s", subscription.UserID, err.Error())
+
+				continue
+			}
+
+			if success {
+				sent++
+			}
+
+			err = s.userRepository.SetDigestSent(ctx, subscription.UserID, now)
+			if err != nil {
+				return sent, err
+			}
+		}
+	}
+
+	return sent, nil
+}
+
+// Send sends digest of news since time to user, returns false when there is nothing to send.
+func (s *Service) Send(ctx context.Context, userID int64, since time.Time) (bool, error) {
+	notDeleted := false
+
+	user, err := s.userRepository.User(
+		ctx, &query.UserListOptions{ID: userID, Deleted: &notDeleted}, users.UserFields{}, users.OrderByNone,
+	)
+	if errors.Is(err, users.ErrUserNotFound) {
+		return false, nil
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	if user.EMail == nil || len(*user.EMail) == 0 {
+		return false, nil
+	}
+
+	data, err := s.templateData(ctx, userID, user.Language, since)
+	if err != nil || data == nil {
+		return false, err
+	}
+
+	text, html, err := render(data)
+	if err != nil {
+		return false, err
+	}
+
+	err = s.emailSender.SendHTML(
+		s.emailFrom, []string{*user.EMail}, data.Subject, text, html, data.UnsubscribeURL,
+	)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
+
+// render returns text and HTML bodies of digest.
+func render(data *TemplateData) (string, string, error) {
+	htmlTmpl, err := htmltemplate.New("digest.html.tmpl").ParseFS(TemplatesFS, "digest.html.tmpl")
+	if err != nil {
+		return "", "", err
+	}
+
+	textTmpl, err := texttemplate.New("digest.txt.tmpl").ParseFS(TemplatesFS, "digest.txt.tmpl")
+	if err != nil {
+		return "", "", err
+	}
+
+	htmlBuf := new(bytes.Buffer)
+
+	err = htmlTmpl.Execute(htmlBuf, data)
+	if err != nil {
+		return "", "", err
+	}
+
+	textBuf := new(bytes.Buffer)
+
+	err = textTmpl.Execute(textBuf, data)
+	if err != nil {
+		return "", "", err
+	}
+
+	return textBuf.String(), htmlBuf.String(), nil
+}
+
+// templateData returns nil when there are no news since time.
+func (s *Service) templateData(
+	ctx context.Context, userID int64, lang string, since time.Time,
+) (*TemplateData, error) {
+	uri, err := s.hostManager.URIByLanguage(lang)
+	if err != nil {
+		return nil, err
+	}
+
+	replyIDs, err := s.commentsRepository.UnreadReplies(ctx, userID, since)
+	if err != nil {
+		return nil, err
+	}
+
+	pictures, err := s.subscribedItemsPictures(ctx, userID, lang, since, uri)
+	if err != nil {
+		return nil, err
+	}
+
+	messagesCount, err := s.messagingRepository.GetUserNewInboxCountSince(ctx, userID, since)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(replyIDs) == 0 && len(pictures) == 0 && messagesCount == 0 {
+		return nil, nil //nolint: nilnil
+	}
+
+	localizer := s.i18n.Localizer(lang)
+	localize := func(messageID string, count int) (string, error) {
+		return localizer.Localize(&i18n.LocalizeConfig{
+			DefaultMessage: &i18n.Message{ID: messageID},
+			TemplateData:   map[string]interface{}{"Count": count},
+		})
+	}
+
+	data := TemplateData{
+		Language:       lang,
+		Pictures:       pictures,
+		UnsubscribeURL: s.UnsubscribeURL(uri, userID),
+	}
+
+	data.Subject, err = localize("digest/subject", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	data.UnsubscribeTitle, err = localize("digest/unsubscribe", 0)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(replyIDs) > 0 {
+		data.RepliesTitle, err = localize("digest/replies", len(replyIDs))
+		if err != nil {
+			return nil, err
+		}
+
+		for _, replyID := range replyIDs[:min(len(replyIDs), maxReplies)] {
+			messageURL, err := s.commentsRepository.MessageURL(ctx, replyID, uri)
+			if err != nil {
+				return nil, err
+			}
+
+			data.Replies = append(data.Replies, messageURL)
+		}
+
+		if len(replyIDs) > maxReplies {
+			data.MoreReplies, err = localize("digest/more", len(replyIDs)-maxReplies)
+			if err != nil {
+				return nil, err
+			}
+		}
+	}
+
+	if len(pictures) > 0 {
+		total := 0
+		for _, item := range pictures {
+			total += int(item.Count)
+		}
+
+		data.PicturesTitle, err = localize("digest/pictures", total)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if messagesCount > 0 {
+		data.MessagesTitle, err = localize("digest/messages", int(messagesCount))
+		if err != nil {
+			return nil, err
+		}
+
+		messagesURL := *uri
+		messagesURL.Path = "/account/messages"
+		data.MessagesURL = messagesURL.String()
+	}
+
+	return &data, nil
+}
+
+func (s *Service) subscribedItemsPictures(
+	ctx context.Context, userID int64, lang string, since time.Time, uri *url.URL,
+) ([]ItemPictures, error) {
+	var rows []struct {
+		ItemID    int64 `db:"item_id"`
+		Count     int32 `db:"count"`
+		PictureID int64 `db:"picture_id"`
+	}
+
+	err := s.db.Select(
+		schema.UserItemSubscribeTableItemIDCol,
+		goqu.COUNT(goqu.DISTINCT(schema.PictureTableIDCol)).As("count"),
+		goqu.MAX(schema.PictureTableIDCol).As("picture_id"),
+	).
+		From(schema.UserItemSubscribeTable).
+		Join(schema.ItemParentCacheTable, goqu.On(
+			schema.UserItemSubscribeTableItemIDCol.Eq(schema.ItemParentCacheTableParentIDCol),
+		)).
+		Join(schema.PictureItemTable, goqu.On(schema.ItemParentCacheTableItemIDCol.Eq(schema.PictureItemTableItemIDCol))).
+		Join(schema.PictureTable, goqu.On(schema.PictureItemTablePictureIDCol.Eq(schema.PictureTableIDCol))).
+		Where(
+			schema.UserItemSubscribeTableUserIDCol.Eq(userID),
+			schema.PictureTableStatusCol.Eq(schema.PictureStatusAccepted),
+			schema.PictureTableAcceptDatetimeCol.Gt(since),
+		).
+		GroupBy(schema.UserItemSubscribeTableItemIDCol).
+		Order(schema.UserItemSubscribeTableItemIDCol.Asc()).
+		ScanStructsContext(ctx, &rows)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rows) == 0 {
+		return nil, nil
+	}
+
+	itemIDs := make([]int64, 0, len(rows))
+	pictureIDs := make([]int64, 0, len(rows))
+
+	for _, row := range rows {
+		itemIDs = append(itemIDs, row.ItemID)
+		pictureIDs = append(pictureIDs, row.PictureID)
+	}
+
+	itemRows, _, err := s.itemRepository.List(ctx, &query.ItemListOptions{
+		ItemIDs:  itemIDs,
+		Language: lang,
+	}, &items.ItemFields{NameOnly: true}, items.OrderByNone, false)
+	if err != nil {
+		return nil, err
+	}
+
+	names := make(map[int64]string, len(itemRows))
+	for _, itemRow := range itemRows {
+		names[itemRow.ID] = itemRow.NameOnly
+	}
+
+	var identities []struct {
+		ID       int64  `db:"id"`
+		Identity string `db:"identity"`
+	}
+
+	err = s.db.Select(schema.PictureTableIDCol, schema.PictureTableIdentityCol).
+		From(schema.PictureTable).
+		Where(schema.PictureTableIDCol.In(pictureIDs)).
+		ScanStructsContext(ctx, &identities)
+	if err != nil {
+		return nil, err
+	}
+
+	pictureURLs := make(map[int64]string, len(identities))
+	for _, identity := range identities {
+		pictureURLs[identity.ID] = frontend.PictureURL(uri, identity.Identity)
+	}
+
+	result := make([]ItemPictures, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, ItemPictures{
+			Name:  names[row.ItemID],
+			Count: row.Count,
+			URL:   pictureURLs[row.PictureID],
+		})
+	}
+
+	return result, nil
+}
+
+func (s *Service) sign(userID int64) string {
+	mac := hmac.New(sha256.New, []byte(s.salt))
+	_, _ = mac.Write([]byte("digest-unsubscribe:" + strconv.FormatInt(userID, 10)))
+
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// UnsubscribeURL returns link, which unsubscribes user from digest without authentication.
+func (s *Service) UnsubscribeURL(uri *url.URL, userID int64) string {
+	result := *uri
+	result.Path = unsubscribePath
+	result.RawQuery = url.Values{
+		"user_id": []string{strconv.FormatInt(userID, 10)},
+		"sign":    []string{s.sign(userID)},
+	}.Encode()
+
+	return result.String()
+}
+
+// UnsubscribePage returns HTML page in language of user, which asks to confirm unsubscription
+// with POST to unsubscribeURL. Link in email only opens this page, so link prefetchers and scanners
+// don't unsubscribe user.
+func (s *Service) UnsubscribePage(
+	ctx context.Context, userID int64, signature string, unsubscribeURL string,
+) (string, error) {
+	if !hmac.Equal([]byte(signature), []byte(s.sign(userID))) {
+		return "", ErrInvalidSignature
+	}
+
+	lang, err := s.userRepository.UserLanguage(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+
+	localizer := s.i18n.Localizer(lang)
+	data := unsubscribeTemplateData{Language: lang, UnsubscribeURL: unsubscribeURL}
+
+	data.Title, err = localizer.Localize(&i18n.LocalizeConfig{
+		DefaultMessage: &i18n.Message{ID: "digest/unsubscribe"},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	data.Question, err = localizer.Localize(&i18n.LocalizeConfig{
+		DefaultMessage: &i18n.Message{ID: "digest/unsubscribe-confirm"},
+	})
+	if err != nil {
+		return "", err
+	}
+
+	tmpl, err := htmltemplate.New("unsubscribe.html.tmpl").ParseFS(TemplatesFS, "unsubscribe.html.tmpl")
+	if err != nil {
+		return "", err
+	}
+
+	buf := new(bytes.Buffer)
+
+	err = tmpl.Execute(buf, data)
+	if err != nil {
+		return "", err
+	}
+
+	return buf.String(), nil
+}
+
+// Unsubscribe removes digest subscription of user and returns confirmation in language of user.
+func (s *Service) Unsubscribe(ctx context.Context, userID int64, signature string) (string, error) {
+	if !hmac.Equal([]byte(signature), []byte(s.sign(userID))) {
+		return "", ErrInvalidSignature
+	}
+
+	err := s.userRepository.SetDigestPeriod(ctx, userID, "")
+	if err != nil {
+		return "", err
+	}
+
+	lang, err := s.userRepository.UserLanguage(ctx, userID)
+	if err != nil {
+		return "", err
+	}
+
+	return s.i18n.Localizer(lang).Localize(&i18n.LocalizeConfig{
+		DefaultMessage: &i18n.Message{ID: "digest/unsubscribed"},
+	})
+}
@@ -0,0 +1,55 @@
+package digest
+
+import (
+	"net/url"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRender(t *testing.T) {
+	t.Parallel()
+
+	text, html, err := render(&TemplateData{
+		Language:         "en",
+		Subject:          "Your digest",
+		RepliesTitle:     "New replies to your messages: 1",
+		Replies:          []string{"https://www.example.com/ng/picture/abc?page=1#msg1"},
+		PicturesTitle:    "New pictures of items you follow: 2",
+		Pictures:         []ItemPictures{{Name: "BMW <M3>", Count: 2, URL: "https://www.example.com/picture/abc"}},
+		UnsubscribeTitle: "Unsubscribe from digest",
+		UnsubscribeURL:   "https://www.example.com/api/digest/unsubscribe?sign=x&user_id=1",
+	})
+	require.NoError(t, err)
+
+	require.Equal(t, `Your digest
+
+New replies to your messages: 1
+https://www.example.com/ng/picture/abc?page=1#msg1
+
+New pictures of items you follow: 2
+BMW <M3>: 2
+https://www.example.com/picture/abc
+
+--
+Unsubscribe from digest: https://www.example.com/api/digest/unsubscribe?sign=x&user_id=1
+`, text)
+	require.Contains(t, html, `<a href="https://www.example.com/picture/abc">BMW &lt;M3&gt;</a>: 2`)
+	require.NotContains(t, html, "/account/messages")
+}
+
+func TestUnsubscribeURL(t *testing.T) {
+	t.Parallel()
+
+	service := NewService(nil, nil, nil, nil, nil, nil, nil, nil, "", "salt")
+	uri, err := url.Parse("https://www.example.com")
+	require.NoError(t, err)
+
+	unsubscribeURL, err := url.Parse(service.UnsubscribeURL(uri, 1))
+	require.NoError(t, err)
+	require.Equal(t, unsubscribePath, unsubscribeURL.Path)
+	require.Equal(t, "1", unsubscribeURL.Query().Get("user_id"))
+	require.Equal(t, service.sign(1), unsubscribeURL.Query().Get("sign"))
+	require.NotEqual(t, service.sign(1), service.sign(2))
+	require.NotEqual(t, service.sign(1), NewService(nil, nil, nil, nil, nil, nil, nil, nil, "", "other").sign(1))
+}
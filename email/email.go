@@ -11,6 +11,8 @@ import (
 // Sender Sender.
 type Sender interface {
 	Send(from string, to []string, subject, body, replyTo string) error
+	// SendHTML sends body with htmlBody alternative, unsubscribeURL goes to List-Unsubscribe header when not empty.
+	SendHTML(from string, to []string, subject, body, htmlBody, unsubscribeURL string) error
 }
 
 type SMTPSender struct {
@@ -18,7 +20,8 @@ type SMTPSender struct {
 }
 
 type MockSender struct {
-	Body string
+	Body     string
+	HTMLBody string
 }
 
 func (s *SMTPSender) Send(from string, to []string, subject, body, replyTo string) error {
@@ -34,9 +37,36 @@ func (s *SMTPSender) Send(from string, to []string, subject, body, replyTo strin
 	return d.DialAndSend(msg)
 }
 
+func (s *SMTPSender) SendHTML(from string, to []string, subject, body, htmlBody, unsubscribeURL string) error {
+	msg := gomail.NewMessage()
+	msg.SetHeader("From", from)
+	msg.SetHeader("To", to...)
+	msg.SetHeader("Subject", subject)
+	msg.SetBody("text/plain", body)
+	msg.AddAlternative("text/html", htmlBody)
+
+	if len(unsubscribeURL) > 0 {
+		msg.SetHeader("List-Unsubscribe", "<"+unsubscribeURL+">")
+		// RFC 8058, mail clients unsubscribe with POST to List-Unsubscribe url
+		msg.SetHeader("List-Unsubscribe-Post", "List-Unsubscribe=One-Click")
+	}
+
+	d := gomail.NewDialer(s.Config.Hostname, s.Config.Port, s.Config.Username, s.Config.Password)
+
+	return d.DialAndSend(msg)
+}
+
 func (s *MockSender) Send(from string, to []string, subject, body, _ string) error {
 	logrus.Debugf("Subject: %s\nFrom: %s\nTo: %s\n%s", subject, from, strings.Join(to, ", "), body)
 	s.Body = body
 
 	return nil
 }
+
+func (s *MockSender) SendHTML(from string, to []string, subject, body, htmlBody, _ string) error {
+	logrus.Debugf("Subject: %s\nFrom: %s\nTo: %s\n%s", subject, from, strings.Join(to, ", "), body)
+	s.Body = body
+	s.HTMLBody = htmlBody
+
+	return nil
+}
@@ -0,0 +1,38 @@
+// Package export writes the catalogue as an open data set.
+//
+// A data set is a directory with metadata.json and a newline-delimited JSON file per entity.
+// Every line of a file is a single JSON object, absent values are null. Fields are never renamed
+// or removed within a format version, new fields may be appended.
+//
+// metadata.json describes the data set: format name and version, generation time, licence,
+// the `since` boundary of incremental export and number of records in every file.
+//
+// Files:
+//
+//	deletions.ndjson              tombstones of incremental export: entity, item_id, parent_id, picture_id, deleted_at
+//	brands.ndjson                 items of brand type: id, catname, name, full_name, updated_at
+//	items.ndjson                  other items: id, type, catname, name, body, production years and months,
+//	                              model years, flags, produced amount, engine_item_id, add_datetime, updated_at
+//	item_parents.ndjson           links of catalogue tree: item_id, parent_id, type, catname
+//	item_languages.ndjson         localized names and texts: item_id, language, name, text, full_text
+//	vehicle_types.ndjson          dictionary of vehicle types: id, parent_id, catname, name
+//	item_vehicle_types.ndjson     item_id, vehicle_type_id, inherited
+//	attributes.ndjson             dictionary of specification attributes: id, parent_id, name, type, unit
+//	attribute_list_options.ndjson values of list attributes: id, attribute_id, parent_id, name
+//	specs.ndjson                  actual specification values: item_id, attribute_id, value
+//	pictures.ndjson               accepted pictures: id, identity, width, height, taken date, add_date,
+//	                              accept_datetime, copyrights
+//	picture_items.ndjson          items on pictures: picture_id, item_id, type, perspective_id
+//
+// Names of vehicle types, attributes and list options are translation keys.
+//
+// Incremental export contains items changed since the given time: updated item rows, items with revisions
+// of names and links, and items with recalculated specifications, together with their related rows
+// and pictures linked to them or accepted since then. Dictionaries are always exported in full.
+//
+// Deletions are reported as tombstones in deletions.ndjson and are applied before other files:
+// entity `item` (item_id) for deleted items and brands, `item_parent` (item_id, parent_id) for removed links,
+// `picture` (picture_id) for unaccepted or deleted pictures and `picture_item` (picture_id, item_id)
+// for items removed from pictures. Links of deleted item are covered by its tombstone.
+// Tombstones are kept for TombstoneRetention, older incremental export has to be replaced with a full one.
+package export
@@ -0,0 +1,629 @@
+package export
+
+import (
+	"bufio"
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/autowp/goautowp/schema"
+	"github.com/autowp/goautowp/util"
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	FormatName    = "autowp-catalogue"
+	FormatVersion = 1
+
+	metadataFilename = "metadata.json"
+	filePermissions  = 0o644
+	dirPermissions   = 0o755
+
+	// TombstoneRetention is how long deletions are kept, incremental export since earlier time is incomplete.
+	TombstoneRetention = 180 * 24 * time.Hour
+)
+
+var (
+	itemTypeNames = map[schema.ItemTableItemTypeID]string{
+		schema.ItemTableItemTypeIDVehicle:   "vehicle",
+		schema.ItemTableItemTypeIDEngine:    "engine",
+		schema.ItemTableItemTypeIDCategory:  "category",
+		schema.ItemTableItemTypeIDTwins:     "twins",
+		schema.ItemTableItemTypeIDBrand:     "brand",
+		schema.ItemTableItemTypeIDFactory:   "factory",
+		schema.ItemTableItemTypeIDMuseum:    "museum",
+		schema.ItemTableItemTypeIDPerson:    "person",
+		schema.ItemTableItemTypeIDCopyright: "copyright",
+	}
+	itemParentTypeNames = map[schema.ItemParentType]string{
+		schema.ItemParentTypeDefault: "default",
+		schema.ItemParentTypeTuning:  "tuning",
+		schema.ItemParentTypeSport:   "sport",
+		schema.ItemParentTypeDesign:  "design",
+	}
+	pictureItemTypeNames = map[schema.PictureItemType]string{
+		schema.PictureItemTypeContent:    "content",
+		schema.PictureItemTypeAuthor:     "author",
+		schema.PictureItemTypeCopyrights: "copyrights",
+	}
+	attributeTypeNames = map[schema.AttrsAttributeTypeID]string{
+		schema.AttrsAttributeTypeIDString:  "string",
+		schema.AttrsAttributeTypeIDInteger: "integer",
+		schema.AttrsAttributeTypeIDFloat:   "float",
+		schema.AttrsAttributeTypeIDText:    "text",
+		schema.AttrsAttributeTypeIDBoolean: "boolean",
+		schema.AttrsAttributeTypeIDList:    "list",
+		schema.AttrsAttributeTypeIDTree:    "tree",
+	}
+)
+
+type License struct {
+	Name        string `json:"name"`
+	URL         string `json:"url"`
+	Attribution string `json:"attribution"`
+}
+
+type FileInfo struct {
+	Name    string `json:"name"`
+	Records int64  `json:"records"`
+}
+
+type Metadata struct {
+	Format      string     `json:"format"`
+	Version     int        `json:"version"`
+	GeneratedAt time.Time  `json:"generated_at"`
+	Since       *time.Time `json:"since"`
+	License     License    `json:"license"`
+	Files       []FileInfo `json:"files"`
+}
+
+type Brand struct {
+	ID        int64     `db:"id"         json:"id"`
+	Catname   *string   `db:"catname"    json:"catname"`
+	Name      string    `db:"name"       json:"name"`
+	FullName  *string   `db:"full_name"  json:"full_name"`
+	UpdatedAt time.Time `db:"updated_at" json:"updated_at"`
+}
+
+type Item struct {
+	ID                     int64                      `db:"id"                        json:"id"`
+	ItemTypeID             schema.ItemTableItemTypeID `db:"item_type_id"              json:"-"`
+	Type                   string                     `db:"-"                         json:"type"`
+	Catname                *string                    `db:"catname"                   json:"catname"`
+	Name                   string                     `db:"name"                      json:"name"`
+	Body                   string                     `db:"body"                      json:"body"`
+	BeginYear              *int32                     `db:"begin_year"                json:"begin_year"`
+	BeginMonth             *int16                     `db:"begin_month"               json:"begin_month"`
+	EndYear                *int32                     `db:"end_year"                  json:"end_year"`
+	EndMonth               *int16                     `db:"end_month"                 json:"end_month"`
+	Today                  *bool                      `db:"today"                     json:"today"`
+	BeginModelYear         *int32                     `db:"begin_model_year"          json:"begin_model_year"`
+	BeginModelYearFraction *string                    `db:"begin_model_year_fraction" json:"begin_model_year_fraction"`
+	EndModelYear           *int32                     `db:"end_model_year"            json:"end_model_year"`
+	EndModelYearFraction   *string                    `db:"end_model_year_fraction"   json:"end_model_year_fraction"`
+	IsConcept              bool                       `db:"is_concept"                json:"is_concept"`
+	IsGroup                bool                       `db:"is_group"                  json:"is_group"`
+	Produced               *int32                     `db:"produced"                  json:"produced"`
+	ProducedExactly        bool                       `db:"produced_exactly"          json:"produced_exactly"`
+	EngineItemID           *int64                     `db:"engine_item_id"            json:"engine_item_id"`
+	AddDatetime            *time.Time                 `db:"add_datetime"              json:"add_datetime"`
+	UpdatedAt              time.Time                  `db:"updated_at"                json:"updated_at"`
+}
+
+type ItemParent struct {
+	ItemID   int64                 `db:"item_id"   json:"item_id"`
+	ParentID int64                 `db:"parent_id" json:"parent_id"`
+	TypeID   schema.ItemParentType `db:"type"      json:"-"`
+	Type     string                `db:"-"         json:"type"`
+	Catname  string                `db:"catname"   json:"catname"`
+}
+
+type ItemLanguage struct {
+	ItemID   int64   `db:"item_id"   json:"item_id"`
+	Language string  `db:"language"  json:"language"`
+	Name     *string `db:"name"      json:"name"`
+	Text     *string `db:"text"      json:"text"`
+	FullText *string `db:"full_text" json:"full_text"`
+}
+
+type VehicleType struct {
+	ID       int64  `db:"id"        json:"id"`
+	ParentID *int64 `db:"parent_id" json:"parent_id"`
+	Catname  string `db:"catname"   json:"catname"`
+	Name     string `db:"name"      json:"name"`
+}
+
+type ItemVehicleType struct {
+	ItemID        int64 `db:"item_id"         json:"item_id"`
+	VehicleTypeID int64 `db:"vehicle_type_id" json:"vehicle_type_id"`
+	Inherited     bool  `db:"inherited"       json:"inherited"`
+}
+
+type Attribute struct {
+	ID       int64                        `db:"id"        json:"id"`
+	ParentID *int64                       `db:"parent_id" json:"parent_id"`
+	Name     string                       `db:"name"      json:"name"`
+	TypeID   *schema.AttrsAttributeTypeID `db:"type_id"   json:"-"`
+	Type     *string                      `db:"-"         json:"type"`
+	Unit     *string                      `db:"unit"      json:"unit"`
+}
+
+type AttributeListOption struct {
+	ID          int64  `db:"id"           json:"id"`
+	AttributeID int64  `db:"attribute_id" json:"attribute_id"`
+	ParentID    *int64 `db:"parent_id"    json:"parent_id"`
+	Name        string `db:"name"         json:"name"`
+}
+
+// Spec is an actual value of attribute, value of list attribute is an id of list option.
+type Spec[T any] struct {
+	ItemID      int64 `db:"item_id"      json:"item_id"`
+	AttributeID int64 `db:"attribute_id" json:"attribute_id"`
+	Value       *T    `db:"value"        json:"value"`
+}
+
+type Picture struct {
+	ID             int64      `db:"id"              json:"id"`
+	Identity       string     `db:"identity"        json:"identity"`
+	Width          uint16     `db:"width"           json:"width"`
+	Height         uint16     `db:"height"          json:"height"`
+	TakenYear      *int16     `db:"taken_year"      json:"taken_year"`
+	TakenMonth     *uint8     `db:"taken_month"     json:"taken_month"`
+	TakenDay       *uint8     `db:"taken_day"       json:"taken_day"`
+	AddDate        time.Time  `db:"add_date"        json:"add_date"`
+	AcceptDatetime *time.Time `db:"accept_datetime" json:"accept_datetime"`
+	Copyrights     *string    `db:"copyrights"      json:"copyrights"`
+}
+
+type PictureItem struct {
+	PictureID     int64                  `db:"picture_id"     json:"picture_id"`
+	ItemID        int64                  `db:"item_id"        json:"item_id"`
+	TypeID        schema.PictureItemType `db:"type"           json:"-"`
+	Type          string                 `db:"-"              json:"type"`
+	PerspectiveID *int64                 `db:"perspective_id" json:"perspective_id"`
+}
+
+// Deletion is a tombstone of record removed from the data set, keys not applicable to entity are null.
+type Deletion struct {
+	Entity    string    `db:"entity"     json:"entity"`
+	ItemID    *int64    `db:"item_id"    json:"item_id"`
+	ParentID  *int64    `db:"parent_id"  json:"parent_id"`
+	PictureID *int64    `db:"picture_id" json:"picture_id"`
+	DeletedAt time.Time `db:"deleted_at" json:"deleted_at"`
+}
+
+// Exporter writes catalogue to a directory of newline-delimited JSON files.
+type Exporter struct {
+	db      *goqu.Database
+	license License
+}
+
+func NewExporter(db *goqu.Database, license License) *Exporter {
+	return &Exporter{
+		db:      db,
+		license: license,
+	}
+}
+
+// Export writes full data set to dir, or only items changed after since when it is not zero.
+func (s *Exporter) Export(ctx context.Context, dir string, since time.Time) (*Metadata, error) {
+	err := os.MkdirAll(dir, dirPermissions)
+	if err != nil {
+		return nil, err
+	}
+
+	metadata := Metadata{
+		Format:      FormatName,
+		Version:     FormatVersion,
+		GeneratedAt: time.Now().UTC(),
+		License:     s.license,
+	}
+
+	// every file is read from the same snapshot, so links, specs and pictures match exported items
+	tx, err := s.db.BeginTx(ctx, &sql.TxOptions{Isolation: sql.LevelRepeatableRead, ReadOnly: true})
+	if err != nil {
+		return nil, err
+	}
+
+	defer func() {
+		err := tx.Rollback()
+		if err != nil && !errors.Is(err, sql.ErrTxDone) {
+			logrus.Errorf("export: failed to rollback snapshot: %s", err.Error())
+		}
+	}()
+
+	var (
+		itemFilter    exp.Expression = goqu.L("1")
+		pictureFilter exp.Expression = goqu.L("1")
+	)
+
+	if !since.IsZero() {
+		since = since.UTC()
+		metadata.Since = &since
+
+		changed := s.changedItems(tx, since)
+		itemFilter = goqu.I("item_id").In(changed)
+		pictureFilter = goqu.Or(
+			schema.PictureTableAcceptDatetimeCol.Gte(since),
+			schema.PictureTableIDCol.In(
+				tx.Select(schema.PictureItemTablePictureIDCol).
+					From(schema.PictureItemTable).
+					Where(schema.PictureItemTableItemIDCol.In(changed)),
+			),
+		)
+	}
+
+	files := []struct {
+		name  string
+		write func(encoder *json.Encoder) (int64, error)
+	}{
+		{"deletions.ndjson", func(encoder *json.Encoder) (int64, error) {
+			return s.writeDeletions(ctx, tx, encoder, since)
+		}},
+		{"brands.ndjson", func(encoder *json.Encoder) (int64, error) {
+			return s.writeBrands(ctx, tx, encoder, since)
+		}},
+		{"items.ndjson", func(encoder *json.Encoder) (int64, error) {
+			return s.writeItems(ctx, tx, encoder, since)
+		}},
+		{"item_parents.ndjson", func(encoder *json.Encoder) (int64, error) {
+			return s.writeItemParents(ctx, tx, encoder, itemFilter)
+		}},
+		{"item_languages.ndjson", func(encoder *json.Encoder) (int64, error) {
+			return s.writeItemLanguages(ctx, tx, encoder, itemFilter)
+		}},
+		{"vehicle_types.ndjson", func(encoder *json.Encoder) (int64, error) {
+			return s.writeVehicleTypes(ctx, tx, encoder)
+		}},
+		{"item_vehicle_types.ndjson", func(encoder *json.Encoder) (int64, error) {
+			return s.writeItemVehicleTypes(ctx, tx, encoder, itemFilter)
+		}},
+		{"attributes.ndjson", func(encoder *json.Encoder) (int64, error) {
+			return s.writeAttributes(ctx, tx, encoder)
+		}},
+		{"attribute_list_options.ndjson", func(encoder *json.Encoder) (int64, error) {
+			return s.writeAttributeListOptions(ctx, tx, encoder)
+		}},
+		{"specs.ndjson", func(encoder *json.Encoder) (int64, error) {
+			return s.writeSpecs(ctx, tx, encoder, itemFilter)
+		}},
+		{"pictures.ndjson", func(encoder *json.Encoder) (int64, error) {
+			return s.writePictures(ctx, tx, encoder, pictureFilter)
+		}},
+		{"picture_items.ndjson", func(encoder *json.Encoder) (int64, error) {
+			return s.writePictureItems(ctx, tx, encoder, pictureFilter)
+		}},
+	}
+
+	for _, file := range files {
+		count, err := writeFile(filepath.Join(dir, file.name), file.write)
+		if err != nil {
+			return nil, err
+		}
+
+		metadata.Files = append(metadata.Files, FileInfo{Name: file.name, Records: count})
+	}
+
+	err = tx.Commit()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = writeFile(filepath.Join(dir, metadataFilename), func(encoder *json.Encoder) (int64, error) {
+		encoder.SetIndent("", "  ")
+
+		return 1, encoder.Encode(metadata)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &metadata, nil
+}
+
+// changedItems selects ids of items updated, edited through revisions or with specs recalculated since.
+func (s *Exporter) changedItems(tx *goqu.TxDatabase, since time.Time) *goqu.SelectDataset {
+	return tx.Select(schema.ItemTableIDCol).
+		From(schema.ItemTable).
+		Where(schema.ItemTableUpdatedAtCol.Gte(since)).
+		Union(
+			tx.Select(schema.ItemRevisionTableItemIDCol).
+				From(schema.ItemRevisionTable).
+				Where(schema.ItemRevisionTableCreatedAtCol.Gte(since)),
+		).
+		Union(
+			tx.Select(schema.AttrsValuesTableItemIDCol).
+				From(schema.AttrsValuesTable).
+				Where(schema.AttrsValuesTableUpdateDateCole.Gte(since)),
+		)
+}
+
+// CleanupTombstones deletes tombstones older than TombstoneRetention.
+func (s *Exporter) CleanupTombstones(ctx context.Context) (int64, error) {
+	res, err := s.db.Delete(schema.ExportTombstoneTable).
+		Where(schema.ExportTombstoneTableDeletedAtCol.Lt(time.Now().Add(-TombstoneRetention))).
+		Executor().ExecContext(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	return res.RowsAffected()
+}
+
+func writeFile(path string, write func(encoder *json.Encoder) (int64, error)) (int64, error) {
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, filePermissions)
+	if err != nil {
+		return 0, err
+	}
+	defer util.Close(file)
+
+	writer := bufio.NewWriter(file)
+	encoder := json.NewEncoder(writer)
+	encoder.SetEscapeHTML(false)
+
+	count, err := write(encoder)
+	if err != nil {
+		return 0, err
+	}
+
+	return count, writer.Flush()
+}
+
+// writeRows streams rows of query to encoder one by one, prepare fills fields computed from scanned ones.
+func writeRows[T any](
+	ctx context.Context, encoder *json.Encoder, sqSelect *goqu.SelectDataset, prepare func(row *T),
+) (int64, error) {
+	scanner, err := sqSelect.Executor().ScannerContext(ctx)
+	if err != nil {
+		return 0, err
+	}
+	defer util.Close(scanner)
+
+	var count int64
+
+	for scanner.Next() {
+		var row T
+
+		err = scanner.ScanStruct(&row)
+		if err != nil {
+			return 0, err
+		}
+
+		if prepare != nil {
+			prepare(&row)
+		}
+
+		err = encoder.Encode(row)
+		if err != nil {
+			return 0, err
+		}
+
+		count++
+	}
+
+	return count, scanner.Err()
+}
+
+func (s *Exporter) itemsSelect(tx *goqu.TxDatabase, since time.Time) *goqu.SelectDataset {
+	sqSelect := tx.From(schema.ItemTable).Order(schema.ItemTableIDCol.Asc())
+
+	if !since.IsZero() {
+		sqSelect = sqSelect.Where(schema.ItemTableIDCol.In(s.changedItems(tx, since)))
+	}
+
+	return sqSelect
+}
+
+// writeDeletions writes tombstones of incremental export, full export has nothing to delete.
+func (s *Exporter) writeDeletions(
+	ctx context.Context, tx *goqu.TxDatabase, encoder *json.Encoder, since time.Time,
+) (int64, error) {
+	if since.IsZero() {
+		return 0, nil
+	}
+
+	return writeRows[Deletion](ctx, encoder, tx.Select(
+		schema.ExportTombstoneTableEntityCol, schema.ExportTombstoneTableItemIDCol,
+		schema.ExportTombstoneTableParentIDCol, schema.ExportTombstoneTablePictureIDCol,
+		schema.ExportTombstoneTableDeletedAtCol,
+	).
+		From(schema.ExportTombstoneTable).
+		Where(schema.ExportTombstoneTableDeletedAtCol.Gte(since)).
+		Order(schema.ExportTombstoneTableIDCol.Asc()), nil)
+}
+
+func (s *Exporter) writeBrands(
+	ctx context.Context, tx *goqu.TxDatabase, encoder *json.Encoder, since time.Time,
+) (int64, error) {
+	return writeRows[Brand](ctx, encoder, s.itemsSelect(tx, since).Select(
+		schema.ItemTableIDCol, schema.ItemTableCatnameCol, schema.ItemTableNameCol, schema.ItemTableFullNameCol,
+		schema.ItemTableUpdatedAtCol,
+	).Where(schema.ItemTableItemTypeIDCol.Eq(schema.ItemTableItemTypeIDBrand)), nil)
+}
+
+func (s *Exporter) writeItems(
+	ctx context.Context, tx *goqu.TxDatabase, encoder *json.Encoder, since time.Time,
+) (int64, error) {
+	return writeRows(ctx, encoder, s.itemsSelect(tx, since).Select(
+		schema.ItemTableIDCol, schema.ItemTableItemTypeIDCol, schema.ItemTableCatnameCol, schema.ItemTableNameCol,
+		schema.ItemTableBodyCol, schema.ItemTableBeginYearCol, schema.ItemTableBeginMonthCol,
+		schema.ItemTableEndYearCol, schema.ItemTableEndMonthCol, schema.ItemTableTodayCol,
+		schema.ItemTableBeginModelYearCol, schema.ItemTableBeginModelYearFractionCol,
+		schema.ItemTableEndModelYearCol, schema.ItemTableEndModelYearFractionCol,
+		schema.ItemTableIsConceptCol, schema.ItemTableIsGroupCol, schema.ItemTableProducedCol,
+		schema.ItemTableProducedExactlyCol, schema.ItemTableEngineItemIDCol, schema.ItemTableAddDatetimeCol,
+		schema.ItemTableUpdatedAtCol,
+	).Where(schema.ItemTableItemTypeIDCol.Neq(schema.ItemTableItemTypeIDBrand)), func(row *Item) {
+		row.Type = itemTypeNames[row.ItemTypeID]
+	})
+}
+
+func (s *Exporter) writeItemParents(
+	ctx context.Context, tx *goqu.TxDatabase, encoder *json.Encoder, itemFilter exp.Expression,
+) (int64, error) {
+	return writeRows(ctx, encoder, tx.Select(
+		schema.ItemParentTableItemIDCol, schema.ItemParentTableParentIDCol, schema.ItemParentTableTypeCol,
+		schema.ItemParentTableCatnameCol,
+	).
+		From(schema.ItemParentTable).
+		Where(itemFilter).
+		Order(schema.ItemParentTableItemIDCol.Asc(), schema.ItemParentTableParentIDCol.Asc()),
+		func(row *ItemParent) {
+			row.Type = itemParentTypeNames[row.TypeID]
+		},
+	)
+}
+
+func (s *Exporter) writeItemLanguages(
+	ctx context.Context, tx *goqu.TxDatabase, encoder *json.Encoder, itemFilter exp.Expression,
+) (int64, error) {
+	textTable := schema.TextstorageTextTable.As("text")
+	fullTextTable := schema.TextstorageTextTable.As("full_text")
+
+	return writeRows[ItemLanguage](ctx, encoder, tx.Select(
+		schema.ItemLanguageTableItemIDCol, schema.ItemLanguageTableLanguageCol, schema.ItemLanguageTableNameCol,
+		textTable.Col(schema.TextstorageTextTableTextColName).As("text"),
+		fullTextTable.Col(schema.TextstorageTextTableTextColName).As("full_text"),
+	).
+		From(schema.ItemLanguageTable).
+		LeftJoin(textTable, goqu.On(
+			schema.ItemLanguageTableTextIDCol.Eq(textTable.Col(schema.TextstorageTextTableIDColName)),
+		)).
+		LeftJoin(fullTextTable, goqu.On(
+			schema.ItemLanguageTableFullTextIDCol.Eq(fullTextTable.Col(schema.TextstorageTextTableIDColName)),
+		)).
+		Where(itemFilter).
+		Order(schema.ItemLanguageTableItemIDCol.Asc(), schema.ItemLanguageTableLanguageCol.Asc()), nil)
+}
+
+func (s *Exporter) writeVehicleTypes(
+	ctx context.Context, tx *goqu.TxDatabase, encoder *json.Encoder,
+) (int64, error) {
+	return writeRows[VehicleType](ctx, encoder, tx.Select(
+		schema.VehicleTypeTableIDCol, schema.VehicleTypeTableParentIDCol, schema.VehicleTypeTableCatnameCol,
+		schema.VehicleTypeTableNameCol,
+	).
+		From(schema.VehicleTypeTable).
+		Order(schema.VehicleTypeTableIDCol.Asc()), nil)
+}
+
+func (s *Exporter) writeItemVehicleTypes(
+	ctx context.Context, tx *goqu.TxDatabase, encoder *json.Encoder, itemFilter exp.Expression,
+) (int64, error) {
+	return writeRows[ItemVehicleType](ctx, encoder, tx.Select(
+		schema.ItemVehicleTypeTableItemIDCol, schema.ItemVehicleTypeTableVehicleTypeIDCol,
+		schema.ItemVehicleTypeTableInheritedCol,
+	).
+		From(schema.ItemVehicleTypeTable).
+		Where(itemFilter).
+		Order(schema.ItemVehicleTypeTableItemIDCol.Asc(), schema.ItemVehicleTypeTableVehicleTypeIDCol.Asc()), nil)
+}
+
+func (s *Exporter) writeAttributes(
+	ctx context.Context, tx *goqu.TxDatabase, encoder *json.Encoder,
+) (int64, error) {
+	return writeRows(ctx, encoder, tx.Select(
+		schema.AttrsAttributesTableIDCol, schema.AttrsAttributesTableParentIDCol, schema.AttrsAttributesTableNameCol,
+		schema.AttrsAttributesTableTypeIDCol, schema.AttrsUnitsTableAbbrCol.As("unit"),
+	).
+		From(schema.AttrsAttributesTable).
+		LeftJoin(schema.AttrsUnitsTable, goqu.On(schema.AttrsAttributesTableUnitIDCol.Eq(schema.AttrsUnitsTableIDCol))).
+		Order(schema.AttrsAttributesTableIDCol.Asc()),
+		func(row *Attribute) {
+			if row.TypeID != nil {
+				if name, ok := attributeTypeNames[*row.TypeID]; ok {
+					row.Type = &name
+				}
+			}
+		},
+	)
+}
+
+func (s *Exporter) writeAttributeListOptions(
+	ctx context.Context, tx *goqu.TxDatabase, encoder *json.Encoder,
+) (int64, error) {
+	return writeRows[AttributeListOption](ctx, encoder, tx.Select(
+		schema.AttrsListOptionsTableIDCol, schema.AttrsListOptionsTableAttributeIDCol,
+		schema.AttrsListOptionsTableParentIDCol, schema.AttrsListOptionsTableNameCol,
+	).
+		From(schema.AttrsListOptionsTable).
+		Order(schema.AttrsListOptionsTableIDCol.Asc()), nil)
+}
+
+func (s *Exporter) writeSpecs(
+	ctx context.Context, tx *goqu.TxDatabase, encoder *json.Encoder, itemFilter exp.Expression,
+) (int64, error) {
+	specsSelect := func(table exp.IdentifierExpression) *goqu.SelectDataset {
+		return tx.Select(table.Col("item_id"), table.Col("attribute_id"), table.Col("value")).
+			From(table).
+			Where(itemFilter).
+			Order(table.Col("item_id").Asc(), table.Col("attribute_id").Asc())
+	}
+
+	total, err := writeRows[Spec[int32]](ctx, encoder, specsSelect(schema.AttrsValuesIntTable), nil)
+	if err != nil {
+		return 0, err
+	}
+
+	count, err := writeRows[Spec[float64]](ctx, encoder, specsSelect(schema.AttrsValuesFloatTable), nil)
+	if err != nil {
+		return 0, err
+	}
+
+	total += count
+
+	count, err = writeRows[Spec[string]](ctx, encoder, specsSelect(schema.AttrsValuesStringTable), nil)
+	if err != nil {
+		return 0, err
+	}
+
+	total += count
+
+	count, err = writeRows[Spec[int64]](ctx, encoder, specsSelect(schema.AttrsValuesListTable).
+		OrderAppend(schema.AttrsValuesListTableOrderingCol.Asc()), nil)
+	if err != nil {
+		return 0, err
+	}
+
+	return total + count, nil
+}
+
+func (s *Exporter) writePictures(
+	ctx context.Context, tx *goqu.TxDatabase, encoder *json.Encoder, pictureFilter exp.Expression,
+) (int64, error) {
+	return writeRows[Picture](ctx, encoder, tx.Select(
+		schema.PictureTableIDCol, schema.PictureTableIdentityCol, schema.PictureTableWidthCol,
+		schema.PictureTableHeightCol, schema.PictureTableTakenYearCol, schema.PictureTableTakenMonthCol,
+		schema.PictureTableTakenDayCol, schema.PictureTableAddDateCol, schema.PictureTableAcceptDatetimeCol,
+		schema.TextstorageTextTableTextCol.As("copyrights"),
+	).
+		From(schema.PictureTable).
+		LeftJoin(schema.TextstorageTextTable, goqu.On(
+			schema.PictureTableCopyrightsTextIDCol.Eq(schema.TextstorageTextTableIDCol),
+		)).
+		Where(schema.PictureTableStatusCol.Eq(schema.PictureStatusAccepted), pictureFilter).
+		Order(schema.PictureTableIDCol.Asc()), nil)
+}
+
+func (s *Exporter) writePictureItems(
+	ctx context.Context, tx *goqu.TxDatabase, encoder *json.Encoder, pictureFilter exp.Expression,
+) (int64, error) {
+	return writeRows(ctx, encoder, tx.Select(
+		schema.PictureItemTablePictureIDCol, schema.PictureItemTableItemIDCol, schema.PictureItemTableTypeCol,
+		schema.PictureItemTablePerspectiveIDCol,
+	).
+		From(schema.PictureItemTable).
+		Join(schema.PictureTable, goqu.On(schema.PictureItemTablePictureIDCol.Eq(schema.PictureTableIDCol))).
+		Where(schema.PictureTableStatusCol.Eq(schema.PictureStatusAccepted), pictureFilter).
+		Order(schema.PictureItemTablePictureIDCol.Asc(), schema.PictureItemTableItemIDCol.Asc()),
+		func(row *PictureItem) {
+			row.Type = pictureItemTypeNames[row.TypeID]
+		},
+	)
+}
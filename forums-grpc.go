@@ -4,7 +4,9 @@ import (
 	"context"
 
 	"github.com/autowp/goautowp/comments"
+	"github.com/autowp/goautowp/markup"
 	"github.com/autowp/goautowp/schema"
+	"github.com/autowp/goautowp/search"
 	"github.com/autowp/goautowp/users"
 	"github.com/autowp/goautowp/util"
 	"github.com/autowp/goautowp/validation"
@@ -23,6 +25,7 @@ type ForumsGRPCServer struct {
 	forums             *Forums
 	commentsRepository *comments.Repository
 	usersRepository    *users.Repository
+	searchIndexer      *search.Indexer
 }
 
 func NewForumsGRPCServer(
@@ -30,12 +33,14 @@ func NewForumsGRPCServer(
 	forums *Forums,
 	commentsRepository *comments.Repository,
 	usersRepository *users.Repository,
+	searchIndexer *search.Indexer,
 ) *ForumsGRPCServer {
 	return &ForumsGRPCServer{
 		auth:               auth,
 		forums:             forums,
 		commentsRepository: commentsRepository,
 		usersRepository:    usersRepository,
+		searchIndexer:      searchIndexer,
 	}
 }
 
@@ -105,12 +110,15 @@ func (s *ForumsGRPCServer) CreateTopic(
 		userCtx.UserID,
 		in.GetMessage(),
 		userCtx.IP.String(),
-		in.GetModeratorAttention(),
+		comments.Moderation{Attention: in.GetModeratorAttention()}, //nolint:exhaustruct
 	)
 	if err != nil {
 		return nil, err
 	}
 
+	// indexes first message too
+	s.searchIndexer.Refresh(ctx, search.KindForumTopic, topicID)
+
 	if in.GetSubscription() {
 		err = s.commentsRepository.Subscribe(
 			ctx,
@@ -274,6 +282,8 @@ func (s *ForumsGRPCServer) DeleteTopic(
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
+	s.searchIndexer.Refresh(ctx, search.KindForumTopic, in.GetId())
+
 	return &emptypb.Empty{}, nil
 }
 
@@ -429,6 +439,10 @@ func (s *ForumsGRPCServer) GetLastMessage(
 		Id:        msg.ID,
 		CreatedAt: timestamppb.New(msg.Datetime),
 		UserId:    userID,
+		Preview: util.GetTextPreview(markup.PlainText(msg.Message), util.TextPreviewOptions{
+			Maxlines:  1,
+			Maxlength: comments.CommentMessagePreviewLength,
+		}),
 	}, nil
 }
 
@@ -50,6 +50,7 @@ type CommentMessage struct {
 	ID       int64         `db:"id"`
 	Datetime time.Time     `db:"datetime"`
 	UserID   sql.NullInt64 `db:"author_id"`
+	Message  string        `db:"message"`
 }
 
 // Forums Main Object.
@@ -470,6 +471,7 @@ func (s *Forums) LastMessage(
 ) (*CommentMessage, error) {
 	sqSelect := s.db.Select(
 		schema.CommentMessageTableIDCol, schema.CommentMessageTableDatetimeCol, schema.CommentMessageTableAuthorIDCol,
+		schema.CommentMessageTableMessageCol,
 	).
 		From(schema.CommentMessageTable).
 		Join(
@@ -0,0 +1,94 @@
+package goautowp
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/autowp/goautowp/graphql"
+	"github.com/gin-gonic/gin"
+	"golang.org/x/text/language"
+)
+
+const graphQLMaxBodySize = 1024 * 1024
+
+type GraphQLREST struct {
+	schema *graphql.Schema
+}
+
+func NewGraphQLREST(catalogue *CatalogueGraphQL, maxDepth, maxComplexity int) *GraphQLREST {
+	return &GraphQLREST{
+		schema: catalogue.Schema(maxDepth, maxComplexity),
+	}
+}
+
+func (s *GraphQLREST) detectLanguage(ctx *gin.Context) string {
+	tags, _, err := language.ParseAcceptLanguage(ctx.Request.Header.Get("Accept-Language"))
+	if err != nil || len(tags) == 0 {
+		return "en"
+	}
+
+	base, _ := tags[0].Base()
+
+	return base.String()
+}
+
+func (s *GraphQLREST) execute(ctx *gin.Context, request graphql.Request) {
+	response := s.schema.Execute(withGraphQLLanguage(ctx, s.detectLanguage(ctx)), request)
+
+	ctx.JSON(http.StatusOK, response)
+}
+
+func (s *GraphQLREST) postAction(ctx *gin.Context) {
+	var request graphql.Request
+
+	decoder := json.NewDecoder(http.MaxBytesReader(ctx.Writer, ctx.Request.Body, graphQLMaxBodySize))
+	decoder.UseNumber()
+
+	err := decoder.Decode(&request)
+	if err != nil {
+		var maxBytesError *http.MaxBytesError
+		if errors.As(err, &maxBytesError) {
+			ctx.String(http.StatusRequestEntityTooLarge, err.Error())
+
+			return
+		}
+
+		ctx.String(http.StatusBadRequest, err.Error())
+
+		return
+	}
+
+	s.execute(ctx, request)
+}
+
+func (s *GraphQLREST) getAction(ctx *gin.Context) {
+	request := graphql.Request{
+		Query:         ctx.Query("query"),
+		OperationName: ctx.Query("operationName"),
+	}
+
+	if variables := ctx.Query("variables"); len(variables) > 0 {
+		decoder := json.NewDecoder(bytes.NewBufferString(variables))
+		decoder.UseNumber()
+
+		err := decoder.Decode(&request.Variables)
+		if err != nil {
+			ctx.String(http.StatusBadRequest, err.Error())
+
+			return
+		}
+	}
+
+	s.execute(ctx, request)
+}
+
+func (s *GraphQLREST) SetupRouter(router *gin.Engine) {
+	router.POST("/api/graphql", func(ctx *gin.Context) {
+		s.postAction(ctx)
+	})
+	router.GET("/api/graphql", func(ctx *gin.Context) {
+		s.getAction(ctx)
+	})
+}
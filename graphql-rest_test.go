@@ -0,0 +1,122 @@
+package goautowp
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/autowp/goautowp/config"
+	"github.com/autowp/goautowp/graphql"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestGraphQLItemChilds(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	random := rand.New(rand.NewSource(time.Now().UnixNano())) //nolint:gosec
+	cfg := config.LoadConfig(".")
+
+	kc := cnt.Keycloak()
+	adminToken, err := kc.Login(ctx, "frontend", "", cfg.Keycloak.Realm, adminUsername, adminPassword)
+	require.NoError(t, err)
+	require.NotNil(t, adminToken)
+
+	brandName := fmt.Sprintf("brand-%d", random.Int())
+	brandID := createItem(t, conn, cnt, &APIItem{
+		Name:       brandName,
+		IsGroup:    true,
+		ItemTypeId: ItemType_ITEM_TYPE_BRAND,
+		Catname:    fmt.Sprintf("brand-%d", random.Int()),
+	})
+
+	itemsClient := NewItemsClient(conn)
+
+	for _, name := range []string{"vehicle-a", "vehicle-b"} {
+		vehicleID := createItem(t, conn, cnt, &APIItem{
+			Name:       name,
+			ItemTypeId: ItemType_ITEM_TYPE_VEHICLE,
+			BeginYear:  2000,
+		})
+
+		_, err = itemsClient.CreateItemParent(
+			metadata.AppendToOutgoingContext(ctx, authorizationHeader, bearerPrefix+adminToken.AccessToken),
+			&ItemParent{ItemId: vehicleID, ParentId: brandID, Catname: name},
+		)
+		require.NoError(t, err)
+	}
+
+	graphQLREST, err := cnt.GraphQLREST()
+	require.NoError(t, err)
+
+	router := gin.New()
+	graphQLREST.SetupRouter(router)
+
+	body, err := json.Marshal(graphql.Request{
+		Query: `query ($id: ID!) {
+			item(id: $id) {
+				id
+				name
+				type
+				childs(limit: 10) { name beginYear type parents { id } }
+			}
+		}`,
+		Variables: map[string]any{"id": strconv.FormatInt(brandID, 10)},
+	})
+	require.NoError(t, err)
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, "/api/graphql", bytes.NewReader(body))
+	require.NoError(t, err)
+
+	resRecorder := httptest.NewRecorder()
+	router.ServeHTTP(resRecorder, req)
+	require.Equal(t, http.StatusOK, resRecorder.Code)
+
+	brandIDStr := strconv.FormatInt(brandID, 10)
+
+	require.JSONEq(t, `{"data": {"item": {
+		"id": "`+brandIDStr+`",
+		"name": "`+brandName+`",
+		"type": "BRAND",
+		"childs": [
+			{"name": "vehicle-a", "beginYear": 2000, "type": "VEHICLE", "parents": [{"id": "`+brandIDStr+`"}]},
+			{"name": "vehicle-b", "beginYear": 2000, "type": "VEHICLE", "parents": [{"id": "`+brandIDStr+`"}]}
+		]
+	}}}`, resRecorder.Body.String())
+}
+
+func TestGraphQLComplexityLimit(t *testing.T) {
+	t.Parallel()
+
+	graphQLREST, err := cnt.GraphQLREST()
+	require.NoError(t, err)
+
+	router := gin.New()
+	graphQLREST.SetupRouter(router)
+
+	req, err := http.NewRequestWithContext(t.Context(), http.MethodGet, "/api/graphql?query="+url.QueryEscape(
+		`{ items(limit: 100) { childs(limit: 100) { pictures(limit: 100) { id } } } }`,
+	), nil)
+	require.NoError(t, err)
+
+	resRecorder := httptest.NewRecorder()
+	router.ServeHTTP(resRecorder, req)
+	require.Equal(t, http.StatusOK, resRecorder.Code)
+
+	var response graphql.Response
+
+	err = json.Unmarshal(resRecorder.Body.Bytes(), &response)
+	require.NoError(t, err)
+	require.Nil(t, response.Data)
+	require.Len(t, response.Errors, 1)
+	require.Contains(t, response.Errors[0].Message, graphql.ErrComplexityExceeded.Error())
+}
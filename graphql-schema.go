@@ -0,0 +1,719 @@
+package goautowp
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"maps"
+	"slices"
+	"time"
+
+	"github.com/autowp/goautowp/attrs"
+	"github.com/autowp/goautowp/graphql"
+	"github.com/autowp/goautowp/image/storage"
+	"github.com/autowp/goautowp/items"
+	"github.com/autowp/goautowp/pictures"
+	"github.com/autowp/goautowp/query"
+	"github.com/autowp/goautowp/schema"
+	"github.com/autowp/goautowp/users"
+)
+
+const (
+	graphQLDefaultLimit       = 20
+	graphQLMaxLimit           = 100
+	graphQLDefaultImageFormat = "picture-thumb-medium"
+)
+
+var graphQLItemTypes = map[schema.ItemTableItemTypeID]string{
+	schema.ItemTableItemTypeIDVehicle:   "VEHICLE",
+	schema.ItemTableItemTypeIDEngine:    "ENGINE",
+	schema.ItemTableItemTypeIDCategory:  "CATEGORY",
+	schema.ItemTableItemTypeIDTwins:     "TWINS",
+	schema.ItemTableItemTypeIDBrand:     "BRAND",
+	schema.ItemTableItemTypeIDFactory:   "FACTORY",
+	schema.ItemTableItemTypeIDMuseum:    "MUSEUM",
+	schema.ItemTableItemTypeIDPerson:    "PERSON",
+	schema.ItemTableItemTypeIDCopyright: "COPYRIGHT",
+}
+
+// graphQLItemFields selects all columns exposed by Item type.
+var graphQLItemFields = &items.ItemFields{Meta: true}
+
+type graphQLLanguageKey struct{}
+
+type graphQLSpec struct {
+	AttributeID int64
+	Value       string
+}
+
+type graphQLImage struct {
+	Src    string
+	Width  int
+	Height int
+}
+
+// CatalogueGraphQL resolves public catalogue schema. Relations are loaded by batch resolvers,
+// one query for all objects of the same level of response.
+type CatalogueGraphQL struct {
+	itemsRepository    *items.Repository
+	picturesRepository *pictures.Repository
+	attrsRepository    *attrs.Repository
+	usersRepository    *users.Repository
+	imageStorage       *storage.Storage
+}
+
+func NewCatalogueGraphQL(
+	itemsRepository *items.Repository,
+	picturesRepository *pictures.Repository,
+	attrsRepository *attrs.Repository,
+	usersRepository *users.Repository,
+	imageStorage *storage.Storage,
+) *CatalogueGraphQL {
+	return &CatalogueGraphQL{
+		itemsRepository:    itemsRepository,
+		picturesRepository: picturesRepository,
+		attrsRepository:    attrsRepository,
+		usersRepository:    usersRepository,
+		imageStorage:       imageStorage,
+	}
+}
+
+func withGraphQLLanguage(ctx context.Context, lang string) context.Context {
+	return context.WithValue(ctx, graphQLLanguageKey{}, lang)
+}
+
+func graphQLLanguage(ctx context.Context) string {
+	lang, ok := ctx.Value(graphQLLanguageKey{}).(string)
+	if !ok {
+		return "en"
+	}
+
+	return lang
+}
+
+// graphQLLimit returns limit argument clamped to allowed range.
+func graphQLLimit(args graphql.Args) uint32 {
+	limit, ok := args.Int("limit")
+	if !ok || limit <= 0 {
+		return graphQLDefaultLimit
+	}
+
+	return uint32(min(limit, graphQLMaxLimit)) //nolint: gosec
+}
+
+func graphQLPage(args graphql.Args) uint32 {
+	page, ok := args.Int("page")
+	if !ok || page <= 0 {
+		return 1
+	}
+
+	return uint32(page) //nolint: gosec
+}
+
+// graphQLListComplexity counts every object of list, assuming limit objects are returned.
+func graphQLListComplexity(args graphql.Args, childComplexity int) int {
+	return 1 + int(graphQLLimit(args))*childComplexity
+}
+
+func graphQLListArgs() map[string]*graphql.Argument {
+	return map[string]*graphql.Argument{
+		"limit": {Type: graphql.Int, Default: int64(graphQLDefaultLimit)},
+		"page":  {Type: graphql.Int, Default: int64(1)},
+	}
+}
+
+func graphQLField[T any](fieldType graphql.Type, getter func(row T) any) *graphql.Field {
+	return &graphql.Field{
+		Type: fieldType,
+		Resolve: func(_ context.Context, source any, _ graphql.Args) (any, error) {
+			return getter(source.(T)), nil //nolint: forcetypeassert
+		},
+	}
+}
+
+func nullInt32(value sql.NullInt32) any {
+	if !value.Valid {
+		return nil
+	}
+
+	return value.Int32
+}
+
+func nullInt16(value sql.NullInt16) any {
+	if !value.Valid {
+		return nil
+	}
+
+	return value.Int16
+}
+
+func nullString(value sql.NullString) any {
+	if !value.Valid {
+		return nil
+	}
+
+	return value.String
+}
+
+func nullTime(value sql.NullTime) any {
+	if !value.Valid {
+		return nil
+	}
+
+	return value.Time.Format(time.RFC3339)
+}
+
+func sourceIDs[T any](sources []any, id func(row T) int64) []int64 {
+	ids := make([]int64, 0, len(sources))
+
+	for _, source := range sources {
+		ids = append(ids, id(source.(T))) //nolint: forcetypeassert
+	}
+
+	return ids
+}
+
+// Schema builds schema with given limits.
+func (s *CatalogueGraphQL) Schema(maxDepth, maxComplexity int) *graphql.Schema {
+	itemType := &graphql.Object{Name: "Item"}
+	pictureType := &graphql.Object{Name: "Picture"}
+	userType := &graphql.Object{Name: "User"}
+	itemTypeEnum := &graphql.Enum{Name: "ItemType"}
+
+	for _, typeID := range slices.Sorted(maps.Keys(graphQLItemTypes)) {
+		itemTypeEnum.Values = append(itemTypeEnum.Values, graphQLItemTypes[typeID])
+	}
+
+	unitType := &graphql.Object{Name: "Unit", Fields: map[string]*graphql.Field{
+		"id": graphQLField(graphql.NewNonNull(graphql.ID), func(row *schema.AttrsUnitRow) any { return row.ID }),
+		"name": graphQLField(graphql.NewNonNull(graphql.String), func(row *schema.AttrsUnitRow) any {
+			return row.Name
+		}),
+		"abbr": graphQLField(graphql.NewNonNull(graphql.String), func(row *schema.AttrsUnitRow) any {
+			return row.Abbr
+		}),
+	}}
+
+	attributeType := &graphql.Object{Name: "Attribute", Fields: map[string]*graphql.Field{
+		"id": graphQLField(graphql.NewNonNull(graphql.ID), func(row *schema.AttrsAttributeRow) any { return row.ID }),
+		"name": graphQLField(graphql.NewNonNull(graphql.String), func(row *schema.AttrsAttributeRow) any {
+			return row.Name
+		}),
+		"unit": {
+			Type: unitType,
+			Resolve: func(ctx context.Context, source any, _ graphql.Args) (any, error) {
+				row := source.(*schema.AttrsAttributeRow) //nolint: forcetypeassert
+				if !row.UnitID.Valid {
+					return nil, nil //nolint: nilnil
+				}
+
+				return s.attrsRepository.Unit(ctx, row.UnitID.Int64)
+			},
+		},
+	}}
+
+	specType := &graphql.Object{Name: "Spec", Fields: map[string]*graphql.Field{
+		"attribute": {
+			Type: graphql.NewNonNull(attributeType),
+			Resolve: func(ctx context.Context, source any, _ graphql.Args) (any, error) {
+				return s.attrsRepository.Attribute(ctx, source.(graphQLSpec).AttributeID) //nolint: forcetypeassert
+			},
+		},
+		"value": graphQLField(graphql.NewNonNull(graphql.String), func(row graphQLSpec) any { return row.Value }),
+	}}
+
+	imageType := &graphql.Object{Name: "Image", Fields: map[string]*graphql.Field{
+		"src":    graphQLField(graphql.NewNonNull(graphql.String), func(row *graphQLImage) any { return row.Src }),
+		"width":  graphQLField(graphql.NewNonNull(graphql.Int), func(row *graphQLImage) any { return row.Width }),
+		"height": graphQLField(graphql.NewNonNull(graphql.Int), func(row *graphQLImage) any { return row.Height }),
+	}}
+
+	itemType.Fields = map[string]*graphql.Field{
+		"id": graphQLField(graphql.NewNonNull(graphql.ID), func(row *items.Item) any { return row.ID }),
+		"type": graphQLField(graphql.NewNonNull(itemTypeEnum), func(row *items.Item) any {
+			return graphQLItemTypes[row.ItemTypeID]
+		}),
+		"catname": graphQLField(graphql.String, func(row *items.Item) any {
+			return nullString(row.Catname)
+		}),
+		"name":           graphQLField(graphql.NewNonNull(graphql.String), func(row *items.Item) any { return row.NameOnly }),
+		"fullName":       graphQLField(graphql.String, func(row *items.Item) any { return nullString(row.ItemRow.FullName) }),
+		"body":           graphQLField(graphql.NewNonNull(graphql.String), func(row *items.Item) any { return row.Body }),
+		"beginYear":      graphQLField(graphql.Int, func(row *items.Item) any { return nullInt32(row.BeginYear) }),
+		"beginMonth":     graphQLField(graphql.Int, func(row *items.Item) any { return nullInt16(row.BeginMonth) }),
+		"endYear":        graphQLField(graphql.Int, func(row *items.Item) any { return nullInt32(row.EndYear) }),
+		"endMonth":       graphQLField(graphql.Int, func(row *items.Item) any { return nullInt16(row.EndMonth) }),
+		"beginModelYear": graphQLField(graphql.Int, func(row *items.Item) any { return nullInt32(row.BeginModelYear) }),
+		"endModelYear":   graphQLField(graphql.Int, func(row *items.Item) any { return nullInt32(row.EndModelYear) }),
+		"produced":       graphQLField(graphql.Int, func(row *items.Item) any { return nullInt32(row.Produced) }),
+		"isConcept": graphQLField(graphql.NewNonNull(graphql.Boolean), func(row *items.Item) any {
+			return row.IsConcept
+		}),
+		"isGroup": graphQLField(graphql.NewNonNull(graphql.Boolean), func(row *items.Item) any {
+			return row.IsGroup
+		}),
+		"today": graphQLField(graphql.Boolean, func(row *items.Item) any {
+			if !row.Today.Valid {
+				return nil
+			}
+
+			return row.Today.Bool
+		}),
+		"parents": {
+			Type:         graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(itemType))),
+			Args:         map[string]*graphql.Argument{"limit": {Type: graphql.Int, Default: int64(graphQLDefaultLimit)}},
+			BatchResolve: s.resolveItemParents,
+			Complexity:   graphQLListComplexity,
+		},
+		"childs": {
+			Type:         graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(itemType))),
+			Args:         map[string]*graphql.Argument{"limit": {Type: graphql.Int, Default: int64(graphQLDefaultLimit)}},
+			BatchResolve: s.resolveItemChilds,
+			Complexity:   graphQLListComplexity,
+		},
+		"pictures": {
+			Type:         graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(pictureType))),
+			Args:         map[string]*graphql.Argument{"limit": {Type: graphql.Int, Default: int64(graphQLDefaultLimit)}},
+			BatchResolve: s.resolveItemPictures,
+			Complexity:   graphQLListComplexity,
+		},
+		"specs": {
+			Type:         graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(specType))),
+			BatchResolve: s.resolveItemSpecs,
+			Complexity: func(_ graphql.Args, childComplexity int) int {
+				return 1 + graphQLMaxLimit*childComplexity
+			},
+		},
+	}
+
+	pictureType.Fields = map[string]*graphql.Field{
+		"id": graphQLField(graphql.NewNonNull(graphql.ID), func(row *schema.PictureRow) any { return row.ID }),
+		"identity": graphQLField(graphql.NewNonNull(graphql.String), func(row *schema.PictureRow) any {
+			return row.Identity
+		}),
+		"width":  graphQLField(graphql.NewNonNull(graphql.Int), func(row *schema.PictureRow) any { return row.Width }),
+		"height": graphQLField(graphql.NewNonNull(graphql.Int), func(row *schema.PictureRow) any { return row.Height }),
+		"addDate": graphQLField(graphql.NewNonNull(graphql.String), func(row *schema.PictureRow) any {
+			return row.AddDate.Format(time.RFC3339)
+		}),
+		"acceptDate": graphQLField(graphql.String, func(row *schema.PictureRow) any {
+			return nullTime(row.AcceptDatetime)
+		}),
+		"owner": {
+			Type:         userType,
+			BatchResolve: s.resolvePictureOwners,
+		},
+		"items": {
+			Type:         graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(itemType))),
+			Args:         map[string]*graphql.Argument{"limit": {Type: graphql.Int, Default: int64(graphQLDefaultLimit)}},
+			BatchResolve: s.resolvePictureItems,
+			Complexity:   graphQLListComplexity,
+		},
+		"image": {
+			Type: imageType,
+			Args: map[string]*graphql.Argument{
+				"format": {Type: graphql.String, Default: graphQLDefaultImageFormat},
+			},
+			BatchResolve: s.resolvePictureImages,
+		},
+	}
+
+	userType.Fields = map[string]*graphql.Field{
+		"id":   graphQLField(graphql.NewNonNull(graphql.ID), func(row *schema.UsersRow) any { return row.ID }),
+		"name": graphQLField(graphql.NewNonNull(graphql.String), func(row *schema.UsersRow) any { return row.Name }),
+		"identity": graphQLField(graphql.String, func(row *schema.UsersRow) any {
+			return row.Identity
+		}),
+		"deleted": graphQLField(graphql.NewNonNull(graphql.Boolean), func(row *schema.UsersRow) any {
+			return row.Deleted
+		}),
+	}
+
+	itemsArgs := graphQLListArgs()
+	itemsArgs["ids"] = &graphql.Argument{Type: graphql.NewList(graphql.NewNonNull(graphql.ID))}
+	itemsArgs["type"] = &graphql.Argument{Type: itemTypeEnum}
+	itemsArgs["name"] = &graphql.Argument{Type: graphql.String}
+	itemsArgs["catname"] = &graphql.Argument{Type: graphql.String}
+
+	picturesArgs := graphQLListArgs()
+	picturesArgs["itemId"] = &graphql.Argument{Type: graphql.ID}
+
+	return &graphql.Schema{
+		Query: &graphql.Object{Name: "Query", Fields: map[string]*graphql.Field{
+			"item": {
+				Type:    itemType,
+				Args:    map[string]*graphql.Argument{"id": {Type: graphql.NewNonNull(graphql.ID)}},
+				Resolve: s.resolveItem,
+			},
+			"items": {
+				Type:       graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(itemType))),
+				Args:       itemsArgs,
+				Resolve:    s.resolveItems,
+				Complexity: graphQLListComplexity,
+			},
+			"picture": {
+				Type: pictureType,
+				Args: map[string]*graphql.Argument{
+					"id":       {Type: graphql.ID},
+					"identity": {Type: graphql.String},
+				},
+				Resolve: s.resolvePicture,
+			},
+			"pictures": {
+				Type:       graphql.NewNonNull(graphql.NewList(graphql.NewNonNull(pictureType))),
+				Args:       picturesArgs,
+				Resolve:    s.resolvePictures,
+				Complexity: graphQLListComplexity,
+			},
+			"user": {
+				Type: userType,
+				Args: map[string]*graphql.Argument{
+					"id":       {Type: graphql.ID},
+					"identity": {Type: graphql.String},
+				},
+				Resolve: s.resolveUser,
+			},
+		}},
+		MaxDepth:      maxDepth,
+		MaxComplexity: maxComplexity,
+	}
+}
+
+func (s *CatalogueGraphQL) itemsByIDs(ctx context.Context, ids []int64) (map[int64]*items.Item, error) {
+	result := make(map[int64]*items.Item, len(ids))
+
+	if len(ids) == 0 {
+		return result, nil
+	}
+
+	rows, _, err := s.itemsRepository.List(ctx, &query.ItemListOptions{
+		ItemIDs:  ids,
+		Language: graphQLLanguage(ctx),
+	}, graphQLItemFields, items.OrderByNone, false)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		result[row.ID] = row
+	}
+
+	return result, nil
+}
+
+// itemsLists maps lists of ids to lists of items loaded with a single query.
+func (s *CatalogueGraphQL) itemsLists(ctx context.Context, lists [][]int64) ([]any, error) {
+	var ids []int64
+	for _, list := range lists {
+		ids = append(ids, list...)
+	}
+
+	rows, err := s.itemsByIDs(ctx, ids)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]any, len(lists))
+
+	for idx, list := range lists {
+		values := make([]*items.Item, 0, len(list))
+
+		for _, id := range list {
+			if row, ok := rows[id]; ok {
+				values = append(values, row)
+			}
+		}
+
+		result[idx] = values
+	}
+
+	return result, nil
+}
+
+func (s *CatalogueGraphQL) resolveItem(ctx context.Context, _ any, args graphql.Args) (any, error) {
+	id, _ := args.Int("id")
+
+	rows, err := s.itemsByIDs(ctx, []int64{id})
+	if err != nil {
+		return nil, err
+	}
+
+	return rows[id], nil
+}
+
+func (s *CatalogueGraphQL) resolveItems(ctx context.Context, _ any, args graphql.Args) (any, error) {
+	options := query.ItemListOptions{
+		ItemIDs:  args.IDs("ids"),
+		Language: graphQLLanguage(ctx),
+		Limit:    graphQLLimit(args),
+		Page:     graphQLPage(args),
+	}
+
+	if value, ok := args.String("type"); ok {
+		for typeID, name := range graphQLItemTypes {
+			if name == value {
+				options.TypeID = []schema.ItemTableItemTypeID{typeID}
+			}
+		}
+	}
+
+	if value, ok := args.String("name"); ok {
+		options.Name = value
+	}
+
+	if value, ok := args.String("catname"); ok {
+		options.Catname = value
+	}
+
+	rows, _, err := s.itemsRepository.List(ctx, &options, graphQLItemFields, items.OrderByIDAsc, false)
+
+	return rows, err
+}
+
+func (s *CatalogueGraphQL) resolveItemParents(ctx context.Context, sources []any, args graphql.Args) ([]any, error) {
+	ids := sourceIDs(sources, func(row *items.Item) int64 { return row.ID })
+
+	parentIDs, err := s.itemsRepository.ItemsParentIDs(ctx, ids, graphQLLimit(args))
+	if err != nil {
+		return nil, err
+	}
+
+	return s.itemsLists(ctx, linkedIDs(ids, parentIDs))
+}
+
+func (s *CatalogueGraphQL) resolveItemChilds(ctx context.Context, sources []any, args graphql.Args) ([]any, error) {
+	ids := sourceIDs(sources, func(row *items.Item) int64 { return row.ID })
+
+	childIDs, err := s.itemsRepository.ItemsChildIDs(ctx, ids, graphQLLimit(args))
+	if err != nil {
+		return nil, err
+	}
+
+	return s.itemsLists(ctx, linkedIDs(ids, childIDs))
+}
+
+// linkedIDs orders lists of linked ids by ids.
+func linkedIDs(ids []int64, links map[int64][]int64) [][]int64 {
+	result := make([][]int64, len(ids))
+	for idx, id := range ids {
+		result[idx] = links[id]
+	}
+
+	return result
+}
+
+func (s *CatalogueGraphQL) resolveItemPictures(ctx context.Context, sources []any, args graphql.Args) ([]any, error) {
+	ids := sourceIDs(sources, func(row *items.Item) int64 { return row.ID })
+
+	pictureIDs, err := s.picturesRepository.ItemsPictureIDs(ctx, ids, graphQLLimit(args))
+	if err != nil {
+		return nil, err
+	}
+
+	return s.picturesLists(ctx, linkedIDs(ids, pictureIDs))
+}
+
+func (s *CatalogueGraphQL) picturesLists(ctx context.Context, lists [][]int64) ([]any, error) {
+	var ids []int64
+	for _, list := range lists {
+		ids = append(ids, list...)
+	}
+
+	rows := make(map[int64]*schema.PictureRow, len(ids))
+
+	if len(ids) > 0 {
+		pictureRows, _, err := s.picturesRepository.Pictures(ctx, &query.PictureListOptions{
+			IDs:    ids,
+			Status: schema.PictureStatusAccepted,
+		}, nil, pictures.OrderByNone, false)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, row := range pictureRows {
+			rows[row.ID] = row
+		}
+	}
+
+	result := make([]any, len(lists))
+
+	for idx, list := range lists {
+		values := make([]*schema.PictureRow, 0, len(list))
+
+		for _, id := range list {
+			if row, ok := rows[id]; ok {
+				values = append(values, row)
+			}
+		}
+
+		result[idx] = values
+	}
+
+	return result, nil
+}
+
+func (s *CatalogueGraphQL) resolveItemSpecs(ctx context.Context, sources []any, _ graphql.Args) ([]any, error) {
+	ids := sourceIDs(sources, func(row *items.Item) int64 { return row.ID })
+
+	values, err := s.attrsRepository.ItemsActualValuesText(ctx, ids, graphQLLanguage(ctx))
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]any, len(ids))
+
+	for idx, id := range ids {
+		specs := make([]graphQLSpec, 0, len(values[id]))
+
+		for attributeID, value := range values[id] {
+			specs = append(specs, graphQLSpec{AttributeID: attributeID, Value: value})
+		}
+
+		slices.SortFunc(specs, func(a, b graphQLSpec) int {
+			return int(a.AttributeID - b.AttributeID)
+		})
+
+		result[idx] = specs
+	}
+
+	return result, nil
+}
+
+func (s *CatalogueGraphQL) resolvePicture(ctx context.Context, _ any, args graphql.Args) (any, error) {
+	options := query.PictureListOptions{Status: schema.PictureStatusAccepted}
+
+	if id, ok := args.Int("id"); ok {
+		options.ID = id
+	}
+
+	if identity, ok := args.String("identity"); ok {
+		options.Identity = identity
+	}
+
+	if options.ID == 0 && options.Identity == "" {
+		return nil, nil //nolint: nilnil
+	}
+
+	row, err := s.picturesRepository.Picture(ctx, &options, nil, pictures.OrderByNone)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil //nolint: nilnil
+	}
+
+	return row, err
+}
+
+func (s *CatalogueGraphQL) resolvePictures(ctx context.Context, _ any, args graphql.Args) (any, error) {
+	options := query.PictureListOptions{
+		Status: schema.PictureStatusAccepted,
+		Limit:  graphQLLimit(args),
+		Page:   graphQLPage(args),
+	}
+
+	if itemID, ok := args.Int("itemId"); ok {
+		options.PictureItem = &query.PictureItemListOptions{ItemID: itemID}
+	}
+
+	rows, _, err := s.picturesRepository.Pictures(ctx, &options, nil, pictures.OrderByAcceptDatetimeDesc, true)
+
+	return rows, err
+}
+
+func (s *CatalogueGraphQL) resolvePictureOwners(ctx context.Context, sources []any, _ graphql.Args) ([]any, error) {
+	ids := make([]int64, 0, len(sources))
+
+	for _, source := range sources {
+		if row := source.(*schema.PictureRow); row.OwnerID.Valid { //nolint: forcetypeassert
+			ids = append(ids, row.OwnerID.Int64)
+		}
+	}
+
+	rows := make(map[int64]*schema.UsersRow, len(ids))
+
+	if len(ids) > 0 {
+		userRows, _, err := s.usersRepository.Users(
+			ctx, &query.UserListOptions{IDs: ids}, users.UserFields{}, users.OrderByNone,
+		)
+		if err != nil {
+			return nil, err
+		}
+
+		for idx := range userRows {
+			rows[userRows[idx].ID] = &userRows[idx]
+		}
+	}
+
+	result := make([]any, len(sources))
+
+	for idx, source := range sources {
+		if row := source.(*schema.PictureRow); row.OwnerID.Valid { //nolint: forcetypeassert
+			result[idx] = rows[row.OwnerID.Int64]
+		}
+	}
+
+	return result, nil
+}
+
+func (s *CatalogueGraphQL) resolvePictureItems(ctx context.Context, sources []any, args graphql.Args) ([]any, error) {
+	ids := sourceIDs(sources, func(row *schema.PictureRow) int64 { return row.ID })
+
+	itemIDs, err := s.picturesRepository.PicturesItemIDs(ctx, ids, graphQLLimit(args))
+	if err != nil {
+		return nil, err
+	}
+
+	return s.itemsLists(ctx, linkedIDs(ids, itemIDs))
+}
+
+func (s *CatalogueGraphQL) resolvePictureImages(ctx context.Context, sources []any, args graphql.Args) ([]any, error) {
+	format, _ := args.String("format")
+	imageIDs := make([]int, 0, len(sources))
+
+	for _, source := range sources {
+		if row := source.(*schema.PictureRow); row.ImageID.Valid { //nolint: forcetypeassert
+			imageIDs = append(imageIDs, int(row.ImageID.Int64))
+		}
+	}
+
+	images, err := s.imageStorage.FormattedImages(ctx, imageIDs, format)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]any, len(sources))
+
+	for idx, source := range sources {
+		row := source.(*schema.PictureRow) //nolint: forcetypeassert
+		if !row.ImageID.Valid {
+			continue
+		}
+
+		if image, ok := images[int(row.ImageID.Int64)]; ok {
+			result[idx] = &graphQLImage{Src: image.Src(), Width: image.Width(), Height: image.Height()}
+		}
+	}
+
+	return result, nil
+}
+
+func (s *CatalogueGraphQL) resolveUser(ctx context.Context, _ any, args graphql.Args) (any, error) {
+	options := query.UserListOptions{}
+
+	if id, ok := args.Int("id"); ok {
+		options.ID = id
+	}
+
+	if identity, ok := args.String("identity"); ok {
+		options.Identity = identity
+	}
+
+	if options.ID == 0 && options.Identity == "" {
+		return nil, nil //nolint: nilnil
+	}
+
+	row, err := s.usersRepository.User(ctx, &options, users.UserFields{}, users.OrderByNone)
+	if errors.Is(err, users.ErrUserNotFound) {
+		return nil, nil //nolint: nilnil
+	}
+
+	return row, err
+}
@@ -0,0 +1,661 @@
+package graphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+)
+
+const typenameField = "__typename"
+
+var (
+	ErrValidation          = errors.New("validation error")
+	ErrComplexityExceeded  = errors.New("query complexity exceeds limit")
+	ErrDepthExceeded       = errors.New("query depth exceeds limit")
+	ErrOperationNotAllowed = errors.New("only queries are supported")
+	errNullValue           = errors.New("null value of non-null field")
+	errBatchResult         = errors.New("batch resolver returned wrong number of values")
+)
+
+// Schema executes queries against Query object. Zero limits disable checks.
+type Schema struct {
+	Query         *Object
+	MaxDepth      int
+	MaxComplexity int
+}
+
+type Request struct {
+	Query         string         `json:"query"`
+	OperationName string         `json:"operationName"`
+	Variables     map[string]any `json:"variables"`
+}
+
+type Error struct {
+	Message string `json:"message"`
+}
+
+// Response has null data when request failed. Errors of resolvers are not isolated to fields.
+type Response struct {
+	Data   any      `json:"data"`
+	Errors []*Error `json:"errors,omitempty"`
+}
+
+type executor struct {
+	schema    *Schema
+	document  *Document
+	variables map[string]any
+}
+
+type collectedField struct {
+	key    string
+	fields []*FieldSelection
+}
+
+// orderedMap keeps fields of response in order of selection.
+type orderedMap struct {
+	keys   []string
+	values map[string]any
+}
+
+func (s *orderedMap) set(key string, value any) {
+	if _, ok := s.values[key]; !ok {
+		s.keys = append(s.keys, key)
+	}
+
+	s.values[key] = value
+}
+
+func (s *orderedMap) MarshalJSON() ([]byte, error) {
+	var buf bytes.Buffer
+
+	buf.WriteByte('{')
+
+	for idx, key := range s.keys {
+		if idx > 0 {
+			buf.WriteByte(',')
+		}
+
+		keyJSON, err := json.Marshal(key)
+		if err != nil {
+			return nil, err
+		}
+
+		valueJSON, err := json.Marshal(s.values[key])
+		if err != nil {
+			return nil, err
+		}
+
+		buf.Write(keyJSON)
+		buf.WriteByte(':')
+		buf.Write(valueJSON)
+	}
+
+	buf.WriteByte('}')
+
+	return buf.Bytes(), nil
+}
+
+// Execute parses, validates and executes request.
+func (s *Schema) Execute(ctx context.Context, request Request) *Response {
+	data, err := s.execute(ctx, request)
+	if err != nil {
+		return &Response{Errors: []*Error{{Message: err.Error()}}}
+	}
+
+	return &Response{Data: data}
+}
+
+func (s *Schema) execute(ctx context.Context, request Request) (any, error) {
+	document, err := Parse(request.Query)
+	if err != nil {
+		return nil, err
+	}
+
+	operation, err := selectOperation(document, request.OperationName)
+	if err != nil {
+		return nil, err
+	}
+
+	if operation.Type != "query" {
+		return nil, ErrOperationNotAllowed
+	}
+
+	exec := &executor{schema: s, document: document, variables: make(map[string]any)}
+
+	for _, definition := range operation.Variables {
+		value, ok := request.Variables[definition.Name]
+		if !ok {
+			value = definition.Default
+		}
+
+		if value == nil && definition.NonNull {
+			return nil, fmt.Errorf("%w: variable `$%s` is required", ErrValidation, definition.Name)
+		}
+
+		if ok || value != nil {
+			exec.variables[definition.Name] = value
+		}
+	}
+
+	_, err = exec.complexity(s.Query, operation.SelectionSet, 1)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := exec.executeFields(ctx, s.Query, []any{nil}, operation.SelectionSet)
+	if err != nil {
+		return nil, err
+	}
+
+	return results[0], nil
+}
+
+func selectOperation(document *Document, name string) (*Operation, error) {
+	if name == "" {
+		if len(document.Operations) != 1 {
+			return nil, fmt.Errorf("%w: operation name is required", ErrValidation)
+		}
+
+		return document.Operations[0], nil
+	}
+
+	for _, operation := range document.Operations {
+		if operation.Name == name {
+			return operation, nil
+		}
+	}
+
+	return nil, fmt.Errorf("%w: unknown operation `%s`", ErrValidation, name)
+}
+
+// complexity validates selections and calculates their cost. Cost is compared with limit as soon as it grows,
+// so costs of large lists don't overflow.
+func (s *executor) complexity(objType *Object, selections []Selection, depth int) (int, error) {
+	if s.schema.MaxDepth > 0 && depth > s.schema.MaxDepth {
+		return 0, fmt.Errorf("%w: %d", ErrDepthExceeded, s.schema.MaxDepth)
+	}
+
+	collected, err := s.collectFields(objType, selections, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	total := 0
+
+	for _, group := range collected {
+		field := group.fields[0]
+		if field.Name == typenameField {
+			continue
+		}
+
+		definition, ok := objType.Fields[field.Name]
+		if !ok {
+			return 0, fmt.Errorf("%w: unknown field `%s` of `%s`", ErrValidation, field.Name, objType.Name)
+		}
+
+		args, err := s.coerceArgs(definition, field)
+		if err != nil {
+			return 0, fmt.Errorf("%s: %w", group.key, err)
+		}
+
+		subSelections := mergeSelectionSets(group.fields)
+		childComplexity := 0
+
+		if childType, ok := namedType(definition.Type).(*Object); ok {
+			if len(subSelections) == 0 {
+				return 0, fmt.Errorf("%w: selection of `%s` is required", ErrValidation, group.key)
+			}
+
+			childComplexity, err = s.complexity(childType, subSelections, depth+1)
+			if err != nil {
+				return 0, err
+			}
+		} else if len(subSelections) > 0 {
+			return 0, fmt.Errorf("%w: `%s` has no fields", ErrValidation, group.key)
+		}
+
+		cost := 1 + childComplexity
+		if definition.Complexity != nil {
+			cost = definition.Complexity(args, childComplexity)
+		}
+
+		total += cost
+
+		if s.schema.MaxComplexity > 0 && (cost < 0 || total < 0 || total > s.schema.MaxComplexity) {
+			return 0, fmt.Errorf("%w: more than %d", ErrComplexityExceeded, s.schema.MaxComplexity)
+		}
+	}
+
+	return total, nil
+}
+
+func (s *executor) executeFields(
+	ctx context.Context, objType *Object, sources []any, selections []Selection,
+) ([]*orderedMap, error) {
+	collected, err := s.collectFields(objType, selections, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	results := make([]*orderedMap, len(sources))
+	for idx := range results {
+		results[idx] = &orderedMap{values: make(map[string]any, len(collected))}
+	}
+
+	for _, group := range collected {
+		field := group.fields[0]
+
+		if field.Name == typenameField {
+			for _, result := range results {
+				result.set(group.key, objType.Name)
+			}
+
+			continue
+		}
+
+		definition := objType.Fields[field.Name]
+
+		args, err := s.coerceArgs(definition, field)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", group.key, err)
+		}
+
+		values, err := resolve(ctx, definition, field.Name, sources, args)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", group.key, err)
+		}
+
+		values, err = s.completeValues(ctx, definition.Type, values, mergeSelectionSets(group.fields))
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", group.key, err)
+		}
+
+		for idx, result := range results {
+			result.set(group.key, values[idx])
+		}
+	}
+
+	return results, nil
+}
+
+func resolve(ctx context.Context, definition *Field, name string, sources []any, args Args) ([]any, error) {
+	if definition.BatchResolve != nil {
+		values, err := definition.BatchResolve(ctx, sources, args)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(values) != len(sources) {
+			return nil, errBatchResult
+		}
+
+		return values, nil
+	}
+
+	values := make([]any, len(sources))
+
+	for idx, source := range sources {
+		if definition.Resolve == nil {
+			if source, ok := source.(map[string]any); ok {
+				values[idx] = source[name]
+			}
+
+			continue
+		}
+
+		value, err := definition.Resolve(ctx, source, args)
+		if err != nil {
+			return nil, err
+		}
+
+		values[idx] = value
+	}
+
+	return values, nil
+}
+
+// completeValues serializes values of all sources at once, so objects in lists are resolved together.
+func (s *executor) completeValues(
+	ctx context.Context, typ Type, values []any, selections []Selection,
+) ([]any, error) {
+	switch typed := typ.(type) {
+	case *NonNull:
+		result, err := s.completeValues(ctx, typed.OfType, values, selections)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, value := range result {
+			if value == nil {
+				return nil, errNullValue
+			}
+		}
+
+		return result, nil
+	case *List:
+		return s.completeLists(ctx, typed, values, selections)
+	case *Object:
+		return s.completeObjects(ctx, typed, values, selections)
+	case *Scalar:
+		return serialize(values, typed.Serialize)
+	case *Enum:
+		return serialize(values, typed.serialize)
+	}
+
+	return nil, fmt.Errorf("%w: unsupported type %s", ErrValidation, typ)
+}
+
+func (s *executor) completeLists(
+	ctx context.Context, typ *List, values []any, selections []Selection,
+) ([]any, error) {
+	var (
+		flat    []any
+		lengths = make([]int, len(values))
+	)
+
+	for idx, value := range values {
+		if value == nil {
+			lengths[idx] = -1
+
+			continue
+		}
+
+		ref := reflect.ValueOf(value)
+		if ref.Kind() != reflect.Slice {
+			return nil, fmt.Errorf("%w: list expected, got %T", ErrInvalidValue, value)
+		}
+
+		lengths[idx] = ref.Len()
+
+		for i := range ref.Len() {
+			flat = append(flat, ref.Index(i).Interface())
+		}
+	}
+
+	completed, err := s.completeValues(ctx, typ.OfType, flat, selections)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]any, len(values))
+	offset := 0
+
+	for idx, length := range lengths {
+		if length < 0 {
+			continue
+		}
+
+		result[idx] = completed[offset : offset+length]
+		offset += length
+	}
+
+	return result, nil
+}
+
+func (s *executor) completeObjects(
+	ctx context.Context, typ *Object, values []any, selections []Selection,
+) ([]any, error) {
+	var (
+		sources []any
+		indexes []int
+	)
+
+	for idx, value := range values {
+		if !isNil(value) {
+			sources = append(sources, value)
+			indexes = append(indexes, idx)
+		}
+	}
+
+	result := make([]any, len(values))
+
+	if len(sources) == 0 {
+		return result, nil
+	}
+
+	objects, err := s.executeFields(ctx, typ, sources, selections)
+	if err != nil {
+		return nil, err
+	}
+
+	for idx, object := range objects {
+		result[indexes[idx]] = object
+	}
+
+	return result, nil
+}
+
+func serialize(values []any, serializer func(any) (any, error)) ([]any, error) {
+	result := make([]any, len(values))
+
+	for idx, value := range values {
+		if deref(value) == nil {
+			continue
+		}
+
+		var err error
+
+		result[idx], err = serializer(value)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return result, nil
+}
+
+// collectFields flattens fragments and groups fields by response keys.
+func (s *executor) collectFields(
+	objType *Object, selections []Selection, visited map[string]bool,
+) ([]*collectedField, error) {
+	var (
+		result []*collectedField
+		byKey  = make(map[string]*collectedField)
+	)
+
+	err := s.collect(objType, selections, visited, func(field *FieldSelection) {
+		key := field.ResponseKey()
+
+		if group, ok := byKey[key]; ok {
+			group.fields = append(group.fields, field)
+
+			return
+		}
+
+		group := &collectedField{key: key, fields: []*FieldSelection{field}}
+		byKey[key] = group
+		result = append(result, group)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+func (s *executor) collect(
+	objType *Object, selections []Selection, visited map[string]bool, add func(*FieldSelection),
+) error {
+	for _, selection := range selections {
+		var (
+			directives    []*Directive
+			typeCondition string
+			subSelections []Selection
+		)
+
+		switch typed := selection.(type) {
+		case *FieldSelection:
+			include, err := s.included(typed.Directives)
+			if err != nil {
+				return err
+			}
+
+			if include {
+				add(typed)
+			}
+
+			continue
+		case *FragmentSpread:
+			if visited[typed.Name] {
+				return fmt.Errorf("%w: fragment `%s` spreads itself", ErrValidation, typed.Name)
+			}
+
+			fragment, ok := s.document.Fragments[typed.Name]
+			if !ok {
+				return fmt.Errorf("%w: unknown fragment `%s`", ErrValidation, typed.Name)
+			}
+
+			directives, typeCondition, subSelections = typed.Directives, fragment.TypeCondition, fragment.SelectionSet
+			visited = withVisited(visited, typed.Name)
+		case *InlineFragment:
+			directives, typeCondition, subSelections = typed.Directives, typed.TypeCondition, typed.SelectionSet
+		}
+
+		include, err := s.included(directives)
+		if err != nil {
+			return err
+		}
+
+		if !include || (typeCondition != "" && typeCondition != objType.Name) {
+			continue
+		}
+
+		err = s.collect(objType, subSelections, visited, add)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func withVisited(visited map[string]bool, name string) map[string]bool {
+	result := make(map[string]bool, len(visited)+1)
+	for key := range visited {
+		result[key] = true
+	}
+
+	result[name] = true
+
+	return result
+}
+
+// included evaluates @skip and @include directives.
+func (s *executor) included(directives []*Directive) (bool, error) {
+	for _, directive := range directives {
+		if directive.Name != "skip" && directive.Name != "include" {
+			return false, fmt.Errorf("%w: unknown directive `@%s`", ErrValidation, directive.Name)
+		}
+
+		value, err := s.coerceValue(NewNonNull(Boolean), directive.Arguments["if"])
+		if err != nil {
+			return false, fmt.Errorf("@%s: %w", directive.Name, err)
+		}
+
+		if value.(bool) == (directive.Name == "skip") { //nolint: forcetypeassert
+			return false, nil
+		}
+	}
+
+	return true, nil
+}
+
+func (s *executor) coerceArgs(definition *Field, field *FieldSelection) (Args, error) {
+	args := make(Args, len(definition.Args))
+
+	for name := range field.Arguments {
+		if _, ok := definition.Args[name]; !ok {
+			return nil, fmt.Errorf("%w: unknown argument `%s`", ErrValidation, name)
+		}
+	}
+
+	for name, argument := range definition.Args {
+		raw, ok := field.Arguments[name]
+
+		if variable, isVariable := raw.(Variable); isVariable {
+			raw, ok = s.variables[string(variable)]
+		}
+
+		if !ok {
+			if argument.Default == nil {
+				if _, nonNull := argument.Type.(*NonNull); nonNull {
+					return nil, fmt.Errorf("%w: argument `%s` is required", ErrValidation, name)
+				}
+
+				continue
+			}
+
+			raw = argument.Default
+		}
+
+		value, err := s.coerceValue(argument.Type, raw)
+		if err != nil {
+			return nil, fmt.Errorf("argument `%s`: %w", name, err)
+		}
+
+		args[name] = value
+	}
+
+	return args, nil
+}
+
+func (s *executor) coerceValue(typ Type, value any) (any, error) {
+	if variable, ok := value.(Variable); ok {
+		value = s.variables[string(variable)]
+	}
+
+	if nonNull, ok := typ.(*NonNull); ok {
+		if value == nil {
+			return nil, fmt.Errorf("%w: %s expected, got null", ErrInvalidValue, typ)
+		}
+
+		typ = nonNull.OfType
+	}
+
+	if value == nil {
+		return nil, nil //nolint: nilnil
+	}
+
+	switch typed := typ.(type) {
+	case *List:
+		values, ok := value.([]any)
+		if !ok {
+			values = []any{value}
+		}
+
+		result := make([]any, len(values))
+
+		for idx, item := range values {
+			var err error
+
+			result[idx], err = s.coerceValue(typed.OfType, item)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		return result, nil
+	case *Scalar:
+		return typed.Coerce(value)
+	case *Enum:
+		return typed.coerce(value)
+	}
+
+	return nil, fmt.Errorf("%w: %s can't be used as input", ErrValidation, typ)
+}
+
+func mergeSelectionSets(fields []*FieldSelection) []Selection {
+	if len(fields) == 1 {
+		return fields[0].SelectionSet
+	}
+
+	var result []Selection
+	for _, field := range fields {
+		result = append(result, field.SelectionSet...)
+	}
+
+	return result
+}
@@ -0,0 +1,170 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+type testItem struct {
+	ID       int64
+	Name     string
+	ParentID int64
+}
+
+func testSchema(batches *int) *Schema {
+	rows := map[int64]*testItem{
+		1: {ID: 1, Name: "Volkswagen"},
+		2: {ID: 2, Name: "Golf", ParentID: 1},
+		3: {ID: 3, Name: "Passat", ParentID: 1},
+		4: {ID: 4, Name: "Golf I", ParentID: 2},
+	}
+
+	item := &Object{Name: "Item"}
+	item.Fields = map[string]*Field{
+		"id": {
+			Type: NewNonNull(ID),
+			Resolve: func(_ context.Context, source any, _ Args) (any, error) {
+				return source.(*testItem).ID, nil //nolint: forcetypeassert
+			},
+		},
+		"name": {
+			Type: String,
+			Resolve: func(_ context.Context, source any, _ Args) (any, error) {
+				return source.(*testItem).Name, nil //nolint: forcetypeassert
+			},
+		},
+		"childs": {
+			Type: NewNonNull(NewList(NewNonNull(item))),
+			Args: map[string]*Argument{"limit": {Type: Int, Default: int64(10)}},
+			BatchResolve: func(_ context.Context, sources []any, args Args) ([]any, error) {
+				*batches++
+
+				limit, _ := args.Int("limit")
+				result := make([]any, len(sources))
+
+				for idx, source := range sources {
+					childs := make([]*testItem, 0)
+
+					for id := range int64(len(rows)) {
+						row := rows[id+1]
+						if row.ParentID == source.(*testItem).ID && int64(len(childs)) < limit { //nolint: forcetypeassert
+							childs = append(childs, row)
+						}
+					}
+
+					result[idx] = childs
+				}
+
+				return result, nil
+			},
+			Complexity: func(args Args, childComplexity int) int {
+				limit, _ := args.Int("limit")
+
+				return 1 + int(limit)*childComplexity
+			},
+		},
+	}
+
+	return &Schema{
+		Query: &Object{Name: "Query", Fields: map[string]*Field{
+			"item": {
+				Type: item,
+				Args: map[string]*Argument{"id": {Type: NewNonNull(ID)}},
+				Resolve: func(_ context.Context, _ any, args Args) (any, error) {
+					id, _ := args.Int("id")
+
+					return rows[id], nil
+				},
+			},
+		}},
+		MaxDepth:      5,
+		MaxComplexity: 100,
+	}
+}
+
+func executeJSON(t *testing.T, schema *Schema, request Request) string {
+	t.Helper()
+
+	result, err := json.Marshal(schema.Execute(t.Context(), request))
+	require.NoError(t, err)
+
+	return string(result)
+}
+
+func TestExecute(t *testing.T) {
+	t.Parallel()
+
+	batches := 0
+	schema := testSchema(&batches)
+
+	require.JSONEq(t, `{"data": {
+		"brand": {
+			"__typename": "Item",
+			"name": "Volkswagen",
+			"childs": [
+				{"id": "2", "name": "Golf", "childs": [{"name": "Golf I"}]},
+				{"id": "3", "name": "Passat", "childs": []}
+			]
+		},
+		"missing": null
+	}}`, executeJSON(t, schema, Request{
+		Query: `query ($id: ID!, $skip: Boolean = false) {
+			brand: item(id: $id) {
+				__typename
+				name
+				childs(limit: 2) { ...child }
+			}
+			missing: item(id: 100) { name @skip(if: $skip) }
+		}
+		fragment child on Item { id name childs(limit: 5) { name } }`,
+		Variables: map[string]any{"id": "1"},
+	}))
+
+	// one query per level of childs, not per item
+	require.Equal(t, 2, batches)
+}
+
+func TestExecuteOrder(t *testing.T) {
+	t.Parallel()
+
+	batches := 0
+
+	// fields are returned in order of selection
+	require.Equal(t, `{"data":{"item":{"name":"Golf","id":"2"}}}`, executeJSON(t, testSchema(&batches), Request{
+		Query: `{ item(id: 2) { name id } }`,
+	}))
+}
+
+func TestExecuteErrors(t *testing.T) {
+	t.Parallel()
+
+	batches := 0
+	schema := testSchema(&batches)
+
+	for query, expected := range map[string]error{
+		`{ item(id: 1) { unknown } }`:                                        ErrValidation,
+		`{ item { name } }`:                                                  ErrValidation,
+		`{ item(id: 1, foo: 1) { name } }`:                                   ErrValidation,
+		`{ item(id: 1) }`:                                                    ErrValidation,
+		`{ item(id: 1) { name { id } } }`:                                    ErrValidation,
+		`{ item(id: 1) { ...missing } }`:                                     ErrValidation,
+		`{ item(id: 1) { ...f } } fragment f on Item { ...f }`:               ErrValidation,
+		`{ item(id: 1) { name @defer } }`:                                    ErrValidation,
+		`{ item(id: "x") { name } }`:                                         ErrInvalidValue,
+		`mutation { item(id: 1) { name } }`:                                  ErrOperationNotAllowed,
+		`{ item(id: 1) { childs(limit: 10) { childs(limit: 10) { id } } } }`: ErrComplexityExceeded,
+		`{ item(id: 1) { childs(limit: 1) { childs(limit: 1) { childs(limit: 1) { childs(limit: 1) {
+			childs(limit: 1) { id } } } } } } }`: ErrDepthExceeded,
+		`{ item(id: 1) { childs(limit: 2000000000) { childs(limit: 2000000000) {
+			childs(limit: 2000000000) { id } } } } }`: ErrComplexityExceeded,
+	} {
+		_, err := schema.execute(t.Context(), Request{Query: query})
+		require.ErrorIs(t, err, expected, query)
+	}
+
+	require.Equal(t, `{"data":null,"errors":[{"message":"validation error: operation name is required"}]}`,
+		executeJSON(t, schema, Request{Query: `query a { item(id: 1) { id } } query b { item(id: 2) { id } }`}))
+}
@@ -0,0 +1,820 @@
+package graphql
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+var ErrSyntax = errors.New("syntax error")
+
+// maxNestingDepth limits nesting of selection sets, values and type references,
+// so parser recursion is bounded regardless of document size.
+const maxNestingDepth = 64
+
+type tokenKind int
+
+const (
+	tokenEOF tokenKind = iota
+	tokenPunct
+	tokenName
+	tokenInt
+	tokenFloat
+	tokenString
+)
+
+type token struct {
+	kind  tokenKind
+	value string
+	pos   int
+}
+
+// Document is a parsed request. Only executable definitions are supported.
+type Document struct {
+	Operations []*Operation
+	Fragments  map[string]*Fragment
+}
+
+type Operation struct {
+	Type         string
+	Name         string
+	Variables    []*VariableDefinition
+	SelectionSet []Selection
+}
+
+type VariableDefinition struct {
+	Name    string
+	NonNull bool
+	Default any
+}
+
+// Selection is one of *FieldSelection, *FragmentSpread or *InlineFragment.
+type Selection interface {
+	selection()
+}
+
+type Directive struct {
+	Name      string
+	Arguments map[string]any
+}
+
+type FieldSelection struct {
+	Alias        string
+	Name         string
+	Arguments    map[string]any
+	Directives   []*Directive
+	SelectionSet []Selection
+}
+
+type FragmentSpread struct {
+	Name       string
+	Directives []*Directive
+}
+
+type InlineFragment struct {
+	TypeCondition string
+	Directives    []*Directive
+	SelectionSet  []Selection
+}
+
+type Fragment struct {
+	Name          string
+	TypeCondition string
+	SelectionSet  []Selection
+}
+
+// Variable is a reference to variable in argument values.
+type Variable string
+
+// EnumValue is an unquoted name in argument values.
+type EnumValue string
+
+func (*FieldSelection) selection() {}
+func (*FragmentSpread) selection() {}
+func (*InlineFragment) selection() {}
+
+func (f *FieldSelection) ResponseKey() string {
+	if f.Alias != "" {
+		return f.Alias
+	}
+
+	return f.Name
+}
+
+type parser struct {
+	source string
+	pos    int
+	token  token
+	depth  int
+}
+
+// Parse parses GraphQL document.
+func Parse(source string) (*Document, error) {
+	prs := &parser{source: source}
+
+	err := prs.next()
+	if err != nil {
+		return nil, err
+	}
+
+	doc := &Document{Fragments: make(map[string]*Fragment)}
+
+	for prs.token.kind != tokenEOF {
+		if prs.peek("{") {
+			selectionSet, err := prs.parseSelectionSet()
+			if err != nil {
+				return nil, err
+			}
+
+			doc.Operations = append(doc.Operations, &Operation{Type: "query", SelectionSet: selectionSet})
+
+			continue
+		}
+
+		if prs.token.kind != tokenName {
+			return nil, prs.unexpected()
+		}
+
+		switch prs.token.value {
+		case "query", "mutation", "subscription":
+			operation, err := prs.parseOperation()
+			if err != nil {
+				return nil, err
+			}
+
+			doc.Operations = append(doc.Operations, operation)
+		case "fragment":
+			fragment, err := prs.parseFragment()
+			if err != nil {
+				return nil, err
+			}
+
+			if _, ok := doc.Fragments[fragment.Name]; ok {
+				return nil, fmt.Errorf("%w: duplicate fragment `%s`", ErrSyntax, fragment.Name)
+			}
+
+			doc.Fragments[fragment.Name] = fragment
+		default:
+			return nil, prs.unexpected()
+		}
+	}
+
+	if len(doc.Operations) == 0 {
+		return nil, fmt.Errorf("%w: no operations", ErrSyntax)
+	}
+
+	return doc, nil
+}
+
+func (p *parser) parseOperation() (*Operation, error) {
+	operation := &Operation{Type: p.token.value}
+
+	err := p.next()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.token.kind == tokenName {
+		operation.Name = p.token.value
+
+		err = p.next()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if p.peek("(") {
+		operation.Variables, err = p.parseVariableDefinitions()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	_, err = p.parseDirectives()
+	if err != nil {
+		return nil, err
+	}
+
+	operation.SelectionSet, err = p.parseSelectionSet()
+	if err != nil {
+		return nil, err
+	}
+
+	return operation, nil
+}
+
+func (p *parser) parseVariableDefinitions() ([]*VariableDefinition, error) {
+	err := p.expect("(")
+	if err != nil {
+		return nil, err
+	}
+
+	var result []*VariableDefinition
+
+	for !p.peek(")") {
+		err = p.expect("$")
+		if err != nil {
+			return nil, err
+		}
+
+		name, err := p.parseName()
+		if err != nil {
+			return nil, err
+		}
+
+		err = p.expect(":")
+		if err != nil {
+			return nil, err
+		}
+
+		nonNull, err := p.parseTypeRef()
+		if err != nil {
+			return nil, err
+		}
+
+		definition := &VariableDefinition{Name: name, NonNull: nonNull}
+
+		if p.peek("=") {
+			err = p.next()
+			if err != nil {
+				return nil, err
+			}
+
+			definition.Default, err = p.parseValue(true)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		result = append(result, definition)
+	}
+
+	return result, p.next()
+}
+
+// parseTypeRef skips type reference and reports whether outer type is non-null.
+func (p *parser) parseTypeRef() (bool, error) {
+	err := p.enter()
+	if err != nil {
+		return false, err
+	}
+	defer p.leave()
+
+	if p.peek("[") {
+		err = p.next()
+		if err != nil {
+			return false, err
+		}
+
+		_, err = p.parseTypeRef()
+		if err != nil {
+			return false, err
+		}
+
+		err = p.expect("]")
+	} else {
+		_, err = p.parseName()
+	}
+
+	if err != nil {
+		return false, err
+	}
+
+	if p.peek("!") {
+		return true, p.next()
+	}
+
+	return false, nil
+}
+
+func (p *parser) parseFragment() (*Fragment, error) {
+	err := p.next()
+	if err != nil {
+		return nil, err
+	}
+
+	name, err := p.parseName()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.token.kind != tokenName || p.token.value != "on" {
+		return nil, p.unexpected()
+	}
+
+	err = p.next()
+	if err != nil {
+		return nil, err
+	}
+
+	typeCondition, err := p.parseName()
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = p.parseDirectives()
+	if err != nil {
+		return nil, err
+	}
+
+	selectionSet, err := p.parseSelectionSet()
+	if err != nil {
+		return nil, err
+	}
+
+	return &Fragment{Name: name, TypeCondition: typeCondition, SelectionSet: selectionSet}, nil
+}
+
+func (p *parser) parseSelectionSet() ([]Selection, error) {
+	err := p.enter()
+	if err != nil {
+		return nil, err
+	}
+	defer p.leave()
+
+	err = p.expect("{")
+	if err != nil {
+		return nil, err
+	}
+
+	var result []Selection
+
+	for !p.peek("}") {
+		selection, err := p.parseSelection()
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, selection)
+	}
+
+	if len(result) == 0 {
+		return nil, fmt.Errorf("%w: empty selection set at %d", ErrSyntax, p.token.pos)
+	}
+
+	return result, p.next()
+}
+
+func (p *parser) parseSelection() (Selection, error) {
+	if !p.peek("...") {
+		return p.parseField()
+	}
+
+	err := p.next()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.token.kind == tokenName && p.token.value != "on" {
+		spread := &FragmentSpread{Name: p.token.value}
+
+		err = p.next()
+		if err != nil {
+			return nil, err
+		}
+
+		spread.Directives, err = p.parseDirectives()
+		if err != nil {
+			return nil, err
+		}
+
+		return spread, nil
+	}
+
+	fragment := &InlineFragment{}
+
+	if p.token.kind == tokenName {
+		err = p.next()
+		if err != nil {
+			return nil, err
+		}
+
+		fragment.TypeCondition, err = p.parseName()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	fragment.Directives, err = p.parseDirectives()
+	if err != nil {
+		return nil, err
+	}
+
+	fragment.SelectionSet, err = p.parseSelectionSet()
+	if err != nil {
+		return nil, err
+	}
+
+	return fragment, nil
+}
+
+func (p *parser) parseField() (*FieldSelection, error) {
+	name, err := p.parseName()
+	if err != nil {
+		return nil, err
+	}
+
+	field := &FieldSelection{Name: name}
+
+	if p.peek(":") {
+		err = p.next()
+		if err != nil {
+			return nil, err
+		}
+
+		field.Alias = name
+
+		field.Name, err = p.parseName()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	field.Arguments, err = p.parseArguments()
+	if err != nil {
+		return nil, err
+	}
+
+	field.Directives, err = p.parseDirectives()
+	if err != nil {
+		return nil, err
+	}
+
+	if p.peek("{") {
+		field.SelectionSet, err = p.parseSelectionSet()
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return field, nil
+}
+
+func (p *parser) parseDirectives() ([]*Directive, error) {
+	var result []*Directive
+
+	for p.peek("@") {
+		err := p.next()
+		if err != nil {
+			return nil, err
+		}
+
+		name, err := p.parseName()
+		if err != nil {
+			return nil, err
+		}
+
+		args, err := p.parseArguments()
+		if err != nil {
+			return nil, err
+		}
+
+		result = append(result, &Directive{Name: name, Arguments: args})
+	}
+
+	return result, nil
+}
+
+func (p *parser) parseArguments() (map[string]any, error) {
+	result := make(map[string]any)
+
+	if !p.peek("(") {
+		return result, nil
+	}
+
+	err := p.next()
+	if err != nil {
+		return nil, err
+	}
+
+	for !p.peek(")") {
+		name, err := p.parseName()
+		if err != nil {
+			return nil, err
+		}
+
+		err = p.expect(":")
+		if err != nil {
+			return nil, err
+		}
+
+		result[name], err = p.parseValue(false)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return result, p.next()
+}
+
+func (p *parser) parseValue(isConst bool) (any, error) {
+	tok := p.token
+
+	switch tok.kind {
+	case tokenInt:
+		value, err := strconv.ParseInt(tok.value, 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrSyntax, err)
+		}
+
+		return value, p.next()
+	case tokenFloat:
+		value, err := strconv.ParseFloat(tok.value, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %w", ErrSyntax, err)
+		}
+
+		return value, p.next()
+	case tokenString:
+		return tok.value, p.next()
+	case tokenName:
+		var value any
+
+		switch tok.value {
+		case "true":
+			value = true
+		case "false":
+			value = false
+		case "null":
+			value = nil
+		default:
+			value = EnumValue(tok.value)
+		}
+
+		return value, p.next()
+	case tokenPunct:
+		return p.parseCompositeValue(isConst)
+	case tokenEOF:
+	}
+
+	return nil, p.unexpected()
+}
+
+func (p *parser) parseCompositeValue(isConst bool) (any, error) {
+	err := p.enter()
+	if err != nil {
+		return nil, err
+	}
+	defer p.leave()
+
+	switch p.token.value {
+	case "$":
+		if isConst {
+			return nil, p.unexpected()
+		}
+
+		err = p.next()
+		if err != nil {
+			return nil, err
+		}
+
+		name, err := p.parseName()
+		if err != nil {
+			return nil, err
+		}
+
+		return Variable(name), nil
+	case "[":
+		err = p.next()
+		if err != nil {
+			return nil, err
+		}
+
+		list := make([]any, 0)
+
+		for !p.peek("]") {
+			value, err := p.parseValue(isConst)
+			if err != nil {
+				return nil, err
+			}
+
+			list = append(list, value)
+		}
+
+		return list, p.next()
+	case "{":
+		err = p.next()
+		if err != nil {
+			return nil, err
+		}
+
+		object := make(map[string]any)
+
+		for !p.peek("}") {
+			name, err := p.parseName()
+			if err != nil {
+				return nil, err
+			}
+
+			err = p.expect(":")
+			if err != nil {
+				return nil, err
+			}
+
+			object[name], err = p.parseValue(isConst)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		return object, p.next()
+	}
+
+	return nil, p.unexpected()
+}
+
+func (p *parser) parseName() (string, error) {
+	if p.token.kind != tokenName {
+		return "", p.unexpected()
+	}
+
+	name := p.token.value
+
+	return name, p.next()
+}
+
+func (p *parser) enter() error {
+	p.depth++
+
+	if p.depth > maxNestingDepth {
+		return fmt.Errorf("%w: nesting deeper than %d at %d", ErrSyntax, maxNestingDepth, p.token.pos)
+	}
+
+	return nil
+}
+
+func (p *parser) leave() {
+	p.depth--
+}
+
+func (p *parser) peek(punct string) bool {
+	return p.token.kind == tokenPunct && p.token.value == punct
+}
+
+func (p *parser) expect(punct string) error {
+	if !p.peek(punct) {
+		return p.unexpected()
+	}
+
+	return p.next()
+}
+
+func (p *parser) unexpected() error {
+	if p.token.kind == tokenEOF {
+		return fmt.Errorf("%w: unexpected end of document", ErrSyntax)
+	}
+
+	return fmt.Errorf("%w: unexpected `%s` at %d", ErrSyntax, p.token.value, p.token.pos)
+}
+
+// next reads next token skipping whitespace, commas and comments.
+func (p *parser) next() error {
+	for p.pos < len(p.source) {
+		char := p.source[p.pos]
+
+		switch {
+		case char == ' ' || char == '\t' || char == '\n' || char == '\r' || char == ',':
+			p.pos++
+		case char == '#':
+			for p.pos < len(p.source) && p.source[p.pos] != '\n' {
+				p.pos++
+			}
+		case strings.HasPrefix(p.source[p.pos:], "\uFEFF"):
+			p.pos += len("\uFEFF")
+		default:
+			return p.readToken()
+		}
+	}
+
+	p.token = token{kind: tokenEOF, pos: p.pos}
+
+	return nil
+}
+
+func (p *parser) readToken() error {
+	start := p.pos
+	char := p.source[p.pos]
+
+	switch {
+	case strings.HasPrefix(p.source[start:], "..."):
+		p.pos += len("...")
+		p.token = token{kind: tokenPunct, value: "...", pos: start}
+	case strings.ContainsRune("!$()&:=@[]{}|", rune(char)):
+		p.pos++
+		p.token = token{kind: tokenPunct, value: string(char), pos: start}
+	case char == '_' || isLetter(char):
+		for p.pos < len(p.source) && (p.source[p.pos] == '_' || isLetter(p.source[p.pos]) || isDigit(p.source[p.pos])) {
+			p.pos++
+		}
+
+		p.token = token{kind: tokenName, value: p.source[start:p.pos], pos: start}
+	case char == '-' || isDigit(char):
+		return p.readNumber()
+	case char == '"':
+		return p.readString()
+	default:
+		r, _ := utf8.DecodeRuneInString(p.source[start:])
+
+		return fmt.Errorf("%w: unexpected character %q at %d", ErrSyntax, r, start)
+	}
+
+	return nil
+}
+
+func (p *parser) readNumber() error {
+	start := p.pos
+	kind := tokenInt
+
+	if p.source[p.pos] == '-' {
+		p.pos++
+	}
+
+	digits := p.skipDigits()
+
+	if p.pos < len(p.source) && p.source[p.pos] == '.' {
+		kind = tokenFloat
+		p.pos++
+		digits = p.skipDigits() && digits
+	}
+
+	if p.pos < len(p.source) && (p.source[p.pos] == 'e' || p.source[p.pos] == 'E') {
+		kind = tokenFloat
+		p.pos++
+
+		if p.pos < len(p.source) && (p.source[p.pos] == '+' || p.source[p.pos] == '-') {
+			p.pos++
+		}
+
+		digits = p.skipDigits() && digits
+	}
+
+	if !digits {
+		return fmt.Errorf("%w: invalid number at %d", ErrSyntax, start)
+	}
+
+	p.token = token{kind: kind, value: p.source[start:p.pos], pos: start}
+
+	return nil
+}
+
+func (p *parser) skipDigits() bool {
+	start := p.pos
+
+	for p.pos < len(p.source) && isDigit(p.source[p.pos]) {
+		p.pos++
+	}
+
+	return p.pos > start
+}
+
+func (p *parser) readString() error {
+	start := p.pos
+
+	if strings.HasPrefix(p.source[start:], `"""`) {
+		end := strings.Index(p.source[start+3:], `"""`)
+		if end < 0 {
+			return fmt.Errorf("%w: unterminated string at %d", ErrSyntax, start)
+		}
+
+		p.pos = start + 3 + end + 3
+		p.token = token{kind: tokenString, value: strings.TrimSpace(p.source[start+3 : start+3+end]), pos: start}
+
+		return nil
+	}
+
+	p.pos++
+
+	for p.pos < len(p.source) {
+		switch p.source[p.pos] {
+		case '\\':
+			p.pos += 2
+		case '\n':
+			return fmt.Errorf("%w: unterminated string at %d", ErrSyntax, start)
+		case '"':
+			p.pos++
+
+			value, err := strconv.Unquote(p.source[start:p.pos])
+			if err != nil {
+				return fmt.Errorf("%w: invalid string at %d: %w", ErrSyntax, start, err)
+			}
+
+			p.token = token{kind: tokenString, value: value, pos: start}
+
+			return nil
+		default:
+			p.pos++
+		}
+	}
+
+	return fmt.Errorf("%w: unterminated string at %d", ErrSyntax, start)
+}
+
+func isLetter(char byte) bool {
+	return (char >= 'a' && char <= 'z') || (char >= 'A' && char <= 'Z')
+}
+
+func isDigit(char byte) bool {
+	return char >= '0' && char <= '9'
+}
@@ -0,0 +1,116 @@
+package graphql
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestParse(t *testing.T) {
+	t.Parallel()
+
+	doc, err := Parse(`
+		# comment
+		query Items($limit: Int = 10, $ids: [ID!]!) {
+			first: items(ids: $ids, limit: $limit, name: "Golf \"I\"", type: VEHICLE) {
+				id
+				...names @include(if: true)
+				... on Item { catname }
+			}
+		}
+
+		fragment names on Item {
+			name, fullName
+		}
+	`)
+	require.NoError(t, err)
+	require.Len(t, doc.Operations, 1)
+
+	operation := doc.Operations[0]
+	require.Equal(t, "query", operation.Type)
+	require.Equal(t, "Items", operation.Name)
+	require.Equal(t, []*VariableDefinition{
+		{Name: "limit", Default: int64(10)},
+		{Name: "ids", NonNull: true},
+	}, operation.Variables)
+
+	require.Len(t, operation.SelectionSet, 1)
+	field, ok := operation.SelectionSet[0].(*FieldSelection)
+	require.True(t, ok)
+	require.Equal(t, "first", field.ResponseKey())
+	require.Equal(t, "items", field.Name)
+	require.Equal(t, map[string]any{
+		"ids":   Variable("ids"),
+		"limit": Variable("limit"),
+		"name":  `Golf "I"`,
+		"type":  EnumValue("VEHICLE"),
+	}, field.Arguments)
+	require.Len(t, field.SelectionSet, 3)
+
+	spread, ok := field.SelectionSet[1].(*FragmentSpread)
+	require.True(t, ok)
+	require.Equal(t, "names", spread.Name)
+	require.Equal(t, []*Directive{{Name: "include", Arguments: map[string]any{"if": true}}}, spread.Directives)
+
+	inline, ok := field.SelectionSet[2].(*InlineFragment)
+	require.True(t, ok)
+	require.Equal(t, "Item", inline.TypeCondition)
+
+	require.Equal(t, "Item", doc.Fragments["names"].TypeCondition)
+	require.Len(t, doc.Fragments["names"].SelectionSet, 2)
+}
+
+func TestParseValues(t *testing.T) {
+	t.Parallel()
+
+	doc, err := Parse(`{ f(a: -1, b: 1.5e2, c: [1, "x", null], d: {e: false}, g: """ block """) }`)
+	require.NoError(t, err)
+
+	field, ok := doc.Operations[0].SelectionSet[0].(*FieldSelection)
+	require.True(t, ok)
+	require.Equal(t, map[string]any{
+		"a": int64(-1),
+		"b": 150.0,
+		"c": []any{int64(1), "x", nil},
+		"d": map[string]any{"e": false},
+		"g": "block",
+	}, field.Arguments)
+}
+
+func TestParseErrors(t *testing.T) {
+	t.Parallel()
+
+	for _, source := range []string{
+		``,
+		`{`,
+		`{ }`,
+		`{ a(b: ) }`,
+		`{ a(b: "unterminated) }`,
+		`query ($a: Int = $b) { a }`,
+		`fragment f on Item { a } fragment f on Item { b } { a }`,
+		`{ a } %`,
+	} {
+		_, err := Parse(source)
+		require.ErrorIs(t, err, ErrSyntax, source)
+	}
+}
+
+func TestParseDepthLimit(t *testing.T) {
+	t.Parallel()
+
+	const depth = 100000
+
+	for _, source := range []string{
+		strings.Repeat("{ a ", depth) + strings.Repeat("}", depth),
+		"{ a(b: " + strings.Repeat("[", depth) + strings.Repeat("]", depth) + ") }",
+		"{ a(b: " + strings.Repeat("{c: ", depth) + "1" + strings.Repeat("}", depth) + ") }",
+		"query ($a: " + strings.Repeat("[", depth) + "Int" + strings.Repeat("]", depth) + ") { a }",
+	} {
+		_, err := Parse(source)
+		require.ErrorIs(t, err, ErrSyntax)
+	}
+
+	_, err := Parse(strings.Repeat("{ a ", maxNestingDepth) + strings.Repeat("}", maxNestingDepth))
+	require.NoError(t, err)
+}
@@ -0,0 +1,327 @@
+package graphql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"reflect"
+	"slices"
+	"strconv"
+)
+
+var ErrInvalidValue = errors.New("invalid value")
+
+// Type is one of *Scalar, *Enum, *Object, *List or *NonNull.
+type Type interface {
+	String() string
+}
+
+type Scalar struct {
+	Name string
+	// Coerce converts argument value, literal or variable, to Go value.
+	Coerce func(value any) (any, error)
+	// Serialize converts resolved value to JSON value.
+	Serialize func(value any) (any, error)
+}
+
+type Enum struct {
+	Name   string
+	Values []string
+}
+
+type Object struct {
+	Name   string
+	Fields map[string]*Field
+}
+
+type List struct {
+	OfType Type
+}
+
+type NonNull struct {
+	OfType Type
+}
+
+type Argument struct {
+	Type    Type
+	Default any
+}
+
+// Args are coerced arguments of field, absent optional arguments without default are missing.
+type Args map[string]any
+
+type (
+	ResolveFunc      func(ctx context.Context, source any, args Args) (any, error)
+	BatchResolveFunc func(ctx context.Context, sources []any, args Args) ([]any, error)
+	ComplexityFunc   func(args Args, childComplexity int) int
+)
+
+// Field is resolved either by Resolve, once per source, or by BatchResolve, once for all sources at the same
+// level of response, which allows to load related rows of all parents with a single query.
+// Fields without resolvers read value by field name from map[string]any sources.
+type Field struct {
+	Type         Type
+	Args         map[string]*Argument
+	Resolve      ResolveFunc
+	BatchResolve BatchResolveFunc
+	// Complexity defaults to 1 + childComplexity.
+	Complexity ComplexityFunc
+}
+
+func (s *Scalar) String() string  { return s.Name }
+func (s *Enum) String() string    { return s.Name }
+func (s *Object) String() string  { return s.Name }
+func (s *List) String() string    { return "[" + s.OfType.String() + "]" }
+func (s *NonNull) String() string { return s.OfType.String() + "!" }
+
+func NewList(ofType Type) *List {
+	return &List{OfType: ofType}
+}
+
+func NewNonNull(ofType Type) *NonNull {
+	return &NonNull{OfType: ofType}
+}
+
+var (
+	Int = &Scalar{
+		Name:      "Int",
+		Coerce:    coerceInt,
+		Serialize: coerceInt,
+	}
+	Float = &Scalar{
+		Name:      "Float",
+		Coerce:    coerceFloat,
+		Serialize: coerceFloat,
+	}
+	String = &Scalar{
+		Name: "String",
+		Coerce: func(value any) (any, error) {
+			if str, ok := value.(string); ok {
+				return str, nil
+			}
+
+			return nil, fmt.Errorf("%w: String expected", ErrInvalidValue)
+		},
+		Serialize: func(value any) (any, error) {
+			return fmt.Sprint(deref(value)), nil
+		},
+	}
+	Boolean = &Scalar{
+		Name: "Boolean",
+		Coerce: func(value any) (any, error) {
+			if b, ok := value.(bool); ok {
+				return b, nil
+			}
+
+			return nil, fmt.Errorf("%w: Boolean expected", ErrInvalidValue)
+		},
+		Serialize: func(value any) (any, error) {
+			if b, ok := value.(bool); ok {
+				return b, nil
+			}
+
+			return nil, fmt.Errorf("%w: Boolean expected", ErrInvalidValue)
+		},
+	}
+	// ID is serialized as string, int64 is used as Go value.
+	ID = &Scalar{
+		Name:      "ID",
+		Coerce:    coerceID,
+		Serialize: serializeID,
+	}
+)
+
+func (s Args) Int(name string) (int64, bool) {
+	value, ok := s[name].(int64)
+
+	return value, ok
+}
+
+func (s Args) String(name string) (string, bool) {
+	value, ok := s[name].(string)
+
+	return value, ok
+}
+
+func (s Args) Bool(name string) (bool, bool) {
+	value, ok := s[name].(bool)
+
+	return value, ok
+}
+
+// IDs returns list of ID argument.
+func (s Args) IDs(name string) []int64 {
+	values, _ := s[name].([]any)
+	result := make([]int64, 0, len(values))
+
+	for _, value := range values {
+		if id, ok := value.(int64); ok {
+			result = append(result, id)
+		}
+	}
+
+	return result
+}
+
+func coerceInt(value any) (any, error) {
+	value = deref(value)
+
+	switch typed := value.(type) {
+	case json.Number:
+		result, err := typed.Int64()
+		if err != nil {
+			return nil, fmt.Errorf("%w: Int expected", ErrInvalidValue)
+		}
+
+		return result, nil
+	case float64:
+		if typed != math.Trunc(typed) {
+			return nil, fmt.Errorf("%w: Int expected", ErrInvalidValue)
+		}
+
+		return int64(typed), nil
+	}
+
+	ref := reflect.ValueOf(value)
+
+	switch ref.Kind() { //nolint: exhaustive
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return ref.Int(), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return int64(ref.Uint()), nil //nolint: gosec
+	}
+
+	return nil, fmt.Errorf("%w: Int expected", ErrInvalidValue)
+}
+
+func coerceFloat(value any) (any, error) {
+	value = deref(value)
+
+	switch typed := value.(type) {
+	case json.Number:
+		return typed.Float64()
+	case float32:
+		return float64(typed), nil
+	case float64:
+		return typed, nil
+	case string:
+		return nil, fmt.Errorf("%w: Float expected", ErrInvalidValue)
+	}
+
+	result, err := coerceInt(value)
+	if err != nil {
+		return nil, fmt.Errorf("%w: Float expected", ErrInvalidValue)
+	}
+
+	number, _ := result.(int64)
+
+	return float64(number), nil
+}
+
+func coerceID(value any) (any, error) {
+	switch typed := value.(type) {
+	case int64:
+		return typed, nil
+	case string, json.Number:
+		result, err := strconv.ParseInt(fmt.Sprint(typed), 10, 64)
+		if err != nil {
+			return nil, fmt.Errorf("%w: ID expected", ErrInvalidValue)
+		}
+
+		return result, nil
+	case float64:
+		return coerceInt(typed)
+	}
+
+	return nil, fmt.Errorf("%w: ID expected", ErrInvalidValue)
+}
+
+func serializeID(value any) (any, error) {
+	switch typed := deref(value).(type) {
+	case string:
+		return typed, nil
+	default:
+		id, err := coerceInt(typed)
+		if err != nil {
+			return nil, fmt.Errorf("%w: ID expected", ErrInvalidValue)
+		}
+
+		return strconv.FormatInt(id.(int64), 10), nil //nolint: forcetypeassert
+	}
+}
+
+func (s *Enum) coerce(value any) (any, error) {
+	var str string
+
+	switch typed := value.(type) {
+	case EnumValue:
+		str = string(typed)
+	case string:
+		str = typed
+	}
+
+	if !slices.Contains(s.Values, str) {
+		return nil, fmt.Errorf("%w: one of %v expected", ErrInvalidValue, s.Values)
+	}
+
+	return str, nil
+}
+
+func (s *Enum) serialize(value any) (any, error) {
+	str := fmt.Sprint(deref(value))
+
+	if !slices.Contains(s.Values, str) {
+		return nil, fmt.Errorf("%w: unexpected %s value `%s`", ErrInvalidValue, s.Name, str)
+	}
+
+	return str, nil
+}
+
+// deref returns value pointed by pointer, nil for nil pointers.
+func deref(value any) any {
+	ref := reflect.ValueOf(value)
+
+	for ref.Kind() == reflect.Pointer {
+		if ref.IsNil() {
+			return nil
+		}
+
+		ref = ref.Elem()
+	}
+
+	if !ref.IsValid() {
+		return nil
+	}
+
+	return ref.Interface()
+}
+
+func isNil(value any) bool {
+	if value == nil {
+		return true
+	}
+
+	ref := reflect.ValueOf(value)
+
+	switch ref.Kind() { //nolint: exhaustive
+	case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Interface:
+		return ref.IsNil()
+	}
+
+	return false
+}
+
+func namedType(typ Type) Type {
+	for {
+		switch typed := typ.(type) {
+		case *NonNull:
+			typ = typed.OfType
+		case *List:
+			typ = typed.OfType
+		default:
+			return typ
+		}
+	}
+}
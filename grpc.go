@@ -244,6 +244,15 @@ func wrapFieldViolations(fv []*errdetails.BadRequest_FieldViolation) error {
 	return st.Err()
 }
 
+// wrapCursorError reports invalid or unsupported pagination cursor as InvalidArgument.
+func wrapCursorError(err error) error {
+	if errors.Is(err, util.ErrInvalidCursor) || errors.Is(err, util.ErrCursorNotSupported) {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	return status.Error(codes.Internal, err.Error())
+}
+
 func (s *GRPCServer) GetTimezones(context.Context, *emptypb.Empty) (*Timezones, error) {
 	return &Timezones{Timezones: TimeZones()}, nil
 }
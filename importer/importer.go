@@ -0,0 +1,924 @@
+package importer
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"html"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/autowp/goautowp/attrs"
+	"github.com/autowp/goautowp/items"
+	"github.com/autowp/goautowp/query"
+	"github.com/autowp/goautowp/schema"
+	"github.com/autowp/goautowp/search"
+	"github.com/autowp/goautowp/textstorage"
+	"github.com/autowp/goautowp/util"
+	"github.com/doug-martin/goqu/v9"
+	"github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v3"
+)
+
+var (
+	ErrInvalidFile     = errors.New("invalid import file")
+	ErrAmbiguousMatch  = errors.New("more than one existing item matches")
+	ErrUnresolvedRef   = errors.New("unresolved reference")
+	errUnexpectedState = errors.New("unexpected import state")
+
+	itemTypes = map[string]schema.ItemTableItemTypeID{
+		"vehicle":   schema.ItemTableItemTypeIDVehicle,
+		"engine":    schema.ItemTableItemTypeIDEngine,
+		"category":  schema.ItemTableItemTypeIDCategory,
+		"twins":     schema.ItemTableItemTypeIDTwins,
+		"brand":     schema.ItemTableItemTypeIDBrand,
+		"factory":   schema.ItemTableItemTypeIDFactory,
+		"museum":    schema.ItemTableItemTypeIDMuseum,
+		"person":    schema.ItemTableItemTypeIDPerson,
+		"copyright": schema.ItemTableItemTypeIDCopyright,
+	}
+	itemParentTypes = map[string]schema.ItemParentType{
+		"":        schema.ItemParentTypeDefault,
+		"default": schema.ItemParentTypeDefault,
+		"tuning":  schema.ItemParentTypeTuning,
+		"sport":   schema.ItemParentTypeSport,
+		"design":  schema.ItemParentTypeDesign,
+	}
+	likeEscaper = strings.NewReplacer(`\`, `\\`, `%`, `\%`, `_`, `\_`)
+)
+
+// File is a model line to import. Both YAML and JSON are accepted.
+type File struct {
+	Items []Item `yaml:"items"`
+}
+
+// Item describes single item. Zero values are treated as not specified, existing values are kept for them.
+type Item struct {
+	// Key references item within file, defaults to catname or name.
+	Key             string              `yaml:"key"`
+	Type            string              `yaml:"type"`
+	Catname         string              `yaml:"catname"`
+	Name            string              `yaml:"name"`
+	FullName        string              `yaml:"full_name"`
+	Body            string              `yaml:"body"`
+	BeginYear       int32               `yaml:"begin_year"`
+	BeginMonth      int16               `yaml:"begin_month"`
+	EndYear         int32               `yaml:"end_year"`
+	EndMonth        int16               `yaml:"end_month"`
+	Today           *bool               `yaml:"today"`
+	BeginModelYear  int32               `yaml:"begin_model_year"`
+	EndModelYear    int32               `yaml:"end_model_year"`
+	Produced        *int32              `yaml:"produced"`
+	ProducedExactly *bool               `yaml:"produced_exactly"`
+	IsConcept       *bool               `yaml:"is_concept"`
+	IsGroup         *bool               `yaml:"is_group"`
+	Languages       map[string]Language `yaml:"languages"`
+	Parents         []Parent            `yaml:"parents"`
+	// VehicleTypes are catnames of vehicle types.
+	VehicleTypes []string `yaml:"vehicle_types"`
+}
+
+type Language struct {
+	Name     string `yaml:"name"`
+	Text     string `yaml:"text"`
+	FullText string `yaml:"full_text"`
+}
+
+// Parent references parent by key of item in the same file, by catname of existing item or by id.
+type Parent struct {
+	Item    string `yaml:"item"`
+	ID      int64  `yaml:"id"`
+	Type    string `yaml:"type"`
+	Catname string `yaml:"catname"`
+}
+
+type ChangeKind string
+
+const (
+	ChangeKindCreateItem     ChangeKind = "create-item"
+	ChangeKindUpdateItem     ChangeKind = "update-item"
+	ChangeKindCreateParent   ChangeKind = "create-parent"
+	ChangeKindUpdateLanguage ChangeKind = "update-language"
+	ChangeKindAddVehicleType ChangeKind = "add-vehicle-type"
+)
+
+// Change is a single line of import diff. ItemID is zero for items created by import.
+type Change struct {
+	Kind     ChangeKind `json:"kind"`
+	Key      string     `json:"key"`
+	ItemID   int64      `json:"item_id,omitempty"`
+	Field    string     `json:"field,omitempty"`
+	Language string     `json:"language,omitempty"`
+	Old      string     `json:"old,omitempty"`
+	New      string     `json:"new,omitempty"`
+}
+
+func (c Change) String() string {
+	item := c.Key
+	if c.ItemID > 0 {
+		item = fmt.Sprintf("%s #%d", c.Key, c.ItemID)
+	}
+
+	switch c.Kind {
+	case ChangeKindCreateItem:
+		return fmt.Sprintf("+ item %s: %s", item, c.New)
+	case ChangeKindUpdateItem:
+		return fmt.Sprintf("~ item %s %s: %q -> %q", item, c.Field, c.Old, c.New)
+	case ChangeKindCreateParent:
+		return fmt.Sprintf("+ parent %s -> %s", item, c.New)
+	case ChangeKindUpdateLanguage:
+		return fmt.Sprintf("~ language %s %s %s: %q -> %q", item, c.Language, c.Field, c.Old, c.New)
+	case ChangeKindAddVehicleType:
+		return fmt.Sprintf("+ vehicle type %s %s", item, c.New)
+	}
+
+	return fmt.Sprintf("%s %s", c.Kind, item)
+}
+
+// Plan is a result of matching file against catalogue. It is applied as is.
+type Plan struct {
+	Changes []Change
+	items   []*plannedItem
+	// order is items of file with parents going before their childs.
+	order []*plannedItem
+}
+
+type plannedItem struct {
+	key          string
+	id           int64
+	row          schema.ItemRow
+	mask         []string
+	parents      []plannedParent
+	languages    []plannedLanguage
+	vehicleTypes []int64
+	// scope is ids of existing items the item is matched among descendants of.
+	scope   []int64
+	changes []Change
+}
+
+type plannedParent struct {
+	key     string
+	id      int64
+	typeID  schema.ItemParentType
+	catname string
+	label   string
+}
+
+type plannedLanguage struct {
+	language string
+	name     string
+	text     string
+	fullText string
+}
+
+// Validator checks values with the same rules as API does and returns descriptions of problems found.
+type Validator interface {
+	// ValidateItem checks fields of mask, all fields of new item are checked when mask is nil.
+	ValidateItem(ctx context.Context, row schema.ItemRow, mask []string) ([]string, error)
+	ValidateLanguage(name, text, fullText string) ([]string, error)
+}
+
+// EventLog records applied changes in log of events.
+type EventLog interface {
+	AddItemEvent(ctx context.Context, userID int64, message string, itemIDs []int64) error
+}
+
+type Importer struct {
+	db                    *goqu.Database
+	repository            *items.Repository
+	attrsRepository       *attrs.Repository
+	textStorageRepository *textstorage.Repository
+	searchIndexer         *search.Indexer
+	validator             Validator
+	eventLog              EventLog
+}
+
+func NewImporter(
+	db *goqu.Database, repository *items.Repository, attrsRepository *attrs.Repository,
+	textStorageRepository *textstorage.Repository, searchIndexer *search.Indexer, validator Validator,
+	eventLog EventLog,
+) *Importer {
+	return &Importer{
+		db:                    db,
+		repository:            repository,
+		attrsRepository:       attrsRepository,
+		textStorageRepository: textStorageRepository,
+		searchIndexer:         searchIndexer,
+		validator:             validator,
+		eventLog:              eventLog,
+	}
+}
+
+// ReadFile parses YAML or JSON file, unknown fields are rejected.
+func ReadFile(path string) (*File, error) {
+	reader, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer util.Close(reader)
+
+	decoder := yaml.NewDecoder(reader)
+	decoder.KnownFields(true)
+
+	var file File
+
+	err = decoder.Decode(&file)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidFile, err)
+	}
+
+	return &file, nil
+}
+
+// Plan matches items of file with existing ones by catname or name and collects changes required.
+// Items with parents are matched among descendants of their parents, so parents are planned first.
+func (s *Importer) Plan(ctx context.Context, file *File) (*Plan, error) {
+	plan := Plan{items: make([]*plannedItem, len(file.Items))}
+	byKey := make(map[string]int, len(file.Items))
+
+	for idx := range file.Items {
+		key := itemKey(&file.Items[idx])
+
+		if _, ok := byKey[key]; ok {
+			return nil, fmt.Errorf("%w: duplicate key `%s`", ErrInvalidFile, key)
+		}
+
+		byKey[key] = idx
+	}
+
+	visiting := make(map[int]bool, len(file.Items))
+
+	for idx := range file.Items {
+		_, err := s.planItem(ctx, file, idx, byKey, visiting, &plan)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for _, planned := range plan.items {
+		plan.Changes = append(plan.Changes, planned.changes...)
+		planned.changes = nil
+	}
+
+	for _, planned := range plan.items {
+		err := s.planParents(ctx, planned, &plan)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for idx, planned := range plan.items {
+		err := s.planLanguages(ctx, &file.Items[idx], planned, &plan)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	for idx, planned := range plan.items {
+		err := s.planVehicleTypes(ctx, &file.Items[idx], planned, &plan)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &plan, nil
+}
+
+func itemKey(item *Item) string {
+	key := item.Key
+	if key == "" {
+		key = item.Catname
+	}
+
+	if key == "" {
+		key = item.Name
+	}
+
+	return key
+}
+
+func (s *Importer) planItem(
+	ctx context.Context, file *File, idx int, byKey map[string]int, visiting map[int]bool, plan *Plan,
+) (*plannedItem, error) {
+	if plan.items[idx] != nil {
+		return plan.items[idx], nil
+	}
+
+	item := &file.Items[idx]
+	key := itemKey(item)
+
+	if visiting[idx] {
+		return nil, fmt.Errorf("%w: `%s` is a parent of itself", ErrInvalidFile, key)
+	}
+
+	visiting[idx] = true
+
+	typeID, ok := itemTypes[item.Type]
+	if !ok {
+		return nil, fmt.Errorf("%w: unknown type `%s` of `%s`", ErrInvalidFile, item.Type, item.Name)
+	}
+
+	if item.Name == "" {
+		return nil, fmt.Errorf("%w: name is required", ErrInvalidFile)
+	}
+
+	row, values := itemRow(item)
+	row.ItemTypeID = typeID
+	planned := &plannedItem{key: key, row: row}
+
+	err := s.resolveParents(ctx, file, item, planned, byKey, visiting, plan)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := s.match(ctx, item, typeID, planned)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", key, err)
+	}
+
+	plan.items[idx] = planned
+	plan.order = append(plan.order, planned)
+
+	if existing == 0 {
+		err = s.validateItem(ctx, key, planned.row, nil)
+		if err != nil {
+			return nil, err
+		}
+
+		planned.row.AddDatetime = sql.NullTime{Valid: true, Time: time.Now()}
+		planned.changes = append(planned.changes, Change{
+			Kind: ChangeKindCreateItem,
+			Key:  key,
+			New:  fmt.Sprintf("%s %q", item.Type, item.Name),
+		})
+
+		return planned, nil
+	}
+
+	planned.id = existing
+	planned.row.ID = existing
+
+	cols := make([]string, 0, len(values))
+	for col := range values {
+		cols = append(cols, col)
+	}
+
+	slices.Sort(cols)
+
+	oldValues, err := s.repository.ItemRevisionValues(ctx, existing, cols)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, col := range cols {
+		newValue := items.RevisionValue(values[col])
+		if oldValues[col] == newValue {
+			continue
+		}
+
+		planned.mask = append(planned.mask, col)
+		planned.changes = append(planned.changes, Change{
+			Kind:   ChangeKindUpdateItem,
+			Key:    key,
+			ItemID: existing,
+			Field:  col,
+			Old:    oldValues[col].String,
+			New:    newValue.String,
+		})
+	}
+
+	if len(planned.mask) > 0 {
+		err = s.validateItem(ctx, key, planned.row, planned.mask)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return planned, nil
+}
+
+func (s *Importer) validateItem(ctx context.Context, key string, row schema.ItemRow, mask []string) error {
+	problems, err := s.validator.ValidateItem(ctx, row, mask)
+	if err != nil {
+		return err
+	}
+
+	if len(problems) > 0 {
+		return fmt.Errorf("%w: `%s`: %s", ErrInvalidFile, key, strings.Join(problems, "; "))
+	}
+
+	return nil
+}
+
+// resolveParents finds parents of item, plans parents from the same file first, and collects scope of matching:
+// existing parents and, for parents created by import, their own scope.
+func (s *Importer) resolveParents(
+	ctx context.Context, file *File, item *Item, planned *plannedItem, byKey map[string]int, visiting map[int]bool,
+	plan *Plan,
+) error {
+	for _, parent := range item.Parents {
+		typeID, ok := itemParentTypes[parent.Type]
+		if !ok {
+			return fmt.Errorf("%w: unknown parent type `%s` of `%s`", ErrInvalidFile, parent.Type, planned.key)
+		}
+
+		ref := plannedParent{typeID: typeID, catname: parent.Catname}
+		parentIdx, inFile := byKey[parent.Item]
+
+		switch {
+		case parent.ID > 0:
+			_, err := s.repository.Item(ctx, &query.ItemListOptions{ItemID: parent.ID}, nil)
+			if err != nil {
+				return fmt.Errorf("%s: parent #%d: %w", planned.key, parent.ID, err)
+			}
+
+			ref.id = parent.ID
+			ref.label = fmt.Sprintf("#%d", parent.ID)
+		case inFile:
+			parentPlanned, err := s.planItem(ctx, file, parentIdx, byKey, visiting, plan)
+			if err != nil {
+				return err
+			}
+
+			ref.key = parent.Item
+			ref.id = parentPlanned.id
+			ref.label = parent.Item
+
+			if ref.id == 0 {
+				planned.scope = append(planned.scope, parentPlanned.scope...)
+			}
+		default:
+			existing, err := s.repository.Item(ctx, &query.ItemListOptions{Catname: parent.Item}, nil)
+			if err != nil {
+				if errors.Is(err, items.ErrItemNotFound) {
+					return fmt.Errorf("%w: parent `%s` of `%s`", ErrUnresolvedRef, parent.Item, planned.key)
+				}
+
+				return err
+			}
+
+			ref.id = existing.ID
+			ref.label = parent.Item
+		}
+
+		if ref.id > 0 {
+			planned.scope = append(planned.scope, ref.id)
+		}
+
+		planned.parents = append(planned.parents, ref)
+	}
+
+	planned.scope = util.RemoveDuplicate(planned.scope)
+
+	return nil
+}
+
+// match returns id of existing item with same catname or, if catname is not given, with same name.
+// Name is compared case-insensitively. Item with parents is looked for among descendants of its scope only,
+// item which parents are all created by import is new.
+func (s *Importer) match(
+	ctx context.Context, item *Item, typeID schema.ItemTableItemTypeID, planned *plannedItem,
+) (int64, error) {
+	options := query.ItemListOptions{
+		TypeID: []schema.ItemTableItemTypeID{typeID},
+		Limit:  2, //nolint: mnd
+	}
+
+	if len(item.Parents) > 0 {
+		if len(planned.scope) == 0 {
+			return 0, nil
+		}
+
+		options.ItemParentCacheAncestor = &query.ItemParentCacheListOptions{ParentIDs: planned.scope}
+	}
+
+	if item.Catname != "" {
+		options.Catname = item.Catname
+	} else {
+		options.Name = likeEscaper.Replace(item.Name)
+	}
+
+	rows, _, err := s.repository.List(ctx, &options, nil, items.OrderByNone, false)
+	if err != nil {
+		return 0, err
+	}
+
+	switch len(rows) {
+	case 0:
+		return 0, nil
+	case 1:
+		return rows[0].ID, nil
+	}
+
+	return 0, ErrAmbiguousMatch
+}
+
+// planParents keeps links which don't exist yet.
+func (s *Importer) planParents(ctx context.Context, planned *plannedItem, plan *Plan) error {
+	refs := planned.parents
+	planned.parents = nil
+
+	for _, ref := range refs {
+		if planned.id > 0 && ref.id > 0 {
+			_, err := s.repository.ItemParent(ctx, &query.ItemParentListOptions{
+				ItemID:   planned.id,
+				ParentID: ref.id,
+			}, items.ItemParentFields{})
+			if err == nil {
+				continue
+			}
+
+			if !errors.Is(err, items.ErrItemNotFound) {
+				return err
+			}
+		}
+
+		planned.parents = append(planned.parents, ref)
+		plan.Changes = append(plan.Changes, Change{
+			Kind:   ChangeKindCreateParent,
+			Key:    planned.key,
+			ItemID: planned.id,
+			New:    ref.label,
+		})
+	}
+
+	return nil
+}
+
+func (s *Importer) planLanguages(ctx context.Context, item *Item, planned *plannedItem, plan *Plan) error {
+	current := make(map[string]Language)
+
+	if planned.id > 0 {
+		rows, err := s.repository.ItemLanguageList(ctx, planned.id)
+		if err != nil {
+			return err
+		}
+
+		for _, row := range rows {
+			value := Language{Name: row.Name}
+
+			if row.TextID > 0 {
+				value.Text, err = s.textStorageRepository.Text(ctx, row.TextID)
+				if err != nil {
+					return err
+				}
+			}
+
+			if row.FullTextID > 0 {
+				value.FullText, err = s.textStorageRepository.Text(ctx, row.FullTextID)
+				if err != nil {
+					return err
+				}
+			}
+
+			current[row.Language] = value
+		}
+	}
+
+	languages := make([]string, 0, len(item.Languages))
+	for language := range item.Languages {
+		languages = append(languages, language)
+	}
+
+	slices.Sort(languages)
+
+	for _, language := range languages {
+		value := item.Languages[language]
+		old := current[language]
+
+		problems, err := s.validator.ValidateLanguage(value.Name, value.Text, value.FullText)
+		if err != nil {
+			return err
+		}
+
+		if len(problems) > 0 {
+			return fmt.Errorf(
+				"%w: `%s` language `%s`: %s", ErrInvalidFile, planned.key, language, strings.Join(problems, "; "),
+			)
+		}
+		changed := false
+
+		for _, field := range []struct {
+			name               string
+			oldValue, newValue string
+			target             *string
+		}{
+			{items.ItemLanguageRevisionFieldName, old.Name, value.Name, &value.Name},
+			{items.ItemLanguageRevisionFieldText, old.Text, value.Text, &value.Text},
+			{items.ItemLanguageRevisionFieldFullText, old.FullText, value.FullText, &value.FullText},
+		} {
+			if field.newValue == "" {
+				*field.target = field.oldValue
+
+				continue
+			}
+
+			if field.newValue == field.oldValue {
+				continue
+			}
+
+			changed = true
+
+			plan.Changes = append(plan.Changes, Change{
+				Kind:     ChangeKindUpdateLanguage,
+				Key:      planned.key,
+				ItemID:   planned.id,
+				Field:    field.name,
+				Language: language,
+				Old:      field.oldValue,
+				New:      field.newValue,
+			})
+		}
+
+		if changed {
+			planned.languages = append(planned.languages, plannedLanguage{
+				language: language,
+				name:     value.Name,
+				text:     value.Text,
+				fullText: value.FullText,
+			})
+		}
+	}
+
+	return nil
+}
+
+func (s *Importer) planVehicleTypes(ctx context.Context, item *Item, planned *plannedItem, plan *Plan) error {
+	var current []int64
+
+	if planned.id > 0 {
+		var err error
+
+		current, err = s.repository.ItemVehicleTypeIDs(ctx, planned.id)
+		if err != nil {
+			return err
+		}
+	}
+
+	for _, catname := range item.VehicleTypes {
+		vehicleType, err := s.repository.VehicleType(ctx, &query.VehicleTypeListOptions{Catname: catname})
+		if err != nil {
+			if errors.Is(err, sql.ErrNoRows) {
+				return fmt.Errorf("%w: vehicle type `%s` of `%s`", ErrUnresolvedRef, catname, planned.key)
+			}
+
+			return err
+		}
+
+		if slices.Contains(current, vehicleType.ID) || slices.Contains(planned.vehicleTypes, vehicleType.ID) {
+			continue
+		}
+
+		planned.vehicleTypes = append(planned.vehicleTypes, vehicleType.ID)
+		plan.Changes = append(plan.Changes, Change{
+			Kind:   ChangeKindAddVehicleType,
+			Key:    planned.key,
+			ItemID: planned.id,
+			New:    catname,
+		})
+	}
+
+	return nil
+}
+
+// Apply performs planned changes in a single transaction: creates and updates items first, then links them,
+// fills languages and vehicle types. Values are validated by Plan already. Caches, specifications, search index
+// and log of events are updated after commit, their failures are logged. Returns ids of items by keys.
+func (s *Importer) Apply(ctx context.Context, plan *Plan, userID int64) (map[string]int64, error) {
+	ids := make(map[string]int64, len(plan.items))
+	ctx = context.WithoutCancel(ctx)
+
+	err := s.db.WithTx(func(tx *goqu.TxDatabase) error {
+		return s.apply(ctx, tx, plan, ids, userID)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	changes := make(map[string][]string, len(plan.items))
+	for _, change := range plan.Changes {
+		changes[change.Key] = append(changes[change.Key], change.String())
+	}
+
+	for _, planned := range plan.order {
+		s.afterApply(ctx, planned, ids, changes[planned.key], userID)
+	}
+
+	return ids, nil
+}
+
+func (s *Importer) apply(
+	ctx context.Context, tx *goqu.TxDatabase, plan *Plan, ids map[string]int64, userID int64,
+) error {
+	for _, planned := range plan.items {
+		var (
+			itemID = planned.id
+			err    error
+		)
+
+		switch {
+		case itemID == 0:
+			itemID, err = s.repository.CreateItemTx(ctx, tx, planned.row)
+		case len(planned.mask) > 0:
+			err = s.repository.UpdateItemTx(ctx, tx, planned.row, planned.mask, userID)
+		}
+
+		if err != nil {
+			return fmt.Errorf("%s: %w", planned.key, err)
+		}
+
+		ids[planned.key] = itemID
+	}
+
+	for _, planned := range plan.items {
+		itemID := ids[planned.key]
+
+		for _, parent := range planned.parents {
+			parentID := parent.id
+			if parentID == 0 {
+				parentID = ids[parent.key]
+			}
+
+			if parentID == 0 {
+				return fmt.Errorf("%s: %w", planned.key, errUnexpectedState)
+			}
+
+			_, err := s.repository.CreateItemParentTx(
+				ctx, tx, itemID, parentID, parent.typeID, parent.catname, userID,
+			)
+			if err != nil {
+				return fmt.Errorf("%s: %w", planned.key, err)
+			}
+		}
+
+		for _, language := range planned.languages {
+			_, _, err := s.repository.UpdateItemLanguageTx(
+				ctx, tx, itemID, language.language, language.name, language.text, language.fullText, userID,
+			)
+			if err != nil {
+				return fmt.Errorf("%s: %w", planned.key, err)
+			}
+		}
+
+		for _, vehicleTypeID := range planned.vehicleTypes {
+			_, err := s.repository.AddItemVehicleTypeTx(ctx, tx, itemID, vehicleTypeID)
+			if err != nil {
+				return fmt.Errorf("%s: %w", planned.key, err)
+			}
+		}
+	}
+
+	return nil
+}
+
+// afterApply refreshes data derived from committed item, parents are processed before their childs.
+func (s *Importer) afterApply(
+	ctx context.Context, planned *plannedItem, ids map[string]int64, changes []string, userID int64,
+) {
+	created := planned.id == 0
+	if !created && len(planned.mask) == 0 && len(planned.parents) == 0 && len(planned.languages) == 0 &&
+		len(planned.vehicleTypes) == 0 {
+		return
+	}
+
+	itemID := ids[planned.key]
+
+	err := s.repository.RefreshItemCaches(ctx, itemID)
+	if err != nil {
+		logrus.Errorf("importer: failed to refresh caches of item %d: %s", itemID, err.Error())
+	}
+
+	_, err = s.repository.RefreshAutoByVehicle(ctx, itemID)
+	if err != nil {
+		logrus.Errorf("importer: failed to refresh auto by vehicle of item %d: %s", itemID, err.Error())
+	}
+
+	if created {
+		err = s.attrsRepository.UpdateInheritedValues(ctx, itemID)
+		if err != nil {
+			logrus.Errorf("importer: failed to update inherited values of item %d: %s", itemID, err.Error())
+		}
+	}
+
+	if len(planned.parents) > 0 {
+		err = s.attrsRepository.UpdateActualValues(ctx, itemID)
+		if err != nil {
+			logrus.Errorf("importer: failed to update actual values of item %d: %s", itemID, err.Error())
+		}
+	}
+
+	if created || len(planned.mask) > 0 {
+		err = s.repository.UserItemSubscribe(ctx, itemID, userID)
+		if err != nil {
+			logrus.Errorf("importer: failed to subscribe user %d to item %d: %s", userID, itemID, err.Error())
+		}
+	}
+
+	s.searchIndexer.Refresh(ctx, search.KindItem, itemID)
+
+	message := "Редактирование мета-информации автомобиля " + html.EscapeString(planned.row.Name)
+	if created {
+		message = "Создан новый автомобиль " + html.EscapeString(planned.row.Name)
+	}
+
+	htmlChanges := make([]string, 0, len(changes))
+	for _, change := range changes {
+		htmlChanges = append(htmlChanges, html.EscapeString(change))
+	}
+
+	message += "<p>Импорт: " + strings.Join(htmlChanges, "<br />") + "</p>"
+
+	itemIDs := []int64{itemID}
+	for _, parent := range planned.parents {
+		if parent.id > 0 {
+			itemIDs = append(itemIDs, parent.id)
+		} else {
+			itemIDs = append(itemIDs, ids[parent.key])
+		}
+	}
+
+	err = s.eventLog.AddItemEvent(ctx, userID, message, itemIDs)
+	if err != nil {
+		logrus.Errorf("importer: failed to log event of item %d: %s", itemID, err.Error())
+	}
+}
+
+// itemRow converts item to row and collects specified values by column names.
+func itemRow(item *Item) (schema.ItemRow, map[string]any) {
+	row := schema.ItemRow{
+		Name: item.Name,
+		Catname: sql.NullString{
+			Valid:  item.Catname != "",
+			String: item.Catname,
+		},
+		FullName: sql.NullString{
+			Valid:  item.FullName != "",
+			String: item.FullName,
+		},
+		Body:           item.Body,
+		BeginYear:      sql.NullInt32{Valid: item.BeginYear > 0, Int32: item.BeginYear},
+		BeginMonth:     sql.NullInt16{Valid: item.BeginMonth > 0, Int16: item.BeginMonth},
+		EndYear:        sql.NullInt32{Valid: item.EndYear > 0, Int32: item.EndYear},
+		EndMonth:       sql.NullInt16{Valid: item.EndMonth > 0, Int16: item.EndMonth},
+		BeginModelYear: sql.NullInt32{Valid: item.BeginModelYear > 0, Int32: item.BeginModelYear},
+		EndModelYear:   sql.NullInt32{Valid: item.EndModelYear > 0, Int32: item.EndModelYear},
+	}
+
+	values := map[string]any{schema.ItemTableNameColName: row.Name}
+
+	if row.Catname.Valid {
+		values[schema.ItemTableCatnameColName] = row.Catname
+	}
+
+	if row.FullName.Valid {
+		values[schema.ItemTableFullNameColName] = row.FullName
+	}
+
+	if row.Body != "" {
+		values[schema.ItemTableBodyColName] = row.Body
+	}
+
+	for col, value := range map[string]driver.Valuer{
+		schema.ItemTableBeginYearColName:      row.BeginYear,
+		schema.ItemTableBeginMonthColName:     row.BeginMonth,
+		schema.ItemTableEndYearColName:        row.EndYear,
+		schema.ItemTableEndMonthColName:       row.EndMonth,
+		schema.ItemTableBeginModelYearColName: row.BeginModelYear,
+		schema.ItemTableEndModelYearColName:   row.EndModelYear,
+	} {
+		if v, _ := value.Value(); v != nil {
+			values[col] = value
+		}
+	}
+
+	if item.Today != nil {
+		row.Today = sql.NullBool{Valid: true, Bool: *item.Today}
+		values[schema.ItemTableTodayColName] = row.Today
+	}
+
+	if item.Produced != nil {
+		row.Produced = sql.NullInt32{Valid: true, Int32: *item.Produced}
+		values[schema.ItemTableProducedColName] = row.Produced
+	}
+
+	if item.ProducedExactly != nil {
+		row.ProducedExactly = *item.ProducedExactly
+		values[schema.ItemTableProducedExactlyColName] = row.ProducedExactly
+	}
+
+	if item.IsConcept != nil {
+		row.IsConcept = *item.IsConcept
+		values[schema.ItemTableIsConceptColName] = row.IsConcept
+	}
+
+	if item.IsGroup != nil {
+		row.IsGroup = *item.IsGroup
+		values[schema.ItemTableIsGroupColName] = row.IsGroup
+	}
+
+	return row, values
+}
@@ -0,0 +1,102 @@
+package importer
+
+import (
+	"database/sql"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/autowp/goautowp/schema"
+	"github.com/stretchr/testify/require"
+)
+
+func TestReadFile(t *testing.T) {
+	t.Parallel()
+
+	dir := t.TempDir()
+
+	yamlPath := filepath.Join(dir, "line.yaml")
+	err := os.WriteFile(yamlPath, []byte(`
+items:
+  - key: line
+    type: vehicle
+    name: Golf
+    is_group: true
+    parents:
+      - item: volkswagen
+  - type: vehicle
+    catname: golf-1
+    name: Golf I
+    begin_year: 1974
+    end_year: 1983
+    languages:
+      ru:
+        name: Гольф I
+    parents:
+      - item: line
+        type: sport
+    vehicle_types: [car]
+`), 0o600)
+	require.NoError(t, err)
+
+	file, err := ReadFile(yamlPath)
+	require.NoError(t, err)
+	require.Len(t, file.Items, 2)
+	require.Equal(t, "Гольф I", file.Items[1].Languages["ru"].Name)
+	require.Equal(t, []Parent{{Item: "line", Type: "sport"}}, file.Items[1].Parents)
+
+	jsonPath := filepath.Join(dir, "line.json")
+	err = os.WriteFile(jsonPath, []byte(`{"items": [{"type": "engine", "name": "EA827", "produced": 0}]}`), 0o600)
+	require.NoError(t, err)
+
+	file, err = ReadFile(jsonPath)
+	require.NoError(t, err)
+	require.Len(t, file.Items, 1)
+	require.NotNil(t, file.Items[0].Produced)
+
+	err = os.WriteFile(jsonPath, []byte(`{"items": [{"type": "engine", "title": "EA827"}]}`), 0o600)
+	require.NoError(t, err)
+
+	_, err = ReadFile(jsonPath)
+	require.ErrorIs(t, err, ErrInvalidFile)
+}
+
+func TestItemRow(t *testing.T) {
+	t.Parallel()
+
+	isGroup := false
+
+	row, values := itemRow(&Item{Name: "Golf I", Catname: "golf-1", BeginYear: 1974, IsGroup: &isGroup})
+	require.Equal(t, sql.NullInt32{Valid: true, Int32: 1974}, row.BeginYear)
+	require.False(t, row.EndYear.Valid)
+	require.Equal(t, map[string]any{
+		schema.ItemTableNameColName:      "Golf I",
+		schema.ItemTableCatnameColName:   sql.NullString{Valid: true, String: "golf-1"},
+		schema.ItemTableBeginYearColName: sql.NullInt32{Valid: true, Int32: 1974},
+		schema.ItemTableIsGroupColName:   false,
+	}, values)
+}
+
+func TestChangeString(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, `~ item golf-1 #5 begin_year: "1974" -> "1975"`, Change{
+		Kind:   ChangeKindUpdateItem,
+		Key:    "golf-1",
+		ItemID: 5,
+		Field:  schema.ItemTableBeginYearColName,
+		Old:    "1974",
+		New:    "1975",
+	}.String())
+	require.Equal(t, "+ parent golf-1 -> line", Change{
+		Kind: ChangeKindCreateParent,
+		Key:  "golf-1",
+		New:  "line",
+	}.String())
+}
+
+func TestLikeEscaper(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, `100\% Golf\_1 \\`, likeEscaper.Replace(`100% Golf_1 \`))
+}
@@ -670,3 +670,63 @@ func (s *APIItem) Validate( //nolint: maintidx
 
 	return result, nil
 }
+
+// importValidator checks items of catalogue import with the same rules as API does.
+type importValidator struct {
+	repository *items.Repository
+}
+
+func (s importValidator) ValidateItem(ctx context.Context, row schema.ItemRow, mask []string) ([]string, error) {
+	item := APIItem{
+		Id:              row.ID,
+		ItemTypeId:      extractItemTypeID(row.ItemTypeID),
+		Name:            row.Name,
+		FullName:        row.FullName.String,
+		Catname:         row.Catname.String,
+		Body:            row.Body,
+		BeginYear:       row.BeginYear.Int32,
+		BeginMonth:      int32(row.BeginMonth.Int16),
+		EndYear:         row.EndYear.Int32,
+		EndMonth:        int32(row.EndMonth.Int16),
+		BeginModelYear:  row.BeginModelYear.Int32,
+		EndModelYear:    row.EndModelYear.Int32,
+		IsConcept:       row.IsConcept,
+		ProducedExactly: row.ProducedExactly,
+		IsGroup:         row.IsGroup,
+	}
+
+	if row.Today.Valid {
+		item.Today = wrapperspb.Bool(row.Today.Bool)
+	}
+
+	if row.Produced.Valid {
+		item.Produced = wrapperspb.Int32(row.Produced.Int32)
+	}
+
+	violations, err := item.Validate(ctx, s.repository, mask, []string{users.RoleCarsModer})
+	if err != nil {
+		return nil, err
+	}
+
+	return fieldViolationProblems(violations), nil
+}
+
+func (s importValidator) ValidateLanguage(name, text, fullText string) ([]string, error) {
+	language := ItemLanguage{Name: name, Text: text, FullText: fullText}
+
+	violations, err := language.Validate()
+	if err != nil {
+		return nil, err
+	}
+
+	return fieldViolationProblems(violations), nil
+}
+
+func fieldViolationProblems(violations []*errdetails.BadRequest_FieldViolation) []string {
+	problems := make([]string, 0, len(violations))
+	for _, violation := range violations {
+		problems = append(problems, violation.GetField()+": "+violation.GetDescription())
+	}
+
+	return problems
+}
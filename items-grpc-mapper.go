@@ -1,11 +1,20 @@
 package goautowp
 
 import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"strconv"
+
 	"github.com/autowp/goautowp/items"
 	"github.com/autowp/goautowp/query"
 	"github.com/autowp/goautowp/schema"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
 )
 
+var errUnexpectedItemRevisionField = errors.New("unexpected item revision field")
+
 func convertChildsCounts(childCounts []items.ChildCount) []*ChildsCount {
 	result := make([]*ChildsCount, 0, len(childCounts))
 
@@ -368,3 +377,118 @@ func convertItemParentOrder(order ItemParentsRequest_Order) items.ItemParentOrde
 
 	return items.ItemParentOrderByNone
 }
+
+func extractItemRevisionEntity(entity string) ItemRevisionEntity {
+	switch entity {
+	case schema.ItemRevisionEntityItemLanguage:
+		return ItemRevisionEntity_ITEM_REVISION_ENTITY_ITEM_LANGUAGE
+	case schema.ItemRevisionEntityItemParent:
+		return ItemRevisionEntity_ITEM_REVISION_ENTITY_ITEM_PARENT
+	}
+
+	return ItemRevisionEntity_ITEM_REVISION_ENTITY_ITEM
+}
+
+func extractItemRevision(row schema.ItemRevisionRow) *ItemRevision {
+	result := &ItemRevision{
+		Id:        row.ID,
+		ItemId:    row.ItemID,
+		UserId:    row.UserID.Int64,
+		CreatedAt: timestamppb.New(row.CreatedAt),
+		Entity:    extractItemRevisionEntity(row.Entity),
+		Language:  row.Language.String,
+		ParentId:  row.ParentID.Int64,
+		Field:     row.Field,
+	}
+
+	if row.OldValue.Valid {
+		result.OldValue = wrapperspb.String(row.OldValue.String)
+	}
+
+	if row.NewValue.Valid {
+		result.NewValue = wrapperspb.String(row.NewValue.String)
+	}
+
+	return result
+}
+
+// applyItemRevisionValue sets field of item to the value stored in revision.
+func applyItemRevisionValue(item *APIItem, field string, value sql.NullString) error { //nolint: maintidx
+	var (
+		intValue int64
+		err      error
+	)
+
+	if value.Valid {
+		switch field {
+		case schema.ItemTableNameColName, schema.ItemTableFullNameColName, schema.ItemTableBodyColName,
+			schema.ItemTableCatnameColName, schema.ItemTableBeginModelYearFractionColName,
+			schema.ItemTableEndModelYearFractionColName:
+		default:
+			intValue, err = strconv.ParseInt(value.String, 10, 64)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	boolValue := intValue != 0
+	int32Value := int32(intValue) //nolint: gosec
+
+	switch field {
+	case schema.ItemTableNameColName:
+		item.Name = value.String
+	case schema.ItemTableFullNameColName:
+		item.FullName = value.String
+	case schema.ItemTableBodyColName:
+		item.Body = value.String
+	case schema.ItemTableCatnameColName:
+		item.Catname = value.String
+	case schema.ItemTableBeginModelYearFractionColName:
+		item.BeginModelYearFraction = value.String
+	case schema.ItemTableEndModelYearFractionColName:
+		item.EndModelYearFraction = value.String
+	case schema.ItemTableBeginYearColName:
+		item.BeginYear = int32Value
+	case schema.ItemTableBeginMonthColName:
+		item.BeginMonth = int32Value
+	case schema.ItemTableEndYearColName:
+		item.EndYear = int32Value
+	case schema.ItemTableEndMonthColName:
+		item.EndMonth = int32Value
+	case schema.ItemTableBeginModelYearColName:
+		item.BeginModelYear = int32Value
+	case schema.ItemTableEndModelYearColName:
+		item.EndModelYear = int32Value
+	case schema.ItemTableSpecIDColName:
+		item.SpecId = int32Value
+	case schema.ItemTableEngineItemIDColName:
+		item.EngineItemId = intValue
+	case schema.ItemTableTodayColName:
+		item.Today = nil
+		if value.Valid {
+			item.Today = wrapperspb.Bool(boolValue)
+		}
+	case schema.ItemTableProducedColName:
+		item.Produced = nil
+		if value.Valid {
+			item.Produced = wrapperspb.Int32(int32Value)
+		}
+	case schema.ItemTableIsConceptColName:
+		item.IsConcept = boolValue
+	case schema.ItemTableIsConceptInheritColName:
+		item.IsConceptInherit = boolValue
+	case schema.ItemTableProducedExactlyColName:
+		item.ProducedExactly = boolValue
+	case schema.ItemTableIsGroupColName:
+		item.IsGroup = boolValue
+	case schema.ItemTableSpecInheritColName:
+		item.SpecInherit = boolValue
+	case schema.ItemTableEngineInheritColName:
+		item.EngineInherit = boolValue
+	default:
+		return fmt.Errorf("%w: `%s`", errUnexpectedItemRevisionField, field)
+	}
+
+	return nil
+}
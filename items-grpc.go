@@ -13,6 +13,7 @@ import (
 	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/autowp/goautowp/attrs"
 	"github.com/autowp/goautowp/frontend"
@@ -25,6 +26,7 @@ import (
 	"github.com/autowp/goautowp/pictures"
 	"github.com/autowp/goautowp/query"
 	"github.com/autowp/goautowp/schema"
+	"github.com/autowp/goautowp/search"
 	"github.com/autowp/goautowp/textstorage"
 	"github.com/autowp/goautowp/users"
 	"github.com/autowp/goautowp/util"
@@ -33,11 +35,13 @@ import (
 	"github.com/nicksnyder/go-i18n/v2/i18n"
 	geo "github.com/paulmach/go.geo"
 	"github.com/redis/go-redis/v9"
+	"github.com/sirupsen/logrus"
 	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/proto"
 	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/fieldmaskpb"
 )
 
 const (
@@ -50,6 +54,8 @@ const (
 	topSpecsContributorsValuesCountThreshold = 10
 	topSpecsContributorsInDays               = 3
 	topSpecsContributorsLimit                = 4
+
+	autocompleteSuggestionsLimit = 100
 )
 
 func (s *ItemParent) Validate() ([]*errdetails.BadRequest_FieldViolation, error) {
@@ -106,6 +112,7 @@ type ItemsGRPCServer struct {
 	linkExtractor         *LinkExtractor
 	itemOfDayRepository   *itemofday.Repository
 	redis                 *redis.Client
+	searchIndexer         *search.Indexer
 }
 
 func NewItemsGRPCServer(
@@ -127,6 +134,7 @@ func NewItemsGRPCServer(
 	linkExtractor *LinkExtractor,
 	itemOfDayRepository *itemofday.Repository,
 	redis *redis.Client,
+	searchIndexer *search.Indexer,
 ) *ItemsGRPCServer {
 	return &ItemsGRPCServer{
 		repository:            repository,
@@ -147,6 +155,7 @@ func NewItemsGRPCServer(
 		linkExtractor:         linkExtractor,
 		itemOfDayRepository:   itemOfDayRepository,
 		redis:                 redis,
+		searchIndexer:         searchIndexer,
 	}
 }
 
@@ -406,10 +415,20 @@ func (s *ItemsGRPCServer) Item(ctx context.Context, in *ItemRequest) (*APIItem,
 		return nil, status.Error(codes.PermissionDenied, "PermissionDenied")
 	}
 
-	res, err := s.repository.Item(ctx, &query.ItemListOptions{
+	options := query.ItemListOptions{
 		ItemID:   in.GetId(),
 		Language: in.GetLanguage(),
-	}, fields)
+	}
+
+	res, err := s.repository.Item(ctx, &options, fields)
+	if errors.Is(err, items.ErrItemNotFound) {
+		// merged item resolves to the item it was merged into
+		options.ItemID, err = s.repository.ItemRedirect(ctx, in.GetId())
+		if err == nil {
+			res, err = s.repository.Item(ctx, &options, fields)
+		}
+	}
+
 	if err != nil {
 		if errors.Is(err, items.ErrItemNotFound) {
 			return nil, status.Error(codes.NotFound, err.Error())
@@ -473,9 +492,45 @@ func (s *ItemsGRPCServer) List(ctx context.Context, in *ItemsRequest) (*APIItemL
 
 	order, options.SortByName = convertItemOrder(in.GetOrder())
 
-	res, pages, err := s.repository.List(ctx, options, fields, order, true)
-	if err != nil {
-		return nil, status.Error(codes.Internal, err.Error())
+	if options.Autocomplete != "" {
+		options.AutocompleteItemIDs, err = s.searchIndexer.Autocomplete().Suggest(
+			query.ParseAutocomplete(options.Autocomplete).Name, autocompleteSuggestionsLimit,
+		)
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+
+		// keep order of suggestions, which are ranked by relevance and popularity
+		if in.GetOrder() == ItemsRequest_DEFAULT && len(options.AutocompleteItemIDs) > 0 {
+			order = items.OrderByNone
+		}
+	}
+
+	var (
+		res        []*items.Item
+		pages      *util.Pages
+		nextCursor string
+	)
+
+	if in.GetCursor() != nil {
+		res, nextCursor, err = s.repository.ListByCursor(ctx, options, fields, order, in.GetCursor().GetValue())
+		if err != nil {
+			return nil, wrapCursorError(err)
+		}
+
+		if in.GetCount() {
+			count, err := s.repository.CountDistinct(ctx, *options)
+			if err != nil {
+				return nil, status.Error(codes.Internal, err.Error())
+			}
+
+			pages = &util.Pages{TotalItemCount: int32(count)} //nolint: gosec
+		}
+	} else {
+		res, pages, err = s.repository.List(ctx, options, fields, order, true)
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
 	}
 
 	is := make([]*APIItem, len(res))
@@ -503,8 +558,9 @@ func (s *ItemsGRPCServer) List(ctx context.Context, in *ItemsRequest) (*APIItemL
 	}
 
 	return &APIItemList{
-		Items:     is,
-		Paginator: paginator,
+		Items:      is,
+		Paginator:  paginator,
+		NextCursor: nextCursor,
 	}, nil
 }
 
@@ -1087,6 +1143,8 @@ func (s *ItemsGRPCServer) UpdateItemLanguage(
 		if err != nil {
 			return nil, status.Error(codes.Internal, err.Error())
 		}
+
+		s.searchIndexer.Refresh(ctx, search.KindItem, itemID)
 	}
 
 	return &emptypb.Empty{}, nil
@@ -1638,7 +1696,7 @@ func (s *ItemsGRPCServer) CreateItemParent(
 	ctx = context.WithoutCancel(ctx)
 
 	_, err = s.repository.CreateItemParent(
-		ctx, item.ID, parentItem.ID, convertItemParentType(in.GetType()), in.GetCatname(),
+		ctx, item.ID, parentItem.ID, convertItemParentType(in.GetType()), in.GetCatname(), userCtx.UserID,
 	)
 	if err != nil {
 		return nil, status.Error(codes.Internal, err.Error())
@@ -1718,6 +1776,8 @@ func (s *ItemsGRPCServer) UpdateItemParent(
 		return nil, wrapFieldViolations(InvalidParams)
 	}
 
+	ctx = context.WithoutCancel(ctx)
+
 	_, err = s.repository.UpdateItemParent(
 		ctx,
 		in.GetItemId(),
@@ -1725,6 +1785,7 @@ func (s *ItemsGRPCServer) UpdateItemParent(
 		convertItemParentType(in.GetType()),
 		in.GetCatname(),
 		false,
+		userCtx.UserID,
 	)
 	if err != nil {
 		return nil, status.Error(codes.Internal, err.Error())
@@ -1733,6 +1794,22 @@ func (s *ItemsGRPCServer) UpdateItemParent(
 	return &emptypb.Empty{}, nil
 }
 
+func (s *ItemsGRPCServer) itemParentRow(ctx context.Context, itemID, parentID int64) (*schema.ItemParentRow, error) {
+	row, err := s.repository.ItemParent(ctx, &query.ItemParentListOptions{
+		ItemID:   itemID,
+		ParentID: parentID,
+	}, items.ItemParentFields{})
+	if err != nil {
+		if errors.Is(err, items.ErrItemNotFound) {
+			return nil, nil //nolint: nilnil
+		}
+
+		return nil, err
+	}
+
+	return &row.ItemParentRow, nil
+}
+
 func (s *ItemsGRPCServer) DeleteItemParent(
 	ctx context.Context,
 	in *DeleteItemParentRequest,
@@ -1772,7 +1849,7 @@ func (s *ItemsGRPCServer) DeleteItemParent(
 
 	ctx = context.WithoutCancel(ctx)
 
-	err = s.repository.RemoveItemParent(ctx, item.ID, parent.ID)
+	err = s.repository.RemoveItemParent(ctx, item.ID, parent.ID, userCtx.UserID)
 	if err != nil {
 		return nil, status.Error(codes.Internal, err.Error())
 	}
@@ -1977,6 +2054,383 @@ func (s *ItemsGRPCServer) MoveItemParent(
 	return &emptypb.Empty{}, nil
 }
 
+func (s *ItemsGRPCServer) MergeItems(ctx context.Context, in *MergeItemsRequest) (*emptypb.Empty, error) {
+	userCtx, err := s.auth.ValidateGRPC(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if !util.Contains(userCtx.Roles, users.RoleCarsModer) {
+		return nil, status.Error(codes.PermissionDenied, "PermissionDenied")
+	}
+
+	names := make([]string, 0, 2) //nolint: mnd
+
+	for _, id := range []int64{in.GetSourceId(), in.GetTargetId()} {
+		item, err := s.repository.Item(
+			ctx, &query.ItemListOptions{ItemID: id, Language: EventsDefaultLanguage},
+			&items.ItemFields{NameText: true},
+		)
+		if err != nil {
+			if errors.Is(err, items.ErrItemNotFound) {
+				return nil, status.Error(codes.NotFound, err.Error())
+			}
+
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+
+		nameText, err := s.formatItemNameText(item, "en")
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+
+		names = append(names, nameText)
+	}
+
+	ctx = context.WithoutCancel(ctx)
+
+	merged, err := s.repository.MergeItems(ctx, in.GetSourceId(), in.GetTargetId())
+	if err != nil {
+		if errors.Is(err, items.ErrItemNotFound) {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+
+		if errors.Is(err, items.ErrMergeSameItem) || errors.Is(err, items.ErrMergeTypeMismatch) ||
+			errors.Is(err, items.ErrMergeRelated) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	err = s.repository.RefreshMergedItem(ctx, in.GetTargetId())
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	err = s.attrsRepository.UpdateActualValues(ctx, in.GetTargetId())
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	// childs inherit from target and vehicles take values of engine from target now
+	for _, id := range slices.Concat(merged.ChildIDs, merged.VehicleIDs) {
+		err = s.attrsRepository.UpdateActualValues(ctx, id)
+		if err != nil {
+			logrus.Errorf("items: failed to update actual values of item %d after merge: %s", id, err.Error())
+		}
+	}
+
+	// source is already deleted, so it is removed from search
+	for _, id := range []int64{in.GetSourceId(), in.GetTargetId()} {
+		s.searchIndexer.Refresh(ctx, search.KindItem, id)
+	}
+
+	err = s.events.Add(ctx, Event{
+		UserID: userCtx.UserID,
+		Message: fmt.Sprintf(
+			"%s объединён с %s (#%d)", html.EscapeString(names[1]), html.EscapeString(names[0]), in.GetSourceId(),
+		),
+		Items: []int64{in.GetTargetId()},
+	})
+	if err != nil {
+		logrus.Errorf("items: failed to log merge of item %d: %s", in.GetSourceId(), err.Error())
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (s *ItemsGRPCServer) SplitItem(ctx context.Context, in *SplitItemRequest) (*ItemID, error) {
+	userCtx, err := s.auth.ValidateGRPC(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if !util.Contains(userCtx.Roles, users.RoleCarsModer) {
+		return nil, status.Error(codes.PermissionDenied, "PermissionDenied")
+	}
+
+	if utf8.RuneCountInString(in.GetName()) > schema.ItemNameMaxLength {
+		return nil, status.Error(codes.InvalidArgument, "name is too long")
+	}
+
+	ctx = context.WithoutCancel(ctx)
+
+	itemID, err := s.repository.SplitItem(
+		ctx, in.GetItemId(), strings.TrimSpace(in.GetName()), in.GetChildIds(), in.GetPictureIds(), userCtx.UserID,
+	)
+	if err != nil {
+		if errors.Is(err, items.ErrItemNotFound) {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+
+		if errors.Is(err, items.ErrSplitChildNotFound) || errors.Is(err, items.ErrSplitPictureNotFound) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	err = s.attrsRepository.UpdateInheritedValues(ctx, itemID)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	for _, childID := range in.GetChildIds() {
+		err = s.attrsRepository.UpdateActualValues(ctx, childID)
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+	}
+
+	s.searchIndexer.Refresh(ctx, search.KindItem, itemID)
+
+	for _, pictureID := range in.GetPictureIds() {
+		s.searchIndexer.Refresh(ctx, search.KindPicture, pictureID)
+	}
+
+	names := make([]string, 0, 2) //nolint: mnd
+
+	for _, id := range []int64{in.GetItemId(), itemID} {
+		item, err := s.repository.Item(
+			ctx, &query.ItemListOptions{ItemID: id, Language: EventsDefaultLanguage},
+			&items.ItemFields{NameText: true},
+		)
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+
+		nameText, err := s.formatItemNameText(item, "en")
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+
+		names = append(names, nameText)
+	}
+
+	err = s.events.Add(ctx, Event{
+		UserID:   userCtx.UserID,
+		Message:  fmt.Sprintf("%s выделен из %s", html.EscapeString(names[1]), html.EscapeString(names[0])),
+		Items:    append([]int64{in.GetItemId(), itemID}, in.GetChildIds()...),
+		Pictures: in.GetPictureIds(),
+	})
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &ItemID{Id: itemID}, nil
+}
+
+func (s *ItemsGRPCServer) GetItemHistory(ctx context.Context, in *GetItemHistoryRequest) (*ItemRevisions, error) {
+	userCtx, err := s.auth.ValidateGRPC(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if !util.Contains(userCtx.Roles, users.RoleModer) {
+		return nil, status.Error(codes.PermissionDenied, "PermissionDenied")
+	}
+
+	if in.GetItemId() == 0 {
+		return nil, status.Error(codes.InvalidArgument, "item_id is zero")
+	}
+
+	rows, pages, err := s.repository.ItemRevisions(ctx, in.GetItemId(), in.GetPage())
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	result := make([]*ItemRevision, 0, len(rows))
+	for _, row := range rows {
+		result = append(result, extractItemRevision(row))
+	}
+
+	var paginator *Pages
+	if pages != nil {
+		paginator = &Pages{
+			PageCount:        pages.PageCount,
+			First:            pages.First,
+			Last:             pages.Last,
+			Current:          pages.Current,
+			FirstPageInRange: pages.FirstPageInRange,
+			LastPageInRange:  pages.LastPageInRange,
+			PagesInRange:     pages.PagesInRange,
+			TotalItemCount:   pages.TotalItemCount,
+			Next:             pages.Next,
+			Previous:         pages.Previous,
+		}
+	}
+
+	return &ItemRevisions{
+		Items:     result,
+		Paginator: paginator,
+	}, nil
+}
+
+// RevertItemRevision restores value changed by revision. Change is applied through the regular update methods,
+// so it is validated, logged and recorded as a new revision.
+func (s *ItemsGRPCServer) RevertItemRevision(
+	ctx context.Context, in *RevertItemRevisionRequest,
+) (*emptypb.Empty, error) {
+	userCtx, err := s.auth.ValidateGRPC(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if !util.Contains(userCtx.Roles, users.RoleCarsModer) {
+		return nil, status.Error(codes.PermissionDenied, "PermissionDenied")
+	}
+
+	revision, err := s.repository.ItemRevision(ctx, in.GetId())
+	if err != nil {
+		if errors.Is(err, items.ErrItemRevisionNotFound) {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	switch revision.Entity {
+	case schema.ItemRevisionEntityItem:
+		return s.revertItemRevision(ctx, revision)
+	case schema.ItemRevisionEntityItemLanguage:
+		return s.revertItemLanguageRevision(ctx, revision)
+	case schema.ItemRevisionEntityItemParent:
+		return s.revertItemParentRevision(ctx, revision)
+	}
+
+	return nil, status.Error(codes.InvalidArgument, "unexpected revision entity")
+}
+
+func (s *ItemsGRPCServer) revertItemRevision(
+	ctx context.Context, revision *schema.ItemRevisionRow,
+) (*emptypb.Empty, error) {
+	values, err := s.repository.ItemRevisionValues(ctx, revision.ItemID, items.ItemRevisionFields)
+	if err != nil {
+		if errors.Is(err, items.ErrItemNotFound) {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	values[revision.Field] = revision.OldValue
+	item := &APIItem{Id: revision.ItemID}
+
+	for field, value := range values {
+		err = applyItemRevisionValue(item, field, value)
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+	}
+
+	return s.UpdateItem(ctx, &UpdateItemRequest{
+		Item:       item,
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{revision.Field}},
+	})
+}
+
+func (s *ItemsGRPCServer) revertItemLanguageRevision(
+	ctx context.Context, revision *schema.ItemRevisionRow,
+) (*emptypb.Empty, error) {
+	languages, err := s.GetItemLanguages(ctx, &APIGetItemLanguagesRequest{ItemId: revision.ItemID})
+	if err != nil {
+		return nil, err
+	}
+
+	itemLanguage := &ItemLanguage{
+		ItemId:   revision.ItemID,
+		Language: revision.Language.String,
+	}
+
+	for _, row := range languages.GetItems() {
+		if row.GetLanguage() == itemLanguage.GetLanguage() {
+			itemLanguage = row
+		}
+	}
+
+	switch revision.Field {
+	case items.ItemLanguageRevisionFieldName:
+		itemLanguage.Name = revision.OldValue.String
+	case items.ItemLanguageRevisionFieldText:
+		itemLanguage.Text = revision.OldValue.String
+	case items.ItemLanguageRevisionFieldFullText:
+		itemLanguage.FullText = revision.OldValue.String
+	default:
+		return nil, status.Error(codes.InvalidArgument, "unexpected revision field")
+	}
+
+	return s.UpdateItemLanguage(ctx, itemLanguage)
+}
+
+func (s *ItemsGRPCServer) revertItemParentRevision(
+	ctx context.Context, revision *schema.ItemRevisionRow,
+) (*emptypb.Empty, error) {
+	itemID := revision.ItemID
+	parentID := revision.ParentID.Int64
+
+	parseType := func(value string) (ItemParentType, error) {
+		typeID, err := strconv.ParseInt(value, 10, 8)
+		if err != nil {
+			return ItemParentType_ITEM_TYPE_DEFAULT, status.Error(codes.Internal, err.Error())
+		}
+
+		return extractItemParentType(schema.ItemParentType(typeID)), nil
+	}
+
+	if revision.Field == items.ItemParentRevisionFieldLink {
+		if !revision.OldValue.Valid {
+			return s.DeleteItemParent(ctx, &DeleteItemParentRequest{ItemId: itemID, ParentId: parentID})
+		}
+
+		typeID, err := parseType(revision.OldValue.String)
+		if err != nil {
+			return nil, err
+		}
+
+		catname, err := s.repository.ItemParentLinkCatname(ctx, revision)
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
+
+		return s.CreateItemParent(ctx, &ItemParent{ItemId: itemID, ParentId: parentID, Type: typeID, Catname: catname})
+	}
+
+	if revision.Field == items.ItemParentRevisionFieldCatname && (!revision.OldValue.Valid || !revision.NewValue.Valid) {
+		return nil, status.Error(codes.InvalidArgument, "catname is changed along with link, revert link revision")
+	}
+
+	row, err := s.itemParentRow(ctx, itemID, parentID)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if row == nil {
+		return nil, status.Error(codes.FailedPrecondition, "item is not linked to parent anymore")
+	}
+
+	itemParent := &ItemParent{
+		ItemId:   itemID,
+		ParentId: parentID,
+		Type:     extractItemParentType(row.Type),
+		Catname:  row.Catname,
+	}
+
+	switch revision.Field {
+	case items.ItemParentRevisionFieldType:
+		itemParent.Type, err = parseType(revision.OldValue.String)
+		if err != nil {
+			return nil, err
+		}
+	case items.ItemParentRevisionFieldCatname:
+		itemParent.Catname = revision.OldValue.String
+	default:
+		return nil, status.Error(codes.InvalidArgument, "unexpected revision field")
+	}
+
+	return s.UpdateItemParent(ctx, itemParent)
+}
+
 func (s *ItemsGRPCServer) RefreshInheritance(
 	ctx context.Context, in *RefreshInheritanceRequest,
 ) (*emptypb.Empty, error) {
@@ -2680,11 +3134,22 @@ func (s *ItemsGRPCServer) GetPath(ctx context.Context, in *PathRequest) (*PathRe
 	}
 	convertedFields := convertItemFields(&fields)
 
-	currentCategory, err := s.repository.Item(ctx, &query.ItemListOptions{
+	categoryOptions := query.ItemListOptions{
 		Language: lang,
 		TypeID:   []schema.ItemTableItemTypeID{schema.ItemTableItemTypeIDCategory},
 		Catname:  in.GetCatname(),
-	}, convertedFields)
+	}
+
+	currentCategory, err := s.repository.Item(ctx, &categoryOptions, convertedFields)
+	if errors.Is(err, items.ErrItemNotFound) {
+		// catname of merged category
+		categoryOptions.ItemID, err = s.repository.CatnameRedirect(ctx, in.GetCatname())
+		if err == nil {
+			categoryOptions.Catname = ""
+			currentCategory, err = s.repository.Item(ctx, &categoryOptions, convertedFields)
+		}
+	}
+
 	if err != nil {
 		if errors.Is(err, items.ErrItemNotFound) {
 			return nil, status.Error(codes.NotFound, "category not found")
@@ -2736,13 +3201,28 @@ func (s *ItemsGRPCServer) GetPath(ctx context.Context, in *PathRequest) (*PathRe
 
 	currentCar := currentCategory
 	for _, pathNode := range path {
+		parentID := currentCar.ID
+
 		currentCar, err = s.repository.Item(ctx, &query.ItemListOptions{
 			Language: lang,
 			ItemParentParent: &query.ItemParentListOptions{
-				ParentID: currentCar.ID,
+				ParentID: parentID,
 				Catname:  pathNode,
 			},
 		}, convertedFields)
+		if errors.Is(err, items.ErrItemNotFound) {
+			// path node of merged item or of child moved during merge
+			var itemID int64
+
+			itemID, err = s.repository.ItemParentRedirect(ctx, parentID, pathNode)
+			if err == nil {
+				currentCar, err = s.repository.Item(ctx, &query.ItemListOptions{
+					Language: lang,
+					ItemID:   itemID,
+				}, convertedFields)
+			}
+		}
+
 		if err != nil {
 			if errors.Is(err, items.ErrItemNotFound) {
 				return nil, status.Error(codes.NotFound, "path node not found")
@@ -2960,6 +3440,8 @@ func (s *ItemsGRPCServer) CreateItem(ctx context.Context, in *APIItem) (*ItemID,
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
+	s.searchIndexer.Refresh(ctx, search.KindItem, itemID)
+
 	err = s.events.Add(ctx, Event{
 		UserID:  userCtx.UserID,
 		Message: "Создан новый автомобиль " + html.EscapeString(itemNameText),
@@ -3194,6 +3676,10 @@ func (s *ItemsGRPCServer) UpdateItem( //nolint: maintidx
 		}
 	}
 
+	if util.Contains(mask.GetPaths(), "name") {
+		s.searchIndexer.Refresh(ctx, search.KindItem, item.ID)
+	}
+
 	if notifyMeta {
 		item, err = s.repository.Item(
 			ctx,
@@ -1,10 +1,12 @@
 package goautowp
 
 import (
+	"database/sql"
 	"fmt"
 	"math/rand"
 	"slices"
 	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -13,7 +15,9 @@ import (
 	"github.com/autowp/goautowp/query"
 	"github.com/autowp/goautowp/schema"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
 	"google.golang.org/protobuf/types/known/fieldmaskpb"
 	"google.golang.org/protobuf/types/known/wrapperspb"
@@ -1061,6 +1065,405 @@ func TestCreateMoveDeleteItemParent(t *testing.T) {
 	require.Empty(t, res.GetItems())
 }
 
+func TestMergeItems(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.LoadConfig(".")
+
+	goquDB, err := cnt.GoquDB()
+	require.NoError(t, err)
+
+	ctx := t.Context()
+
+	random := rand.New(rand.NewSource(time.Now().UnixNano())) //nolint:gosec
+	categoryCatname := fmt.Sprintf("category-%d", random.Int())
+
+	categoryID := createItem(t, conn, cnt, &APIItem{
+		Name:       fmt.Sprintf("category-%d", random.Int()),
+		IsGroup:    true,
+		ItemTypeId: ItemType_ITEM_TYPE_CATEGORY,
+		Catname:    categoryCatname,
+	})
+
+	srcID := createItem(t, conn, cnt, &APIItem{
+		Name:       fmt.Sprintf("vehicle-%d", random.Int()),
+		IsGroup:    true,
+		ItemTypeId: ItemType_ITEM_TYPE_VEHICLE,
+	})
+
+	dstID := createItem(t, conn, cnt, &APIItem{
+		Name:       fmt.Sprintf("vehicle-%d", random.Int()),
+		IsGroup:    true,
+		ItemTypeId: ItemType_ITEM_TYPE_VEHICLE,
+	})
+
+	childID := createItem(t, conn, cnt, &APIItem{
+		Name:       fmt.Sprintf("vehicle-%d", random.Int()),
+		ItemTypeId: ItemType_ITEM_TYPE_VEHICLE,
+	})
+
+	client := NewItemsClient(conn)
+
+	_, adminToken := getUserWithCleanHistory(t, conn, cfg, goquDB, adminUsername, adminPassword)
+	adminCtx := metadata.AppendToOutgoingContext(ctx, authorizationHeader, bearerPrefix+adminToken)
+
+	for _, itemParent := range []*ItemParent{
+		{ItemId: srcID, ParentId: categoryID, Catname: "merge-source"},
+		{ItemId: dstID, ParentId: categoryID, Catname: "merge-target"},
+		{ItemId: childID, ParentId: srcID, Catname: "child"},
+	} {
+		itemParent.Type = ItemParentType_ITEM_TYPE_DEFAULT
+
+		_, err = client.CreateItemParent(adminCtx, itemParent)
+		require.NoError(t, err)
+	}
+
+	// item cannot be merged into its descendant
+	_, err = client.MergeItems(adminCtx, &MergeItemsRequest{SourceId: srcID, TargetId: childID})
+	require.Error(t, err)
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = client.MergeItems(adminCtx, &MergeItemsRequest{SourceId: srcID, TargetId: dstID})
+	require.NoError(t, err)
+
+	// child is moved to target
+	res, err := client.List(ctx, &ItemsRequest{
+		Options:  &ItemListOptions{Parent: &ItemParentListOptions{ParentId: dstID}},
+		Language: "en",
+	})
+	require.NoError(t, err)
+	require.Len(t, res.GetItems(), 1)
+	require.Equal(t, childID, res.GetItems()[0].GetId())
+
+	// old id resolves to target
+	item, err := client.Item(ctx, &ItemRequest{Id: srcID, Language: "en"})
+	require.NoError(t, err)
+	require.Equal(t, dstID, item.GetId())
+
+	// old path resolves to target and its moved child
+	path, err := client.GetPath(ctx, &PathRequest{
+		Catname:  categoryCatname,
+		Path:     "merge-source/child",
+		Language: "en",
+	})
+	require.NoError(t, err)
+
+	nodes := path.GetPath()
+	require.Len(t, nodes, 3) //nolint: mnd
+	require.Equal(t, dstID, nodes[1].GetItem().GetId())
+	require.Equal(t, childID, nodes[2].GetItem().GetId())
+
+	// source is already merged
+	_, err = client.MergeItems(adminCtx, &MergeItemsRequest{SourceId: srcID, TargetId: dstID})
+	require.Error(t, err)
+	require.Equal(t, codes.NotFound, status.Code(err))
+}
+
+func TestSplitItem(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.LoadConfig(".")
+
+	goquDB, err := cnt.GoquDB()
+	require.NoError(t, err)
+
+	ctx := t.Context()
+
+	random := rand.New(rand.NewSource(time.Now().UnixNano())) //nolint:gosec
+
+	categoryID := createItem(t, conn, cnt, &APIItem{
+		Name:       fmt.Sprintf("category-%d", random.Int()),
+		IsGroup:    true,
+		ItemTypeId: ItemType_ITEM_TYPE_CATEGORY,
+		Catname:    fmt.Sprintf("category-%d", random.Int()),
+	})
+
+	itemID := createItem(t, conn, cnt, &APIItem{
+		Name:       fmt.Sprintf("vehicle-%d", random.Int()),
+		IsGroup:    true,
+		ItemTypeId: ItemType_ITEM_TYPE_VEHICLE,
+	})
+
+	childID1 := createItem(t, conn, cnt, &APIItem{
+		Name:       fmt.Sprintf("vehicle-%d", random.Int()),
+		ItemTypeId: ItemType_ITEM_TYPE_VEHICLE,
+	})
+
+	childID2 := createItem(t, conn, cnt, &APIItem{
+		Name:       fmt.Sprintf("vehicle-%d", random.Int()),
+		ItemTypeId: ItemType_ITEM_TYPE_VEHICLE,
+	})
+
+	client := NewItemsClient(conn)
+
+	_, adminToken := getUserWithCleanHistory(t, conn, cfg, goquDB, adminUsername, adminPassword)
+	adminCtx := metadata.AppendToOutgoingContext(ctx, authorizationHeader, bearerPrefix+adminToken)
+
+	for _, itemParent := range []*ItemParent{
+		{ItemId: itemID, ParentId: categoryID},
+		{ItemId: childID1, ParentId: itemID},
+		{ItemId: childID2, ParentId: itemID},
+	} {
+		itemParent.Type = ItemParentType_ITEM_TYPE_DEFAULT
+
+		_, err = client.CreateItemParent(adminCtx, itemParent)
+		require.NoError(t, err)
+	}
+
+	frName := fmt.Sprintf("véhicule-%d", random.Int())
+
+	_, err = client.UpdateItemLanguage(adminCtx, &ItemLanguage{ItemId: itemID, Language: "fr", Name: frName})
+	require.NoError(t, err)
+
+	pictureID1 := CreatePicture(t, cnt, "./test/test.jpg", PicturePostForm{ItemID: itemID}, adminToken)
+	pictureID2 := CreatePicture(t, cnt, "./test/test.jpg", PicturePostForm{ItemID: itemID}, adminToken)
+
+	// only childs of item can be moved
+	_, err = client.SplitItem(adminCtx, &SplitItemRequest{ItemId: itemID, ChildIds: []int64{categoryID}})
+	require.Error(t, err)
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	// only pictures of item can be moved
+	_, err = client.SplitItem(adminCtx, &SplitItemRequest{ItemId: childID1, PictureIds: []int64{pictureID1}})
+	require.Error(t, err)
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	name := fmt.Sprintf("facelift-%d", random.Int())
+
+	res, err := client.SplitItem(adminCtx, &SplitItemRequest{
+		ItemId:     itemID,
+		Name:       name,
+		ChildIds:   []int64{childID1},
+		PictureIds: []int64{pictureID1},
+	})
+	require.NoError(t, err)
+
+	newItemID := res.GetId()
+	require.NotZero(t, newItemID)
+
+	item, err := client.Item(ctx, &ItemRequest{Id: newItemID, Language: "en", Fields: &ItemFields{NameOnly: true}})
+	require.NoError(t, err)
+	require.Equal(t, name, item.GetNameOnly())
+	require.True(t, item.GetIsGroup())
+
+	childs := func(parentID int64) []int64 {
+		list, err := client.List(ctx, &ItemsRequest{
+			Options:  &ItemListOptions{Parent: &ItemParentListOptions{ParentId: parentID}},
+			Language: "en",
+		})
+		require.NoError(t, err)
+
+		ids := make([]int64, 0, len(list.GetItems()))
+		for _, child := range list.GetItems() {
+			ids = append(ids, child.GetId())
+		}
+
+		return ids
+	}
+
+	require.ElementsMatch(t, []int64{childID1}, childs(newItemID))
+	require.ElementsMatch(t, []int64{childID2}, childs(itemID))
+	require.ElementsMatch(t, []int64{itemID, newItemID}, childs(categoryID))
+
+	languages, err := client.GetItemLanguages(adminCtx, &APIGetItemLanguagesRequest{ItemId: newItemID})
+	require.NoError(t, err)
+
+	names := make(map[string]string, len(languages.GetItems()))
+	for _, language := range languages.GetItems() {
+		names[language.GetLanguage()] = language.GetName()
+	}
+
+	require.Equal(t, frName, names["fr"])
+
+	pictureItems := func(pictureID int64) []int64 {
+		var ids []int64
+
+		err := goquDB.Select(schema.PictureItemTableItemIDCol).
+			From(schema.PictureItemTable).
+			Where(
+				schema.PictureItemTablePictureIDCol.Eq(pictureID),
+				schema.PictureItemTableTypeCol.Eq(schema.PictureItemTypeContent),
+			).
+			ScanValsContext(ctx, &ids)
+		require.NoError(t, err)
+
+		return ids
+	}
+
+	require.ElementsMatch(t, []int64{newItemID}, pictureItems(pictureID1))
+	require.ElementsMatch(t, []int64{itemID}, pictureItems(pictureID2))
+}
+
+func TestImportValidator(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+
+	repository, err := cnt.ItemsRepository()
+	require.NoError(t, err)
+
+	validator := importValidator{repository: repository}
+
+	problems, err := validator.ValidateItem(ctx, schema.ItemRow{
+		ItemTypeID: schema.ItemTableItemTypeIDVehicle,
+		Name:       "Golf I",
+		BeginYear:  sql.NullInt32{Valid: true, Int32: 1983},
+		EndYear:    sql.NullInt32{Valid: true, Int32: 1974},
+		BeginMonth: sql.NullInt16{Valid: true, Int16: 13},
+	}, nil)
+	require.NoError(t, err)
+	require.Len(t, problems, 3)
+
+	problems, err = validator.ValidateItem(ctx, schema.ItemRow{
+		ItemTypeID: schema.ItemTableItemTypeIDVehicle,
+		Name:       strings.Repeat("a", schema.ItemNameMaxLength+1),
+	}, nil)
+	require.NoError(t, err)
+	require.Len(t, problems, 1)
+
+	problems, err = validator.ValidateItem(ctx, schema.ItemRow{
+		ItemTypeID: schema.ItemTableItemTypeIDVehicle,
+		Name:       "Golf I",
+		BeginYear:  sql.NullInt32{Valid: true, Int32: 1974},
+		EndYear:    sql.NullInt32{Valid: true, Int32: 1983},
+	}, nil)
+	require.NoError(t, err)
+	require.Empty(t, problems)
+
+	problems, err = validator.ValidateLanguage(strings.Repeat("a", schema.ItemLanguageNameMaxLength+1), "", "")
+	require.NoError(t, err)
+	require.Len(t, problems, 1)
+}
+
+func TestItemHistoryAndRevert(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.LoadConfig(".")
+
+	goquDB, err := cnt.GoquDB()
+	require.NoError(t, err)
+
+	ctx := t.Context()
+
+	random := rand.New(rand.NewSource(time.Now().UnixNano())) //nolint:gosec
+
+	oldName := fmt.Sprintf("vehicle-%d", random.Int())
+	newName := fmt.Sprintf("vehicle-%d", random.Int())
+
+	itemID := createItem(t, conn, cnt, &APIItem{
+		Name:       oldName,
+		ItemTypeId: ItemType_ITEM_TYPE_VEHICLE,
+		BeginYear:  1990,
+	})
+
+	client := NewItemsClient(conn)
+
+	_, adminToken := getUserWithCleanHistory(t, conn, cfg, goquDB, adminUsername, adminPassword)
+	adminCtx := metadata.AppendToOutgoingContext(ctx, authorizationHeader, bearerPrefix+adminToken)
+
+	_, err = client.UpdateItem(adminCtx, &UpdateItemRequest{
+		Item:       &APIItem{Id: itemID, Name: newName, BeginYear: 1991},
+		UpdateMask: &fieldmaskpb.FieldMask{Paths: []string{"name", "begin_year"}},
+	})
+	require.NoError(t, err)
+
+	history, err := client.GetItemHistory(adminCtx, &GetItemHistoryRequest{ItemId: itemID})
+	require.NoError(t, err)
+
+	var nameRevision *ItemRevision
+
+	for _, revision := range history.GetItems() {
+		if revision.GetEntity() == ItemRevisionEntity_ITEM_REVISION_ENTITY_ITEM && revision.GetField() == "name" {
+			nameRevision = revision
+		}
+	}
+
+	require.NotNil(t, nameRevision)
+	require.Equal(t, oldName, nameRevision.GetOldValue().GetValue())
+	require.Equal(t, newName, nameRevision.GetNewValue().GetValue())
+
+	_, err = client.RevertItemRevision(ctx, &RevertItemRevisionRequest{Id: nameRevision.GetId()})
+	require.Error(t, err)
+
+	_, err = client.RevertItemRevision(adminCtx, &RevertItemRevisionRequest{Id: nameRevision.GetId()})
+	require.NoError(t, err)
+
+	item, err := client.Item(adminCtx, &ItemRequest{
+		Id: itemID, Language: "en", Fields: &ItemFields{NameOnly: true, Meta: true},
+	})
+	require.NoError(t, err)
+	require.Equal(t, oldName, item.GetNameOnly())
+	require.Equal(t, int32(1991), item.GetBeginYear())
+}
+
+func TestRevertItemParentRemovalRestoresCatname(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.LoadConfig(".")
+
+	goquDB, err := cnt.GoquDB()
+	require.NoError(t, err)
+
+	ctx := t.Context()
+
+	random := rand.New(rand.NewSource(time.Now().UnixNano())) //nolint:gosec
+
+	parentID := createItem(t, conn, cnt, &APIItem{
+		Name:       fmt.Sprintf("category-%d", random.Int()),
+		IsGroup:    true,
+		ItemTypeId: ItemType_ITEM_TYPE_CATEGORY,
+		Catname:    fmt.Sprintf("category-%d", random.Int()),
+	})
+
+	childID := createItem(t, conn, cnt, &APIItem{
+		Name:       fmt.Sprintf("vehicle-%d", random.Int()),
+		ItemTypeId: ItemType_ITEM_TYPE_VEHICLE,
+	})
+
+	client := NewItemsClient(conn)
+
+	_, adminToken := getUserWithCleanHistory(t, conn, cfg, goquDB, adminUsername, adminPassword)
+	adminCtx := metadata.AppendToOutgoingContext(ctx, authorizationHeader, bearerPrefix+adminToken)
+
+	_, err = client.CreateItemParent(adminCtx, &ItemParent{
+		ItemId: childID, ParentId: parentID, Catname: "custom-catname", Type: ItemParentType_ITEM_TYPE_DEFAULT,
+	})
+	require.NoError(t, err)
+
+	_, err = client.DeleteItemParent(adminCtx, &DeleteItemParentRequest{ItemId: childID, ParentId: parentID})
+	require.NoError(t, err)
+
+	history, err := client.GetItemHistory(adminCtx, &GetItemHistoryRequest{ItemId: childID})
+	require.NoError(t, err)
+
+	var removalRevision *ItemRevision
+
+	for _, revision := range history.GetItems() {
+		if revision.GetEntity() == ItemRevisionEntity_ITEM_REVISION_ENTITY_ITEM_PARENT &&
+			revision.GetField() == items.ItemParentRevisionFieldLink && revision.GetNewValue() == nil {
+			removalRevision = revision
+		}
+	}
+
+	require.NotNil(t, removalRevision)
+
+	_, err = client.RevertItemRevision(adminCtx, &RevertItemRevisionRequest{Id: removalRevision.GetId()})
+	require.NoError(t, err)
+
+	var catname string
+
+	success, err := goquDB.Select(schema.ItemParentTableCatnameCol).
+		From(schema.ItemParentTable).
+		Where(
+			schema.ItemParentTableItemIDCol.Eq(childID),
+			schema.ItemParentTableParentIDCol.Eq(parentID),
+		).
+		ScanValContext(ctx, &catname)
+	require.NoError(t, err)
+	require.True(t, success)
+	require.Equal(t, "custom-catname", catname)
+}
+
 func TestDeleteItemParentNotDeletesSecondChild(t *testing.T) {
 	t.Parallel()
 
@@ -4465,3 +4868,54 @@ func TestCreatedBrandIsGroup(t *testing.T) {
 	require.NotEmpty(t, res)
 	require.True(t, res.GetIsGroup())
 }
+
+func TestListByCursor(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	random := rand.New(rand.NewSource(time.Now().UnixNano())) //nolint:gosec
+	prefix := fmt.Sprintf("cursor-%d-", random.Int())
+	ids := make([]int64, 0)
+
+	for idx := range 3 {
+		ids = append(ids, createItem(t, conn, cnt, &APIItem{
+			Name:       prefix + strconv.Itoa(idx),
+			ItemTypeId: ItemType_ITEM_TYPE_VEHICLE,
+		}))
+	}
+
+	client := NewItemsClient(conn)
+	request := &ItemsRequest{
+		Language: "en",
+		Limit:    2,
+		Order:    ItemsRequest_ID_ASC,
+		Options:  &ItemListOptions{Name: prefix + "%"},
+		Cursor:   wrapperspb.String(""),
+		Count:    true,
+	}
+
+	res, err := client.List(ctx, request)
+	require.NoError(t, err)
+	require.Len(t, res.GetItems(), 2)
+	require.Equal(t, ids[0], res.GetItems()[0].GetId())
+	require.Equal(t, ids[1], res.GetItems()[1].GetId())
+	require.EqualValues(t, 3, res.GetPaginator().GetTotalItemCount())
+	require.NotEmpty(t, res.GetNextCursor())
+
+	request.Cursor = wrapperspb.String(res.GetNextCursor())
+	request.Count = false
+
+	res, err = client.List(ctx, request)
+	require.NoError(t, err)
+	require.Len(t, res.GetItems(), 1)
+	require.Equal(t, ids[2], res.GetItems()[0].GetId())
+	require.Nil(t, res.GetPaginator())
+	require.Empty(t, res.GetNextCursor())
+
+	// cursor of one order is rejected by another
+	request.Order = ItemsRequest_ID_DESC
+
+	_, err = client.List(ctx, request)
+	require.Error(t, err)
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
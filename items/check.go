@@ -0,0 +1,709 @@
+package items
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"cloud.google.com/go/civil"
+	"github.com/autowp/goautowp/query"
+	"github.com/autowp/goautowp/schema"
+	"github.com/autowp/goautowp/util"
+	"github.com/doug-martin/goqu/v9"
+)
+
+type CheckProblemKind string
+
+const (
+	CheckProblemCycle            CheckProblemKind = "cycle"
+	CheckProblemCatname          CheckProblemKind = "catname"
+	CheckProblemDuplicateCatname CheckProblemKind = "duplicate-catname"
+	CheckProblemOrphanedLanguage CheckProblemKind = "orphaned-language"
+	CheckProblemParentCache      CheckProblemKind = "parent-cache"
+	CheckProblemVehicleType      CheckProblemKind = "vehicle-type"
+	CheckProblemOrderCache       CheckProblemKind = "order-cache"
+
+	checkBatchSize = 500
+)
+
+var (
+	CheckProblemKinds = []CheckProblemKind{
+		CheckProblemCycle, CheckProblemCatname, CheckProblemDuplicateCatname, CheckProblemOrphanedLanguage,
+		CheckProblemParentCache, CheckProblemVehicleType, CheckProblemOrderCache,
+	}
+
+	ErrUnknownCheckProblemKind = errors.New("unknown problem kind")
+)
+
+func ParseCheckProblemKind(value string) (CheckProblemKind, error) {
+	kind := CheckProblemKind(value)
+	if !slices.Contains(CheckProblemKinds, kind) {
+		return "", fmt.Errorf("%w: `%s`", ErrUnknownCheckProblemKind, value)
+	}
+
+	return kind, nil
+}
+
+// CheckProblem is an inconsistency of catalogue graph or its caches.
+type CheckProblem struct {
+	Kind     CheckProblemKind `json:"kind"`
+	ItemID   int64            `json:"item_id"`
+	ParentID int64            `json:"parent_id,omitempty"`
+	Language string           `json:"language,omitempty"`
+	Details  string           `json:"details,omitempty"`
+	Repaired bool             `json:"repaired"`
+}
+
+type catalogueChecker struct {
+	repository *Repository
+	repair     []CheckProblemKind
+	report     func(CheckProblem) error
+	itemIDs    []int64
+	edges      []schema.ItemParentRow
+	parents    map[int64][]int64
+	childs     map[int64][]int64
+	// items in or under cycles, their caches cannot be built
+	cyclic map[int64]bool
+}
+
+// CheckCatalogue detects cycles of item_parent, bad and duplicate catnames, orphaned item_parent_language rows,
+// stale item_parent_cache rows, wrong inherited vehicle types and stale order cache.
+// Every problem is passed to report, problems of kinds listed in repair are fixed before that.
+func (s *Repository) CheckCatalogue(
+	ctx context.Context, repair []CheckProblemKind, report func(CheckProblem) error,
+) error {
+	checker := catalogueChecker{
+		repository: s,
+		repair:     repair,
+		report:     report,
+		parents:    make(map[int64][]int64),
+		childs:     make(map[int64][]int64),
+		cyclic:     make(map[int64]bool),
+	}
+
+	err := checker.load(ctx)
+	if err != nil {
+		return err
+	}
+
+	steps := []func(context.Context) error{
+		checker.checkCycles,
+		checker.checkCatnames,
+		checker.checkDuplicateCatnames,
+		checker.checkOrphanedLanguages,
+		checker.checkParentCache,
+		checker.checkVehicleTypes,
+		checker.checkOrderCache,
+	}
+
+	for _, step := range steps {
+		err = step(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *catalogueChecker) load(ctx context.Context) error {
+	err := s.repository.db.Select(schema.ItemTableIDCol).
+		From(schema.ItemTable).
+		Order(schema.ItemTableIDCol.Asc()).
+		ScanValsContext(ctx, &s.itemIDs)
+	if err != nil {
+		return err
+	}
+
+	err = s.repository.db.Select(
+		schema.ItemParentTableItemIDCol, schema.ItemParentTableParentIDCol, schema.ItemParentTableCatnameCol,
+	).
+		From(schema.ItemParentTable).
+		ScanStructsContext(ctx, &s.edges)
+	if err != nil {
+		return err
+	}
+
+	for _, edge := range s.edges {
+		s.addEdge(edge.ItemID, edge.ParentID)
+	}
+
+	return nil
+}
+
+func (s *catalogueChecker) addEdge(itemID, parentID int64) {
+	s.parents[itemID] = append(s.parents[itemID], parentID)
+	s.childs[parentID] = append(s.childs[parentID], itemID)
+}
+
+func (s *catalogueChecker) removeEdge(itemID, parentID int64) {
+	s.parents[itemID] = slices.DeleteFunc(s.parents[itemID], func(id int64) bool { return id == parentID })
+	s.childs[parentID] = slices.DeleteFunc(s.childs[parentID], func(id int64) bool { return id == itemID })
+	s.edges = slices.DeleteFunc(s.edges, func(edge schema.ItemParentRow) bool {
+		return edge.ItemID == itemID && edge.ParentID == parentID
+	})
+}
+
+func (s *catalogueChecker) emit(problem CheckProblem, repair func() error) error {
+	if repair != nil && slices.Contains(s.repair, problem.Kind) {
+		err := repair()
+		if err != nil {
+			return err
+		}
+
+		problem.Repaired = true
+	}
+
+	return s.report(problem)
+}
+
+// checkCycles finds edges closing cycles. Repair removes them, which makes graph acyclic.
+func (s *catalogueChecker) checkCycles(ctx context.Context) error {
+	cycles := findCycles(s.itemIDs, s.parents)
+	if len(cycles) == 0 {
+		return nil
+	}
+
+	removed := make([]int64, 0, len(cycles))
+
+	for _, cycle := range cycles {
+		itemID, parentID := cycle[len(cycle)-2], cycle[len(cycle)-1]
+
+		path := make([]string, len(cycle))
+		for idx, id := range cycle {
+			path[idx] = strconv.FormatInt(id, 10)
+		}
+
+		err := s.emit(CheckProblem{
+			Kind:     CheckProblemCycle,
+			ItemID:   itemID,
+			ParentID: parentID,
+			Details:  strings.Join(path, " > "),
+		}, func() error {
+			err := s.repository.db.WithTx(func(tx *goqu.TxDatabase) error {
+				_, err := tx.Delete(schema.ItemParentLanguageTable).Where(
+					schema.ItemParentLanguageTableItemIDCol.Eq(itemID),
+					schema.ItemParentLanguageTableParentIDCol.Eq(parentID),
+				).Executor().ExecContext(ctx)
+				if err != nil {
+					return err
+				}
+
+				_, err = tx.Delete(schema.ItemParentTable).Where(
+					schema.ItemParentTableItemIDCol.Eq(itemID),
+					schema.ItemParentTableParentIDCol.Eq(parentID),
+				).Executor().ExecContext(ctx)
+
+				return err
+			})
+			if err != nil {
+				return err
+			}
+
+			s.removeEdge(itemID, parentID)
+			removed = append(removed, itemID)
+
+			return nil
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	// caches are rebuilt only when all cycles are gone, otherwise rebuild never ends
+	if len(removed) == len(cycles) {
+		for _, itemID := range removed {
+			err := s.refreshItem(ctx, itemID)
+			if err != nil {
+				return err
+			}
+		}
+
+		return nil
+	}
+
+	for _, cycle := range cycles {
+		toCheck := slices.Clone(cycle)
+		for len(toCheck) > 0 {
+			id := toCheck[0]
+			toCheck = toCheck[1:]
+
+			if !s.cyclic[id] {
+				s.cyclic[id] = true
+				toCheck = append(toCheck, s.childs[id]...)
+			}
+		}
+	}
+
+	return nil
+}
+
+func (s *catalogueChecker) refreshItem(ctx context.Context, itemID int64) error {
+	_, err := s.repository.RebuildCache(ctx, itemID)
+	if err != nil {
+		return err
+	}
+
+	err = s.repository.UpdateInheritance(ctx, itemID)
+	if err != nil {
+		return err
+	}
+
+	return s.repository.RefreshItemVehicleTypeInheritanceFromParents(ctx, itemID)
+}
+
+// findCycles returns cycles of graph as paths from item through its parents back to the item.
+// Removing the last edge of every path makes graph acyclic.
+func findCycles(itemIDs []int64, parents map[int64][]int64) [][]int64 {
+	const (
+		visiting = 1
+		visited  = 2
+	)
+
+	var (
+		state  = make(map[int64]int8)
+		stack  []int64
+		cycles [][]int64
+		visit  func(id int64)
+	)
+
+	visit = func(id int64) {
+		state[id] = visiting
+		stack = append(stack, id)
+
+		for _, parentID := range parents[id] {
+			switch state[parentID] {
+			case visiting:
+				start := slices.Index(stack, parentID)
+				cycles = append(cycles, append(slices.Clone(stack[start:]), parentID))
+			case visited:
+			default:
+				visit(parentID)
+			}
+		}
+
+		stack = stack[:len(stack)-1]
+		state[id] = visited
+	}
+
+	for _, id := range itemIDs {
+		if state[id] == 0 {
+			visit(id)
+		}
+	}
+
+	return cycles
+}
+
+// checkCatnames finds empty catnames and catnames colliding with reserved words of catalogue routes.
+func (s *catalogueChecker) checkCatnames(ctx context.Context) error {
+	for _, edge := range s.edges {
+		if edge.Catname != "" && !util.Contains(catnameBlacklist, edge.Catname) {
+			continue
+		}
+
+		err := s.emit(CheckProblem{
+			Kind:     CheckProblemCatname,
+			ItemID:   edge.ItemID,
+			ParentID: edge.ParentID,
+			Details:  "invalid catname `" + edge.Catname + "`",
+		}, func() error {
+			return s.repairCatname(ctx, edge.ItemID, edge.ParentID)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// checkDuplicateCatnames finds siblings sharing the same catname, only the first of them is reachable by URL.
+// Repair gives new catnames to the rest of them.
+func (s *catalogueChecker) checkDuplicateCatnames(ctx context.Context) error {
+	duplicates := s.repository.db.Select(schema.ItemParentTableParentIDCol, schema.ItemParentTableCatnameCol).
+		From(schema.ItemParentTable).
+		GroupBy(schema.ItemParentTableParentIDCol, schema.ItemParentTableCatnameCol).
+		Having(goqu.COUNT(goqu.Star()).Gt(1)).
+		As("duplicates")
+
+	var rows []schema.ItemParentRow
+
+	err := s.repository.db.Select(
+		schema.ItemParentTableItemIDCol, schema.ItemParentTableParentIDCol, schema.ItemParentTableCatnameCol,
+	).
+		From(schema.ItemParentTable).
+		Join(duplicates, goqu.On(
+			schema.ItemParentTableParentIDCol.Eq(goqu.T("duplicates").Col(schema.ItemParentTableParentIDColName)),
+			schema.ItemParentTableCatnameCol.Eq(goqu.T("duplicates").Col(schema.ItemParentTableCatnameColName)),
+		)).
+		Order(
+			schema.ItemParentTableParentIDCol.Asc(),
+			schema.ItemParentTableCatnameCol.Asc(),
+			schema.ItemParentTableItemIDCol.Asc(),
+		).
+		ScanStructsContext(ctx, &rows)
+	if err != nil {
+		return err
+	}
+
+	for idx, row := range rows {
+		if idx == 0 || rows[idx-1].ParentID != row.ParentID || rows[idx-1].Catname != row.Catname {
+			continue
+		}
+
+		err = s.emit(CheckProblem{
+			Kind:     CheckProblemDuplicateCatname,
+			ItemID:   row.ItemID,
+			ParentID: row.ParentID,
+			Details:  "catname `" + row.Catname + "` is used by other child",
+		}, func() error {
+			return s.repairCatname(ctx, row.ItemID, row.ParentID)
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *catalogueChecker) repairCatname(ctx context.Context, itemID, parentID int64) error {
+	parentRow, err := s.repository.Item(ctx, &query.ItemListOptions{ItemID: parentID}, nil)
+	if err != nil {
+		return err
+	}
+
+	itemRow, err := s.repository.Item(ctx, &query.ItemListOptions{ItemID: itemID}, nil)
+	if err != nil {
+		return err
+	}
+
+	catname, err := s.repository.extractCatname(ctx, s.repository.db, parentRow.ItemRow, itemRow.ItemRow)
+	if err != nil {
+		return err
+	}
+
+	if len(catname) == 0 {
+		return errFailedToCreateItemParentCatname
+	}
+
+	_, err = s.repository.db.Update(schema.ItemParentTable).Set(goqu.Record{
+		schema.ItemParentTableCatnameColName:       catname,
+		schema.ItemParentTableManualCatnameColName: false,
+	}).Where(
+		schema.ItemParentTableItemIDCol.Eq(itemID),
+		schema.ItemParentTableParentIDCol.Eq(parentID),
+	).Executor().ExecContext(ctx)
+
+	return err
+}
+
+// checkOrphanedLanguages finds names of item_parent_language left after their edge is removed.
+func (s *catalogueChecker) checkOrphanedLanguages(ctx context.Context) error {
+	var rows []struct {
+		ItemID   int64  `db:"item_id"`
+		ParentID int64  `db:"parent_id"`
+		Language string `db:"language"`
+	}
+
+	err := s.repository.db.Select(
+		schema.ItemParentLanguageTableItemIDCol, schema.ItemParentLanguageTableParentIDCol,
+		schema.ItemParentLanguageTableLanguageCol,
+	).
+		From(schema.ItemParentLanguageTable).
+		LeftJoin(schema.ItemParentTable, goqu.On(
+			schema.ItemParentLanguageTableItemIDCol.Eq(schema.ItemParentTableItemIDCol),
+			schema.ItemParentLanguageTableParentIDCol.Eq(schema.ItemParentTableParentIDCol),
+		)).
+		Where(schema.ItemParentTableItemIDCol.IsNull()).
+		ScanStructsContext(ctx, &rows)
+	if err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		err = s.emit(CheckProblem{
+			Kind:     CheckProblemOrphanedLanguage,
+			ItemID:   row.ItemID,
+			ParentID: row.ParentID,
+			Language: row.Language,
+		}, func() error {
+			_, err := s.repository.db.Delete(schema.ItemParentLanguageTable).Where(
+				schema.ItemParentLanguageTableItemIDCol.Eq(row.ItemID),
+				schema.ItemParentLanguageTableParentIDCol.Eq(row.ParentID),
+				schema.ItemParentLanguageTableLanguageCol.Eq(row.Language),
+			).Executor().ExecContext(ctx)
+
+			return err
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (s *catalogueChecker) ancestors(itemID int64) map[int64]bool {
+	result := map[int64]bool{itemID: true}
+	toCheck := []int64{itemID}
+
+	for len(toCheck) > 0 {
+		id := toCheck[0]
+		toCheck = toCheck[1:]
+
+		for _, parentID := range s.parents[id] {
+			if !result[parentID] {
+				result[parentID] = true
+				toCheck = append(toCheck, parentID)
+			}
+		}
+	}
+
+	return result
+}
+
+// checkParentCache compares item_parent_cache with ancestors of every item.
+func (s *catalogueChecker) checkParentCache(ctx context.Context) error {
+	for chunk := range slices.Chunk(s.itemIDs, checkBatchSize) {
+		var rows []struct {
+			ItemID   int64 `db:"item_id"`
+			ParentID int64 `db:"parent_id"`
+		}
+
+		err := s.repository.db.Select(schema.ItemParentCacheTableItemIDCol, schema.ItemParentCacheTableParentIDCol).
+			From(schema.ItemParentCacheTable).
+			Where(schema.ItemParentCacheTableItemIDCol.In(chunk)).
+			ScanStructsContext(ctx, &rows)
+		if err != nil {
+			return err
+		}
+
+		cached := make(map[int64]map[int64]bool, len(chunk))
+
+		for _, row := range rows {
+			if cached[row.ItemID] == nil {
+				cached[row.ItemID] = make(map[int64]bool)
+			}
+
+			cached[row.ItemID][row.ParentID] = true
+		}
+
+		for _, itemID := range chunk {
+			if s.cyclic[itemID] {
+				continue
+			}
+
+			expected := s.ancestors(itemID)
+			missing := setDiff(expected, cached[itemID])
+			stale := setDiff(cached[itemID], expected)
+
+			if len(missing) == 0 && len(stale) == 0 {
+				continue
+			}
+
+			err = s.emit(CheckProblem{
+				Kind:    CheckProblemParentCache,
+				ItemID:  itemID,
+				Details: fmt.Sprintf("missing %v, stale %v", missing, stale),
+			}, func() error {
+				_, err := s.repository.RebuildCache(ctx, itemID)
+
+				return err
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// checkVehicleTypes checks that items without own vehicle types inherit all types of their parents.
+// Items are checked from roots to leafs, so repair of a parent is seen by its childs.
+func (s *catalogueChecker) checkVehicleTypes(ctx context.Context) error {
+	var rows []struct {
+		ItemID        int64 `db:"item_id"`
+		VehicleTypeID int64 `db:"vehicle_type_id"`
+		Inherited     bool  `db:"inherited"`
+	}
+
+	err := s.repository.db.Select(
+		schema.ItemVehicleTypeTableItemIDCol, schema.ItemVehicleTypeTableVehicleTypeIDCol,
+		schema.ItemVehicleTypeTableInheritedCol,
+	).
+		From(schema.ItemVehicleTypeTable).
+		ScanStructsContext(ctx, &rows)
+	if err != nil {
+		return err
+	}
+
+	own := make(map[int64][]int64)
+	inherited := make(map[int64][]int64)
+
+	for _, row := range rows {
+		if row.Inherited {
+			inherited[row.ItemID] = append(inherited[row.ItemID], row.VehicleTypeID)
+		} else {
+			own[row.ItemID] = append(own[row.ItemID], row.VehicleTypeID)
+		}
+	}
+
+	// repair of item refreshes its descendants too, so they are reloaded
+	touched := make(map[int64]bool)
+
+	for _, itemID := range s.rootsFirst() {
+		if s.cyclic[itemID] {
+			continue
+		}
+
+		expected := make(map[int64]bool)
+
+		if len(own[itemID]) == 0 {
+			for _, parentID := range s.parents[itemID] {
+				for _, id := range slices.Concat(own[parentID], inherited[parentID]) {
+					expected[id] = true
+				}
+			}
+		}
+
+		if slices.ContainsFunc(s.parents[itemID], func(id int64) bool { return touched[id] }) {
+			touched[itemID] = true
+
+			inherited[itemID], err = s.repository.getItemVehicleTypeIDs(ctx, itemID, true)
+			if err != nil {
+				return err
+			}
+		}
+
+		actual := make(map[int64]bool, len(inherited[itemID]))
+		for _, id := range inherited[itemID] {
+			actual[id] = true
+		}
+
+		missing := setDiff(expected, actual)
+		stale := setDiff(actual, expected)
+
+		if len(missing) == 0 && len(stale) == 0 {
+			continue
+		}
+
+		err = s.emit(CheckProblem{
+			Kind:    CheckProblemVehicleType,
+			ItemID:  itemID,
+			Details: fmt.Sprintf("missing %v, stale %v", missing, stale),
+		}, func() error {
+			err := s.repository.RefreshItemVehicleTypeInheritanceFromParents(ctx, itemID)
+			if err != nil {
+				return err
+			}
+
+			touched[itemID] = true
+			inherited[itemID], err = s.repository.getItemVehicleTypeIDs(ctx, itemID, true)
+
+			return err
+		})
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// rootsFirst orders items so every item follows all its parents.
+func (s *catalogueChecker) rootsFirst() []int64 {
+	var (
+		result  = make([]int64, 0, len(s.itemIDs))
+		visited = make(map[int64]bool, len(s.itemIDs))
+		visit   func(id int64)
+	)
+
+	visit = func(id int64) {
+		visited[id] = true
+
+		for _, parentID := range s.parents[id] {
+			if !visited[parentID] {
+				visit(parentID)
+			}
+		}
+
+		result = append(result, id)
+	}
+
+	for _, id := range s.itemIDs {
+		if !visited[id] {
+			visit(id)
+		}
+	}
+
+	return result
+}
+
+// checkOrderCache compares cached dates used for chronological sorting with production dates.
+func (s *catalogueChecker) checkOrderCache(ctx context.Context) error {
+	for chunk := range slices.Chunk(s.itemIDs, checkBatchSize) {
+		var rows []struct {
+			schema.ItemRow
+			BeginOrderCache sql.NullTime `db:"begin_order_cache"`
+			EndOrderCache   sql.NullTime `db:"end_order_cache"`
+		}
+
+		err := s.repository.db.Select(
+			schema.ItemTableIDCol,
+			schema.ItemTableBeginYearCol, schema.ItemTableBeginMonthCol,
+			schema.ItemTableBeginModelYearCol, schema.ItemTableBeginModelYearFractionCol,
+			schema.ItemTableEndYearCol, schema.ItemTableEndMonthCol,
+			schema.ItemTableEndModelYearCol, schema.ItemTableEndModelYearFractionCol,
+			schema.ItemTableBeginOrderCacheCol, schema.ItemTableEndOrderCacheCol,
+		).
+			From(schema.ItemTable).
+			Where(schema.ItemTableIDCol.In(chunk)).
+			ScanStructsContext(ctx, &rows)
+		if err != nil {
+			return err
+		}
+
+		for _, row := range rows {
+			begin, end := orderCacheDates(row.ItemRow)
+
+			if row.BeginOrderCache.Valid && row.EndOrderCache.Valid &&
+				civil.DateOf(row.BeginOrderCache.Time) == begin && civil.DateOf(row.EndOrderCache.Time) == end {
+				continue
+			}
+
+			err = s.emit(CheckProblem{
+				Kind:    CheckProblemOrderCache,
+				ItemID:  row.ID,
+				Details: fmt.Sprintf("expected %s - %s", begin, end),
+			}, func() error {
+				_, err := s.repository.UpdateOrderCache(ctx, row.ID)
+
+				return err
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// setDiff returns sorted keys of a missing in b.
+func setDiff(a, b map[int64]bool) []int64 {
+	return slices.Sorted(func(yield func(int64) bool) {
+		for id := range maps.Keys(a) {
+			if !b[id] && !yield(id) {
+				return
+			}
+		}
+	})
+}
@@ -0,0 +1,127 @@
+package items
+
+import (
+	"database/sql"
+	"testing"
+
+	"github.com/autowp/goautowp/config"
+	"github.com/autowp/goautowp/image/storage"
+	"github.com/autowp/goautowp/schema"
+	"github.com/autowp/goautowp/textstorage"
+	"github.com/doug-martin/goqu/v9"
+	"github.com/stretchr/testify/require"
+)
+
+func TestFindCycles(t *testing.T) {
+	t.Parallel()
+
+	require.Empty(t, findCycles([]int64{1, 2, 3}, map[int64][]int64{
+		2: {1},
+		3: {1, 2},
+	}))
+
+	require.Equal(t, [][]int64{{1, 3, 2, 1}}, findCycles([]int64{1, 2, 3}, map[int64][]int64{
+		1: {3},
+		2: {1},
+		3: {2},
+	}))
+
+	require.Equal(t, [][]int64{{4, 4}}, findCycles([]int64{4}, map[int64][]int64{
+		4: {4},
+	}))
+}
+
+func TestCheckCatalogueCycle(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.LoadConfig("../")
+	db, err := sql.Open("mysql", cfg.AutowpDSN)
+	require.NoError(t, err)
+
+	goquDB := goqu.New("mysql", db)
+	ctx := t.Context()
+
+	imageStorage, err := storage.NewStorage(goquDB, cfg.ImageStorage)
+	require.NoError(t, err)
+
+	repository := NewRepository(
+		goquDB,
+		200,
+		cfg.ContentLanguages,
+		textstorage.New(goquDB),
+		imageStorage,
+	)
+
+	parentID := CreateItem(t, goquDB, schema.ItemRow{
+		ItemTypeID: schema.ItemTableItemTypeIDVehicle,
+		Name:       "TestCheckCatalogueCycle parent",
+		IsGroup:    true,
+	})
+
+	childID := CreateItem(t, goquDB, schema.ItemRow{
+		ItemTypeID: schema.ItemTableItemTypeIDVehicle,
+		Name:       "TestCheckCatalogueCycle child",
+		IsGroup:    true,
+	})
+
+	success, err := repository.CreateItemParent(ctx, childID, parentID, schema.ItemParentTypeDefault, "child", 0)
+	require.NoError(t, err)
+	require.True(t, success)
+
+	// CreateItemParent refuses cycles, so the closing edge is written directly
+	_, err = goquDB.Insert(schema.ItemParentTable).Rows(goqu.Record{
+		schema.ItemParentTableParentIDColName:      childID,
+		schema.ItemParentTableItemIDColName:        parentID,
+		schema.ItemParentTableTypeColName:          schema.ItemParentTypeDefault,
+		schema.ItemParentTableCatnameColName:       "parent",
+		schema.ItemParentTableManualCatnameColName: false,
+		schema.ItemParentTableTimestampColName:     goqu.Func("NOW"),
+	}).Executor().ExecContext(ctx)
+	require.NoError(t, err)
+
+	ownCycles := func(repair []CheckProblemKind) []CheckProblem {
+		result := make([]CheckProblem, 0)
+
+		err := repository.CheckCatalogue(ctx, repair, func(problem CheckProblem) error {
+			isOwn := (problem.ItemID == parentID && problem.ParentID == childID) ||
+				(problem.ItemID == childID && problem.ParentID == parentID)
+
+			if problem.Kind == CheckProblemCycle && isOwn {
+				result = append(result, problem)
+			}
+
+			return nil
+		})
+		require.NoError(t, err)
+
+		return result
+	}
+
+	problems := ownCycles(nil)
+	require.Len(t, problems, 1)
+	require.False(t, problems[0].Repaired)
+
+	problems = ownCycles([]CheckProblemKind{CheckProblemCycle})
+	require.Len(t, problems, 1)
+	require.True(t, problems[0].Repaired)
+
+	var edgesCount int
+
+	_, err = goquDB.Select(goqu.COUNT(goqu.Star())).
+		From(schema.ItemParentTable).
+		Where(goqu.Or(
+			goqu.And(
+				schema.ItemParentTableItemIDCol.Eq(childID),
+				schema.ItemParentTableParentIDCol.Eq(parentID),
+			),
+			goqu.And(
+				schema.ItemParentTableItemIDCol.Eq(parentID),
+				schema.ItemParentTableParentIDCol.Eq(childID),
+			),
+		)).
+		ScanValContext(ctx, &edgesCount)
+	require.NoError(t, err)
+	require.Equal(t, 1, edgesCount)
+
+	require.Empty(t, ownCycles(nil))
+}
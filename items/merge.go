@@ -0,0 +1,803 @@
+package items
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+	"strconv"
+	"time"
+
+	"github.com/autowp/goautowp/schema"
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+)
+
+var (
+	ErrMergeSameItem     = errors.New("item cannot be merged into itself")
+	ErrMergeTypeMismatch = errors.New("items of different types cannot be merged")
+	ErrMergeRelated      = errors.New("item cannot be merged into its ancestor or descendant")
+)
+
+// MergedItem lists items, which specifications depend on the merged item and should be recalculated.
+type MergedItem struct {
+	// ChildIDs are childs of source moved under target
+	ChildIDs []int64
+	// VehicleIDs are vehicles with engine re-pointed from source to target
+	VehicleIDs []int64
+}
+
+// MergeItems moves everything attached to source item to target item and deletes source.
+// Source id and catnames are kept as redirects to target. Target wins when both have the same row,
+// e.g. the same parent, language or user value.
+// Caches and inherited values should be refreshed with RefreshMergedItem after merge.
+func (s *Repository) MergeItems(ctx context.Context, srcID, dstID int64) (*MergedItem, error) {
+	if srcID == dstID {
+		return nil, ErrMergeSameItem
+	}
+
+	ctx = context.WithoutCancel(ctx)
+
+	var result MergedItem
+
+	err := s.db.WithTx(func(tx *goqu.TxDatabase) error {
+		src, err := checkMergeable(ctx, tx, srcID, dstID)
+		if err != nil {
+			return err
+		}
+
+		err = tx.Select(schema.ItemParentTableItemIDCol).
+			From(schema.ItemParentTable).
+			Where(schema.ItemParentTableParentIDCol.Eq(srcID)).
+			ScanValsContext(ctx, &result.ChildIDs)
+		if err != nil {
+			return err
+		}
+
+		err = tx.Select(schema.ItemTableIDCol).
+			From(schema.ItemTable).
+			Where(schema.ItemTableEngineItemIDCol.Eq(srcID)).
+			ScanValsContext(ctx, &result.VehicleIDs)
+		if err != nil {
+			return err
+		}
+
+		steps := []func(context.Context, *goqu.TxDatabase, int64, int64) error{
+			mergeItemParents,
+			mergeItemChilds,
+			mergeItemLanguages,
+			mergePictureItems,
+			mergeAttrsUserValues,
+			mergeComments,
+			mergeItemMisc,
+		}
+
+		for _, step := range steps {
+			err := step(ctx, tx, srcID, dstID)
+			if err != nil {
+				return err
+			}
+		}
+
+		_, err = tx.Update(schema.ItemRedirectTable).
+			Set(goqu.Record{schema.ItemRedirectTableToItemIDColName: dstID}).
+			Where(schema.ItemRedirectTableToItemIDCol.Eq(srcID)).
+			Executor().ExecContext(ctx)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Insert(schema.ItemRedirectTable).Rows(goqu.Record{
+			schema.ItemRedirectTableItemIDColName:   srcID,
+			schema.ItemRedirectTableToItemIDColName: dstID,
+			schema.ItemRedirectTableCatnameColName:  src.Catname,
+		}).Executor().ExecContext(ctx)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Delete(schema.ItemTable).Where(schema.ItemTableIDCol.Eq(srcID)).Executor().ExecContext(ctx)
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &result, nil
+}
+
+// checkMergeable locks both items and checks they are of the same type and are not ancestor and descendant,
+// so concurrent merge or re-parent can't invalidate the check. Returns source item.
+func checkMergeable(ctx context.Context, tx *goqu.TxDatabase, srcID, dstID int64) (*schema.ItemRow, error) {
+	var rows []schema.ItemRow
+
+	err := tx.Select(schema.ItemTableIDCol, schema.ItemTableItemTypeIDCol, schema.ItemTableCatnameCol).
+		From(schema.ItemTable).
+		Where(schema.ItemTableIDCol.In([]int64{srcID, dstID})).
+		Order(schema.ItemTableIDCol.Asc()).
+		ForUpdate(exp.Wait).
+		ScanStructsContext(ctx, &rows)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(rows) != 2 { //nolint: mnd
+		return nil, ErrItemNotFound
+	}
+
+	src, dst := rows[0], rows[1]
+	if src.ID != srcID {
+		src, dst = dst, src
+	}
+
+	if src.ItemTypeID != dst.ItemTypeID {
+		return nil, ErrMergeTypeMismatch
+	}
+
+	related, err := tx.Select(goqu.V(true)).
+		From(schema.ItemParentCacheTable).
+		Where(goqu.Or(
+			goqu.And(
+				schema.ItemParentCacheTableItemIDCol.Eq(srcID),
+				schema.ItemParentCacheTableParentIDCol.Eq(dstID),
+			),
+			goqu.And(
+				schema.ItemParentCacheTableItemIDCol.Eq(dstID),
+				schema.ItemParentCacheTableParentIDCol.Eq(srcID),
+			),
+		)).
+		Limit(1).
+		ForShare(exp.Wait).
+		ScanValContext(ctx, new(bool))
+	if err != nil {
+		return nil, err
+	}
+
+	if related {
+		return nil, ErrMergeRelated
+	}
+
+	return &src, nil
+}
+
+// RefreshMergedItem rebuilds caches and inherited values of item, which received rows of merged item.
+func (s *Repository) RefreshMergedItem(ctx context.Context, itemID int64) error {
+	ctx = context.WithoutCancel(ctx)
+
+	_, err := s.RebuildCache(ctx, itemID)
+	if err != nil {
+		return err
+	}
+
+	err = s.UpdateInheritance(ctx, itemID)
+	if err != nil {
+		return err
+	}
+
+	err = s.RefreshItemVehicleTypeInheritanceFromParents(ctx, itemID)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.UpdateOrderCache(ctx, itemID)
+
+	return err
+}
+
+// ItemRedirect returns id of item, which item with given id was merged into.
+func (s *Repository) ItemRedirect(ctx context.Context, itemID int64) (int64, error) {
+	var toItemID int64
+
+	success, err := s.db.Select(schema.ItemRedirectTableToItemIDCol).
+		From(schema.ItemRedirectTable).
+		Where(schema.ItemRedirectTableItemIDCol.Eq(itemID)).
+		ScanValContext(ctx, &toItemID)
+	if err != nil {
+		return 0, err
+	}
+
+	if !success {
+		return 0, ErrItemNotFound
+	}
+
+	return toItemID, nil
+}
+
+// CatnameRedirect returns id of item, which item with given catname was merged into.
+func (s *Repository) CatnameRedirect(ctx context.Context, catname string) (int64, error) {
+	var toItemID int64
+
+	success, err := s.db.Select(schema.ItemRedirectTableToItemIDCol).
+		From(schema.ItemRedirectTable).
+		Where(schema.ItemRedirectTableCatnameCol.Eq(catname)).
+		Order(schema.ItemRedirectTableItemIDCol.Desc()).
+		Limit(1).
+		ScanValContext(ctx, &toItemID)
+	if err != nil {
+		return 0, err
+	}
+
+	if !success {
+		return 0, ErrItemNotFound
+	}
+
+	return toItemID, nil
+}
+
+// ItemParentRedirect returns id of item, which was a child of parent with given catname before merge.
+func (s *Repository) ItemParentRedirect(ctx context.Context, parentID int64, catname string) (int64, error) {
+	var itemID int64
+
+	success, err := s.db.Select(schema.ItemParentRedirectTableItemIDCol).
+		From(schema.ItemParentRedirectTable).
+		Where(
+			schema.ItemParentRedirectTableParentIDCol.Eq(parentID),
+			schema.ItemParentRedirectTableCatnameCol.Eq(catname),
+		).
+		ScanValContext(ctx, &itemID)
+	if err != nil {
+		return 0, err
+	}
+
+	if !success {
+		return 0, ErrItemNotFound
+	}
+
+	return itemID, nil
+}
+
+func addItemParentRedirect(
+	ctx context.Context, tx *goqu.TxDatabase, parentID int64, catname string, itemID int64,
+) error {
+	_, err := tx.Insert(schema.ItemParentRedirectTable).Rows(goqu.Record{
+		schema.ItemParentRedirectTableParentIDColName: parentID,
+		schema.ItemParentRedirectTableCatnameColName:  catname,
+		schema.ItemParentRedirectTableItemIDColName:   itemID,
+	}).OnConflict(goqu.DoUpdate(
+		schema.ItemParentRedirectTableParentIDColName+","+schema.ItemParentRedirectTableCatnameColName,
+		goqu.Record{
+			schema.ItemParentRedirectTableItemIDColName: goqu.Func(
+				"VALUES",
+				goqu.C(schema.ItemParentRedirectTableItemIDColName),
+			),
+		},
+	)).Executor().ExecContext(ctx)
+
+	return err
+}
+
+func itemParentRows(
+	ctx context.Context, tx *goqu.TxDatabase, where ...exp.Expression,
+) ([]schema.ItemParentRow, error) {
+	var rows []schema.ItemParentRow
+
+	err := tx.Select(
+		schema.ItemParentTableItemIDCol, schema.ItemParentTableParentIDCol, schema.ItemParentTableCatnameCol,
+	).
+		From(schema.ItemParentTable).
+		Where(where...).
+		ScanStructsContext(ctx, &rows)
+
+	return rows, err
+}
+
+func itemParentExists(ctx context.Context, tx *goqu.TxDatabase, where ...exp.Expression) (bool, error) {
+	return tx.Select(goqu.V(true)).From(schema.ItemParentTable).Where(where...).Limit(1).
+		ScanValContext(ctx, new(bool))
+}
+
+func moveItemParent(ctx context.Context, tx *goqu.TxDatabase, row schema.ItemParentRow, set goqu.Record) error {
+	where := []exp.Expression{
+		schema.ItemParentTableItemIDCol.Eq(row.ItemID),
+		schema.ItemParentTableParentIDCol.Eq(row.ParentID),
+	}
+
+	_, err := tx.Update(schema.ItemParentTable).Set(set).Where(where...).Executor().ExecContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	languageSet := goqu.Record{}
+
+	if itemID, ok := set[schema.ItemParentTableItemIDColName]; ok {
+		languageSet[schema.ItemParentLanguageTableItemIDColName] = itemID
+	}
+
+	if parentID, ok := set[schema.ItemParentTableParentIDColName]; ok {
+		languageSet[schema.ItemParentLanguageTableParentIDColName] = parentID
+	}
+
+	_, err = tx.Update(schema.ItemParentLanguageTable).Set(languageSet).Where(
+		schema.ItemParentLanguageTableItemIDCol.Eq(row.ItemID),
+		schema.ItemParentLanguageTableParentIDCol.Eq(row.ParentID),
+	).Executor().ExecContext(ctx)
+
+	return err
+}
+
+func deleteItemParent(ctx context.Context, tx *goqu.TxDatabase, row schema.ItemParentRow) error {
+	_, err := tx.Delete(schema.ItemParentLanguageTable).Where(
+		schema.ItemParentLanguageTableItemIDCol.Eq(row.ItemID),
+		schema.ItemParentLanguageTableParentIDCol.Eq(row.ParentID),
+	).Executor().ExecContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Delete(schema.ItemParentTable).Where(
+		schema.ItemParentTableItemIDCol.Eq(row.ItemID),
+		schema.ItemParentTableParentIDCol.Eq(row.ParentID),
+	).Executor().ExecContext(ctx)
+
+	return err
+}
+
+// mergeItemParents moves source under its parents, catnames of edges dropped as duplicates become redirects.
+func mergeItemParents(ctx context.Context, tx *goqu.TxDatabase, srcID, dstID int64) error {
+	rows, err := itemParentRows(ctx, tx, schema.ItemParentTableItemIDCol.Eq(srcID))
+	if err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		exists, err := itemParentExists(ctx, tx,
+			schema.ItemParentTableItemIDCol.Eq(dstID),
+			schema.ItemParentTableParentIDCol.Eq(row.ParentID),
+		)
+		if err != nil {
+			return err
+		}
+
+		if !exists {
+			err = moveItemParent(ctx, tx, row, goqu.Record{schema.ItemParentTableItemIDColName: dstID})
+			if err != nil {
+				return err
+			}
+
+			continue
+		}
+
+		err = deleteItemParent(ctx, tx, row)
+		if err != nil {
+			return err
+		}
+
+		err = addItemParentRedirect(ctx, tx, row.ParentID, row.Catname, dstID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// mergeItemChilds moves childs of source to target, catname taken by other child of target gets a suffix.
+func mergeItemChilds(ctx context.Context, tx *goqu.TxDatabase, srcID, dstID int64) error {
+	rows, err := itemParentRows(ctx, tx, schema.ItemParentTableParentIDCol.Eq(srcID))
+	if err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		exists, err := itemParentExists(ctx, tx,
+			schema.ItemParentTableItemIDCol.Eq(row.ItemID),
+			schema.ItemParentTableParentIDCol.Eq(dstID),
+		)
+		if err != nil {
+			return err
+		}
+
+		if exists {
+			err = deleteItemParent(ctx, tx, row)
+			if err != nil {
+				return err
+			}
+		} else {
+			catname := row.Catname
+
+			taken, err := itemParentExists(ctx, tx,
+				schema.ItemParentTableParentIDCol.Eq(dstID),
+				schema.ItemParentTableCatnameCol.Eq(catname),
+			)
+			if err != nil {
+				return err
+			}
+
+			if taken {
+				suffix := "-" + strconv.FormatInt(row.ItemID, 10)
+				catname = catname[:min(len(catname), schema.ItemParentMaxCatname-len(suffix))] + suffix
+			}
+
+			err = moveItemParent(ctx, tx, row, goqu.Record{
+				schema.ItemParentTableParentIDColName: dstID,
+				schema.ItemParentTableCatnameColName:  catname,
+			})
+			if err != nil {
+				return err
+			}
+
+			if catname == row.Catname {
+				continue
+			}
+		}
+
+		err = addItemParentRedirect(ctx, tx, dstID, row.Catname, row.ItemID)
+		if err != nil {
+			return err
+		}
+	}
+
+	// redirects through source as a parent now lead through target
+	err = moveItemRows[string](ctx, tx, schema.ItemParentRedirectTable,
+		schema.ItemParentRedirectTableParentIDColName, schema.ItemParentRedirectTableCatnameColName, srcID, dstID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Update(schema.ItemParentRedirectTable).
+		Set(goqu.Record{schema.ItemParentRedirectTableItemIDColName: dstID}).
+		Where(schema.ItemParentRedirectTableItemIDCol.Eq(srcID)).
+		Executor().ExecContext(ctx)
+
+	return err
+}
+
+// mergeItemLanguages moves names and texts of source, missing values of target are filled from source.
+func mergeItemLanguages(ctx context.Context, tx *goqu.TxDatabase, srcID, dstID int64) error {
+	var rows []schema.ItemLanguageRow
+
+	err := tx.Select(
+		schema.ItemLanguageTableItemIDCol, schema.ItemLanguageTableLanguageCol, schema.ItemLanguageTableNameCol,
+		schema.ItemLanguageTableTextIDCol, schema.ItemLanguageTableFullTextIDCol,
+	).
+		From(schema.ItemLanguageTable).
+		Where(schema.ItemLanguageTableItemIDCol.In([]int64{srcID, dstID})).
+		ScanStructsContext(ctx, &rows)
+	if err != nil {
+		return err
+	}
+
+	dstRows := make(map[string]schema.ItemLanguageRow)
+
+	for _, row := range rows {
+		if row.ItemID == dstID {
+			dstRows[row.Language] = row
+		}
+	}
+
+	for _, row := range rows {
+		dstRow, ok := dstRows[row.Language]
+		if row.ItemID != srcID || !ok {
+			continue
+		}
+
+		set := goqu.Record{}
+
+		if (!dstRow.Name.Valid || dstRow.Name.String == "") && row.Name.Valid {
+			set[schema.ItemLanguageTableNameColName] = row.Name
+		}
+
+		if !dstRow.TextID.Valid && row.TextID.Valid {
+			set[schema.ItemLanguageTableTextIDColName] = row.TextID
+		}
+
+		if !dstRow.FullTextID.Valid && row.FullTextID.Valid {
+			set[schema.ItemLanguageTableFullTextIDColName] = row.FullTextID
+		}
+
+		if len(set) == 0 {
+			continue
+		}
+
+		_, err = tx.Update(schema.ItemLanguageTable).Set(set).Where(
+			schema.ItemLanguageTableItemIDCol.Eq(dstID),
+			schema.ItemLanguageTableLanguageCol.Eq(row.Language),
+		).Executor().ExecContext(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	return moveItemRows[string](ctx, tx, schema.ItemLanguageTable,
+		schema.ItemLanguageTableItemIDColName, schema.ItemLanguageTableLanguageColName, srcID, dstID)
+}
+
+func mergePictureItems(ctx context.Context, tx *goqu.TxDatabase, srcID, dstID int64) error {
+	for _, pictureItemType := range []schema.PictureItemType{
+		schema.PictureItemTypeContent, schema.PictureItemTypeAuthor, schema.PictureItemTypeCopyrights,
+	} {
+		err := moveItemRows[int64](ctx, tx, schema.PictureItemTable,
+			schema.PictureItemTableItemIDColName, schema.PictureItemTablePictureIDColName, srcID, dstID,
+			schema.PictureItemTableTypeCol.Eq(pictureItemType))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// mergeAttrsUserValues moves user values of source, values of the same user to the same attribute of target win.
+// Actual values of source are dropped and should be recalculated for target.
+func mergeAttrsUserValues(ctx context.Context, tx *goqu.TxDatabase, srcID, dstID int64) error {
+	var rows []schema.AttrsUserValueRow
+
+	err := tx.Select(
+		schema.AttrsUserValuesTableAttributeIDCol, schema.AttrsUserValuesTableItemIDCol,
+		schema.AttrsUserValuesTableUserIDCol,
+	).
+		From(schema.AttrsUserValuesTable).
+		Where(schema.AttrsUserValuesTableItemIDCol.Eq(srcID)).
+		ScanStructsContext(ctx, &rows)
+	if err != nil {
+		return err
+	}
+
+	tables := []struct {
+		table       exp.IdentifierExpression
+		attributeID exp.IdentifierExpression
+		itemID      exp.IdentifierExpression
+		itemIDName  string
+		userID      exp.IdentifierExpression
+	}{
+		{
+			schema.AttrsUserValuesFloatTable, schema.AttrsUserValuesFloatTableAttributeIDCol,
+			schema.AttrsUserValuesFloatTableItemIDCol, schema.AttrsUserValuesFloatTableItemIDColName,
+			schema.AttrsUserValuesFloatTableUserIDCol,
+		},
+		{
+			schema.AttrsUserValuesIntTable, schema.AttrsUserValuesIntTableAttributeIDCol,
+			schema.AttrsUserValuesIntTableItemIDCol, schema.AttrsUserValuesIntTableItemIDColName,
+			schema.AttrsUserValuesIntTableUserIDCol,
+		},
+		{
+			schema.AttrsUserValuesStringTable, schema.AttrsUserValuesStringTableAttributeIDCol,
+			schema.AttrsUserValuesStringTableItemIDCol, schema.AttrsUserValuesStringTableItemIDColName,
+			schema.AttrsUserValuesStringTableUserIDCol,
+		},
+		{
+			schema.AttrsUserValuesListTable, schema.AttrsUserValuesListTableAttributeIDCol,
+			schema.AttrsUserValuesListTableItemIDCol, schema.AttrsUserValuesListTableItemIDColName,
+			schema.AttrsUserValuesListTableUserIDCol,
+		},
+		{
+			schema.AttrsUserValuesTable, schema.AttrsUserValuesTableAttributeIDCol,
+			schema.AttrsUserValuesTableItemIDCol, schema.AttrsUserValuesTableItemIDColName,
+			schema.AttrsUserValuesTableUserIDCol,
+		},
+	}
+
+	for _, row := range rows {
+		exists, err := tx.Select(goqu.V(true)).
+			From(schema.AttrsUserValuesTable).
+			Where(
+				schema.AttrsUserValuesTableAttributeIDCol.Eq(row.AttributeID),
+				schema.AttrsUserValuesTableItemIDCol.Eq(dstID),
+				schema.AttrsUserValuesTableUserIDCol.Eq(row.UserID),
+			).
+			ScanValContext(ctx, new(bool))
+		if err != nil {
+			return err
+		}
+
+		for _, table := range tables {
+			where := []exp.Expression{
+				table.attributeID.Eq(row.AttributeID),
+				table.itemID.Eq(srcID),
+				table.userID.Eq(row.UserID),
+			}
+
+			if exists {
+				_, err = tx.Delete(table.table).Where(where...).Executor().ExecContext(ctx)
+			} else {
+				_, err = tx.Update(table.table).Set(goqu.Record{table.itemIDName: dstID}).Where(where...).
+					Executor().ExecContext(ctx)
+			}
+
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	for _, col := range []exp.IdentifierExpression{
+		schema.AttrsValuesFloatTableItemIDCol,
+		schema.AttrsValuesIntTableItemIDCol,
+		schema.AttrsValuesStringTableItemIDCol,
+		schema.AttrsValuesListTableItemIDCol,
+		schema.AttrsValuesTableItemIDCol,
+	} {
+		_, err = tx.Delete(goqu.T(col.GetTable())).Where(col.Eq(srcID)).Executor().ExecContext(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// mergeComments moves comments of source to the topic of target and recalculates its stat.
+func mergeComments(ctx context.Context, tx *goqu.TxDatabase, srcID, dstID int64) error {
+	isItemComment := schema.CommentMessageTableTypeIDCol.Eq(schema.CommentMessageTypeIDItems)
+
+	_, err := tx.Update(schema.CommentMessageTable).
+		Set(goqu.Record{schema.CommentMessageTableItemIDColName: dstID}).
+		Where(isItemComment, schema.CommentMessageTableItemIDCol.Eq(srcID)).
+		Executor().ExecContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = moveItemRows[int64](ctx, tx, schema.CommentTopicViewTable,
+		schema.CommentTopicViewTableItemIDColName, schema.CommentTopicViewTableUserIDColName, srcID, dstID,
+		schema.CommentTopicViewTableTypeIDCol.Eq(schema.CommentMessageTypeIDItems))
+	if err != nil {
+		return err
+	}
+
+	err = moveItemRows[int64](ctx, tx, schema.CommentTopicSubscribeTable,
+		schema.CommentTopicSubscribeTableItemIDColName, schema.CommentTopicSubscribeTableUserIDColName, srcID, dstID,
+		schema.CommentTopicSubscribeTableTypeIDCol.Eq(schema.CommentMessageTypeIDItems))
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Delete(schema.CommentTopicTable).Where(
+		schema.CommentTopicTableTypeIDCol.Eq(schema.CommentMessageTypeIDItems),
+		schema.CommentTopicTableItemIDCol.Eq(srcID),
+	).Executor().ExecContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	st := struct {
+		MessagesCount int          `db:"count"`
+		LastUpdate    sql.NullTime `db:"last_update"`
+	}{}
+
+	_, err = tx.Select(
+		goqu.COUNT(goqu.Star()).As("count"),
+		goqu.MAX(schema.CommentMessageTableDatetimeCol).As("last_update"),
+	).
+		From(schema.CommentMessageTable).
+		Where(isItemComment, schema.CommentMessageTableItemIDCol.Eq(dstID)).
+		ScanStructContext(ctx, &st)
+	if err != nil {
+		return err
+	}
+
+	if st.MessagesCount <= 0 || !st.LastUpdate.Valid {
+		return nil
+	}
+
+	_, err = tx.Insert(schema.CommentTopicTable).Rows(goqu.Record{
+		schema.CommentTopicTableItemIDColName:     dstID,
+		schema.CommentTopicTableTypeIDColName:     schema.CommentMessageTypeIDItems,
+		schema.CommentTopicTableLastUpdateColName: st.LastUpdate.Time.Format(time.DateTime),
+		schema.CommentTopicTableMessagesColName:   st.MessagesCount,
+	}).OnConflict(goqu.DoUpdate(
+		schema.CommentTopicTableItemIDColName+","+schema.CommentTopicTableTypeIDColName,
+		goqu.Record{
+			schema.CommentTopicTableLastUpdateColName: goqu.Func(
+				"VALUES",
+				goqu.C(schema.CommentTopicTableLastUpdateColName),
+			),
+			schema.CommentTopicTableMessagesColName: goqu.Func(
+				"VALUES",
+				goqu.C(schema.CommentTopicTableMessagesColName),
+			),
+		},
+	)).Executor().ExecContext(ctx)
+
+	return err
+}
+
+func mergeItemMisc(ctx context.Context, tx *goqu.TxDatabase, srcID, dstID int64) error {
+	// tables, which rows are never duplicated
+	for _, col := range []exp.IdentifierExpression{
+		schema.ItemLinkTableItemIDCol,
+		schema.BrandAliasTableItemIDCol,
+		schema.OfDayTableItemIDCol,
+		schema.ModificationTableItemIDCol,
+		schema.ItemTableEngineItemIDCol,
+	} {
+		_, err := tx.Update(goqu.T(col.GetTable())).Set(goqu.Record{col.GetCol().(string): dstID}).
+			Where(col.Eq(srcID)).Executor().ExecContext(ctx)
+		if err != nil {
+			return err
+		}
+	}
+
+	moves := []struct {
+		table  exp.IdentifierExpression
+		itemID string
+		key    string
+	}{
+		{
+			schema.UserItemSubscribeTable,
+			schema.UserItemSubscribeTableItemIDColName, schema.UserItemSubscribeTableUserIDColName,
+		},
+		{
+			schema.TelegramBrandTable,
+			schema.TelegramBrandTableItemIDColName, schema.TelegramBrandTableChatIDColName,
+		},
+		{
+			schema.LogEventsItemTable,
+			schema.LogEventsItemTableItemIDColName, schema.LogEventsItemTableLogEventIDColName,
+		},
+		{schema.ItemPointTable, schema.ItemPointTableItemIDColName, ""},
+	}
+
+	for _, move := range moves {
+		err := moveItemRows[int64](ctx, tx, move.table, move.itemID, move.key, srcID, dstID)
+		if err != nil {
+			return err
+		}
+	}
+
+	// inherited vehicle types are refreshed after merge
+	_, err := tx.Delete(schema.ItemVehicleTypeTable).Where(
+		schema.ItemVehicleTypeTableItemIDCol.Eq(srcID),
+		schema.ItemVehicleTypeTableInheritedCol.IsTrue(),
+	).Executor().ExecContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = moveItemRows[int64](ctx, tx, schema.ItemVehicleTypeTable,
+		schema.ItemVehicleTypeTableItemIDColName, schema.ItemVehicleTypeTableVehicleTypeIDColName, srcID, dstID)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Delete(schema.ItemParentCacheTable).Where(goqu.Or(
+		schema.ItemParentCacheTableItemIDCol.Eq(srcID),
+		schema.ItemParentCacheTableParentIDCol.Eq(srcID),
+	)).Executor().ExecContext(ctx)
+
+	return err
+}
+
+// moveItemRows re-points rows of table from srcID to dstID. Rows of srcID, which keyCol values are already
+// present for dstID, are deleted. Empty keyCol means one row per item at most.
+func moveItemRows[K any](
+	ctx context.Context, tx *goqu.TxDatabase, table exp.IdentifierExpression, itemIDCol, keyCol string,
+	srcID, dstID int64, filter ...exp.Expression,
+) error {
+	srcWhere := slices.Concat(filter, []exp.Expression{table.Col(itemIDCol).Eq(srcID)})
+	dstWhere := slices.Concat(filter, []exp.Expression{table.Col(itemIDCol).Eq(dstID)})
+
+	if keyCol == "" {
+		exists, err := tx.Select(goqu.V(true)).From(table).Where(dstWhere...).Limit(1).
+			ScanValContext(ctx, new(bool))
+		if err != nil {
+			return err
+		}
+
+		if exists {
+			_, err = tx.Delete(table).Where(srcWhere...).Executor().ExecContext(ctx)
+
+			return err
+		}
+	} else {
+		var keys []K
+
+		err := tx.Select(table.Col(keyCol)).From(table).Where(dstWhere...).ScanValsContext(ctx, &keys)
+		if err != nil {
+			return err
+		}
+
+		if len(keys) > 0 {
+			_, err = tx.Delete(table).Where(append(srcWhere, table.Col(keyCol).In(keys))...).
+				Executor().ExecContext(ctx)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	_, err := tx.Update(table).Set(goqu.Record{itemIDCol: dstID}).Where(srcWhere...).Executor().ExecContext(ctx)
+
+	return err
+}
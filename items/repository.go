@@ -7,6 +7,7 @@ import (
 	"errors"
 	"fmt"
 	"io"
+	"maps"
 	"regexp"
 	"slices"
 	"sort"
@@ -369,6 +370,16 @@ const (
 	CataloguePathResultTypeBrandItem
 )
 
+// queryBuilder is implemented by both goqu.Database and goqu.TxDatabase, so helpers taking it
+// are shared by standalone writes and writes within transaction of caller.
+type queryBuilder interface {
+	From(from ...interface{}) *goqu.SelectDataset
+	Select(cols ...interface{}) *goqu.SelectDataset
+	Insert(table interface{}) *goqu.InsertDataset
+	Update(table interface{}) *goqu.UpdateDataset
+	Delete(table interface{}) *goqu.DeleteDataset
+}
+
 // Repository Main Object.
 type Repository struct {
 	db                               *goqu.Database
@@ -1182,6 +1193,47 @@ func (s *Repository) wrappedSelectColumns(orderBy OrderBy) map[string]Column {
 	return columns
 }
 
+func itemID(row *Item) any { return row.ID }
+
+// itemKeysets are orders supporting cursor pagination, equal to ones applied by orderBy.
+var itemKeysets = map[OrderBy]util.Keyset[*Item]{
+	OrderByIDDesc: {{Name: schema.ItemTableIDColName, Desc: true, Value: itemID}},
+	OrderByIDAsc:  {{Name: schema.ItemTableIDColName, Value: itemID}},
+}
+
+// ListByCursor returns page of items following cursor and cursor of next page, empty for the last page.
+// Unlike List it doesn't count rows and doesn't use OFFSET.
+func (s *Repository) ListByCursor(
+	ctx context.Context, options *query.ItemListOptions, fields *ItemFields, orderBy OrderBy, cursor string,
+) ([]*Item, string, error) {
+	keyset, ok := itemKeysets[orderBy]
+	if !ok {
+		return nil, "", util.ErrCursorNotSupported
+	}
+
+	after, err := keyset.Decode(cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	pageOptions := *options
+	pageOptions.After = after
+	pageOptions.Page = 0
+
+	if options.Limit > 0 {
+		pageOptions.Limit = options.Limit + 1
+	}
+
+	res, _, err := s.List(ctx, &pageOptions, fields, orderBy, false)
+	if err != nil {
+		return nil, "", err
+	}
+
+	res, next := keyset.Page(res, options.Limit)
+
+	return res, next, nil
+}
+
 func (s *Repository) List( //nolint:maintidx
 	ctx context.Context, options *query.ItemListOptions, fields *ItemFields, orderBy OrderBy,
 	pagination bool,
@@ -1218,24 +1270,29 @@ func (s *Repository) List( //nolint:maintidx
 			sqSelect = sqSelect.Order(wrappedOrderBy...)
 		}
 
-		paginator := util.Paginator{
-			SQLSelect:         sqSelect,
-			ItemCountPerPage:  int32(options.Limit), //nolint: gosec
-			CurrentPageNumber: int32(options.Page),  //nolint: gosec
-		}
+		if options.After != nil {
+			// keyset pagination, limit already includes row to detect next page
+			sqSelect = sqSelect.Limit(uint(options.Limit))
+		} else {
+			paginator := util.Paginator{
+				SQLSelect:         sqSelect,
+				ItemCountPerPage:  int32(options.Limit), //nolint: gosec
+				CurrentPageNumber: int32(options.Page),  //nolint: gosec
+			}
 
-		if pagination {
-			pages, err = paginator.GetPages(ctx)
+			if pagination {
+				pages, err = paginator.GetPages(ctx)
+				if err != nil {
+					return nil, nil, err
+				}
+			}
+
+			sqSelect, err = paginator.GetCurrentItems(ctx)
 			if err != nil {
 				return nil, nil, err
 			}
 		}
 
-		sqSelect, err = paginator.GetCurrentItems(ctx)
-		if err != nil {
-			return nil, nil, err
-		}
-
 		// implements deferred join pattern
 		wrappedAlias := "wrapped"
 		wrappedIDCol := goqu.T(wrappedAlias).Col(schema.ItemTableIDColName)
@@ -1577,6 +1634,14 @@ func (s *Repository) Tree(ctx context.Context, id string) (*TreeItem, error) {
 	}, nil
 }
 
+// AddItemVehicleTypeTx sets own vehicle type of item within transaction of caller,
+// inheritance is left to be refreshed after commit.
+func (s *Repository) AddItemVehicleTypeTx(
+	ctx context.Context, tx *goqu.TxDatabase, itemID int64, vehicleTypeID int64,
+) (bool, error) {
+	return s.setItemVehicleTypeRow(ctx, tx, itemID, vehicleTypeID, false)
+}
+
 func (s *Repository) AddItemVehicleType(
 	ctx context.Context,
 	itemID int64,
@@ -1584,7 +1649,7 @@ func (s *Repository) AddItemVehicleType(
 ) error {
 	ctx = context.WithoutCancel(ctx)
 
-	changed, err := s.setItemVehicleTypeRow(ctx, itemID, vehicleTypeID, false)
+	changed, err := s.setItemVehicleTypeRow(ctx, s.db, itemID, vehicleTypeID, false)
 	if err != nil {
 		return err
 	}
@@ -1643,11 +1708,12 @@ func (s *Repository) RemoveItemVehicleType(
 
 func (s *Repository) setItemVehicleTypeRow(
 	ctx context.Context,
+	db queryBuilder,
 	itemID int64,
 	vehicleTypeID int64,
 	inherited bool,
 ) (bool, error) {
-	res, err := s.db.Insert(schema.ItemVehicleTypeTable).Rows(goqu.Record{
+	res, err := db.Insert(schema.ItemVehicleTypeTable).Rows(goqu.Record{
 		schema.ItemVehicleTypeTableItemIDColName:        itemID,
 		schema.ItemVehicleTypeTableVehicleTypeIDColName: vehicleTypeID,
 		schema.ItemVehicleTypeTableInheritedColName:     inherited,
@@ -1749,6 +1815,11 @@ func (s *Repository) refreshItemVehicleTypeInheritance(ctx context.Context, item
 	return nil
 }
 
+// ItemVehicleTypeIDs returns vehicle types assigned to item directly, not inherited from parents.
+func (s *Repository) ItemVehicleTypeIDs(ctx context.Context, itemID int64) ([]int64, error) {
+	return s.getItemVehicleTypeIDs(ctx, itemID, false)
+}
+
 func (s *Repository) getItemVehicleTypeIDs(
 	ctx context.Context,
 	itemID int64,
@@ -1799,7 +1870,7 @@ func (s *Repository) setItemVehicleTypeRows(
 	ctx = context.WithoutCancel(ctx)
 
 	for _, t := range types {
-		rowChanged, err := s.setItemVehicleTypeRow(ctx, itemID, t, inherited)
+		rowChanged, err := s.setItemVehicleTypeRow(ctx, s.db, itemID, t, inherited)
 		if err != nil {
 			return false, err
 		}
@@ -2102,9 +2173,42 @@ func (s *Repository) ItemLanguageList(ctx context.Context, itemID int64) ([]Item
 func (s *Repository) UpdateItemLanguage(
 	ctx context.Context, itemID int64, lang, name, text, fullText string, userID int64,
 ) ([]string, error) {
+	var (
+		changes []string
+		updated bool
+	)
+
+	ctx = context.WithoutCancel(ctx)
+
+	err := s.db.WithTx(func(tx *goqu.TxDatabase) error {
+		var err error
+
+		changes, updated, err = s.UpdateItemLanguageTx(ctx, tx, itemID, lang, name, text, fullText, userID)
+
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if updated {
+		_, err = s.RefreshAutoByVehicle(ctx, itemID)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return changes, nil
+}
+
+// UpdateItemLanguageTx writes language values along with their revisions within transaction of caller,
+// returns names of changes and whether item_language row is written.
+func (s *Repository) UpdateItemLanguageTx(
+	ctx context.Context, tx *goqu.TxDatabase, itemID int64, lang, name, text, fullText string, userID int64,
+) ([]string, bool, error) {
 	var row schema.ItemLanguageRow
 
-	success, err := s.db.Select(
+	success, err := tx.Select(
 		schema.ItemLanguageTableNameCol,
 		schema.ItemLanguageTableTextIDCol,
 		schema.ItemLanguageTableFullTextIDCol,
@@ -2113,9 +2217,11 @@ func (s *Repository) UpdateItemLanguage(
 		Where(
 			schema.ItemLanguageTableItemIDCol.Eq(itemID),
 			schema.ItemLanguageTableLanguageCol.Eq(lang),
-		).ScanStructContext(ctx, &row)
+		).
+		ForUpdate(exp.Wait).
+		ScanStructContext(ctx, &row)
 	if err != nil {
-		return nil, err
+		return nil, false, err
 	}
 
 	if !success {
@@ -2138,26 +2244,26 @@ func (s *Repository) UpdateItemLanguage(
 	}
 
 	textChanged := false
-	ctx = context.WithoutCancel(ctx)
+	oldText := ""
 
 	if row.TextID.Valid {
-		oldText, err := s.textStorageRepository.Text(ctx, row.TextID.Int32)
+		oldText, err = s.textStorageRepository.TextTx(ctx, tx, row.TextID.Int32)
 		if err != nil {
-			return nil, err
+			return nil, false, err
 		}
 
 		textChanged = text != oldText
 
-		err = s.textStorageRepository.SetText(ctx, row.TextID.Int32, text, userID)
+		err = s.textStorageRepository.SetTextTx(ctx, tx, row.TextID.Int32, text, userID)
 		if err != nil {
-			return nil, err
+			return nil, false, err
 		}
 	} else if len(text) > 0 {
 		textChanged = true
 
-		textID, err := s.textStorageRepository.CreateText(ctx, text, userID)
+		textID, err := s.textStorageRepository.CreateTextTx(ctx, tx, text, userID)
 		if err != nil {
-			return nil, err
+			return nil, false, err
 		}
 
 		set[schema.ItemLanguageTableTextIDColName] = textID
@@ -2168,25 +2274,26 @@ func (s *Repository) UpdateItemLanguage(
 	}
 
 	fullTextChanged := false
+	oldFullText := ""
 
 	if row.FullTextID.Valid {
-		oldFullText, err := s.textStorageRepository.Text(ctx, row.FullTextID.Int32)
+		oldFullText, err = s.textStorageRepository.TextTx(ctx, tx, row.FullTextID.Int32)
 		if err != nil {
-			return nil, err
+			return nil, false, err
 		}
 
 		fullTextChanged = fullText != oldFullText
 
-		err = s.textStorageRepository.SetText(ctx, row.FullTextID.Int32, fullText, userID)
+		err = s.textStorageRepository.SetTextTx(ctx, tx, row.FullTextID.Int32, fullText, userID)
 		if err != nil {
-			return nil, err
+			return nil, false, err
 		}
 	} else if len(fullText) > 0 {
 		fullTextChanged = true
 
-		fullTextID, err := s.textStorageRepository.CreateText(ctx, fullText, userID)
+		fullTextID, err := s.textStorageRepository.CreateTextTx(ctx, tx, fullText, userID)
 		if err != nil {
-			return nil, err
+			return nil, false, err
 		}
 
 		set[schema.ItemLanguageTableFullTextIDColName] = fullTextID
@@ -2196,7 +2303,9 @@ func (s *Repository) UpdateItemLanguage(
 		changes = append(changes, "moder/item/full-description")
 	}
 
-	if len(set) > 0 {
+	updated := len(set) > 0
+
+	if updated {
 		onConflict := goqu.Record{}
 		for col := range set {
 			onConflict[col] = goqu.Func("VALUES", goqu.C(col))
@@ -2205,21 +2314,42 @@ func (s *Repository) UpdateItemLanguage(
 		set[schema.ItemLanguageTableItemIDColName] = itemID
 		set[schema.ItemLanguageTableLanguageColName] = lang
 
-		_, err = s.db.Insert(schema.ItemLanguageTable).Rows(set).OnConflict(goqu.DoUpdate(
+		_, err = tx.Insert(schema.ItemLanguageTable).Rows(set).OnConflict(goqu.DoUpdate(
 			schema.ItemLanguageTableItemIDColName+","+schema.ItemLanguageTableLanguageColName,
 			onConflict,
 		)).Executor().ExecContext(ctx)
 		if err != nil {
-			return nil, err
+			return nil, false, err
 		}
+	}
 
-		_, err = s.RefreshAutoByVehicle(ctx, itemID)
-		if err != nil {
-			return nil, err
-		}
+	revision := schema.ItemRevisionRow{
+		ItemID:   itemID,
+		Entity:   schema.ItemRevisionEntityItemLanguage,
+		Language: sql.NullString{String: lang, Valid: true},
 	}
+	revisions := make([]schema.ItemRevisionRow, 0)
 
-	return changes, nil
+	for _, field := range []struct {
+		name               string
+		oldValue, newValue string
+	}{
+		{ItemLanguageRevisionFieldName, oldName, name},
+		{ItemLanguageRevisionFieldText, oldText, text},
+		{ItemLanguageRevisionFieldFullText, oldFullText, fullText},
+	} {
+		revision.Field = field.name
+		revision.OldValue = sql.NullString{String: field.oldValue, Valid: true}
+		revision.NewValue = sql.NullString{String: field.newValue, Valid: true}
+		revisions = append(revisions, revision)
+	}
+
+	err = addItemRevisions(ctx, tx, userID, revisions)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return changes, updated, nil
 }
 
 func (s *Repository) ParentLanguageList(
@@ -2289,13 +2419,25 @@ func (s *Repository) SetItemParentLanguage(
 	lang string,
 	newName string,
 	forceIsAuto bool,
+) error {
+	return s.setItemParentLanguage(ctx, s.db, parentID, itemID, lang, newName, forceIsAuto)
+}
+
+func (s *Repository) setItemParentLanguage(
+	ctx context.Context,
+	db queryBuilder,
+	parentID int64,
+	itemID int64,
+	lang string,
+	newName string,
+	forceIsAuto bool,
 ) error {
 	bvlRow := struct {
 		IsAuto bool   `db:"is_auto"`
 		Name   string `db:"name"`
 	}{}
 
-	success, err := s.db.From(schema.ItemParentLanguageTable).Where(
+	success, err := db.From(schema.ItemParentLanguageTable).Where(
 		schema.ItemParentLanguageTableParentIDCol.Eq(parentID),
 		schema.ItemParentLanguageTableItemIDCol.Eq(itemID),
 		schema.ItemParentLanguageTableLanguageCol.Eq(lang),
@@ -2323,7 +2465,7 @@ func (s *Repository) SetItemParentLanguage(
 		parentRow := schema.ItemRow{}
 		itmRow := schema.ItemRow{}
 
-		success, err = s.db.Select(
+		success, err = db.Select(
 			schema.ItemTableIDCol,
 			schema.ItemTableNameCol,
 			schema.ItemTableBodyCol,
@@ -2344,7 +2486,7 @@ func (s *Repository) SetItemParentLanguage(
 			return ErrItemNotFound
 		}
 
-		success, err = s.db.Select(
+		success, err = db.Select(
 			schema.ItemTableIDCol,
 			schema.ItemTableNameCol,
 			schema.ItemTableBodyCol,
@@ -2365,7 +2507,7 @@ func (s *Repository) SetItemParentLanguage(
 			return ErrItemNotFound
 		}
 
-		newName, err = s.extractName(ctx, parentRow, itmRow, lang)
+		newName, err = s.extractName(ctx, db, parentRow, itmRow, lang)
 		if err != nil {
 			return err
 		}
@@ -2377,7 +2519,7 @@ func (s *Repository) SetItemParentLanguage(
 		newName = newName[:schema.ItemLanguageNameMaxLength]
 	}
 
-	_, err = s.db.Insert(schema.ItemParentLanguageTable).Rows(goqu.Record{
+	_, err = db.Insert(schema.ItemParentLanguageTable).Rows(goqu.Record{
 		schema.ItemParentLanguageTableItemIDColName:   itemID,
 		schema.ItemParentLanguageTableParentIDColName: parentID,
 		schema.ItemParentLanguageTableLanguageColName: lang,
@@ -2404,12 +2546,13 @@ func (s *Repository) SetItemParentLanguage(
 
 func (s *Repository) namePreferLanguage(
 	ctx context.Context,
+	db queryBuilder,
 	parentID, itemID int64,
 	lang string,
 ) (string, error) {
 	res := ""
 
-	success, err := s.db.Select(schema.ItemParentLanguageTableNameCol).
+	success, err := db.Select(schema.ItemParentLanguageTableNameCol).
 		From(schema.ItemParentLanguageTable).
 		Where(
 			schema.ItemParentLanguageTableItemIDCol.Eq(itemID),
@@ -2431,17 +2574,18 @@ func (s *Repository) namePreferLanguage(
 
 func (s *Repository) extractCatname(
 	ctx context.Context,
+	db queryBuilder,
 	brandRow, vehicleRow schema.ItemRow,
 ) (string, error) {
 	var err error
 
-	diffName, err := s.namePreferLanguage(ctx, brandRow.ID, vehicleRow.ID, "en")
+	diffName, err := s.namePreferLanguage(ctx, db, brandRow.ID, vehicleRow.ID, "en")
 	if err != nil {
 		return "", err
 	}
 
 	if len(diffName) == 0 {
-		diffName, err = s.extractName(ctx, brandRow, vehicleRow, "en")
+		diffName, err = s.extractName(ctx, db, brandRow, vehicleRow, "en")
 		if err != nil {
 			return "", err
 		}
@@ -2459,7 +2603,7 @@ func (s *Repository) extractCatname(
 			catname = catname + "_" + strconv.Itoa(i)
 		}
 
-		allowed, err = s.isAllowedCatname(ctx, vehicleRow.ID, brandRow.ID, catname)
+		allowed, err = s.isAllowedCatname(ctx, db, vehicleRow.ID, brandRow.ID, catname)
 		if err != nil {
 			return "", err
 		}
@@ -2471,9 +2615,9 @@ func (s *Repository) extractCatname(
 }
 
 func (s *Repository) extractName(
-	ctx context.Context, parentRow schema.ItemRow, vehicleRow schema.ItemRow, lang string,
+	ctx context.Context, db queryBuilder, parentRow schema.ItemRow, vehicleRow schema.ItemRow, lang string,
 ) (string, error) {
-	langName, err := s.getName(ctx, vehicleRow.ID, lang)
+	langName, err := s.getName(ctx, db, vehicleRow.ID, lang)
 	if err != nil {
 		return "", err
 	}
@@ -2483,7 +2627,7 @@ func (s *Repository) extractName(
 		vehicleName = vehicleRow.Name
 	}
 
-	aliases, err := s.getAliases(ctx, parentRow.ID)
+	aliases, err := s.getAliases(ctx, db, parentRow.ID)
 	if err != nil {
 		return "", err
 	}
@@ -2543,7 +2687,7 @@ func (s *Repository) extractName(
 		if specsDifferent {
 			specShortName := ""
 
-			success, err := s.db.Select(schema.SpecTableShortNameCol).From(schema.SpecTable).
+			success, err := db.Select(schema.SpecTableShortNameCol).From(schema.SpecTable).
 				Where(schema.SpecTableIDCol.Eq(vehicleRow.SpecID.Int32)).
 				ScanValContext(ctx, &specShortName)
 			if err != nil {
@@ -2563,16 +2707,16 @@ func (s *Repository) extractName(
 	return name, nil
 }
 
-func (s *Repository) getAliases(ctx context.Context, itemID int64) ([]string, error) {
+func (s *Repository) getAliases(ctx context.Context, db queryBuilder, itemID int64) ([]string, error) {
 	var aliases []string
 
-	err := s.db.Select(schema.BrandAliasTableNameCol).From(schema.BrandAliasTable).
+	err := db.Select(schema.BrandAliasTableNameCol).From(schema.BrandAliasTable).
 		Where(schema.BrandAliasTableItemIDCol.Eq(itemID)).ScanValsContext(ctx, &aliases)
 	if err != nil {
 		return nil, err
 	}
 
-	langNames, err := s.getNames(ctx, itemID)
+	langNames, err := s.getNames(ctx, db, itemID)
 	if err != nil {
 		return nil, err
 	}
@@ -2586,7 +2730,7 @@ func (s *Repository) getAliases(ctx context.Context, itemID int64) ([]string, er
 	return aliases, nil
 }
 
-func (s *Repository) getName(ctx context.Context, itemID int64, lang string) (string, error) {
+func (s *Repository) getName(ctx context.Context, db queryBuilder, itemID int64, lang string) (string, error) {
 	langPriority, ok := languagePriority[lang]
 	if !ok {
 		langPriority, ok = languagePriority[DefaultLanguageCode]
@@ -2605,7 +2749,7 @@ func (s *Repository) getName(ctx context.Context, itemID int64, lang string) (st
 
 	result := ""
 
-	success, err := s.db.Select(schema.ItemLanguageTableNameCol).
+	success, err := db.Select(schema.ItemLanguageTableNameCol).
 		From(schema.ItemLanguageTable).
 		Where(
 			schema.ItemLanguageTableItemIDCol.Eq(itemID),
@@ -2625,10 +2769,10 @@ func (s *Repository) getName(ctx context.Context, itemID int64, lang string) (st
 	return result, nil
 }
 
-func (s *Repository) getNames(ctx context.Context, itemID int64) ([]string, error) {
+func (s *Repository) getNames(ctx context.Context, db queryBuilder, itemID int64) ([]string, error) {
 	var result []string
 
-	err := s.db.Select(schema.ItemLanguageTableNameCol).From(schema.ItemLanguageTable).
+	err := db.Select(schema.ItemLanguageTableNameCol).From(schema.ItemLanguageTable).
 		Where(
 			schema.ItemLanguageTableItemIDCol.Eq(itemID),
 			goqu.L("? > 0", goqu.Func("length", schema.ItemLanguageTableNameCol)),
@@ -2668,6 +2812,7 @@ func (s *Repository) isAllowedCombination(
 
 func (s *Repository) isAllowedCatname(
 	ctx context.Context,
+	db queryBuilder,
 	itemID, parentID int64,
 	catname string,
 ) (bool, error) {
@@ -2681,7 +2826,7 @@ func (s *Repository) isAllowedCatname(
 
 	var exists bool
 
-	success, err := s.db.Select(goqu.V(true)).From(schema.ItemParentTable).Where(
+	success, err := db.Select(goqu.V(true)).From(schema.ItemParentTable).Where(
 		schema.ItemParentTableParentIDCol.Eq(parentID),
 		schema.ItemParentTableCatnameCol.Eq(catname),
 		schema.ItemParentTableItemIDCol.Neq(itemID),
@@ -2693,7 +2838,7 @@ func (s *Repository) isAllowedCatname(
 	return !success || !exists, nil
 }
 
-func (s *Repository) collectAncestorsIDs(ctx context.Context, id int64) ([]int64, error) {
+func (s *Repository) collectAncestorsIDs(ctx context.Context, db queryBuilder, id int64) ([]int64, error) {
 	var (
 		toCheck = []int64{id}
 		ids     []int64
@@ -2704,7 +2849,7 @@ func (s *Repository) collectAncestorsIDs(ctx context.Context, id int64) ([]int64
 
 		var res []int64
 
-		err := s.db.Select(schema.ItemParentTableParentIDCol).
+		err := db.Select(schema.ItemParentTableParentIDCol).
 			From(schema.ItemParentTable).
 			Where(schema.ItemParentTableItemIDCol.In(toCheck)).
 			ScanValsContext(ctx, &res)
@@ -2720,6 +2865,7 @@ func (s *Repository) collectAncestorsIDs(ctx context.Context, id int64) ([]int64
 
 func (s *Repository) setItemParentLanguages(
 	ctx context.Context,
+	db queryBuilder,
 	parentID, itemID int64,
 	values map[string]schema.ItemParentLanguageRow,
 	forceIsAuto bool,
@@ -2732,7 +2878,7 @@ func (s *Repository) setItemParentLanguages(
 			name = values[lang].Name
 		}
 
-		err := s.SetItemParentLanguage(ctx, parentID, itemID, lang, name, forceIsAuto)
+		err := s.setItemParentLanguage(ctx, db, parentID, itemID, lang, name, forceIsAuto)
 		if err != nil {
 			return err
 		}
@@ -2741,19 +2887,44 @@ func (s *Repository) setItemParentLanguages(
 	return nil
 }
 
+// CreateItemParent links item to parent, link is recorded in revisions of user along with the insert.
 func (s *Repository) CreateItemParent(
-	ctx context.Context, itemID, parentID int64, typeID schema.ItemParentType, catname string,
+	ctx context.Context, itemID, parentID int64, typeID schema.ItemParentType, catname string, userID int64,
+) (bool, error) {
+	ctx = context.WithoutCancel(ctx)
+	created := false
+
+	err := s.db.WithTx(func(tx *goqu.TxDatabase) error {
+		var err error
+
+		created, err = s.CreateItemParentTx(ctx, tx, itemID, parentID, typeID, catname, userID)
+
+		return err
+	})
+	if err != nil || !created {
+		return false, err
+	}
+
+	_, err = s.RebuildCache(ctx, itemID)
+
+	return err == nil, err
+}
+
+// CreateItemParentTx links item to parent within transaction of caller, caches are left to be rebuilt after commit.
+func (s *Repository) CreateItemParentTx(
+	ctx context.Context, tx *goqu.TxDatabase, itemID, parentID int64, typeID schema.ItemParentType, catname string,
+	userID int64,
 ) (bool, error) {
 	if itemID == parentID {
 		return false, errSelfParent
 	}
 
-	parentRow, err := s.Item(ctx, &query.ItemListOptions{ItemID: parentID}, nil)
+	parentRow, err := itemParentLinkRow(ctx, tx, parentID)
 	if err != nil {
 		return false, err
 	}
 
-	itemRow, err := s.Item(ctx, &query.ItemListOptions{ItemID: itemID}, nil)
+	itemRow, err := itemParentLinkRow(ctx, tx, itemID)
 	if err != nil {
 		return false, err
 	}
@@ -2772,7 +2943,7 @@ func (s *Repository) CreateItemParent(
 	}
 
 	if len(catname) > 0 {
-		allowed, err := s.isAllowedCatname(ctx, itemID, parentID, catname)
+		allowed, err := s.isAllowedCatname(ctx, tx, itemID, parentID, catname)
 		if err != nil {
 			return false, err
 		}
@@ -2785,7 +2956,7 @@ func (s *Repository) CreateItemParent(
 	manualCatname := len(catname) > 0 && catname != "_"
 
 	if !manualCatname {
-		catname, err = s.extractCatname(ctx, parentRow.ItemRow, itemRow.ItemRow)
+		catname, err = s.extractCatname(ctx, tx, parentRow, itemRow)
 		if err != nil {
 			return false, err
 		}
@@ -2795,7 +2966,7 @@ func (s *Repository) CreateItemParent(
 		}
 	}
 
-	parentIDs, err := s.collectAncestorsIDs(ctx, parentID)
+	parentIDs, err := s.collectAncestorsIDs(ctx, tx, parentID)
 	if err != nil {
 		return false, err
 	}
@@ -2804,30 +2975,24 @@ func (s *Repository) CreateItemParent(
 		return false, errItemParentCycle
 	}
 
-	exists := false
-
-	success, err := s.db.Select(goqu.V(true)).From(schema.ItemParentTable).Where(
-		schema.ItemParentTableItemIDCol.Eq(itemID),
-		schema.ItemParentTableParentIDCol.Eq(parentID),
-	).ScanValContext(ctx, &exists)
-	if err != nil {
-		return false, err
-	}
-
-	if success && exists {
-		return false, nil
-	}
-
-	ctx = context.WithoutCancel(ctx)
-
-	_, err = s.db.Insert(schema.ItemParentTable).Rows(goqu.Record{
+	res, err := tx.Insert(schema.ItemParentTable).Rows(goqu.Record{
 		schema.ItemParentTableParentIDColName:      parentID,
 		schema.ItemParentTableItemIDColName:        itemID,
 		schema.ItemParentTableTypeColName:          typeID,
 		schema.ItemParentTableCatnameColName:       catname,
 		schema.ItemParentTableManualCatnameColName: manualCatname,
 		schema.ItemParentTableTimestampColName:     goqu.Func("NOW"),
-	}).Executor().ExecContext(ctx)
+	}).OnConflict(goqu.DoNothing()).Executor().ExecContext(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil || affected == 0 {
+		return false, err
+	}
+
+	err = s.AddItemParentRevisions(ctx, tx, userID, itemID, parentID, nil)
 	if err != nil {
 		return false, err
 	}
@@ -2835,7 +3000,7 @@ func (s *Repository) CreateItemParent(
 	values := make(map[string]schema.ItemParentLanguageRow)
 
 	for _, lang := range s.contentLanguages {
-		name, err := s.extractName(ctx, parentRow.ItemRow, itemRow.ItemRow, lang)
+		name, err := s.extractName(ctx, tx, parentRow, itemRow, lang)
 		if err != nil {
 			return false, err
 		}
@@ -2845,51 +3010,54 @@ func (s *Repository) CreateItemParent(
 		}
 	}
 
-	err = s.setItemParentLanguages(ctx, parentID, itemID, values, true)
+	err = s.setItemParentLanguages(ctx, tx, parentID, itemID, values, true)
 	if err != nil {
 		return false, err
 	}
 
-	_, err = s.RebuildCache(ctx, itemID)
-
-	return err == nil, err
+	return true, nil
 }
 
-func (s *Repository) UpdateItemParent(
-	ctx context.Context,
-	itemID, parentID int64,
-	typeID schema.ItemParentType,
-	catname string,
-	forceIsAuto bool,
-) (bool, error) {
-	var itemParentRow schema.ItemParentRow
+// itemParentLinkRow reads columns of item required to link it and to extract catname and name of link.
+func itemParentLinkRow(ctx context.Context, db queryBuilder, itemID int64) (schema.ItemRow, error) {
+	var row schema.ItemRow
 
-	success, err := s.db.Select(
-		schema.ItemParentTableCatnameCol,
-		schema.ItemParentTableManualCatnameCol,
+	success, err := db.Select(
+		schema.ItemTableIDCol,
+		schema.ItemTableItemTypeIDCol,
+		schema.ItemTableIsGroupCol,
+		schema.ItemTableNameCol,
+		schema.ItemTableBodyCol,
+		schema.ItemTableSpecIDCol,
+		schema.ItemTableBeginYearCol,
+		schema.ItemTableEndYearCol,
+		schema.ItemTableBeginModelYearCol,
+		schema.ItemTableEndModelYearCol,
 	).
-		From(schema.ItemParentTable).Where(
-		schema.ItemParentTableParentIDCol.Eq(parentID),
-		schema.ItemParentTableItemIDCol.Eq(itemID),
-	).ScanStructContext(ctx, &itemParentRow)
+		From(schema.ItemTable).
+		Where(schema.ItemTableIDCol.Eq(itemID)).
+		ScanStructContext(ctx, &row)
 	if err != nil {
-		return false, err
+		return row, err
 	}
 
 	if !success {
-		return false, nil
+		return row, ErrItemNotFound
 	}
 
-	var isAuto bool
+	return row, nil
+}
 
-	if forceIsAuto {
-		isAuto = true
-	} else {
-		isAuto = !itemParentRow.ManualCatname
-		if itemParentRow.Catname != catname {
-			isAuto = false
-		}
-	}
+// UpdateItemParent changes link between item and parent, change is recorded in revisions of user along with the update.
+func (s *Repository) UpdateItemParent(
+	ctx context.Context,
+	itemID, parentID int64,
+	typeID schema.ItemParentType,
+	catname string,
+	forceIsAuto bool,
+	userID int64,
+) (bool, error) {
+	isAuto := forceIsAuto
 
 	if len(catname) == 0 || catname == "_" || util.Contains(catnameBlacklist, catname) {
 		parentRow, err := s.Item(
@@ -2898,6 +3066,10 @@ func (s *Repository) UpdateItemParent(
 			&ItemFields{NameText: true},
 		)
 		if err != nil {
+			if errors.Is(err, ErrItemNotFound) {
+				return false, nil
+			}
+
 			return false, err
 		}
 
@@ -2907,10 +3079,14 @@ func (s *Repository) UpdateItemParent(
 			&ItemFields{NameText: true},
 		)
 		if err != nil {
+			if errors.Is(err, ErrItemNotFound) {
+				return false, nil
+			}
+
 			return false, err
 		}
 
-		catname, err = s.extractCatname(ctx, parentRow.ItemRow, itemRow.ItemRow)
+		catname, err = s.extractCatname(ctx, s.db, parentRow.ItemRow, itemRow.ItemRow)
 		if err != nil {
 			return false, err
 		}
@@ -2919,58 +3095,105 @@ func (s *Repository) UpdateItemParent(
 	}
 
 	ctx = context.WithoutCancel(ctx)
+	updated := false
 
-	res, err := s.db.Update(schema.ItemParentTable).Set(goqu.Record{
-		schema.ItemParentTableTypeColName:          typeID,
-		schema.ItemParentTableCatnameColName:       catname,
-		schema.ItemParentTableManualCatnameColName: !isAuto,
-	}).Where(
-		schema.ItemParentTableParentIDCol.Eq(parentID),
-		schema.ItemParentTableItemIDCol.Eq(itemID),
-	).Executor().ExecContext(ctx)
-	if err != nil {
-		return false, err
-	}
+	err := s.db.WithTx(func(tx *goqu.TxDatabase) error {
+		var oldRow schema.ItemParentRow
 
-	affected, err := res.RowsAffected()
-	if err != nil {
-		return false, err
-	}
+		success, err := tx.From(schema.ItemParentTable).Where(
+			schema.ItemParentTableParentIDCol.Eq(parentID),
+			schema.ItemParentTableItemIDCol.Eq(itemID),
+		).ForUpdate(exp.Wait).ScanStructContext(ctx, &oldRow)
+		if err != nil || !success {
+			return err
+		}
 
-	if affected > 0 {
-		_, err = s.RebuildCache(ctx, itemID)
+		if !isAuto {
+			isAuto = !oldRow.ManualCatname && oldRow.Catname == catname
+		}
+
+		res, err := tx.Update(schema.ItemParentTable).Set(goqu.Record{
+			schema.ItemParentTableTypeColName:          typeID,
+			schema.ItemParentTableCatnameColName:       catname,
+			schema.ItemParentTableManualCatnameColName: !isAuto,
+		}).Where(
+			schema.ItemParentTableParentIDCol.Eq(parentID),
+			schema.ItemParentTableItemIDCol.Eq(itemID),
+		).Executor().ExecContext(ctx)
+		if err != nil {
+			return err
+		}
+
+		affected, err := res.RowsAffected()
+		if err != nil {
+			return err
+		}
 
-		return true, err
+		updated = affected > 0
+		if !updated {
+			return nil
+		}
+
+		return s.AddItemParentRevisions(ctx, tx, userID, itemID, parentID, &oldRow)
+	})
+	if err != nil || !updated {
+		return false, err
 	}
 
-	return false, nil
+	_, err = s.RebuildCache(ctx, itemID)
+
+	return true, err
 }
 
-func (s *Repository) RemoveItemParent(ctx context.Context, itemID, parentID int64) error {
+// RemoveItemParent unlinks item from parent, removal is recorded in revisions of user along with the delete.
+func (s *Repository) RemoveItemParent(ctx context.Context, itemID, parentID int64, userID int64) error {
 	ctx = context.WithoutCancel(ctx)
 
-	res, err := s.db.Delete(schema.ItemParentTable).Where(
-		schema.ItemParentTableItemIDCol.Eq(itemID),
-		schema.ItemParentTableParentIDCol.Eq(parentID),
-	).Executor().ExecContext(ctx)
-	if err != nil {
-		return err
-	}
+	var affectedItemParentRows, affectedItemParentLanguageRows int64
 
-	affectedItemParentRows, err := res.RowsAffected()
-	if err != nil {
-		return err
-	}
+	err := s.db.WithTx(func(tx *goqu.TxDatabase) error {
+		var oldRow schema.ItemParentRow
 
-	res, err = s.db.Delete(schema.ItemParentLanguageTable).Where(
-		schema.ItemParentLanguageTableItemIDCol.Eq(itemID),
-		schema.ItemParentLanguageTableParentIDCol.Eq(parentID),
-	).Executor().ExecContext(ctx)
-	if err != nil {
-		return err
-	}
+		success, err := tx.From(schema.ItemParentTable).Where(
+			schema.ItemParentTableItemIDCol.Eq(itemID),
+			schema.ItemParentTableParentIDCol.Eq(parentID),
+		).ForUpdate(exp.Wait).ScanStructContext(ctx, &oldRow)
+		if err != nil {
+			return err
+		}
 
-	affectedItemParentLanguageRows, err := res.RowsAffected()
+		res, err := tx.Delete(schema.ItemParentTable).Where(
+			schema.ItemParentTableItemIDCol.Eq(itemID),
+			schema.ItemParentTableParentIDCol.Eq(parentID),
+		).Executor().ExecContext(ctx)
+		if err != nil {
+			return err
+		}
+
+		affectedItemParentRows, err = res.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		res, err = tx.Delete(schema.ItemParentLanguageTable).Where(
+			schema.ItemParentLanguageTableItemIDCol.Eq(itemID),
+			schema.ItemParentLanguageTableParentIDCol.Eq(parentID),
+		).Executor().ExecContext(ctx)
+		if err != nil {
+			return err
+		}
+
+		affectedItemParentLanguageRows, err = res.RowsAffected()
+		if err != nil {
+			return err
+		}
+
+		if !success {
+			return nil
+		}
+
+		return s.AddItemParentRevisions(ctx, tx, userID, itemID, parentID, &oldRow)
+	})
 	if err != nil {
 		return err
 	}
@@ -3189,7 +3412,7 @@ func (s *Repository) MoveItemParent(
 		)
 	}
 
-	parentIDs, err := s.collectAncestorsIDs(ctx, newParentRow.ID)
+	parentIDs, err := s.collectAncestorsIDs(ctx, s.db, newParentRow.ID)
 	if err != nil {
 		return false, err
 	}
@@ -3319,7 +3542,7 @@ func (s *Repository) refreshItemParentLanguage(ctx context.Context, parentID, it
 		values[iplRow.Language] = row
 	}
 
-	return s.setItemParentLanguages(ctx, parentID, itemID, values, false)
+	return s.setItemParentLanguages(ctx, s.db, parentID, itemID, values, false)
 }
 
 func (s *Repository) refreshAuto(ctx context.Context, parentID, itemID int64) (bool, error) {
@@ -3367,7 +3590,7 @@ func (s *Repository) refreshAuto(ctx context.Context, parentID, itemID int64) (b
 		return false, err
 	}
 
-	catname, err := s.extractCatname(ctx, brandRow.ItemRow, vehicleRow.ItemRow)
+	catname, err := s.extractCatname(ctx, s.db, brandRow.ItemRow, vehicleRow.ItemRow)
 	if err != nil {
 		return false, err
 	}
@@ -3538,6 +3761,86 @@ func (s *Repository) ItemParents(
 	return res, pages, err
 }
 
+// ItemsParentIDs returns ids of parents of items, at most limit per item.
+func (s *Repository) ItemsParentIDs(ctx context.Context, itemIDs []int64, limit uint32) (map[int64][]int64, error) {
+	return s.itemParentLinkIDs(
+		ctx, schema.ItemParentTableItemIDCol, schema.ItemParentTableParentIDCol, itemIDs, limit,
+		[]exp.OrderedExpression{schema.ItemParentTableParentIDCol.Asc()},
+	)
+}
+
+// ItemsChildIDs returns ids of childs of items in the same order as ItemParentOrderByAuto, at most limit per item.
+func (s *Repository) ItemsChildIDs(ctx context.Context, parentIDs []int64, limit uint32) (map[int64][]int64, error) {
+	return s.itemParentLinkIDs(
+		ctx, schema.ItemParentTableParentIDCol, schema.ItemParentTableItemIDCol, parentIDs, limit,
+		[]exp.OrderedExpression{
+			schema.ItemParentTableTypeCol.Asc(),
+			schema.ItemTableBeginOrderCacheCol.Asc(),
+			schema.ItemTableEndOrderCacheCol.Asc(),
+			schema.ItemTableNameCol.Asc(),
+			schema.ItemTableBodyCol.Asc(),
+			schema.ItemTableSpecIDCol.Asc(),
+			schema.ItemParentTableItemIDCol.Asc(),
+		},
+	)
+}
+
+// itemParentLinkIDs groups item_parent links by source column, limit is applied per source in SQL.
+func (s *Repository) itemParentLinkIDs(
+	ctx context.Context, source, target exp.IdentifierExpression, ids []int64, limit uint32,
+	order []exp.OrderedExpression,
+) (map[int64][]int64, error) {
+	result := make(map[int64][]int64, len(ids))
+
+	if len(ids) == 0 {
+		return result, nil
+	}
+
+	const (
+		sourceAlias    = "source_id"
+		targetAlias    = "target_id"
+		rowNumberAlias = "num"
+	)
+
+	args := make([]interface{}, 0, len(order)+1)
+	args = append(args, source)
+
+	for _, expr := range order {
+		args = append(args, expr)
+	}
+
+	subSelect := s.db.Select(
+		source.As(sourceAlias),
+		target.As(targetAlias),
+		goqu.L(
+			"ROW_NUMBER() OVER(PARTITION BY ? ORDER BY "+strings.Repeat("?, ", len(order)-1)+"?)", args...,
+		).As(rowNumberAlias),
+	).
+		From(schema.ItemParentTable).
+		Join(schema.ItemTable, goqu.On(schema.ItemParentTableItemIDCol.Eq(schema.ItemTableIDCol))).
+		Where(source.In(ids))
+
+	var rows []struct {
+		SourceID int64 `db:"source_id"`
+		TargetID int64 `db:"target_id"`
+	}
+
+	err := s.db.Select(sourceAlias, targetAlias).
+		From(subSelect.As("t")).
+		Where(goqu.C(rowNumberAlias).Lte(limit)).
+		Order(goqu.C(sourceAlias).Asc(), goqu.C(rowNumberAlias).Asc()).
+		ScanStructsContext(ctx, &rows)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		result[row.SourceID] = append(result[row.SourceID], row.TargetID)
+	}
+
+	return result, nil
+}
+
 func (s *Repository) ItemParent(
 	ctx context.Context, listOptions *query.ItemParentListOptions, fields ItemParentFields,
 ) (*ItemParent, error) {
@@ -4613,6 +4916,18 @@ func (s *Repository) UpdateOrderCache(ctx context.Context, itemID int64) (bool,
 		return false, nil
 	}
 
+	begin, end := orderCacheDates(row)
+
+	_, err = s.db.Update(schema.ItemTable).Set(goqu.Record{
+		schema.ItemTableBeginOrderCacheColName: begin.String(),
+		schema.ItemTableEndOrderCacheColName:   end.String(),
+	}).Where(schema.ItemTableIDCol.Eq(itemID)).Executor().ExecContext(ctx)
+
+	return true, err
+}
+
+// orderCacheDates returns dates of production begin and end used to sort items chronologically.
+func orderCacheDates(row schema.ItemRow) (civil.Date, civil.Date) {
 	begin := civil.Date{
 		Day: 1,
 	}
@@ -4664,12 +4979,7 @@ func (s *Repository) UpdateOrderCache(ctx context.Context, itemID int64) (bool,
 	begin = begin.AddDays(0)
 	end = end.AddDays(0)
 
-	_, err = s.db.Update(schema.ItemTable).Set(goqu.Record{
-		schema.ItemTableBeginOrderCacheColName: begin.String(),
-		schema.ItemTableEndOrderCacheColName:   end.String(),
-	}).Where(schema.ItemTableIDCol.Eq(itemID)).Executor().ExecContext(ctx)
-
-	return true, err
+	return begin, end
 }
 
 func (s *Repository) FirstCharacters(ctx context.Context) ([]string, error) {
@@ -4693,7 +5003,35 @@ func (s *Repository) CreateItem(
 	row schema.ItemRow,
 	userID int64,
 ) (int64, error) {
-	res, err := s.db.Insert(schema.ItemTable).Rows(row).Executor().ExecContext(ctx)
+	var itemID int64
+
+	err := s.db.WithTx(func(tx *goqu.TxDatabase) error {
+		var err error
+
+		itemID, err = s.CreateItemTx(ctx, tx, row)
+
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	err = s.RefreshItemCaches(ctx, itemID)
+	if err != nil {
+		return 0, err
+	}
+
+	err = s.UserItemSubscribe(ctx, itemID, userID)
+	if err != nil {
+		return 0, err
+	}
+
+	return itemID, nil
+}
+
+// CreateItemTx inserts item within transaction of caller, caches are left to be refreshed after commit.
+func (s *Repository) CreateItemTx(ctx context.Context, tx *goqu.TxDatabase, row schema.ItemRow) (int64, error) {
+	res, err := tx.Insert(schema.ItemTable).Rows(row).Executor().ExecContext(ctx)
 	if err != nil {
 		return 0, err
 	}
@@ -4704,38 +5042,38 @@ func (s *Repository) CreateItem(
 	}
 
 	if len(row.Name) > 0 {
-		err = s.setItemLanguageName(ctx, itemID, DefaultLanguageCode, row.Name)
+		err = s.setItemLanguageName(ctx, tx, itemID, DefaultLanguageCode, row.Name)
 		if err != nil {
 			return 0, err
 		}
 	}
 
-	_, err = s.UpdateOrderCache(ctx, itemID)
+	return itemID, nil
+}
+
+// RefreshItemCaches recalculates data derived from item, its links and vehicle types.
+func (s *Repository) RefreshItemCaches(ctx context.Context, itemID int64) error {
+	_, err := s.UpdateOrderCache(ctx, itemID)
 	if err != nil {
-		return 0, err
+		return err
 	}
 
 	_, err = s.RebuildCache(ctx, itemID)
 	if err != nil {
-		return 0, err
+		return err
 	}
 
 	err = s.RefreshItemVehicleTypeInheritanceFromParents(ctx, itemID)
 	if err != nil {
-		return 0, err
-	}
-
-	err = s.UserItemSubscribe(ctx, itemID, userID)
-	if err != nil {
-		return 0, err
+		return err
 	}
 
-	err = s.UpdateInheritance(ctx, itemID)
+	err = s.refreshItemVehicleTypeInheritance(ctx, itemID)
 	if err != nil {
-		return 0, err
+		return err
 	}
 
-	return itemID, nil
+	return s.UpdateInheritance(ctx, itemID)
 }
 
 func (s *Repository) SpecExists(ctx context.Context, specID int32) (bool, error) {
@@ -4755,166 +5093,190 @@ func (s *Repository) UpdateItem(
 	mask []string,
 	userID int64,
 ) error {
+	ctx = context.WithoutCancel(ctx)
+
+	err := s.db.WithTx(func(tx *goqu.TxDatabase) error {
+		return s.UpdateItemTx(ctx, tx, row, mask, userID)
+	})
+	if err != nil {
+		return err
+	}
+
+	subscribe := len(itemUpdateSet(row, mask)) > 0
+
+	err = s.UpdateInheritance(ctx, row.ID)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.UpdateOrderCache(ctx, row.ID)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.RefreshAutoByVehicle(ctx, row.ID)
+	if err != nil {
+		return err
+	}
+
+	if subscribe {
+		err = s.UserItemSubscribe(ctx, row.ID, userID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// UpdateItemTx writes fields of mask along with their revisions within transaction of caller,
+// caches are left to be refreshed after commit.
+func (s *Repository) UpdateItemTx(
+	ctx context.Context, tx *goqu.TxDatabase, row schema.ItemRow, mask []string, userID int64,
+) error {
+	set := itemUpdateSet(row, mask)
+
+	if len(set) > 0 {
+		cols := slices.Sorted(maps.Keys(set))
+
+		oldValues, err := itemRevisionValuesForUpdate(ctx, tx, row.ID, cols)
+		if err != nil {
+			return err
+		}
+
+		_, err = tx.Update(schema.ItemTable).
+			Set(set).
+			Where(schema.ItemTableIDCol.Eq(row.ID)).
+			Executor().ExecContext(ctx)
+		if err != nil {
+			return err
+		}
+
+		revisions := make([]schema.ItemRevisionRow, 0, len(cols))
+		for _, col := range cols {
+			revisions = append(revisions, schema.ItemRevisionRow{
+				ItemID:   row.ID,
+				Entity:   schema.ItemRevisionEntityItem,
+				Field:    col,
+				OldValue: oldValues[col],
+				NewValue: RevisionValue(set[col]),
+			})
+		}
+
+		err = addItemRevisions(ctx, tx, userID, revisions)
+		if err != nil {
+			return err
+		}
+	}
+
+	if util.Contains(mask, "name") {
+		err := s.setItemLanguageName(ctx, tx, row.ID, DefaultLanguageCode, row.Name)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// itemUpdateSet collects values of item columns listed in mask.
+func itemUpdateSet(row schema.ItemRow, mask []string) goqu.Record {
 	set := goqu.Record{}
-	subscribe := false
 
 	if util.Contains(mask, "name") {
-		subscribe = true
 		set[schema.ItemTableNameColName] = row.Name
 	}
 
 	if util.Contains(mask, "full_name") {
-		subscribe = true
 		set[schema.ItemTableFullNameColName] = row.FullName
 	}
 
 	if util.Contains(mask, "body") {
-		subscribe = true
 		set[schema.ItemTableBodyColName] = row.Body
 	}
 
 	if util.Contains(mask, "begin_year") {
-		subscribe = true
 		set[schema.ItemTableBeginYearColName] = row.BeginYear
 	}
 
 	if util.Contains(mask, "begin_month") {
-		subscribe = true
 		set[schema.ItemTableBeginMonthColName] = row.BeginMonth
 	}
 
 	if util.Contains(mask, "end_year") {
-		subscribe = true
 		set[schema.ItemTableEndYearColName] = row.EndYear
 	}
 
 	if util.Contains(mask, "end_month") {
-		subscribe = true
 		set[schema.ItemTableEndMonthColName] = row.EndMonth
 	}
 
 	if util.Contains(mask, "today") {
-		subscribe = true
-
 		set[schema.ItemTableTodayColName] = row.Today
 	}
 
 	if util.Contains(mask, "begin_model_year") {
-		subscribe = true
 		set[schema.ItemTableBeginModelYearColName] = row.BeginModelYear
 	}
 
 	if util.Contains(mask, "end_model_year") {
-		subscribe = true
 		set[schema.ItemTableEndModelYearColName] = row.EndModelYear
 	}
 
 	if util.Contains(mask, "begin_model_year_fraction") {
-		subscribe = true
 		set[schema.ItemTableBeginModelYearFractionColName] = row.BeginModelYearFraction
 	}
 
 	if util.Contains(mask, "end_model_year_fraction") {
-		subscribe = true
 		set[schema.ItemTableEndModelYearFractionColName] = row.EndModelYearFraction
 	}
 
 	if util.Contains(mask, "is_concept") {
-		subscribe = true
 		set[schema.ItemTableIsConceptColName] = row.IsConcept
 	}
 
 	if util.Contains(mask, "is_concept_inherit") {
-		subscribe = true
 		set[schema.ItemTableIsConceptInheritColName] = row.IsConceptInherit
 	}
 
 	if util.Contains(mask, "catname") {
-		subscribe = true
 		set[schema.ItemTableCatnameColName] = row.Catname
 	}
 
 	if util.Contains(mask, "produced") {
-		subscribe = true
 		set[schema.ItemTableProducedColName] = row.Produced
 	}
 
 	if util.Contains(mask, "produced_exactly") {
-		subscribe = true
 		set[schema.ItemTableProducedExactlyColName] = row.ProducedExactly
 	}
 
 	if util.Contains(mask, "is_group") {
-		subscribe = true
 		set[schema.ItemTableIsGroupColName] = row.IsGroup
 	}
 
 	if util.Contains(mask, "spec_inherit") {
-		subscribe = true
 		set[schema.ItemTableSpecInheritColName] = row.SpecInherit
 	}
 
 	if util.Contains(mask, "spec_id") {
-		subscribe = true
 		set[schema.ItemTableSpecIDColName] = row.SpecID
 	}
 
 	if util.Contains(mask, "engine_inherit") {
-		subscribe = true
 		set[schema.ItemTableEngineInheritColName] = row.EngineInherit
 	}
 
 	if util.Contains(mask, "engine_item_id") {
-		subscribe = true
 		set[schema.ItemTableEngineItemIDColName] = row.EngineItemID
 	}
 
-	ctx = context.WithoutCancel(ctx)
-
-	if len(set) > 0 {
-		_, err := s.db.Update(schema.ItemTable).
-			Set(set).
-			Where(schema.ItemTableIDCol.Eq(row.ID)).
-			Executor().ExecContext(ctx)
-		if err != nil {
-			return err
-		}
-	}
-
-	if util.Contains(mask, "name") {
-		err := s.setItemLanguageName(ctx, row.ID, DefaultLanguageCode, row.Name)
-		if err != nil {
-			return err
-		}
-	}
-
-	err := s.UpdateInheritance(ctx, row.ID)
-	if err != nil {
-		return err
-	}
-
-	_, err = s.UpdateOrderCache(ctx, row.ID)
-	if err != nil {
-		return err
-	}
-
-	_, err = s.RefreshAutoByVehicle(ctx, row.ID)
-	if err != nil {
-		return err
-	}
-
-	if subscribe {
-		err = s.UserItemSubscribe(ctx, row.ID, userID)
-		if err != nil {
-			return err
-		}
-	}
-
-	return nil
+	return set
 }
 
 func (s *Repository) setItemLanguageName(
 	ctx context.Context,
+	db queryBuilder,
 	itemID int64,
 	lang string,
 	name string,
@@ -4923,7 +5285,7 @@ func (s *Repository) setItemLanguageName(
 		return ErrItemNotFound
 	}
 
-	_, err := s.db.Insert(schema.ItemLanguageTable).Rows(goqu.Record{
+	_, err := db.Insert(schema.ItemLanguageTable).Rows(goqu.Record{
 		schema.ItemLanguageTableItemIDColName:   itemID,
 		schema.ItemLanguageTableLanguageColName: lang,
 		schema.ItemLanguageTableNameColName: sql.NullString{
@@ -236,6 +236,7 @@ func TestGetUserPicturesBrands(t *testing.T) {
 		brandID,
 		schema.ItemParentTypeDefault,
 		"",
+		0,
 	)
 	require.NoError(t, err)
 	require.True(t, success)
@@ -380,6 +381,7 @@ func TestOrderByDescendantsCount(t *testing.T) {
 		itemID,
 		schema.ItemParentTypeDefault,
 		"",
+		0,
 	)
 	require.NoError(t, err)
 	require.True(t, success)
@@ -394,7 +396,7 @@ func TestOrderByDescendantsCount(t *testing.T) {
 		})
 
 		success, err = repository.CreateItemParent(
-			ctx, subSubItemID, subItemID, schema.ItemParentTypeDefault, strconv.Itoa(i),
+			ctx, subSubItemID, subItemID, schema.ItemParentTypeDefault, strconv.Itoa(i), 0,
 		)
 		require.NoError(t, err)
 		require.True(t, success)
@@ -504,6 +506,7 @@ func TestOrderByOrderByDescendantPicturesCount(t *testing.T) {
 		itemID,
 		schema.ItemParentTypeDefault,
 		"",
+		0,
 	)
 	require.NoError(t, err)
 	require.True(t, success)
@@ -518,7 +521,7 @@ func TestOrderByOrderByDescendantPicturesCount(t *testing.T) {
 		})
 
 		success, err = repository.CreateItemParent(
-			ctx, subSubItemID, subItemID, schema.ItemParentTypeDefault, strconv.Itoa(i),
+			ctx, subSubItemID, subItemID, schema.ItemParentTypeDefault, strconv.Itoa(i), 0,
 		)
 		require.NoError(t, err)
 		require.True(t, success)
@@ -660,6 +663,7 @@ func TestOrderByAddDatetime(t *testing.T) {
 		itemID,
 		schema.ItemParentTypeDefault,
 		"",
+		0,
 	)
 	require.NoError(t, err)
 	require.True(t, success)
@@ -674,7 +678,7 @@ func TestOrderByAddDatetime(t *testing.T) {
 		})
 
 		success, err = repository.CreateItemParent(
-			ctx, subSubItemID, subItemID, schema.ItemParentTypeDefault, strconv.Itoa(i),
+			ctx, subSubItemID, subItemID, schema.ItemParentTypeDefault, strconv.Itoa(i), 0,
 		)
 		require.NoError(t, err)
 		require.True(t, success)
@@ -755,6 +759,7 @@ func TestOrderByName(t *testing.T) {
 		itemID,
 		schema.ItemParentTypeDefault,
 		"",
+		0,
 	)
 	require.NoError(t, err)
 	require.True(t, success)
@@ -769,7 +774,7 @@ func TestOrderByName(t *testing.T) {
 		})
 
 		success, err = repository.CreateItemParent(
-			ctx, subSubItemID, subItemID, schema.ItemParentTypeDefault, strconv.Itoa(i),
+			ctx, subSubItemID, subItemID, schema.ItemParentTypeDefault, strconv.Itoa(i), 0,
 		)
 		require.NoError(t, err)
 		require.True(t, success)
@@ -879,6 +884,7 @@ func TestOrderByDescendantsParentsCount(t *testing.T) {
 		itemID,
 		schema.ItemParentTypeDefault,
 		"",
+		0,
 	)
 	require.NoError(t, err)
 	require.True(t, success)
@@ -893,7 +899,7 @@ func TestOrderByDescendantsParentsCount(t *testing.T) {
 		})
 
 		success, err = repository.CreateItemParent(
-			ctx, subSubItemID, subItemID, schema.ItemParentTypeDefault, strconv.Itoa(i),
+			ctx, subSubItemID, subItemID, schema.ItemParentTypeDefault, strconv.Itoa(i), 0,
 		)
 		require.NoError(t, err)
 		require.True(t, success)
@@ -909,7 +915,7 @@ func TestOrderByDescendantsParentsCount(t *testing.T) {
 		})
 
 		success, err = repository.CreateItemParent(
-			ctx, subSubItemID, subSubParentItemID, schema.ItemParentTypeDefault, strconv.Itoa(i),
+			ctx, subSubItemID, subSubParentItemID, schema.ItemParentTypeDefault, strconv.Itoa(i), 0,
 		)
 		require.NoError(t, err)
 		require.True(t, success)
@@ -1037,6 +1043,7 @@ func TestOrderByStarCount(t *testing.T) {
 		itemID,
 		schema.ItemParentTypeDefault,
 		"",
+		0,
 	)
 	require.NoError(t, err)
 	require.True(t, success)
@@ -1051,7 +1058,7 @@ func TestOrderByStarCount(t *testing.T) {
 		})
 
 		success, err = repository.CreateItemParent(
-			ctx, subSubItemID, subItemID, schema.ItemParentTypeDefault, strconv.Itoa(i),
+			ctx, subSubItemID, subItemID, schema.ItemParentTypeDefault, strconv.Itoa(i), 0,
 		)
 		require.NoError(t, err)
 		require.True(t, success)
@@ -1130,6 +1137,7 @@ func TestOrderByItemParentParentTimestamp(t *testing.T) {
 		itemID,
 		schema.ItemParentTypeDefault,
 		"",
+		0,
 	)
 	require.NoError(t, err)
 	require.True(t, success)
@@ -1144,7 +1152,7 @@ func TestOrderByItemParentParentTimestamp(t *testing.T) {
 		})
 
 		success, err = repository.CreateItemParent(
-			ctx, subSubItemID, subItemID, schema.ItemParentTypeDefault, strconv.Itoa(i),
+			ctx, subSubItemID, subItemID, schema.ItemParentTypeDefault, strconv.Itoa(i), 0,
 		)
 		require.NoError(t, err)
 		require.True(t, success)
@@ -1160,7 +1168,7 @@ func TestOrderByItemParentParentTimestamp(t *testing.T) {
 		})
 
 		success, err = repository.CreateItemParent(
-			ctx, subSubItemID, subSubParentItemID, schema.ItemParentTypeDefault, strconv.Itoa(i),
+			ctx, subSubItemID, subSubParentItemID, schema.ItemParentTypeDefault, strconv.Itoa(i), 0,
 		)
 		require.NoError(t, err)
 		require.True(t, success)
@@ -0,0 +1,305 @@
+package items
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"fmt"
+	"strconv"
+
+	"github.com/autowp/goautowp/schema"
+	"github.com/autowp/goautowp/util"
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+)
+
+const itemRevisionsPerPage = 30
+
+var ErrItemRevisionNotFound = errors.New("item revision not found")
+
+// ItemRevisionFields are columns of item tracked by revisions, named same as update mask paths.
+var ItemRevisionFields = []string{
+	schema.ItemTableNameColName,
+	schema.ItemTableFullNameColName,
+	schema.ItemTableBodyColName,
+	schema.ItemTableBeginYearColName,
+	schema.ItemTableBeginMonthColName,
+	schema.ItemTableEndYearColName,
+	schema.ItemTableEndMonthColName,
+	schema.ItemTableTodayColName,
+	schema.ItemTableBeginModelYearColName,
+	schema.ItemTableEndModelYearColName,
+	schema.ItemTableBeginModelYearFractionColName,
+	schema.ItemTableEndModelYearFractionColName,
+	schema.ItemTableIsConceptColName,
+	schema.ItemTableIsConceptInheritColName,
+	schema.ItemTableCatnameColName,
+	schema.ItemTableProducedColName,
+	schema.ItemTableProducedExactlyColName,
+	schema.ItemTableIsGroupColName,
+	schema.ItemTableSpecInheritColName,
+	schema.ItemTableSpecIDColName,
+	schema.ItemTableEngineInheritColName,
+	schema.ItemTableEngineItemIDColName,
+}
+
+const (
+	ItemLanguageRevisionFieldName     = "name"
+	ItemLanguageRevisionFieldText     = "text"
+	ItemLanguageRevisionFieldFullText = "full_text"
+
+	// ItemParentRevisionFieldLink is changed when link is created or removed, value is a type of link.
+	ItemParentRevisionFieldLink    = "link"
+	ItemParentRevisionFieldType    = "type"
+	ItemParentRevisionFieldCatname = "catname"
+)
+
+// RevisionValue converts value written to database to the form stored in revisions.
+func RevisionValue(value any) sql.NullString {
+	if valuer, ok := value.(driver.Valuer); ok {
+		var err error
+
+		value, err = valuer.Value()
+		if err != nil {
+			return sql.NullString{}
+		}
+	}
+
+	switch typed := value.(type) {
+	case nil:
+		return sql.NullString{}
+	case string:
+		return sql.NullString{String: typed, Valid: true}
+	case []byte:
+		return sql.NullString{String: string(typed), Valid: true}
+	case bool:
+		if typed {
+			return sql.NullString{String: "1", Valid: true}
+		}
+
+		return sql.NullString{String: "0", Valid: true}
+	case int64:
+		return sql.NullString{String: strconv.FormatInt(typed, 10), Valid: true}
+	default:
+		return sql.NullString{String: fmt.Sprint(typed), Valid: true}
+	}
+}
+
+// addItemRevisions stores changes made by user, revisions with equal old and new values are skipped.
+func addItemRevisions(ctx context.Context, tx *goqu.TxDatabase, userID int64, rows []schema.ItemRevisionRow) error {
+	records := make([]interface{}, 0, len(rows))
+
+	for _, row := range rows {
+		if row.OldValue == row.NewValue {
+			continue
+		}
+
+		records = append(records, goqu.Record{
+			schema.ItemRevisionTableItemIDColName:    row.ItemID,
+			schema.ItemRevisionTableUserIDColName:    sql.NullInt64{Int64: userID, Valid: userID > 0},
+			schema.ItemRevisionTableCreatedAtColName: goqu.Func("NOW"),
+			schema.ItemRevisionTableEntityColName:    row.Entity,
+			schema.ItemRevisionTableLanguageColName:  row.Language,
+			schema.ItemRevisionTableParentIDColName:  row.ParentID,
+			schema.ItemRevisionTableFieldColName:     row.Field,
+			schema.ItemRevisionTableOldValueColName:  row.OldValue,
+			schema.ItemRevisionTableNewValueColName:  row.NewValue,
+		})
+	}
+
+	if len(records) == 0 {
+		return nil
+	}
+
+	_, err := tx.Insert(schema.ItemRevisionTable).Rows(records...).Executor().ExecContext(ctx)
+
+	return err
+}
+
+func (s *Repository) ItemRevision(ctx context.Context, id int64) (*schema.ItemRevisionRow, error) {
+	var row schema.ItemRevisionRow
+
+	success, err := s.db.From(schema.ItemRevisionTable).
+		Where(schema.ItemRevisionTableIDCol.Eq(id)).
+		ScanStructContext(ctx, &row)
+	if err != nil {
+		return nil, err
+	}
+
+	if !success {
+		return nil, ErrItemRevisionNotFound
+	}
+
+	return &row, nil
+}
+
+// ItemRevisions returns revisions of item, latest first.
+func (s *Repository) ItemRevisions(
+	ctx context.Context, itemID int64, page uint32,
+) ([]schema.ItemRevisionRow, *util.Pages, error) {
+	paginator := util.Paginator{
+		SQLSelect: s.db.From(schema.ItemRevisionTable).
+			Where(schema.ItemRevisionTableItemIDCol.Eq(itemID)).
+			Order(schema.ItemRevisionTableIDCol.Desc()),
+		ItemCountPerPage:  itemRevisionsPerPage,
+		CurrentPageNumber: int32(page), //nolint: gosec
+	}
+
+	pages, err := paginator.GetPages(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	sqSelect, err := paginator.GetCurrentItems(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var rows []schema.ItemRevisionRow
+
+	err = sqSelect.ScanStructsContext(ctx, &rows)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return rows, pages, nil
+}
+
+// ItemRevisionValues returns current values of item columns in the form stored in revisions.
+func (s *Repository) ItemRevisionValues(
+	ctx context.Context, itemID int64, cols []string,
+) (map[string]sql.NullString, error) {
+	return itemRevisionValues(ctx, s.db.From(schema.ItemTable), itemID, cols)
+}
+
+// itemRevisionValuesForUpdate is ItemRevisionValues locking item row until the end of transaction.
+func itemRevisionValuesForUpdate(
+	ctx context.Context, tx *goqu.TxDatabase, itemID int64, cols []string,
+) (map[string]sql.NullString, error) {
+	return itemRevisionValues(ctx, tx.From(schema.ItemTable).ForUpdate(exp.Wait), itemID, cols)
+}
+
+func itemRevisionValues(
+	ctx context.Context, sqSelect *goqu.SelectDataset, itemID int64, cols []string,
+) (map[string]sql.NullString, error) {
+	if len(cols) == 0 {
+		return map[string]sql.NullString{}, nil
+	}
+
+	selectCols := make([]interface{}, len(cols))
+	for idx, col := range cols {
+		selectCols[idx] = schema.ItemTable.Col(col)
+	}
+
+	values := make([]sql.NullString, len(cols))
+	dest := make([]interface{}, len(cols))
+
+	for idx := range values {
+		dest[idx] = &values[idx]
+	}
+
+	rows, err := sqSelect.Select(selectCols...).
+		Where(schema.ItemTableIDCol.Eq(itemID)).
+		Executor().QueryContext(ctx) //nolint:sqlclosecheck
+	if err != nil {
+		return nil, err
+	}
+	defer util.Close(rows)
+
+	if !rows.Next() {
+		if err = rows.Err(); err != nil {
+			return nil, err
+		}
+
+		return nil, ErrItemNotFound
+	}
+
+	err = rows.Scan(dest...)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make(map[string]sql.NullString, len(cols))
+	for idx, col := range cols {
+		result[col] = values[idx]
+	}
+
+	return result, nil
+}
+
+// ItemParentRevisions describes change of link between item and parent, nil stands for missing link.
+// Creation and removal of link are accompanied by catname revision, so removed link can be restored with its catname.
+func ItemParentRevisions(
+	itemID, parentID int64, oldRow, newRow *schema.ItemParentRow,
+) []schema.ItemRevisionRow {
+	revision := schema.ItemRevisionRow{
+		ItemID:   itemID,
+		Entity:   schema.ItemRevisionEntityItemParent,
+		ParentID: sql.NullInt64{Int64: parentID, Valid: true},
+		Field:    ItemParentRevisionFieldLink,
+	}
+	catnameRevision := revision
+	catnameRevision.Field = ItemParentRevisionFieldCatname
+
+	if oldRow != nil {
+		revision.OldValue = RevisionValue(int64(oldRow.Type))
+		catnameRevision.OldValue = RevisionValue(oldRow.Catname)
+	}
+
+	if newRow != nil {
+		revision.NewValue = RevisionValue(int64(newRow.Type))
+		catnameRevision.NewValue = RevisionValue(newRow.Catname)
+	}
+
+	if oldRow != nil && newRow != nil {
+		revision.Field = ItemParentRevisionFieldType
+	}
+
+	return []schema.ItemRevisionRow{revision, catnameRevision}
+}
+
+// ItemParentLinkCatname returns catname stored along with revision of link removal.
+func (s *Repository) ItemParentLinkCatname(ctx context.Context, revision *schema.ItemRevisionRow) (string, error) {
+	var catname sql.NullString
+
+	_, err := s.db.Select(schema.ItemRevisionTableOldValueCol).
+		From(schema.ItemRevisionTable).
+		Where(
+			schema.ItemRevisionTableItemIDCol.Eq(revision.ItemID),
+			schema.ItemRevisionTableParentIDCol.Eq(revision.ParentID),
+			schema.ItemRevisionTableEntityCol.Eq(schema.ItemRevisionEntityItemParent),
+			schema.ItemRevisionTableFieldCol.Eq(ItemParentRevisionFieldCatname),
+			schema.ItemRevisionTableCreatedAtCol.Eq(revision.CreatedAt),
+			schema.ItemRevisionTableNewValueCol.IsNull(),
+			schema.ItemRevisionTableIDCol.Gt(revision.ID),
+		).
+		Order(schema.ItemRevisionTableIDCol.Asc()).
+		Limit(1).
+		ScanValContext(ctx, &catname)
+
+	return catname.String, err
+}
+
+// AddItemParentRevisions records difference between oldRow and current state of link.
+func (s *Repository) AddItemParentRevisions(
+	ctx context.Context, tx *goqu.TxDatabase, userID, itemID, parentID int64, oldRow *schema.ItemParentRow,
+) error {
+	var newRow schema.ItemParentRow
+
+	success, err := tx.From(schema.ItemParentTable).
+		Where(
+			schema.ItemParentTableItemIDCol.Eq(itemID),
+			schema.ItemParentTableParentIDCol.Eq(parentID),
+		).
+		ScanStructContext(ctx, &newRow)
+	if err != nil {
+		return err
+	}
+
+	if !success {
+		return addItemRevisions(ctx, tx, userID, ItemParentRevisions(itemID, parentID, oldRow, nil))
+	}
+
+	return addItemRevisions(ctx, tx, userID, ItemParentRevisions(itemID, parentID, oldRow, &newRow))
+}
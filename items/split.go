@@ -0,0 +1,390 @@
+package items
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"slices"
+	"time"
+
+	"github.com/autowp/goautowp/schema"
+	"github.com/doug-martin/goqu/v9"
+)
+
+var (
+	ErrSplitChildNotFound   = errors.New("item is not a child of split item")
+	ErrSplitPictureNotFound = errors.New("picture is not linked to split item")
+)
+
+// splitParent is a link of split item to its parent, repeated for the new sibling.
+type splitParent struct {
+	ParentID int64
+	Type     schema.ItemParentType
+	Catname  string
+	Names    map[string]string
+}
+
+// SplitItem creates a sibling of item with the same parents, languages and vehicle types
+// and moves selected childs and pictures of item under it. Name of item is used when name is empty.
+// Rows are written in one transaction, caches of new item and moved childs are rebuilt after it.
+func (s *Repository) SplitItem(
+	ctx context.Context, itemID int64, name string, childIDs, pictureIDs []int64, userID int64,
+) (int64, error) {
+	var row schema.ItemRow
+
+	success, err := s.db.From(schema.ItemTable).Where(schema.ItemTableIDCol.Eq(itemID)).ScanStructContext(ctx, &row)
+	if err != nil {
+		return 0, err
+	}
+
+	if !success {
+		return 0, ErrItemNotFound
+	}
+
+	childIDs = slices.Compact(slices.Sorted(slices.Values(childIDs)))
+	pictureIDs = slices.Compact(slices.Sorted(slices.Values(pictureIDs)))
+
+	if len(childIDs) > 0 {
+		count, err := s.db.From(schema.ItemParentTable).Where(
+			schema.ItemParentTableParentIDCol.Eq(itemID),
+			schema.ItemParentTableItemIDCol.In(childIDs),
+		).CountContext(ctx)
+		if err != nil {
+			return 0, err
+		}
+
+		if count != int64(len(childIDs)) {
+			return 0, ErrSplitChildNotFound
+		}
+	}
+
+	if len(pictureIDs) > 0 {
+		count, err := s.db.From(schema.PictureItemTable).Where(
+			schema.PictureItemTableItemIDCol.Eq(itemID),
+			schema.PictureItemTablePictureIDCol.In(pictureIDs),
+			schema.PictureItemTableTypeCol.Eq(schema.PictureItemTypeContent),
+		).CountContext(ctx)
+		if err != nil {
+			return 0, err
+		}
+
+		if count != int64(len(pictureIDs)) {
+			return 0, ErrSplitPictureNotFound
+		}
+	}
+
+	ctx = context.WithoutCancel(ctx)
+
+	row.ID = 0
+	row.Catname = sql.NullString{}
+	row.LogoID = sql.NullInt64{}
+	row.AddDatetime = sql.NullTime{Valid: true, Time: time.Now()}
+
+	// names of sibling are the same as of item unless new name is given
+	nameRow := row
+	if name == "" {
+		nameRow.ID = itemID
+	} else {
+		nameRow.Name = name
+	}
+
+	parents, err := s.splitParents(ctx, itemID, nameRow)
+	if err != nil {
+		return 0, err
+	}
+
+	row.Name = nameRow.Name
+
+	var newItemID int64
+
+	err = s.db.WithTx(func(tx *goqu.TxDatabase) error {
+		res, err := tx.Insert(schema.ItemTable).Rows(row).Executor().ExecContext(ctx)
+		if err != nil {
+			return err
+		}
+
+		newItemID, err = res.LastInsertId()
+		if err != nil {
+			return err
+		}
+
+		err = splitItemLanguages(ctx, tx, itemID, newItemID, row.Name)
+		if err != nil {
+			return err
+		}
+
+		err = splitItemVehicleTypes(ctx, tx, itemID, newItemID)
+		if err != nil {
+			return err
+		}
+
+		err = splitItemParents(ctx, tx, newItemID, parents)
+		if err != nil {
+			return err
+		}
+
+		err = moveItemParents(ctx, tx, childIDs, itemID, newItemID)
+		if err != nil {
+			return err
+		}
+
+		if len(pictureIDs) == 0 {
+			return nil
+		}
+
+		_, err = tx.Update(schema.PictureItemTable).
+			Set(goqu.Record{schema.PictureItemTableItemIDColName: newItemID}).
+			Where(
+				schema.PictureItemTableItemIDCol.Eq(itemID),
+				schema.PictureItemTablePictureIDCol.In(pictureIDs),
+				schema.PictureItemTableTypeCol.Eq(schema.PictureItemTypeContent),
+			).
+			Executor().ExecContext(ctx)
+
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return newItemID, s.refreshSplitItem(ctx, newItemID, childIDs, userID)
+}
+
+// splitParents prepares links of new sibling to parents of item.
+// Sibling is not stored yet, so catnames are checked against existing childs only.
+func (s *Repository) splitParents(ctx context.Context, itemID int64, nameRow schema.ItemRow) ([]splitParent, error) {
+	var rows []schema.ItemParentRow
+
+	err := s.db.Select(schema.ItemParentTableParentIDCol, schema.ItemParentTableTypeCol).
+		From(schema.ItemParentTable).
+		Where(schema.ItemParentTableItemIDCol.Eq(itemID)).
+		ScanStructsContext(ctx, &rows)
+	if err != nil {
+		return nil, err
+	}
+
+	catnameRow := nameRow
+	catnameRow.ID = 0
+
+	result := make([]splitParent, 0, len(rows))
+
+	for _, row := range rows {
+		var parentRow schema.ItemRow
+
+		success, err := s.db.From(schema.ItemTable).
+			Where(schema.ItemTableIDCol.Eq(row.ParentID)).
+			ScanStructContext(ctx, &parentRow)
+		if err != nil {
+			return nil, err
+		}
+
+		if !success {
+			return nil, ErrItemNotFound
+		}
+
+		catname, err := s.extractCatname(ctx, s.db, parentRow, catnameRow)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(catname) == 0 {
+			return nil, errFailedToCreateItemParentCatname
+		}
+
+		names := make(map[string]string, len(s.contentLanguages))
+
+		for _, lang := range s.contentLanguages {
+			name, err := s.extractName(ctx, s.db, parentRow, nameRow, lang)
+			if err != nil {
+				return nil, err
+			}
+
+			if len(name) > schema.ItemLanguageNameMaxLength {
+				name = name[:schema.ItemLanguageNameMaxLength]
+			}
+
+			names[lang] = name
+		}
+
+		result = append(result, splitParent{
+			ParentID: row.ParentID,
+			Type:     row.Type,
+			Catname:  catname,
+			Names:    names,
+		})
+	}
+
+	return result, nil
+}
+
+// refreshSplitItem rebuilds caches and inherited values after split, as CreateItem does for a new item.
+func (s *Repository) refreshSplitItem(ctx context.Context, itemID int64, childIDs []int64, userID int64) error {
+	_, err := s.UpdateOrderCache(ctx, itemID)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.RebuildCache(ctx, itemID)
+	if err != nil {
+		return err
+	}
+
+	for _, childID := range childIDs {
+		_, err = s.refreshAuto(ctx, itemID, childID)
+		if err != nil {
+			return err
+		}
+	}
+
+	err = s.UserItemSubscribe(ctx, itemID, userID)
+	if err != nil {
+		return err
+	}
+
+	err = s.RefreshItemVehicleTypeInheritanceFromParents(ctx, itemID)
+	if err != nil {
+		return err
+	}
+
+	err = s.refreshItemVehicleTypeInheritance(ctx, itemID)
+	if err != nil {
+		return err
+	}
+
+	return s.UpdateInheritance(ctx, itemID)
+}
+
+// splitItemLanguages stores name of sibling and copies names in other languages of item.
+func splitItemLanguages(ctx context.Context, tx *goqu.TxDatabase, srcID, dstID int64, name string) error {
+	var rows []schema.ItemLanguageRow
+
+	err := tx.Select(schema.ItemLanguageTableLanguageCol, schema.ItemLanguageTableNameCol).
+		From(schema.ItemLanguageTable).
+		Where(
+			schema.ItemLanguageTableItemIDCol.Eq(srcID),
+			schema.ItemLanguageTableLanguageCol.Neq(DefaultLanguageCode),
+			schema.ItemLanguageTableNameCol.IsNotNull(),
+			schema.ItemLanguageTableNameCol.Neq(""),
+		).
+		ScanStructsContext(ctx, &rows)
+	if err != nil {
+		return err
+	}
+
+	records := make([]interface{}, 0, len(rows)+1)
+
+	if len(name) > 0 {
+		records = append(records, goqu.Record{
+			schema.ItemLanguageTableItemIDColName:   dstID,
+			schema.ItemLanguageTableLanguageColName: DefaultLanguageCode,
+			schema.ItemLanguageTableNameColName:     name,
+		})
+	}
+
+	for _, row := range rows {
+		records = append(records, goqu.Record{
+			schema.ItemLanguageTableItemIDColName:   dstID,
+			schema.ItemLanguageTableLanguageColName: row.Language,
+			schema.ItemLanguageTableNameColName:     row.Name,
+		})
+	}
+
+	if len(records) == 0 {
+		return nil
+	}
+
+	_, err = tx.Insert(schema.ItemLanguageTable).Rows(records...).Executor().ExecContext(ctx)
+
+	return err
+}
+
+// splitItemVehicleTypes copies own vehicle types of item, inherited ones are refreshed after split.
+func splitItemVehicleTypes(ctx context.Context, tx *goqu.TxDatabase, srcID, dstID int64) error {
+	var vehicleTypeIDs []int64
+
+	err := tx.Select(schema.ItemVehicleTypeTableVehicleTypeIDCol).
+		From(schema.ItemVehicleTypeTable).
+		Where(
+			schema.ItemVehicleTypeTableItemIDCol.Eq(srcID),
+			schema.ItemVehicleTypeTableInheritedCol.IsFalse(),
+		).
+		ScanValsContext(ctx, &vehicleTypeIDs)
+	if err != nil {
+		return err
+	}
+
+	if len(vehicleTypeIDs) == 0 {
+		return nil
+	}
+
+	records := make([]interface{}, 0, len(vehicleTypeIDs))
+
+	for _, vehicleTypeID := range vehicleTypeIDs {
+		records = append(records, goqu.Record{
+			schema.ItemVehicleTypeTableItemIDColName:        dstID,
+			schema.ItemVehicleTypeTableVehicleTypeIDColName: vehicleTypeID,
+			schema.ItemVehicleTypeTableInheritedColName:     false,
+		})
+	}
+
+	_, err = tx.Insert(schema.ItemVehicleTypeTable).Rows(records...).Executor().ExecContext(ctx)
+
+	return err
+}
+
+func splitItemParents(ctx context.Context, tx *goqu.TxDatabase, itemID int64, parents []splitParent) error {
+	for _, parent := range parents {
+		_, err := tx.Insert(schema.ItemParentTable).Rows(goqu.Record{
+			schema.ItemParentTableParentIDColName:      parent.ParentID,
+			schema.ItemParentTableItemIDColName:        itemID,
+			schema.ItemParentTableTypeColName:          parent.Type,
+			schema.ItemParentTableCatnameColName:       parent.Catname,
+			schema.ItemParentTableManualCatnameColName: false,
+			schema.ItemParentTableTimestampColName:     goqu.Func("NOW"),
+		}).Executor().ExecContext(ctx)
+		if err != nil {
+			return err
+		}
+
+		for lang, name := range parent.Names {
+			_, err = tx.Insert(schema.ItemParentLanguageTable).Rows(goqu.Record{
+				schema.ItemParentLanguageTableItemIDColName:   itemID,
+				schema.ItemParentLanguageTableParentIDColName: parent.ParentID,
+				schema.ItemParentLanguageTableLanguageColName: lang,
+				schema.ItemParentLanguageTableNameColName:     name,
+				schema.ItemParentLanguageTableIsAutoColName:   true,
+			}).Executor().ExecContext(ctx)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// moveItemParents re-parents many childs at once, caches of childs are left for the caller to rebuild.
+func moveItemParents(ctx context.Context, tx *goqu.TxDatabase, itemIDs []int64, parentID, newParentID int64) error {
+	if len(itemIDs) == 0 {
+		return nil
+	}
+
+	_, err := tx.Update(schema.ItemParentTable).Set(goqu.Record{
+		schema.ItemParentTableParentIDColName: newParentID,
+	}).Where(
+		schema.ItemParentTableItemIDCol.In(itemIDs),
+		schema.ItemParentTableParentIDCol.Eq(parentID),
+	).Executor().ExecContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	_, err = tx.Update(schema.ItemParentLanguageTable).Set(goqu.Record{
+		schema.ItemParentLanguageTableParentIDColName: newParentID,
+	}).Where(
+		schema.ItemParentLanguageTableItemIDCol.In(itemIDs),
+		schema.ItemParentLanguageTableParentIDCol.Eq(parentID),
+	).Executor().ExecContext(ctx)
+
+	return err
+}
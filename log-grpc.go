@@ -34,15 +34,39 @@ func (s *LogGRPCServer) GetEvents(ctx context.Context, in *LogEventsRequest) (*L
 		return nil, status.Error(codes.PermissionDenied, "PermissionDenied")
 	}
 
-	res, pages, err := s.repository.Events(ctx, log.ListOptions{
+	options := log.ListOptions{
 		ArticleID: in.GetArticleId(),
 		ItemID:    in.GetItemId(),
 		PictureID: in.GetPictureId(),
 		UserID:    in.GetUserId(),
 		Page:      in.GetPage(),
-	})
-	if err != nil {
-		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	var (
+		res        []log.Event
+		pages      *util.Pages
+		nextCursor string
+	)
+
+	if in.GetCursor() != nil {
+		res, nextCursor, err = s.repository.EventsByCursor(ctx, options, in.GetCursor().GetValue())
+		if err != nil {
+			return nil, wrapCursorError(err)
+		}
+
+		if in.GetCount() {
+			count, err := s.repository.EventsCount(ctx, options)
+			if err != nil {
+				return nil, status.Error(codes.Internal, err.Error())
+			}
+
+			pages = &util.Pages{TotalItemCount: count}
+		}
+	} else {
+		res, pages, err = s.repository.Events(ctx, options)
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
 	}
 
 	is := make([]*LogEvent, 0, len(res))
@@ -56,21 +80,25 @@ func (s *LogGRPCServer) GetEvents(ctx context.Context, in *LogEventsRequest) (*L
 		})
 	}
 
-	paginator := &Pages{
-		PageCount:        pages.PageCount,
-		First:            pages.First,
-		Last:             pages.Last,
-		Current:          pages.Current,
-		FirstPageInRange: pages.FirstPageInRange,
-		LastPageInRange:  pages.LastPageInRange,
-		PagesInRange:     pages.PagesInRange,
-		TotalItemCount:   pages.TotalItemCount,
-		Next:             pages.Next,
-		Previous:         pages.Previous,
+	var paginator *Pages
+	if pages != nil {
+		paginator = &Pages{
+			PageCount:        pages.PageCount,
+			First:            pages.First,
+			Last:             pages.Last,
+			Current:          pages.Current,
+			FirstPageInRange: pages.FirstPageInRange,
+			LastPageInRange:  pages.LastPageInRange,
+			PagesInRange:     pages.PagesInRange,
+			TotalItemCount:   pages.TotalItemCount,
+			Next:             pages.Next,
+			Previous:         pages.Previous,
+		}
 	}
 
 	return &LogEvents{
-		Items:     is,
-		Paginator: paginator,
+		Items:      is,
+		Paginator:  paginator,
+		NextCursor: nextCursor,
 	}, nil
 }
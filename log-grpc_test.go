@@ -5,7 +5,10 @@ import (
 
 	"github.com/autowp/goautowp/config"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/wrapperspb"
 )
 
 func TestGetEvents(t *testing.T) {
@@ -71,3 +74,45 @@ func TestGetEventsWithFilters(t *testing.T) {
 	})
 	require.NoError(t, err)
 }
+
+func TestGetEventsByCursor(t *testing.T) {
+	t.Parallel()
+
+	cfg := config.LoadConfig(".")
+
+	kc := cnt.Keycloak()
+	token, err := kc.Login(
+		t.Context(),
+		"frontend",
+		"",
+		cfg.Keycloak.Realm,
+		adminUsername,
+		adminPassword,
+	)
+	require.NoError(t, err)
+	require.NotNil(t, token)
+
+	client := NewLogClient(conn)
+
+	ctx := metadata.AppendToOutgoingContext(
+		t.Context(),
+		authorizationHeader,
+		bearerPrefix+token.AccessToken,
+	)
+
+	res, err := client.GetEvents(ctx, &LogEventsRequest{Cursor: wrapperspb.String(""), Count: true})
+	require.NoError(t, err)
+	require.NotNil(t, res.GetPaginator())
+
+	if len(res.GetNextCursor()) > 0 {
+		last := res.GetItems()[len(res.GetItems())-1]
+
+		res, err = client.GetEvents(ctx, &LogEventsRequest{Cursor: wrapperspb.String(res.GetNextCursor())})
+		require.NoError(t, err)
+		require.NotEmpty(t, res.GetItems())
+		require.False(t, res.GetItems()[0].GetCreatedAt().AsTime().After(last.GetCreatedAt().AsTime()))
+	}
+
+	_, err = client.GetEvents(ctx, &LogEventsRequest{Cursor: wrapperspb.String("invalid")})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
@@ -38,14 +38,17 @@ func NewRepository(db *goqu.Database) *Repository {
 	}
 }
 
-func (s *Repository) Events(
-	ctx context.Context,
-	options ListOptions,
-) ([]Event, *util.Pages, error) {
+// eventsKeyset is an order of events, used by cursor pagination.
+var eventsKeyset = util.Keyset[Event]{
+	{Name: schema.LogEventsTableAddDatetimeColName, Desc: true, Value: func(row Event) any { return row.CreatedAt }},
+	{Name: schema.LogEventsTableIDColName, Desc: true, Value: func(row Event) any { return row.ID }},
+}
+
+func (s *Repository) eventsSelect(options ListOptions) *goqu.SelectDataset {
 	sqSelect := s.db.Select(schema.LogEventsTableIDCol, schema.LogEventsTableUserIDCol,
 		schema.LogEventsTableAddDatetimeCol, schema.LogEventsTableDescriptionCol).
 		From(schema.LogEventsTable).
-		Order(schema.LogEventsTableAddDatetimeCol.Desc(), schema.LogEventsTableIDCol.Desc())
+		Order(eventsKeyset.Order(schema.LogEventsTableName)...)
 
 	if options.ArticleID != 0 {
 		sqSelect = sqSelect.
@@ -72,8 +75,15 @@ func (s *Repository) Events(
 		sqSelect = sqSelect.Where(schema.LogEventsTableUserIDCol.Eq(options.UserID))
 	}
 
+	return sqSelect
+}
+
+func (s *Repository) Events(
+	ctx context.Context,
+	options ListOptions,
+) ([]Event, *util.Pages, error) {
 	paginator := util.Paginator{
-		SQLSelect:         sqSelect,
+		SQLSelect:         s.eventsSelect(options),
 		ItemCountPerPage:  eventsPerPage,
 		CurrentPageNumber: int32(options.Page), //nolint: gosec
 	}
@@ -83,7 +93,7 @@ func (s *Repository) Events(
 		return nil, nil, err
 	}
 
-	sqSelect, err = paginator.GetCurrentItems(ctx)
+	sqSelect, err := paginator.GetCurrentItems(ctx)
 	if err != nil {
 		return nil, nil, err
 	}
@@ -95,13 +105,55 @@ func (s *Repository) Events(
 		return nil, nil, err
 	}
 
+	err = s.fetchRelations(ctx, rows)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return rows, pages, nil
+}
+
+// EventsByCursor returns page of events following cursor and cursor of next page, empty for the last page.
+// Unlike Events it doesn't count rows and doesn't use OFFSET.
+func (s *Repository) EventsByCursor(ctx context.Context, options ListOptions, cursor string) ([]Event, string, error) {
+	after, err := eventsKeyset.Decode(cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	var rows []Event
+
+	err = after.Where(s.eventsSelect(options), schema.LogEventsTableName).
+		Limit(eventsPerPage+1).
+		ScanStructsContext(ctx, &rows)
+	if err != nil {
+		return nil, "", err
+	}
+
+	rows, next := eventsKeyset.Page(rows, eventsPerPage)
+
+	err = s.fetchRelations(ctx, rows)
+	if err != nil {
+		return nil, "", err
+	}
+
+	return rows, next, nil
+}
+
+func (s *Repository) EventsCount(ctx context.Context, options ListOptions) (int32, error) {
+	paginator := util.Paginator{SQLSelect: s.eventsSelect(options)}
+
+	return paginator.GetTotalItemCount(ctx)
+}
+
+func (s *Repository) fetchRelations(ctx context.Context, rows []Event) error {
 	for idx, row := range rows {
-		err = s.db.Select(schema.LogEventsItemTableItemIDCol).
+		err := s.db.Select(schema.LogEventsItemTableItemIDCol).
 			From(schema.LogEventsItemTable).
 			Where(schema.LogEventsItemTableLogEventIDCol.Eq(row.ID)).
 			ScanValsContext(ctx, &rows[idx].Items)
 		if err != nil {
-			return nil, nil, err
+			return err
 		}
 
 		err = s.db.Select(schema.LogEventsPicturesTablePictureIDCol).
@@ -109,9 +161,9 @@ func (s *Repository) Events(
 			Where(schema.LogEventsPicturesTableLogEventIDCol.Eq(row.ID)).
 			ScanValsContext(ctx, &rows[idx].Pictures)
 		if err != nil {
-			return nil, nil, err
+			return err
 		}
 	}
 
-	return rows, pages, nil
+	return nil
 }
@@ -0,0 +1,179 @@
+package goautowp
+
+import (
+	"context"
+	"net/url"
+	"strings"
+
+	"github.com/autowp/goautowp/frontend"
+	"github.com/autowp/goautowp/items"
+	"github.com/autowp/goautowp/markup"
+	"github.com/autowp/goautowp/pictures"
+	"github.com/autowp/goautowp/query"
+	"github.com/autowp/goautowp/schema"
+)
+
+// MarkupExtractor renders markup of messages resolving mentions, item and picture references.
+type MarkupExtractor struct {
+	container *Container
+}
+
+func NewMarkupExtractor(container *Container) *MarkupExtractor {
+	return &MarkupExtractor{container: container}
+}
+
+// RenderAll renders sources resolving their references in batch.
+func (s *MarkupExtractor) RenderAll(ctx context.Context, sources []string, lang string) ([]markup.Result, error) {
+	links, err := s.Links(ctx, markup.ParseReferences(sources...), lang)
+	if err != nil {
+		return nil, err
+	}
+
+	result := make([]markup.Result, 0, len(sources))
+
+	for _, source := range sources {
+		result = append(result, markup.Render(source, links))
+	}
+
+	return result, nil
+}
+
+func (s *MarkupExtractor) Render(ctx context.Context, source string, lang string) (markup.Result, error) {
+	result, err := s.RenderAll(ctx, []string{source}, lang)
+	if err != nil {
+		return markup.Result{}, err
+	}
+
+	return result[0], nil
+}
+
+func (s *MarkupExtractor) Links(ctx context.Context, refs markup.References, lang string) (markup.Links, error) {
+	var (
+		links = markup.Links{}
+		err   error
+	)
+
+	links.Users, err = s.userLinks(ctx, refs.Users)
+	if err != nil {
+		return links, err
+	}
+
+	links.Items, err = s.itemLinks(ctx, refs.Items, lang)
+	if err != nil {
+		return links, err
+	}
+
+	links.Pictures, err = s.pictureLinks(ctx, refs.Pictures)
+	if err != nil {
+		return links, err
+	}
+
+	return links, nil
+}
+
+func (s *MarkupExtractor) userLinks(ctx context.Context, identities []string) (map[string]markup.Link, error) {
+	result := make(map[string]markup.Link, len(identities))
+	if len(identities) == 0 {
+		return result, nil
+	}
+
+	usersRepository, err := s.container.UsersRepository()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, err := usersRepository.UsersByIdentities(ctx, identities)
+	if err != nil {
+		return nil, err
+	}
+
+	for identity, row := range rows {
+		result[identity] = markup.Link{URL: frontend.UserPath(row.ID, row.Identity)}
+	}
+
+	return result, nil
+}
+
+func (s *MarkupExtractor) itemLinks(ctx context.Context, ids []int64, lang string) (map[int64]markup.Link, error) {
+	result := make(map[int64]markup.Link, len(ids))
+	if len(ids) == 0 {
+		return result, nil
+	}
+
+	itemsRepository, err := s.container.ItemsRepository()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, _, err := itemsRepository.List(ctx, &query.ItemListOptions{
+		ItemIDs:  ids,
+		Language: lang,
+	}, &items.ItemFields{
+		NameText: true,
+	}, items.OrderByNone, false)
+	if err != nil {
+		return nil, err
+	}
+
+	extracted, err := s.container.ItemExtractor().ExtractRows(
+		ctx, rows, &ItemFields{NameText: true, Route: true}, lang, UserContext{},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, item := range extracted {
+		if len(item.GetRoute()) == 0 {
+			continue
+		}
+
+		result[item.GetId()] = markup.Link{URL: routePath(item.GetRoute()), Title: item.GetNameText()}
+	}
+
+	return result, nil
+}
+
+func (s *MarkupExtractor) pictureLinks(ctx context.Context, ids []int64) (map[int64]markup.Link, error) {
+	result := make(map[int64]markup.Link, len(ids))
+	if len(ids) == 0 {
+		return result, nil
+	}
+
+	picturesRepository, err := s.container.PicturesRepository()
+	if err != nil {
+		return nil, err
+	}
+
+	rows, _, err := picturesRepository.Pictures(ctx, &query.PictureListOptions{
+		IDs:      ids,
+		Statuses: []schema.PictureStatus{schema.PictureStatusAccepted, schema.PictureStatusInbox},
+	}, nil, pictures.OrderByNone, false)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		result[row.ID] = markup.Link{URL: frontend.PicturePath(row.Identity)}
+	}
+
+	return result, nil
+}
+
+func routePath(route []string) string {
+	parts := make([]string, 0, len(route))
+
+	for idx, part := range route {
+		if idx == 0 {
+			// first segment is prefixed with slash and is "/" alone for brands
+			if part = strings.TrimLeft(part, "/"); len(part) > 0 {
+				parts = append(parts, part)
+			}
+
+			continue
+		}
+
+		parts = append(parts, url.PathEscape(part))
+	}
+
+	return "/" + strings.Join(parts, "/")
+}
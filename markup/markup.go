@@ -0,0 +1,504 @@
+package markup
+
+import (
+	"html"
+	"net/url"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	itemRefPrefix    = "#item"
+	pictureRefPrefix = "#picture"
+	codeFence        = "```"
+)
+
+// MaxReferences limits mentions and references of each kind collected from single source,
+// so message can't fan out into unbounded lookups and notifications.
+const MaxReferences = 10
+
+var allowedSchemes = []string{"http", "https"}
+
+// Link is resolved target of mention or reference.
+type Link struct {
+	URL   string
+	Title string
+}
+
+// Links holds resolved targets of references found in source.
+// Unresolved references are rendered as plain text.
+type Links struct {
+	Users    map[string]Link
+	Items    map[int64]Link
+	Pictures map[int64]Link
+}
+
+// References lists mentions and references found in source.
+type References struct {
+	Users    []string
+	Items    []int64
+	Pictures []int64
+}
+
+type Result struct {
+	HTML string
+	Text string
+}
+
+type renderer struct {
+	links Links
+	refs  *References
+	html  strings.Builder
+	text  strings.Builder
+}
+
+// Render converts source in markup to sanitized HTML and plain text.
+// Everything except the supported subset of Markdown is escaped, so output is safe to embed as is.
+func Render(source string, links Links) Result {
+	r := renderer{links: links}
+	r.blocks(splitLines(source))
+
+	return Result{
+		HTML: r.html.String(),
+		Text: strings.TrimSpace(r.text.String()),
+	}
+}
+
+// PlainText strips markup of source.
+func PlainText(source string) string {
+	return Render(source, Links{}).Text
+}
+
+// ParseReferences collects mentions, item and picture references of sources.
+// At most MaxReferences of each kind are collected per source.
+func ParseReferences(sources ...string) References {
+	refs := References{}
+
+	for _, source := range sources {
+		sourceRefs := References{}
+		r := renderer{refs: &sourceRefs}
+		r.blocks(splitLines(source))
+
+		for _, identity := range sourceRefs.Users {
+			refs.Users = appendUnique(refs.Users, identity)
+		}
+
+		for _, id := range sourceRefs.Items {
+			refs.Items = appendUnique(refs.Items, id)
+		}
+
+		for _, id := range sourceRefs.Pictures {
+			refs.Pictures = appendUnique(refs.Pictures, id)
+		}
+	}
+
+	return refs
+}
+
+func splitLines(source string) []string {
+	source = strings.ReplaceAll(source, "\r", "")
+
+	return strings.Split(strings.TrimSpace(source), "\n")
+}
+
+func isQuote(line string) bool {
+	return strings.HasPrefix(line, ">")
+}
+
+func isListItem(line string) bool {
+	return strings.HasPrefix(line, "- ") || strings.HasPrefix(line, "* ")
+}
+
+func isFence(line string) bool {
+	return strings.HasPrefix(strings.TrimSpace(line), codeFence)
+}
+
+func (r *renderer) blocks(lines []string) {
+	for idx := 0; idx < len(lines); {
+		line := lines[idx]
+
+		switch {
+		case strings.TrimSpace(line) == "":
+			idx++
+		case isFence(line):
+			idx = r.code(lines, idx+1)
+		case isQuote(line):
+			idx = r.quote(lines, idx)
+		case isListItem(line):
+			idx = r.list(lines, idx)
+		default:
+			idx = r.paragraph(lines, idx)
+		}
+	}
+}
+
+func (r *renderer) code(lines []string, idx int) int {
+	content := make([]string, 0)
+
+	for ; idx < len(lines) && !isFence(lines[idx]); idx++ {
+		content = append(content, lines[idx])
+	}
+
+	code := strings.Join(content, "\n")
+
+	r.html.WriteString("<pre><code>" + html.EscapeString(code) + "</code></pre>")
+	r.text.WriteString(code + "\n")
+
+	return idx + 1
+}
+
+func (r *renderer) quote(lines []string, idx int) int {
+	content := make([]string, 0)
+
+	for ; idx < len(lines) && isQuote(lines[idx]); idx++ {
+		line := strings.TrimPrefix(lines[idx], ">")
+		content = append(content, strings.TrimPrefix(line, " "))
+	}
+
+	r.html.WriteString("<blockquote>")
+	r.blocks(content)
+	r.html.WriteString("</blockquote>")
+
+	return idx
+}
+
+func (r *renderer) list(lines []string, idx int) int {
+	r.html.WriteString("<ul>")
+
+	for ; idx < len(lines) && isListItem(lines[idx]); idx++ {
+		r.html.WriteString("<li>")
+		r.text.WriteString("- ")
+		r.inline(lines[idx][2:], true)
+		r.html.WriteString("</li>")
+		r.text.WriteString("\n")
+	}
+
+	r.html.WriteString("</ul>")
+
+	return idx
+}
+
+func (r *renderer) paragraph(lines []string, idx int) int {
+	r.html.WriteString("<p>")
+
+	for first := true; idx < len(lines); idx++ {
+		line := lines[idx]
+		if strings.TrimSpace(line) == "" || isFence(line) || isQuote(line) || isListItem(line) {
+			break
+		}
+
+		if !first {
+			r.html.WriteString("<br />")
+		}
+
+		first = false
+
+		r.inline(line, true)
+		r.text.WriteString("\n")
+	}
+
+	r.html.WriteString("</p>")
+
+	return idx
+}
+
+func (r *renderer) write(value string) {
+	r.html.WriteString(html.EscapeString(value))
+	r.text.WriteString(value)
+}
+
+func (r *renderer) link(href string, label string) {
+	r.html.WriteString(`<a href="` + html.EscapeString(href) + `" rel="nofollow">` + html.EscapeString(label) + "</a>")
+	r.text.WriteString(label)
+}
+
+// inline renders emphasis, code spans, links, mentions and references of single line.
+func (r *renderer) inline(line string, allowLinks bool) {
+	plainStart := 0
+
+	for idx := 0; idx < len(line); {
+		end, render := r.token(line, idx, allowLinks)
+		if render == nil {
+			_, size := utf8.DecodeRuneInString(line[idx:])
+			idx += size
+
+			continue
+		}
+
+		r.write(line[plainStart:idx])
+		render()
+
+		idx = end
+		plainStart = idx
+	}
+
+	r.write(line[plainStart:])
+}
+
+// token matches markup starting at idx and returns its end with function writing it.
+func (r *renderer) token(line string, idx int, allowLinks bool) (int, func()) {
+	rest := line[idx:]
+	boundary := isBoundary(line, idx)
+
+	switch {
+	case strings.HasPrefix(rest, "`"):
+		return r.span(line, idx, "`", func(inner string) {
+			r.html.WriteString("<code>" + html.EscapeString(inner) + "</code>")
+			r.text.WriteString(inner)
+		})
+	case strings.HasPrefix(rest, "**"):
+		return r.span(line, idx, "**", r.wrap("strong", allowLinks))
+	case strings.HasPrefix(rest, "~~"):
+		return r.span(line, idx, "~~", r.wrap("del", allowLinks))
+	case strings.HasPrefix(rest, "*"):
+		return r.span(line, idx, "*", r.wrap("em", allowLinks))
+	case strings.HasPrefix(rest, "_") && boundary:
+		end, render := r.span(line, idx, "_", r.wrap("em", allowLinks))
+		if render != nil && !isBoundaryAfter(line, end) {
+			return 0, nil
+		}
+
+		return end, render
+	case strings.HasPrefix(rest, "[") && allowLinks:
+		return r.markdownLink(line, idx)
+	case (strings.HasPrefix(rest, "http://") || strings.HasPrefix(rest, "https://")) && allowLinks && boundary:
+		return r.autoLink(line, idx)
+	case strings.HasPrefix(rest, "@") && boundary:
+		return r.mention(line, idx, allowLinks)
+	case strings.HasPrefix(rest, "#") && boundary:
+		return r.reference(line, idx, allowLinks)
+	}
+
+	return 0, nil
+}
+
+// isBoundary reports whether markup starting at idx is not glued to preceding word.
+func isBoundary(line string, idx int) bool {
+	if idx <= 0 {
+		return true
+	}
+
+	prev, _ := utf8.DecodeLastRuneInString(line[:idx])
+
+	return !unicode.IsLetter(prev) && !unicode.IsDigit(prev)
+}
+
+// isBoundaryAfter reports whether markup ending at idx is not glued to following word.
+func isBoundaryAfter(line string, idx int) bool {
+	if idx >= len(line) {
+		return true
+	}
+
+	next, _ := utf8.DecodeRuneInString(line[idx:])
+
+	return !unicode.IsLetter(next) && !unicode.IsDigit(next)
+}
+
+// span matches text enclosed in delimiter and not padded with spaces.
+func (r *renderer) span(line string, idx int, delimiter string, render func(inner string)) (int, func()) {
+	start := idx + len(delimiter)
+
+	length := strings.Index(line[start:], delimiter)
+	if length <= 0 {
+		return 0, nil
+	}
+
+	inner := line[start : start+length]
+	if strings.TrimSpace(inner) != inner {
+		return 0, nil
+	}
+
+	return start + length + len(delimiter), func() {
+		render(inner)
+	}
+}
+
+func (r *renderer) wrap(tag string, allowLinks bool) func(inner string) {
+	return func(inner string) {
+		r.html.WriteString("<" + tag + ">")
+		r.inline(inner, allowLinks)
+		r.html.WriteString("</" + tag + ">")
+	}
+}
+
+func (r *renderer) markdownLink(line string, idx int) (int, func()) {
+	labelEnd := strings.Index(line[idx:], "](")
+	if labelEnd <= 1 {
+		return 0, nil
+	}
+
+	labelEnd += idx
+	hrefStart := labelEnd + len("](")
+
+	hrefLength := strings.Index(line[hrefStart:], ")")
+	if hrefLength <= 0 {
+		return 0, nil
+	}
+
+	label := line[idx+1 : labelEnd]
+	href := line[hrefStart : hrefStart+hrefLength]
+
+	if !isSafeURL(href) {
+		return 0, nil
+	}
+
+	return hrefStart + hrefLength + 1, func() {
+		r.html.WriteString(`<a href="` + html.EscapeString(href) + `" rel="nofollow">`)
+		r.inline(label, false)
+		r.html.WriteString("</a>")
+	}
+}
+
+func (r *renderer) autoLink(line string, idx int) (int, func()) {
+	end := idx
+
+	for end < len(line) {
+		chr, size := utf8.DecodeRuneInString(line[end:])
+		if unicode.IsSpace(chr) || chr == '<' || chr == '>' || chr == '"' {
+			break
+		}
+
+		end += size
+	}
+
+	href := strings.TrimRight(line[idx:end], ".,;:!?)'")
+	if !isSafeURL(href) {
+		return 0, nil
+	}
+
+	return idx + len(href), func() {
+		r.link(href, href)
+	}
+}
+
+func isSafeURL(value string) bool {
+	parsed, err := url.Parse(value)
+	if err != nil || len(parsed.Host) == 0 {
+		return false
+	}
+
+	for _, scheme := range allowedSchemes {
+		if parsed.Scheme == scheme {
+			return true
+		}
+	}
+
+	return false
+}
+
+func isIdentityRune(chr rune) bool {
+	return chr == '_' || chr == '.' || chr == '-' || unicode.IsLetter(chr) || unicode.IsDigit(chr)
+}
+
+func (r *renderer) mention(line string, idx int, allowLinks bool) (int, func()) {
+	start := idx + 1
+	end := start
+
+	for end < len(line) {
+		chr, size := utf8.DecodeRuneInString(line[end:])
+		if !isIdentityRune(chr) {
+			break
+		}
+
+		end += size
+	}
+
+	identity := strings.TrimRight(line[start:end], ".-")
+	if len(identity) == 0 {
+		return 0, nil
+	}
+
+	if r.refs != nil {
+		r.refs.Users = appendLimited(r.refs.Users, identity)
+	}
+
+	return start + len(identity), func() {
+		label := "@" + identity
+
+		link, ok := r.links.Users[identity]
+		if !ok || !allowLinks {
+			r.write(label)
+
+			return
+		}
+
+		r.link(link.URL, label)
+	}
+}
+
+func (r *renderer) reference(line string, idx int, allowLinks bool) (int, func()) {
+	rest := line[idx:]
+
+	var (
+		prefix string
+		links  map[int64]Link
+		refs   *[]int64
+	)
+
+	switch {
+	case strings.HasPrefix(rest, itemRefPrefix):
+		prefix, links = itemRefPrefix, r.links.Items
+	case strings.HasPrefix(rest, pictureRefPrefix):
+		prefix, links = pictureRefPrefix, r.links.Pictures
+	default:
+		return 0, nil
+	}
+
+	digits := len(rest[len(prefix):]) - len(strings.TrimLeft(rest[len(prefix):], "0123456789"))
+	end := idx + len(prefix) + digits
+
+	if digits == 0 || !isBoundaryAfter(line, end) {
+		return 0, nil
+	}
+
+	id, err := strconv.ParseInt(line[idx+len(prefix):end], 10, 64)
+	if err != nil || id <= 0 {
+		return 0, nil
+	}
+
+	if r.refs != nil {
+		refs = &r.refs.Items
+		if prefix == pictureRefPrefix {
+			refs = &r.refs.Pictures
+		}
+
+		*refs = appendLimited(*refs, id)
+	}
+
+	return end, func() {
+		label := line[idx:end]
+
+		link, ok := links[id]
+		if !ok || !allowLinks {
+			r.write(label)
+
+			return
+		}
+
+		if len(link.Title) > 0 {
+			label = link.Title
+		}
+
+		r.link(link.URL, label)
+	}
+}
+
+func appendUnique[T comparable](list []T, value T) []T {
+	for _, existing := range list {
+		if existing == value {
+			return list
+		}
+	}
+
+	return append(list, value)
+}
+
+func appendLimited[T comparable](list []T, value T) []T {
+	if len(list) >= MaxReferences {
+		return list
+	}
+
+	return appendUnique(list, value)
+}
@@ -0,0 +1,87 @@
+package markup
+
+import (
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestRender(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		source string
+		html   string
+		text   string
+	}{
+		{
+			source: "Hello <script>alert(1)</script>",
+			html:   "<p>Hello &lt;script&gt;alert(1)&lt;/script&gt;</p>",
+			text:   "Hello <script>alert(1)</script>",
+		},
+		{
+			source: "**bold** *em* _em_ ~~del~~ `a<b` snake_case_name 2 * 3 * 4",
+			html: "<p><strong>bold</strong> <em>em</em> <em>em</em> <del>del</del> <code>a&lt;b</code> " +
+				"snake_case_name 2 * 3 * 4</p>",
+			text: "bold em em del a<b snake_case_name 2 * 3 * 4",
+		},
+		{
+			source: "line 1\r\nline 2\n\n> quote\n\n- one\n- two\n```\n**raw**\n```",
+			html: "<p>line 1<br />line 2</p><blockquote><p>quote</p></blockquote>" +
+				"<ul><li>one</li><li>two</li></ul><pre><code>**raw**</code></pre>",
+			text: "line 1\nline 2\nquote\n- one\n- two\n**raw**",
+		},
+		{
+			source: "see https://example.com/a?b=1&c=2. or [site](https://example.com) [bad](javascript:alert(1))",
+			html: `<p>see <a href="https://example.com/a?b=1&amp;c=2" rel="nofollow">https://example.com/a?b=1&amp;c=2</a>. ` +
+				`or <a href="https://example.com" rel="nofollow">site</a> [bad](javascript:alert(1))</p>`,
+			text: "see https://example.com/a?b=1&c=2. or site [bad](javascript:alert(1))",
+		},
+		{
+			source: "@admin look at #item5 and #picture7, not #item5x or mail@example.com or @ghost",
+			html: `<p><a href="/users/admin" rel="nofollow">@admin</a> look at ` +
+				`<a href="/bmw/m3" rel="nofollow">BMW M3</a> and #picture7, not #item5x or mail@example.com or @ghost</p>`,
+			text: "@admin look at BMW M3 and #picture7, not #item5x or mail@example.com or @ghost",
+		},
+	}
+
+	links := Links{
+		Users: map[string]Link{"admin": {URL: "/users/admin"}},
+		Items: map[int64]Link{5: {URL: "/bmw/m3", Title: "BMW M3"}},
+	}
+
+	for _, test := range tests {
+		result := Render(test.source, links)
+		require.Equal(t, test.html, result.HTML, test.source)
+		require.Equal(t, test.text, result.Text, test.source)
+	}
+}
+
+func TestParseReferences(t *testing.T) {
+	t.Parallel()
+
+	refs := ParseReferences("@admin, **@user1** #item5 #picture7", "@admin #item5 #item6 `@code`")
+
+	require.Equal(t, References{
+		Users:    []string{"admin", "user1"},
+		Items:    []int64{5, 6},
+		Pictures: []int64{7},
+	}, refs)
+}
+
+func TestParseReferencesLimit(t *testing.T) {
+	t.Parallel()
+
+	mentions := make([]string, 0, MaxReferences*2)
+	for i := range MaxReferences * 2 {
+		mentions = append(mentions, "@user"+strconv.Itoa(i))
+	}
+
+	source := strings.Join(mentions, " ")
+
+	require.Len(t, ParseReferences(source).Users, MaxReferences)
+	// limit applies per source, so page of messages is resolved as a whole
+	require.Len(t, ParseReferences(source, "@another").Users, MaxReferences+1)
+}
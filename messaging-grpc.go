@@ -2,8 +2,11 @@ package goautowp
 
 import (
 	"context"
+	"errors"
 
 	"github.com/autowp/goautowp/messaging"
+	"github.com/autowp/goautowp/schema"
+	"github.com/autowp/goautowp/users"
 	"github.com/autowp/goautowp/util"
 	"github.com/autowp/goautowp/validation"
 	"google.golang.org/genproto/googleapis/rpc/errdetails"
@@ -15,14 +18,18 @@ import (
 
 type MessagingGRPCServer struct {
 	UnimplementedMessagingServer
-	repository *messaging.Repository
-	auth       *Auth
+	repository      *messaging.Repository
+	auth            *Auth
+	markupExtractor *MarkupExtractor
 }
 
-func NewMessagingGRPCServer(repository *messaging.Repository, auth *Auth) *MessagingGRPCServer {
+func NewMessagingGRPCServer(
+	repository *messaging.Repository, auth *Auth, markupExtractor *MarkupExtractor,
+) *MessagingGRPCServer {
 	return &MessagingGRPCServer{
-		repository: repository,
-		auth:       auth,
+		repository:      repository,
+		auth:            auth,
+		markupExtractor: markupExtractor,
 	}
 }
 
@@ -190,8 +197,14 @@ func (s *MessagingGRPCServer) CreateMessage(
 		return nil, wrapFieldViolations(fvs)
 	}
 
-	err = s.repository.CreateMessage(ctx, userCtx.UserID, in.GetUserId(), message)
+	err = s.repository.CreateMessage(
+		ctx, userCtx.UserID, in.GetUserId(), message, util.Contains(userCtx.Roles, users.RoleModer),
+	)
 	if err != nil {
+		if errors.Is(err, messaging.ErrBlocked) {
+			return nil, status.Error(codes.PermissionDenied, err.Error())
+		}
+
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
@@ -238,12 +251,23 @@ func (s *MessagingGRPCServer) GetMessages(
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
+	texts := make([]string, len(messages))
+	for idx, msg := range messages {
+		texts[idx] = msg.Text
+	}
+
+	rendered, err := s.markupExtractor.RenderAll(ctx, texts, in.GetLanguage())
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
 	items := make([]*APIMessage, len(messages))
 
 	for idx, msg := range messages {
 		item := APIMessage{
 			Id:              msg.ID,
 			Text:            msg.Text,
+			TextHtml:        rendered[idx].HTML,
 			IsNew:           msg.IsNew,
 			CanDelete:       msg.CanDelete,
 			CanReply:        msg.CanReply,
@@ -280,3 +304,329 @@ func (s *MessagingGRPCServer) GetMessages(
 		},
 	}, nil
 }
+
+func conversationErrorStatus(err error) error {
+	switch {
+	case errors.Is(err, messaging.ErrNotParticipant), errors.Is(err, messaging.ErrBlocked):
+		return status.Error(codes.PermissionDenied, err.Error())
+	case errors.Is(err, messaging.ErrConversationNotFound):
+		return status.Error(codes.NotFound, err.Error())
+	case errors.Is(err, messaging.ErrTooFewParticipants), errors.Is(err, messaging.ErrTooManyParticipants):
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	return status.Error(codes.Internal, err.Error())
+}
+
+func convertConversationParticipants(participants []messaging.Participant) []*MessagingConversationParticipant {
+	result := make([]*MessagingConversationParticipant, 0, len(participants))
+
+	for _, participant := range participants {
+		item := MessagingConversationParticipant{ //nolint:exhaustruct
+			UserId:        participant.UserID,
+			ReadMessageId: participant.ReadMessageID,
+		}
+
+		if participant.ReadAt != nil {
+			item.ReadAt = timestamppb.New(*participant.ReadAt)
+		}
+
+		result = append(result, &item)
+	}
+
+	return result
+}
+
+func convertConversationMessage(msg *messaging.ConversationMessage, html string) *MessagingConversationMessage {
+	item := MessagingConversationMessage{ //nolint:exhaustruct
+		Id:             msg.ID,
+		ConversationId: msg.ConversationID,
+		Text:           msg.Text,
+		TextHtml:       html,
+		Date:           timestamppb.New(msg.Date),
+		IsNew:          msg.IsNew,
+		ReadBy:         msg.ReadBy,
+	}
+
+	if msg.AuthorID != nil {
+		item.AuthorId = *msg.AuthorID
+	}
+
+	return &item
+}
+
+func (s *MessagingGRPCServer) GetConversations(
+	ctx context.Context,
+	in *MessagingGetConversationsRequest,
+) (*MessagingConversations, error) {
+	userCtx, err := s.auth.ValidateGRPC(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if userCtx.UserID == 0 {
+		return nil, status.Errorf(codes.Unauthenticated, "Unauthenticated")
+	}
+
+	conversations, pages, err := s.repository.Conversations(ctx, userCtx.UserID, in.GetPage())
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	texts := make([]string, 0, len(conversations))
+
+	for _, conversation := range conversations {
+		if conversation.LastMessage != nil {
+			texts = append(texts, conversation.LastMessage.Text)
+		}
+	}
+
+	rendered, err := s.markupExtractor.RenderAll(ctx, texts, in.GetLanguage())
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	items := make([]*MessagingConversation, 0, len(conversations))
+	idx := 0
+
+	for _, conversation := range conversations {
+		item := MessagingConversation{ //nolint:exhaustruct
+			Id:           conversation.ID,
+			Title:        conversation.Title,
+			Direct:       conversation.Direct,
+			Muted:        conversation.Muted,
+			Participants: convertConversationParticipants(conversation.Participants),
+			UnreadCount:  conversation.UnreadCount,
+		}
+
+		if conversation.LastMessage != nil {
+			item.LastMessage = convertConversationMessage(conversation.LastMessage, rendered[idx].HTML)
+			idx++
+		}
+
+		items = append(items, &item)
+	}
+
+	return &MessagingConversations{
+		Items: items,
+		Paginator: &Pages{
+			PageCount:        pages.PageCount,
+			First:            pages.First,
+			Last:             pages.Last,
+			Previous:         pages.Previous,
+			Next:             pages.Next,
+			Current:          pages.Current,
+			FirstPageInRange: pages.FirstPageInRange,
+			LastPageInRange:  pages.LastPageInRange,
+			PagesInRange:     pages.PagesInRange,
+			TotalItemCount:   pages.TotalItemCount,
+		},
+	}, nil
+}
+
+func (s *MessagingGRPCServer) CreateConversation(
+	ctx context.Context,
+	in *MessagingCreateConversationRequest,
+) (*MessagingConversationID, error) {
+	userCtx, err := s.auth.ValidateGRPC(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if userCtx.UserID == 0 {
+		return nil, status.Errorf(codes.Unauthenticated, "Unauthenticated")
+	}
+
+	// group conversations are intended for discussions between moderators
+	if !util.Contains(userCtx.Roles, users.RoleModer) {
+		return nil, status.Error(codes.PermissionDenied, "PermissionDenied")
+	}
+
+	titleInputFilter := validation.InputFilter{
+		Filters: []validation.FilterInterface{&validation.StringTrimFilter{}},
+		Validators: []validation.ValidatorInterface{
+			&validation.StringLength{Max: schema.ConversationTitleMaxLength},
+		},
+	}
+
+	title, problems, err := titleInputFilter.IsValidString(in.GetTitle())
+	if err != nil {
+		return nil, err
+	}
+
+	fvs := make([]*errdetails.BadRequest_FieldViolation, 0, len(problems))
+	for _, fv := range problems {
+		fvs = append(fvs, &errdetails.BadRequest_FieldViolation{
+			Field:       "title",
+			Description: fv,
+		})
+	}
+
+	if len(fvs) > 0 {
+		return nil, wrapFieldViolations(fvs)
+	}
+
+	id, err := s.repository.CreateConversation(ctx, userCtx.UserID, title, in.GetUserIds())
+	if err != nil {
+		return nil, conversationErrorStatus(err)
+	}
+
+	return &MessagingConversationID{Id: id}, nil
+}
+
+func (s *MessagingGRPCServer) GetConversationMessages(
+	ctx context.Context,
+	in *MessagingGetConversationMessagesRequest,
+) (*MessagingConversationMessages, error) {
+	userCtx, err := s.auth.ValidateGRPC(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if userCtx.UserID == 0 {
+		return nil, status.Errorf(codes.Unauthenticated, "Unauthenticated")
+	}
+
+	messages, participants, pages, err := s.repository.ConversationMessages(
+		ctx, in.GetConversationId(), userCtx.UserID, in.GetPage(),
+	)
+	if err != nil {
+		return nil, conversationErrorStatus(err)
+	}
+
+	texts := make([]string, len(messages))
+	for idx, msg := range messages {
+		texts[idx] = msg.Text
+	}
+
+	rendered, err := s.markupExtractor.RenderAll(ctx, texts, in.GetLanguage())
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	items := make([]*MessagingConversationMessage, len(messages))
+	for idx := range messages {
+		items[idx] = convertConversationMessage(&messages[idx], rendered[idx].HTML)
+	}
+
+	return &MessagingConversationMessages{
+		Items:        items,
+		Participants: convertConversationParticipants(participants),
+		Paginator: &Pages{
+			PageCount:        pages.PageCount,
+			First:            pages.First,
+			Last:             pages.Last,
+			Previous:         pages.Previous,
+			Next:             pages.Next,
+			Current:          pages.Current,
+			FirstPageInRange: pages.FirstPageInRange,
+			LastPageInRange:  pages.LastPageInRange,
+			PagesInRange:     pages.PagesInRange,
+			TotalItemCount:   pages.TotalItemCount,
+		},
+	}, nil
+}
+
+func (s *MessagingGRPCServer) SendConversationMessage(
+	ctx context.Context,
+	in *MessagingSendConversationMessageRequest,
+) (*MessagingConversationMessageID, error) {
+	userCtx, err := s.auth.ValidateGRPC(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if userCtx.UserID == 0 {
+		return nil, status.Errorf(codes.Unauthenticated, "Unauthenticated")
+	}
+
+	messageInputFilter := validation.InputFilter{
+		Filters: []validation.FilterInterface{&validation.StringTrimFilter{}},
+		Validators: []validation.ValidatorInterface{
+			&validation.NotEmpty{},
+			&validation.StringLength{Max: messaging.MaxText},
+		},
+	}
+
+	message, problems, err := messageInputFilter.IsValidString(in.GetText())
+	if err != nil {
+		return nil, err
+	}
+
+	fvs := make([]*errdetails.BadRequest_FieldViolation, 0, len(problems))
+	for _, fv := range problems {
+		fvs = append(fvs, &errdetails.BadRequest_FieldViolation{
+			Field:       "text",
+			Description: fv,
+		})
+	}
+
+	if len(fvs) > 0 {
+		return nil, wrapFieldViolations(fvs)
+	}
+
+	conversationID := in.GetConversationId()
+	// moderators are not affected by blocks
+	isModer := util.Contains(userCtx.Roles, users.RoleModer)
+
+	if conversationID == 0 {
+		if in.GetUserId() == 0 || in.GetUserId() == userCtx.UserID {
+			return nil, status.Error(codes.InvalidArgument, "conversation_id or user_id expected")
+		}
+
+		// block is checked before conversation is created
+		conversationID, err = s.repository.DirectConversation(ctx, userCtx.UserID, in.GetUserId(), isModer)
+		if err != nil {
+			return nil, conversationErrorStatus(err)
+		}
+	}
+
+	id, err := s.repository.AddConversationMessage(ctx, conversationID, userCtx.UserID, message, isModer)
+	if err != nil {
+		return nil, conversationErrorStatus(err)
+	}
+
+	return &MessagingConversationMessageID{Id: id, ConversationId: conversationID}, nil
+}
+
+func (s *MessagingGRPCServer) MarkConversationRead(
+	ctx context.Context,
+	in *MessagingMarkConversationReadRequest,
+) (*emptypb.Empty, error) {
+	userCtx, err := s.auth.ValidateGRPC(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if userCtx.UserID == 0 {
+		return nil, status.Errorf(codes.Unauthenticated, "Unauthenticated")
+	}
+
+	err = s.repository.MarkConversationRead(ctx, in.GetConversationId(), userCtx.UserID, in.GetMessageId())
+	if err != nil {
+		return nil, conversationErrorStatus(err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (s *MessagingGRPCServer) SetConversationMuted(
+	ctx context.Context,
+	in *MessagingSetConversationMutedRequest,
+) (*emptypb.Empty, error) {
+	userCtx, err := s.auth.ValidateGRPC(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if userCtx.UserID == 0 {
+		return nil, status.Errorf(codes.Unauthenticated, "Unauthenticated")
+	}
+
+	err = s.repository.SetConversationMuted(ctx, in.GetConversationId(), userCtx.UserID, in.GetMuted())
+	if err != nil {
+		return nil, conversationErrorStatus(err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
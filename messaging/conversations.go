@@ -0,0 +1,894 @@
+package messaging
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/autowp/goautowp/schema"
+	"github.com/autowp/goautowp/util"
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+	"github.com/sirupsen/logrus"
+)
+
+var (
+	ErrNotParticipant         = errors.New("user is not a participant of conversation")
+	ErrConversationNotFound   = errors.New("conversation not found")
+	ErrTooFewParticipants     = errors.New("conversation requires at least two participants")
+	ErrTooManyParticipants    = errors.New("too many participants")
+	errSelfDirectConversation = errors.New("direct conversation with self")
+)
+
+const (
+	MaxConversationParticipants = 50
+	ConversationsPerPage        = 20
+)
+
+type Participant struct {
+	UserID        int64
+	ReadMessageID int64
+	ReadAt        *time.Time
+	Muted         bool
+}
+
+type ConversationMessage struct {
+	ID             int64
+	ConversationID int64
+	AuthorID       *int64
+	Text           string
+	Date           time.Time
+	IsNew          bool
+	// ReadBy is participants, except of author, who have read message
+	ReadBy []int64
+}
+
+type Conversation struct {
+	ID           int64
+	Title        string
+	Direct       bool
+	Muted        bool
+	Participants []Participant
+	LastMessage  *ConversationMessage
+	UnreadCount  int32
+}
+
+func directKey(userID int64, withUserID int64) string {
+	return fmt.Sprintf("%d-%d", min(userID, withUserID), max(userID, withUserID))
+}
+
+// DirectConversation returns conversation of two users, creating it on first use.
+// Returns ErrBlocked when withUserID blocked userID, unless userID is moderator.
+func (s *Repository) DirectConversation(
+	ctx context.Context, userID int64, withUserID int64, moder bool,
+) (int64, error) {
+	if userID == withUserID {
+		return 0, errSelfDirectConversation
+	}
+
+	if !moder {
+		blocked, err := s.usersRepository.IsBlocked(ctx, withUserID, userID)
+		if err != nil {
+			return 0, err
+		}
+
+		if blocked {
+			return 0, ErrBlocked
+		}
+	}
+
+	return s.directConversation(context.WithoutCancel(ctx), s.db, userID, withUserID)
+}
+
+func (s *Repository) directConversation(
+	ctx context.Context, db queryBuilder, userID int64, withUserID int64,
+) (int64, error) {
+	key := directKey(userID, withUserID)
+
+	_, err := db.Insert(schema.ConversationTable).
+		Rows(goqu.Record{
+			schema.ConversationTableDirectKeyColName: key,
+			schema.ConversationTableCreatedByColName: userID,
+		}).
+		OnConflict(goqu.DoNothing()).
+		Executor().ExecContext(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	var conversationID int64
+
+	success, err := db.Select(schema.ConversationTableIDCol).
+		From(schema.ConversationTable).
+		Where(schema.ConversationTableDirectKeyCol.Eq(key)).
+		ScanValContext(ctx, &conversationID)
+	if err != nil {
+		return 0, err
+	}
+
+	if !success {
+		return 0, ErrConversationNotFound
+	}
+
+	err = s.addParticipants(ctx, db, conversationID, []int64{userID, withUserID})
+	if err != nil {
+		return 0, err
+	}
+
+	return conversationID, nil
+}
+
+// CreateConversation creates group conversation of creator and users.
+func (s *Repository) CreateConversation(
+	ctx context.Context, creatorID int64, title string, userIDs []int64,
+) (int64, error) {
+	participants := util.RemoveDuplicate(append([]int64{creatorID}, userIDs...))
+
+	if len(participants) < 2 { //nolint: mnd
+		return 0, ErrTooFewParticipants
+	}
+
+	if len(participants) > MaxConversationParticipants {
+		return 0, ErrTooManyParticipants
+	}
+
+	var conversationID int64
+
+	ctx = context.WithoutCancel(ctx)
+
+	err := s.db.WithTx(func(tx *goqu.TxDatabase) error {
+		res, err := tx.Insert(schema.ConversationTable).
+			Rows(goqu.Record{
+				schema.ConversationTableTitleColName:     strings.TrimSpace(title),
+				schema.ConversationTableCreatedByColName: creatorID,
+			}).
+			Executor().ExecContext(ctx)
+		if err != nil {
+			return err
+		}
+
+		conversationID, err = res.LastInsertId()
+		if err != nil {
+			return err
+		}
+
+		return s.addParticipants(ctx, tx, conversationID, participants)
+	})
+
+	return conversationID, err
+}
+
+// queryBuilder is implemented by both goqu.Database and goqu.TxDatabase, so helpers taking it
+// are shared by standalone writes and writes within transaction of caller.
+type queryBuilder interface {
+	From(from ...interface{}) *goqu.SelectDataset
+	Select(cols ...interface{}) *goqu.SelectDataset
+	Insert(table interface{}) *goqu.InsertDataset
+	Update(table interface{}) *goqu.UpdateDataset
+	Delete(table interface{}) *goqu.DeleteDataset
+}
+
+func (s *Repository) addParticipants(
+	ctx context.Context, db queryBuilder, conversationID int64, userIDs []int64,
+) error {
+	rows := make([]interface{}, 0, len(userIDs))
+	for _, userID := range userIDs {
+		rows = append(rows, goqu.Record{
+			schema.ConversationParticipantTableConversationIDColName: conversationID,
+			schema.ConversationParticipantTableUserIDColName:         userID,
+		})
+	}
+
+	_, err := db.Insert(schema.ConversationParticipantTable).
+		Rows(rows...).
+		OnConflict(goqu.DoNothing()).
+		Executor().ExecContext(ctx)
+
+	return err
+}
+
+func (s *Repository) participants(
+	ctx context.Context, conversationIDs []int64,
+) ([]schema.ConversationParticipantRow, error) {
+	rows := make([]schema.ConversationParticipantRow, 0)
+	if len(conversationIDs) == 0 {
+		return rows, nil
+	}
+
+	err := s.db.Select(
+		schema.ConversationParticipantTableConversationIDCol, schema.ConversationParticipantTableUserIDCol,
+		schema.ConversationParticipantTableJoinedAtCol, schema.ConversationParticipantTableReadMessageIDCol,
+		schema.ConversationParticipantTableReadAtCol, schema.ConversationParticipantTableMutedCol,
+	).
+		From(schema.ConversationParticipantTable).
+		Where(schema.ConversationParticipantTableConversationIDCol.In(conversationIDs)).
+		Order(schema.ConversationParticipantTableJoinedAtCol.Asc(), schema.ConversationParticipantTableUserIDCol.Asc()).
+		ScanStructsContext(ctx, &rows)
+
+	return rows, err
+}
+
+// participant returns ErrNotParticipant when user is not in conversation.
+func (s *Repository) participant(
+	ctx context.Context, db queryBuilder, conversationID int64, userID int64,
+) (*schema.ConversationParticipantRow, error) {
+	row := schema.ConversationParticipantRow{}
+
+	success, err := db.Select(
+		schema.ConversationParticipantTableConversationIDCol, schema.ConversationParticipantTableUserIDCol,
+		schema.ConversationParticipantTableJoinedAtCol, schema.ConversationParticipantTableReadMessageIDCol,
+		schema.ConversationParticipantTableReadAtCol, schema.ConversationParticipantTableMutedCol,
+	).
+		From(schema.ConversationParticipantTable).
+		Where(
+			schema.ConversationParticipantTableConversationIDCol.Eq(conversationID),
+			schema.ConversationParticipantTableUserIDCol.Eq(userID),
+		).
+		ScanStructContext(ctx, &row)
+	if err != nil {
+		return nil, err
+	}
+
+	if !success {
+		return nil, ErrNotParticipant
+	}
+
+	return &row, nil
+}
+
+func (s *Repository) conversation(ctx context.Context, conversationID int64) (*schema.ConversationRow, error) {
+	row := schema.ConversationRow{}
+
+	success, err := s.db.Select(
+		schema.ConversationTableIDCol, schema.ConversationTableTitleCol, schema.ConversationTableDirectKeyCol,
+		schema.ConversationTableCreatedByCol, schema.ConversationTableCreatedAtCol,
+		schema.ConversationTableLastMessageIDCol,
+	).
+		From(schema.ConversationTable).
+		Where(schema.ConversationTableIDCol.Eq(conversationID)).
+		ScanStructContext(ctx, &row)
+	if err != nil {
+		return nil, err
+	}
+
+	if !success {
+		return nil, ErrConversationNotFound
+	}
+
+	return &row, nil
+}
+
+// AddConversationMessage posts message to conversation and notifies participants who didn't mute it.
+// Messages of direct conversations are also delivered to inbox of legacy folders.
+func (s *Repository) AddConversationMessage(
+	ctx context.Context, conversationID int64, authorID int64, text string, moder bool,
+) (int64, error) {
+	text = strings.TrimSpace(text)
+
+	err := validateText(text)
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = s.participant(ctx, s.db, conversationID, authorID)
+	if err != nil {
+		return 0, err
+	}
+
+	conversation, err := s.conversation(ctx, conversationID)
+	if err != nil {
+		return 0, err
+	}
+
+	participants, err := s.participants(ctx, []int64{conversationID})
+	if err != nil {
+		return 0, err
+	}
+
+	// participants who blocked author are not notified, direct conversation rejects message.
+	// Moderators are not affected by blocks.
+	blockedBy := make(map[int64]bool, len(participants))
+
+	if !moder {
+		recipientIDs := make([]int64, 0, len(participants))
+
+		for _, participant := range participants {
+			if participant.UserID != authorID {
+				recipientIDs = append(recipientIDs, participant.UserID)
+			}
+		}
+
+		blockerIDs, err := s.usersRepository.BlockedBy(ctx, recipientIDs, authorID)
+		if err != nil {
+			return 0, err
+		}
+
+		if len(blockerIDs) > 0 && conversation.DirectKey.Valid {
+			return 0, ErrBlocked
+		}
+
+		for _, blockerID := range blockerIDs {
+			blockedBy[blockerID] = true
+		}
+	}
+
+	ctx = context.WithoutCancel(ctx)
+
+	var messageID int64
+
+	err = s.db.WithTx(func(tx *goqu.TxDatabase) error {
+		var personalMessageID int64
+
+		if conversation.DirectKey.Valid {
+			for _, participant := range participants {
+				if participant.UserID == authorID {
+					continue
+				}
+
+				personalMessageID, err = s.insertMessage(ctx, tx, authorID, participant.UserID, text)
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		messageID, err = s.insertConversationMessage(ctx, tx, conversationID, authorID, text, personalMessageID)
+
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	// message is stored already, so failed notifications are logged only
+	for _, participant := range participants {
+		if participant.UserID == authorID || blockedBy[participant.UserID] || participant.Muted {
+			continue
+		}
+
+		err = s.createMessageCallback(ctx, authorID, participant.UserID, text)
+		if err != nil {
+			logrus.Errorf(
+				"messaging: failed to notify %d about conversation message %d: %s",
+				participant.UserID, messageID, err.Error(),
+			)
+		}
+	}
+
+	return messageID, nil
+}
+
+// insertConversationMessage stores message, author is considered to have read conversation up to it.
+// personalMessageID links message of direct conversation to its copy in legacy folders.
+func (s *Repository) insertConversationMessage(
+	ctx context.Context, db queryBuilder, conversationID int64, authorID int64, text string, personalMessageID int64,
+) (int64, error) {
+	res, err := db.Insert(schema.ConversationMessageTable).Rows(goqu.Record{
+		schema.ConversationMessageTableConversationIDColName: conversationID,
+		schema.ConversationMessageTableAuthorIDColName:       sql.NullInt64{Int64: authorID, Valid: authorID != 0},
+		schema.ConversationMessageTableTextColName:           text,
+		schema.ConversationMessageTableCreatedAtColName:      goqu.Func("NOW"),
+		schema.ConversationMessageTablePersonalMessageIDColName: sql.NullInt64{
+			Int64: personalMessageID,
+			Valid: personalMessageID != 0,
+		},
+	}).Executor().ExecContext(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	messageID, err := res.LastInsertId()
+	if err != nil {
+		return 0, err
+	}
+
+	_, err = db.Update(schema.ConversationTable).
+		Set(goqu.Record{schema.ConversationTableLastMessageIDColName: messageID}).
+		Where(schema.ConversationTableIDCol.Eq(conversationID)).
+		Executor().ExecContext(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	err = s.markConversationRead(ctx, db, conversationID, authorID, messageID)
+	if err != nil {
+		return 0, err
+	}
+
+	return messageID, nil
+}
+
+// MarkConversationRead moves read position of user up to message, up to the last message when messageID is 0.
+// Read position never moves backwards.
+func (s *Repository) MarkConversationRead(
+	ctx context.Context, conversationID int64, userID int64, messageID int64,
+) error {
+	_, err := s.participant(ctx, s.db, conversationID, userID)
+	if err != nil {
+		return err
+	}
+
+	conversation, err := s.conversation(ctx, conversationID)
+	if err != nil {
+		return err
+	}
+
+	if !conversation.LastMessageID.Valid {
+		return nil
+	}
+
+	if messageID <= 0 || messageID > conversation.LastMessageID.Int64 {
+		messageID = conversation.LastMessageID.Int64
+	}
+
+	return s.markConversationRead(context.WithoutCancel(ctx), s.db, conversationID, userID, messageID)
+}
+
+// markConversationRead moves read position and marks copies of read messages in legacy folders as read.
+func (s *Repository) markConversationRead(
+	ctx context.Context, db queryBuilder, conversationID int64, userID int64, messageID int64,
+) error {
+	res, err := db.Update(schema.ConversationParticipantTable).
+		Set(goqu.Record{
+			schema.ConversationParticipantTableReadMessageIDColName: messageID,
+			schema.ConversationParticipantTableReadAtColName:        goqu.Func("NOW"),
+		}).
+		Where(
+			schema.ConversationParticipantTableConversationIDCol.Eq(conversationID),
+			schema.ConversationParticipantTableUserIDCol.Eq(userID),
+			schema.ConversationParticipantTableReadMessageIDCol.Lt(messageID),
+		).
+		Executor().ExecContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	affected, err := res.RowsAffected()
+	if err != nil || affected == 0 {
+		return err
+	}
+
+	_, err = db.Update(schema.PersonalMessagesTable).
+		Set(goqu.Record{schema.PersonalMessagesTableReadenColName: true}).
+		Where(
+			schema.PersonalMessagesTableToUserIDCol.Eq(userID),
+			schema.PersonalMessagesTableReadenCol.IsFalse(),
+			schema.PersonalMessagesTableIDCol.In(
+				db.Select(schema.ConversationMessageTablePersonalMessageIDCol).
+					From(schema.ConversationMessageTable).
+					Where(
+						schema.ConversationMessageTableConversationIDCol.Eq(conversationID),
+						schema.ConversationMessageTableIDCol.Lte(messageID),
+						schema.ConversationMessageTablePersonalMessageIDCol.IsNotNull(),
+					),
+			),
+		).
+		Executor().ExecContext(ctx)
+
+	return err
+}
+
+// markConversationsReadByPersonalMessages moves read positions of direct conversations
+// up to copies of messages read in legacy folders. Position stops before the first message still unread
+// by user, so reading newer messages in legacy folders doesn't mark older ones as read.
+func (s *Repository) markConversationsReadByPersonalMessages(
+	ctx context.Context, userID int64, personalMessageIDs []int64,
+) error {
+	var conversationIDs []int64
+
+	err := s.db.Select(schema.ConversationMessageTableConversationIDCol).Distinct().
+		From(schema.ConversationMessageTable).
+		Where(schema.ConversationMessageTablePersonalMessageIDCol.In(personalMessageIDs)).
+		ScanValsContext(ctx, &conversationIDs)
+	if err != nil {
+		return err
+	}
+
+	if len(conversationIDs) == 0 {
+		return nil
+	}
+
+	const firstUnreadAlias = "first_unread_id"
+
+	unreadRows := make([]struct {
+		ConversationID int64 `db:"conversation_id"`
+		FirstUnreadID  int64 `db:"first_unread_id"`
+	}, 0)
+
+	// copies deleted by user are not shown in conversation, so they don't hold position back
+	err = s.db.Select(
+		schema.ConversationMessageTableConversationIDCol,
+		goqu.MIN(schema.ConversationMessageTableIDCol).As(firstUnreadAlias),
+	).
+		From(schema.ConversationMessageTable).
+		Join(
+			schema.PersonalMessagesTable,
+			goqu.On(schema.ConversationMessageTablePersonalMessageIDCol.Eq(schema.PersonalMessagesTableIDCol)),
+		).
+		Where(
+			schema.ConversationMessageTableConversationIDCol.In(conversationIDs),
+			schema.PersonalMessagesTableToUserIDCol.Eq(userID),
+			schema.PersonalMessagesTableReadenCol.IsFalse(),
+			schema.PersonalMessagesTableDeletedByToCol.IsFalse(),
+		).
+		GroupBy(schema.ConversationMessageTableConversationIDCol).
+		ScanStructsContext(ctx, &unreadRows)
+	if err != nil {
+		return err
+	}
+
+	firstUnread := make(map[int64]int64, len(unreadRows))
+	for _, row := range unreadRows {
+		firstUnread[row.ConversationID] = row.FirstUnreadID
+	}
+
+	for _, conversationID := range conversationIDs {
+		sqSelect := s.db.Select(goqu.MAX(schema.ConversationMessageTableIDCol)).
+			From(schema.ConversationMessageTable).
+			Where(schema.ConversationMessageTableConversationIDCol.Eq(conversationID))
+
+		if unreadID, ok := firstUnread[conversationID]; ok {
+			sqSelect = sqSelect.Where(schema.ConversationMessageTableIDCol.Lt(unreadID))
+		}
+
+		var messageID sql.NullInt64
+
+		_, err = sqSelect.ScanValContext(ctx, &messageID)
+		if err != nil {
+			return err
+		}
+
+		if !messageID.Valid {
+			continue
+		}
+
+		err = s.markConversationRead(ctx, s.db, conversationID, userID, messageID.Int64)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// visibleTo excludes messages of direct conversations deleted by user in legacy folders.
+func (s *Repository) visibleTo(userID int64) exp.Expression {
+	return goqu.L("NOT EXISTS ?", s.db.Select(goqu.V(true)).
+		From(schema.PersonalMessagesTable).
+		Where(
+			schema.PersonalMessagesTableIDCol.Eq(schema.ConversationMessageTablePersonalMessageIDCol),
+			goqu.Or(
+				goqu.And(
+					schema.PersonalMessagesTableFromUserIDCol.Eq(userID),
+					schema.PersonalMessagesTableDeletedByFromCol.IsTrue(),
+				),
+				goqu.And(
+					schema.PersonalMessagesTableToUserIDCol.Eq(userID),
+					schema.PersonalMessagesTableDeletedByToCol.IsTrue(),
+				),
+			),
+		),
+	)
+}
+
+// SetConversationMuted enables or disables notifications about new messages of conversation.
+func (s *Repository) SetConversationMuted(
+	ctx context.Context, conversationID int64, userID int64, muted bool,
+) error {
+	_, err := s.participant(ctx, s.db, conversationID, userID)
+	if err != nil {
+		return err
+	}
+
+	_, err = s.db.Update(schema.ConversationParticipantTable).
+		Set(goqu.Record{schema.ConversationParticipantTableMutedColName: muted}).
+		Where(
+			schema.ConversationParticipantTableConversationIDCol.Eq(conversationID),
+			schema.ConversationParticipantTableUserIDCol.Eq(userID),
+		).
+		Executor().ExecContext(ctx)
+
+	return err
+}
+
+// Conversations returns conversations of user, recently active first, with last message and unread count.
+func (s *Repository) Conversations(
+	ctx context.Context, userID int64, page int32,
+) ([]Conversation, *util.Pages, error) {
+	paginator := util.Paginator{
+		SQLSelect: s.db.Select(
+			schema.ConversationTableIDCol, schema.ConversationTableTitleCol, schema.ConversationTableDirectKeyCol,
+			schema.ConversationTableCreatedByCol, schema.ConversationTableCreatedAtCol,
+			schema.ConversationTableLastMessageIDCol,
+		).
+			From(schema.ConversationTable).
+			Join(
+				schema.ConversationParticipantTable,
+				goqu.On(schema.ConversationTableIDCol.Eq(schema.ConversationParticipantTableConversationIDCol)),
+			).
+			Where(schema.ConversationParticipantTableUserIDCol.Eq(userID)).
+			Order(
+				goqu.COALESCE(schema.ConversationTableLastMessageIDCol, 0).Desc(),
+				schema.ConversationTableIDCol.Desc(),
+			),
+		ItemCountPerPage:  ConversationsPerPage,
+		CurrentPageNumber: page,
+	}
+
+	ds, err := paginator.GetCurrentItems(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	rows := make([]schema.ConversationRow, 0)
+
+	err = ds.ScanStructsContext(ctx, &rows)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	pages, err := paginator.GetPages(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	ids := make([]int64, 0, len(rows))
+	lastMessageIDs := make([]int64, 0, len(rows))
+
+	for _, row := range rows {
+		ids = append(ids, row.ID)
+
+		if row.LastMessageID.Valid {
+			lastMessageIDs = append(lastMessageIDs, row.LastMessageID.Int64)
+		}
+	}
+
+	participants, err := s.participants(ctx, ids)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	participantsByConversation := make(map[int64][]schema.ConversationParticipantRow, len(ids))
+	for _, participant := range participants {
+		participantsByConversation[participant.ConversationID] = append(
+			participantsByConversation[participant.ConversationID], participant,
+		)
+	}
+
+	lastMessages, err := s.conversationMessagesByIDs(ctx, userID, lastMessageIDs)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	unread, err := s.unreadCounts(ctx, userID, ids)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	result := make([]Conversation, 0, len(rows))
+
+	for _, row := range rows {
+		conversationParticipants := participantsByConversation[row.ID]
+
+		conversation := Conversation{
+			ID:           row.ID,
+			Title:        row.Title,
+			Direct:       row.DirectKey.Valid,
+			Participants: extractParticipants(conversationParticipants),
+			UnreadCount:  unread[row.ID],
+		}
+
+		for _, participant := range conversationParticipants {
+			if participant.UserID == userID {
+				conversation.Muted = participant.Muted
+			}
+		}
+
+		if lastMessage, ok := lastMessages[row.LastMessageID.Int64]; ok && row.LastMessageID.Valid {
+			message := prepareConversationMessage(lastMessage, userID, conversationParticipants)
+			conversation.LastMessage = &message
+		}
+
+		result = append(result, conversation)
+	}
+
+	return result, pages, nil
+}
+
+func (s *Repository) conversationMessagesByIDs(
+	ctx context.Context, userID int64, ids []int64,
+) (map[int64]schema.ConversationMessageRow, error) {
+	result := make(map[int64]schema.ConversationMessageRow, len(ids))
+	if len(ids) == 0 {
+		return result, nil
+	}
+
+	rows := make([]schema.ConversationMessageRow, 0, len(ids))
+
+	err := s.conversationMessagesSelect().
+		Where(schema.ConversationMessageTableIDCol.In(ids), s.visibleTo(userID)).
+		ScanStructsContext(ctx, &rows)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		result[row.ID] = row
+	}
+
+	return result, nil
+}
+
+// unreadCounts returns number of messages of other participants after read position of user.
+func (s *Repository) unreadCounts(ctx context.Context, userID int64, conversationIDs []int64) (map[int64]int32, error) {
+	result := make(map[int64]int32, len(conversationIDs))
+	if len(conversationIDs) == 0 {
+		return result, nil
+	}
+
+	rows := make([]struct {
+		ConversationID int64 `db:"conversation_id"`
+		Count          int32 `db:"count"`
+	}, 0, len(conversationIDs))
+
+	err := s.db.Select(schema.ConversationMessageTableConversationIDCol, goqu.COUNT(goqu.Star()).As("count")).
+		From(schema.ConversationMessageTable).
+		Join(
+			schema.ConversationParticipantTable,
+			goqu.On(
+				schema.ConversationMessageTableConversationIDCol.Eq(
+					schema.ConversationParticipantTableConversationIDCol,
+				),
+				schema.ConversationParticipantTableUserIDCol.Eq(userID),
+			),
+		).
+		Where(
+			schema.ConversationMessageTableConversationIDCol.In(conversationIDs),
+			schema.ConversationMessageTableIDCol.Gt(schema.ConversationParticipantTableReadMessageIDCol),
+			goqu.Or(
+				schema.ConversationMessageTableAuthorIDCol.IsNull(),
+				schema.ConversationMessageTableAuthorIDCol.Neq(userID),
+			),
+			s.visibleTo(userID),
+		).
+		GroupBy(schema.ConversationMessageTableConversationIDCol).
+		ScanStructsContext(ctx, &rows)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		result[row.ConversationID] = row.Count
+	}
+
+	return result, nil
+}
+
+// ConversationMessages returns page of messages, newest first, and participants with their read positions.
+func (s *Repository) ConversationMessages(
+	ctx context.Context, conversationID int64, userID int64, page int32,
+) ([]ConversationMessage, []Participant, *util.Pages, error) {
+	_, err := s.participant(ctx, s.db, conversationID, userID)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	paginator := util.Paginator{
+		SQLSelect: s.conversationMessagesSelect().
+			Where(schema.ConversationMessageTableConversationIDCol.Eq(conversationID), s.visibleTo(userID)).
+			Order(schema.ConversationMessageTableIDCol.Desc()),
+		ItemCountPerPage:  MessagesPerPage,
+		CurrentPageNumber: page,
+	}
+
+	ds, err := paginator.GetCurrentItems(ctx)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	rows := make([]schema.ConversationMessageRow, 0)
+
+	err = ds.ScanStructsContext(ctx, &rows)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	pages, err := paginator.GetPages(ctx)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	participants, err := s.participants(ctx, []int64{conversationID})
+	if err != nil {
+		return nil, nil, nil, err
+	}
+
+	messages := make([]ConversationMessage, 0, len(rows))
+	for _, row := range rows {
+		messages = append(messages, prepareConversationMessage(row, userID, participants))
+	}
+
+	return messages, extractParticipants(participants), pages, nil
+}
+
+func (s *Repository) conversationMessagesSelect() *goqu.SelectDataset {
+	return s.db.Select(
+		schema.ConversationMessageTableIDCol, schema.ConversationMessageTableConversationIDCol,
+		schema.ConversationMessageTableAuthorIDCol, schema.ConversationMessageTableTextCol,
+		schema.ConversationMessageTableCreatedAtCol,
+	).
+		From(schema.ConversationMessageTable)
+}
+
+func prepareConversationMessage(
+	row schema.ConversationMessageRow, userID int64, participants []schema.ConversationParticipantRow,
+) ConversationMessage {
+	message := ConversationMessage{
+		ID:             row.ID,
+		ConversationID: row.ConversationID,
+		AuthorID:       util.SQLNullInt64ToPtr(row.AuthorID),
+		Text:           row.Text,
+		Date:           row.CreatedAt,
+		ReadBy:         make([]int64, 0),
+	}
+
+	for _, participant := range participants {
+		if row.AuthorID.Valid && participant.UserID == row.AuthorID.Int64 {
+			continue
+		}
+
+		if participant.ReadMessageID >= row.ID {
+			message.ReadBy = append(message.ReadBy, participant.UserID)
+		} else if participant.UserID == userID {
+			message.IsNew = true
+		}
+	}
+
+	return message
+}
+
+func extractParticipants(rows []schema.ConversationParticipantRow) []Participant {
+	result := make([]Participant, 0, len(rows))
+
+	for _, row := range rows {
+		participant := Participant{
+			UserID:        row.UserID,
+			ReadMessageID: row.ReadMessageID,
+			Muted:         row.Muted,
+		}
+
+		if row.ReadAt.Valid {
+			participant.ReadAt = &row.ReadAt.Time
+		}
+
+		result = append(result, participant)
+	}
+
+	return result
+}
+
+// appendToDirectConversation mirrors legacy personal message into direct conversation of users.
+// Returns true when recipient muted the conversation.
+func (s *Repository) appendToDirectConversation(
+	ctx context.Context, db queryBuilder, fromUserID int64, toUserID int64, text string, personalMessageID int64,
+) (bool, error) {
+	conversationID, err := s.directConversation(ctx, db, fromUserID, toUserID)
+	if err != nil {
+		return false, err
+	}
+
+	_, err = s.insertConversationMessage(ctx, db, conversationID, fromUserID, text, personalMessageID)
+	if err != nil {
+		return false, err
+	}
+
+	recipient, err := s.participant(ctx, db, conversationID, toUserID)
+	if err != nil {
+		return false, err
+	}
+
+	return recipient.Muted, nil
+}
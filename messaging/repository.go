@@ -9,12 +9,15 @@ import (
 
 	"github.com/autowp/goautowp/i18nbundle"
 	"github.com/autowp/goautowp/schema"
+	"github.com/autowp/goautowp/users"
 	"github.com/autowp/goautowp/util"
 	"github.com/doug-martin/goqu/v9"
 	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/sirupsen/logrus"
 )
 
 var (
+	ErrBlocked        = errors.New("recipient blocked messages from sender")
 	errMessageIsEmpty = errors.New("message is empty")
 	errTooLongMessage = errors.New("too long message")
 )
@@ -30,6 +33,7 @@ const (
 
 type Repository struct {
 	db                    *goqu.Database
+	usersRepository       *users.Repository
 	createMessageCallback CreateMessageCallback
 	i18n                  *i18nbundle.I18n
 }
@@ -52,11 +56,13 @@ type CreateMessageCallback func(ctx context.Context, fromUserID int64, toUserID
 
 func NewRepository(
 	db *goqu.Database,
+	usersRepository *users.Repository,
 	createMessageCallback CreateMessageCallback,
 	i18n *i18nbundle.I18n,
 ) *Repository {
 	return &Repository{
 		db:                    db,
+		usersRepository:       usersRepository,
 		createMessageCallback: createMessageCallback,
 		i18n:                  i18n,
 	}
@@ -71,6 +77,18 @@ func (s *Repository) GetUserNewMessagesCount(ctx context.Context, userID int64)
 	return paginator.GetTotalItemCount(ctx)
 }
 
+func (s *Repository) GetUserNewInboxCountSince(ctx context.Context, userID int64, since time.Time) (int32, error) {
+	paginator := util.Paginator{
+		SQLSelect: s.getInboxSelect(userID).
+			Where(
+				schema.PersonalMessagesTableReadenCol.IsNotTrue(),
+				schema.PersonalMessagesTableAddDatetimeCol.Gt(since),
+			),
+	}
+
+	return paginator.GetTotalItemCount(ctx)
+}
+
 func (s *Repository) GetInboxCount(ctx context.Context, userID int64) (int32, error) {
 	paginator := util.Paginator{
 		SQLSelect: s.getInboxSelect(userID),
@@ -188,16 +206,72 @@ func (s *Repository) CreateMessageFromTemplate(
 		return err
 	}
 
-	return s.CreateMessage(ctx, fromUserID, toUserID, text)
+	return s.CreateMessage(ctx, fromUserID, toUserID, text, false)
 }
 
+// CreateMessage sends personal message. Recipient who blocked sender rejects it, unless sender is moderator.
 func (s *Repository) CreateMessage(
 	ctx context.Context,
 	fromUserID int64,
 	toUserID int64,
 	text string,
+	moder bool,
 ) error {
 	text = strings.TrimSpace(text)
+
+	if fromUserID != 0 && !moder {
+		blocked, err := s.usersRepository.IsBlocked(ctx, toUserID, fromUserID)
+		if err != nil {
+			return err
+		}
+
+		if blocked {
+			return ErrBlocked
+		}
+	}
+
+	ctx = context.WithoutCancel(ctx)
+
+	muted := false
+
+	err := s.db.WithTx(func(tx *goqu.TxDatabase) error {
+		personalMessageID, err := s.insertMessage(ctx, tx, fromUserID, toUserID, text)
+		if err != nil {
+			return err
+		}
+
+		if fromUserID != 0 && fromUserID != toUserID {
+			muted, err = s.appendToDirectConversation(ctx, tx, fromUserID, toUserID, text, personalMessageID)
+		}
+
+		return err
+	})
+	if err != nil {
+		return err
+	}
+
+	if muted {
+		return nil
+	}
+
+	// message is stored already, so failed notification is logged only
+	err = s.createMessageCallback(ctx, fromUserID, toUserID, text)
+	if err != nil {
+		logrus.Errorf("messaging: failed to notify %d about message from %d: %s", toUserID, fromUserID, err.Error())
+	}
+
+	return nil
+}
+
+// CreateSystemMessage stores system message without createMessageCallback,
+// caller is responsible for delivery over other channels.
+func (s *Repository) CreateSystemMessage(ctx context.Context, toUserID int64, text string) error {
+	_, err := s.insertMessage(context.WithoutCancel(ctx), s.db, 0, toUserID, strings.TrimSpace(text))
+
+	return err
+}
+
+func validateText(text string) error {
 	msgLength := len(text)
 
 	if msgLength <= 0 {
@@ -208,11 +282,20 @@ func (s *Repository) CreateMessage(
 		return errTooLongMessage
 	}
 
-	nullableFromUserID := sql.NullInt64{Int64: fromUserID, Valid: fromUserID != 0}
+	return nil
+}
 
-	ctx = context.WithoutCancel(ctx)
+func (s *Repository) insertMessage(
+	ctx context.Context, db queryBuilder, fromUserID int64, toUserID int64, text string,
+) (int64, error) {
+	err := validateText(text)
+	if err != nil {
+		return 0, err
+	}
+
+	nullableFromUserID := sql.NullInt64{Int64: fromUserID, Valid: fromUserID != 0}
 
-	_, err := s.db.Insert(schema.PersonalMessagesTable).Rows(
+	res, err := db.Insert(schema.PersonalMessagesTable).Rows(
 		goqu.Record{
 			schema.PersonalMessagesTableFromUserIDColName:  nullableFromUserID,
 			schema.PersonalMessagesTableToUserIDColName:    toUserID,
@@ -222,22 +305,27 @@ func (s *Repository) CreateMessage(
 		},
 	).Executor().ExecContext(ctx)
 	if err != nil {
-		return err
+		return 0, err
 	}
 
-	return s.createMessageCallback(ctx, fromUserID, toUserID, text)
+	return res.LastInsertId()
 }
 
-func (s *Repository) markReaden(ctx context.Context, ids []int64) error {
-	var err error
-	if len(ids) > 0 {
-		_, err = s.db.Update(schema.PersonalMessagesTable).
-			Set(goqu.Record{schema.PersonalMessagesTableReadenColName: true}).
-			Where(schema.PersonalMessagesTableIDCol.In(ids)).
-			Executor().ExecContext(ctx)
+// markReaden marks messages received by user as read, direct conversations are read up to them as well.
+func (s *Repository) markReaden(ctx context.Context, userID int64, ids []int64) error {
+	if len(ids) == 0 {
+		return nil
 	}
 
-	return err
+	_, err := s.db.Update(schema.PersonalMessagesTable).
+		Set(goqu.Record{schema.PersonalMessagesTableReadenColName: true}).
+		Where(schema.PersonalMessagesTableIDCol.In(ids)).
+		Executor().ExecContext(ctx)
+	if err != nil {
+		return err
+	}
+
+	return s.markConversationsReadByPersonalMessages(ctx, userID, ids)
 }
 
 func (s *Repository) markReadenRows(
@@ -253,7 +341,7 @@ func (s *Repository) markReadenRows(
 		}
 	}
 
-	return s.markReaden(ctx, ids)
+	return s.markReaden(ctx, userID, ids)
 }
 
 func (s *Repository) getBox(
@@ -10,6 +10,7 @@ import (
 	"github.com/autowp/goautowp/config"
 	"github.com/autowp/goautowp/i18nbundle"
 	"github.com/autowp/goautowp/schema"
+	"github.com/autowp/goautowp/users"
 	"github.com/doug-martin/goqu/v9"
 	_ "github.com/doug-martin/goqu/v9/dialect/mysql" // enable mysql dialect
 	_ "github.com/go-sql-driver/mysql"               // enable mysql driver
@@ -30,7 +31,11 @@ func createRepository(t *testing.T) *Repository {
 	i18n, err := i18nbundle.New()
 	require.NoError(t, err)
 
-	s := NewRepository(goquDB, func(_ context.Context, _ int64, _ int64, _ string) error {
+	usersRepository := users.NewRepository(
+		goquDB, nil, cfg.UsersSalt, cfg.Languages, nil, cfg.Keycloak, cfg.MessageInterval, nil,
+	)
+
+	s := NewRepository(goquDB, usersRepository, func(_ context.Context, _ int64, _ int64, _ string) error {
 		return nil
 	}, i18n)
 
@@ -189,7 +194,7 @@ func TestDialogCount(t *testing.T) { //nolint:paralleltest
 	countBefore, err := repo.GetDialogCount(ctx, user1, user2)
 	require.NoError(t, err)
 
-	err = repo.CreateMessage(ctx, user1, user2, "Test message")
+	err = repo.CreateMessage(ctx, user1, user2, "Test message", false)
 	require.NoError(t, err)
 
 	countAfter, err := repo.GetDialogCount(ctx, user1, user2)
@@ -201,3 +206,194 @@ func TestDialogCount(t *testing.T) { //nolint:paralleltest
 	require.NoError(t, err)
 	require.Equal(t, countAfter, messages[0].DialogCount)
 }
+
+func TestConversations(t *testing.T) { //nolint:paralleltest
+	repo := createRepository(t)
+	ctx := t.Context()
+
+	user1 := createRandomUser(t, repo)
+	user2 := createRandomUser(t, repo)
+	user3 := createRandomUser(t, repo)
+
+	// legacy message lands in direct conversation
+	err := repo.CreateMessage(ctx, user1, user2, "Legacy message", false)
+	require.NoError(t, err)
+
+	direct, err := repo.DirectConversation(ctx, user2, user1, false)
+	require.NoError(t, err)
+
+	conversations, _, err := repo.Conversations(ctx, user2, 1)
+	require.NoError(t, err)
+	require.NotEmpty(t, conversations)
+	require.Equal(t, direct, conversations[0].ID)
+	require.True(t, conversations[0].Direct)
+	require.Equal(t, int32(1), conversations[0].UnreadCount)
+	require.NotNil(t, conversations[0].LastMessage)
+	require.Equal(t, "Legacy message", conversations[0].LastMessage.Text)
+
+	// group conversation
+	group, err := repo.CreateConversation(ctx, user1, "Moderators", []int64{user2, user3})
+	require.NoError(t, err)
+
+	messageID, err := repo.AddConversationMessage(ctx, group, user1, "Group message", false)
+	require.NoError(t, err)
+
+	_, err = repo.AddConversationMessage(ctx, direct, user3, "Intrusion", false)
+	require.ErrorIs(t, err, ErrNotParticipant)
+
+	err = repo.MarkConversationRead(ctx, group, user2, 0)
+	require.NoError(t, err)
+
+	messages, participants, _, err := repo.ConversationMessages(ctx, group, user3, 1)
+	require.NoError(t, err)
+	require.Len(t, participants, 3)
+	require.Len(t, messages, 1)
+	require.Equal(t, messageID, messages[0].ID)
+	require.True(t, messages[0].IsNew)
+	require.Equal(t, []int64{user2}, messages[0].ReadBy)
+
+	conversations, _, err = repo.Conversations(ctx, user2, 1)
+	require.NoError(t, err)
+	require.Equal(t, group, conversations[0].ID)
+	require.Equal(t, int32(0), conversations[0].UnreadCount)
+
+	err = repo.SetConversationMuted(ctx, group, user3, true)
+	require.NoError(t, err)
+
+	conversations, _, err = repo.Conversations(ctx, user3, 1)
+	require.NoError(t, err)
+	require.True(t, conversations[0].Muted)
+	require.Equal(t, int32(1), conversations[0].UnreadCount)
+}
+
+func TestConversationSyncsWithLegacyFolders(t *testing.T) { //nolint:paralleltest
+	repo := createRepository(t)
+	ctx := t.Context()
+
+	user1 := createRandomUser(t, repo)
+	user2 := createRandomUser(t, repo)
+
+	err := repo.CreateMessage(ctx, user1, user2, "Legacy message", false)
+	require.NoError(t, err)
+
+	direct, err := repo.DirectConversation(ctx, user1, user2, false)
+	require.NoError(t, err)
+
+	// reading inbox reads conversation
+	inbox, _, err := repo.GetInbox(ctx, user2, 1)
+	require.NoError(t, err)
+	require.Len(t, inbox, 1)
+
+	conversations, _, err := repo.Conversations(ctx, user2, 1)
+	require.NoError(t, err)
+	require.Equal(t, direct, conversations[0].ID)
+	require.Equal(t, int32(0), conversations[0].UnreadCount)
+
+	// reading conversation reads inbox
+	_, err = repo.AddConversationMessage(ctx, direct, user1, "Conversation message", false)
+	require.NoError(t, err)
+
+	count, err := repo.GetInboxNewCount(ctx, user2)
+	require.NoError(t, err)
+	require.Equal(t, int32(1), count)
+
+	err = repo.MarkConversationRead(ctx, direct, user2, 0)
+	require.NoError(t, err)
+
+	count, err = repo.GetInboxNewCount(ctx, user2)
+	require.NoError(t, err)
+	require.Zero(t, count)
+
+	// message deleted from inbox is hidden from recipient only
+	err = repo.DeleteMessage(ctx, user2, inbox[0].ID)
+	require.NoError(t, err)
+
+	messages, _, _, err := repo.ConversationMessages(ctx, direct, user2, 1)
+	require.NoError(t, err)
+	require.Len(t, messages, 1)
+	require.Equal(t, "Conversation message", messages[0].Text)
+
+	messages, _, _, err = repo.ConversationMessages(ctx, direct, user1, 1)
+	require.NoError(t, err)
+	require.Len(t, messages, 2)
+
+	err = repo.ClearSent(ctx, user1)
+	require.NoError(t, err)
+
+	messages, _, _, err = repo.ConversationMessages(ctx, direct, user1, 1)
+	require.NoError(t, err)
+	require.Empty(t, messages)
+
+	conversations, _, err = repo.Conversations(ctx, user1, 1)
+	require.NoError(t, err)
+	require.Equal(t, direct, conversations[0].ID)
+	require.Nil(t, conversations[0].LastMessage)
+}
+
+func TestReadingNewerLegacyMessageKeepsOlderUnread(t *testing.T) { //nolint:paralleltest
+	repo := createRepository(t)
+	ctx := t.Context()
+
+	user1 := createRandomUser(t, repo)
+	user2 := createRandomUser(t, repo)
+
+	err := repo.CreateMessage(ctx, user1, user2, "Older message", false)
+	require.NoError(t, err)
+
+	err = repo.CreateMessage(ctx, user1, user2, "Newer message", false)
+	require.NoError(t, err)
+
+	var ids []int64
+
+	err = repo.db.Select(schema.PersonalMessagesTableIDCol).
+		From(schema.PersonalMessagesTable).
+		Where(schema.PersonalMessagesTableToUserIDCol.Eq(user2)).
+		Order(schema.PersonalMessagesTableIDCol.Asc()).
+		ScanValsContext(ctx, &ids)
+	require.NoError(t, err)
+	require.Len(t, ids, 2)
+
+	err = repo.markReaden(ctx, user2, ids[1:])
+	require.NoError(t, err)
+
+	conversations, _, err := repo.Conversations(ctx, user2, 1)
+	require.NoError(t, err)
+	require.NotEmpty(t, conversations)
+	require.Equal(t, int32(2), conversations[0].UnreadCount)
+
+	err = repo.markReaden(ctx, user2, ids[:1])
+	require.NoError(t, err)
+
+	conversations, _, err = repo.Conversations(ctx, user2, 1)
+	require.NoError(t, err)
+	require.Equal(t, int32(0), conversations[0].UnreadCount)
+}
+
+func TestBlockIgnoredForModerator(t *testing.T) { //nolint:paralleltest
+	repo := createRepository(t)
+	ctx := t.Context()
+
+	user1 := createRandomUser(t, repo)
+	user2 := createRandomUser(t, repo)
+
+	err := repo.usersRepository.BlockUser(ctx, user2, user1)
+	require.NoError(t, err)
+
+	err = repo.CreateMessage(ctx, user1, user2, "Blocked message", false)
+	require.ErrorIs(t, err, ErrBlocked)
+
+	_, err = repo.DirectConversation(ctx, user1, user2, false)
+	require.ErrorIs(t, err, ErrBlocked)
+
+	err = repo.CreateMessage(ctx, user1, user2, "Moderator message", true)
+	require.NoError(t, err)
+
+	direct, err := repo.DirectConversation(ctx, user1, user2, true)
+	require.NoError(t, err)
+
+	_, err = repo.AddConversationMessage(ctx, direct, user1, "Blocked message", false)
+	require.ErrorIs(t, err, ErrBlocked)
+
+	_, err = repo.AddConversationMessage(ctx, direct, user1, "Moderator message", true)
+	require.NoError(t, err)
+}
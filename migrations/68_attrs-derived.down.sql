@@ -0,0 +1,8 @@
+DELETE FROM attrs_values_int WHERE attribute_id IN (231, 232);
+DELETE FROM attrs_values_float WHERE attribute_id = 230;
+DELETE FROM attrs_user_values_int WHERE attribute_id IN (231, 232);
+DELETE FROM attrs_user_values_float WHERE attribute_id = 230;
+DELETE FROM attrs_user_values WHERE attribute_id IN (230, 231, 232);
+DELETE FROM attrs_zone_attributes WHERE attribute_id IN (230, 231, 232);
+DELETE FROM attrs_attributes WHERE id IN (230, 231, 232);
+DELETE FROM attrs_units WHERE id IN (21, 22);
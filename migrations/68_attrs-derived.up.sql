@@ -0,0 +1,15 @@
+INSERT INTO attrs_units (id, name, abbr) VALUES
+(21, 'specs/unit/21/name', 'specs/unit/21/abbr'),
+(22, 'specs/unit/22/name', 'specs/unit/22/abbr');
+
+INSERT INTO attrs_attributes (id, name, type_id, parent_id, unit_id, description, `precision`, position, multiple) VALUES
+(230, 'specs/attrs/dynamic/power-to-weight', 3, 46, 21, '', 1, 59, 0),
+(231, 'specs/attrs/engine/power/kw', 2, 32, 18, '', NULL, 42, 0),
+(232, 'specs/attrs/engine/torque/lb-ft', 2, 36, 22, '', NULL, 40, 0);
+
+INSERT INTO attrs_zone_attributes (zone_id, attribute_id, position) VALUES
+(1, 230, 194), (1, 231, 195), (1, 232, 196),
+(2, 230, 192), (2, 231, 193), (2, 232, 194),
+(3, 230, 246), (3, 231, 247), (3, 232, 248),
+(4, 230, 1002), (4, 231, 1003), (4, 232, 1004),
+(5, 231, 1006), (5, 232, 1007);
@@ -0,0 +1 @@
+DROP TABLE IF EXISTS attrs_dirty_values;
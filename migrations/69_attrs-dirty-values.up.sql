@@ -0,0 +1,11 @@
+CREATE TABLE attrs_dirty_values (
+  attribute_id int unsigned NOT NULL,
+  item_id int unsigned NOT NULL,
+  version bigint unsigned NOT NULL DEFAULT 1,
+  created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (attribute_id, item_id),
+  KEY item_id (item_id),
+  KEY created_at (created_at),
+  CONSTRAINT attrs_dirty_values_attribute_id_fk FOREIGN KEY (attribute_id) REFERENCES attrs_attributes (id) ON DELETE CASCADE,
+  CONSTRAINT attrs_dirty_values_item_id_fk FOREIGN KEY (item_id) REFERENCES item (id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS item_parent_redirect;
+DROP TABLE IF EXISTS item_redirect;
@@ -0,0 +1,20 @@
+CREATE TABLE item_redirect (
+  item_id int unsigned NOT NULL,
+  to_item_id int unsigned NOT NULL,
+  catname varchar(255) DEFAULT NULL,
+  created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (item_id),
+  KEY to_item_id (to_item_id),
+  KEY catname (catname),
+  CONSTRAINT item_redirect_to_item_id_fk FOREIGN KEY (to_item_id) REFERENCES item (id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
+
+CREATE TABLE item_parent_redirect (
+  parent_id int unsigned NOT NULL,
+  catname varchar(150) NOT NULL,
+  item_id int unsigned NOT NULL,
+  PRIMARY KEY (parent_id, catname),
+  KEY item_id (item_id),
+  CONSTRAINT item_parent_redirect_parent_id_fk FOREIGN KEY (parent_id) REFERENCES item (id) ON DELETE CASCADE,
+  CONSTRAINT item_parent_redirect_item_id_fk FOREIGN KEY (item_id) REFERENCES item (id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
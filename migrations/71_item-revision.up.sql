@@ -0,0 +1,17 @@
+CREATE TABLE item_revision (
+  id int unsigned NOT NULL AUTO_INCREMENT,
+  item_id int unsigned NOT NULL,
+  user_id int unsigned DEFAULT NULL,
+  created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  entity varchar(20) NOT NULL,
+  language varchar(5) DEFAULT NULL,
+  parent_id int unsigned DEFAULT NULL,
+  field varchar(50) NOT NULL,
+  old_value mediumtext,
+  new_value mediumtext,
+  PRIMARY KEY (id),
+  KEY item_id (item_id, id),
+  KEY user_id (user_id),
+  CONSTRAINT item_revision_item_id_fk FOREIGN KEY (item_id) REFERENCES item (id) ON DELETE CASCADE,
+  CONSTRAINT item_revision_user_id_fk FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE SET NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
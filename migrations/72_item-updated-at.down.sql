@@ -0,0 +1 @@
+ALTER TABLE item DROP COLUMN updated_at;
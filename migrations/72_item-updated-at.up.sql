@@ -0,0 +1,3 @@
+ALTER TABLE item
+  ADD COLUMN updated_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP ON UPDATE CURRENT_TIMESTAMP,
+  ADD INDEX updated_at (updated_at);
@@ -0,0 +1,2 @@
+DROP TABLE comment_message_history;
+ALTER TABLE comment_message DROP COLUMN edited_at;
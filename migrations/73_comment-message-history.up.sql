@@ -0,0 +1,14 @@
+ALTER TABLE comment_message ADD COLUMN edited_at timestamp NULL DEFAULT NULL;
+
+CREATE TABLE comment_message_history (
+  id int unsigned NOT NULL AUTO_INCREMENT,
+  comment_id int unsigned NOT NULL,
+  user_id int unsigned DEFAULT NULL,
+  created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  message mediumtext NOT NULL,
+  PRIMARY KEY (id),
+  KEY comment_id (comment_id, id),
+  KEY user_id (user_id),
+  CONSTRAINT comment_message_history_comment_id_fk FOREIGN KEY (comment_id) REFERENCES comment_message (id) ON DELETE CASCADE,
+  CONSTRAINT comment_message_history_user_id_fk FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE SET NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
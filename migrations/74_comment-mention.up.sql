@@ -0,0 +1,8 @@
+CREATE TABLE comment_mention (
+  comment_id int unsigned NOT NULL,
+  user_id int unsigned NOT NULL,
+  PRIMARY KEY (comment_id, user_id),
+  KEY user_id (user_id, comment_id),
+  CONSTRAINT comment_mention_comment_id_fk FOREIGN KEY (comment_id) REFERENCES comment_message (id) ON DELETE CASCADE,
+  CONSTRAINT comment_mention_user_id_fk FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
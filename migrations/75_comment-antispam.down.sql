@@ -0,0 +1,2 @@
+DROP TABLE comment_spam_token;
+DROP TABLE comment_classification;
@@ -0,0 +1,17 @@
+CREATE TABLE comment_classification (
+  comment_id int unsigned NOT NULL,
+  score double NOT NULL,
+  action varchar(20) NOT NULL,
+  reasons varchar(1024) NOT NULL DEFAULT '',
+  created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (comment_id),
+  KEY action (action, comment_id),
+  CONSTRAINT comment_classification_comment_id_fk FOREIGN KEY (comment_id) REFERENCES comment_message (id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
+
+CREATE TABLE comment_spam_token (
+  token varchar(64) CHARACTER SET utf8mb4 COLLATE utf8mb4_bin NOT NULL,
+  spam int unsigned NOT NULL,
+  ham int unsigned NOT NULL,
+  PRIMARY KEY (token)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
@@ -0,0 +1,3 @@
+DROP TABLE conversation_message;
+DROP TABLE conversation_participant;
+DROP TABLE conversation;
@@ -0,0 +1,83 @@
+CREATE TABLE conversation (
+  id int unsigned NOT NULL AUTO_INCREMENT,
+  title varchar(255) NOT NULL DEFAULT '',
+  direct_key varchar(32) DEFAULT NULL,
+  created_by int unsigned DEFAULT NULL,
+  created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  last_message_id int unsigned DEFAULT NULL,
+  PRIMARY KEY (id),
+  UNIQUE KEY direct_key (direct_key),
+  CONSTRAINT conversation_created_by_fk FOREIGN KEY (created_by) REFERENCES users (id) ON DELETE SET NULL
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
+
+CREATE TABLE conversation_participant (
+  conversation_id int unsigned NOT NULL,
+  user_id int unsigned NOT NULL,
+  joined_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  read_message_id int unsigned NOT NULL DEFAULT 0,
+  read_at timestamp NULL DEFAULT NULL,
+  muted tinyint(1) NOT NULL DEFAULT 0,
+  PRIMARY KEY (conversation_id, user_id),
+  KEY user_id (user_id, conversation_id),
+  CONSTRAINT conversation_participant_conversation_id_fk FOREIGN KEY (conversation_id) REFERENCES conversation (id) ON DELETE CASCADE,
+  CONSTRAINT conversation_participant_user_id_fk FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
+
+CREATE TABLE conversation_message (
+  id int unsigned NOT NULL AUTO_INCREMENT,
+  conversation_id int unsigned NOT NULL,
+  author_id int unsigned DEFAULT NULL,
+  text mediumtext NOT NULL,
+  created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  personal_message_id int unsigned DEFAULT NULL,
+  PRIMARY KEY (id),
+  KEY conversation_id (conversation_id, id),
+  UNIQUE KEY personal_message_id (personal_message_id),
+  CONSTRAINT conversation_message_conversation_id_fk FOREIGN KEY (conversation_id) REFERENCES conversation (id) ON DELETE CASCADE,
+  CONSTRAINT conversation_message_author_id_fk FOREIGN KEY (author_id) REFERENCES users (id) ON DELETE SET NULL,
+  CONSTRAINT conversation_message_personal_message_id_fk FOREIGN KEY (personal_message_id) REFERENCES personal_messages (id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
+
+-- messages deleted by both users are gone, ones deleted by single user stay hidden from that user only
+INSERT INTO conversation (direct_key, created_at)
+SELECT CONCAT(LEAST(from_user_id, to_user_id), '-', GREATEST(from_user_id, to_user_id)), MIN(add_datetime)
+FROM personal_messages
+WHERE from_user_id IS NOT NULL AND from_user_id <> to_user_id AND NOT (deleted_by_from AND deleted_by_to)
+GROUP BY LEAST(from_user_id, to_user_id), GREATEST(from_user_id, to_user_id);
+
+INSERT INTO conversation_message (conversation_id, author_id, text, created_at, personal_message_id)
+SELECT conversation.id, personal_messages.from_user_id, personal_messages.contents, personal_messages.add_datetime,
+  personal_messages.id
+FROM personal_messages
+  JOIN conversation ON conversation.direct_key = CONCAT(
+    LEAST(personal_messages.from_user_id, personal_messages.to_user_id), '-',
+    GREATEST(personal_messages.from_user_id, personal_messages.to_user_id)
+  )
+WHERE personal_messages.from_user_id IS NOT NULL
+  AND NOT (personal_messages.deleted_by_from AND personal_messages.deleted_by_to)
+ORDER BY personal_messages.id;
+
+UPDATE conversation
+SET last_message_id = (SELECT MAX(id) FROM conversation_message WHERE conversation_id = conversation.id);
+
+-- user has read conversation up to the first message still unread in legacy folders
+INSERT INTO conversation_participant (conversation_id, user_id, joined_at, read_message_id)
+SELECT participant.id, participant.user_id, participant.created_at, COALESCE(
+  (
+    SELECT MIN(conversation_message.id) - 1
+    FROM conversation_message
+      JOIN personal_messages ON personal_messages.id = conversation_message.personal_message_id
+    WHERE conversation_message.conversation_id = participant.id
+      AND personal_messages.to_user_id = participant.user_id
+      AND NOT personal_messages.readen
+  ),
+  participant.last_message_id,
+  0
+)
+FROM (
+  SELECT id, CAST(SUBSTRING_INDEX(direct_key, '-', 1) AS UNSIGNED) AS user_id, created_at, last_message_id
+  FROM conversation
+  UNION ALL
+  SELECT id, CAST(SUBSTRING_INDEX(direct_key, '-', -1) AS UNSIGNED) AS user_id, created_at, last_message_id
+  FROM conversation
+) AS participant;
@@ -0,0 +1,9 @@
+CREATE TABLE user_block (
+  user_id int unsigned NOT NULL,
+  blocked_user_id int unsigned NOT NULL,
+  created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (user_id, blocked_user_id),
+  KEY blocked_user_id (blocked_user_id),
+  CONSTRAINT user_block_user_id_fk FOREIGN KEY (user_id) REFERENCES users (id) ON DELETE CASCADE,
+  CONSTRAINT user_block_blocked_user_id_fk FOREIGN KEY (blocked_user_id) REFERENCES users (id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
@@ -0,0 +1,5 @@
+ALTER TABLE attrs_dirty_values
+  DROP KEY depth,
+  DROP COLUMN next_attempt_at,
+  DROP COLUMN attempts,
+  DROP COLUMN depth;
@@ -0,0 +1,10 @@
+ALTER TABLE attrs_dirty_values
+  ADD COLUMN depth int unsigned NOT NULL DEFAULT 0,
+  ADD COLUMN attempts int unsigned NOT NULL DEFAULT 0,
+  ADD COLUMN next_attempt_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  ADD KEY depth (depth, created_at);
+
+UPDATE attrs_dirty_values
+SET depth = COALESCE((
+  SELECT MAX(diff) FROM item_parent_cache WHERE item_parent_cache.item_id = attrs_dirty_values.item_id
+), 0);
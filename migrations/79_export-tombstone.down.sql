@@ -0,0 +1,8 @@
+DROP TRIGGER IF EXISTS export_tombstone_picture_item_update;
+DROP TRIGGER IF EXISTS export_tombstone_picture_item_delete;
+DROP TRIGGER IF EXISTS export_tombstone_picture_update;
+DROP TRIGGER IF EXISTS export_tombstone_picture_delete;
+DROP TRIGGER IF EXISTS export_tombstone_item_parent_update;
+DROP TRIGGER IF EXISTS export_tombstone_item_parent_delete;
+DROP TRIGGER IF EXISTS export_tombstone_item_delete;
+DROP TABLE IF EXISTS export_tombstone;
@@ -0,0 +1,37 @@
+CREATE TABLE export_tombstone (
+  id bigint unsigned NOT NULL AUTO_INCREMENT,
+  entity varchar(20) NOT NULL,
+  item_id int unsigned DEFAULT NULL,
+  parent_id int unsigned DEFAULT NULL,
+  picture_id int unsigned DEFAULT NULL,
+  deleted_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (id),
+  KEY deleted_at (deleted_at)
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
+
+CREATE TRIGGER export_tombstone_item_delete AFTER DELETE ON item FOR EACH ROW
+  INSERT INTO export_tombstone (entity, item_id) VALUES ('item', OLD.id);
+
+CREATE TRIGGER export_tombstone_item_parent_delete AFTER DELETE ON item_parent FOR EACH ROW
+  INSERT INTO export_tombstone (entity, item_id, parent_id) VALUES ('item_parent', OLD.item_id, OLD.parent_id);
+
+CREATE TRIGGER export_tombstone_item_parent_update AFTER UPDATE ON item_parent FOR EACH ROW
+  INSERT INTO export_tombstone (entity, item_id, parent_id)
+  SELECT 'item_parent', OLD.item_id, OLD.parent_id FROM DUAL
+  WHERE OLD.item_id <> NEW.item_id OR OLD.parent_id <> NEW.parent_id;
+
+CREATE TRIGGER export_tombstone_picture_delete AFTER DELETE ON pictures FOR EACH ROW
+  INSERT INTO export_tombstone (entity, picture_id)
+  SELECT 'picture', OLD.id FROM DUAL WHERE OLD.status = 'accepted';
+
+CREATE TRIGGER export_tombstone_picture_update AFTER UPDATE ON pictures FOR EACH ROW
+  INSERT INTO export_tombstone (entity, picture_id)
+  SELECT 'picture', OLD.id FROM DUAL WHERE OLD.status = 'accepted' AND NEW.status <> 'accepted';
+
+CREATE TRIGGER export_tombstone_picture_item_delete AFTER DELETE ON picture_item FOR EACH ROW
+  INSERT INTO export_tombstone (entity, picture_id, item_id) VALUES ('picture_item', OLD.picture_id, OLD.item_id);
+
+CREATE TRIGGER export_tombstone_picture_item_update AFTER UPDATE ON picture_item FOR EACH ROW
+  INSERT INTO export_tombstone (entity, picture_id, item_id)
+  SELECT 'picture_item', OLD.picture_id, OLD.item_id FROM DUAL
+  WHERE OLD.picture_id <> NEW.picture_id OR OLD.item_id <> NEW.item_id;
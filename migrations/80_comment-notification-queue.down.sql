@@ -0,0 +1 @@
+DROP TABLE comment_notification_queue;
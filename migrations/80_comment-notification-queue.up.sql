@@ -0,0 +1,9 @@
+CREATE TABLE comment_notification_queue (
+  message_id int unsigned NOT NULL,
+  attempts int unsigned NOT NULL DEFAULT 0,
+  next_attempt_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  created_at timestamp NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  PRIMARY KEY (message_id),
+  KEY next_attempt_at (next_attempt_at),
+  CONSTRAINT comment_notification_queue_message_id_fk FOREIGN KEY (message_id) REFERENCES comment_message (id) ON DELETE CASCADE
+) ENGINE=InnoDB DEFAULT CHARSET=utf8mb4 COLLATE=utf8mb4_0900_ai_ci;
@@ -0,0 +1 @@
+ALTER TABLE comment_message DROP COLUMN deleted_as_spam;
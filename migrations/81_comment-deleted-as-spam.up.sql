@@ -0,0 +1 @@
+ALTER TABLE comment_message ADD COLUMN deleted_as_spam tinyint(1) NOT NULL DEFAULT 0 AFTER deleted_by;
@@ -0,0 +1,254 @@
+package notifications
+
+import (
+	"context"
+	"errors"
+	"slices"
+
+	"github.com/autowp/goautowp/config"
+	"github.com/autowp/goautowp/email"
+	"github.com/autowp/goautowp/i18nbundle"
+	"github.com/autowp/goautowp/markup"
+	"github.com/autowp/goautowp/messaging"
+	"github.com/autowp/goautowp/query"
+	"github.com/autowp/goautowp/schema"
+	"github.com/autowp/goautowp/users"
+	"github.com/nicksnyder/go-i18n/v2/i18n"
+	"github.com/sirupsen/logrus"
+)
+
+var ErrUnsupportedPreference = errors.New("unsupported notification event or channel")
+
+type Event string
+
+const (
+	EventReply           Event = "reply"
+	EventSubscription    Event = "subscription"
+	EventPictureAccepted Event = "picture-accepted"
+	EventPictureRemoved  Event = "picture-removed"
+	EventPersonalMessage Event = "personal-message"
+	EventMention         Event = "mention"
+)
+
+var Events = []Event{
+	EventReply, EventSubscription, EventPictureAccepted, EventPictureRemoved, EventPersonalMessage, EventMention,
+}
+
+type Channel string
+
+const (
+	ChannelSystemMessage Channel = "system-message"
+	ChannelEmail         Channel = "email"
+	ChannelTelegram      Channel = "telegram"
+)
+
+var Channels = []Channel{ChannelSystemMessage, ChannelEmail, ChannelTelegram}
+
+// Supported reports whether event can be delivered over channel.
+// Personal message is a message itself, so it is never duplicated as system one.
+func Supported(event Event, channel Channel) bool {
+	return event != EventPersonalMessage || channel != ChannelSystemMessage
+}
+
+// DefaultEnabled reports whether event is delivered over channel when user has not chosen otherwise.
+func DefaultEnabled(event Event, channel Channel) bool {
+	switch channel {
+	case ChannelSystemMessage:
+		return Supported(event, channel)
+	case ChannelTelegram:
+		return true
+	case ChannelEmail:
+		return false
+	}
+
+	return false
+}
+
+type Preference struct {
+	Event   Event
+	Channel Channel
+	Enabled bool
+}
+
+// TemplateDataFunc builds template data of notification in language of receiver.
+type TemplateDataFunc func(lang string) (map[string]interface{}, error)
+
+// TelegramCallback pushes text to telegram chats of user.
+type TelegramCallback func(ctx context.Context, fromUserID int64, toUserID int64, text string) error
+
+// Notifier delivers notification events to users over channels chosen in their preferences.
+type Notifier struct {
+	userRepository      *users.Repository
+	messagingRepository *messaging.Repository
+	emailSender         email.Sender
+	config              config.NotificationsConfig
+	i18n                *i18nbundle.I18n
+	telegramCallback    TelegramCallback
+}
+
+func NewNotifier(
+	userRepository *users.Repository,
+	messagingRepository *messaging.Repository,
+	emailSender email.Sender,
+	config config.NotificationsConfig,
+	i18n *i18nbundle.I18n,
+	telegramCallback TelegramCallback,
+) *Notifier {
+	return &Notifier{
+		userRepository:      userRepository,
+		messagingRepository: messagingRepository,
+		emailSender:         emailSender,
+		config:              config,
+		i18n:                i18n,
+		telegramCallback:    telegramCallback,
+	}
+}
+
+func (s *Notifier) Preferences(ctx context.Context, userID int64) ([]Preference, error) {
+	rows, err := s.userRepository.NotificationPreferences(ctx, userID)
+	if err != nil {
+		return nil, err
+	}
+
+	type key struct {
+		event   Event
+		channel Channel
+	}
+
+	stored := make(map[key]bool, len(rows))
+	for _, row := range rows {
+		stored[key{event: Event(row.Event), channel: Channel(row.Channel)}] = row.Enabled
+	}
+
+	result := make([]Preference, 0, len(Events)*len(Channels))
+
+	for _, event := range Events {
+		for _, channel := range Channels {
+			if !Supported(event, channel) {
+				continue
+			}
+
+			enabled, ok := stored[key{event: event, channel: channel}]
+			if !ok {
+				enabled = DefaultEnabled(event, channel)
+			}
+
+			result = append(result, Preference{Event: event, Channel: channel, Enabled: enabled})
+		}
+	}
+
+	return result, nil
+}
+
+func (s *Notifier) SetPreference(
+	ctx context.Context, userID int64, event Event, channel Channel, enabled bool,
+) error {
+	if !slices.Contains(Events, event) || !slices.Contains(Channels, channel) || !Supported(event, channel) {
+		return ErrUnsupportedPreference
+	}
+
+	return s.userRepository.SetNotificationPreference(ctx, userID, string(event), string(channel), enabled)
+}
+
+// Notify localizes message to language of user and delivers it over enabled channels.
+// Deleted and unknown users are skipped silently.
+func (s *Notifier) Notify(
+	ctx context.Context, event Event, userID int64, messageID string, templateDataFunc TemplateDataFunc,
+) error {
+	ctx = context.WithoutCancel(ctx)
+
+	receiver, err := s.receiver(ctx, userID)
+	if err != nil || receiver == nil {
+		return err
+	}
+
+	templateData, err := templateDataFunc(receiver.Language)
+	if err != nil {
+		return err
+	}
+
+	text, err := s.i18n.Localizer(receiver.Language).Localize(&i18n.LocalizeConfig{
+		DefaultMessage: &i18n.Message{ID: messageID},
+		TemplateData:   templateData,
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.deliver(ctx, event, receiver, 0, text)
+}
+
+// MessageCreated is called for every stored personal message.
+// Messages of users are delivered as EventPersonalMessage, system messages created bypassing Notify
+// are pushed to telegram as before.
+func (s *Notifier) MessageCreated(ctx context.Context, fromUserID int64, toUserID int64, text string) error {
+	if fromUserID == 0 {
+		return s.telegramCallback(ctx, fromUserID, toUserID, text)
+	}
+
+	receiver, err := s.receiver(ctx, toUserID)
+	if err != nil || receiver == nil {
+		return err
+	}
+
+	return s.deliver(ctx, EventPersonalMessage, receiver, fromUserID, text)
+}
+
+func (s *Notifier) receiver(ctx context.Context, userID int64) (*schema.UsersRow, error) {
+	notDeleted := false
+
+	receiver, err := s.userRepository.User(
+		ctx, &query.UserListOptions{ID: userID, Deleted: &notDeleted}, users.UserFields{}, users.OrderByNone,
+	)
+	if errors.Is(err, users.ErrUserNotFound) {
+		return nil, nil //nolint: nilnil
+	}
+
+	return receiver, err
+}
+
+func (s *Notifier) deliver(
+	ctx context.Context, event Event, receiver *schema.UsersRow, fromUserID int64, text string,
+) error {
+	prefs, err := s.Preferences(ctx, receiver.ID)
+	if err != nil {
+		return err
+	}
+
+	for _, pref := range prefs {
+		if pref.Event != event || !pref.Enabled {
+			continue
+		}
+
+		switch pref.Channel {
+		case ChannelSystemMessage:
+			err = s.messagingRepository.CreateSystemMessage(ctx, receiver.ID, text)
+		case ChannelEmail:
+			err = s.sendEmail(event, receiver, text)
+		case ChannelTelegram:
+			err = s.telegramCallback(ctx, fromUserID, receiver.ID, text)
+		}
+
+		// failure of one channel must not prevent delivery to others
+		if err != nil {
+			logrus.Errorf("notifications: failed to deliver %s to user %d via %s: %s", event, receiver.ID, pref.Channel, err)
+		}
+	}
+
+	return nil
+}
+
+func (s *Notifier) sendEmail(event Event, receiver *schema.UsersRow, text string) error {
+	if receiver.EMail == nil || len(*receiver.EMail) == 0 {
+		return nil
+	}
+
+	subject, err := s.i18n.Localizer(receiver.Language).Localize(&i18n.LocalizeConfig{
+		DefaultMessage: &i18n.Message{ID: "notifications/" + string(event) + "/subject"},
+	})
+	if err != nil {
+		return err
+	}
+
+	return s.emailSender.Send(s.config.EmailFrom, []string{*receiver.EMail}, subject, markup.PlainText(text), "")
+}
@@ -0,0 +1,38 @@
+package notifications
+
+import (
+	"context"
+	"testing"
+
+	"github.com/autowp/goautowp/config"
+	"github.com/autowp/goautowp/email"
+	"github.com/autowp/goautowp/i18nbundle"
+	"github.com/autowp/goautowp/messaging"
+	"github.com/autowp/goautowp/users"
+	"github.com/doug-martin/goqu/v9"
+	"github.com/stretchr/testify/require"
+)
+
+// NewTestNotifier builds notifier for tests of notifying packages:
+// system messages are stored, emails and telegram pushes are dropped.
+func NewTestNotifier(
+	t *testing.T, db *goqu.Database, usersRepository *users.Repository, cfg config.NotificationsConfig,
+) *Notifier {
+	t.Helper()
+
+	i18n, err := i18nbundle.New()
+	require.NoError(t, err)
+
+	drop := func(_ context.Context, _ int64, _ int64, _ string) error {
+		return nil
+	}
+
+	return NewNotifier(
+		usersRepository,
+		messaging.NewRepository(db, usersRepository, drop, i18n),
+		&email.MockSender{},
+		cfg,
+		i18n,
+		drop,
+	)
+}
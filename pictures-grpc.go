@@ -17,9 +17,11 @@ import (
 	"github.com/autowp/goautowp/image/sampler"
 	"github.com/autowp/goautowp/items"
 	"github.com/autowp/goautowp/messaging"
+	"github.com/autowp/goautowp/notifications"
 	"github.com/autowp/goautowp/pictures"
 	"github.com/autowp/goautowp/query"
 	"github.com/autowp/goautowp/schema"
+	"github.com/autowp/goautowp/search"
 	"github.com/autowp/goautowp/telegram"
 	"github.com/autowp/goautowp/textstorage"
 	"github.com/autowp/goautowp/users"
@@ -60,6 +62,8 @@ type PicturesGRPCServer struct {
 	pictureExtractor      *PictureExtractor
 	pictureItemExtractor  *PictureItemExtractor
 	itemExtractor         *ItemExtractor
+	searchIndexer         *search.Indexer
+	notifier              *notifications.Notifier
 }
 
 func NewPicturesGRPCServer(
@@ -77,6 +81,8 @@ func NewPicturesGRPCServer(
 	pictureExtractor *PictureExtractor,
 	pictureItemExtractor *PictureItemExtractor,
 	itemExtractor *ItemExtractor,
+	searchIndexer *search.Indexer,
+	notifier *notifications.Notifier,
 ) *PicturesGRPCServer {
 	return &PicturesGRPCServer{
 		repository:            repository,
@@ -95,6 +101,8 @@ func NewPicturesGRPCServer(
 		pictureExtractor:      pictureExtractor,
 		pictureItemExtractor:  pictureItemExtractor,
 		itemExtractor:         itemExtractor,
+		searchIndexer:         searchIndexer,
+		notifier:              notifier,
 	}
 }
 
@@ -484,6 +492,8 @@ func (s *PicturesGRPCServer) restoreFromRemoving(
 		return err
 	}
 
+	s.searchIndexer.Refresh(ctx, search.KindPicture, pic.ID)
+
 	err = s.events.Add(ctx, Event{
 		UserID:   userID,
 		Message:  fmt.Sprintf("Картинки `%d` восстановлена из очереди удаления", pic.ID),
@@ -522,6 +532,8 @@ func (s *PicturesGRPCServer) unaccept(ctx context.Context, pictureID int64, user
 		return err
 	}
 
+	s.searchIndexer.Refresh(ctx, search.KindPicture, pictureID)
+
 	err = s.events.Add(ctx, Event{
 		UserID:   userID,
 		Message:  fmt.Sprintf(`С картинки %d снят статус "принято"`, pictureID),
@@ -917,6 +929,8 @@ func (s *PicturesGRPCServer) SetPictureItemItemID(
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
+	s.searchIndexer.Refresh(ctx, search.KindPicture, in.GetPictureId())
+
 	return &emptypb.Empty{}, nil
 }
 
@@ -963,6 +977,8 @@ func (s *PicturesGRPCServer) DeletePictureItem(
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
+	s.searchIndexer.Refresh(ctx, search.KindPicture, in.GetPictureId())
+
 	return &emptypb.Empty{}, nil
 }
 
@@ -1007,6 +1023,8 @@ func (s *PicturesGRPCServer) CreatePictureItem(
 		}
 	}
 
+	s.searchIndexer.Refresh(ctx, search.KindPicture, in.GetPictureId())
+
 	return &emptypb.Empty{}, nil
 }
 
@@ -1336,6 +1354,8 @@ func (s *PicturesGRPCServer) UpdatePicture(
 		}
 	}
 
+	s.searchIndexer.Refresh(ctx, search.KindPicture, in.GetId())
+
 	return &emptypb.Empty{}, nil
 }
 
@@ -1602,6 +1622,8 @@ func (s *PicturesGRPCServer) SetPictureStatus(
 		return nil, status.Errorf(codes.InvalidArgument, "InvalidArgument")
 	}
 
+	s.searchIndexer.Refresh(ctx, search.KindPicture, pic.ID)
+
 	return &emptypb.Empty{}, nil
 }
 
@@ -1636,7 +1658,7 @@ func (s *PicturesGRPCServer) sendMessage(
 		return err
 	}
 
-	return s.messagingRepository.CreateMessage(ctx, 0, receiver.ID, message)
+	return s.messagingRepository.CreateMessage(ctx, 0, receiver.ID, message, false)
 }
 
 func (s *PicturesGRPCServer) sendLocalizedMessage(
@@ -1678,14 +1700,25 @@ func (s *PicturesGRPCServer) sendLocalizedMessage(
 	)
 }
 
+func (s *PicturesGRPCServer) notify(
+	ctx context.Context, event notifications.Event, userID int64, receiverID sql.NullInt64, messageID string,
+	templateDataFunc notifications.TemplateDataFunc,
+) error {
+	if !receiverID.Valid || (receiverID.Int64 == userID) {
+		return nil
+	}
+
+	return s.notifier.Notify(ctx, event, receiverID.Int64, messageID, templateDataFunc)
+}
+
 func (s *PicturesGRPCServer) notifyAccepted(
 	ctx context.Context, pic *schema.PictureRow, userID int64, isFirstTimeAccepted bool,
 ) error {
 	ctx = context.WithoutCancel(ctx)
 
 	if isFirstTimeAccepted {
-		err := s.sendLocalizedMessage(
-			ctx, userID, pic.OwnerID, "pm/your-picture-accepted-%s",
+		err := s.notify(
+			ctx, notifications.EventPictureAccepted, userID, pic.OwnerID, "pm/your-picture-accepted-%s",
 			func(lang string) (map[string]interface{}, error) {
 				pictureURL, err := s.pictureURL(pic.Identity, lang)
 				if err != nil {
@@ -1697,7 +1730,7 @@ func (s *PicturesGRPCServer) notifyAccepted(
 				}, nil
 			})
 		if err != nil {
-			return fmt.Errorf("sendLocalizedMessage: %w", err)
+			return fmt.Errorf("notify: %w", err)
 		}
 
 		err = s.telegramService.NotifyPicture(ctx, pic, s.itemRepository)
@@ -1751,8 +1784,8 @@ func (s *PicturesGRPCServer) notifyRemoving(
 ) error {
 	ctx = context.WithoutCancel(ctx)
 
-	return s.sendLocalizedMessage(
-		ctx, userID, pic.OwnerID, "pm/your-picture-%s-enqueued-to-remove-%s",
+	return s.notify(
+		ctx, notifications.EventPictureRemoved, userID, pic.OwnerID, "pm/your-picture-%s-enqueued-to-remove-%s",
 		func(lang string) (map[string]interface{}, error) {
 			deleteRequests, err := s.repository.NegativeVotes(ctx, pic.ID)
 			if err != nil {
@@ -2120,9 +2153,31 @@ func (s *PicturesGRPCServer) GetPictures(
 
 	fields := convertPictureFields(in.GetFields())
 
-	rows, pages, err := s.repository.Pictures(ctx, options, fields, order, in.GetPaginator())
-	if err != nil {
-		return nil, status.Error(codes.Internal, err.Error())
+	var (
+		rows       []*schema.PictureRow
+		pages      *util.Pages
+		nextCursor string
+	)
+
+	if in.GetCursor() != nil {
+		rows, nextCursor, err = s.repository.PicturesByCursor(ctx, options, fields, order, in.GetCursor().GetValue())
+		if err != nil {
+			return nil, wrapCursorError(err)
+		}
+
+		if in.GetPaginator() {
+			count, err := s.repository.Count(ctx, options)
+			if err != nil {
+				return nil, status.Error(codes.Internal, err.Error())
+			}
+
+			pages = &util.Pages{TotalItemCount: int32(count)} //nolint: gosec
+		}
+	} else {
+		rows, pages, err = s.repository.Pictures(ctx, options, fields, order, in.GetPaginator())
+		if err != nil {
+			return nil, status.Error(codes.Internal, err.Error())
+		}
 	}
 
 	res, err := s.pictureExtractor.ExtractRows(ctx, rows, in.GetFields(), in.GetLanguage(), userCtx)
@@ -2147,8 +2202,9 @@ func (s *PicturesGRPCServer) GetPictures(
 	}
 
 	return &PicturesList{
-		Items:     res,
-		Paginator: paginator,
+		Items:      res,
+		Paginator:  paginator,
+		NextCursor: nextCursor,
 	}, nil
 }
 
@@ -446,7 +446,7 @@ func (s *PicturesREST) handlePicturePOST(ctx *gin.Context) {
 			userCtx.UserID,
 			values.Comment,
 			ctx.RemoteIP(),
-			false,
+			comments.Moderation{}, //nolint:exhaustruct
 		)
 		if err != nil {
 			ctx.String(http.StatusInternalServerError, err.Error())
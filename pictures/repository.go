@@ -999,6 +999,183 @@ func (s *Repository) Pictures(
 	return res, pages, err
 }
 
+func pictureID(row *schema.PictureRow) any { return row.ID }
+
+func pictureAddDate(row *schema.PictureRow) any { return row.AddDate }
+
+func pictureAcceptDatetime(row *schema.PictureRow) any {
+	if !row.AcceptDatetime.Valid {
+		return nil
+	}
+
+	return row.AcceptDatetime.Time
+}
+
+// pictureKeysets are orders supporting cursor pagination, equal to ones applied by orderBy.
+var pictureKeysets = map[OrderBy]util.Keyset[*schema.PictureRow]{
+	OrderByAddDateDesc: {
+		{Name: schema.PictureTableAddDateColName, Desc: true, Value: pictureAddDate},
+		{Name: schema.PictureTableIDColName, Desc: true, Value: pictureID},
+	},
+	OrderByAddDateAsc: {
+		{Name: schema.PictureTableAddDateColName, Value: pictureAddDate},
+		{Name: schema.PictureTableIDColName, Value: pictureID},
+	},
+	OrderByAcceptDatetimeDesc: {
+		{Name: schema.PictureTableAcceptDatetimeColName, Desc: true, Nullable: true, Value: pictureAcceptDatetime},
+		{Name: schema.PictureTableAddDateColName, Desc: true, Value: pictureAddDate},
+		{Name: schema.PictureTableIDColName, Desc: true, Value: pictureID},
+	},
+	OrderByAcceptDatetimeAsc: {
+		{Name: schema.PictureTableAcceptDatetimeColName, Nullable: true, Value: pictureAcceptDatetime},
+		{Name: schema.PictureTableAddDateColName, Value: pictureAddDate},
+		{Name: schema.PictureTableIDColName, Value: pictureID},
+	},
+	OrderByIDDesc: {
+		{Name: schema.PictureTableIDColName, Desc: true, Value: pictureID},
+	},
+	OrderByIDAsc: {
+		{Name: schema.PictureTableIDColName, Value: pictureID},
+	},
+}
+
+// PicturesByCursor returns page of pictures following cursor and cursor of next page, empty for the last page.
+// Unlike Pictures it doesn't count rows and doesn't use OFFSET.
+func (s *Repository) PicturesByCursor(
+	ctx context.Context,
+	options *query.PictureListOptions,
+	fields *PictureFields,
+	order OrderBy,
+	cursor string,
+) ([]*schema.PictureRow, string, error) {
+	keyset, ok := pictureKeysets[order]
+	if !ok {
+		return nil, "", util.ErrCursorNotSupported
+	}
+
+	after, err := keyset.Decode(cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	pageOptions := *options
+	pageOptions.After = after
+	pageOptions.Page = 0
+
+	sqSelect, err := s.PictureSelect(&pageOptions, fields, order)
+	if err != nil {
+		return nil, "", fmt.Errorf("PictureSelect(): %w", err)
+	}
+
+	if options.Limit > 0 {
+		sqSelect = sqSelect.Limit(uint(options.Limit) + 1)
+	}
+
+	var res []*schema.PictureRow
+
+	err = sqSelect.ScanStructsContext(ctx, &res)
+	if err != nil {
+		return nil, "", err
+	}
+
+	res, next := keyset.Page(res, options.Limit)
+
+	return res, next, nil
+}
+
+// ItemsPictureIDs returns ids of latest accepted pictures of every item, at most limit per item.
+func (s *Repository) ItemsPictureIDs(ctx context.Context, itemIDs []int64, limit uint32) (map[int64][]int64, error) {
+	result := make(map[int64][]int64, len(itemIDs))
+
+	if len(itemIDs) == 0 {
+		return result, nil
+	}
+
+	const rowNumberAlias = "num"
+
+	subSelect := s.db.Select(
+		schema.PictureItemTableItemIDCol,
+		schema.PictureItemTablePictureIDCol,
+		goqu.L(
+			"ROW_NUMBER() OVER(PARTITION BY ? ORDER BY ? DESC, ? DESC)",
+			schema.PictureItemTableItemIDCol, schema.PictureTableAcceptDatetimeCol, schema.PictureTableIDCol,
+		).As(rowNumberAlias),
+	).
+		From(schema.PictureItemTable).
+		Join(schema.PictureTable, goqu.On(schema.PictureItemTablePictureIDCol.Eq(schema.PictureTableIDCol))).
+		Where(
+			schema.PictureItemTableItemIDCol.In(itemIDs),
+			schema.PictureTableStatusCol.Eq(schema.PictureStatusAccepted),
+		)
+
+	var rows []struct {
+		ItemID    int64 `db:"item_id"`
+		PictureID int64 `db:"picture_id"`
+	}
+
+	err := s.db.Select(schema.PictureItemTableItemIDColName, schema.PictureItemTablePictureIDColName).
+		From(subSelect.As("t")).
+		Where(goqu.C(rowNumberAlias).Lte(limit)).
+		Order(goqu.C(schema.PictureItemTableItemIDColName).Asc(), goqu.C(rowNumberAlias).Asc()).
+		ScanStructsContext(ctx, &rows)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		result[row.ItemID] = append(result[row.ItemID], row.PictureID)
+	}
+
+	return result, nil
+}
+
+// PicturesItemIDs returns ids of items depicted on every picture, at most limit per picture.
+func (s *Repository) PicturesItemIDs(
+	ctx context.Context, pictureIDs []int64, limit uint32,
+) (map[int64][]int64, error) {
+	result := make(map[int64][]int64, len(pictureIDs))
+
+	if len(pictureIDs) == 0 {
+		return result, nil
+	}
+
+	const rowNumberAlias = "num"
+
+	subSelect := s.db.Select(
+		schema.PictureItemTablePictureIDCol,
+		schema.PictureItemTableItemIDCol,
+		goqu.L(
+			"ROW_NUMBER() OVER(PARTITION BY ? ORDER BY ?)",
+			schema.PictureItemTablePictureIDCol, schema.PictureItemTableItemIDCol,
+		).As(rowNumberAlias),
+	).
+		From(schema.PictureItemTable).
+		Where(
+			schema.PictureItemTablePictureIDCol.In(pictureIDs),
+			schema.PictureItemTableTypeCol.Eq(schema.PictureItemTypeContent),
+		)
+
+	var rows []struct {
+		PictureID int64 `db:"picture_id"`
+		ItemID    int64 `db:"item_id"`
+	}
+
+	err := s.db.Select(schema.PictureItemTablePictureIDColName, schema.PictureItemTableItemIDColName).
+		From(subSelect.As("t")).
+		Where(goqu.C(rowNumberAlias).Lte(limit)).
+		Order(goqu.C(schema.PictureItemTablePictureIDColName).Asc(), goqu.C(rowNumberAlias).Asc()).
+		ScanStructsContext(ctx, &rows)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, row := range rows {
+		result[row.PictureID] = append(result[row.PictureID], row.ItemID)
+	}
+
+	return result, nil
+}
+
 func (s *Repository) Picture(
 	ctx context.Context, options *query.PictureListOptions, fields *PictureFields, order OrderBy,
 ) (*schema.PictureRow, error) {
@@ -1,7 +1,6 @@
 package pictures
 
 import (
-	"context"
 	"database/sql"
 	"io"
 	"math/rand"
@@ -14,10 +13,9 @@ import (
 	"github.com/autowp/goautowp/comments"
 	"github.com/autowp/goautowp/config"
 	"github.com/autowp/goautowp/hosts"
-	"github.com/autowp/goautowp/i18nbundle"
 	"github.com/autowp/goautowp/image/storage"
 	"github.com/autowp/goautowp/items"
-	"github.com/autowp/goautowp/messaging"
+	"github.com/autowp/goautowp/notifications"
 	"github.com/autowp/goautowp/query"
 	"github.com/autowp/goautowp/schema"
 	"github.com/autowp/goautowp/textstorage"
@@ -96,18 +94,9 @@ func repository(t *testing.T) (*goqu.Database, *Repository) {
 		cfg.MessageInterval,
 		imageStorage,
 	)
-	i, err := i18nbundle.New()
-	require.NoError(t, err)
-
-	msgRepo := messaging.NewRepository(
-		goquDB,
-		func(_ context.Context, _ int64, _ int64, _ string) error {
-			return nil
-		},
-		i,
-	)
+	notifier := notifications.NewTestNotifier(t, goquDB, userRepo, cfg.Notifications)
 	hostsManager := hosts.NewManager(cfg.Languages)
-	commentsRepo := comments.NewRepository(goquDB, userRepo, msgRepo, hostsManager)
+	commentsRepo := comments.NewRepository(goquDB, userRepo, notifier, hostsManager)
 
 	return goquDB, NewRepository(
 		goquDB,
@@ -0,0 +1,7 @@
+CREATE TABLE user_notification_preferences (
+    user_id bigint NOT NULL,
+    event text NOT NULL,
+    channel text NOT NULL,
+    enabled boolean NOT NULL,
+    PRIMARY KEY (user_id, event, channel)
+)
\ No newline at end of file
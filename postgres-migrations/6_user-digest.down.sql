@@ -0,0 +1 @@
+DROP TABLE user_digest;
\ No newline at end of file
@@ -0,0 +1,5 @@
+CREATE TABLE user_digest (
+    user_id bigint NOT NULL PRIMARY KEY,
+    period text NOT NULL,
+    sent_at timestamp with time zone NULL DEFAULT NULL
+)
\ No newline at end of file
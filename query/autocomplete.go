@@ -0,0 +1,69 @@
+package query
+
+import (
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var autocompleteRegexp = regexp.MustCompile(
+	`^(([0-9]{4})([-–]([^[:space:]]{2,4}))?[[:space:]]+)?(.*?)( \((.+)\))?( '([0-9]{4})(–(.+))?)?$`,
+)
+
+// AutocompleteQuery is a text typed into item autocomplete, split to name and production details
+// in the format of formatted item names, e.g. `2005–10 BMW 3 Series (E90) '2004–н.в.`.
+type AutocompleteQuery struct {
+	Name           string
+	Body           string
+	BeginYear      int
+	EndYear        int
+	Today          bool
+	BeginModelYear int
+	EndModelYear   int
+}
+
+func ParseAutocomplete(query string) AutocompleteQuery {
+	result := AutocompleteQuery{Name: query}
+
+	match := autocompleteRegexp.FindStringSubmatch(query)
+	if match == nil {
+		return result
+	}
+
+	result.Name = strings.TrimSpace(match[5])
+	result.Body = strings.TrimSpace(match[7])
+
+	if beginYearStr := match[9]; beginYearStr != "" {
+		result.BeginYear, _ = strconv.Atoi(beginYearStr)
+	}
+
+	if beginModelYearStr := match[2]; beginModelYearStr != "" {
+		result.BeginModelYear, _ = strconv.Atoi(beginModelYearStr)
+	}
+
+	result.EndYear, result.Today = parseAutocompleteEndYear(match[11], result.BeginYear)
+
+	endModelYear, today := parseAutocompleteEndYear(match[4], result.BeginModelYear)
+	result.EndModelYear = endModelYear
+	result.Today = result.Today || today
+
+	return result
+}
+
+// parseAutocompleteEndYear parses end year which is "н.в." (till now), full or short.
+func parseAutocompleteEndYear(value string, beginYear int) (int, bool) {
+	if value == "н.в." {
+		return 0, true
+	}
+
+	endYear, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, false
+	}
+
+	if len(value) == 2 { //nolint: mnd
+		endYear = beginYear - beginYear%100 + endYear
+	}
+
+	return endYear, false
+}
@@ -2,9 +2,6 @@ package query
 
 import (
 	"fmt"
-	"regexp"
-	"strconv"
-	"strings"
 
 	"github.com/autowp/goautowp/schema"
 	"github.com/autowp/goautowp/util"
@@ -63,6 +60,7 @@ type ItemListOptions struct {
 	IsGroup                      bool
 	ExcludeSelfAndChilds         int64
 	Autocomplete                 string
+	AutocompleteItemIDs          []int64
 	SuggestionsTo                int64
 	EngineItem                   *ItemListOptions
 	Dateless                     bool
@@ -76,6 +74,8 @@ type ItemListOptions struct {
 	AttrsUserValues              *AttrsUserValueListOptions
 	AttrsUserValuesCountGte      int
 	YearsRange                   YearsRange
+	// After restricts list to rows following cursor, used by keyset pagination.
+	After *util.KeysetCursor
 }
 
 func ItemParentNoParentAlias(alias string) string {
@@ -119,9 +119,17 @@ func (s *ItemListOptions) Select(db *goqu.Database, alias string) (*goqu.SelectD
 	}
 
 	sqSelect := db.Select().From(schema.ItemTable.As(alias))
+
 	sqSelect, _, err = s.apply(alias, sqSelect)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.After != nil {
+		sqSelect = s.After.Where(sqSelect, alias)
+	}
 
-	return sqSelect, err
+	return sqSelect, nil
 }
 
 func (s *ItemListOptions) ExistsSelect(
@@ -746,119 +754,63 @@ func (s *ItemListOptions) applyAutocompleteFilter(
 		return sqSelect, false
 	}
 
-	query := s.Autocomplete
-
-	var (
-		beginYear      int
-		endYear        int
-		today          = false
-		body           string
-		beginModelYear int
-		endModelYear   int
-	)
-
-	var err error
-
-	regex := regexp.MustCompile(
-		`^(([0-9]{4})([-–]([^[:space:]]{2,4}))?[[:space:]]+)?(.*?)( \((.+)\))?( '([0-9]{4})(–(.+))?)?$`,
-	)
-	match := regex.FindStringSubmatch(query)
-
-	if match != nil {
-		query = strings.TrimSpace(match[5])
-		body = strings.TrimSpace(match[7])
-
-		beginYearStr := match[9]
-		if beginYearStr != "" {
-			beginYear, err = strconv.Atoi(beginYearStr)
-			if err != nil {
-				beginYear = 0
-			}
-		}
-
-		endYearStr := match[11]
-
-		beginModelYearStr := match[2]
-		if beginModelYearStr != "" {
-			beginModelYear, err = strconv.Atoi(beginModelYearStr)
-			if err != nil {
-				beginModelYear = 0
-			}
-		}
-
-		endModelYearStr := match[4]
-
-		if endYearStr == "н.в." {
-			today = true
-		} else {
-			eyLength := len(endYearStr)
-			if eyLength > 0 {
-				endYear, err = strconv.Atoi(endYearStr)
-				if err != nil {
-					endYear = 0
-				}
-
-				if eyLength == 2 {
-					endYear = beginYear - beginYear%100 + endYear
-				}
-			}
-		}
-
-		if endModelYearStr == "н.в." {
-			today = true
-		} else {
-			eyLength := len(endModelYearStr)
-			if eyLength > 0 {
-				endModelYear, err = strconv.Atoi(endModelYearStr)
-				if err != nil {
-					endModelYear = 0
-				}
-
-				if eyLength == 2 {
-					endModelYear = beginModelYear - beginModelYear%100 + endModelYear
-				}
-			}
-		}
-	}
+	autocomplete := ParseAutocomplete(s.Autocomplete)
 
 	aliasTable := goqu.T(alias)
 	groupBy := false
 
-	if query != "" {
+	if autocomplete.Name != "" {
 		groupBy = true
 		ilAlias := alias + "il"
 		ilAliasTable := goqu.T(ilAlias)
+		idCol := aliasTable.Col(schema.ItemTableIDColName)
+		nameCond := ilAliasTable.Col(schema.ItemLanguageTableNameColName).ILike(autocomplete.Name + "%")
+
 		sqSelect = sqSelect.
 			Join(schema.ItemLanguageTable.As(ilAlias), goqu.On(
-				aliasTable.Col(schema.ItemTableIDColName).
-					Eq(ilAliasTable.Col(schema.ItemLanguageTableItemIDColName)),
-			)).
-			Where(ilAliasTable.Col(schema.ItemLanguageTableNameColName).ILike(query + "%"))
+				idCol.Eq(ilAliasTable.Col(schema.ItemLanguageTableItemIDColName)),
+			))
+
+		if len(s.AutocompleteItemIDs) > 0 {
+			// best matches of autocomplete index go first, then prefix matches not known to index
+			ranks := make([]interface{}, 0, len(s.AutocompleteItemIDs)+1)
+			ranks = append(ranks, idCol)
+
+			for i := len(s.AutocompleteItemIDs) - 1; i >= 0; i-- {
+				ranks = append(ranks, s.AutocompleteItemIDs[i])
+			}
+
+			sqSelect = sqSelect.
+				Where(goqu.Or(nameCond, idCol.In(s.AutocompleteItemIDs))).
+				Order(goqu.Func("FIELD", ranks...).Desc())
+		} else {
+			sqSelect = sqSelect.Where(nameCond)
+		}
 	}
 
-	if beginYear > 0 {
-		sqSelect = sqSelect.Where(aliasTable.Col(schema.ItemTableBeginYearColName).Eq(beginYear))
+	if autocomplete.BeginYear > 0 {
+		sqSelect = sqSelect.Where(aliasTable.Col(schema.ItemTableBeginYearColName).Eq(autocomplete.BeginYear))
 	}
 
-	if today {
+	if autocomplete.Today {
 		sqSelect = sqSelect.Where(aliasTable.Col(schema.ItemTableTodayColName).IsTrue())
-	} else if endYear > 0 {
-		sqSelect = sqSelect.Where(aliasTable.Col(schema.ItemTableEndYearColName).Eq(endYear))
+	} else if autocomplete.EndYear > 0 {
+		sqSelect = sqSelect.Where(aliasTable.Col(schema.ItemTableEndYearColName).Eq(autocomplete.EndYear))
 	}
 
-	if body != "" {
-		sqSelect = sqSelect.Where(aliasTable.Col(schema.ItemTableBodyColName).ILike(body + "%"))
+	if autocomplete.Body != "" {
+		sqSelect = sqSelect.Where(aliasTable.Col(schema.ItemTableBodyColName).ILike(autocomplete.Body + "%"))
 	}
 
-	if beginModelYear > 0 {
+	if autocomplete.BeginModelYear > 0 {
 		sqSelect = sqSelect.Where(
-			aliasTable.Col(schema.ItemTableBeginModelYearColName).Eq(beginModelYear),
+			aliasTable.Col(schema.ItemTableBeginModelYearColName).Eq(autocomplete.BeginModelYear),
 		)
 	}
 
-	if endModelYear > 0 {
+	if autocomplete.EndModelYear > 0 {
 		sqSelect = sqSelect.Where(
-			aliasTable.Col(schema.ItemTableEndModelYearColName).Eq(endModelYear),
+			aliasTable.Col(schema.ItemTableEndModelYearColName).Eq(autocomplete.EndModelYear),
 		)
 	}
 
@@ -13,6 +13,7 @@ const (
 type PictureItemListOptions struct {
 	TypeID                      schema.PictureItemType
 	PictureID                   int64
+	PictureIDs                  []int64
 	ItemID                      int64
 	ItemIDs                     []int64
 	ItemIDExpr                  exp.Expression
@@ -127,6 +128,10 @@ func (s *PictureItemListOptions) apply(
 		sqSelect = sqSelect.Where(pictureIDCol.Eq(s.PictureID))
 	}
 
+	if len(s.PictureIDs) > 0 {
+		sqSelect = sqSelect.Where(pictureIDCol.In(s.PictureIDs))
+	}
+
 	if s.ItemID != 0 {
 		sqSelect = sqSelect.Where(itemIDCol.Eq(s.ItemID))
 	}
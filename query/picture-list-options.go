@@ -7,6 +7,7 @@ import (
 
 	"cloud.google.com/go/civil"
 	"github.com/autowp/goautowp/schema"
+	"github.com/autowp/goautowp/util"
 	"github.com/doug-martin/goqu/v9"
 	"github.com/doug-martin/goqu/v9/exp"
 )
@@ -56,6 +57,8 @@ type PictureListOptions struct {
 	HasNoPictureModerVote bool
 	DfDistance            *DfDistanceListOptions
 	HasSpecialName        bool
+	// After restricts list to rows following cursor, used by keyset pagination.
+	After *util.KeysetCursor
 }
 
 func (s *PictureListOptions) Clone() *PictureListOptions {
@@ -83,10 +86,19 @@ func (s *PictureListOptions) Clone() *PictureListOptions {
 }
 
 func (s *PictureListOptions) Select(db *goqu.Database, alias string) (*goqu.SelectDataset, error) {
-	return s.apply(
+	sqSelect, err := s.apply(
 		alias,
 		db.Select().From(schema.PictureTable.As(alias)),
 	)
+	if err != nil {
+		return nil, err
+	}
+
+	if s.After != nil {
+		sqSelect = s.After.Where(sqSelect, alias)
+	}
+
+	return sqSelect, nil
 }
 
 func (s *PictureListOptions) CountSelect(
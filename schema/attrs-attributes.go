@@ -92,6 +92,7 @@ const (
 	EngineStrokeAttr                       int64 = 29
 	EngineVolumeAttr                       int64 = 31
 	EnginePowerAttr                        int64 = 33
+	EngineTorqueAttr                       int64 = 37
 	DriveUnitAttr                          int64 = 41
 	GearboxAttr                            int64 = 42
 	GearboxTypeAttr                        int64 = 43
@@ -130,4 +131,7 @@ const (
 	RearBrakesThicknessAttr                int64 = 149
 	AccelerationTo60MphAttr                int64 = 175
 	EngineTypeAttr                         int64 = 207
+	PowerToWeightAttr                      int64 = 230
+	EnginePowerKWAttr                      int64 = 231
+	EngineTorqueLbFtAttr                   int64 = 232
 )
@@ -0,0 +1,37 @@
+package schema
+
+import (
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+)
+
+const (
+	AttrsDirtyValuesTableName                 = "attrs_dirty_values"
+	AttrsDirtyValuesTableAttributeIDColName   = "attribute_id"
+	AttrsDirtyValuesTableItemIDColName        = "item_id"
+	AttrsDirtyValuesTableVersionColName       = "version"
+	AttrsDirtyValuesTableCreatedAtColName     = "created_at"
+	AttrsDirtyValuesTableDepthColName         = "depth"
+	AttrsDirtyValuesTableAttemptsColName      = "attempts"
+	AttrsDirtyValuesTableNextAttemptAtColName = "next_attempt_at"
+)
+
+var (
+	AttrsDirtyValuesTable                 = goqu.T(AttrsDirtyValuesTableName)
+	AttrsDirtyValuesTableAttributeIDCol   = AttrsDirtyValuesTable.Col(AttrsDirtyValuesTableAttributeIDColName)
+	AttrsDirtyValuesTableItemIDCol        = AttrsDirtyValuesTable.Col(AttrsDirtyValuesTableItemIDColName)
+	AttrsDirtyValuesTableVersionCol       = AttrsDirtyValuesTable.Col(AttrsDirtyValuesTableVersionColName)
+	AttrsDirtyValuesTableCreatedAtCol     = AttrsDirtyValuesTable.Col(AttrsDirtyValuesTableCreatedAtColName)
+	AttrsDirtyValuesTableDepthCol         = AttrsDirtyValuesTable.Col(AttrsDirtyValuesTableDepthColName)
+	AttrsDirtyValuesTableAttemptsCol      = AttrsDirtyValuesTable.Col(AttrsDirtyValuesTableAttemptsColName)
+	AttrsDirtyValuesTableNextAttemptAtCol = AttrsDirtyValuesTable.Col(AttrsDirtyValuesTableNextAttemptAtColName)
+)
+
+type AttrsDirtyValueRow struct {
+	AttributeID int64     `db:"attribute_id"`
+	ItemID      int64     `db:"item_id"`
+	Version     int64     `db:"version"`
+	CreatedAt   time.Time `db:"created_at"`
+	Attempts    int32     `db:"attempts"`
+}
@@ -0,0 +1,57 @@
+package schema
+
+import (
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+)
+
+const (
+	CommentClassificationTableName             = "comment_classification"
+	CommentClassificationTableCommentIDColName = "comment_id"
+	CommentClassificationTableScoreColName     = "score"
+	CommentClassificationTableActionColName    = "action"
+	CommentClassificationTableReasonsColName   = "reasons"
+	CommentClassificationTableCreatedAtColName = "created_at"
+
+	// CommentSpamTokenTableName holds naive Bayes model: number of spam and ham messages containing token.
+	// Row with empty token holds total number of messages the model is trained on.
+	CommentSpamTokenTableName         = "comment_spam_token"
+	CommentSpamTokenTableTokenColName = "token"
+	CommentSpamTokenTableSpamColName  = "spam"
+	CommentSpamTokenTableHamColName   = "ham"
+)
+
+var (
+	CommentClassificationTable             = goqu.T(CommentClassificationTableName)
+	CommentClassificationTableCommentIDCol = CommentClassificationTable.Col(
+		CommentClassificationTableCommentIDColName,
+	)
+	CommentClassificationTableScoreCol   = CommentClassificationTable.Col(CommentClassificationTableScoreColName)
+	CommentClassificationTableActionCol  = CommentClassificationTable.Col(CommentClassificationTableActionColName)
+	CommentClassificationTableReasonsCol = CommentClassificationTable.Col(
+		CommentClassificationTableReasonsColName,
+	)
+	CommentClassificationTableCreatedAtCol = CommentClassificationTable.Col(
+		CommentClassificationTableCreatedAtColName,
+	)
+
+	CommentSpamTokenTable         = goqu.T(CommentSpamTokenTableName)
+	CommentSpamTokenTableTokenCol = CommentSpamTokenTable.Col(CommentSpamTokenTableTokenColName)
+	CommentSpamTokenTableSpamCol  = CommentSpamTokenTable.Col(CommentSpamTokenTableSpamColName)
+	CommentSpamTokenTableHamCol   = CommentSpamTokenTable.Col(CommentSpamTokenTableHamColName)
+)
+
+type CommentClassificationRow struct {
+	CommentID int64     `db:"comment_id"`
+	Score     float64   `db:"score"`
+	Action    string    `db:"action"`
+	Reasons   string    `db:"reasons"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+type CommentSpamTokenRow struct {
+	Token string `db:"token"`
+	Spam  int64  `db:"spam"`
+	Ham   int64  `db:"ham"`
+}
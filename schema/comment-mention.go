@@ -0,0 +1,15 @@
+package schema
+
+import "github.com/doug-martin/goqu/v9"
+
+const (
+	CommentMentionTableName             = "comment_mention"
+	CommentMentionTableCommentIDColName = "comment_id"
+	CommentMentionTableUserIDColName    = "user_id"
+)
+
+var (
+	CommentMentionTable             = goqu.T(CommentMentionTableName)
+	CommentMentionTableCommentIDCol = CommentMentionTable.Col(CommentMentionTableCommentIDColName)
+	CommentMentionTableUserIDCol    = CommentMentionTable.Col(CommentMentionTableUserIDColName)
+)
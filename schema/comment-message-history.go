@@ -0,0 +1,39 @@
+package schema
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+)
+
+const (
+	CommentMessageHistoryTableName             = "comment_message_history"
+	CommentMessageHistoryTableIDColName        = "id"
+	CommentMessageHistoryTableCommentIDColName = "comment_id"
+	CommentMessageHistoryTableUserIDColName    = "user_id"
+	CommentMessageHistoryTableCreatedAtColName = "created_at"
+	CommentMessageHistoryTableMessageColName   = "message"
+)
+
+var (
+	CommentMessageHistoryTable             = goqu.T(CommentMessageHistoryTableName)
+	CommentMessageHistoryTableIDCol        = CommentMessageHistoryTable.Col(CommentMessageHistoryTableIDColName)
+	CommentMessageHistoryTableCommentIDCol = CommentMessageHistoryTable.Col(
+		CommentMessageHistoryTableCommentIDColName,
+	)
+	CommentMessageHistoryTableUserIDCol    = CommentMessageHistoryTable.Col(CommentMessageHistoryTableUserIDColName)
+	CommentMessageHistoryTableCreatedAtCol = CommentMessageHistoryTable.Col(
+		CommentMessageHistoryTableCreatedAtColName,
+	)
+	CommentMessageHistoryTableMessageCol = CommentMessageHistoryTable.Col(CommentMessageHistoryTableMessageColName)
+)
+
+// CommentMessageHistoryRow is previous version of message, replaced at CreatedAt by edit of UserID.
+type CommentMessageHistoryRow struct {
+	ID        int64         `db:"id"`
+	CommentID int64         `db:"comment_id"`
+	UserID    sql.NullInt64 `db:"user_id"`
+	CreatedAt time.Time     `db:"created_at"`
+	Message   string        `db:"message"`
+}
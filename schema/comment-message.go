@@ -33,9 +33,13 @@ const (
 	CommentMessageTableModeratorAttentionColName = "moderator_attention"
 	CommentMessageTableDeletedColName            = "deleted"
 	CommentMessageTableDeletedByColName          = "deleted_by"
+	CommentMessageTableDeletedAsSpamColName      = "deleted_as_spam"
 	CommentMessageTableDeleteDateColName         = "delete_date"
 	CommentMessageTableRepliesCountColName       = "replies_count"
 	CommentMessageTableVoteColName               = "vote"
+	CommentMessageTableDatetimeColName           = "datetime"
+	CommentMessageTableMessageColName            = "message"
+	CommentMessageTableEditedAtColName           = "edited_at"
 )
 
 type CommentMessageRow struct {
@@ -50,6 +54,8 @@ type CommentMessageRow struct {
 	IP                 net.IP                           `db:"ip"`
 	Message            string                           `db:"message"`
 	Vote               int32                            `db:"vote"`
+	EditedAt           sql.NullTime                     `db:"edited_at"`
+	RepliesCount       int32                            `db:"replies_count"`
 }
 
 var (
@@ -64,19 +70,23 @@ var (
 	CommentMessageTableAuthorIDCol = CommentMessageTable.Col(
 		CommentMessageTableAuthorIDColName,
 	)
-	CommentMessageTableDatetimeCol = CommentMessageTable.Col("datetime")
+	CommentMessageTableDatetimeCol = CommentMessageTable.Col(CommentMessageTableDatetimeColName)
 	CommentMessageTableVoteCol     = CommentMessageTable.Col(
 		CommentMessageTableVoteColName,
 	)
 	CommentMessageTableParentIDCol = CommentMessageTable.Col(
 		CommentMessageTableParentIDColName,
 	)
-	CommentMessageTableMessageCol = CommentMessageTable.Col("message")
-	CommentMessageTableIPCol      = CommentMessageTable.Col("ip")
-	CommentMessageTableDeletedCol = CommentMessageTable.Col(
+	CommentMessageTableMessageCol  = CommentMessageTable.Col(CommentMessageTableMessageColName)
+	CommentMessageTableIPCol       = CommentMessageTable.Col("ip")
+	CommentMessageTableEditedAtCol = CommentMessageTable.Col(CommentMessageTableEditedAtColName)
+	CommentMessageTableDeletedCol  = CommentMessageTable.Col(
 		CommentMessageTableDeletedColName,
 	)
 	CommentMessageTableModeratorAttentionCol = CommentMessageTable.Col(
 		CommentMessageTableModeratorAttentionColName,
 	)
+	CommentMessageTableDeletedByCol     = CommentMessageTable.Col(CommentMessageTableDeletedByColName)
+	CommentMessageTableDeletedAsSpamCol = CommentMessageTable.Col(CommentMessageTableDeletedAsSpamColName)
+	CommentMessageTableRepliesCountCol  = CommentMessageTable.Col(CommentMessageTableRepliesCountColName)
 )
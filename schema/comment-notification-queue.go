@@ -0,0 +1,34 @@
+package schema
+
+import (
+	"github.com/doug-martin/goqu/v9"
+)
+
+const (
+	CommentNotificationQueueTableName                 = "comment_notification_queue"
+	CommentNotificationQueueTableMessageIDColName     = "message_id"
+	CommentNotificationQueueTableAttemptsColName      = "attempts"
+	CommentNotificationQueueTableNextAttemptAtColName = "next_attempt_at"
+	CommentNotificationQueueTableCreatedAtColName     = "created_at"
+)
+
+var (
+	CommentNotificationQueueTable             = goqu.T(CommentNotificationQueueTableName)
+	CommentNotificationQueueTableMessageIDCol = CommentNotificationQueueTable.Col(
+		CommentNotificationQueueTableMessageIDColName,
+	)
+	CommentNotificationQueueTableAttemptsCol = CommentNotificationQueueTable.Col(
+		CommentNotificationQueueTableAttemptsColName,
+	)
+	CommentNotificationQueueTableNextAttemptAtCol = CommentNotificationQueueTable.Col(
+		CommentNotificationQueueTableNextAttemptAtColName,
+	)
+	CommentNotificationQueueTableCreatedAtCol = CommentNotificationQueueTable.Col(
+		CommentNotificationQueueTableCreatedAtColName,
+	)
+)
+
+type CommentNotificationQueueRow struct {
+	MessageID int64 `db:"message_id"`
+	Attempts  int32 `db:"attempts"`
+}
@@ -0,0 +1,107 @@
+package schema
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+)
+
+const (
+	ConversationTableName         = "conversation"
+	ConversationTableIDColName    = "id"
+	ConversationTableTitleColName = "title"
+	// ConversationTableDirectKeyColName identifies conversation of two users as "<min user id>-<max user id>",
+	// NULL for group conversations.
+	ConversationTableDirectKeyColName     = "direct_key"
+	ConversationTableCreatedByColName     = "created_by"
+	ConversationTableCreatedAtColName     = "created_at"
+	ConversationTableLastMessageIDColName = "last_message_id"
+	ConversationTitleMaxLength            = 255
+
+	ConversationParticipantTableName                  = "conversation_participant"
+	ConversationParticipantTableConversationIDColName = "conversation_id"
+	ConversationParticipantTableUserIDColName         = "user_id"
+	ConversationParticipantTableJoinedAtColName       = "joined_at"
+	ConversationParticipantTableReadMessageIDColName  = "read_message_id"
+	ConversationParticipantTableReadAtColName         = "read_at"
+	ConversationParticipantTableMutedColName          = "muted"
+
+	ConversationMessageTableName                  = "conversation_message"
+	ConversationMessageTableIDColName             = "id"
+	ConversationMessageTableConversationIDColName = "conversation_id"
+	ConversationMessageTableAuthorIDColName       = "author_id"
+	ConversationMessageTableTextColName           = "text"
+	ConversationMessageTableCreatedAtColName      = "created_at"
+	// ConversationMessageTablePersonalMessageIDColName links message of direct conversation to its copy
+	// in legacy folders, so deletes and read marks are shared.
+	ConversationMessageTablePersonalMessageIDColName = "personal_message_id"
+)
+
+var (
+	ConversationTable                 = goqu.T(ConversationTableName)
+	ConversationTableIDCol            = ConversationTable.Col(ConversationTableIDColName)
+	ConversationTableTitleCol         = ConversationTable.Col(ConversationTableTitleColName)
+	ConversationTableDirectKeyCol     = ConversationTable.Col(ConversationTableDirectKeyColName)
+	ConversationTableCreatedByCol     = ConversationTable.Col(ConversationTableCreatedByColName)
+	ConversationTableCreatedAtCol     = ConversationTable.Col(ConversationTableCreatedAtColName)
+	ConversationTableLastMessageIDCol = ConversationTable.Col(ConversationTableLastMessageIDColName)
+
+	ConversationParticipantTable                  = goqu.T(ConversationParticipantTableName)
+	ConversationParticipantTableConversationIDCol = ConversationParticipantTable.Col(
+		ConversationParticipantTableConversationIDColName,
+	)
+	ConversationParticipantTableUserIDCol = ConversationParticipantTable.Col(
+		ConversationParticipantTableUserIDColName,
+	)
+	ConversationParticipantTableJoinedAtCol = ConversationParticipantTable.Col(
+		ConversationParticipantTableJoinedAtColName,
+	)
+	ConversationParticipantTableReadMessageIDCol = ConversationParticipantTable.Col(
+		ConversationParticipantTableReadMessageIDColName,
+	)
+	ConversationParticipantTableReadAtCol = ConversationParticipantTable.Col(
+		ConversationParticipantTableReadAtColName,
+	)
+	ConversationParticipantTableMutedCol = ConversationParticipantTable.Col(
+		ConversationParticipantTableMutedColName,
+	)
+
+	ConversationMessageTable                  = goqu.T(ConversationMessageTableName)
+	ConversationMessageTableIDCol             = ConversationMessageTable.Col(ConversationMessageTableIDColName)
+	ConversationMessageTableConversationIDCol = ConversationMessageTable.Col(
+		ConversationMessageTableConversationIDColName,
+	)
+	ConversationMessageTableAuthorIDCol          = ConversationMessageTable.Col(ConversationMessageTableAuthorIDColName)
+	ConversationMessageTableTextCol              = ConversationMessageTable.Col(ConversationMessageTableTextColName)
+	ConversationMessageTableCreatedAtCol         = ConversationMessageTable.Col(ConversationMessageTableCreatedAtColName)
+	ConversationMessageTablePersonalMessageIDCol = ConversationMessageTable.Col(
+		ConversationMessageTablePersonalMessageIDColName,
+	)
+)
+
+type ConversationRow struct {
+	ID            int64          `db:"id"`
+	Title         string         `db:"title"`
+	DirectKey     sql.NullString `db:"direct_key"`
+	CreatedBy     sql.NullInt64  `db:"created_by"`
+	CreatedAt     time.Time      `db:"created_at"`
+	LastMessageID sql.NullInt64  `db:"last_message_id"`
+}
+
+type ConversationParticipantRow struct {
+	ConversationID int64        `db:"conversation_id"`
+	UserID         int64        `db:"user_id"`
+	JoinedAt       time.Time    `db:"joined_at"`
+	ReadMessageID  int64        `db:"read_message_id"`
+	ReadAt         sql.NullTime `db:"read_at"`
+	Muted          bool         `db:"muted"`
+}
+
+type ConversationMessageRow struct {
+	ID             int64         `db:"id"`
+	ConversationID int64         `db:"conversation_id"`
+	AuthorID       sql.NullInt64 `db:"author_id"`
+	Text           string        `db:"text"`
+	CreatedAt      time.Time     `db:"created_at"`
+}
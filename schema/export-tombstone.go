@@ -0,0 +1,23 @@
+package schema
+
+import "github.com/doug-martin/goqu/v9"
+
+const (
+	ExportTombstoneTableName             = "export_tombstone"
+	ExportTombstoneTableIDColName        = "id"
+	ExportTombstoneTableEntityColName    = "entity"
+	ExportTombstoneTableItemIDColName    = "item_id"
+	ExportTombstoneTableParentIDColName  = "parent_id"
+	ExportTombstoneTablePictureIDColName = "picture_id"
+	ExportTombstoneTableDeletedAtColName = "deleted_at"
+)
+
+var (
+	ExportTombstoneTable             = goqu.T(ExportTombstoneTableName)
+	ExportTombstoneTableIDCol        = ExportTombstoneTable.Col(ExportTombstoneTableIDColName)
+	ExportTombstoneTableEntityCol    = ExportTombstoneTable.Col(ExportTombstoneTableEntityColName)
+	ExportTombstoneTableItemIDCol    = ExportTombstoneTable.Col(ExportTombstoneTableItemIDColName)
+	ExportTombstoneTableParentIDCol  = ExportTombstoneTable.Col(ExportTombstoneTableParentIDColName)
+	ExportTombstoneTablePictureIDCol = ExportTombstoneTable.Col(ExportTombstoneTablePictureIDColName)
+	ExportTombstoneTableDeletedAtCol = ExportTombstoneTable.Col(ExportTombstoneTableDeletedAtColName)
+)
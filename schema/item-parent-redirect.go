@@ -0,0 +1,17 @@
+package schema
+
+import "github.com/doug-martin/goqu/v9"
+
+const (
+	ItemParentRedirectTableName            = "item_parent_redirect"
+	ItemParentRedirectTableParentIDColName = "parent_id"
+	ItemParentRedirectTableCatnameColName  = "catname"
+	ItemParentRedirectTableItemIDColName   = "item_id"
+)
+
+var (
+	ItemParentRedirectTable            = goqu.T(ItemParentRedirectTableName)
+	ItemParentRedirectTableParentIDCol = ItemParentRedirectTable.Col(ItemParentRedirectTableParentIDColName)
+	ItemParentRedirectTableCatnameCol  = ItemParentRedirectTable.Col(ItemParentRedirectTableCatnameColName)
+	ItemParentRedirectTableItemIDCol   = ItemParentRedirectTable.Col(ItemParentRedirectTableItemIDColName)
+)
@@ -0,0 +1,17 @@
+package schema
+
+import "github.com/doug-martin/goqu/v9"
+
+const (
+	ItemRedirectTableName            = "item_redirect"
+	ItemRedirectTableItemIDColName   = "item_id"
+	ItemRedirectTableToItemIDColName = "to_item_id"
+	ItemRedirectTableCatnameColName  = "catname"
+)
+
+var (
+	ItemRedirectTable            = goqu.T(ItemRedirectTableName)
+	ItemRedirectTableItemIDCol   = ItemRedirectTable.Col(ItemRedirectTableItemIDColName)
+	ItemRedirectTableToItemIDCol = ItemRedirectTable.Col(ItemRedirectTableToItemIDColName)
+	ItemRedirectTableCatnameCol  = ItemRedirectTable.Col(ItemRedirectTableCatnameColName)
+)
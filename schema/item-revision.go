@@ -0,0 +1,53 @@
+package schema
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+)
+
+const (
+	ItemRevisionTableName             = "item_revision"
+	ItemRevisionTableIDColName        = "id"
+	ItemRevisionTableItemIDColName    = "item_id"
+	ItemRevisionTableUserIDColName    = "user_id"
+	ItemRevisionTableCreatedAtColName = "created_at"
+	ItemRevisionTableEntityColName    = "entity"
+	ItemRevisionTableLanguageColName  = "language"
+	ItemRevisionTableParentIDColName  = "parent_id"
+	ItemRevisionTableFieldColName     = "field"
+	ItemRevisionTableOldValueColName  = "old_value"
+	ItemRevisionTableNewValueColName  = "new_value"
+
+	ItemRevisionEntityItem         = "item"
+	ItemRevisionEntityItemLanguage = "item_language"
+	ItemRevisionEntityItemParent   = "item_parent"
+)
+
+var (
+	ItemRevisionTable             = goqu.T(ItemRevisionTableName)
+	ItemRevisionTableIDCol        = ItemRevisionTable.Col(ItemRevisionTableIDColName)
+	ItemRevisionTableItemIDCol    = ItemRevisionTable.Col(ItemRevisionTableItemIDColName)
+	ItemRevisionTableUserIDCol    = ItemRevisionTable.Col(ItemRevisionTableUserIDColName)
+	ItemRevisionTableCreatedAtCol = ItemRevisionTable.Col(ItemRevisionTableCreatedAtColName)
+	ItemRevisionTableEntityCol    = ItemRevisionTable.Col(ItemRevisionTableEntityColName)
+	ItemRevisionTableLanguageCol  = ItemRevisionTable.Col(ItemRevisionTableLanguageColName)
+	ItemRevisionTableParentIDCol  = ItemRevisionTable.Col(ItemRevisionTableParentIDColName)
+	ItemRevisionTableFieldCol     = ItemRevisionTable.Col(ItemRevisionTableFieldColName)
+	ItemRevisionTableOldValueCol  = ItemRevisionTable.Col(ItemRevisionTableOldValueColName)
+	ItemRevisionTableNewValueCol  = ItemRevisionTable.Col(ItemRevisionTableNewValueColName)
+)
+
+type ItemRevisionRow struct {
+	ID        int64          `db:"id"`
+	ItemID    int64          `db:"item_id"`
+	UserID    sql.NullInt64  `db:"user_id"`
+	CreatedAt time.Time      `db:"created_at"`
+	Entity    string         `db:"entity"`
+	Language  sql.NullString `db:"language"`
+	ParentID  sql.NullInt64  `db:"parent_id"`
+	Field     string         `db:"field"`
+	OldValue  sql.NullString `db:"old_value"`
+	NewValue  sql.NullString `db:"new_value"`
+}
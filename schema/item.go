@@ -49,6 +49,7 @@ const (
 	ItemTableVehicleTypeInheritColName     = "vehicle_type_inherit"
 	ItemTableSpecInheritColName            = "spec_inherit"
 	ItemTableProducedColName               = "produced"
+	ItemTableUpdatedAtColName              = "updated_at"
 
 	ItemNameMinLength     = 2
 	ItemNameMaxLength     = 150
@@ -88,6 +89,10 @@ var ( //nolint: dupl
 	ItemTableProducedCol               = ItemTable.Col(ItemTableProducedColName)
 	ItemTableProducedExactlyCol        = ItemTable.Col(ItemTableProducedExactlyColName)
 	ItemTableBeginOrderCacheCol        = ItemTable.Col(ItemTableBeginOrderCacheColName)
+	ItemTableEndOrderCacheCol          = ItemTable.Col(ItemTableEndOrderCacheColName)
+	ItemTableUpdatedAtCol              = ItemTable.Col(ItemTableUpdatedAtColName)
+	ItemTableFullNameCol               = ItemTable.Col(ItemTableFullNameColName)
+	ItemTableAddDatetimeCol            = ItemTable.Col(ItemTableAddDatetimeColName)
 )
 
 type ItemRow struct {
@@ -0,0 +1,13 @@
+package schema
+
+import "github.com/doug-martin/goqu/v9"
+
+const (
+	ModificationTableName          = "modification"
+	ModificationTableItemIDColName = "item_id"
+)
+
+var (
+	ModificationTable          = goqu.T(ModificationTableName)
+	ModificationTableItemIDCol = ModificationTable.Col(ModificationTableItemIDColName)
+)
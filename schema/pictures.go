@@ -61,6 +61,11 @@ var (
 	PictureTableAcceptDatetimeCol     = PictureTable.Col(PictureTableAcceptDatetimeColName)
 	PictureTableContentCountCol       = PictureTable.Col(PictureTableContentCountColName)
 	PictureTableRemovingDateCol       = PictureTable.Col(PictureTableRemovingDateColName)
+	PictureTableNameCol               = PictureTable.Col(PictureTableNameColName)
+	PictureTableAddDateCol            = PictureTable.Col(PictureTableAddDateColName)
+	PictureTableTakenYearCol          = PictureTable.Col(PictureTableTakenYearColName)
+	PictureTableTakenMonthCol         = PictureTable.Col(PictureTableTakenMonthColName)
+	PictureTableTakenDayCol           = PictureTable.Col(PictureTableTakenDayColName)
 )
 
 type PictureRow struct {
@@ -33,6 +33,17 @@ const (
 	UserUserPreferencesTableDCNColName      = "disable_comments_notifications"
 	UserUserPreferencesTableUserIDColName   = "user_id"
 	UserUserPreferencesTableToUserIDColName = "to_user_id"
+
+	UserNotificationPreferencesTableName           = "user_notification_preferences"
+	UserNotificationPreferencesTableUserIDColName  = "user_id"
+	UserNotificationPreferencesTableEventColName   = "event"
+	UserNotificationPreferencesTableChannelColName = "channel"
+	UserNotificationPreferencesTableEnabledColName = "enabled"
+
+	UserDigestTableName          = "user_digest"
+	UserDigestTableUserIDColName = "user_id"
+	UserDigestTablePeriodColName = "period"
+	UserDigestTableSentAtColName = "sent_at"
 )
 
 var (
@@ -68,4 +79,23 @@ var (
 	UserUserPreferencesTableDCNCol      = UserUserPreferencesTable.Col(
 		UserUserPreferencesTableDCNColName,
 	)
+
+	UserNotificationPreferencesTable          = goqu.T(UserNotificationPreferencesTableName)
+	UserNotificationPreferencesTableUserIDCol = UserNotificationPreferencesTable.Col(
+		UserNotificationPreferencesTableUserIDColName,
+	)
+	UserNotificationPreferencesTableEventCol = UserNotificationPreferencesTable.Col(
+		UserNotificationPreferencesTableEventColName,
+	)
+	UserNotificationPreferencesTableChannelCol = UserNotificationPreferencesTable.Col(
+		UserNotificationPreferencesTableChannelColName,
+	)
+	UserNotificationPreferencesTableEnabledCol = UserNotificationPreferencesTable.Col(
+		UserNotificationPreferencesTableEnabledColName,
+	)
+
+	UserDigestTable          = goqu.T(UserDigestTableName)
+	UserDigestTableUserIDCol = UserDigestTable.Col(UserDigestTableUserIDColName)
+	UserDigestTablePeriodCol = UserDigestTable.Col(UserDigestTablePeriodColName)
+	UserDigestTableSentAtCol = UserDigestTable.Col(UserDigestTableSentAtColName)
 )
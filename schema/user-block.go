@@ -0,0 +1,17 @@
+package schema
+
+import "github.com/doug-martin/goqu/v9"
+
+const (
+	UserBlockTableName                 = "user_block"
+	UserBlockTableUserIDColName        = "user_id"
+	UserBlockTableBlockedUserIDColName = "blocked_user_id"
+	UserBlockTableCreatedAtColName     = "created_at"
+)
+
+var (
+	UserBlockTable                 = goqu.T(UserBlockTableName)
+	UserBlockTableUserIDCol        = UserBlockTable.Col(UserBlockTableUserIDColName)
+	UserBlockTableBlockedUserIDCol = UserBlockTable.Col(UserBlockTableBlockedUserIDColName)
+	UserBlockTableCreatedAtCol     = UserBlockTable.Col(UserBlockTableCreatedAtColName)
+)
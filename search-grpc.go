@@ -0,0 +1,134 @@
+package goautowp
+
+import (
+	"context"
+	"errors"
+	"math"
+
+	"github.com/autowp/goautowp/search"
+	"github.com/autowp/goautowp/util"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+type SearchGRPCServer struct {
+	UnimplementedSearchServer
+	index *search.Index
+}
+
+func NewSearchGRPCServer(index *search.Index) *SearchGRPCServer {
+	return &SearchGRPCServer{
+		index: index,
+	}
+}
+
+func reduceSearchKind(value SearchHit_Type) (search.Kind, bool) {
+	switch value {
+	case SearchHit_ITEM:
+		return search.KindItem, true
+	case SearchHit_PICTURE:
+		return search.KindPicture, true
+	case SearchHit_COMMENT:
+		return search.KindComment, true
+	case SearchHit_FORUM_TOPIC:
+		return search.KindForumTopic, true
+	case SearchHit_UNKNOWN:
+	}
+
+	return "", false
+}
+
+func extractSearchKind(value search.Kind) SearchHit_Type {
+	switch value {
+	case search.KindItem:
+		return SearchHit_ITEM
+	case search.KindPicture:
+		return SearchHit_PICTURE
+	case search.KindComment:
+		return SearchHit_COMMENT
+	case search.KindForumTopic:
+		return SearchHit_FORUM_TOPIC
+	}
+
+	return SearchHit_UNKNOWN
+}
+
+func (s *SearchGRPCServer) Search(ctx context.Context, in *SearchRequest) (*SearchResponse, error) {
+	kinds := make([]search.Kind, 0, len(in.GetTypes()))
+
+	for _, value := range in.GetTypes() {
+		kind, ok := reduceSearchKind(value)
+		if !ok {
+			return nil, status.Errorf(codes.InvalidArgument, "unexpected type %s", value)
+		}
+
+		kinds = append(kinds, kind)
+	}
+
+	res, err := s.index.Search(search.Request{
+		Query:    in.GetQuery(),
+		Kinds:    kinds,
+		Language: in.GetLanguage(),
+		Page:     int(in.GetPage()),
+		Limit:    int(in.GetLimit()),
+	})
+	if err != nil {
+		if errors.Is(err, search.ErrInvalidQuery) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	items := make([]*SearchHit, 0, len(res.Hits))
+	for _, hit := range res.Hits {
+		items = append(items, &SearchHit{
+			Type:     extractSearchKind(hit.Kind),
+			Id:       hit.EntityID,
+			Language: hit.Language,
+			Title:    hit.Title,
+			Snippet:  hit.Snippet,
+			Score:    hit.Score,
+		})
+	}
+
+	facets := make([]*SearchFacet, 0, len(res.Facets))
+	for _, facet := range res.Facets {
+		terms := make([]*SearchFacetTerm, 0, len(facet.Terms))
+		for _, term := range facet.Terms {
+			terms = append(terms, &SearchFacetTerm{
+				Term:  term.Term,
+				Count: int32(term.Count), //nolint: gosec
+			})
+		}
+
+		facets = append(facets, &SearchFacet{
+			Field: facet.Field,
+			Terms: terms,
+		})
+	}
+
+	total := int32(min(res.Total, math.MaxInt32)) //nolint: gosec
+
+	pages, err := util.NewCountedPaginator(total, int32(res.Limit), int32(res.Page)).GetPages(ctx) //nolint: gosec
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &SearchResponse{
+		Items:  items,
+		Facets: facets,
+		Paginator: &Pages{
+			PageCount:        pages.PageCount,
+			First:            pages.First,
+			Last:             pages.Last,
+			Current:          pages.Current,
+			FirstPageInRange: pages.FirstPageInRange,
+			LastPageInRange:  pages.LastPageInRange,
+			PagesInRange:     pages.PagesInRange,
+			TotalItemCount:   pages.TotalItemCount,
+			Next:             pages.Next,
+			Previous:         pages.Previous,
+		},
+	}, nil
+}
@@ -0,0 +1,93 @@
+package goautowp
+
+import (
+	"fmt"
+	"math/rand"
+	"testing"
+	"time"
+
+	"github.com/autowp/goautowp/config"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+func TestSearchForumTopic(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	cfg := config.LoadConfig(".")
+
+	goquDB, err := cnt.GoquDB()
+	require.NoError(t, err)
+
+	_, token := getUserWithCleanHistory(t, conn, cfg, goquDB, testUsername, testPassword)
+
+	random := rand.New(rand.NewSource(time.Now().UnixNano())) //nolint:gosec
+	word := fmt.Sprintf("searchable%d", random.Int())
+
+	topic, err := NewForumsClient(conn).CreateTopic(
+		metadata.AppendToOutgoingContext(ctx, authorizationHeader, bearerPrefix+token),
+		&APICreateTopicRequest{
+			ThemeId: 2,
+			Name:    "Topic " + word,
+			Message: "Message about " + word,
+		},
+	)
+	require.NoError(t, err)
+
+	client := NewSearchClient(conn)
+
+	res, err := client.Search(ctx, &SearchRequest{Query: word})
+	require.NoError(t, err)
+	require.Len(t, res.GetItems(), 2)
+	require.EqualValues(t, 2, res.GetPaginator().GetTotalItemCount())
+
+	types := make(map[SearchHit_Type]int64)
+	for _, hit := range res.GetItems() {
+		types[hit.GetType()] = hit.GetId()
+	}
+
+	require.Equal(t, topic.GetId(), types[SearchHit_FORUM_TOPIC])
+	require.Contains(t, types, SearchHit_COMMENT)
+
+	res, err = client.Search(ctx, &SearchRequest{Query: word, Types: []SearchHit_Type{SearchHit_FORUM_TOPIC}})
+	require.NoError(t, err)
+	require.Len(t, res.GetItems(), 1)
+	require.Equal(t, "Topic "+word, res.GetItems()[0].GetTitle())
+
+	_, err = client.Search(ctx, &SearchRequest{Query: " "})
+	require.Error(t, err)
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+func TestSearchSkipsModeratorsForum(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	cfg := config.LoadConfig(".")
+
+	goquDB, err := cnt.GoquDB()
+	require.NoError(t, err)
+
+	_, token := getUserWithCleanHistory(t, conn, cfg, goquDB, adminUsername, adminPassword)
+
+	random := rand.New(rand.NewSource(time.Now().UnixNano())) //nolint:gosec
+	word := fmt.Sprintf("secret%d", random.Int())
+
+	// theme of moderators
+	_, err = NewForumsClient(conn).CreateTopic(
+		metadata.AppendToOutgoingContext(ctx, authorizationHeader, bearerPrefix+token),
+		&APICreateTopicRequest{
+			ThemeId: 15,
+			Name:    "Topic " + word,
+			Message: "Message about " + word,
+		},
+	)
+	require.NoError(t, err)
+
+	res, err := NewSearchClient(conn).Search(ctx, &SearchRequest{Query: word})
+	require.NoError(t, err)
+	require.Empty(t, res.GetItems())
+}
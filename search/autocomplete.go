@@ -0,0 +1,256 @@
+package search
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/analysis/analyzer/custom"
+	"github.com/blevesearch/bleve/v2/analysis/tokenizer/whitespace"
+	"github.com/blevesearch/bleve/v2/mapping"
+	"github.com/blevesearch/bleve/v2/search/query"
+)
+
+const (
+	fieldNames      = "names"
+	fieldPopularity = "popularity"
+
+	foldedAnalyzer = "folded"
+
+	// candidates fetched by text relevance before ranking by popularity
+	autocompleteCandidates = 200
+	// share of relevance score added per order of magnitude of popularity
+	popularityWeight = 0.25
+
+	exactBoost  = 3
+	prefixBoost = 2
+)
+
+type autocompleteDocument struct {
+	Names      []string `json:"names"`
+	Popularity int64    `json:"popularity"`
+}
+
+// Autocomplete is an index of item names transliterated to latin, matched by prefix and with typos.
+type Autocomplete struct {
+	index bleve.Index
+}
+
+// OpenAutocomplete opens autocomplete index at path creating it when missing. Empty path means in-memory index.
+func OpenAutocomplete(path string) (*Autocomplete, error) {
+	indexMapping, err := buildAutocompleteMapping()
+	if err != nil {
+		return nil, err
+	}
+
+	if path == "" {
+		idx, err := bleve.NewMemOnly(indexMapping)
+		if err != nil {
+			return nil, err
+		}
+
+		return &Autocomplete{index: idx}, nil
+	}
+
+	idx, err := bleve.Open(path)
+	if errors.Is(err, bleve.ErrorIndexPathDoesNotExist) {
+		idx, err = bleve.New(path, indexMapping)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("open autocomplete index `%s`: %w", path, err)
+	}
+
+	return &Autocomplete{index: idx}, nil
+}
+
+func (s *Autocomplete) Close() error {
+	return s.index.Close()
+}
+
+func buildAutocompleteMapping() (*mapping.IndexMappingImpl, error) {
+	indexMapping := bleve.NewIndexMapping()
+
+	// names are folded before indexing, so only split them to words
+	err := indexMapping.AddCustomAnalyzer(foldedAnalyzer, map[string]interface{}{
+		"type":      custom.Name,
+		"tokenizer": whitespace.Name,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	namesField := bleve.NewTextFieldMapping()
+	namesField.Analyzer = foldedAnalyzer
+	namesField.IncludeTermVectors = false
+	// number of names should not affect ranking
+	namesField.SkipFreqNorm = true
+
+	popularityField := bleve.NewNumericFieldMapping()
+	popularityField.Index = false
+
+	doc := bleve.NewDocumentMapping()
+	doc.AddFieldMappingsAt(fieldNames, namesField)
+	doc.AddFieldMappingsAt(fieldPopularity, popularityField)
+
+	indexMapping.DefaultMapping = doc
+	indexMapping.DefaultAnalyzer = foldedAnalyzer
+
+	return indexMapping, nil
+}
+
+// Replace sets names of item. Each name is indexed by its words and as a single compound word,
+// so "Mercedes-Benz" is found by "mercedesbenz" and "奔驰" by "benchi".
+func (s *Autocomplete) Replace(itemID int64, names []string, popularity int64) error {
+	id := strconv.FormatInt(itemID, 10)
+
+	folded := make([]string, 0, len(names))
+
+	for _, name := range names {
+		words := Fold(name)
+		if len(words) == 0 {
+			continue
+		}
+
+		if len(words) > 1 {
+			words = append(words, strings.Join(words, ""))
+		}
+
+		folded = append(folded, strings.Join(words, " "))
+	}
+
+	if len(folded) == 0 {
+		return s.index.Delete(id)
+	}
+
+	return s.index.Index(id, autocompleteDocument{Names: folded, Popularity: popularity})
+}
+
+func (s *Autocomplete) Delete(itemID int64) error {
+	return s.index.Delete(strconv.FormatInt(itemID, 10))
+}
+
+func (s *Autocomplete) DocCount() (uint64, error) {
+	return s.index.DocCount()
+}
+
+// Suggest returns ids of items which names match text, best first.
+// Every typed word must match a word of a name exactly, by prefix for the last word or with few typos.
+func (s *Autocomplete) Suggest(text string, limit int) ([]int64, error) {
+	words := Fold(text)
+	if len(words) == 0 {
+		return nil, nil
+	}
+
+	wordQueries := make([]query.Query, 0, len(words))
+	for idx, word := range words {
+		wordQueries = append(wordQueries, autocompleteWordQuery(word, idx == len(words)-1, true))
+	}
+
+	textQuery := query.Query(bleve.NewConjunctionQuery(wordQueries...))
+
+	if len(words) > 1 {
+		// typed with spaces, while name is a single word or vice versa
+		textQuery = bleve.NewDisjunctionQuery(
+			textQuery, autocompleteWordQuery(strings.Join(words, ""), true, false),
+		)
+	}
+
+	req := bleve.NewSearchRequestOptions(textQuery, autocompleteCandidates, 0, false)
+	req.Fields = []string{fieldPopularity}
+
+	res, err := s.index.Search(req)
+	if err != nil {
+		return nil, err
+	}
+
+	type candidate struct {
+		id   int64
+		rank float64
+	}
+
+	candidates := make([]candidate, 0, len(res.Hits))
+
+	for _, hit := range res.Hits {
+		id, err := strconv.ParseInt(hit.ID, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+
+		popularity, _ := hit.Fields[fieldPopularity].(float64)
+
+		candidates = append(candidates, candidate{
+			id:   id,
+			rank: hit.Score * (1 + popularityWeight*math.Log10(1+max(popularity, 0))),
+		})
+	}
+
+	slices.SortStableFunc(candidates, func(a, b candidate) int {
+		switch {
+		case a.rank > b.rank:
+			return -1
+		case a.rank < b.rank:
+			return 1
+		}
+
+		return 0
+	})
+
+	if limit > 0 && len(candidates) > limit {
+		candidates = candidates[:limit]
+	}
+
+	ids := make([]int64, 0, len(candidates))
+	for _, c := range candidates {
+		ids = append(ids, c.id)
+	}
+
+	return ids, nil
+}
+
+func autocompleteWordQuery(word string, prefix bool, typos bool) query.Query {
+	exact := bleve.NewTermQuery(word)
+	exact.SetField(fieldNames)
+	exact.SetBoost(exactBoost)
+
+	queries := []query.Query{exact}
+
+	if prefix {
+		prefixQuery := bleve.NewPrefixQuery(word)
+		prefixQuery.SetField(fieldNames)
+		prefixQuery.SetBoost(prefixBoost)
+
+		queries = append(queries, prefixQuery)
+	}
+
+	if fuzziness := typoTolerance(word); typos && fuzziness > 0 {
+		fuzzy := bleve.NewFuzzyQuery(word)
+		fuzzy.SetField(fieldNames)
+		fuzzy.SetFuzziness(fuzziness)
+
+		queries = append(queries, fuzzy)
+	}
+
+	return bleve.NewDisjunctionQuery(queries...)
+}
+
+// typoTolerance is a number of allowed edits of word, short words must be typed exactly.
+func typoTolerance(word string) int {
+	const (
+		exactMaxLength   = 3
+		oneTypoMaxLength = 6
+	)
+
+	switch length := len(word); {
+	case length <= exactMaxLength:
+		return 0
+	case length <= oneTypoMaxLength:
+		return 1
+	}
+
+	return 2 //nolint: mnd
+}
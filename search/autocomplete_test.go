@@ -0,0 +1,96 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func TestFold(t *testing.T) {
+	t.Parallel()
+
+	require.Equal(t, []string{"moskvich", "412"}, Fold("Москвич-412"))
+	require.Equal(t, []string{"moskvich"}, Fold("Moskvich"))
+	require.Equal(t, Fold("Honda"), Fold("Хонда"))
+	require.Equal(t, []string{"skoda", "citroen"}, Fold("Škoda, Citroën"))
+	require.Equal(t, []string{"ben", "chi"}, Fold("奔驰"))
+	require.Empty(t, Fold(" - "))
+}
+
+func newTestAutocomplete(t *testing.T) *Autocomplete {
+	t.Helper()
+
+	idx, err := OpenAutocomplete("")
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		require.NoError(t, idx.Close())
+	})
+
+	require.NoError(t, idx.Replace(1, []string{"Moskvich", "Москвич"}, 100))
+	require.NoError(t, idx.Replace(2, []string{"Mercedes-Benz", "Мерседес-Бенц", "奔驰"}, 5000))
+	require.NoError(t, idx.Replace(3, []string{"Mercedes-Benz W123"}, 50))
+	require.NoError(t, idx.Replace(4, []string{"Lada"}, 300))
+	require.NoError(t, idx.Replace(5, []string{"Lancia"}, 200))
+
+	return idx
+}
+
+func TestAutocompleteSuggest(t *testing.T) {
+	t.Parallel()
+
+	idx := newTestAutocomplete(t)
+
+	tests := []struct {
+		query    string
+		expected []int64
+	}{
+		// transliteration
+		{query: "Москвич", expected: []int64{1}},
+		{query: "moskvich", expected: []int64{1}},
+		// typo
+		{query: "Moskvitch", expected: []int64{1}},
+		{query: "Mercedes Bens", expected: []int64{2, 3}},
+		// prefix of the last word
+		{query: "Mercedes Benz W1", expected: []int64{3}},
+		// pinyin
+		{query: "benchi", expected: []int64{2}},
+		// compound word typed separately
+		{query: "mercedesbenz", expected: []int64{2, 3}},
+		// short words are prefixes only, more popular first
+		{query: "la", expected: []int64{4, 5}},
+		{query: "xyz", expected: []int64{}},
+	}
+
+	for _, test := range tests {
+		ids, err := idx.Suggest(test.query, 10)
+		require.NoError(t, err, test.query)
+		require.Equal(t, test.expected, ids, test.query)
+	}
+}
+
+func TestAutocompleteReplace(t *testing.T) {
+	t.Parallel()
+
+	idx := newTestAutocomplete(t)
+
+	require.NoError(t, idx.Replace(4, []string{"VAZ"}, 300))
+
+	ids, err := idx.Suggest("lada", 10)
+	require.NoError(t, err)
+	require.Empty(t, ids)
+
+	ids, err = idx.Suggest("ваз", 10)
+	require.NoError(t, err)
+	require.Equal(t, []int64{4}, ids)
+
+	require.NoError(t, idx.Delete(4))
+
+	ids, err = idx.Suggest("vaz", 10)
+	require.NoError(t, err)
+	require.Empty(t, ids)
+
+	ids, err = idx.Suggest("mercedes", 1)
+	require.NoError(t, err)
+	require.Equal(t, []int64{2}, ids)
+}
@@ -0,0 +1,33 @@
+package search
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/mozillazg/go-unidecode"
+)
+
+// foldReplacer evens out common differences of latin transcriptions,
+// so "Хонда" and "Honda" or "Фольксваген" and "Volkswagen" are closer.
+var foldReplacer = strings.NewReplacer(
+	"'", "",
+	"`", "",
+	"kh", "h",
+	"iu", "yu",
+	"ia", "ya",
+	"io", "yo",
+	"w", "v",
+	"x", "ks",
+	"ph", "f",
+)
+
+// Fold transliterates text of any script to lowercase latin and splits it to words.
+// Chinese is transliterated to pinyin syllables.
+func Fold(text string) []string {
+	text = strings.ToLower(unidecode.Unidecode(text))
+	text = foldReplacer.Replace(text)
+
+	return strings.FieldsFunc(text, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
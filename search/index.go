@@ -0,0 +1,408 @@
+package search
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/blevesearch/bleve/v2"
+	"github.com/blevesearch/bleve/v2/analysis/analyzer/standard"
+	"github.com/blevesearch/bleve/v2/analysis/lang/cjk"
+	"github.com/blevesearch/bleve/v2/analysis/lang/en"
+	"github.com/blevesearch/bleve/v2/analysis/lang/es"
+	"github.com/blevesearch/bleve/v2/analysis/lang/fr"
+	"github.com/blevesearch/bleve/v2/analysis/lang/it"
+	"github.com/blevesearch/bleve/v2/analysis/lang/pt"
+	"github.com/blevesearch/bleve/v2/analysis/lang/ru"
+	"github.com/blevesearch/bleve/v2/mapping"
+	"github.com/blevesearch/bleve/v2/search/query"
+)
+
+var ErrInvalidQuery = errors.New("invalid search query")
+
+type Kind string
+
+const (
+	KindItem       Kind = "item"
+	KindPicture    Kind = "picture"
+	KindComment    Kind = "comment"
+	KindForumTopic Kind = "forum_topic"
+)
+
+const (
+	fieldKind     = "kind"
+	fieldRef      = "ref"
+	fieldEntityID = "entity_id"
+	fieldLanguage = "language"
+	fieldTitle    = "title"
+	fieldBody     = "body"
+	// same texts processed by standard analyzer, used when query language is unknown
+	fieldPlainTitle = "title_plain"
+	fieldPlainBody  = "body_plain"
+	fieldDate       = "date"
+
+	defaultLimit  = 20
+	maxLimit      = 100
+	maxFacetTerms = 10
+	titleBoost    = 2
+	// upper bound of documents produced by single entity, one per language
+	maxEntityDocuments = 100
+)
+
+// languageAnalyzers maps content languages to bleve analyzers, others are processed by standard analyzer.
+var languageAnalyzers = map[string]string{
+	"en":    en.AnalyzerName,
+	"ru":    ru.AnalyzerName,
+	"fr":    fr.AnalyzerName,
+	"es":    es.AnalyzerName,
+	"it":    it.AnalyzerName,
+	"pt-br": pt.AnalyzerName,
+	"zh":    cjk.AnalyzerName,
+}
+
+// Document is a unit of search index. Items and other multilingual entities produce a document per language.
+type Document struct {
+	Kind     Kind      `json:"kind"`
+	Ref      string    `json:"ref"`
+	EntityID int64     `json:"entity_id"`
+	Language string    `json:"language"`
+	Title    string    `json:"title"`
+	Body     string    `json:"body"`
+	Date     time.Time `json:"date"`
+}
+
+// BleveType selects document mapping, so text fields are analyzed by rules of document language.
+func (s Document) BleveType() string {
+	if _, ok := languageAnalyzers[s.Language]; ok {
+		return s.Language
+	}
+
+	return ""
+}
+
+func (s Document) id() string {
+	return s.Ref + "/" + s.Language
+}
+
+func ref(kind Kind, entityID int64) string {
+	return string(kind) + "/" + strconv.FormatInt(entityID, 10)
+}
+
+type Request struct {
+	Query    string
+	Kinds    []Kind
+	Language string
+	Page     int
+	Limit    int
+}
+
+type Hit struct {
+	Kind     Kind
+	EntityID int64
+	Language string
+	Title    string
+	Snippet  string
+	Score    float64
+}
+
+type FacetTerm struct {
+	Term  string
+	Count int
+}
+
+type Facet struct {
+	Field string
+	Terms []FacetTerm
+}
+
+type Result struct {
+	Hits   []Hit
+	Facets []Facet
+	Total  uint64
+	Page   int
+	Limit  int
+}
+
+// Index is an embedded full-text index of catalogue texts.
+type Index struct {
+	index bleve.Index
+}
+
+// Open opens index at path creating it when missing. Empty path means in-memory index.
+func Open(path string) (*Index, error) {
+	indexMapping := buildMapping()
+
+	if path == "" {
+		idx, err := bleve.NewMemOnly(indexMapping)
+		if err != nil {
+			return nil, err
+		}
+
+		return &Index{index: idx}, nil
+	}
+
+	idx, err := bleve.Open(path)
+	if errors.Is(err, bleve.ErrorIndexPathDoesNotExist) {
+		idx, err = bleve.New(path, indexMapping)
+	}
+
+	if err != nil {
+		return nil, fmt.Errorf("open search index `%s`: %w", path, err)
+	}
+
+	return &Index{index: idx}, nil
+}
+
+// Remove deletes index files at path.
+func Remove(path string) error {
+	if path == "" {
+		return nil
+	}
+
+	return os.RemoveAll(path)
+}
+
+func (s *Index) Close() error {
+	return s.index.Close()
+}
+
+func buildMapping() *mapping.IndexMappingImpl {
+	keywordField := bleve.NewKeywordFieldMapping()
+	keywordField.IncludeInAll = false
+
+	entityIDField := bleve.NewNumericFieldMapping()
+	entityIDField.IncludeInAll = false
+
+	dateField := bleve.NewDateTimeFieldMapping()
+	dateField.IncludeInAll = false
+
+	textField := func(name string, analyzer string) *mapping.FieldMapping {
+		field := bleve.NewTextFieldMapping()
+		field.Name = name
+		field.Analyzer = analyzer
+
+		return field
+	}
+
+	documentMapping := func(analyzer string) *mapping.DocumentMapping {
+		doc := bleve.NewDocumentMapping()
+		doc.AddFieldMappingsAt(fieldKind, keywordField)
+		doc.AddFieldMappingsAt(fieldRef, keywordField)
+		doc.AddFieldMappingsAt(fieldEntityID, entityIDField)
+		doc.AddFieldMappingsAt(fieldLanguage, keywordField)
+		doc.AddFieldMappingsAt(fieldTitle,
+			textField(fieldTitle, analyzer), textField(fieldPlainTitle, standard.Name))
+		doc.AddFieldMappingsAt(fieldBody,
+			textField(fieldBody, analyzer), textField(fieldPlainBody, standard.Name))
+		doc.AddFieldMappingsAt(fieldDate, dateField)
+
+		return doc
+	}
+
+	indexMapping := bleve.NewIndexMapping()
+	indexMapping.DefaultAnalyzer = standard.Name
+	indexMapping.DefaultMapping = documentMapping(standard.Name)
+
+	for language, analyzer := range languageAnalyzers {
+		indexMapping.AddDocumentMapping(language, documentMapping(analyzer))
+	}
+
+	return indexMapping
+}
+
+// Replace swaps all documents of entity with given ones.
+func (s *Index) Replace(kind Kind, entityID int64, docs []Document) error {
+	ids, err := s.documentIDs(kind, entityID)
+	if err != nil {
+		return err
+	}
+
+	batch := s.index.NewBatch()
+
+	for _, id := range ids {
+		batch.Delete(id)
+	}
+
+	for _, doc := range docs {
+		doc.Kind = kind
+		doc.EntityID = entityID
+		doc.Ref = ref(kind, entityID)
+
+		err = batch.Index(doc.id(), doc)
+		if err != nil {
+			return err
+		}
+	}
+
+	return s.index.Batch(batch)
+}
+
+// Delete removes all documents of entity.
+func (s *Index) Delete(kind Kind, entityID int64) error {
+	return s.Replace(kind, entityID, nil)
+}
+
+func (s *Index) documentIDs(kind Kind, entityID int64) ([]string, error) {
+	refQuery := bleve.NewTermQuery(ref(kind, entityID))
+	refQuery.SetField(fieldRef)
+
+	res, err := s.index.Search(bleve.NewSearchRequestOptions(refQuery, maxEntityDocuments, 0, false))
+	if err != nil {
+		return nil, err
+	}
+
+	ids := make([]string, 0, len(res.Hits))
+
+	for _, hit := range res.Hits {
+		ids = append(ids, hit.ID)
+	}
+
+	return ids, nil
+}
+
+// DocCount returns number of indexed documents.
+func (s *Index) DocCount() (uint64, error) {
+	return s.index.DocCount()
+}
+
+// Search runs query over titles and bodies with optional filtering by kinds and language.
+// Results are faceted by kind and language and paginated.
+func (s *Index) Search(req Request) (*Result, error) {
+	text := strings.TrimSpace(req.Query)
+	if text == "" {
+		return nil, fmt.Errorf("%w: empty query", ErrInvalidQuery)
+	}
+
+	limit := req.Limit
+	if limit <= 0 {
+		limit = defaultLimit
+	}
+
+	limit = min(limit, maxLimit)
+
+	page := max(req.Page, 1)
+
+	conjuncts := []query.Query{textQuery(text, req.Language)}
+
+	if len(req.Kinds) > 0 {
+		kinds := make([]query.Query, 0, len(req.Kinds))
+
+		for _, kind := range req.Kinds {
+			kindQuery := bleve.NewTermQuery(string(kind))
+			kindQuery.SetField(fieldKind)
+			kinds = append(kinds, kindQuery)
+		}
+
+		conjuncts = append(conjuncts, bleve.NewDisjunctionQuery(kinds...))
+	}
+
+	if req.Language != "" {
+		languageQuery := bleve.NewTermQuery(req.Language)
+		languageQuery.SetField(fieldLanguage)
+		conjuncts = append(conjuncts, languageQuery)
+	}
+
+	searchRequest := bleve.NewSearchRequestOptions(
+		bleve.NewConjunctionQuery(conjuncts...), limit, (page-1)*limit, false,
+	)
+	searchRequest.Fields = []string{fieldKind, fieldEntityID, fieldLanguage, fieldTitle}
+	searchRequest.Highlight = bleve.NewHighlightWithStyle("html")
+	searchRequest.Highlight.AddField(fieldBody)
+	searchRequest.Highlight.AddField(fieldPlainBody)
+	searchRequest.AddFacet(fieldKind, bleve.NewFacetRequest(fieldKind, maxFacetTerms))
+	searchRequest.AddFacet(fieldLanguage, bleve.NewFacetRequest(fieldLanguage, maxFacetTerms))
+
+	res, err := s.index.Search(searchRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	result := Result{
+		Hits:   make([]Hit, 0, len(res.Hits)),
+		Facets: make([]Facet, 0, len(res.Facets)),
+		Total:  res.Total,
+		Page:   page,
+		Limit:  limit,
+	}
+
+	for _, hit := range res.Hits {
+		item := Hit{Score: hit.Score}
+
+		if kind, ok := hit.Fields[fieldKind].(string); ok {
+			item.Kind = Kind(kind)
+		}
+
+		if entityID, ok := hit.Fields[fieldEntityID].(float64); ok {
+			item.EntityID = int64(entityID)
+		}
+
+		item.Language, _ = hit.Fields[fieldLanguage].(string)
+		item.Title, _ = hit.Fields[fieldTitle].(string)
+
+		for _, field := range []string{fieldBody, fieldPlainBody} {
+			if fragments := hit.Fragments[field]; len(fragments) > 0 {
+				item.Snippet = fragments[0]
+
+				break
+			}
+		}
+
+		result.Hits = append(result.Hits, item)
+	}
+
+	for _, field := range []string{fieldKind, fieldLanguage} {
+		facetResult, ok := res.Facets[field]
+		if !ok {
+			continue
+		}
+
+		facet := Facet{Field: field, Terms: make([]FacetTerm, 0)}
+
+		for _, term := range facetResult.Terms.Terms() {
+			facet.Terms = append(facet.Terms, FacetTerm{Term: term.Term, Count: term.Count})
+		}
+
+		result.Facets = append(result.Facets, facet)
+	}
+
+	return &result, nil
+}
+
+// textQuery matches words in any order, titles are ranked higher than bodies.
+// When language is known query is also analyzed by its rules, so other word forms are matched.
+func textQuery(text string, language string) query.Query {
+	type field struct {
+		name     string
+		analyzer string
+		boost    float64
+	}
+
+	fields := []field{
+		{name: fieldPlainTitle, analyzer: standard.Name, boost: titleBoost},
+		{name: fieldPlainBody, analyzer: standard.Name, boost: 1},
+	}
+
+	if analyzer, ok := languageAnalyzers[language]; ok {
+		fields = append(fields,
+			field{name: fieldTitle, analyzer: analyzer, boost: titleBoost},
+			field{name: fieldBody, analyzer: analyzer, boost: 1},
+		)
+	}
+
+	queries := make([]query.Query, 0, len(fields))
+
+	for _, field := range fields {
+		match := bleve.NewMatchQuery(text)
+		match.SetField(field.name)
+		match.Analyzer = field.analyzer
+		match.SetBoost(field.boost)
+		match.SetOperator(query.MatchQueryOperatorAnd)
+
+		queries = append(queries, match)
+	}
+
+	return bleve.NewDisjunctionQuery(queries...)
+}
@@ -0,0 +1,127 @@
+package search
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/require"
+)
+
+func newTestIndex(t *testing.T) *Index {
+	t.Helper()
+
+	idx, err := Open("")
+	require.NoError(t, err)
+
+	t.Cleanup(func() {
+		require.NoError(t, idx.Close())
+	})
+
+	return idx
+}
+
+func TestSearchStemmedLanguage(t *testing.T) {
+	t.Parallel()
+
+	idx := newTestIndex(t)
+
+	err := idx.Replace(KindItem, 1, []Document{
+		{Language: "en", Title: "Volkswagen Beetle", Body: "Small cars built in Wolfsburg"},
+		{Language: "ru", Title: "Фольксваген Жук", Body: "Небольшие машины из Вольфсбурга"},
+	})
+	require.NoError(t, err)
+
+	res, err := idx.Search(Request{Query: "car", Language: "en"})
+	require.NoError(t, err)
+	require.EqualValues(t, 1, res.Total)
+	require.Equal(t, KindItem, res.Hits[0].Kind)
+	require.EqualValues(t, 1, res.Hits[0].EntityID)
+	require.Equal(t, "en", res.Hits[0].Language)
+	require.Equal(t, "Volkswagen Beetle", res.Hits[0].Title)
+	require.Contains(t, res.Hits[0].Snippet, "<mark>cars</mark>")
+
+	res, err = idx.Search(Request{Query: "машина", Language: "ru"})
+	require.NoError(t, err)
+	require.EqualValues(t, 1, res.Total)
+	require.Equal(t, "ru", res.Hits[0].Language)
+}
+
+func TestSearchFacetsAndKinds(t *testing.T) {
+	t.Parallel()
+
+	idx := newTestIndex(t)
+
+	require.NoError(t, idx.Replace(KindItem, 1, []Document{{Language: "en", Title: "Lancia Stratos"}}))
+	require.NoError(t, idx.Replace(KindPicture, 2, []Document{{Title: "Lancia Stratos HF"}}))
+	require.NoError(t, idx.Replace(KindComment, 3, []Document{{Body: "stratos is a rally legend"}}))
+	require.NoError(t, idx.Replace(KindForumTopic, 4, []Document{{Title: "Ferrari Testarossa"}}))
+
+	res, err := idx.Search(Request{Query: "stratos"})
+	require.NoError(t, err)
+	require.EqualValues(t, 3, res.Total)
+
+	facets := make(map[string]map[string]int)
+
+	for _, facet := range res.Facets {
+		facets[facet.Field] = make(map[string]int)
+		for _, term := range facet.Terms {
+			facets[facet.Field][term.Term] = term.Count
+		}
+	}
+
+	require.Equal(t, map[string]int{"item": 1, "picture": 1, "comment": 1}, facets[fieldKind])
+
+	res, err = idx.Search(Request{Query: "stratos", Kinds: []Kind{KindPicture, KindComment}})
+	require.NoError(t, err)
+	require.EqualValues(t, 2, res.Total)
+
+	for _, hit := range res.Hits {
+		require.NotEqual(t, KindItem, hit.Kind)
+	}
+}
+
+func TestSearchReplaceAndDelete(t *testing.T) {
+	t.Parallel()
+
+	idx := newTestIndex(t)
+
+	require.NoError(t, idx.Replace(KindItem, 1, []Document{
+		{Language: "en", Title: "Trabant 601"},
+		{Language: "de", Title: "Trabant 601"},
+	}))
+
+	res, err := idx.Search(Request{Query: "trabant"})
+	require.NoError(t, err)
+	require.EqualValues(t, 2, res.Total)
+
+	require.NoError(t, idx.Replace(KindItem, 1, []Document{{Language: "en", Title: "Trabant P601"}}))
+
+	count, err := idx.DocCount()
+	require.NoError(t, err)
+	require.EqualValues(t, 1, count)
+
+	require.NoError(t, idx.Delete(KindItem, 1))
+
+	res, err = idx.Search(Request{Query: "trabant"})
+	require.NoError(t, err)
+	require.Zero(t, res.Total)
+}
+
+func TestSearchPagination(t *testing.T) {
+	t.Parallel()
+
+	idx := newTestIndex(t)
+
+	for i := range int64(5) {
+		require.NoError(t, idx.Replace(KindComment, i+1, []Document{{Body: "moskvich"}}))
+	}
+
+	res, err := idx.Search(Request{Query: "moskvich", Page: 3, Limit: 2})
+	require.NoError(t, err)
+	require.EqualValues(t, 5, res.Total)
+	require.Len(t, res.Hits, 1)
+	require.Equal(t, 3, res.Page)
+	require.Equal(t, 2, res.Limit)
+
+	_, err = idx.Search(Request{Query: "  "})
+	require.ErrorIs(t, err, ErrInvalidQuery)
+}
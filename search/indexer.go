@@ -0,0 +1,547 @@
+package search
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/autowp/goautowp/markup"
+	"github.com/autowp/goautowp/schema"
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	reindexBatchSize = 500
+	// popularity of item changes with pictures of its descendants
+	popularityRefreshInterval = 24 * time.Hour
+	// forums_topics.status of removed topics
+	topicStatusDeleted = "deleted"
+)
+
+// Indexer feeds search index from the database.
+// Index is local to the replica, so refreshes are broadcasted over rabbitMQ exchange to other replicas.
+type Indexer struct {
+	db           *goqu.Database
+	index        *Index
+	autocomplete *Autocomplete
+	publisher    *refreshPublisher
+}
+
+func NewIndexer(
+	db *goqu.Database, index *Index, autocomplete *Autocomplete, rabbitMQ string, exchange string,
+) *Indexer {
+	return &Indexer{
+		db:           db,
+		index:        index,
+		autocomplete: autocomplete,
+		publisher:    newRefreshPublisher(rabbitMQ, exchange),
+	}
+}
+
+// Close drops connection used to broadcast refreshes.
+func (s *Indexer) Close() {
+	s.publisher.Close()
+}
+
+func (s *Indexer) Index() *Index {
+	return s.index
+}
+
+func (s *Indexer) Autocomplete() *Autocomplete {
+	return s.autocomplete
+}
+
+// IndexItem indexes item name and texts in every language it is described in.
+func (s *Indexer) IndexItem(ctx context.Context, itemID int64) error {
+	err := s.indexItemsAutocomplete(ctx, []int64{itemID})
+	if err != nil {
+		return err
+	}
+
+	textTable := schema.TextstorageTextTable.As("text")
+	fullTextTable := schema.TextstorageTextTable.As("full_text")
+
+	var rows []struct {
+		Language string         `db:"language"`
+		Name     sql.NullString `db:"name"`
+		Text     sql.NullString `db:"text"`
+		FullText sql.NullString `db:"full_text"`
+	}
+
+	err = s.db.Select(
+		schema.ItemLanguageTableLanguageCol,
+		schema.ItemLanguageTableNameCol,
+		textTable.Col(schema.TextstorageTextTableTextColName).As("text"),
+		fullTextTable.Col(schema.TextstorageTextTableTextColName).As("full_text"),
+	).
+		From(schema.ItemLanguageTable).
+		LeftJoin(textTable, goqu.On(
+			schema.ItemLanguageTableTextIDCol.Eq(textTable.Col(schema.TextstorageTextTableIDColName)),
+		)).
+		LeftJoin(fullTextTable, goqu.On(
+			schema.ItemLanguageTableFullTextIDCol.Eq(fullTextTable.Col(schema.TextstorageTextTableIDColName)),
+		)).
+		Where(schema.ItemLanguageTableItemIDCol.Eq(itemID)).
+		ScanStructsContext(ctx, &rows)
+	if err != nil {
+		return err
+	}
+
+	docs := make([]Document, 0, len(rows))
+
+	for _, row := range rows {
+		body := strings.TrimSpace(strings.Join([]string{row.Text.String, row.FullText.String}, "\n"))
+		if row.Name.String == "" && body == "" {
+			continue
+		}
+
+		docs = append(docs, Document{Language: row.Language, Title: row.Name.String, Body: body})
+	}
+
+	return s.index.Replace(KindItem, itemID, docs)
+}
+
+// indexItemsAutocomplete indexes all names of items including brand aliases,
+// number of accepted pictures of item and its descendants is used as popularity.
+func (s *Indexer) indexItemsAutocomplete(ctx context.Context, itemIDs []int64) error {
+	var nameRows []struct {
+		ItemID int64  `db:"item_id"`
+		Name   string `db:"name"`
+	}
+
+	err := s.db.Select(schema.ItemTableIDCol.As("item_id"), schema.ItemTableNameCol.As("name")).
+		From(schema.ItemTable).
+		Where(schema.ItemTableIDCol.In(itemIDs)).
+		Union(
+			s.db.Select(schema.ItemLanguageTableItemIDCol, schema.ItemLanguageTableNameCol).
+				From(schema.ItemLanguageTable).
+				Where(
+					schema.ItemLanguageTableItemIDCol.In(itemIDs),
+					schema.ItemLanguageTableNameCol.IsNotNull(),
+				),
+		).
+		Union(
+			s.db.Select(schema.BrandAliasTableItemIDCol, schema.BrandAliasTableNameCol).
+				From(schema.BrandAliasTable).
+				Where(schema.BrandAliasTableItemIDCol.In(itemIDs)),
+		).
+		ScanStructsContext(ctx, &nameRows)
+	if err != nil {
+		return err
+	}
+
+	names := make(map[int64][]string, len(itemIDs))
+	for _, row := range nameRows {
+		names[row.ItemID] = append(names[row.ItemID], row.Name)
+	}
+
+	var popularityRows []struct {
+		ItemID     int64 `db:"item_id"`
+		Popularity int64 `db:"popularity"`
+	}
+
+	err = s.db.Select(
+		schema.ItemParentCacheTableParentIDCol.As("item_id"),
+		goqu.COUNT(goqu.DISTINCT(schema.PictureItemTablePictureIDCol)).As("popularity"),
+	).
+		From(schema.ItemParentCacheTable).
+		Join(schema.PictureItemTable, goqu.On(
+			schema.ItemParentCacheTableItemIDCol.Eq(schema.PictureItemTableItemIDCol),
+		)).
+		Join(schema.PictureTable, goqu.On(schema.PictureItemTablePictureIDCol.Eq(schema.PictureTableIDCol))).
+		Where(
+			schema.ItemParentCacheTableParentIDCol.In(itemIDs),
+			schema.PictureTableStatusCol.Eq(schema.PictureStatusAccepted),
+		).
+		GroupBy(schema.ItemParentCacheTableParentIDCol).
+		ScanStructsContext(ctx, &popularityRows)
+	if err != nil {
+		return err
+	}
+
+	popularity := make(map[int64]int64, len(popularityRows))
+	for _, row := range popularityRows {
+		popularity[row.ItemID] = row.Popularity
+	}
+
+	for _, itemID := range itemIDs {
+		if len(names[itemID]) == 0 {
+			err = s.autocomplete.Delete(itemID)
+		} else {
+			err = s.autocomplete.Replace(itemID, names[itemID], popularity[itemID])
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// RefreshAutocomplete indexes names and popularity of all items in the local autocomplete index again.
+func (s *Indexer) RefreshAutocomplete(ctx context.Context) error {
+	var lastID int64
+
+	for {
+		var ids []int64
+
+		err := s.db.Select(schema.ItemTableIDCol).
+			From(schema.ItemTable).
+			Where(schema.ItemTableIDCol.Gt(lastID)).
+			Order(schema.ItemTableIDCol.Asc()).
+			Limit(reindexBatchSize).
+			ScanValsContext(ctx, &ids)
+		if err != nil {
+			return err
+		}
+
+		if len(ids) == 0 {
+			return nil
+		}
+
+		err = s.indexItemsAutocomplete(ctx, ids)
+		if err != nil {
+			return err
+		}
+
+		lastID = ids[len(ids)-1]
+	}
+}
+
+// RefreshAutocompletePeriodically keeps popularity of items up to date, as it changes with pictures of
+// descendants, which are not refreshed as the item itself. Every replica maintains its own index.
+func (s *Indexer) RefreshAutocompletePeriodically(ctx context.Context, quitChan chan bool) {
+	ticker := time.NewTicker(popularityRefreshInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ticker.C:
+			err := s.RefreshAutocomplete(ctx)
+			if err != nil {
+				logrus.Errorf("search: failed to refresh autocomplete: %s", err.Error())
+			}
+
+		case <-quitChan:
+			return
+		}
+	}
+}
+
+// IndexPicture indexes picture and its comments, which are searchable only while picture is accepted.
+func (s *Indexer) IndexPicture(ctx context.Context, pictureID int64) error {
+	err := s.indexPicture(ctx, pictureID)
+	if err != nil {
+		return err
+	}
+
+	return s.indexComments(ctx, schema.CommentMessageTypeIDPictures, pictureID)
+}
+
+// indexPicture indexes name of accepted picture, names of depicted items are used when picture is not named.
+func (s *Indexer) indexPicture(ctx context.Context, pictureID int64) error {
+	var row struct {
+		Name    sql.NullString       `db:"name"`
+		Status  schema.PictureStatus `db:"status"`
+		AddDate time.Time            `db:"add_date"`
+	}
+
+	success, err := s.db.Select(schema.PictureTableNameCol, schema.PictureTableStatusCol, schema.PictureTableAddDateCol).
+		From(schema.PictureTable).
+		Where(schema.PictureTableIDCol.Eq(pictureID)).
+		ScanStructContext(ctx, &row)
+	if err != nil {
+		return err
+	}
+
+	if !success || row.Status != schema.PictureStatusAccepted {
+		return s.index.Delete(KindPicture, pictureID)
+	}
+
+	title := row.Name.String
+	if title == "" {
+		var names []string
+
+		err = s.db.Select(schema.ItemTableNameCol).
+			From(schema.PictureItemTable).
+			Join(schema.ItemTable, goqu.On(schema.PictureItemTableItemIDCol.Eq(schema.ItemTableIDCol))).
+			Where(schema.PictureItemTablePictureIDCol.Eq(pictureID)).
+			Order(schema.ItemTableNameCol.Asc()).
+			ScanValsContext(ctx, &names)
+		if err != nil {
+			return err
+		}
+
+		title = strings.Join(names, ", ")
+	}
+
+	if title == "" {
+		return s.index.Delete(KindPicture, pictureID)
+	}
+
+	return s.index.Replace(KindPicture, pictureID, []Document{{Title: title, Date: row.AddDate}})
+}
+
+// IndexComment indexes message of comment, deleted comments and comments of hidden entities
+// are removed from index.
+func (s *Indexer) IndexComment(ctx context.Context, commentID int64) error {
+	var row struct {
+		TypeID   schema.CommentMessageType `db:"type_id"`
+		ItemID   int64                     `db:"item_id"`
+		Message  string                    `db:"message"`
+		Deleted  bool                      `db:"deleted"`
+		Datetime time.Time                 `db:"datetime"`
+	}
+
+	success, err := s.db.Select(
+		schema.CommentMessageTableTypeIDCol,
+		schema.CommentMessageTableItemIDCol,
+		schema.CommentMessageTableMessageCol,
+		schema.CommentMessageTableDeletedCol,
+		schema.CommentMessageTableDatetimeCol,
+	).
+		From(schema.CommentMessageTable).
+		Where(schema.CommentMessageTableIDCol.Eq(commentID)).
+		ScanStructContext(ctx, &row)
+	if err != nil {
+		return err
+	}
+
+	if !success || row.Deleted || strings.TrimSpace(row.Message) == "" {
+		return s.index.Delete(KindComment, commentID)
+	}
+
+	visible, err := s.commentsVisible(ctx, row.TypeID, row.ItemID)
+	if err != nil {
+		return err
+	}
+
+	if !visible {
+		return s.index.Delete(KindComment, commentID)
+	}
+
+	return s.index.Replace(KindComment, commentID, []Document{{Body: markup.PlainText(row.Message), Date: row.Datetime}})
+}
+
+// commentsVisible returns false for comments of not accepted pictures and of deleted or moderators-only topics,
+// same as forums and pictures hide them from public.
+func (s *Indexer) commentsVisible(ctx context.Context, typeID schema.CommentMessageType, itemID int64) (bool, error) {
+	var sqSelect *goqu.SelectDataset
+
+	switch typeID { //nolint: exhaustive
+	case schema.CommentMessageTypeIDPictures:
+		sqSelect = s.db.Select(goqu.V(true)).
+			From(schema.PictureTable).
+			Where(
+				schema.PictureTableIDCol.Eq(itemID),
+				schema.PictureTableStatusCol.Eq(schema.PictureStatusAccepted),
+			)
+	case schema.CommentMessageTypeIDForums:
+		sqSelect = s.publicTopicsSelect().Where(schema.ForumsTopicsTableIDCol.Eq(itemID))
+	default:
+		return true, nil
+	}
+
+	var visible bool
+
+	success, err := sqSelect.ScanValContext(ctx, &visible)
+	if err != nil {
+		return false, err
+	}
+
+	return success && visible, nil
+}
+
+func (s *Indexer) publicTopicsSelect() *goqu.SelectDataset {
+	return s.db.Select(goqu.V(true)).
+		From(schema.ForumsTopicsTable).
+		Join(schema.ForumsThemesTable, goqu.On(schema.ForumsTopicsTableThemeIDCol.Eq(schema.ForumsThemesTableIDCol))).
+		Where(
+			schema.ForumsTopicsTableStatusCol.Neq(topicStatusDeleted),
+			schema.ForumsThemesTableIsModeratorCol.IsFalse(),
+		)
+}
+
+// indexComments refreshes comments of entity after its visibility could change.
+func (s *Indexer) indexComments(ctx context.Context, typeID schema.CommentMessageType, itemID int64) error {
+	var ids []int64
+
+	err := s.db.Select(schema.CommentMessageTableIDCol).
+		From(schema.CommentMessageTable).
+		Where(
+			schema.CommentMessageTableTypeIDCol.Eq(typeID),
+			schema.CommentMessageTableItemIDCol.Eq(itemID),
+		).
+		ScanValsContext(ctx, &ids)
+	if err != nil {
+		return err
+	}
+
+	visible, err := s.commentsVisible(ctx, typeID, itemID)
+	if err != nil {
+		return err
+	}
+
+	for _, id := range ids {
+		if visible {
+			err = s.IndexComment(ctx, id)
+		} else {
+			err = s.index.Delete(KindComment, id)
+		}
+
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// IndexForumTopic indexes forum topic and its messages.
+func (s *Indexer) IndexForumTopic(ctx context.Context, topicID int64) error {
+	err := s.indexForumTopic(ctx, topicID)
+	if err != nil {
+		return err
+	}
+
+	return s.indexComments(ctx, schema.CommentMessageTypeIDForums, topicID)
+}
+
+// indexForumTopic indexes name of forum topic, deleted and moderators-only topics are removed from index.
+func (s *Indexer) indexForumTopic(ctx context.Context, topicID int64) error {
+	var row struct {
+		Name        string    `db:"name"`
+		Status      string    `db:"status"`
+		AddDatetime time.Time `db:"add_datetime"`
+		IsModerator bool      `db:"is_moderator"`
+	}
+
+	success, err := s.db.Select(
+		schema.ForumsTopicsTableNameCol,
+		schema.ForumsTopicsTableStatusCol,
+		schema.ForumsTopicsTableAddDatetimeCol,
+		schema.ForumsThemesTableIsModeratorCol,
+	).
+		From(schema.ForumsTopicsTable).
+		Join(schema.ForumsThemesTable, goqu.On(schema.ForumsTopicsTableThemeIDCol.Eq(schema.ForumsThemesTableIDCol))).
+		Where(schema.ForumsTopicsTableIDCol.Eq(topicID)).
+		ScanStructContext(ctx, &row)
+	if err != nil {
+		return err
+	}
+
+	if !success || row.Status == topicStatusDeleted || row.IsModerator {
+		return s.index.Delete(KindForumTopic, topicID)
+	}
+
+	return s.index.Replace(KindForumTopic, topicID, []Document{{Title: row.Name, Date: row.AddDatetime}})
+}
+
+// Refresh indexes entity after it was changed and asks other replicas to do the same.
+// Entity is already stored at this point, so failure is only logged and is fixed by the next reindex.
+func (s *Indexer) Refresh(ctx context.Context, kind Kind, entityID int64) {
+	s.refreshLocal(ctx, kind, entityID)
+
+	err := s.publisher.Publish(context.WithoutCancel(ctx), kind, entityID)
+	if err != nil {
+		logrus.Errorf("search: failed to broadcast refresh of %s %d: %s", kind, entityID, err.Error())
+	}
+}
+
+func (s *Indexer) refreshLocal(ctx context.Context, kind Kind, entityID int64) {
+	var err error
+
+	switch kind {
+	case KindItem:
+		err = s.IndexItem(ctx, entityID)
+	case KindPicture:
+		err = s.IndexPicture(ctx, entityID)
+	case KindComment:
+		err = s.IndexComment(ctx, entityID)
+	case KindForumTopic:
+		err = s.IndexForumTopic(ctx, entityID)
+	}
+
+	if err != nil {
+		logrus.Errorf("search: failed to index %s %d: %s", kind, entityID, err.Error())
+	}
+}
+
+// Reindex walks all indexable entities and indexes them again.
+func (s *Indexer) Reindex(ctx context.Context) error {
+	sources := []struct {
+		kind  Kind
+		ids   *goqu.SelectDataset
+		idCol exp.IdentifierExpression
+		index func(ctx context.Context, id int64) error
+	}{
+		{
+			kind:  KindItem,
+			ids:   s.db.Select(schema.ItemTableIDCol).From(schema.ItemTable),
+			idCol: schema.ItemTableIDCol,
+			index: s.IndexItem,
+		},
+		{
+			kind: KindPicture,
+			ids: s.db.Select(schema.PictureTableIDCol).From(schema.PictureTable).
+				Where(schema.PictureTableStatusCol.Eq(schema.PictureStatusAccepted)),
+			idCol: schema.PictureTableIDCol,
+			index: s.indexPicture,
+		},
+		{
+			kind: KindComment,
+			ids: s.db.Select(schema.CommentMessageTableIDCol).From(schema.CommentMessageTable).
+				Where(schema.CommentMessageTableDeletedCol.IsFalse()),
+			idCol: schema.CommentMessageTableIDCol,
+			index: s.IndexComment,
+		},
+		{
+			kind:  KindForumTopic,
+			ids:   s.publicTopicsSelect().Select(schema.ForumsTopicsTableIDCol),
+			idCol: schema.ForumsTopicsTableIDCol,
+			index: s.indexForumTopic,
+		},
+	}
+
+	for _, source := range sources {
+		var (
+			lastID int64
+			total  int
+		)
+
+		for {
+			var ids []int64
+
+			err := source.ids.
+				Where(source.idCol.Gt(lastID)).
+				Order(source.idCol.Asc()).
+				Limit(reindexBatchSize).
+				ScanValsContext(ctx, &ids)
+			if err != nil {
+				return err
+			}
+
+			if len(ids) == 0 {
+				break
+			}
+
+			for _, id := range ids {
+				err = source.index(ctx, id)
+				if err != nil {
+					return err
+				}
+			}
+
+			lastID = ids[len(ids)-1]
+			total += len(ids)
+		}
+
+		logrus.Infof("search: %d %s entities indexed", total, source.kind)
+	}
+
+	return nil
+}
@@ -0,0 +1,204 @@
+package search
+
+import (
+	"context"
+	"encoding/json"
+	"sync"
+
+	"github.com/autowp/goautowp/util"
+	"github.com/google/uuid"
+	amqp "github.com/rabbitmq/amqp091-go"
+	"github.com/sirupsen/logrus"
+)
+
+// RefreshMessage asks every replica to index entity again in its local index.
+type RefreshMessage struct {
+	Origin   string `json:"origin"`
+	Kind     Kind   `json:"kind"`
+	EntityID int64  `json:"entity_id"`
+}
+
+// refreshPublisher broadcasts refreshes over fanout exchange, connection is established on first use
+// and dropped on failure, so unavailable broker doesn't stall requests.
+type refreshPublisher struct {
+	url      string
+	exchange string
+	origin   string
+	mutex    sync.Mutex
+	conn     *amqp.Connection
+	ch       *amqp.Channel
+}
+
+func newRefreshPublisher(url, exchange string) *refreshPublisher {
+	return &refreshPublisher{
+		url:      url,
+		exchange: exchange,
+		origin:   uuid.NewString(),
+	}
+}
+
+func declareRefreshExchange(ch *amqp.Channel, exchange string) error {
+	return ch.ExchangeDeclare(
+		exchange, // name
+		"fanout", // type
+		true,     // durable
+		false,    // auto-deleted
+		false,    // internal
+		false,    // no-wait
+		nil,      // arguments
+	)
+}
+
+func (s *refreshPublisher) channel() (*amqp.Channel, error) {
+	if s.ch != nil && !s.ch.IsClosed() {
+		return s.ch, nil
+	}
+
+	s.reset()
+
+	conn, err := amqp.Dial(s.url)
+	if err != nil {
+		return nil, err
+	}
+
+	ch, err := conn.Channel()
+	if err != nil {
+		util.Close(conn)
+
+		return nil, err
+	}
+
+	err = declareRefreshExchange(ch, s.exchange)
+	if err != nil {
+		util.Close(conn)
+
+		return nil, err
+	}
+
+	s.conn = conn
+	s.ch = ch
+
+	return ch, nil
+}
+
+func (s *refreshPublisher) reset() {
+	if s.conn != nil {
+		util.Close(s.conn)
+	}
+
+	s.conn = nil
+	s.ch = nil
+}
+
+func (s *refreshPublisher) Publish(ctx context.Context, kind Kind, entityID int64) error {
+	if s.url == "" || s.exchange == "" {
+		return nil
+	}
+
+	body, err := json.Marshal(RefreshMessage{Origin: s.origin, Kind: kind, EntityID: entityID})
+	if err != nil {
+		return err
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	ch, err := s.channel()
+	if err != nil {
+		return err
+	}
+
+	err = ch.PublishWithContext(ctx, s.exchange, "", false, false, amqp.Publishing{
+		ContentType: "application/json",
+		Body:        body,
+	})
+	if err != nil {
+		s.reset()
+	}
+
+	return err
+}
+
+func (s *refreshPublisher) Close() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.reset()
+}
+
+// ListenRefresh applies refreshes made by other replicas to the local index until quit.
+func (s *Indexer) ListenRefresh(ctx context.Context, quitChan chan bool) error {
+	conn, err := util.ConnectRabbitMQ(s.publisher.url)
+	if err != nil {
+		return err
+	}
+	defer util.Close(conn)
+
+	ch, err := conn.Channel()
+	if err != nil {
+		return err
+	}
+	defer util.Close(ch)
+
+	err = declareRefreshExchange(ch, s.publisher.exchange)
+	if err != nil {
+		return err
+	}
+
+	inQ, err := ch.QueueDeclare(
+		"",    // name
+		false, // durable
+		true,  // delete when unused
+		true,  // exclusive
+		false, // no-wait
+		nil,   // arguments
+	)
+	if err != nil {
+		return err
+	}
+
+	err = ch.QueueBind(inQ.Name, "", s.publisher.exchange, false, nil)
+	if err != nil {
+		return err
+	}
+
+	msgs, err := ch.Consume(
+		inQ.Name, // queue
+		"",       // consumer
+		true,     // auto-ack
+		true,     // exclusive
+		false,    // no-local
+		false,    // no-wait
+		nil,      // args
+	)
+	if err != nil {
+		return err
+	}
+
+	for {
+		select {
+		case msg, ok := <-msgs:
+			if !ok {
+				return nil
+			}
+
+			var message RefreshMessage
+
+			err = json.Unmarshal(msg.Body, &message)
+			if err != nil {
+				logrus.Errorf("search: failed to parse refresh message `%s`: %s", msg.Body, err.Error())
+
+				continue
+			}
+
+			if message.Origin == s.publisher.origin {
+				continue
+			}
+
+			s.refreshLocal(ctx, message.Kind, message.EntityID)
+
+		case <-quitChan:
+			return nil
+		}
+	}
+}
@@ -1639,10 +1639,16 @@ const (
 	Users_GetUserPreferences_FullMethodName               = "/goautowp.Users/GetUserPreferences"
 	Users_DisableUserCommentsNotifications_FullMethodName = "/goautowp.Users/DisableUserCommentsNotifications"
 	Users_EnableUserCommentsNotifications_FullMethodName  = "/goautowp.Users/EnableUserCommentsNotifications"
+	Users_GetNotificationPreferences_FullMethodName       = "/goautowp.Users/GetNotificationPreferences"
+	Users_SetNotificationPreference_FullMethodName        = "/goautowp.Users/SetNotificationPreference"
+	Users_SetDigestPeriod_FullMethodName                  = "/goautowp.Users/SetDigestPeriod"
 	Users_GetUsers_FullMethodName                         = "/goautowp.Users/GetUsers"
 	Users_GetAccounts_FullMethodName                      = "/goautowp.Users/GetAccounts"
 	Users_DeleteUserAccount_FullMethodName                = "/goautowp.Users/DeleteUserAccount"
 	Users_DeleteUserPhoto_FullMethodName                  = "/goautowp.Users/DeleteUserPhoto"
+	Users_GetBlockedUsers_FullMethodName                  = "/goautowp.Users/GetBlockedUsers"
+	Users_BlockUser_FullMethodName                        = "/goautowp.Users/BlockUser"
+	Users_UnblockUser_FullMethodName                      = "/goautowp.Users/UnblockUser"
 )
 
 // UsersClient is the client API for Users service.
@@ -1656,10 +1662,16 @@ type UsersClient interface {
 	GetUserPreferences(ctx context.Context, in *APIUserPreferencesRequest, opts ...grpc.CallOption) (*APIUserPreferencesResponse, error)
 	DisableUserCommentsNotifications(ctx context.Context, in *APIUserPreferencesRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
 	EnableUserCommentsNotifications(ctx context.Context, in *APIUserPreferencesRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	GetNotificationPreferences(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*NotificationPreferences, error)
+	SetNotificationPreference(ctx context.Context, in *NotificationPreference, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	SetDigestPeriod(ctx context.Context, in *SetDigestPeriodRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
 	GetUsers(ctx context.Context, in *APIUsersRequest, opts ...grpc.CallOption) (*APIUsersResponse, error)
 	GetAccounts(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*APIAccountsResponse, error)
 	DeleteUserAccount(ctx context.Context, in *DeleteUserAccountRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
 	DeleteUserPhoto(ctx context.Context, in *DeleteUserPhotoRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	GetBlockedUsers(ctx context.Context, in *APIGetBlockedUsersRequest, opts ...grpc.CallOption) (*APIBlockedUsers, error)
+	BlockUser(ctx context.Context, in *APIBlockUserRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	UnblockUser(ctx context.Context, in *APIBlockUserRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
 }
 
 type usersClient struct {
@@ -1740,6 +1752,36 @@ func (c *usersClient) EnableUserCommentsNotifications(ctx context.Context, in *A
 	return out, nil
 }
 
+func (c *usersClient) GetNotificationPreferences(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*NotificationPreferences, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(NotificationPreferences)
+	err := c.cc.Invoke(ctx, Users_GetNotificationPreferences_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *usersClient) SetNotificationPreference(ctx context.Context, in *NotificationPreference, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, Users_SetNotificationPreference_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *usersClient) SetDigestPeriod(ctx context.Context, in *SetDigestPeriodRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, Users_SetDigestPeriod_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *usersClient) GetUsers(ctx context.Context, in *APIUsersRequest, opts ...grpc.CallOption) (*APIUsersResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(APIUsersResponse)
@@ -1780,6 +1822,36 @@ func (c *usersClient) DeleteUserPhoto(ctx context.Context, in *DeleteUserPhotoRe
 	return out, nil
 }
 
+func (c *usersClient) GetBlockedUsers(ctx context.Context, in *APIGetBlockedUsersRequest, opts ...grpc.CallOption) (*APIBlockedUsers, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(APIBlockedUsers)
+	err := c.cc.Invoke(ctx, Users_GetBlockedUsers_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *usersClient) BlockUser(ctx context.Context, in *APIBlockUserRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, Users_BlockUser_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *usersClient) UnblockUser(ctx context.Context, in *APIBlockUserRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, Users_UnblockUser_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // UsersServer is the server API for Users service.
 // All implementations must embed UnimplementedUsersServer
 // for forward compatibility.
@@ -1791,10 +1863,16 @@ type UsersServer interface {
 	GetUserPreferences(context.Context, *APIUserPreferencesRequest) (*APIUserPreferencesResponse, error)
 	DisableUserCommentsNotifications(context.Context, *APIUserPreferencesRequest) (*emptypb.Empty, error)
 	EnableUserCommentsNotifications(context.Context, *APIUserPreferencesRequest) (*emptypb.Empty, error)
+	GetNotificationPreferences(context.Context, *emptypb.Empty) (*NotificationPreferences, error)
+	SetNotificationPreference(context.Context, *NotificationPreference) (*emptypb.Empty, error)
+	SetDigestPeriod(context.Context, *SetDigestPeriodRequest) (*emptypb.Empty, error)
 	GetUsers(context.Context, *APIUsersRequest) (*APIUsersResponse, error)
 	GetAccounts(context.Context, *emptypb.Empty) (*APIAccountsResponse, error)
 	DeleteUserAccount(context.Context, *DeleteUserAccountRequest) (*emptypb.Empty, error)
 	DeleteUserPhoto(context.Context, *DeleteUserPhotoRequest) (*emptypb.Empty, error)
+	GetBlockedUsers(context.Context, *APIGetBlockedUsersRequest) (*APIBlockedUsers, error)
+	BlockUser(context.Context, *APIBlockUserRequest) (*emptypb.Empty, error)
+	UnblockUser(context.Context, *APIBlockUserRequest) (*emptypb.Empty, error)
 	mustEmbedUnimplementedUsersServer()
 }
 
@@ -1826,6 +1904,15 @@ func (UnimplementedUsersServer) DisableUserCommentsNotifications(context.Context
 func (UnimplementedUsersServer) EnableUserCommentsNotifications(context.Context, *APIUserPreferencesRequest) (*emptypb.Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method EnableUserCommentsNotifications not implemented")
 }
+func (UnimplementedUsersServer) GetNotificationPreferences(context.Context, *emptypb.Empty) (*NotificationPreferences, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetNotificationPreferences not implemented")
+}
+func (UnimplementedUsersServer) SetNotificationPreference(context.Context, *NotificationPreference) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetNotificationPreference not implemented")
+}
+func (UnimplementedUsersServer) SetDigestPeriod(context.Context, *SetDigestPeriodRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetDigestPeriod not implemented")
+}
 func (UnimplementedUsersServer) GetUsers(context.Context, *APIUsersRequest) (*APIUsersResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetUsers not implemented")
 }
@@ -1838,6 +1925,15 @@ func (UnimplementedUsersServer) DeleteUserAccount(context.Context, *DeleteUserAc
 func (UnimplementedUsersServer) DeleteUserPhoto(context.Context, *DeleteUserPhotoRequest) (*emptypb.Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method DeleteUserPhoto not implemented")
 }
+func (UnimplementedUsersServer) GetBlockedUsers(context.Context, *APIGetBlockedUsersRequest) (*APIBlockedUsers, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetBlockedUsers not implemented")
+}
+func (UnimplementedUsersServer) BlockUser(context.Context, *APIBlockUserRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method BlockUser not implemented")
+}
+func (UnimplementedUsersServer) UnblockUser(context.Context, *APIBlockUserRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UnblockUser not implemented")
+}
 func (UnimplementedUsersServer) mustEmbedUnimplementedUsersServer() {}
 func (UnimplementedUsersServer) testEmbeddedByValue()               {}
 
@@ -1985,6 +2081,60 @@ func _Users_EnableUserCommentsNotifications_Handler(srv interface{}, ctx context
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Users_GetNotificationPreferences_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(emptypb.Empty)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UsersServer).GetNotificationPreferences(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Users_GetNotificationPreferences_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UsersServer).GetNotificationPreferences(ctx, req.(*emptypb.Empty))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Users_SetNotificationPreference_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(NotificationPreference)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UsersServer).SetNotificationPreference(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Users_SetNotificationPreference_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UsersServer).SetNotificationPreference(ctx, req.(*NotificationPreference))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Users_SetDigestPeriod_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SetDigestPeriodRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UsersServer).SetDigestPeriod(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Users_SetDigestPeriod_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UsersServer).SetDigestPeriod(ctx, req.(*SetDigestPeriodRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Users_GetUsers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(APIUsersRequest)
 	if err := dec(in); err != nil {
@@ -2057,6 +2207,60 @@ func _Users_DeleteUserPhoto_Handler(srv interface{}, ctx context.Context, dec fu
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Users_GetBlockedUsers_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(APIGetBlockedUsersRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UsersServer).GetBlockedUsers(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Users_GetBlockedUsers_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UsersServer).GetBlockedUsers(ctx, req.(*APIGetBlockedUsersRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Users_BlockUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(APIBlockUserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UsersServer).BlockUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Users_BlockUser_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UsersServer).BlockUser(ctx, req.(*APIBlockUserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Users_UnblockUser_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(APIBlockUserRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(UsersServer).UnblockUser(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Users_UnblockUser_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(UsersServer).UnblockUser(ctx, req.(*APIBlockUserRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Users_ServiceDesc is the grpc.ServiceDesc for Users service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -2092,6 +2296,18 @@ var Users_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "EnableUserCommentsNotifications",
 			Handler:    _Users_EnableUserCommentsNotifications_Handler,
 		},
+		{
+			MethodName: "GetNotificationPreferences",
+			Handler:    _Users_GetNotificationPreferences_Handler,
+		},
+		{
+			MethodName: "SetNotificationPreference",
+			Handler:    _Users_SetNotificationPreference_Handler,
+		},
+		{
+			MethodName: "SetDigestPeriod",
+			Handler:    _Users_SetDigestPeriod_Handler,
+		},
 		{
 			MethodName: "GetUsers",
 			Handler:    _Users_GetUsers_Handler,
@@ -2108,6 +2324,18 @@ var Users_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "DeleteUserPhoto",
 			Handler:    _Users_DeleteUserPhoto_Handler,
 		},
+		{
+			MethodName: "GetBlockedUsers",
+			Handler:    _Users_GetBlockedUsers_Handler,
+		},
+		{
+			MethodName: "BlockUser",
+			Handler:    _Users_BlockUser_Handler,
+		},
+		{
+			MethodName: "UnblockUser",
+			Handler:    _Users_UnblockUser_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "spec.proto",
@@ -2520,6 +2748,10 @@ const (
 	Items_UpdateItemParent_FullMethodName         = "/goautowp.Items/UpdateItemParent"
 	Items_DeleteItemParent_FullMethodName         = "/goautowp.Items/DeleteItemParent"
 	Items_MoveItemParent_FullMethodName           = "/goautowp.Items/MoveItemParent"
+	Items_MergeItems_FullMethodName               = "/goautowp.Items/MergeItems"
+	Items_SplitItem_FullMethodName                = "/goautowp.Items/SplitItem"
+	Items_GetItemHistory_FullMethodName           = "/goautowp.Items/GetItemHistory"
+	Items_RevertItemRevision_FullMethodName       = "/goautowp.Items/RevertItemRevision"
 	Items_RefreshInheritance_FullMethodName       = "/goautowp.Items/RefreshInheritance"
 	Items_SetUserItemSubscription_FullMethodName  = "/goautowp.Items/SetUserItemSubscription"
 	Items_GetPath_FullMethodName                  = "/goautowp.Items/GetPath"
@@ -2568,6 +2800,10 @@ type ItemsClient interface {
 	UpdateItemParent(ctx context.Context, in *ItemParent, opts ...grpc.CallOption) (*emptypb.Empty, error)
 	DeleteItemParent(ctx context.Context, in *DeleteItemParentRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
 	MoveItemParent(ctx context.Context, in *MoveItemParentRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	MergeItems(ctx context.Context, in *MergeItemsRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	SplitItem(ctx context.Context, in *SplitItemRequest, opts ...grpc.CallOption) (*ItemID, error)
+	GetItemHistory(ctx context.Context, in *GetItemHistoryRequest, opts ...grpc.CallOption) (*ItemRevisions, error)
+	RevertItemRevision(ctx context.Context, in *RevertItemRevisionRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
 	RefreshInheritance(ctx context.Context, in *RefreshInheritanceRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
 	SetUserItemSubscription(ctx context.Context, in *SetUserItemSubscriptionRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
 	GetPath(ctx context.Context, in *PathRequest, opts ...grpc.CallOption) (*PathResponse, error)
@@ -2962,6 +3198,46 @@ func (c *itemsClient) MoveItemParent(ctx context.Context, in *MoveItemParentRequ
 	return out, nil
 }
 
+func (c *itemsClient) MergeItems(ctx context.Context, in *MergeItemsRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, Items_MergeItems_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *itemsClient) SplitItem(ctx context.Context, in *SplitItemRequest, opts ...grpc.CallOption) (*ItemID, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ItemID)
+	err := c.cc.Invoke(ctx, Items_SplitItem_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *itemsClient) GetItemHistory(ctx context.Context, in *GetItemHistoryRequest, opts ...grpc.CallOption) (*ItemRevisions, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ItemRevisions)
+	err := c.cc.Invoke(ctx, Items_GetItemHistory_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *itemsClient) RevertItemRevision(ctx context.Context, in *RevertItemRevisionRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, Items_RevertItemRevision_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *itemsClient) RefreshInheritance(ctx context.Context, in *RefreshInheritanceRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(emptypb.Empty)
@@ -3044,6 +3320,10 @@ type ItemsServer interface {
 	UpdateItemParent(context.Context, *ItemParent) (*emptypb.Empty, error)
 	DeleteItemParent(context.Context, *DeleteItemParentRequest) (*emptypb.Empty, error)
 	MoveItemParent(context.Context, *MoveItemParentRequest) (*emptypb.Empty, error)
+	MergeItems(context.Context, *MergeItemsRequest) (*emptypb.Empty, error)
+	SplitItem(context.Context, *SplitItemRequest) (*ItemID, error)
+	GetItemHistory(context.Context, *GetItemHistoryRequest) (*ItemRevisions, error)
+	RevertItemRevision(context.Context, *RevertItemRevisionRequest) (*emptypb.Empty, error)
 	RefreshInheritance(context.Context, *RefreshInheritanceRequest) (*emptypb.Empty, error)
 	SetUserItemSubscription(context.Context, *SetUserItemSubscriptionRequest) (*emptypb.Empty, error)
 	GetPath(context.Context, *PathRequest) (*PathResponse, error)
@@ -3172,6 +3452,18 @@ func (UnimplementedItemsServer) DeleteItemParent(context.Context, *DeleteItemPar
 func (UnimplementedItemsServer) MoveItemParent(context.Context, *MoveItemParentRequest) (*emptypb.Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method MoveItemParent not implemented")
 }
+func (UnimplementedItemsServer) MergeItems(context.Context, *MergeItemsRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MergeItems not implemented")
+}
+func (UnimplementedItemsServer) SplitItem(context.Context, *SplitItemRequest) (*ItemID, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SplitItem not implemented")
+}
+func (UnimplementedItemsServer) GetItemHistory(context.Context, *GetItemHistoryRequest) (*ItemRevisions, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetItemHistory not implemented")
+}
+func (UnimplementedItemsServer) RevertItemRevision(context.Context, *RevertItemRevisionRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RevertItemRevision not implemented")
+}
 func (UnimplementedItemsServer) RefreshInheritance(context.Context, *RefreshInheritanceRequest) (*emptypb.Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method RefreshInheritance not implemented")
 }
@@ -3889,6 +4181,78 @@ func _Items_MoveItemParent_Handler(srv interface{}, ctx context.Context, dec fun
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Items_MergeItems_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MergeItemsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ItemsServer).MergeItems(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Items_MergeItems_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ItemsServer).MergeItems(ctx, req.(*MergeItemsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Items_SplitItem_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SplitItemRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ItemsServer).SplitItem(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Items_SplitItem_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ItemsServer).SplitItem(ctx, req.(*SplitItemRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Items_GetItemHistory_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetItemHistoryRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ItemsServer).GetItemHistory(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Items_GetItemHistory_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ItemsServer).GetItemHistory(ctx, req.(*GetItemHistoryRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Items_RevertItemRevision_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RevertItemRevisionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ItemsServer).RevertItemRevision(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Items_RevertItemRevision_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ItemsServer).RevertItemRevision(ctx, req.(*RevertItemRevisionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Items_RefreshInheritance_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(RefreshInheritanceRequest)
 	if err := dec(in); err != nil {
@@ -4120,6 +4484,22 @@ var Items_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "MoveItemParent",
 			Handler:    _Items_MoveItemParent_Handler,
 		},
+		{
+			MethodName: "MergeItems",
+			Handler:    _Items_MergeItems_Handler,
+		},
+		{
+			MethodName: "SplitItem",
+			Handler:    _Items_SplitItem_Handler,
+		},
+		{
+			MethodName: "GetItemHistory",
+			Handler:    _Items_GetItemHistory_Handler,
+		},
+		{
+			MethodName: "RevertItemRevision",
+			Handler:    _Items_RevertItemRevision_Handler,
+		},
 		{
 			MethodName: "RefreshInheritance",
 			Handler:    _Items_RefreshInheritance_Handler,
@@ -4282,17 +4662,21 @@ var Mosts_ServiceDesc = grpc.ServiceDesc{
 }
 
 const (
-	Comments_GetCommentVotes_FullMethodName = "/goautowp.Comments/GetCommentVotes"
-	Comments_Subscribe_FullMethodName       = "/goautowp.Comments/Subscribe"
-	Comments_UnSubscribe_FullMethodName     = "/goautowp.Comments/UnSubscribe"
-	Comments_View_FullMethodName            = "/goautowp.Comments/View"
-	Comments_SetDeleted_FullMethodName      = "/goautowp.Comments/SetDeleted"
-	Comments_MoveComment_FullMethodName     = "/goautowp.Comments/MoveComment"
-	Comments_VoteComment_FullMethodName     = "/goautowp.Comments/VoteComment"
-	Comments_Add_FullMethodName             = "/goautowp.Comments/Add"
-	Comments_GetMessagePage_FullMethodName  = "/goautowp.Comments/GetMessagePage"
-	Comments_GetMessage_FullMethodName      = "/goautowp.Comments/GetMessage"
-	Comments_GetMessages_FullMethodName     = "/goautowp.Comments/GetMessages"
+	Comments_GetCommentVotes_FullMethodName    = "/goautowp.Comments/GetCommentVotes"
+	Comments_Subscribe_FullMethodName          = "/goautowp.Comments/Subscribe"
+	Comments_UnSubscribe_FullMethodName        = "/goautowp.Comments/UnSubscribe"
+	Comments_View_FullMethodName               = "/goautowp.Comments/View"
+	Comments_SetDeleted_FullMethodName         = "/goautowp.Comments/SetDeleted"
+	Comments_MoveComment_FullMethodName        = "/goautowp.Comments/MoveComment"
+	Comments_VoteComment_FullMethodName        = "/goautowp.Comments/VoteComment"
+	Comments_Add_FullMethodName                = "/goautowp.Comments/Add"
+	Comments_EditMessage_FullMethodName        = "/goautowp.Comments/EditMessage"
+	Comments_GetMessagePage_FullMethodName     = "/goautowp.Comments/GetMessagePage"
+	Comments_GetMessage_FullMethodName         = "/goautowp.Comments/GetMessage"
+	Comments_GetMessages_FullMethodName        = "/goautowp.Comments/GetMessages"
+	Comments_GetMessageTree_FullMethodName     = "/goautowp.Comments/GetMessageTree"
+	Comments_GetModerationQueue_FullMethodName = "/goautowp.Comments/GetModerationQueue"
+	Comments_ModerateMessages_FullMethodName   = "/goautowp.Comments/ModerateMessages"
 )
 
 // CommentsClient is the client API for Comments service.
@@ -4307,9 +4691,13 @@ type CommentsClient interface {
 	MoveComment(ctx context.Context, in *CommentsMoveCommentRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
 	VoteComment(ctx context.Context, in *CommentsVoteCommentRequest, opts ...grpc.CallOption) (*CommentsVoteCommentResponse, error)
 	Add(ctx context.Context, in *AddCommentRequest, opts ...grpc.CallOption) (*AddCommentResponse, error)
+	EditMessage(ctx context.Context, in *CommentsEditMessageRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
 	GetMessagePage(ctx context.Context, in *GetMessagePageRequest, opts ...grpc.CallOption) (*APICommentsMessagePage, error)
 	GetMessage(ctx context.Context, in *GetMessageRequest, opts ...grpc.CallOption) (*APICommentsMessage, error)
 	GetMessages(ctx context.Context, in *GetMessagesRequest, opts ...grpc.CallOption) (*APICommentsMessages, error)
+	GetMessageTree(ctx context.Context, in *GetMessageTreeRequest, opts ...grpc.CallOption) (*APICommentsMessageTree, error)
+	GetModerationQueue(ctx context.Context, in *CommentsModerationQueueRequest, opts ...grpc.CallOption) (*CommentsModerationQueue, error)
+	ModerateMessages(ctx context.Context, in *CommentsModerateMessagesRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
 }
 
 type commentsClient struct {
@@ -4400,6 +4788,16 @@ func (c *commentsClient) Add(ctx context.Context, in *AddCommentRequest, opts ..
 	return out, nil
 }
 
+func (c *commentsClient) EditMessage(ctx context.Context, in *CommentsEditMessageRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, Comments_EditMessage_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *commentsClient) GetMessagePage(ctx context.Context, in *GetMessagePageRequest, opts ...grpc.CallOption) (*APICommentsMessagePage, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(APICommentsMessagePage)
@@ -4430,21 +4828,55 @@ func (c *commentsClient) GetMessages(ctx context.Context, in *GetMessagesRequest
 	return out, nil
 }
 
-// CommentsServer is the server API for Comments service.
-// All implementations must embed UnimplementedCommentsServer
-// for forward compatibility.
-type CommentsServer interface {
-	GetCommentVotes(context.Context, *GetCommentVotesRequest) (*CommentVoteItems, error)
-	Subscribe(context.Context, *CommentsSubscribeRequest) (*emptypb.Empty, error)
-	UnSubscribe(context.Context, *CommentsUnSubscribeRequest) (*emptypb.Empty, error)
-	View(context.Context, *CommentsViewRequest) (*emptypb.Empty, error)
-	SetDeleted(context.Context, *CommentsSetDeletedRequest) (*emptypb.Empty, error)
-	MoveComment(context.Context, *CommentsMoveCommentRequest) (*emptypb.Empty, error)
+func (c *commentsClient) GetMessageTree(ctx context.Context, in *GetMessageTreeRequest, opts ...grpc.CallOption) (*APICommentsMessageTree, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(APICommentsMessageTree)
+	err := c.cc.Invoke(ctx, Comments_GetMessageTree_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *commentsClient) GetModerationQueue(ctx context.Context, in *CommentsModerationQueueRequest, opts ...grpc.CallOption) (*CommentsModerationQueue, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CommentsModerationQueue)
+	err := c.cc.Invoke(ctx, Comments_GetModerationQueue_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *commentsClient) ModerateMessages(ctx context.Context, in *CommentsModerateMessagesRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, Comments_ModerateMessages_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// CommentsServer is the server API for Comments service.
+// All implementations must embed UnimplementedCommentsServer
+// for forward compatibility.
+type CommentsServer interface {
+	GetCommentVotes(context.Context, *GetCommentVotesRequest) (*CommentVoteItems, error)
+	Subscribe(context.Context, *CommentsSubscribeRequest) (*emptypb.Empty, error)
+	UnSubscribe(context.Context, *CommentsUnSubscribeRequest) (*emptypb.Empty, error)
+	View(context.Context, *CommentsViewRequest) (*emptypb.Empty, error)
+	SetDeleted(context.Context, *CommentsSetDeletedRequest) (*emptypb.Empty, error)
+	MoveComment(context.Context, *CommentsMoveCommentRequest) (*emptypb.Empty, error)
 	VoteComment(context.Context, *CommentsVoteCommentRequest) (*CommentsVoteCommentResponse, error)
 	Add(context.Context, *AddCommentRequest) (*AddCommentResponse, error)
+	EditMessage(context.Context, *CommentsEditMessageRequest) (*emptypb.Empty, error)
 	GetMessagePage(context.Context, *GetMessagePageRequest) (*APICommentsMessagePage, error)
 	GetMessage(context.Context, *GetMessageRequest) (*APICommentsMessage, error)
 	GetMessages(context.Context, *GetMessagesRequest) (*APICommentsMessages, error)
+	GetMessageTree(context.Context, *GetMessageTreeRequest) (*APICommentsMessageTree, error)
+	GetModerationQueue(context.Context, *CommentsModerationQueueRequest) (*CommentsModerationQueue, error)
+	ModerateMessages(context.Context, *CommentsModerateMessagesRequest) (*emptypb.Empty, error)
 	mustEmbedUnimplementedCommentsServer()
 }
 
@@ -4479,6 +4911,9 @@ func (UnimplementedCommentsServer) VoteComment(context.Context, *CommentsVoteCom
 func (UnimplementedCommentsServer) Add(context.Context, *AddCommentRequest) (*AddCommentResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Add not implemented")
 }
+func (UnimplementedCommentsServer) EditMessage(context.Context, *CommentsEditMessageRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method EditMessage not implemented")
+}
 func (UnimplementedCommentsServer) GetMessagePage(context.Context, *GetMessagePageRequest) (*APICommentsMessagePage, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetMessagePage not implemented")
 }
@@ -4488,6 +4923,15 @@ func (UnimplementedCommentsServer) GetMessage(context.Context, *GetMessageReques
 func (UnimplementedCommentsServer) GetMessages(context.Context, *GetMessagesRequest) (*APICommentsMessages, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetMessages not implemented")
 }
+func (UnimplementedCommentsServer) GetMessageTree(context.Context, *GetMessageTreeRequest) (*APICommentsMessageTree, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetMessageTree not implemented")
+}
+func (UnimplementedCommentsServer) GetModerationQueue(context.Context, *CommentsModerationQueueRequest) (*CommentsModerationQueue, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetModerationQueue not implemented")
+}
+func (UnimplementedCommentsServer) ModerateMessages(context.Context, *CommentsModerateMessagesRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ModerateMessages not implemented")
+}
 func (UnimplementedCommentsServer) mustEmbedUnimplementedCommentsServer() {}
 func (UnimplementedCommentsServer) testEmbeddedByValue()                  {}
 
@@ -4653,6 +5097,24 @@ func _Comments_Add_Handler(srv interface{}, ctx context.Context, dec func(interf
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Comments_EditMessage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CommentsEditMessageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CommentsServer).EditMessage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Comments_EditMessage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CommentsServer).EditMessage(ctx, req.(*CommentsEditMessageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Comments_GetMessagePage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(GetMessagePageRequest)
 	if err := dec(in); err != nil {
@@ -4707,6 +5169,60 @@ func _Comments_GetMessages_Handler(srv interface{}, ctx context.Context, dec fun
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Comments_GetMessageTree_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetMessageTreeRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CommentsServer).GetMessageTree(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Comments_GetMessageTree_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CommentsServer).GetMessageTree(ctx, req.(*GetMessageTreeRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Comments_GetModerationQueue_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CommentsModerationQueueRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CommentsServer).GetModerationQueue(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Comments_GetModerationQueue_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CommentsServer).GetModerationQueue(ctx, req.(*CommentsModerationQueueRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Comments_ModerateMessages_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CommentsModerateMessagesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(CommentsServer).ModerateMessages(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Comments_ModerateMessages_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(CommentsServer).ModerateMessages(ctx, req.(*CommentsModerateMessagesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Comments_ServiceDesc is the grpc.ServiceDesc for Comments service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -4746,6 +5262,10 @@ var Comments_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Add",
 			Handler:    _Comments_Add_Handler,
 		},
+		{
+			MethodName: "EditMessage",
+			Handler:    _Comments_EditMessage_Handler,
+		},
 		{
 			MethodName: "GetMessagePage",
 			Handler:    _Comments_GetMessagePage_Handler,
@@ -4758,6 +5278,18 @@ var Comments_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetMessages",
 			Handler:    _Comments_GetMessages_Handler,
 		},
+		{
+			MethodName: "GetMessageTree",
+			Handler:    _Comments_GetMessageTree_Handler,
+		},
+		{
+			MethodName: "GetModerationQueue",
+			Handler:    _Comments_GetModerationQueue_Handler,
+		},
+		{
+			MethodName: "ModerateMessages",
+			Handler:    _Comments_ModerateMessages_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "spec.proto",
@@ -6324,12 +6856,18 @@ var Pictures_ServiceDesc = grpc.ServiceDesc{
 }
 
 const (
-	Messaging_GetMessagesNewCount_FullMethodName = "/goautowp.Messaging/GetMessagesNewCount"
-	Messaging_GetMessagesSummary_FullMethodName  = "/goautowp.Messaging/GetMessagesSummary"
-	Messaging_DeleteMessage_FullMethodName       = "/goautowp.Messaging/DeleteMessage"
-	Messaging_ClearFolder_FullMethodName         = "/goautowp.Messaging/ClearFolder"
-	Messaging_CreateMessage_FullMethodName       = "/goautowp.Messaging/CreateMessage"
-	Messaging_GetMessages_FullMethodName         = "/goautowp.Messaging/GetMessages"
+	Messaging_GetMessagesNewCount_FullMethodName     = "/goautowp.Messaging/GetMessagesNewCount"
+	Messaging_GetMessagesSummary_FullMethodName      = "/goautowp.Messaging/GetMessagesSummary"
+	Messaging_DeleteMessage_FullMethodName           = "/goautowp.Messaging/DeleteMessage"
+	Messaging_ClearFolder_FullMethodName             = "/goautowp.Messaging/ClearFolder"
+	Messaging_CreateMessage_FullMethodName           = "/goautowp.Messaging/CreateMessage"
+	Messaging_GetMessages_FullMethodName             = "/goautowp.Messaging/GetMessages"
+	Messaging_GetConversations_FullMethodName        = "/goautowp.Messaging/GetConversations"
+	Messaging_CreateConversation_FullMethodName      = "/goautowp.Messaging/CreateConversation"
+	Messaging_GetConversationMessages_FullMethodName = "/goautowp.Messaging/GetConversationMessages"
+	Messaging_SendConversationMessage_FullMethodName = "/goautowp.Messaging/SendConversationMessage"
+	Messaging_MarkConversationRead_FullMethodName    = "/goautowp.Messaging/MarkConversationRead"
+	Messaging_SetConversationMuted_FullMethodName    = "/goautowp.Messaging/SetConversationMuted"
 )
 
 // MessagingClient is the client API for Messaging service.
@@ -6342,6 +6880,12 @@ type MessagingClient interface {
 	ClearFolder(ctx context.Context, in *MessagingClearFolder, opts ...grpc.CallOption) (*emptypb.Empty, error)
 	CreateMessage(ctx context.Context, in *MessagingCreateMessage, opts ...grpc.CallOption) (*emptypb.Empty, error)
 	GetMessages(ctx context.Context, in *MessagingGetMessagesRequest, opts ...grpc.CallOption) (*MessagingGetMessagesResponse, error)
+	GetConversations(ctx context.Context, in *MessagingGetConversationsRequest, opts ...grpc.CallOption) (*MessagingConversations, error)
+	CreateConversation(ctx context.Context, in *MessagingCreateConversationRequest, opts ...grpc.CallOption) (*MessagingConversationID, error)
+	GetConversationMessages(ctx context.Context, in *MessagingGetConversationMessagesRequest, opts ...grpc.CallOption) (*MessagingConversationMessages, error)
+	SendConversationMessage(ctx context.Context, in *MessagingSendConversationMessageRequest, opts ...grpc.CallOption) (*MessagingConversationMessageID, error)
+	MarkConversationRead(ctx context.Context, in *MessagingMarkConversationReadRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	SetConversationMuted(ctx context.Context, in *MessagingSetConversationMutedRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
 }
 
 type messagingClient struct {
@@ -6412,6 +6956,66 @@ func (c *messagingClient) GetMessages(ctx context.Context, in *MessagingGetMessa
 	return out, nil
 }
 
+func (c *messagingClient) GetConversations(ctx context.Context, in *MessagingGetConversationsRequest, opts ...grpc.CallOption) (*MessagingConversations, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(MessagingConversations)
+	err := c.cc.Invoke(ctx, Messaging_GetConversations_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *messagingClient) CreateConversation(ctx context.Context, in *MessagingCreateConversationRequest, opts ...grpc.CallOption) (*MessagingConversationID, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(MessagingConversationID)
+	err := c.cc.Invoke(ctx, Messaging_CreateConversation_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *messagingClient) GetConversationMessages(ctx context.Context, in *MessagingGetConversationMessagesRequest, opts ...grpc.CallOption) (*MessagingConversationMessages, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(MessagingConversationMessages)
+	err := c.cc.Invoke(ctx, Messaging_GetConversationMessages_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *messagingClient) SendConversationMessage(ctx context.Context, in *MessagingSendConversationMessageRequest, opts ...grpc.CallOption) (*MessagingConversationMessageID, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(MessagingConversationMessageID)
+	err := c.cc.Invoke(ctx, Messaging_SendConversationMessage_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *messagingClient) MarkConversationRead(ctx context.Context, in *MessagingMarkConversationReadRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, Messaging_MarkConversationRead_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *messagingClient) SetConversationMuted(ctx context.Context, in *MessagingSetConversationMutedRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, Messaging_SetConversationMuted_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // MessagingServer is the server API for Messaging service.
 // All implementations must embed UnimplementedMessagingServer
 // for forward compatibility.
@@ -6422,6 +7026,12 @@ type MessagingServer interface {
 	ClearFolder(context.Context, *MessagingClearFolder) (*emptypb.Empty, error)
 	CreateMessage(context.Context, *MessagingCreateMessage) (*emptypb.Empty, error)
 	GetMessages(context.Context, *MessagingGetMessagesRequest) (*MessagingGetMessagesResponse, error)
+	GetConversations(context.Context, *MessagingGetConversationsRequest) (*MessagingConversations, error)
+	CreateConversation(context.Context, *MessagingCreateConversationRequest) (*MessagingConversationID, error)
+	GetConversationMessages(context.Context, *MessagingGetConversationMessagesRequest) (*MessagingConversationMessages, error)
+	SendConversationMessage(context.Context, *MessagingSendConversationMessageRequest) (*MessagingConversationMessageID, error)
+	MarkConversationRead(context.Context, *MessagingMarkConversationReadRequest) (*emptypb.Empty, error)
+	SetConversationMuted(context.Context, *MessagingSetConversationMutedRequest) (*emptypb.Empty, error)
 	mustEmbedUnimplementedMessagingServer()
 }
 
@@ -6450,6 +7060,24 @@ func (UnimplementedMessagingServer) CreateMessage(context.Context, *MessagingCre
 func (UnimplementedMessagingServer) GetMessages(context.Context, *MessagingGetMessagesRequest) (*MessagingGetMessagesResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetMessages not implemented")
 }
+func (UnimplementedMessagingServer) GetConversations(context.Context, *MessagingGetConversationsRequest) (*MessagingConversations, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetConversations not implemented")
+}
+func (UnimplementedMessagingServer) CreateConversation(context.Context, *MessagingCreateConversationRequest) (*MessagingConversationID, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateConversation not implemented")
+}
+func (UnimplementedMessagingServer) GetConversationMessages(context.Context, *MessagingGetConversationMessagesRequest) (*MessagingConversationMessages, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetConversationMessages not implemented")
+}
+func (UnimplementedMessagingServer) SendConversationMessage(context.Context, *MessagingSendConversationMessageRequest) (*MessagingConversationMessageID, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SendConversationMessage not implemented")
+}
+func (UnimplementedMessagingServer) MarkConversationRead(context.Context, *MessagingMarkConversationReadRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method MarkConversationRead not implemented")
+}
+func (UnimplementedMessagingServer) SetConversationMuted(context.Context, *MessagingSetConversationMutedRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetConversationMuted not implemented")
+}
 func (UnimplementedMessagingServer) mustEmbedUnimplementedMessagingServer() {}
 func (UnimplementedMessagingServer) testEmbeddedByValue()                   {}
 
@@ -6579,6 +7207,114 @@ func _Messaging_GetMessages_Handler(srv interface{}, ctx context.Context, dec fu
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Messaging_GetConversations_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MessagingGetConversationsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessagingServer).GetConversations(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Messaging_GetConversations_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessagingServer).GetConversations(ctx, req.(*MessagingGetConversationsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Messaging_CreateConversation_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MessagingCreateConversationRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessagingServer).CreateConversation(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Messaging_CreateConversation_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessagingServer).CreateConversation(ctx, req.(*MessagingCreateConversationRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Messaging_GetConversationMessages_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MessagingGetConversationMessagesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessagingServer).GetConversationMessages(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Messaging_GetConversationMessages_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessagingServer).GetConversationMessages(ctx, req.(*MessagingGetConversationMessagesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Messaging_SendConversationMessage_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MessagingSendConversationMessageRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessagingServer).SendConversationMessage(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Messaging_SendConversationMessage_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessagingServer).SendConversationMessage(ctx, req.(*MessagingSendConversationMessageRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Messaging_MarkConversationRead_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MessagingMarkConversationReadRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessagingServer).MarkConversationRead(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Messaging_MarkConversationRead_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessagingServer).MarkConversationRead(ctx, req.(*MessagingMarkConversationReadRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Messaging_SetConversationMuted_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(MessagingSetConversationMutedRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MessagingServer).SetConversationMuted(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Messaging_SetConversationMuted_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MessagingServer).SetConversationMuted(ctx, req.(*MessagingSetConversationMutedRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Messaging_ServiceDesc is the grpc.ServiceDesc for Messaging service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -6610,6 +7346,30 @@ var Messaging_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetMessages",
 			Handler:    _Messaging_GetMessages_Handler,
 		},
+		{
+			MethodName: "GetConversations",
+			Handler:    _Messaging_GetConversations_Handler,
+		},
+		{
+			MethodName: "CreateConversation",
+			Handler:    _Messaging_CreateConversation_Handler,
+		},
+		{
+			MethodName: "GetConversationMessages",
+			Handler:    _Messaging_GetConversationMessages_Handler,
+		},
+		{
+			MethodName: "SendConversationMessage",
+			Handler:    _Messaging_SendConversationMessage_Handler,
+		},
+		{
+			MethodName: "MarkConversationRead",
+			Handler:    _Messaging_MarkConversationRead_Handler,
+		},
+		{
+			MethodName: "SetConversationMuted",
+			Handler:    _Messaging_SetConversationMuted_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "spec.proto",
@@ -6998,23 +7758,28 @@ var Text_ServiceDesc = grpc.ServiceDesc{
 }
 
 const (
-	Attrs_GetAttribute_FullMethodName           = "/goautowp.Attrs/GetAttribute"
-	Attrs_GetAttributes_FullMethodName          = "/goautowp.Attrs/GetAttributes"
-	Attrs_GetAttributeTypes_FullMethodName      = "/goautowp.Attrs/GetAttributeTypes"
-	Attrs_GetListOptions_FullMethodName         = "/goautowp.Attrs/GetListOptions"
-	Attrs_GetUnits_FullMethodName               = "/goautowp.Attrs/GetUnits"
-	Attrs_GetZoneAttributes_FullMethodName      = "/goautowp.Attrs/GetZoneAttributes"
-	Attrs_GetZones_FullMethodName               = "/goautowp.Attrs/GetZones"
-	Attrs_GetValues_FullMethodName              = "/goautowp.Attrs/GetValues"
-	Attrs_GetUserValues_FullMethodName          = "/goautowp.Attrs/GetUserValues"
-	Attrs_SetUserValues_FullMethodName          = "/goautowp.Attrs/SetUserValues"
-	Attrs_DeleteUserValues_FullMethodName       = "/goautowp.Attrs/DeleteUserValues"
-	Attrs_MoveUserValues_FullMethodName         = "/goautowp.Attrs/MoveUserValues"
-	Attrs_GetConflicts_FullMethodName           = "/goautowp.Attrs/GetConflicts"
-	Attrs_GetSpecifications_FullMethodName      = "/goautowp.Attrs/GetSpecifications"
-	Attrs_GetChildSpecifications_FullMethodName = "/goautowp.Attrs/GetChildSpecifications"
-	Attrs_GetChartParameters_FullMethodName     = "/goautowp.Attrs/GetChartParameters"
-	Attrs_GetChartData_FullMethodName           = "/goautowp.Attrs/GetChartData"
+	Attrs_GetAttribute_FullMethodName                = "/goautowp.Attrs/GetAttribute"
+	Attrs_GetAttributes_FullMethodName               = "/goautowp.Attrs/GetAttributes"
+	Attrs_GetAttributeTypes_FullMethodName           = "/goautowp.Attrs/GetAttributeTypes"
+	Attrs_GetListOptions_FullMethodName              = "/goautowp.Attrs/GetListOptions"
+	Attrs_GetUnits_FullMethodName                    = "/goautowp.Attrs/GetUnits"
+	Attrs_GetZoneAttributes_FullMethodName           = "/goautowp.Attrs/GetZoneAttributes"
+	Attrs_GetZones_FullMethodName                    = "/goautowp.Attrs/GetZones"
+	Attrs_GetValues_FullMethodName                   = "/goautowp.Attrs/GetValues"
+	Attrs_GetUserValues_FullMethodName               = "/goautowp.Attrs/GetUserValues"
+	Attrs_SetUserValues_FullMethodName               = "/goautowp.Attrs/SetUserValues"
+	Attrs_DeleteUserValues_FullMethodName            = "/goautowp.Attrs/DeleteUserValues"
+	Attrs_MoveUserValues_FullMethodName              = "/goautowp.Attrs/MoveUserValues"
+	Attrs_GetConflicts_FullMethodName                = "/goautowp.Attrs/GetConflicts"
+	Attrs_GetSpecifications_FullMethodName           = "/goautowp.Attrs/GetSpecifications"
+	Attrs_GetChildSpecifications_FullMethodName      = "/goautowp.Attrs/GetChildSpecifications"
+	Attrs_GetSpecificationsTable_FullMethodName      = "/goautowp.Attrs/GetSpecificationsTable"
+	Attrs_GetChildSpecificationsTable_FullMethodName = "/goautowp.Attrs/GetChildSpecificationsTable"
+	Attrs_GetChartParameters_FullMethodName          = "/goautowp.Attrs/GetChartParameters"
+	Attrs_GetChartData_FullMethodName                = "/goautowp.Attrs/GetChartData"
+	Attrs_GetChartSeries_FullMethodName              = "/goautowp.Attrs/GetChartSeries"
+	Attrs_GetChartScatter_FullMethodName             = "/goautowp.Attrs/GetChartScatter"
+	Attrs_GetPendingRecalculations_FullMethodName    = "/goautowp.Attrs/GetPendingRecalculations"
 )
 
 // AttrsClient is the client API for Attrs service.
@@ -7030,14 +7795,19 @@ type AttrsClient interface {
 	GetZones(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*AttrZonesResponse, error)
 	GetValues(ctx context.Context, in *AttrValuesRequest, opts ...grpc.CallOption) (*AttrValuesResponse, error)
 	GetUserValues(ctx context.Context, in *AttrUserValuesRequest, opts ...grpc.CallOption) (*AttrUserValuesResponse, error)
-	SetUserValues(ctx context.Context, in *AttrSetUserValuesRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	SetUserValues(ctx context.Context, in *AttrSetUserValuesRequest, opts ...grpc.CallOption) (*AttrSetUserValuesResponse, error)
 	DeleteUserValues(ctx context.Context, in *DeleteAttrUserValuesRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
 	MoveUserValues(ctx context.Context, in *MoveAttrUserValuesRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
 	GetConflicts(ctx context.Context, in *AttrConflictsRequest, opts ...grpc.CallOption) (*AttrConflictsResponse, error)
 	GetSpecifications(ctx context.Context, in *GetSpecificationsRequest, opts ...grpc.CallOption) (*GetSpecificationsResponse, error)
 	GetChildSpecifications(ctx context.Context, in *GetSpecificationsRequest, opts ...grpc.CallOption) (*GetSpecificationsResponse, error)
+	GetSpecificationsTable(ctx context.Context, in *GetSpecificationsRequest, opts ...grpc.CallOption) (*SpecificationsTable, error)
+	GetChildSpecificationsTable(ctx context.Context, in *GetSpecificationsRequest, opts ...grpc.CallOption) (*SpecificationsTable, error)
 	GetChartParameters(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ChartParameters, error)
 	GetChartData(ctx context.Context, in *ChartDataRequest, opts ...grpc.CallOption) (*ChartData, error)
+	GetChartSeries(ctx context.Context, in *ChartSeriesRequest, opts ...grpc.CallOption) (*ChartSeries, error)
+	GetChartScatter(ctx context.Context, in *ChartScatterRequest, opts ...grpc.CallOption) (*ChartScatter, error)
+	GetPendingRecalculations(ctx context.Context, in *AttrPendingRecalculationsRequest, opts ...grpc.CallOption) (*AttrPendingRecalculationsResponse, error)
 }
 
 type attrsClient struct {
@@ -7138,9 +7908,9 @@ func (c *attrsClient) GetUserValues(ctx context.Context, in *AttrUserValuesReque
 	return out, nil
 }
 
-func (c *attrsClient) SetUserValues(ctx context.Context, in *AttrSetUserValuesRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+func (c *attrsClient) SetUserValues(ctx context.Context, in *AttrSetUserValuesRequest, opts ...grpc.CallOption) (*AttrSetUserValuesResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
-	out := new(emptypb.Empty)
+	out := new(AttrSetUserValuesResponse)
 	err := c.cc.Invoke(ctx, Attrs_SetUserValues_FullMethodName, in, out, cOpts...)
 	if err != nil {
 		return nil, err
@@ -7198,6 +7968,26 @@ func (c *attrsClient) GetChildSpecifications(ctx context.Context, in *GetSpecifi
 	return out, nil
 }
 
+func (c *attrsClient) GetSpecificationsTable(ctx context.Context, in *GetSpecificationsRequest, opts ...grpc.CallOption) (*SpecificationsTable, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SpecificationsTable)
+	err := c.cc.Invoke(ctx, Attrs_GetSpecificationsTable_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *attrsClient) GetChildSpecificationsTable(ctx context.Context, in *GetSpecificationsRequest, opts ...grpc.CallOption) (*SpecificationsTable, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SpecificationsTable)
+	err := c.cc.Invoke(ctx, Attrs_GetChildSpecificationsTable_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *attrsClient) GetChartParameters(ctx context.Context, in *emptypb.Empty, opts ...grpc.CallOption) (*ChartParameters, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(ChartParameters)
@@ -7218,6 +8008,36 @@ func (c *attrsClient) GetChartData(ctx context.Context, in *ChartDataRequest, op
 	return out, nil
 }
 
+func (c *attrsClient) GetChartSeries(ctx context.Context, in *ChartSeriesRequest, opts ...grpc.CallOption) (*ChartSeries, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ChartSeries)
+	err := c.cc.Invoke(ctx, Attrs_GetChartSeries_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *attrsClient) GetChartScatter(ctx context.Context, in *ChartScatterRequest, opts ...grpc.CallOption) (*ChartScatter, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ChartScatter)
+	err := c.cc.Invoke(ctx, Attrs_GetChartScatter_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *attrsClient) GetPendingRecalculations(ctx context.Context, in *AttrPendingRecalculationsRequest, opts ...grpc.CallOption) (*AttrPendingRecalculationsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(AttrPendingRecalculationsResponse)
+	err := c.cc.Invoke(ctx, Attrs_GetPendingRecalculations_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // AttrsServer is the server API for Attrs service.
 // All implementations must embed UnimplementedAttrsServer
 // for forward compatibility.
@@ -7231,14 +8051,19 @@ type AttrsServer interface {
 	GetZones(context.Context, *emptypb.Empty) (*AttrZonesResponse, error)
 	GetValues(context.Context, *AttrValuesRequest) (*AttrValuesResponse, error)
 	GetUserValues(context.Context, *AttrUserValuesRequest) (*AttrUserValuesResponse, error)
-	SetUserValues(context.Context, *AttrSetUserValuesRequest) (*emptypb.Empty, error)
+	SetUserValues(context.Context, *AttrSetUserValuesRequest) (*AttrSetUserValuesResponse, error)
 	DeleteUserValues(context.Context, *DeleteAttrUserValuesRequest) (*emptypb.Empty, error)
 	MoveUserValues(context.Context, *MoveAttrUserValuesRequest) (*emptypb.Empty, error)
 	GetConflicts(context.Context, *AttrConflictsRequest) (*AttrConflictsResponse, error)
 	GetSpecifications(context.Context, *GetSpecificationsRequest) (*GetSpecificationsResponse, error)
 	GetChildSpecifications(context.Context, *GetSpecificationsRequest) (*GetSpecificationsResponse, error)
+	GetSpecificationsTable(context.Context, *GetSpecificationsRequest) (*SpecificationsTable, error)
+	GetChildSpecificationsTable(context.Context, *GetSpecificationsRequest) (*SpecificationsTable, error)
 	GetChartParameters(context.Context, *emptypb.Empty) (*ChartParameters, error)
 	GetChartData(context.Context, *ChartDataRequest) (*ChartData, error)
+	GetChartSeries(context.Context, *ChartSeriesRequest) (*ChartSeries, error)
+	GetChartScatter(context.Context, *ChartScatterRequest) (*ChartScatter, error)
+	GetPendingRecalculations(context.Context, *AttrPendingRecalculationsRequest) (*AttrPendingRecalculationsResponse, error)
 	mustEmbedUnimplementedAttrsServer()
 }
 
@@ -7276,7 +8101,7 @@ func (UnimplementedAttrsServer) GetValues(context.Context, *AttrValuesRequest) (
 func (UnimplementedAttrsServer) GetUserValues(context.Context, *AttrUserValuesRequest) (*AttrUserValuesResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetUserValues not implemented")
 }
-func (UnimplementedAttrsServer) SetUserValues(context.Context, *AttrSetUserValuesRequest) (*emptypb.Empty, error) {
+func (UnimplementedAttrsServer) SetUserValues(context.Context, *AttrSetUserValuesRequest) (*AttrSetUserValuesResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method SetUserValues not implemented")
 }
 func (UnimplementedAttrsServer) DeleteUserValues(context.Context, *DeleteAttrUserValuesRequest) (*emptypb.Empty, error) {
@@ -7294,12 +8119,27 @@ func (UnimplementedAttrsServer) GetSpecifications(context.Context, *GetSpecifica
 func (UnimplementedAttrsServer) GetChildSpecifications(context.Context, *GetSpecificationsRequest) (*GetSpecificationsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetChildSpecifications not implemented")
 }
+func (UnimplementedAttrsServer) GetSpecificationsTable(context.Context, *GetSpecificationsRequest) (*SpecificationsTable, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSpecificationsTable not implemented")
+}
+func (UnimplementedAttrsServer) GetChildSpecificationsTable(context.Context, *GetSpecificationsRequest) (*SpecificationsTable, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetChildSpecificationsTable not implemented")
+}
 func (UnimplementedAttrsServer) GetChartParameters(context.Context, *emptypb.Empty) (*ChartParameters, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetChartParameters not implemented")
 }
 func (UnimplementedAttrsServer) GetChartData(context.Context, *ChartDataRequest) (*ChartData, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method GetChartData not implemented")
 }
+func (UnimplementedAttrsServer) GetChartSeries(context.Context, *ChartSeriesRequest) (*ChartSeries, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetChartSeries not implemented")
+}
+func (UnimplementedAttrsServer) GetChartScatter(context.Context, *ChartScatterRequest) (*ChartScatter, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetChartScatter not implemented")
+}
+func (UnimplementedAttrsServer) GetPendingRecalculations(context.Context, *AttrPendingRecalculationsRequest) (*AttrPendingRecalculationsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetPendingRecalculations not implemented")
+}
 func (UnimplementedAttrsServer) mustEmbedUnimplementedAttrsServer() {}
 func (UnimplementedAttrsServer) testEmbeddedByValue()               {}
 
@@ -7591,6 +8431,42 @@ func _Attrs_GetChildSpecifications_Handler(srv interface{}, ctx context.Context,
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Attrs_GetSpecificationsTable_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSpecificationsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AttrsServer).GetSpecificationsTable(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Attrs_GetSpecificationsTable_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AttrsServer).GetSpecificationsTable(ctx, req.(*GetSpecificationsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Attrs_GetChildSpecificationsTable_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetSpecificationsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AttrsServer).GetChildSpecificationsTable(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Attrs_GetChildSpecificationsTable_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AttrsServer).GetChildSpecificationsTable(ctx, req.(*GetSpecificationsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _Attrs_GetChartParameters_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(emptypb.Empty)
 	if err := dec(in); err != nil {
@@ -7627,6 +8503,60 @@ func _Attrs_GetChartData_Handler(srv interface{}, ctx context.Context, dec func(
 	return interceptor(ctx, in, info, handler)
 }
 
+func _Attrs_GetChartSeries_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ChartSeriesRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AttrsServer).GetChartSeries(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Attrs_GetChartSeries_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AttrsServer).GetChartSeries(ctx, req.(*ChartSeriesRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Attrs_GetChartScatter_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ChartScatterRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AttrsServer).GetChartScatter(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Attrs_GetChartScatter_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AttrsServer).GetChartScatter(ctx, req.(*ChartScatterRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Attrs_GetPendingRecalculations_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(AttrPendingRecalculationsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(AttrsServer).GetPendingRecalculations(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Attrs_GetPendingRecalculations_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(AttrsServer).GetPendingRecalculations(ctx, req.(*AttrPendingRecalculationsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // Attrs_ServiceDesc is the grpc.ServiceDesc for Attrs service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -7694,6 +8624,14 @@ var Attrs_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetChildSpecifications",
 			Handler:    _Attrs_GetChildSpecifications_Handler,
 		},
+		{
+			MethodName: "GetSpecificationsTable",
+			Handler:    _Attrs_GetSpecificationsTable_Handler,
+		},
+		{
+			MethodName: "GetChildSpecificationsTable",
+			Handler:    _Attrs_GetChildSpecificationsTable_Handler,
+		},
 		{
 			MethodName: "GetChartParameters",
 			Handler:    _Attrs_GetChartParameters_Handler,
@@ -7702,6 +8640,120 @@ var Attrs_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "GetChartData",
 			Handler:    _Attrs_GetChartData_Handler,
 		},
+		{
+			MethodName: "GetChartSeries",
+			Handler:    _Attrs_GetChartSeries_Handler,
+		},
+		{
+			MethodName: "GetChartScatter",
+			Handler:    _Attrs_GetChartScatter_Handler,
+		},
+		{
+			MethodName: "GetPendingRecalculations",
+			Handler:    _Attrs_GetPendingRecalculations_Handler,
+		},
+	},
+	Streams:  []grpc.StreamDesc{},
+	Metadata: "spec.proto",
+}
+
+const (
+	Search_Search_FullMethodName = "/goautowp.Search/Search"
+)
+
+// SearchClient is the client API for Search service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+type SearchClient interface {
+	Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResponse, error)
+}
+
+type searchClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewSearchClient(cc grpc.ClientConnInterface) SearchClient {
+	return &searchClient{cc}
+}
+
+func (c *searchClient) Search(ctx context.Context, in *SearchRequest, opts ...grpc.CallOption) (*SearchResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(SearchResponse)
+	err := c.cc.Invoke(ctx, Search_Search_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// SearchServer is the server API for Search service.
+// All implementations must embed UnimplementedSearchServer
+// for forward compatibility.
+type SearchServer interface {
+	Search(context.Context, *SearchRequest) (*SearchResponse, error)
+	mustEmbedUnimplementedSearchServer()
+}
+
+// UnimplementedSearchServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedSearchServer struct{}
+
+func (UnimplementedSearchServer) Search(context.Context, *SearchRequest) (*SearchResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Search not implemented")
+}
+func (UnimplementedSearchServer) mustEmbedUnimplementedSearchServer() {}
+func (UnimplementedSearchServer) testEmbeddedByValue()                {}
+
+// UnsafeSearchServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to SearchServer will
+// result in compilation errors.
+type UnsafeSearchServer interface {
+	mustEmbedUnimplementedSearchServer()
+}
+
+func RegisterSearchServer(s grpc.ServiceRegistrar, srv SearchServer) {
+	// If the following call pancis, it indicates UnimplementedSearchServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Search_ServiceDesc, srv)
+}
+
+func _Search_Search_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SearchServer).Search(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Search_Search_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SearchServer).Search(ctx, req.(*SearchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Search_ServiceDesc is the grpc.ServiceDesc for Search service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Search_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "goautowp.Search",
+	HandlerType: (*SearchServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Search",
+			Handler:    _Search_Search_Handler,
+		},
 	},
 	Streams:  []grpc.StreamDesc{},
 	Metadata: "spec.proto",
@@ -752,6 +752,7 @@ func (s *Service) handleMeCommand(ctx context.Context, update *tgbotapi.Update)
 				userRow.ID,
 				token,
 			),
+			false,
 		)
 		if err != nil {
 			return err
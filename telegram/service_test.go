@@ -17,6 +17,7 @@ import (
 	"github.com/autowp/goautowp/image/storage"
 	"github.com/autowp/goautowp/items"
 	"github.com/autowp/goautowp/messaging"
+	"github.com/autowp/goautowp/notifications"
 	"github.com/autowp/goautowp/pictures"
 	"github.com/autowp/goautowp/schema"
 	"github.com/autowp/goautowp/textstorage"
@@ -76,13 +77,15 @@ func TestInboxCommand(t *testing.T) {
 
 	messagingRepo := messaging.NewRepository(
 		goquDB,
+		usersRepo,
 		func(_ context.Context, _ int64, _ int64, _ string) error {
 			return nil
 		},
 		i18n,
 	)
+	notifier := notifications.NewTestNotifier(t, goquDB, usersRepo, cfg.Notifications)
 	hostManager := hosts.NewManager(cfg.Languages)
-	commentsRepo := comments.NewRepository(goquDB, usersRepo, messagingRepo, hostManager)
+	commentsRepo := comments.NewRepository(goquDB, usersRepo, notifier, hostManager)
 	picturesRepo := pictures.NewRepository(
 		goquDB,
 		imageStorage,
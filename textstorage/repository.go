@@ -7,6 +7,7 @@ import (
 
 	"github.com/autowp/goautowp/schema"
 	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
 )
 
 var ErrTextNotFound = errors.New("text not found")
@@ -42,6 +43,26 @@ func (s *Repository) Text(ctx context.Context, id int32) (string, error) {
 	return result, nil
 }
 
+// TextTx reads text within transaction of caller and locks it until the end of transaction.
+func (s *Repository) TextTx(ctx context.Context, tx *goqu.TxDatabase, id int32) (string, error) {
+	result := ""
+
+	success, err := tx.From(schema.TextstorageTextTable).
+		Select(schema.TextstorageTextTableTextCol).
+		Where(schema.TextstorageTextTableIDCol.Eq(id)).
+		ForUpdate(exp.Wait).
+		ScanValContext(ctx, &result)
+	if err != nil {
+		return "", err
+	}
+
+	if !success {
+		return "", fmt.Errorf("%w: `%v`", ErrTextNotFound, id)
+	}
+
+	return result, nil
+}
+
 func (s *Repository) FirstText(ctx context.Context, ids []int32) (string, error) {
 	if len(ids) == 0 {
 		return "", nil
@@ -71,9 +92,24 @@ func (s *Repository) FirstText(ctx context.Context, ids []int32) (string, error)
 }
 
 func (s *Repository) CreateText(ctx context.Context, text string, userID int64) (int32, error) {
-	ctx = context.WithoutCancel(ctx)
+	var id int32
 
-	res, err := s.db.Insert(schema.TextstorageTextTable).Rows(goqu.Record{
+	err := s.db.WithTx(func(tx *goqu.TxDatabase) error {
+		var err error
+
+		id, err = s.CreateTextTx(context.WithoutCancel(ctx), tx, text, userID)
+
+		return err
+	})
+
+	return id, err
+}
+
+// CreateTextTx creates text within transaction of caller.
+func (s *Repository) CreateTextTx(
+	ctx context.Context, tx *goqu.TxDatabase, text string, userID int64,
+) (int32, error) {
+	res, err := tx.Insert(schema.TextstorageTextTable).Rows(goqu.Record{
 		schema.TextstorageTextTableRevisionColName:    0,
 		schema.TextstorageTextTableTextColName:        "",
 		schema.TextstorageTextTableLastUpdatedColName: goqu.Func("NOW"),
@@ -88,15 +124,22 @@ func (s *Repository) CreateText(ctx context.Context, text string, userID int64)
 	}
 
 	id := int32(lastInsertID) //nolint: gosec
-	err = s.SetText(ctx, id, text, userID)
+	err = s.SetTextTx(ctx, tx, id, text, userID)
 
 	return id, err
 }
 
 func (s *Repository) SetText(ctx context.Context, textID int32, text string, userID int64) error {
-	ctx = context.WithoutCancel(ctx)
+	return s.db.WithTx(func(tx *goqu.TxDatabase) error {
+		return s.SetTextTx(context.WithoutCancel(ctx), tx, textID, text, userID)
+	})
+}
 
-	res, err := s.db.Update(schema.TextstorageTextTable).
+// SetTextTx updates text and stores its revision within transaction of caller.
+func (s *Repository) SetTextTx(
+	ctx context.Context, tx *goqu.TxDatabase, textID int32, text string, userID int64,
+) error {
+	res, err := tx.Update(schema.TextstorageTextTable).
 		Set(goqu.Record{
 			schema.TextstorageTextTableRevisionColName: goqu.L(
 				"? + 1", goqu.C(schema.TextstorageTextTableRevisionColName)),
@@ -118,7 +161,7 @@ func (s *Repository) SetText(ctx context.Context, textID int32, text string, use
 	}
 
 	if affected > 0 {
-		_, err = s.db.Insert(schema.TextstorageRevisionTable).
+		_, err = tx.Insert(schema.TextstorageRevisionTable).
 			Cols(
 				schema.TextstorageRevisionTableTextIDColName,
 				schema.TextstorageRevisionTableRevisionColName,
@@ -127,7 +170,7 @@ func (s *Repository) SetText(ctx context.Context, textID int32, text string, use
 				schema.TextstorageRevisionTableUserIDColName,
 			).
 			FromQuery(
-				s.db.Select(
+				tx.Select(
 					schema.TextstorageTextTableIDCol,
 					schema.TextstorageTextTableRevisionCol,
 					schema.TextstorageTextTableTextCol,
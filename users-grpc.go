@@ -2,10 +2,13 @@ package goautowp
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"strings"
 
 	"github.com/autowp/goautowp/config"
+	"github.com/autowp/goautowp/digest"
+	"github.com/autowp/goautowp/notifications"
 	"github.com/autowp/goautowp/query"
 	"github.com/autowp/goautowp/schema"
 	"github.com/autowp/goautowp/users"
@@ -14,8 +17,11 @@ import (
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
 )
 
+const blockedUsersMaxLimit = 100
+
 func convertUserFields(fields *UserFields, currentUserRoles []string) users.UserFields {
 	lastIP := fields.GetLastIp() && util.Contains(currentUserRoles, users.RoleModer)
 	login := fields.GetLogin() && util.Contains(currentUserRoles, users.RoleModer)
@@ -34,6 +40,98 @@ func convertUserFields(fields *UserFields, currentUserRoles []string) users.User
 	}
 }
 
+func extractNotificationEvent(event notifications.Event) NotificationEvent {
+	switch event {
+	case notifications.EventReply:
+		return NotificationEvent_NOTIFICATION_EVENT_REPLY
+	case notifications.EventSubscription:
+		return NotificationEvent_NOTIFICATION_EVENT_SUBSCRIPTION
+	case notifications.EventPictureAccepted:
+		return NotificationEvent_NOTIFICATION_EVENT_PICTURE_ACCEPTED
+	case notifications.EventPictureRemoved:
+		return NotificationEvent_NOTIFICATION_EVENT_PICTURE_REMOVED
+	case notifications.EventPersonalMessage:
+		return NotificationEvent_NOTIFICATION_EVENT_PERSONAL_MESSAGE
+	case notifications.EventMention:
+		return NotificationEvent_NOTIFICATION_EVENT_MENTION
+	}
+
+	return NotificationEvent_NOTIFICATION_EVENT_UNKNOWN
+}
+
+func convertNotificationEvent(event NotificationEvent) notifications.Event {
+	switch event {
+	case NotificationEvent_NOTIFICATION_EVENT_UNKNOWN:
+		return ""
+	case NotificationEvent_NOTIFICATION_EVENT_REPLY:
+		return notifications.EventReply
+	case NotificationEvent_NOTIFICATION_EVENT_SUBSCRIPTION:
+		return notifications.EventSubscription
+	case NotificationEvent_NOTIFICATION_EVENT_PICTURE_ACCEPTED:
+		return notifications.EventPictureAccepted
+	case NotificationEvent_NOTIFICATION_EVENT_PICTURE_REMOVED:
+		return notifications.EventPictureRemoved
+	case NotificationEvent_NOTIFICATION_EVENT_PERSONAL_MESSAGE:
+		return notifications.EventPersonalMessage
+	case NotificationEvent_NOTIFICATION_EVENT_MENTION:
+		return notifications.EventMention
+	}
+
+	return ""
+}
+
+func extractNotificationChannel(channel notifications.Channel) NotificationChannel {
+	switch channel {
+	case notifications.ChannelSystemMessage:
+		return NotificationChannel_NOTIFICATION_CHANNEL_SYSTEM_MESSAGE
+	case notifications.ChannelEmail:
+		return NotificationChannel_NOTIFICATION_CHANNEL_EMAIL
+	case notifications.ChannelTelegram:
+		return NotificationChannel_NOTIFICATION_CHANNEL_TELEGRAM
+	}
+
+	return NotificationChannel_NOTIFICATION_CHANNEL_UNKNOWN
+}
+
+func convertNotificationChannel(channel NotificationChannel) notifications.Channel {
+	switch channel {
+	case NotificationChannel_NOTIFICATION_CHANNEL_UNKNOWN:
+		return ""
+	case NotificationChannel_NOTIFICATION_CHANNEL_SYSTEM_MESSAGE:
+		return notifications.ChannelSystemMessage
+	case NotificationChannel_NOTIFICATION_CHANNEL_EMAIL:
+		return notifications.ChannelEmail
+	case NotificationChannel_NOTIFICATION_CHANNEL_TELEGRAM:
+		return notifications.ChannelTelegram
+	}
+
+	return ""
+}
+
+func extractDigestPeriod(period digest.Period) DigestPeriod {
+	switch period {
+	case digest.PeriodDaily:
+		return DigestPeriod_DIGEST_PERIOD_DAILY
+	case digest.PeriodWeekly:
+		return DigestPeriod_DIGEST_PERIOD_WEEKLY
+	}
+
+	return DigestPeriod_DIGEST_PERIOD_NONE
+}
+
+func convertDigestPeriod(period DigestPeriod) digest.Period {
+	switch period {
+	case DigestPeriod_DIGEST_PERIOD_NONE:
+		return ""
+	case DigestPeriod_DIGEST_PERIOD_DAILY:
+		return digest.PeriodDaily
+	case DigestPeriod_DIGEST_PERIOD_WEEKLY:
+		return digest.PeriodWeekly
+	}
+
+	return ""
+}
+
 type UsersGRPCServer struct {
 	UnimplementedUsersServer
 	auth               *Auth
@@ -43,6 +141,7 @@ type UsersGRPCServer struct {
 	languages          map[string]config.LanguageConfig
 	captcha            bool
 	userExtractor      *UserExtractor
+	notifier           *notifications.Notifier
 }
 
 func NewUsersGRPCServer(
@@ -53,6 +152,7 @@ func NewUsersGRPCServer(
 	languages map[string]config.LanguageConfig,
 	captcha bool,
 	userExtractor *UserExtractor,
+	notifier *notifications.Notifier,
 ) *UsersGRPCServer {
 	return &UsersGRPCServer{
 		auth:               auth,
@@ -62,6 +162,7 @@ func NewUsersGRPCServer(
 		languages:          languages,
 		captcha:            captcha,
 		userExtractor:      userExtractor,
+		notifier:           notifier,
 	}
 }
 
@@ -253,6 +354,181 @@ func (s *UsersGRPCServer) GetUserPreferences(
 	}, nil
 }
 
+func (s *UsersGRPCServer) GetNotificationPreferences(
+	ctx context.Context,
+	_ *emptypb.Empty,
+) (*NotificationPreferences, error) {
+	userCtx, err := s.auth.ValidateGRPC(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if userCtx.UserID == 0 {
+		return nil, status.Errorf(codes.Unauthenticated, "Unauthenticated")
+	}
+
+	prefs, err := s.notifier.Preferences(ctx, userCtx.UserID)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	result := make([]*NotificationPreference, 0, len(prefs))
+	for _, pref := range prefs {
+		result = append(result, &NotificationPreference{
+			Event:   extractNotificationEvent(pref.Event),
+			Channel: extractNotificationChannel(pref.Channel),
+			Enabled: pref.Enabled,
+		})
+	}
+
+	period, err := s.userRepository.DigestPeriod(ctx, userCtx.UserID)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &NotificationPreferences{Items: result, DigestPeriod: extractDigestPeriod(digest.Period(period))}, nil
+}
+
+func (s *UsersGRPCServer) SetDigestPeriod(
+	ctx context.Context,
+	in *SetDigestPeriodRequest,
+) (*emptypb.Empty, error) {
+	userCtx, err := s.auth.ValidateGRPC(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if userCtx.UserID == 0 {
+		return nil, status.Errorf(codes.Unauthenticated, "Unauthenticated")
+	}
+
+	err = s.userRepository.SetDigestPeriod(ctx, userCtx.UserID, string(convertDigestPeriod(in.GetPeriod())))
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (s *UsersGRPCServer) GetBlockedUsers(
+	ctx context.Context, in *APIGetBlockedUsersRequest,
+) (*APIBlockedUsers, error) {
+	userCtx, err := s.auth.ValidateGRPC(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if userCtx.UserID == 0 {
+		return nil, status.Errorf(codes.Unauthenticated, "Unauthenticated")
+	}
+
+	limit := in.GetLimit()
+	if limit == 0 || limit > blockedUsersMaxLimit {
+		limit = blockedUsersMaxLimit
+	}
+
+	rows, next, err := s.userRepository.BlockedUsers(ctx, userCtx.UserID, limit, in.GetCursor())
+	if err != nil {
+		return nil, wrapCursorError(err)
+	}
+
+	items := make([]*APIBlockedUser, 0, len(rows))
+	for _, row := range rows {
+		items = append(items, &APIBlockedUser{
+			UserId:    row.UserID,
+			CreatedAt: timestamppb.New(row.CreatedAt),
+		})
+	}
+
+	return &APIBlockedUsers{Items: items, NextCursor: next}, nil
+}
+
+func (s *UsersGRPCServer) BlockUser(ctx context.Context, in *APIBlockUserRequest) (*emptypb.Empty, error) {
+	userCtx, err := s.auth.ValidateGRPC(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if userCtx.UserID == 0 {
+		return nil, status.Errorf(codes.Unauthenticated, "Unauthenticated")
+	}
+
+	if in.GetUserId() == userCtx.UserID {
+		return nil, status.Error(codes.InvalidArgument, users.ErrBlockSelf.Error())
+	}
+
+	_, err = s.userRepository.User(
+		ctx, &query.UserListOptions{ID: in.GetUserId()}, users.UserFields{}, users.OrderByNone,
+	)
+	if err != nil {
+		if errors.Is(err, users.ErrUserNotFound) {
+			return nil, status.Error(codes.NotFound, err.Error())
+		}
+
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	err = s.userRepository.BlockUser(ctx, userCtx.UserID, in.GetUserId())
+	if err != nil {
+		if errors.Is(err, users.ErrTooManyBlocked) {
+			return nil, status.Error(codes.FailedPrecondition, err.Error())
+		}
+
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (s *UsersGRPCServer) UnblockUser(ctx context.Context, in *APIBlockUserRequest) (*emptypb.Empty, error) {
+	userCtx, err := s.auth.ValidateGRPC(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if userCtx.UserID == 0 {
+		return nil, status.Errorf(codes.Unauthenticated, "Unauthenticated")
+	}
+
+	err = s.userRepository.UnblockUser(ctx, userCtx.UserID, in.GetUserId())
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (s *UsersGRPCServer) SetNotificationPreference(
+	ctx context.Context,
+	in *NotificationPreference,
+) (*emptypb.Empty, error) {
+	userCtx, err := s.auth.ValidateGRPC(ctx)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	if userCtx.UserID == 0 {
+		return nil, status.Errorf(codes.Unauthenticated, "Unauthenticated")
+	}
+
+	err = s.notifier.SetPreference(
+		ctx,
+		userCtx.UserID,
+		convertNotificationEvent(in.GetEvent()),
+		convertNotificationChannel(in.GetChannel()),
+		in.GetEnabled(),
+	)
+	if err != nil {
+		if errors.Is(err, notifications.ErrUnsupportedPreference) {
+			return nil, status.Error(codes.InvalidArgument, err.Error())
+		}
+
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
 func (s *UsersGRPCServer) GetUsers(
 	ctx context.Context,
 	in *APIUsersRequest,
@@ -13,7 +13,10 @@ import (
 	"github.com/autowp/goautowp/schema"
 	"github.com/doug-martin/goqu/v9"
 	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
 	"google.golang.org/protobuf/types/known/fieldmaskpb"
 )
 
@@ -210,6 +213,63 @@ func TestSetDisabledUserCommentsNotifications(t *testing.T) {
 	require.False(t, res2.GetDisableCommentsNotifications())
 }
 
+func TestNotificationPreferences(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	cfg := config.LoadConfig(".")
+	client := NewUsersClient(conn)
+	kc := cnt.Keycloak()
+
+	token, err := kc.Login(ctx, "frontend", "", cfg.Keycloak.Realm, testUsername, testPassword)
+	require.NoError(t, err)
+	require.NotNil(t, token)
+
+	ctx = metadata.AppendToOutgoingContext(ctx, authorizationHeader, bearerPrefix+token.AccessToken)
+
+	findPreference := func(prefs *NotificationPreferences, event NotificationEvent,
+		channel NotificationChannel,
+	) *NotificationPreference {
+		for _, pref := range prefs.GetItems() {
+			if pref.GetEvent() == event && pref.GetChannel() == channel {
+				return pref
+			}
+		}
+
+		return nil
+	}
+
+	for _, enabled := range []bool{true, false} {
+		_, err = client.SetNotificationPreference(ctx, &NotificationPreference{
+			Event:   NotificationEvent_NOTIFICATION_EVENT_SUBSCRIPTION,
+			Channel: NotificationChannel_NOTIFICATION_CHANNEL_EMAIL,
+			Enabled: enabled,
+		})
+		require.NoError(t, err)
+
+		prefs, err := client.GetNotificationPreferences(ctx, &emptypb.Empty{})
+		require.NoError(t, err)
+
+		pref := findPreference(
+			prefs, NotificationEvent_NOTIFICATION_EVENT_SUBSCRIPTION, NotificationChannel_NOTIFICATION_CHANNEL_EMAIL,
+		)
+		require.NotNil(t, pref)
+		require.Equal(t, enabled, pref.GetEnabled())
+		require.Nil(t, findPreference(
+			prefs,
+			NotificationEvent_NOTIFICATION_EVENT_PERSONAL_MESSAGE,
+			NotificationChannel_NOTIFICATION_CHANNEL_SYSTEM_MESSAGE,
+		))
+	}
+
+	_, err = client.SetNotificationPreference(ctx, &NotificationPreference{
+		Event:   NotificationEvent_NOTIFICATION_EVENT_PERSONAL_MESSAGE,
+		Channel: NotificationChannel_NOTIFICATION_CHANNEL_SYSTEM_MESSAGE,
+		Enabled: false,
+	})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
 func TestGetOnlineUsers(t *testing.T) {
 	t.Parallel()
 
@@ -375,3 +435,116 @@ func TestUpdateUser(t *testing.T) {
 	require.Equal(t, "Europe/Dublin", res.GetTimezone())
 	require.Equal(t, "ru", res.GetLanguage())
 }
+
+func TestBlockUser(t *testing.T) {
+	t.Parallel()
+
+	ctx := t.Context()
+	cfg := config.LoadConfig(".")
+	kc := cnt.Keycloak()
+	client := NewUsersClient(conn)
+	messagingClient := NewMessagingClient(conn)
+
+	// blocker is a fresh user, so other tests are not affected
+	random := rand.New(rand.NewSource(time.Now().UnixNano())) //nolint:gosec
+	userEmail := "test" + strconv.Itoa(random.Int()) + "@example.com"
+	name := "ivan"
+	password := "password"
+
+	clientToken, err := kc.LoginClient(ctx, cfg.Keycloak.ClientID, cfg.Keycloak.ClientSecret, cfg.Keycloak.Realm)
+	require.NoError(t, err)
+
+	_, err = kc.CreateUser(ctx, clientToken.AccessToken, cfg.Keycloak.Realm, gocloak.User{
+		Enabled:       gocloak.BoolP(true),
+		EmailVerified: gocloak.BoolP(true),
+		Username:      &userEmail,
+		FirstName:     &name,
+		Email:         &userEmail,
+		Credentials: &[]gocloak.CredentialRepresentation{{
+			Type:  gocloak.StringP("password"),
+			Value: &password,
+		}},
+	})
+	require.NoError(t, err)
+
+	token, err := kc.Login(ctx, "frontend", "", cfg.Keycloak.Realm, userEmail, password)
+	require.NoError(t, err)
+
+	blockerCtx := metadata.AppendToOutgoingContext(ctx, authorizationHeader, bearerPrefix+token.AccessToken)
+
+	blocker, err := client.Me(blockerCtx, &APIMeRequest{})
+	require.NoError(t, err)
+
+	testerToken, err := kc.Login(ctx, "frontend", "", cfg.Keycloak.Realm, testUsername, testPassword)
+	require.NoError(t, err)
+
+	testerCtx := metadata.AppendToOutgoingContext(ctx, authorizationHeader, bearerPrefix+testerToken.AccessToken)
+
+	tester, err := client.Me(testerCtx, &APIMeRequest{})
+	require.NoError(t, err)
+
+	_, err = client.BlockUser(blockerCtx, &APIBlockUserRequest{UserId: blocker.GetId()})
+	require.Equal(t, codes.InvalidArgument, status.Code(err))
+
+	_, err = client.BlockUser(blockerCtx, &APIBlockUserRequest{UserId: tester.GetId()})
+	require.NoError(t, err)
+
+	blocked, err := client.GetBlockedUsers(blockerCtx, &APIGetBlockedUsersRequest{})
+	require.NoError(t, err)
+	require.Len(t, blocked.GetItems(), 1)
+	require.Equal(t, tester.GetId(), blocked.GetItems()[0].GetUserId())
+
+	_, err = messagingClient.CreateMessage(testerCtx, &MessagingCreateMessage{
+		UserId: blocker.GetId(),
+		Text:   "Test message",
+	})
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	_, err = messagingClient.SendConversationMessage(testerCtx, &MessagingSendConversationMessageRequest{
+		UserId: blocker.GetId(),
+		Text:   "Test message",
+	})
+	require.Equal(t, codes.PermissionDenied, status.Code(err))
+
+	// replies of blocked user are not collected for digest
+	commentsClient := NewCommentsClient(conn)
+
+	comment, err := commentsClient.Add(blockerCtx, &AddCommentRequest{
+		ItemId:  1,
+		TypeId:  CommentsType_ARTICLES_TYPE_ID,
+		Message: "Comment of blocker",
+	})
+	require.NoError(t, err)
+
+	reply, err := commentsClient.Add(testerCtx, &AddCommentRequest{
+		ItemId:   1,
+		TypeId:   CommentsType_ARTICLES_TYPE_ID,
+		Message:  "Reply of blocked user",
+		ParentId: comment.GetId(),
+	})
+	require.NoError(t, err)
+
+	commentsRepository, err := cnt.CommentsRepository()
+	require.NoError(t, err)
+
+	replyIDs, err := commentsRepository.UnreadReplies(ctx, blocker.GetId(), time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	require.NotContains(t, replyIDs, reply.GetId())
+
+	_, err = client.UnblockUser(blockerCtx, &APIBlockUserRequest{UserId: tester.GetId()})
+	require.NoError(t, err)
+
+	replyIDs, err = commentsRepository.UnreadReplies(ctx, blocker.GetId(), time.Now().Add(-time.Hour))
+	require.NoError(t, err)
+	require.Contains(t, replyIDs, reply.GetId())
+
+	blocked, err = client.GetBlockedUsers(blockerCtx, &APIGetBlockedUsersRequest{})
+	require.NoError(t, err)
+	require.Empty(t, blocked.GetItems())
+
+	_, err = messagingClient.CreateMessage(testerCtx, &MessagingCreateMessage{
+		UserId: blocker.GetId(),
+		Text:   "Test message",
+	})
+	require.NoError(t, err)
+}
@@ -8,6 +8,7 @@ import (
 	"io"
 	"math"
 	"net"
+	"strconv"
 	"strings"
 	"time"
 
@@ -22,7 +23,8 @@ import (
 	"github.com/doug-martin/goqu/v9"
 	_ "github.com/doug-martin/goqu/v9/dialect/mysql"    // enable mysql dialect
 	_ "github.com/doug-martin/goqu/v9/dialect/postgres" // enable postgres dialect
-	_ "github.com/go-sql-driver/mysql"                  // enable mysql driver
+	"github.com/doug-martin/goqu/v9/exp"
+	_ "github.com/go-sql-driver/mysql" // enable mysql driver
 	"github.com/google/uuid"
 	_ "github.com/lib/pq" // enable postgres driver
 	"github.com/sirupsen/logrus"
@@ -38,10 +40,18 @@ const (
 
 const deleteUnusedBatchSize = 100
 
+// MaxBlockedUsers is a maximum number of users one account can block.
+const MaxBlockedUsers = 1000
+
+// users without identity are addressed as user<id>, the same way as in their urls.
+const userIDIdentityPrefix = "user"
+
 var (
 	ErrUserNotFound     = errors.New("user not found")
 	ErrFormatNotFound   = errors.New("format not found")
 	ErrLanguageNotFound = errors.New("language is not defined")
+	ErrBlockSelf        = errors.New("user can't block self")
+	ErrTooManyBlocked   = errors.New("too many blocked users")
 )
 
 type Claims struct {
@@ -114,6 +124,32 @@ type UserPreferences struct {
 	DisableCommentsNotifications bool `db:"disable_comments_notifications" json:"disable_comments_notifications"`
 }
 
+// NotificationPreference is an explicit choice of user to receive notification event over channel or not.
+type NotificationPreference struct {
+	Event   string `db:"event"`
+	Channel string `db:"channel"`
+	Enabled bool   `db:"enabled"`
+}
+
+// DigestSubscription is a choice of user to receive email digest every period.
+type DigestSubscription struct {
+	UserID int64        `db:"user_id"`
+	Period string       `db:"period"`
+	SentAt sql.NullTime `db:"sent_at"`
+}
+
+// BlockedUser is a user whose messages are hidden from blocker.
+type BlockedUser struct {
+	UserID    int64     `db:"blocked_user_id"`
+	CreatedAt time.Time `db:"created_at"`
+}
+
+// blockedUsersKeyset is an order of blocked users, used by cursor pagination.
+var blockedUsersKeyset = util.Keyset[BlockedUser]{
+	{Name: schema.UserBlockTableCreatedAtColName, Desc: true, Value: func(row BlockedUser) any { return row.CreatedAt }},
+	{Name: schema.UserBlockTableBlockedUserIDColName, Desc: true, Value: func(row BlockedUser) any { return row.UserID }},
+}
+
 // NewRepository constructor.
 func NewRepository(
 	autowpDB *goqu.Database,
@@ -169,6 +205,60 @@ func (s *Repository) UserIDByIdentity(ctx context.Context, identity string) (int
 	return userID, nil
 }
 
+// UsersByIdentities resolves not deleted users addressed by identity or as user<id> with a single query.
+// Result is keyed by requested identity, unknown identities are omitted.
+func (s *Repository) UsersByIdentities(ctx context.Context, identities []string) (map[string]schema.UsersRow, error) {
+	result := make(map[string]schema.UsersRow, len(identities))
+	if len(identities) == 0 {
+		return result, nil
+	}
+
+	ids := make([]int64, 0, len(identities))
+
+	for _, identity := range identities {
+		if !strings.HasPrefix(identity, userIDIdentityPrefix) {
+			continue
+		}
+
+		id, err := strconv.ParseInt(strings.TrimPrefix(identity, userIDIdentityPrefix), 10, 64)
+		if err == nil && id > 0 {
+			ids = append(ids, id)
+		}
+	}
+
+	conds := []goqu.Expression{schema.UserTableIdentityCol.In(identities)}
+	if len(ids) > 0 {
+		conds = append(conds, schema.UserTableIDCol.In(ids))
+	}
+
+	var rows []schema.UsersRow
+
+	err := s.autowpDB.Select(schema.UserTableIDCol, schema.UserTableIdentityCol).
+		From(schema.UserTable).
+		Where(schema.UserTableDeletedCol.IsFalse(), goqu.Or(conds...)).
+		ScanStructsContext(ctx, &rows)
+	if err != nil {
+		return nil, err
+	}
+
+	requested := make(map[string]bool, len(identities))
+	for _, identity := range identities {
+		requested[identity] = true
+	}
+
+	for _, row := range rows {
+		if row.Identity != nil && requested[*row.Identity] {
+			result[*row.Identity] = row
+		}
+
+		if idIdentity := userIDIdentityPrefix + strconv.FormatInt(row.ID, 10); requested[idIdentity] {
+			result[idIdentity] = row
+		}
+	}
+
+	return result, nil
+}
+
 func (s *Repository) Users(
 	ctx context.Context, options *query.UserListOptions, fields UserFields, orderBy OrderBy,
 ) ([]schema.UsersRow, *util.Pages, error) {
@@ -915,6 +1005,261 @@ func (s *Repository) UserPreferences(
 	return &row, err
 }
 
+// CommentsNotificationsDisabled returns ids of users among userIDs who disabled notifications
+// about comments of toUserID.
+func (s *Repository) CommentsNotificationsDisabled(
+	ctx context.Context, userIDs []int64, toUserID int64,
+) ([]int64, error) {
+	result := make([]int64, 0)
+
+	if len(userIDs) == 0 {
+		return result, nil
+	}
+
+	err := s.db.Select(schema.UserUserPreferencesTableUserIDCol).
+		From(schema.UserUserPreferencesTable).
+		Where(
+			schema.UserUserPreferencesTableUserIDCol.In(userIDs),
+			schema.UserUserPreferencesTableToUserIDCol.Eq(toUserID),
+			schema.UserUserPreferencesTableDCNCol.IsTrue(),
+		).ScanValsContext(ctx, &result)
+
+	return result, err
+}
+
+func (s *Repository) SetNotificationPreference(
+	ctx context.Context,
+	userID int64,
+	event string,
+	channel string,
+	enabled bool,
+) error {
+	_, err := s.db.Insert(schema.UserNotificationPreferencesTable).
+		Rows(goqu.Record{
+			schema.UserNotificationPreferencesTableUserIDColName:  userID,
+			schema.UserNotificationPreferencesTableEventColName:   event,
+			schema.UserNotificationPreferencesTableChannelColName: channel,
+			schema.UserNotificationPreferencesTableEnabledColName: enabled,
+		}).
+		OnConflict(
+			goqu.DoUpdate(
+				schema.UserNotificationPreferencesTableUserIDColName+", "+
+					schema.UserNotificationPreferencesTableEventColName+", "+
+					schema.UserNotificationPreferencesTableChannelColName,
+				goqu.Record{
+					schema.UserNotificationPreferencesTableEnabledColName: schema.Excluded(
+						schema.UserNotificationPreferencesTableEnabledColName,
+					),
+				},
+			),
+		).Executor().ExecContext(ctx)
+
+	return err
+}
+
+func (s *Repository) NotificationPreferences(
+	ctx context.Context,
+	userID int64,
+) ([]NotificationPreference, error) {
+	var rows []NotificationPreference
+
+	err := s.db.Select(
+		schema.UserNotificationPreferencesTableEventCol,
+		schema.UserNotificationPreferencesTableChannelCol,
+		schema.UserNotificationPreferencesTableEnabledCol,
+	).
+		From(schema.UserNotificationPreferencesTable).
+		Where(schema.UserNotificationPreferencesTableUserIDCol.Eq(userID)).
+		ScanStructsContext(ctx, &rows)
+
+	return rows, err
+}
+
+// SetDigestPeriod subscribes user to digest, empty period unsubscribes.
+func (s *Repository) SetDigestPeriod(ctx context.Context, userID int64, period string) error {
+	if len(period) == 0 {
+		_, err := s.db.Delete(schema.UserDigestTable).
+			Where(schema.UserDigestTableUserIDCol.Eq(userID)).
+			Executor().ExecContext(ctx)
+
+		return err
+	}
+
+	_, err := s.db.Insert(schema.UserDigestTable).
+		Rows(goqu.Record{
+			schema.UserDigestTableUserIDColName: userID,
+			schema.UserDigestTablePeriodColName: period,
+		}).
+		OnConflict(
+			goqu.DoUpdate(
+				schema.UserDigestTableUserIDColName,
+				goqu.Record{
+					schema.UserDigestTablePeriodColName: schema.Excluded(schema.UserDigestTablePeriodColName),
+				},
+			),
+		).Executor().ExecContext(ctx)
+
+	return err
+}
+
+func (s *Repository) DigestPeriod(ctx context.Context, userID int64) (string, error) {
+	var period string
+
+	_, err := s.db.Select(schema.UserDigestTablePeriodCol).
+		From(schema.UserDigestTable).
+		Where(schema.UserDigestTableUserIDCol.Eq(userID)).
+		ScanValContext(ctx, &period)
+
+	return period, err
+}
+
+// DigestSubscriptions returns subscriptions of period, which digest was not sent since sentBefore.
+func (s *Repository) DigestSubscriptions(
+	ctx context.Context, period string, sentBefore time.Time,
+) ([]DigestSubscription, error) {
+	var rows []DigestSubscription
+
+	err := s.db.Select(
+		schema.UserDigestTableUserIDCol, schema.UserDigestTablePeriodCol, schema.UserDigestTableSentAtCol,
+	).
+		From(schema.UserDigestTable).
+		Where(
+			schema.UserDigestTablePeriodCol.Eq(period),
+			goqu.Or(
+				schema.UserDigestTableSentAtCol.IsNull(),
+				schema.UserDigestTableSentAtCol.Lt(sentBefore),
+			),
+		).
+		Order(schema.UserDigestTableUserIDCol.Asc()).
+		ScanStructsContext(ctx, &rows)
+
+	return rows, err
+}
+
+func (s *Repository) SetDigestSent(ctx context.Context, userID int64, sentAt time.Time) error {
+	_, err := s.db.Update(schema.UserDigestTable).
+		Set(goqu.Record{schema.UserDigestTableSentAtColName: sentAt}).
+		Where(schema.UserDigestTableUserIDCol.Eq(userID)).
+		Executor().ExecContext(ctx)
+
+	return err
+}
+
+// BlockUser rejects personal messages from blocked user and hides their comments and forum posts from user.
+// BlockUser hides messages of blockedUserID from userID.
+// Returns ErrTooManyBlocked when userID already blocked MaxBlockedUsers users.
+func (s *Repository) BlockUser(ctx context.Context, userID int64, blockedUserID int64) error {
+	if userID == blockedUserID {
+		return ErrBlockSelf
+	}
+
+	return s.autowpDB.WithTx(func(tx *goqu.TxDatabase) error {
+		var count int64
+
+		_, err := tx.Select(goqu.COUNT(goqu.Star())).
+			From(schema.UserBlockTable).
+			Where(
+				schema.UserBlockTableUserIDCol.Eq(userID),
+				schema.UserBlockTableBlockedUserIDCol.Neq(blockedUserID),
+			).
+			ForUpdate(exp.Wait).
+			ScanValContext(ctx, &count)
+		if err != nil {
+			return err
+		}
+
+		if count >= MaxBlockedUsers {
+			return ErrTooManyBlocked
+		}
+
+		_, err = tx.Insert(schema.UserBlockTable).
+			Rows(goqu.Record{
+				schema.UserBlockTableUserIDColName:        userID,
+				schema.UserBlockTableBlockedUserIDColName: blockedUserID,
+			}).
+			OnConflict(goqu.DoNothing()).
+			Executor().ExecContext(ctx)
+
+		return err
+	})
+}
+
+func (s *Repository) UnblockUser(ctx context.Context, userID int64, blockedUserID int64) error {
+	_, err := s.autowpDB.Delete(schema.UserBlockTable).
+		Where(
+			schema.UserBlockTableUserIDCol.Eq(userID),
+			schema.UserBlockTableBlockedUserIDCol.Eq(blockedUserID),
+		).
+		Executor().ExecContext(ctx)
+
+	return err
+}
+
+// BlockedUsers returns page of users blocked by userID, recently blocked first, and cursor of the next page.
+func (s *Repository) BlockedUsers(
+	ctx context.Context, userID int64, limit uint32, cursor string,
+) ([]BlockedUser, string, error) {
+	after, err := blockedUsersKeyset.Decode(cursor)
+	if err != nil {
+		return nil, "", err
+	}
+
+	rows := make([]BlockedUser, 0)
+
+	err = after.Where(
+		s.autowpDB.Select(schema.UserBlockTableBlockedUserIDCol, schema.UserBlockTableCreatedAtCol).
+			From(schema.UserBlockTable).
+			Where(schema.UserBlockTableUserIDCol.Eq(userID)),
+		schema.UserBlockTableName,
+	).
+		Order(blockedUsersKeyset.Order(schema.UserBlockTableName)...).
+		Limit(uint(limit)+1).
+		ScanStructsContext(ctx, &rows)
+	if err != nil {
+		return nil, "", err
+	}
+
+	rows, next := blockedUsersKeyset.Page(rows, limit)
+
+	return rows, next, nil
+}
+
+func (s *Repository) IsBlocked(ctx context.Context, userID int64, blockedUserID int64) (bool, error) {
+	var exists bool
+
+	success, err := s.autowpDB.Select(goqu.V(true)).
+		From(schema.UserBlockTable).
+		Where(
+			schema.UserBlockTableUserIDCol.Eq(userID),
+			schema.UserBlockTableBlockedUserIDCol.Eq(blockedUserID),
+		).
+		ScanValContext(ctx, &exists)
+	if err != nil {
+		return false, err
+	}
+
+	return success && exists, nil
+}
+
+// BlockedBy returns ids of users among userIDs who blocked blockedUserID.
+func (s *Repository) BlockedBy(ctx context.Context, userIDs []int64, blockedUserID int64) ([]int64, error) {
+	result := make([]int64, 0)
+
+	if len(userIDs) == 0 {
+		return result, nil
+	}
+
+	err := s.autowpDB.Select(schema.UserBlockTableUserIDCol).
+		From(schema.UserBlockTable).
+		Where(
+			schema.UserBlockTableUserIDCol.In(userIDs),
+			schema.UserBlockTableBlockedUserIDCol.Eq(blockedUserID),
+		).
+		ScanValsContext(ctx, &result)
+
+	return result, err
+}
+
 func (s *Repository) incForumTopicsRecord() goqu.Record {
 	r := s.incForumMessagesRecord()
 	r[schema.UserTableForumsTopicsColName] = goqu.L(schema.UserTableForumsTopicsColName + " + 1")
@@ -0,0 +1,219 @@
+package util
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+	"github.com/doug-martin/goqu/v9/exp"
+)
+
+var (
+	ErrInvalidCursor      = errors.New("invalid cursor")
+	ErrCursorNotSupported = errors.New("cursor pagination is not supported for this order")
+)
+
+// KeysetColumn is a column of keyset order. Value returns key of row, int64, time.Time or nil for NULL.
+type KeysetColumn[T any] struct {
+	Name     string
+	Desc     bool
+	Nullable bool
+	Value    func(row T) any
+}
+
+// Keyset is an order of rows usable for cursor pagination, last column must be unique.
+// Unlike OFFSET, cursor points to the last seen row, so deep pages are cheap and rows inserted
+// concurrently don't shift pages.
+type Keyset[T any] []KeysetColumn[T]
+
+type keysetColumn struct {
+	name     string
+	desc     bool
+	nullable bool
+}
+
+// KeysetCursor is a decoded position in keyset order, the beginning of list when no values are present.
+type KeysetCursor struct {
+	columns []keysetColumn
+	values  []any
+}
+
+type encodedCursor struct {
+	Keyset uint32 `json:"k"`
+	Values []any  `json:"v"`
+}
+
+func (s Keyset[T]) columns() []keysetColumn {
+	result := make([]keysetColumn, len(s))
+	for idx, column := range s {
+		result[idx] = keysetColumn{name: column.Name, desc: column.Desc, nullable: column.Nullable}
+	}
+
+	return result
+}
+
+// checksum identifies keyset, so cursor of one order is not accepted by another.
+func (s Keyset[T]) checksum() uint32 {
+	parts := make([]string, len(s))
+	for idx, column := range s {
+		parts[idx] = fmt.Sprintf("%s:%t", column.Name, column.Desc)
+	}
+
+	return crc32.ChecksumIEEE([]byte(strings.Join(parts, ",")))
+}
+
+// Reverse returns the same keyset in opposite direction, used to look up rows preceding cursor.
+func (s Keyset[T]) Reverse() Keyset[T] {
+	result := make(Keyset[T], len(s))
+	for idx, column := range s {
+		column.Desc = !column.Desc
+		result[idx] = column
+	}
+
+	return result
+}
+
+// Order returns ORDER BY expressions of keyset, columns are qualified with alias.
+func (s Keyset[T]) Order(alias string) []exp.OrderedExpression {
+	table := goqu.T(alias)
+	result := make([]exp.OrderedExpression, len(s))
+
+	for idx, column := range s {
+		if column.Desc {
+			result[idx] = table.Col(column.Name).Desc()
+		} else {
+			result[idx] = table.Col(column.Name).Asc()
+		}
+	}
+
+	return result
+}
+
+// Encode returns opaque cursor pointing after row.
+func (s Keyset[T]) Encode(row T) string {
+	values := make([]any, len(s))
+	for idx, column := range s {
+		values[idx] = column.Value(row)
+	}
+
+	data, _ := json.Marshal(encodedCursor{Keyset: s.checksum(), Values: values}) //nolint: errchkjson
+
+	return base64.RawURLEncoding.EncodeToString(data)
+}
+
+// Decode parses cursor returned by Encode, empty cursor points to the beginning of list.
+func (s Keyset[T]) Decode(cursor string) (*KeysetCursor, error) {
+	result := KeysetCursor{columns: s.columns()}
+
+	if len(cursor) == 0 {
+		return &result, nil
+	}
+
+	data, err := base64.RawURLEncoding.DecodeString(cursor)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCursor, err)
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var encoded encodedCursor
+
+	err = decoder.Decode(&encoded)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %w", ErrInvalidCursor, err)
+	}
+
+	if encoded.Keyset != s.checksum() || len(encoded.Values) != len(s) {
+		return nil, fmt.Errorf("%w: cursor of another order", ErrInvalidCursor)
+	}
+
+	result.values = make([]any, len(encoded.Values))
+
+	for idx, value := range encoded.Values {
+		switch typed := value.(type) {
+		case nil:
+			if !s[idx].Nullable {
+				return nil, fmt.Errorf("%w: unexpected null", ErrInvalidCursor)
+			}
+		case json.Number:
+			result.values[idx], err = typed.Int64()
+			if err != nil {
+				return nil, fmt.Errorf("%w: %w", ErrInvalidCursor, err)
+			}
+		case string:
+			result.values[idx], err = time.Parse(time.RFC3339Nano, typed)
+			if err != nil {
+				return nil, fmt.Errorf("%w: %w", ErrInvalidCursor, err)
+			}
+		default:
+			return nil, fmt.Errorf("%w: unexpected value", ErrInvalidCursor)
+		}
+	}
+
+	return &result, nil
+}
+
+// Page trims extra row, fetched to detect next page, and returns cursor of next page, empty for the last one.
+func (s Keyset[T]) Page(rows []T, limit uint32) ([]T, string) {
+	if limit == 0 || len(rows) <= int(limit) {
+		return rows, ""
+	}
+
+	rows = rows[:limit]
+
+	return rows, s.Encode(rows[len(rows)-1])
+}
+
+// Where restricts select to rows following cursor. NULLs are ordered as MySQL does: first in ascending order.
+func (s *KeysetCursor) Where(sqSelect *goqu.SelectDataset, alias string) *goqu.SelectDataset {
+	if len(s.values) == 0 {
+		return sqSelect
+	}
+
+	table := goqu.T(alias)
+	terms := make([]exp.Expression, 0, len(s.columns))
+	equals := make([]exp.Expression, 0, len(s.columns))
+
+	for idx, column := range s.columns {
+		col := table.Col(column.name)
+		value := s.values[idx]
+
+		var after exp.Expression
+
+		switch {
+		case value == nil && !column.desc:
+			after = col.IsNotNull()
+		case value == nil:
+		case column.desc && column.nullable:
+			after = goqu.Or(col.Lt(value), col.IsNull())
+		case column.desc:
+			after = col.Lt(value)
+		default:
+			after = col.Gt(value)
+		}
+
+		if after != nil {
+			terms = append(terms, goqu.And(append(slices.Clone(equals), after)...))
+		}
+
+		if value == nil {
+			equals = append(equals, col.IsNull())
+		} else {
+			equals = append(equals, col.Eq(value))
+		}
+	}
+
+	if len(terms) == 0 {
+		return sqSelect.Where(goqu.L("FALSE"))
+	}
+
+	return sqSelect.Where(goqu.Or(terms...))
+}
@@ -0,0 +1,103 @@
+package util
+
+import (
+	"database/sql"
+	"testing"
+	"time"
+
+	"github.com/doug-martin/goqu/v9"
+	_ "github.com/doug-martin/goqu/v9/dialect/mysql" // enable mysql dialect
+	"github.com/stretchr/testify/require"
+)
+
+type cursorTestRow struct {
+	ID         int64
+	AcceptedAt sql.NullTime
+}
+
+var cursorTestKeyset = Keyset[cursorTestRow]{
+	{Name: "accepted_at", Desc: true, Nullable: true, Value: func(row cursorTestRow) any {
+		if !row.AcceptedAt.Valid {
+			return nil
+		}
+
+		return row.AcceptedAt.Time
+	}},
+	{Name: "id", Desc: true, Value: func(row cursorTestRow) any { return row.ID }},
+}
+
+func TestKeysetCursor(t *testing.T) {
+	t.Parallel()
+
+	acceptedAt := time.Date(2024, 5, 1, 10, 0, 0, 0, time.UTC)
+
+	rows, next := cursorTestKeyset.Page([]cursorTestRow{
+		{ID: 3, AcceptedAt: sql.NullTime{Valid: true, Time: acceptedAt}},
+		{ID: 2, AcceptedAt: sql.NullTime{Valid: true, Time: acceptedAt}},
+		{ID: 1},
+	}, 2)
+	require.Len(t, rows, 2)
+	require.NotEmpty(t, next)
+
+	cursor, err := cursorTestKeyset.Decode(next)
+	require.NoError(t, err)
+
+	sqSelect := cursor.Where(goqu.Dialect("mysql").From("t"), "t")
+	sqlText, _, err := sqSelect.ToSQL()
+	require.NoError(t, err)
+	require.Equal(t, "SELECT * FROM `t` WHERE (((`t`.`accepted_at` < '2024-05-01 10:00:00') OR "+
+		"(`t`.`accepted_at` IS NULL)) OR ((`t`.`accepted_at` = '2024-05-01 10:00:00') AND (`t`.`id` < 2)))", sqlText)
+
+	_, next = cursorTestKeyset.Page([]cursorTestRow{{ID: 2}, {ID: 1}}, 1)
+
+	cursor, err = cursorTestKeyset.Decode(next)
+	require.NoError(t, err)
+
+	sqlText, _, err = cursor.Where(goqu.Dialect("mysql").From("t"), "t").ToSQL()
+	require.NoError(t, err)
+	require.Equal(t, "SELECT * FROM `t` WHERE ((`t`.`accepted_at` IS NULL) AND (`t`.`id` < 2))", sqlText)
+
+	rows, next = cursorTestKeyset.Page([]cursorTestRow{{ID: 1}}, 2)
+	require.Len(t, rows, 1)
+	require.Empty(t, next)
+}
+
+func TestKeysetCursorInvalid(t *testing.T) {
+	t.Parallel()
+
+	otherKeyset := Keyset[cursorTestRow]{
+		{Name: "id", Value: func(row cursorTestRow) any { return row.ID }},
+	}
+
+	for _, cursor := range []string{"!", "e30", otherKeyset.Encode(cursorTestRow{ID: 1})} {
+		_, err := cursorTestKeyset.Decode(cursor)
+		require.ErrorIs(t, err, ErrInvalidCursor, cursor)
+	}
+
+	cursor, err := cursorTestKeyset.Decode("")
+	require.NoError(t, err)
+
+	sqlText, _, err := cursor.Where(goqu.Dialect("mysql").From("t"), "t").ToSQL()
+	require.NoError(t, err)
+	require.Equal(t, "SELECT * FROM `t`", sqlText)
+}
+
+func TestKeysetReverse(t *testing.T) {
+	t.Parallel()
+
+	reversed := cursorTestKeyset.Reverse()
+	require.False(t, reversed[0].Desc)
+	require.False(t, reversed[1].Desc)
+	require.True(t, cursorTestKeyset[0].Desc)
+
+	cursor, err := reversed.Decode(reversed.Encode(cursorTestRow{ID: 2}))
+	require.NoError(t, err)
+
+	sqlText, _, err := cursor.Where(goqu.Dialect("mysql").From("t"), "t").Order(reversed.Order("t")...).ToSQL()
+	require.NoError(t, err)
+	require.Equal(t, "SELECT * FROM `t` WHERE ((`t`.`accepted_at` IS NOT NULL) OR "+
+		"((`t`.`accepted_at` IS NULL) AND (`t`.`id` > 2))) ORDER BY `t`.`accepted_at` ASC, `t`.`id` ASC", sqlText)
+
+	_, err = cursorTestKeyset.Decode(reversed.Encode(cursorTestRow{ID: 2}))
+	require.ErrorIs(t, err, ErrInvalidCursor)
+}
@@ -39,6 +39,16 @@ type Pages struct {
 	PagesInRange     []int32
 }
 
+// NewCountedPaginator creates paginator over already known number of items, e.g. returned by search engine.
+func NewCountedPaginator(totalItemCount int32, itemCountPerPage int32, currentPageNumber int32) *Paginator {
+	return &Paginator{
+		ItemCountPerPage:    itemCountPerPage,
+		CurrentPageNumber:   currentPageNumber,
+		itemCount:           totalItemCount,
+		itemCountCalculated: true,
+	}
+}
+
 func (s *Paginator) Count(ctx context.Context) (int32, error) {
 	var err error
 	if !s.pageCountCalculated {